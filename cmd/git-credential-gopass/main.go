@@ -0,0 +1,88 @@
+// Command git-credential-gopass is a git credential helper reading and
+// storing git credentials in gopass items.
+//
+// Log in with "gopass login" first, then configure git to use it:
+//
+//	git config --global credential.helper gopass
+//
+// Credentials are looked up in every vault by matching the URL of the items
+// with the remote, and stored in the "Git" vault. Flags go before the action:
+//
+//	git config --global credential.helper "gopass --vault Work --only-vault"
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/edgardjr92/gopass/internal/cliconfig"
+	"github.com/edgardjr92/gopass/pkg/gitcred"
+)
+
+const usage = `Usage: git-credential-gopass [--vault name] [--only-vault] <get|store|erase>
+
+Flags:
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git-credential-gopass: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("git-credential-gopass", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	vault := fs.String("vault", gitcred.DefaultVault, "vault credentials are stored in")
+	onlyVault := fs.Bool("only-vault", false, "only look for credentials in the --vault vault")
+
+	if err := fs.Parse(args); err != nil {
+		return flag.ErrHelp
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	path, err := cliconfig.DefaultPath()
+
+	if err != nil {
+		return err
+	}
+
+	cfg, err := cliconfig.Load(path)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := cfg.Client()
+
+	if err != nil {
+		return err
+	}
+
+	helper := &gitcred.Helper{Store: c, Vault: *vault, OnlyVault: *onlyVault}
+
+	return helper.Run(ctx, fs.Arg(0), stdin, stdout)
+}
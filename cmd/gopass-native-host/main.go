@@ -0,0 +1,78 @@
+// Command gopass-native-host is the native messaging host of the gopass
+// browser extensions. Browsers start it and talk to it over stdin and stdout;
+// it isn't meant to be run by hand.
+//
+// Log in with "gopass login" first, then register the host with the browser
+// with a manifest such as, for Chrome:
+//
+//	{
+//	  "name": "com.gopass.native_host",
+//	  "description": "gopass",
+//	  "path": "/usr/local/bin/gopass-native-host",
+//	  "type": "stdio",
+//	  "allowed_origins": ["chrome-extension://<extension id>/"]
+//	}
+//
+// Firefox manifests list "allowed_extensions" instead of "allowed_origins".
+// The host reads the config of gopass on every request, so logging out of
+// gopass locks the extension at once. New logins are saved in the "Browser"
+// vault, unless the extension names another one.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/edgardjr92/gopass/internal/cliconfig"
+	"github.com/edgardjr92/gopass/pkg/nativemsg"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Stdin, os.Stdout)
+	stop()
+
+	// Browsers log the stderr of native hosts, stdout is reserved for messages.
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gopass-native-host: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run serves the messages of the browser. The arguments browsers pass, the
+// origin of the extension or the path of the manifest, have already been
+// checked by the browser against the manifest, so they are ignored.
+func run(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+	path, err := cliconfig.DefaultPath()
+
+	if err != nil {
+		return err
+	}
+
+	host := &nativemsg.Host{
+		Unlock: func() (nativemsg.Store, string, error) {
+			cfg, err := cliconfig.Load(path)
+
+			if err != nil {
+				return nil, "", err
+			}
+
+			if cfg.Token == "" {
+				return nil, "", nativemsg.ErrLocked
+			}
+
+			c, err := cfg.Client()
+
+			if err != nil {
+				return nil, "", err
+			}
+
+			return c, cfg.Token, nil
+		},
+	}
+
+	return host.Serve(ctx, stdin, stdout)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+)
+
+func (a *app) auditList(ctx context.Context, args []string) error {
+	fs := a.flags("audit ls", "[--vault <vault>] [--from <time>] [--to <time>] [--limit <n>]")
+	vaultRef := fs.String("vault", "", "only the events of the vault, by name or ID; deleted vaults by ID")
+	fromRef := fs.String("from", "", "only the events from this time on, as 2006-01-02 or RFC 3339")
+	toRef := fs.String("to", "", "only the events before this time, as 2006-01-02 or RFC 3339")
+	limit := fs.Uint("limit", 0, "maximum number of events")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	filter := client.AuditFilter{Limit: *limit}
+	var err error
+
+	if filter.From, err = parseTime("from", *fromRef); err != nil {
+		return err
+	}
+
+	if filter.To, err = parseTime("to", *toRef); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	if *vaultRef != "" {
+		if filter.VaultID, err = auditVaultID(ctx, c, *vaultRef); err != nil {
+			return err
+		}
+	}
+
+	events, err := c.AuditEvents(ctx, filter)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(events)
+	}
+
+	rows := make([][]string, len(events))
+
+	for i, e := range events {
+		rows[i] = []string{
+			e.CreatedAt.Local().Format(time.RFC3339),
+			e.Action,
+			e.Outcome,
+			optionalID(e.VaultID),
+			optionalID(e.ItemID),
+			e.IP,
+			e.Detail,
+		}
+	}
+
+	return a.printTable([]string{"TIME", "ACTION", "OUTCOME", "VAULT", "ITEM", "IP", "DETAIL"}, rows)
+}
+
+func (a *app) auditVerify(ctx context.Context, args []string) error {
+	fs := a.flags("audit verify", "")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	verification, err := c.VerifyAudit(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		if err := a.printJSON(verification); err != nil {
+			return err
+		}
+	}
+
+	if !verification.Valid {
+		return fmt.Errorf("audit log tampered with at entry %d: %s", verification.BrokenAt, verification.Reason)
+	}
+
+	if !a.json {
+		fmt.Fprintf(a.stdout, "Audit log verified: %d entries, head %s\n", verification.Entries, verification.Head)
+	}
+
+	return nil
+}
+
+// auditVaultID returns the ID of the vault ref. IDs are taken as is, so the
+// events of deleted vaults can be listed.
+func auditVaultID(ctx context.Context, c *client.Client, ref string) (uint, error) {
+	if id, err := strconv.ParseUint(ref, 10, 0); err == nil {
+		return uint(id), nil
+	}
+
+	vault, err := findVault(ctx, c, ref)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return vault.ID, nil
+}
+
+// parseTime parses the value of a time flag, a local date or an RFC 3339 time.
+func parseTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--%s must be a date as 2006-01-02 or an RFC 3339 time", name)
+	}
+
+	return t, nil
+}
+
+// optionalID formats an ID, empty when it is zero.
+func optionalID(id uint) string {
+	if id == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(uint64(id), 10)
+}
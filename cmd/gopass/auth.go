@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edgardjr92/gopass/internal/cliconfig"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/client"
+)
+
+func (a *app) signup(ctx context.Context, args []string) error {
+	fs := a.flags("signup", "--name <name> --email <email> [--server <url>]")
+	name := fs.String("name", "", "your name")
+	email := fs.String("email", "", "your email")
+	server := fs.String("server", "", "URL of the gopass server")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	if utils.IsBlank(*name) || utils.IsBlank(*email) {
+		fs.Usage()
+		return errUsage
+	}
+
+	password, err := a.readSecret("Master password: ")
+
+	if err != nil {
+		return err
+	}
+
+	if a.terminal {
+		again, err := a.readSecret("Repeat master password: ")
+
+		if err != nil {
+			return err
+		}
+
+		if again != password {
+			return errors.New("passwords don't match")
+		}
+	}
+
+	cfg, err := cliconfig.Load(a.configPath)
+
+	if err != nil {
+		return err
+	}
+
+	c := client.New(cfg.ServerURL(*server), "")
+	id, err := c.Signup(ctx, *name, *email, password)
+
+	if err != nil {
+		return err
+	}
+
+	if err := a.saveLogin(ctx, c, cfg, *email, password); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(map[string]any{"id": id, "email": *email, "server": c.BaseURL})
+	}
+
+	fmt.Fprintf(a.stdout, "Account created, logged in as %s\n", *email)
+
+	return nil
+}
+
+func (a *app) login(ctx context.Context, args []string) error {
+	fs := a.flags("login", "[--email <email>] [--server <url>]")
+	email := fs.String("email", "", "your email, defaults to the last one used")
+	server := fs.String("server", "", "URL of the gopass server")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	cfg, err := cliconfig.Load(a.configPath)
+
+	if err != nil {
+		return err
+	}
+
+	if *email == "" {
+		*email = cfg.Email
+	}
+
+	if utils.IsBlank(*email) {
+		fs.Usage()
+		return errUsage
+	}
+
+	password, err := a.readSecret("Master password: ")
+
+	if err != nil {
+		return err
+	}
+
+	c := client.New(cfg.ServerURL(*server), "")
+
+	if err := a.saveLogin(ctx, c, cfg, *email, password); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(map[string]any{"email": *email, "server": c.BaseURL})
+	}
+
+	fmt.Fprintf(a.stdout, "Logged in as %s\n", *email)
+
+	return nil
+}
+
+// saveLogin logs in and caches the token in the config file.
+func (a *app) saveLogin(ctx context.Context, c *client.Client, cfg *cliconfig.Config, email, password string) error {
+	token, err := c.Login(ctx, email, password)
+
+	if err != nil {
+		return err
+	}
+
+	cfg.Server = c.BaseURL
+	cfg.Email = email
+	cfg.Token = token
+
+	return cfg.Save(a.configPath)
+}
+
+func (a *app) passwd(ctx context.Context, args []string) error {
+	fs := a.flags("passwd", "")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	cfg, err := cliconfig.Load(a.configPath)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := cfg.Client()
+
+	if err != nil {
+		return err
+	}
+
+	current, err := a.readSecret("Current master password: ")
+
+	if err != nil {
+		return err
+	}
+
+	password, err := a.readSecret("New master password: ")
+
+	if err != nil {
+		return err
+	}
+
+	if utils.IsBlank(password) {
+		return errors.New("master password can't be empty")
+	}
+
+	if a.terminal {
+		again, err := a.readSecret("Repeat new master password: ")
+
+		if err != nil {
+			return err
+		}
+
+		if again != password {
+			return errors.New("passwords don't match")
+		}
+	}
+
+	token, err := c.ChangePassword(ctx, cfg.Email, current, password)
+
+	if err != nil {
+		return err
+	}
+
+	cfg.Token = token
+
+	if err := cfg.Save(a.configPath); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(map[string]any{"email": cfg.Email})
+	}
+
+	fmt.Fprintln(a.stdout, "Master password changed, every other session is logged out")
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"strconv"
+)
+
+func (a *app) autofill(ctx context.Context, args []string) error {
+	fs := a.flags("autofill", "<url>")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	matches, err := c.Autofill(ctx, pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(matches)
+	}
+
+	rows := make([][]string, len(matches))
+
+	for i, m := range matches {
+		rows[i] = []string{strconv.FormatUint(uint64(m.ID), 10), m.VaultName, m.Name, m.Username, m.Match}
+	}
+
+	return a.printTable([]string{"ID", "VAULT", "NAME", "USERNAME", "MATCH"}, rows)
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"github.com/edgardjr92/gopass/internal/cliconfig"
+	"github.com/edgardjr92/gopass/pkg/client"
+)
+
+// client returns a client authenticated with the cached token.
+func (a *app) client() (*client.Client, error) {
+	cfg, err := cliconfig.Load(a.configPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.Client()
+}
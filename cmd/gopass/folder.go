@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+)
+
+func (a *app) folderCreate(ctx context.Context, args []string) error {
+	fs := a.flags("folder create", "<name> --vault <vault> [--parent <folder>]")
+	vaultRef := fs.String("vault", "", "vault to create the folder in")
+	parentRef := fs.String("parent", "", "folder to create the folder in, the root of the vault by default")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	if *vaultRef == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	vault, err := findVault(ctx, c, *vaultRef)
+
+	if err != nil {
+		return err
+	}
+
+	parentID, err := findFolderID(ctx, c, vault.ID, *parentRef)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := c.CreateFolder(ctx, vault.ID, pos[0], parentID)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(client.Folder{ID: id, VaultID: vault.ID, ParentID: parentID, Name: pos[0]})
+	}
+
+	fmt.Fprintf(a.stdout, "Created folder %q in vault %q (ID %d)\n", pos[0], vault.Name, id)
+
+	return nil
+}
+
+func (a *app) folderList(ctx context.Context, args []string) error {
+	fs := a.flags("folder ls", "--vault <vault>")
+	vaultRef := fs.String("vault", "", "vault to list the folders of")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *vaultRef == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	vault, err := findVault(ctx, c, *vaultRef)
+
+	if err != nil {
+		return err
+	}
+
+	folders, err := c.Folders(ctx, vault.ID)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(folders)
+	}
+
+	rows := make([][]string, len(folders))
+
+	for i, f := range folders {
+		rows[i] = []string{strconv.FormatUint(uint64(f.ID), 10), folderPath(folders, f.ID)}
+	}
+
+	return a.printTable([]string{"ID", "PATH"}, rows)
+}
+
+func (a *app) folderMove(ctx context.Context, args []string) error {
+	fs := a.flags("folder mv", "<folder> --vault <vault> [--parent <folder>] [--name <name>]")
+	vaultRef := fs.String("vault", "", "vault of the folder")
+	parentRef := fs.String("parent", "", "folder to move the folder into, the root of the vault by default")
+	name := fs.String("name", "", "new name")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	if *vaultRef == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	vault, err := findVault(ctx, c, *vaultRef)
+
+	if err != nil {
+		return err
+	}
+
+	folders, err := c.Folders(ctx, vault.ID)
+
+	if err != nil {
+		return err
+	}
+
+	folder, err := matchFolder(folders, pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	var parentID *uint
+
+	if *parentRef != "" {
+		parent, err := matchFolder(folders, *parentRef)
+
+		if err != nil {
+			return err
+		}
+
+		parentID = &parent.ID
+	}
+
+	if *name == "" {
+		*name = folder.Name
+	}
+
+	if err := c.UpdateFolder(ctx, folder.ID, *name, parentID); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(client.Folder{ID: folder.ID, VaultID: vault.ID, ParentID: parentID, Name: *name})
+	}
+
+	fmt.Fprintf(a.stdout, "Moved folder %q\n", *name)
+
+	return nil
+}
+
+func (a *app) folderRemove(ctx context.Context, args []string) error {
+	fs := a.flags("folder rm", "<folder> --vault <vault>")
+	vaultRef := fs.String("vault", "", "vault of the folder")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	if *vaultRef == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	vault, err := findVault(ctx, c, *vaultRef)
+
+	if err != nil {
+		return err
+	}
+
+	folders, err := c.Folders(ctx, vault.ID)
+
+	if err != nil {
+		return err
+	}
+
+	folder, err := matchFolder(folders, pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	if err := c.DeleteFolder(ctx, folder.ID); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(folder)
+	}
+
+	fmt.Fprintf(a.stdout, "Deleted folder %q\n", folder.Name)
+
+	return nil
+}
+
+// findFolderID finds a folder of a vault by ID, name or path. It returns nil when ref is empty.
+func findFolderID(ctx context.Context, c *client.Client, vaultID uint, ref string) (*uint, error) {
+	if ref == "" {
+		return nil, nil
+	}
+
+	folders, err := c.Folders(ctx, vaultID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := matchFolder(folders, ref)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &folder.ID, nil
+}
+
+// matchFolder finds a folder by ID, by path such as "Work/Cloud", or by name when it is unique.
+func matchFolder(folders []client.Folder, ref string) (*client.Folder, error) {
+	if id, err := strconv.ParseUint(ref, 10, 0); err == nil {
+		for _, f := range folders {
+			if f.ID == uint(id) {
+				return &f, nil
+			}
+		}
+	}
+
+	for _, f := range folders {
+		if folderPath(folders, f.ID) == strings.Trim(ref, "/") {
+			return &f, nil
+		}
+	}
+
+	var matches []client.Folder
+
+	for _, f := range folders {
+		if f.Name == ref {
+			matches = append(matches, f)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("folder %q not found", ref)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, fmt.Errorf("folder %q is ambiguous, use its path or ID", ref)
+	}
+}
+
+// folderPath returns the path of a folder from the root of its vault, such as "Work/Cloud".
+func folderPath(folders []client.Folder, id uint) string {
+	byID := make(map[uint]client.Folder, len(folders))
+
+	for _, f := range folders {
+		byID[f.ID] = f
+	}
+
+	var names []string
+
+	// The depth is bounded by the number of folders, in case the server returns a cycle.
+	for i := 0; i <= len(folders); i++ {
+		f, ok := byID[id]
+
+		if !ok {
+			break
+		}
+
+		names = append([]string{f.Name}, names...)
+
+		if f.ParentID == nil {
+			break
+		}
+
+		id = *f.ParentID
+	}
+
+	return strings.Join(names, "/")
+}
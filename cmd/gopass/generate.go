@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/edgardjr92/gopass/pkg/generator"
+)
+
+// generatorFlags holds the password generator flags, shared by generate and the item commands.
+type generatorFlags struct {
+	length      int
+	noLowercase bool
+	noUppercase bool
+	noDigits    bool
+	noSymbols   bool
+	noAmbiguous bool
+}
+
+func addGeneratorFlags(fs *flag.FlagSet) *generatorFlags {
+	g := &generatorFlags{}
+
+	fs.IntVar(&g.length, "length", generator.DefaultOptions().Length, "length of the generated password")
+	fs.BoolVar(&g.noLowercase, "no-lowercase", false, "leave out lowercase letters")
+	fs.BoolVar(&g.noUppercase, "no-uppercase", false, "leave out uppercase letters")
+	fs.BoolVar(&g.noDigits, "no-digits", false, "leave out digits")
+	fs.BoolVar(&g.noSymbols, "no-symbols", false, "leave out symbols")
+	fs.BoolVar(&g.noAmbiguous, "no-ambiguous", false, "leave out characters that look alike, such as l, 1, O and 0")
+
+	return g
+}
+
+// generate returns a password built with the options from the flags.
+func (g *generatorFlags) generate() (string, error) {
+	return generator.Generate(generator.Options{
+		Length:           g.length,
+		Lowercase:        !g.noLowercase,
+		Uppercase:        !g.noUppercase,
+		Digits:           !g.noDigits,
+		Symbols:          !g.noSymbols,
+		ExcludeAmbiguous: g.noAmbiguous,
+	})
+}
+
+func (a *app) generate(args []string) error {
+	fs := a.flags("generate", "[--length <n>] [--no-symbols] ...")
+	g := addGeneratorFlags(fs)
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	password, err := g.generate()
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(map[string]string{"password": password})
+	}
+
+	fmt.Fprintln(a.stdout, password)
+
+	return nil
+}
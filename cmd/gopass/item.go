@@ -0,0 +1,589 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+	"github.com/edgardjr92/gopass/pkg/secretref"
+	"github.com/edgardjr92/gopass/pkg/sshagent"
+)
+
+// hiddenPassword is shown instead of passwords unless --show is given.
+const hiddenPassword = "********"
+
+const urlMatchUsage = "how the URL matches pages to autofill: domain (default), host, startsWith, exact, regex or never"
+
+func (a *app) itemAdd(ctx context.Context, args []string) error {
+	fs := a.flags("item add", "<name> --vault <vault> [--folder <folder>] [--tag <tag>]... [--username <username>] [--url <url>] [--url-match <strategy>] [--notes <notes>] [--ssh-key <file>] [--totp <uri>] [--generate]")
+	vaultRef := fs.String("vault", "", "vault to add the item to")
+	folderRef := fs.String("folder", "", "folder to add the item to, by ID, name or path")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "tag of the item, can be repeated")
+	username := fs.String("username", "", "username")
+	url := fs.String("url", "", "URL")
+	urlMatch := fs.String("url-match", "", urlMatchUsage)
+	notes := fs.String("notes", "", "notes")
+	sshKeyFile := fs.String("ssh-key", "", "file of an SSH private key to store in the item, its passphrase is the password")
+	totpURI := fs.String("totp", "", "otpauth:// URI of the one-time passwords")
+	generate := fs.Bool("generate", false, "generate the password instead of asking for it")
+	g := addGeneratorFlags(fs)
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	if *vaultRef == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	sshKey, err := readSSHKey(*sshKeyFile)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	vault, err := findVault(ctx, c, *vaultRef)
+
+	if err != nil {
+		return err
+	}
+
+	folderID, err := findFolderID(ctx, c, vault.ID, *folderRef)
+
+	if err != nil {
+		return err
+	}
+
+	var password string
+
+	if sshKey != "" && !*generate {
+		password, err = a.readSecret("Key passphrase: ")
+	} else {
+		password, err = a.newPassword(*generate, g)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	input := client.ItemInput{
+		Name:     pos[0],
+		URL:      *url,
+		URLMatch: *urlMatch,
+		Username: *username,
+		Password: password,
+		Notes:    *notes,
+		SSHKey:   sshKey,
+		TOTP:     *totpURI,
+		FolderID: folderID,
+		Tags:     tags,
+	}
+	id, err := c.CreateItem(ctx, vault.ID, input)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(map[string]any{"id": id, "vaultId": vault.ID})
+	}
+
+	fmt.Fprintf(a.stdout, "Added item %q to vault %q (ID %d)\n", pos[0], vault.Name, id)
+
+	return nil
+}
+
+func (a *app) itemGet(ctx context.Context, args []string) error {
+	fs := a.flags("item get", "<id> [--show] [--field <field>]")
+	show := fs.Bool("show", false, "show the password and the TOTP URI")
+	field := fs.String("field", "", "only print this field: name, username, password, url or notes")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := parseID(pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	item, err := c.Item(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if *field != "" {
+		value, err := itemField(item, *field)
+
+		if err != nil {
+			return err
+		}
+
+		if a.json {
+			return a.printJSON(map[string]string{*field: value})
+		}
+
+		fmt.Fprintln(a.stdout, value)
+
+		return nil
+	}
+
+	if a.json {
+		return a.printJSON(item)
+	}
+
+	password := item.Password
+
+	if !*show && password != "" {
+		password = hiddenPassword
+	}
+
+	rows := [][]string{
+		{"ID", strconv.FormatUint(uint64(item.ID), 10)},
+		{"Name", item.Name},
+		{"Username", item.Username},
+		{"Password", password},
+		{"URL", item.URL},
+		{"Notes", item.Notes},
+	}
+
+	if item.URLMatch != "" {
+		rows = append(rows, []string{"URL match", item.URLMatch})
+	}
+
+	if item.SSHKey != "" {
+		rows = append(rows, []string{"SSH key", sshKeyFingerprint(item)})
+	}
+
+	if item.TOTP != "" {
+		totpURI := item.TOTP
+
+		if !*show {
+			totpURI = hiddenPassword
+		}
+
+		rows = append(rows, []string{"TOTP", totpURI})
+	}
+
+	if item.FolderID != nil {
+		folders, err := c.Folders(ctx, item.VaultID)
+
+		if err != nil {
+			return err
+		}
+
+		rows = append(rows, []string{"Folder", folderPath(folders, *item.FolderID)})
+	}
+
+	if len(item.Tags) > 0 {
+		rows = append(rows, []string{"Tags", strings.Join(item.Tags, ", ")})
+	}
+
+	if item.Favorite {
+		rows = append(rows, []string{"Favorite", "yes"})
+	}
+
+	rows = append(rows, []string{"Updated", item.UpdatedAt.Local().Format(time.RFC3339)})
+
+	return a.printTable([]string{"FIELD", "VALUE"}, rows)
+}
+
+func (a *app) itemEdit(ctx context.Context, args []string) error {
+	fs := a.flags("item edit", "<id> [--name <name>] [--folder <folder>] [--tag <tag>]... [--username <username>] [--url <url>] [--url-match <strategy>] [--notes <notes>] [--ssh-key <file>] [--totp <uri>] [--password | --generate]")
+	fs.String("name", "", "new name")
+	fs.String("folder", "", "folder to move the item to, empty for the root of the vault")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "new tags replacing the current ones, can be repeated, empty to remove them")
+	fs.String("username", "", "new username")
+	fs.String("url", "", "new URL")
+	fs.String("url-match", "", urlMatchUsage)
+	fs.String("notes", "", "new notes")
+	fs.String("ssh-key", "", "file of a new SSH private key")
+	fs.String("totp", "", "new otpauth:// URI, empty to remove it")
+	changePassword := fs.Bool("password", false, "ask for a new password")
+	generate := fs.Bool("generate", false, "generate a new password")
+	g := addGeneratorFlags(fs)
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := parseID(pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	item, err := c.Item(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	input := item.Input()
+	changed := false
+
+	fs.Visit(func(f *flag.Flag) {
+		value := f.Value.String()
+
+		if err != nil {
+			return
+		}
+
+		switch f.Name {
+		case "name":
+			input.Name = value
+		case "username":
+			input.Username = value
+		case "url":
+			input.URL = value
+		case "url-match":
+			input.URLMatch = value
+		case "notes":
+			input.Notes = value
+		case "ssh-key":
+			input.SSHKey, err = readSSHKey(value)
+		case "totp":
+			input.TOTP = value
+		case "folder":
+			input.FolderID, err = findFolderID(ctx, c, item.VaultID, value)
+		case "tag":
+			input.Tags = tags
+		default:
+			return
+		}
+
+		changed = true
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if *changePassword || *generate {
+		if input.Password, err = a.newPassword(*generate, g); err != nil {
+			return err
+		}
+
+		changed = true
+	}
+
+	if !changed {
+		return errors.New("nothing to change, see gopass item edit -h")
+	}
+
+	if err := updateItem(ctx, c, id, item.Input(), input); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(map[string]any{"id": id})
+	}
+
+	fmt.Fprintf(a.stdout, "Updated item %q\n", input.Name)
+
+	return nil
+}
+
+// updateItem saves input, the edit of base. When the item was changed since,
+// the changes are merged and saved again unless both changed the same fields.
+func updateItem(ctx context.Context, c *client.Client, id uint, base, input client.ItemInput) error {
+	err := c.UpdateItem(ctx, id, input)
+
+	var apiErr *client.Error
+
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusPreconditionFailed {
+		return err
+	}
+
+	current, err := c.Item(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	merged, conflicts := client.MergeItemInput(base, input, current.Input())
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("item was changed in the meantime, edit it again: conflicting %s", strings.Join(conflicts, ", "))
+	}
+
+	return c.UpdateItem(ctx, id, merged)
+}
+
+func (a *app) itemRemove(ctx context.Context, args []string) error {
+	fs := a.flags("item rm", "<id> [--yes]")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := parseID(pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	item, err := c.Item(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if !*yes {
+		ok, err := a.confirm(fmt.Sprintf("Delete item %q?", item.Name))
+
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return fmt.Errorf("item %q not deleted", item.Name)
+		}
+	}
+
+	if err := c.DeleteItem(ctx, id); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(map[string]any{"id": id})
+	}
+
+	fmt.Fprintf(a.stdout, "Deleted item %q\n", item.Name)
+
+	return nil
+}
+
+func (a *app) itemList(ctx context.Context, args []string) error {
+	fs := a.flags("item ls", "--vault <vault> [--folder <folder>] [--tag <tag>] [--favorites]")
+	vaultRef := fs.String("vault", "", "vault to list the items of")
+	folderRef := fs.String("folder", "", "only list the items in this folder and its subfolders")
+	tag := fs.String("tag", "", "only list the items with this tag")
+	favorites := fs.Bool("favorites", false, "only list the favorite items")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *vaultRef == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	vault, err := findVault(ctx, c, *vaultRef)
+
+	if err != nil {
+		return err
+	}
+
+	filter := client.ItemFilter{Tag: *tag, Favorite: *favorites}
+
+	if folderID, err := findFolderID(ctx, c, vault.ID, *folderRef); err != nil {
+		return err
+	} else if folderID != nil {
+		filter.FolderID = *folderID
+	}
+
+	items, err := c.ListItems(ctx, vault.ID, filter)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(items)
+	}
+
+	rows := make([][]string, len(items))
+
+	for i, item := range items {
+		rows[i] = []string{strconv.FormatUint(uint64(item.ID), 10), item.Name, item.Username, item.URL}
+	}
+
+	return a.printTable([]string{"ID", "NAME", "USERNAME", "URL"}, rows)
+}
+
+// itemFavorite adds an item to the favorites, or removes it when favorite is false.
+func (a *app) itemFavorite(favorite bool) func(context.Context, []string) error {
+	name := "item fav"
+
+	if !favorite {
+		name = "item unfav"
+	}
+
+	return func(ctx context.Context, args []string) error {
+		fs := a.flags(name, "<id>")
+		pos, err := a.parse(fs, args, 1)
+
+		if err != nil {
+			return err
+		}
+
+		id, err := parseID(pos[0])
+
+		if err != nil {
+			return err
+		}
+
+		c, err := a.client()
+
+		if err != nil {
+			return err
+		}
+
+		if err := c.SetFavorite(ctx, id, favorite); err != nil {
+			return err
+		}
+
+		if a.json {
+			return a.printJSON(map[string]any{"id": id, "favorite": favorite})
+		}
+
+		if favorite {
+			fmt.Fprintf(a.stdout, "Added item %d to favorites\n", id)
+		} else {
+			fmt.Fprintf(a.stdout, "Removed item %d from favorites\n", id)
+		}
+
+		return nil
+	}
+}
+
+func (a *app) totp(ctx context.Context, args []string) error {
+	fs := a.flags("totp", "<id>")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := parseID(pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	code, err := c.TOTP(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(code)
+	}
+
+	// Only the code goes to stdout, so it can be piped.
+	fmt.Fprintln(a.stdout, code.Code)
+	fmt.Fprintf(a.stderr, "Valid for %ds\n", code.Remaining)
+
+	return nil
+}
+
+// newPassword generates a password, or asks for one.
+func (a *app) newPassword(generate bool, g *generatorFlags) (string, error) {
+	if generate {
+		return g.generate()
+	}
+
+	return a.readSecret("Password: ")
+}
+
+// readSSHKey reads the private key file at path, if any.
+func readSSHKey(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// sshKeyFingerprint returns the fingerprint of the SSH key of the item, which isn't secret.
+func sshKeyFingerprint(item *client.Item) string {
+	fingerprint, err := sshagent.KeyItem{PrivateKey: []byte(item.SSHKey), Passphrase: []byte(item.Password)}.Fingerprint()
+
+	if err != nil {
+		return "invalid key: " + err.Error()
+	}
+
+	return fingerprint
+}
+
+func parseID(value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 0)
+
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid item ID %q", value)
+	}
+
+	return uint(id), nil
+}
+
+func itemField(item *client.Item, field string) (string, error) {
+	value, ok := item.Field(field)
+
+	if !ok {
+		return "", fmt.Errorf("unknown field %q, use one of %s", field, strings.Join(secretref.Fields, ", "))
+	}
+
+	return value, nil
+}
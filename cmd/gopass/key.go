@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edgardjr92/gopass/internal/cliconfig"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/client"
+)
+
+func (a *app) keyInit(ctx context.Context, args []string) error {
+	fs := a.flags("key init", "")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	cfg, err := cliconfig.Load(a.configPath)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := cfg.Client()
+
+	if err != nil {
+		return err
+	}
+
+	password, err := a.readSecret("Master password: ")
+
+	if err != nil {
+		return err
+	}
+
+	// The master password is checked by logging in again before it encrypts
+	// the private key, which would otherwise be lost to a typo.
+	if err := a.saveLogin(ctx, c, cfg, cfg.Email, password); err != nil {
+		return err
+	}
+
+	publicKey, err := c.GenerateKeys(ctx, cfg.Email, password)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(publicKey)
+	}
+
+	fmt.Fprintf(a.stdout, "Created the key pair of %s\nFingerprint: %s\n", publicKey.Email, publicKey.Fingerprint)
+
+	return nil
+}
+
+func (a *app) keyShow(ctx context.Context, args []string) error {
+	fs := a.flags("key show", "")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	cfg, err := cliconfig.Load(a.configPath)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := cfg.Client()
+
+	if err != nil {
+		return err
+	}
+
+	return a.printPublicKey(c.LookupPublicKey(ctx, cfg.Email))
+}
+
+func (a *app) keyLookup(ctx context.Context, args []string) error {
+	fs := a.flags("key lookup", "<email>")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	return a.printPublicKey(c.LookupPublicKey(ctx, pos[0]))
+}
+
+func (a *app) keyVerify(ctx context.Context, args []string) error {
+	fs := a.flags("key verify", "<email>")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	// The fingerprint is read rather than given as an argument, so its groups don't need quoting.
+	fingerprint, err := a.readLine("Fingerprint, as given by " + pos[0] + ": ")
+
+	if err != nil {
+		return err
+	}
+
+	if utils.IsBlank(fingerprint) {
+		return errors.New("fingerprint can't be empty")
+	}
+
+	return a.printPublicKey(c.VerifyPublicKey(ctx, pos[0], fingerprint))
+}
+
+func (a *app) keyRotate(ctx context.Context, args []string) error {
+	fs := a.flags("key rotate", "")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	cfg, err := cliconfig.Load(a.configPath)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := cfg.Client()
+
+	if err != nil {
+		return err
+	}
+
+	password, err := a.readSecret("Master password: ")
+
+	if err != nil {
+		return err
+	}
+
+	publicKey, err := c.RotateKeys(ctx, cfg.Email, password)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(publicKey)
+	}
+
+	fmt.Fprintf(a.stdout, "Rotated the key pair of %s, your contacts need to verify it again\nFingerprint: %s\n", publicKey.Email, publicKey.Fingerprint)
+
+	return nil
+}
+
+// printPublicKey prints a public key and whether it's verified, or returns err.
+func (a *app) printPublicKey(publicKey *client.PublicKey, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(publicKey)
+	}
+
+	verified := "not verified"
+
+	if publicKey.Verified {
+		verified = "verified"
+	}
+
+	fmt.Fprintf(a.stdout, "%s (%s)\nFingerprint: %s\n", publicKey.Email, verified, publicKey.Fingerprint)
+
+	return nil
+}
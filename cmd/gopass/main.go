@@ -0,0 +1,352 @@
+// Command gopass is the command-line client for the gopass server.
+//
+// Usage:
+//
+//	gopass <command> [arguments]
+//
+// Run "gopass help" for the list of commands. The server URL and the token
+// from "gopass login" are kept in a config file, readable only by its owner,
+// at $GOPASS_CONFIG or in the user config directory.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/cliconfig"
+	"github.com/edgardjr92/gopass/pkg/client"
+	"golang.org/x/term"
+)
+
+const usage = `Usage: gopass <command> [arguments]
+
+Commands:
+  signup                        create an account and log in
+  login                         log in and cache the token
+  passwd                        change your master password, logging out every other session
+  vault create <name>           create a vault
+  vault ls                      list vaults
+  vault rename <vault> <name>   rename a vault
+  vault rm <vault>              delete a vault and all its items
+  item add <name>               add an item to a vault
+  item get <id>                 show an item
+  item edit <id>                change an item
+  item rm <id>                  delete an item
+  item ls                       list the items of a vault
+  item fav <id>                 add an item to the favorites
+  item unfav <id>               remove an item from the favorites
+  folder create <name>          create a folder in a vault
+  folder ls                     list the folders of a vault
+  folder mv <folder>            rename a folder or move it to another parent
+  folder rm <folder>            delete an empty folder
+  tag ls                        list tags
+  tag rename <tag> <name>       rename a tag on all its items
+  tag rm <tag>                  delete a tag from all its items
+  generate                      generate a random password
+  run -- <command> [args...]    run a command with secrets in its environment
+  render <template>...          render templates reading secrets with {{ secret "gopass://..." }}
+  ssh-agent                     serve the SSH keys of items to ssh
+  totp <id>                     show the current one-time password of an item
+  search <query>...             search vaults and items by name, username, host, tags and notes
+  autofill <url>                list the items to fill in the page at url, best match first
+  audit ls                      list the security events of the account, newest first
+  audit verify                  check that the audit log hasn't been tampered with
+  webhook add <url>             post the events of your vaults to url
+  webhook ls                    list webhooks
+  webhook rm <id>               delete a webhook
+  webhook failed                list the events that couldn't be delivered
+  webhook redeliver <id>        deliver a failed event again
+  webhook discard <id>          forget a failed event
+  send text                     share a text through a link that expires
+  send item <id>                share a snapshot of an item through a link that expires
+  send ls                       list the sends still open
+  send rm <id>                  delete a send before it expires
+  send open <link>              open a send, no account needed
+  key init                      create your key pair for sharing keys with others
+  key show                      show the fingerprint of your public key
+  key lookup <email>            show the public key of a user and whether you verified it
+  key verify <email>            check the fingerprint a user gave you and mark their key verified
+  key rotate                    replace your key pair, wrapping the keys shared with you again
+
+Vaults are given by name or ID. Every command accepts --json to print
+machine-readable output. Run "gopass <command> -h" for its flags.
+`
+
+// errUsage is returned when the command line is invalid. The usage has already been printed.
+var errUsage = errors.New("invalid usage")
+
+// exitError makes gopass exit with the status code of a command it ran.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+type app struct {
+	// stdin is given as is to the commands started by gopass run.
+	stdin  io.Reader
+	in     *bufio.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// configPath is the path of the config file.
+	configPath string
+	// terminal tells whether stdin is a terminal, so secrets can be read without echo.
+	terminal bool
+	// environ returns the environment, as os.Environ.
+	environ func() []string
+	// json is set by the --json flag of each command.
+	json bool
+}
+
+func main() {
+	configPath, err := cliconfig.DefaultPath()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gopass: %v\n", err)
+		os.Exit(1)
+	}
+
+	a := &app{
+		stdin:      os.Stdin,
+		in:         bufio.NewReader(os.Stdin),
+		stdout:     os.Stdout,
+		stderr:     os.Stderr,
+		configPath: configPath,
+		terminal:   term.IsTerminal(int(os.Stdin.Fd())),
+		environ:    os.Environ,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err = a.run(ctx, os.Args[1:])
+	stop()
+
+	if errors.Is(err, errUsage) {
+		os.Exit(2)
+	}
+
+	var exitErr *exitError
+
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.code)
+	}
+
+	var apiErr *client.Error
+
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		err = fmt.Errorf(`%w, run "gopass login" to log in again`, err)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gopass: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command given by args.
+func (a *app) run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(a.stderr, usage)
+		return errUsage
+	}
+
+	switch args[0] {
+	case "signup":
+		return a.signup(ctx, args[1:])
+	case "login":
+		return a.login(ctx, args[1:])
+	case "passwd":
+		return a.passwd(ctx, args[1:])
+	case "vault":
+		return a.subcommand(ctx, "vault", args[1:], map[string]func(context.Context, []string) error{
+			"create": a.vaultCreate,
+			"ls":     a.vaultList,
+			"rename": a.vaultRename,
+			"rm":     a.vaultRemove,
+		})
+	case "item":
+		return a.subcommand(ctx, "item", args[1:], map[string]func(context.Context, []string) error{
+			"add":   a.itemAdd,
+			"get":   a.itemGet,
+			"edit":  a.itemEdit,
+			"rm":    a.itemRemove,
+			"ls":    a.itemList,
+			"fav":   a.itemFavorite(true),
+			"unfav": a.itemFavorite(false),
+		})
+	case "folder":
+		return a.subcommand(ctx, "folder", args[1:], map[string]func(context.Context, []string) error{
+			"create": a.folderCreate,
+			"ls":     a.folderList,
+			"mv":     a.folderMove,
+			"rm":     a.folderRemove,
+		})
+	case "tag":
+		return a.subcommand(ctx, "tag", args[1:], map[string]func(context.Context, []string) error{
+			"ls":     a.tagList,
+			"rename": a.tagRename,
+			"rm":     a.tagRemove,
+		})
+	case "generate":
+		return a.generate(args[1:])
+	case "run":
+		return a.runCommand(ctx, args[1:])
+	case "render":
+		return a.render(ctx, args[1:])
+	case "ssh-agent":
+		return a.sshAgent(ctx, args[1:])
+	case "totp":
+		return a.totp(ctx, args[1:])
+	case "search":
+		return a.search(ctx, args[1:])
+	case "autofill":
+		return a.autofill(ctx, args[1:])
+	case "audit":
+		return a.subcommand(ctx, "audit", args[1:], map[string]func(context.Context, []string) error{
+			"ls":     a.auditList,
+			"verify": a.auditVerify,
+		})
+	case "webhook":
+		return a.subcommand(ctx, "webhook", args[1:], map[string]func(context.Context, []string) error{
+			"add":       a.webhookAdd,
+			"ls":        a.webhookList,
+			"rm":        a.webhookRemove,
+			"failed":    a.webhookFailed,
+			"redeliver": a.webhookRedeliver,
+			"discard":   a.webhookDiscard,
+		})
+	case "send":
+		return a.subcommand(ctx, "send", args[1:], map[string]func(context.Context, []string) error{
+			"text": a.sendText,
+			"item": a.sendItem,
+			"ls":   a.sendList,
+			"rm":   a.sendRemove,
+			"open": a.sendOpen,
+		})
+	case "key":
+		return a.subcommand(ctx, "key", args[1:], map[string]func(context.Context, []string) error{
+			"init":   a.keyInit,
+			"show":   a.keyShow,
+			"lookup": a.keyLookup,
+			"verify": a.keyVerify,
+			"rotate": a.keyRotate,
+		})
+	case "help", "-h", "--help":
+		fmt.Fprint(a.stdout, usage)
+		return nil
+	default:
+		fmt.Fprintf(a.stderr, "gopass: unknown command %q\n\n%s", args[0], usage)
+		return errUsage
+	}
+}
+
+func (a *app) subcommand(ctx context.Context, name string, args []string, commands map[string]func(context.Context, []string) error) error {
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			return cmd(ctx, args[1:])
+		}
+
+		fmt.Fprintf(a.stderr, "gopass: unknown command %q\n\n", name+" "+args[0])
+	}
+
+	fmt.Fprint(a.stderr, usage)
+
+	return errUsage
+}
+
+// flags returns a flag set for a command, with the --json flag every command accepts.
+func (a *app) flags(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.BoolVar(&a.json, "json", false, "print JSON output")
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: gopass %s %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parse parses args with fs, allowing flags after the positional arguments,
+// and checks the number of positional arguments.
+func (a *app) parse(fs *flag.FlagSet, args []string, nargs int) ([]string, error) {
+	var positional []string
+
+	for {
+		// The flag package has already printed the error and the usage.
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+
+		args = fs.Args()
+
+		if len(args) == 0 {
+			break
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if len(positional) != nargs {
+		fs.Usage()
+		return nil, errUsage
+	}
+
+	return positional, nil
+}
+
+// readLine prints prompt and reads a line from stdin.
+func (a *app) readLine(prompt string) (string, error) {
+	fmt.Fprint(a.stderr, prompt)
+
+	line, err := a.in.ReadString('\n')
+
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readSecret prompts for a secret. It isn't echoed when stdin is a terminal;
+// otherwise a line is read from stdin, so secrets can be piped in.
+func (a *app) readSecret(prompt string) (string, error) {
+	if !a.terminal {
+		line, err := a.in.ReadString('\n')
+
+		if err != nil && (err != io.EOF || line == "") {
+			return "", fmt.Errorf("reading %s from stdin: %w", strings.TrimSuffix(strings.ToLower(prompt), ": "), err)
+		}
+
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(a.stderr, prompt)
+	secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(a.stderr)
+
+	return string(secret), err
+}
+
+// confirm asks a yes or no question, defaulting to no.
+func (a *app) confirm(question string) (bool, error) {
+	answer, err := a.readLine(question + " [y/N] ")
+
+	if err != nil {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// printJSON prints v as indented JSON.
+func (a *app) printJSON(v any) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// printTable prints rows aligned in columns under the header.
+func (a *app) printTable(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(sanitize(row), "\t"))
+	}
+
+	return w.Flush()
+}
+
+// sanitize replaces tabs and newlines, which would break the table layout.
+func sanitize(row []string) []string {
+	out := make([]string, len(row))
+
+	for i, cell := range row {
+		out[i] = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(cell)
+	}
+
+	return out
+}
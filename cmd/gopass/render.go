@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/cliconfig"
+	"github.com/edgardjr92/gopass/pkg/secretref"
+)
+
+func (a *app) render(ctx context.Context, args []string) error {
+	fs := a.flags("render", "[-o <file>] <template> | --out-dir <dir> <template>...")
+	out := fs.String("o", "", "file to write the output to, instead of stdout")
+	outDir := fs.String("out-dir", "", "directory to write the outputs to, named after the templates without their .tmpl extension")
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	templates := fs.Args()
+
+	if len(templates) == 0 || (len(templates) > 1 && *outDir == "") || (*out != "" && *outDir != "") {
+		fs.Usage()
+		return errUsage
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	// One renderer for all the templates, so each item is looked up once for the whole batch.
+	renderer := secretref.NewRenderer(c)
+	outputs := make([][]byte, len(templates))
+
+	// Everything is rendered before anything is written, so a broken template leaves no file behind.
+	for i, path := range templates {
+		text, err := os.ReadFile(path)
+
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+
+		if err := renderer.Render(ctx, &buf, filepath.Base(path), string(text), nil); err != nil {
+			return err
+		}
+
+		outputs[i] = buf.Bytes()
+	}
+
+	switch {
+	case *outDir != "":
+		if err := os.MkdirAll(*outDir, 0o700); err != nil {
+			return err
+		}
+
+		for i, path := range templates {
+			name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+
+			if err := cliconfig.WritePrivateFile(filepath.Join(*outDir, name), outputs[i]); err != nil {
+				return err
+			}
+		}
+	case *out != "":
+		return cliconfig.WritePrivateFile(*out, outputs[0])
+	default:
+		_, err := a.stdout.Write(outputs[0])
+		return err
+	}
+
+	return nil
+}
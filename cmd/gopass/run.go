@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/edgardjr92/gopass/pkg/inject"
+	"github.com/edgardjr92/gopass/pkg/secretref"
+)
+
+// stringsFlag is a flag that can be repeated.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func (a *app) runCommand(ctx context.Context, args []string) error {
+	fs := a.flags("run", "[--env-file <file>]... [--no-masking] -- <command> [args...]")
+	var envFiles stringsFlag
+	fs.Var(&envFiles, "env-file", "env file mapping variables to gopass:// references, can be repeated")
+	noMasking := fs.Bool("no-masking", false, "don't conceal the secrets in the output of the command")
+
+	// Flags after the command belong to the command, so they aren't reordered as in the other commands.
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	vars := [][]inject.Var{inject.ParseEnviron(a.environ())}
+
+	for _, path := range envFiles {
+		fileVars, err := readEnvFile(path)
+
+		if err != nil {
+			return err
+		}
+
+		vars = append(vars, fileVars)
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	runner := &inject.Runner{
+		Resolver:  secretref.NewBatchResolver(c),
+		Stdin:     a.stdin,
+		Stdout:    a.stdout,
+		Stderr:    a.stderr,
+		NoMasking: *noMasking,
+	}
+
+	err = runner.Run(ctx, inject.Merge(vars...), fs.Arg(0), fs.Args()[1:]...)
+
+	var exitErr *exec.ExitError
+
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+
+		// The command was killed by a signal.
+		if code < 0 {
+			code = 1
+		}
+
+		return &exitError{code}
+	}
+
+	return err
+}
+
+func readEnvFile(path string) ([]inject.Var, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return inject.ParseEnv(f)
+}
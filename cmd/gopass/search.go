@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+func (a *app) search(ctx context.Context, args []string) error {
+	fs := a.flags("search", "<query>... [--limit <n>]")
+	limit := fs.Uint("limit", 0, "maximum number of results")
+	var query []string
+
+	// The words of the query are given as separate arguments, so they are all collected.
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	for fs.NArg() > 0 {
+		query = append(query, fs.Arg(0))
+
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return errUsage
+		}
+	}
+
+	if len(query) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	results, err := c.Search(ctx, strings.Join(query, " "), *limit)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(results)
+	}
+
+	rows := make([][]string, len(results))
+
+	for i, r := range results {
+		rows[i] = []string{r.Kind, strconv.FormatUint(uint64(r.ID), 10), r.VaultName, r.Name, r.Username, r.URL}
+	}
+
+	return a.printTable([]string{"KIND", "ID", "VAULT", "NAME", "USERNAME", "URL"}, rows)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+)
+
+// sendFlags are the flags of the commands creating sends.
+type sendFlags struct {
+	views    *int
+	expires  *time.Duration
+	password *bool
+}
+
+func addSendFlags(fs *flag.FlagSet) *sendFlags {
+	return &sendFlags{
+		views:    fs.Int("views", 1, "number of times the send can be opened"),
+		expires:  fs.Duration("expires", 24*time.Hour, "how long the send can be opened, at most 720h"),
+		password: fs.Bool("password", false, "prompt for a password to ask of the people opening the send"),
+	}
+}
+
+func (a *app) sendText(ctx context.Context, args []string) error {
+	fs := a.flags("send text", "[--views <n>] [--expires <duration>] [--password]")
+	flags := addSendFlags(fs)
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	text, err := a.readSecret("Text to send: ")
+
+	if err != nil {
+		return err
+	}
+
+	if text == "" {
+		return errors.New("nothing to send")
+	}
+
+	return a.createSend(ctx, c, client.NewTextSend(text), flags)
+}
+
+func (a *app) sendItem(ctx context.Context, args []string) error {
+	fs := a.flags("send item", "<id> [--views <n>] [--expires <duration>] [--password]")
+	flags := addSendFlags(fs)
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := parseID(pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	item, err := c.Item(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return a.createSend(ctx, c, client.NewItemSend(*item), flags)
+}
+
+// createSend creates a send of content and prints its link.
+func (a *app) createSend(ctx context.Context, c *client.Client, content client.SendContent, flags *sendFlags) error {
+	opts := client.SendOptions{MaxViews: *flags.views, ExpiresIn: *flags.expires}
+
+	if *flags.password {
+		password, err := a.readSecret("Access password: ")
+
+		if err != nil {
+			return err
+		}
+
+		if password == "" {
+			return errors.New("access password can't be empty")
+		}
+
+		opts.Password = password
+	}
+
+	created, err := c.CreateSend(ctx, content, opts)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(created)
+	}
+
+	fmt.Fprintf(a.stdout, "Created send %d, open %s until %s\n", created.ID, views(created.MaxViews), created.ExpiresAt.Local().Format(time.RFC3339))
+	fmt.Fprintf(a.stdout, "Link, shown only once: %s\n", created.Link)
+
+	return nil
+}
+
+func (a *app) sendList(ctx context.Context, args []string) error {
+	fs := a.flags("send ls", "")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	sends, err := c.Sends(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(sends)
+	}
+
+	rows := make([][]string, len(sends))
+
+	for i, s := range sends {
+		password := "no"
+
+		if s.HasPassword {
+			password = "yes"
+		}
+
+		rows[i] = []string{
+			strconv.FormatUint(uint64(s.ID), 10),
+			fmt.Sprintf("%d/%d", s.Views, s.MaxViews),
+			password,
+			s.ExpiresAt.Local().Format(time.RFC3339),
+		}
+	}
+
+	return a.printTable([]string{"ID", "VIEWS", "PASSWORD", "EXPIRES"}, rows)
+}
+
+func (a *app) sendRemove(ctx context.Context, args []string) error {
+	fs := a.flags("send rm", "<id>")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseUint(pos[0], 10, 0)
+
+	if err != nil || id == 0 {
+		return fmt.Errorf("invalid send ID %q", pos[0])
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	if err := c.DeleteSend(ctx, uint(id)); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(client.Send{ID: uint(id)})
+	}
+
+	fmt.Fprintf(a.stdout, "Deleted send %d\n", id)
+
+	return nil
+}
+
+func (a *app) sendOpen(ctx context.Context, args []string) error {
+	fs := a.flags("send open", "<link> [--password]")
+	askPassword := fs.Bool("password", false, "prompt for the access password of the send")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	var password string
+
+	if *askPassword {
+		if password, err = a.readSecret("Access password: "); err != nil {
+			return err
+		}
+	}
+
+	// No login is needed: the link holds the server URL and the key.
+	received, err := client.OpenSend(ctx, pos[0], password)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(received)
+	}
+
+	switch {
+	case received.Type == client.SendTypeText:
+		fmt.Fprintln(a.stdout, received.Text)
+	case received.Type == client.SendTypeItem && received.Item != nil:
+		item := received.Item
+		rows := [][]string{
+			{"Name", item.Name},
+			{"Username", item.Username},
+			{"Password", item.Password},
+			{"URL", item.URL},
+			{"Notes", item.Notes},
+		}
+
+		if item.TOTP != "" {
+			rows = append(rows, []string{"TOTP", item.TOTP})
+		}
+
+		if err := a.printTable([]string{"FIELD", "VALUE"}, rows); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown send type %q, update gopass to open it", received.Type)
+	}
+
+	if received.ViewsLeft == 0 {
+		fmt.Fprintln(a.stderr, "This was the last view, the send is now deleted")
+	}
+
+	return nil
+}
+
+// views returns a number of views as text.
+func views(n int) string {
+	if n == 1 {
+		return "once"
+	}
+
+	return fmt.Sprintf("%d times", n)
+}
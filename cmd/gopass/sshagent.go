@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/edgardjr92/gopass/pkg/sshagent"
+)
+
+func (a *app) sshAgent(ctx context.Context, args []string) error {
+	fs := a.flags("ssh-agent", "[--socket <path>] [--vault <vault>]... [--ttl <duration>]")
+	socket := fs.String("socket", defaultAgentSocket(), "path of the agent socket")
+	var vaults stringsFlag
+	fs.Var(&vaults, "vault", "vault to serve the SSH keys of, can be repeated, all vaults by default")
+	ttl := fs.Duration("ttl", 0, "how long a key stays unlocked after a confirmation, 0 to confirm every use")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	agent := sshagent.New(&sshagent.VaultSource{Store: c, Vaults: vaults}, sshagent.Options{
+		Confirm: func(ctx context.Context, key sshagent.Key) (bool, error) {
+			return a.confirm(fmt.Sprintf("Allow use of SSH key %s?", key))
+		},
+		TTL: *ttl,
+	})
+
+	l, err := sshagent.Listen(*socket)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		if err := a.printJSON(map[string]string{"socket": *socket}); err != nil {
+			l.Close()
+			return err
+		}
+	} else {
+		fmt.Fprintf(a.stdout, "SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", *socket)
+	}
+
+	fmt.Fprintln(a.stderr, "gopass ssh-agent is running, press Ctrl-C to stop it")
+
+	return sshagent.Serve(ctx, l, agent)
+}
+
+// defaultAgentSocket returns the socket path in the runtime directory of the user, or in the temp directory.
+func defaultAgentSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "gopass", "ssh-agent.sock")
+	}
+
+	return filepath.Join(os.TempDir(), "gopass-"+strconv.Itoa(os.Getuid()), "ssh-agent.sock")
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+)
+
+func (a *app) tagList(ctx context.Context, args []string) error {
+	fs := a.flags("tag ls", "")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	tags, err := c.Tags(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(tags)
+	}
+
+	rows := make([][]string, len(tags))
+
+	for i, t := range tags {
+		rows[i] = []string{strconv.FormatUint(uint64(t.ID), 10), t.Name}
+	}
+
+	return a.printTable([]string{"ID", "NAME"}, rows)
+}
+
+func (a *app) tagRename(ctx context.Context, args []string) error {
+	fs := a.flags("tag rename", "<tag> <new name>")
+	pos, err := a.parse(fs, args, 2)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	tag, err := findTag(ctx, c, pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	if err := c.RenameTag(ctx, tag.ID, pos[1]); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(client.Tag{ID: tag.ID, Name: pos[1]})
+	}
+
+	fmt.Fprintf(a.stdout, "Renamed tag %q to %q\n", tag.Name, pos[1])
+
+	return nil
+}
+
+func (a *app) tagRemove(ctx context.Context, args []string) error {
+	fs := a.flags("tag rm", "<tag>")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	tag, err := findTag(ctx, c, pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	if err := c.DeleteTag(ctx, tag.ID); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(tag)
+	}
+
+	fmt.Fprintf(a.stdout, "Deleted tag %q\n", tag.Name)
+
+	return nil
+}
+
+// findTag finds a tag by name or ID. Names come first, as a tag may be named like a number.
+func findTag(ctx context.Context, c *client.Client, ref string) (*client.Tag, error) {
+	tags, err := c.Tags(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tags {
+		if t.Name == ref {
+			return &t, nil
+		}
+	}
+
+	if id, err := strconv.ParseUint(ref, 10, 0); err == nil {
+		for _, t := range tags {
+			if t.ID == uint(id) {
+				return &t, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("tag %q not found", ref)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+)
+
+func (a *app) vaultCreate(ctx context.Context, args []string) error {
+	fs := a.flags("vault create", "<name>")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	id, err := c.CreateVault(ctx, pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(client.Vault{ID: id, Name: pos[0]})
+	}
+
+	fmt.Fprintf(a.stdout, "Created vault %q (ID %d)\n", pos[0], id)
+
+	return nil
+}
+
+func (a *app) vaultList(ctx context.Context, args []string) error {
+	fs := a.flags("vault ls", "")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	vaults, err := c.Vaults(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(vaults)
+	}
+
+	rows := make([][]string, len(vaults))
+
+	for i, v := range vaults {
+		rows[i] = []string{strconv.FormatUint(uint64(v.ID), 10), v.Name}
+	}
+
+	return a.printTable([]string{"ID", "NAME"}, rows)
+}
+
+func (a *app) vaultRename(ctx context.Context, args []string) error {
+	fs := a.flags("vault rename", "<vault> <new name>")
+	pos, err := a.parse(fs, args, 2)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	vault, err := findVault(ctx, c, pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	if err := c.RenameVault(ctx, vault.ID, pos[1], vault.Revision); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(client.Vault{ID: vault.ID, Name: pos[1]})
+	}
+
+	fmt.Fprintf(a.stdout, "Renamed vault %q to %q\n", vault.Name, pos[1])
+
+	return nil
+}
+
+func (a *app) vaultRemove(ctx context.Context, args []string) error {
+	fs := a.flags("vault rm", "<vault> [--yes]")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	vault, err := findVault(ctx, c, pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	if !*yes {
+		ok, err := a.confirm(fmt.Sprintf("Delete vault %q and all its items?", vault.Name))
+
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return fmt.Errorf("vault %q not deleted", vault.Name)
+		}
+	}
+
+	if err := c.DeleteVault(ctx, vault.ID); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(vault)
+	}
+
+	fmt.Fprintf(a.stdout, "Deleted vault %q\n", vault.Name)
+
+	return nil
+}
+
+// findVault finds a vault by ID or name. Names are matched case insensitively
+// when no vault has the exact name.
+func findVault(ctx context.Context, c *client.Client, ref string) (*client.Vault, error) {
+	vaults, err := c.Vaults(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if id, err := strconv.ParseUint(ref, 10, 0); err == nil {
+		for _, v := range vaults {
+			if v.ID == uint(id) {
+				return &v, nil
+			}
+		}
+	}
+
+	for _, v := range vaults {
+		if v.Name == ref {
+			return &v, nil
+		}
+	}
+
+	var matches []client.Vault
+
+	for _, v := range vaults {
+		if strings.EqualFold(v.Name, ref) {
+			matches = append(matches, v)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("vault %q not found", ref)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, fmt.Errorf("vault %q is ambiguous, use its ID", ref)
+	}
+}
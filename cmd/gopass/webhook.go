@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+)
+
+func (a *app) webhookAdd(ctx context.Context, args []string) error {
+	fs := a.flags("webhook add", "<url> [--event <type>]... [--vault <vault>]")
+	var events stringsFlag
+	fs.Var(&events, "event", "type of the events to post, such as item.updated, can be repeated, all by default")
+	vaultRef := fs.String("vault", "", "vault to post the events of, all by default")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	var vaultID *uint
+
+	if *vaultRef != "" {
+		vault, err := findVault(ctx, c, *vaultRef)
+
+		if err != nil {
+			return err
+		}
+
+		vaultID = &vault.ID
+	}
+
+	webhook, err := c.CreateWebhook(ctx, pos[0], events, vaultID)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(webhook)
+	}
+
+	fmt.Fprintf(a.stdout, "Created webhook %d posting to %s\n", webhook.ID, webhook.URL)
+	fmt.Fprintf(a.stdout, "Signing secret, shown only once: %s\n", webhook.Secret)
+
+	return nil
+}
+
+func (a *app) webhookList(ctx context.Context, args []string) error {
+	fs := a.flags("webhook ls", "")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := c.Webhooks(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(webhooks)
+	}
+
+	rows := make([][]string, len(webhooks))
+
+	for i, w := range webhooks {
+		events, vault := "all", "all"
+
+		if len(w.Events) > 0 {
+			events = strings.Join(w.Events, ",")
+		}
+
+		if w.VaultID != nil {
+			vault = strconv.FormatUint(uint64(*w.VaultID), 10)
+		}
+
+		rows[i] = []string{strconv.FormatUint(uint64(w.ID), 10), w.URL, events, vault}
+	}
+
+	return a.printTable([]string{"ID", "URL", "EVENTS", "VAULT"}, rows)
+}
+
+func (a *app) webhookRemove(ctx context.Context, args []string) error {
+	fs := a.flags("webhook rm", "<id>")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := parseWebhookID("webhook", pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	if err := c.DeleteWebhook(ctx, id); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(client.Webhook{ID: id})
+	}
+
+	fmt.Fprintf(a.stdout, "Deleted webhook %d\n", id)
+
+	return nil
+}
+
+func (a *app) webhookFailed(ctx context.Context, args []string) error {
+	fs := a.flags("webhook failed", "")
+
+	if _, err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	deadLetters, err := c.WebhookDeadLetters(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(deadLetters)
+	}
+
+	rows := make([][]string, len(deadLetters))
+
+	for i, d := range deadLetters {
+		rows[i] = []string{
+			strconv.FormatUint(uint64(d.ID), 10),
+			strconv.FormatUint(uint64(d.WebhookID), 10),
+			d.EventType,
+			strconv.Itoa(d.Attempts),
+			d.LastError,
+		}
+	}
+
+	return a.printTable([]string{"ID", "WEBHOOK", "EVENT", "ATTEMPTS", "ERROR"}, rows)
+}
+
+func (a *app) webhookRedeliver(ctx context.Context, args []string) error {
+	fs := a.flags("webhook redeliver", "<id>")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := parseWebhookID("dead letter", pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	if err := c.RedeliverWebhook(ctx, id); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(client.WebhookDeadLetter{ID: id})
+	}
+
+	fmt.Fprintf(a.stdout, "Queued dead letter %d for redelivery\n", id)
+
+	return nil
+}
+
+func (a *app) webhookDiscard(ctx context.Context, args []string) error {
+	fs := a.flags("webhook discard", "<id>")
+	pos, err := a.parse(fs, args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := parseWebhookID("dead letter", pos[0])
+
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+
+	if err != nil {
+		return err
+	}
+
+	if err := c.DiscardWebhookDeadLetter(ctx, id); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(client.WebhookDeadLetter{ID: id})
+	}
+
+	fmt.Fprintf(a.stdout, "Discarded dead letter %d\n", id)
+
+	return nil
+}
+
+// parseWebhookID parses the ID of a webhook or of a dead letter, as told by kind.
+func parseWebhookID(kind, value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 0)
+
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid %s ID %q", kind, value)
+	}
+
+	return uint(id), nil
+}
@@ -0,0 +1,283 @@
+// Package backup defines the portable format of encrypted full account exports.
+//
+// A backup is a JSON envelope:
+//
+//	{
+//	  "format": "gopass-backup",
+//	  "version": 3,
+//	  "kdf": {"algorithm": "argon2id", "salt": "<base64>", "time": 3, "memory": 65536, "threads": 4},
+//	  "cipher": "xchacha20-poly1305",
+//	  "nonce": "<base64>",
+//	  "ciphertext": "<base64>"
+//	}
+//
+// The encryption key is derived from a passphrase with Argon2id using the
+// parameters in "kdf" (memory is in KiB), which readers bound by MaxKDFTime,
+// MaxKDFMemory and MaxKDFThreads. The ciphertext is the payload
+// encrypted with XChaCha20-Poly1305, with the JSON encoding of the format,
+// version, kdf and cipher fields as additional data, so none of them can be
+// changed without the backup failing to decrypt.
+//
+// The payload is a JSON document with an integrity manifest and the vaults:
+//
+//	{
+//	  "manifest": {"createdAt": "<RFC 3339>", "vaults": 1, "items": 1, "sha256": "<hex>"},
+//	  "vaults": [{
+//	    "id": 1, "name": "Work", "createdAt": "<RFC 3339>", "updatedAt": "<RFC 3339>",
+//	    "folders": [{"id": 1, "name": "Cloud"}, {"id": 2, "name": "AWS", "parentId": 1}],
+//	    "items": [{
+//	      "id": 1, "name": "GitHub", "url": "https://github.com", "username": "octocat",
+//	      "password": "...", "notes": "...", "sshKey": "...", "totp": "otpauth://...",
+//	      "urlMatch": "host", "folderId": 1, "tags": ["dev"], "favorite": true,
+//	      "createdAt": "<RFC 3339>", "updatedAt": "<RFC 3339>"
+//	    }]
+//	  }]
+//	}
+//
+// The manifest holds the number of vaults and items and the hex SHA-256 of the
+// JSON encoding of the vaults array, which are checked when a backup is read.
+// IDs are the ones of the exporting account. They are kept so restores can
+// report how they were remapped, and so items and folders can refer to their
+// folder within the backup.
+//
+// Readers reject versions newer than the one they implement. Changes that
+// older readers can't safely ignore must increase the version. Version 2
+// added the optional "sshKey" and "totp" of items, which version 1 readers would drop.
+// Version 3 added the optional "folders" of vaults and the "urlMatch",
+// "folderId", "tags" and "favorite" of items. Fields added by a version are omitted when empty, so
+// backups of older versions keep decoding and matching their manifest.
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/seal"
+)
+
+const (
+	// Format identifies gopass backups.
+	Format = "gopass-backup"
+	// Version is the latest version of the format.
+	Version = 3
+
+	kdfAlgorithm = "argon2id"
+	cipherName   = "xchacha20-poly1305"
+)
+
+// Limits on the key derivation parameters of a backup. They come from the
+// unauthenticated envelope and bound the memory and the time Decrypt spends
+// before the passphrase can be checked.
+const (
+	// MaxKDFTime is the largest number of Argon2 passes.
+	MaxKDFTime = 16
+	// MaxKDFMemory is the largest Argon2 memory cost, in KiB.
+	MaxKDFMemory = 1 << 20
+	// MaxKDFThreads is the largest number of Argon2 lanes.
+	MaxKDFThreads = 16
+)
+
+var (
+	// ErrInvalidFormat is returned when the data is not a gopass backup.
+	ErrInvalidFormat = errors.New("not a gopass backup")
+	// ErrUnsupportedVersion is returned for backups newer than Version.
+	ErrUnsupportedVersion = errors.New("unsupported backup version")
+	// ErrInvalidPassphrase is returned when the backup can't be decrypted with the passphrase.
+	ErrInvalidPassphrase = errors.New("invalid passphrase or corrupted backup")
+	// ErrCorrupted is returned when the payload doesn't match its manifest.
+	ErrCorrupted = errors.New("backup does not match its manifest")
+	// ErrKDFLimits is returned when the key derivation parameters exceed MaxKDFTime, MaxKDFMemory or MaxKDFThreads.
+	ErrKDFLimits = errors.New("backup key derivation parameters exceed the supported limits")
+)
+
+type Backup struct {
+	Manifest Manifest `json:"manifest"`
+	Vaults   []Vault  `json:"vaults"`
+}
+
+type Manifest struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Vaults    int       `json:"vaults"`
+	Items     int       `json:"items"`
+	SHA256    string    `json:"sha256"`
+}
+
+type Vault struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Folders   []Folder  `json:"folders,omitempty"`
+	Items     []Item    `json:"items"`
+}
+
+// Folder is a folder of a vault. ParentID is the ID of its parent folder in the
+// backup, nil for the folders at the root of the vault.
+type Folder struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	ParentID *uint  `json:"parentId,omitempty"`
+}
+
+type Item struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Url       string    `json:"url"`
+	UrlMatch  string    `json:"urlMatch,omitempty"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password"`
+	Notes     string    `json:"notes"`
+	SSHKey    string    `json:"sshKey,omitempty"`
+	TOTP      string    `json:"totp,omitempty"`
+	FolderID  *uint     `json:"folderId,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Favorite  bool      `json:"favorite,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type envelope struct {
+	header
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type header struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	KDF     kdf    `json:"kdf"`
+	Cipher  string `json:"cipher"`
+}
+
+type kdf struct {
+	Algorithm string `json:"algorithm"`
+	Salt      []byte `json:"salt"`
+	seal.KDFParams
+}
+
+// Encrypt builds a backup of vaults and encrypts it with a key derived from passphrase.
+func Encrypt(vaults []Vault, createdAt time.Time, passphrase string, params seal.KDFParams) ([]byte, error) {
+	if vaults == nil {
+		vaults = []Vault{}
+	}
+
+	manifest, err := newManifest(vaults, createdAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(Backup{Manifest: manifest, Vaults: vaults})
+
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := seal.NewSalt()
+
+	if err != nil {
+		return nil, err
+	}
+
+	h := header{
+		Format:  Format,
+		Version: Version,
+		KDF:     kdf{Algorithm: kdfAlgorithm, Salt: salt, KDFParams: params},
+		Cipher:  cipherName,
+	}
+
+	additionalData, err := json.Marshal(h)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := seal.DeriveKey(passphrase, salt, params)
+	nonce, ciphertext, err := seal.Seal(key, payload, additionalData)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(envelope{header: h, Nonce: nonce, Ciphertext: ciphertext}, "", "  ")
+}
+
+// Decrypt decrypts a backup and checks it against its manifest.
+func Decrypt(data []byte, passphrase string) (*Backup, error) {
+	var env envelope
+
+	if err := json.Unmarshal(data, &env); err != nil || env.Format != Format {
+		return nil, ErrInvalidFormat
+	}
+
+	if env.Version > Version {
+		return nil, ErrUnsupportedVersion
+	}
+
+	if env.KDF.Algorithm != kdfAlgorithm || env.Cipher != cipherName {
+		return nil, ErrInvalidFormat
+	}
+
+	if env.KDF.Time == 0 || env.KDF.Memory == 0 || env.KDF.Threads == 0 {
+		return nil, ErrInvalidFormat
+	}
+
+	if env.KDF.Time > MaxKDFTime || env.KDF.Memory > MaxKDFMemory || env.KDF.Threads > MaxKDFThreads {
+		return nil, ErrKDFLimits
+	}
+
+	additionalData, err := json.Marshal(env.header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := seal.DeriveKey(passphrase, env.KDF.Salt, env.KDF.KDFParams)
+	payload, err := seal.Open(key, env.Nonce, env.Ciphertext, additionalData)
+
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	var b Backup
+
+	if err := json.Unmarshal(payload, &b); err != nil {
+		return nil, ErrCorrupted
+	}
+
+	expected, err := newManifest(b.Vaults, b.Manifest.CreatedAt)
+
+	if err != nil || expected != b.Manifest {
+		return nil, ErrCorrupted
+	}
+
+	return &b, nil
+}
+
+func newManifest(vaults []Vault, createdAt time.Time) (Manifest, error) {
+	if vaults == nil {
+		vaults = []Vault{}
+	}
+
+	encoded, err := json.Marshal(vaults)
+
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	sum := sha256.Sum256(encoded)
+	manifest := Manifest{
+		CreatedAt: createdAt,
+		Vaults:    len(vaults),
+		SHA256:    hex.EncodeToString(sum[:]),
+	}
+
+	for _, vault := range vaults {
+		manifest.Items += len(vault.Items)
+	}
+
+	return manifest, nil
+}
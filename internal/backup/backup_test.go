@@ -0,0 +1,168 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/seal"
+	"github.com/stretchr/testify/assert"
+)
+
+var testParams = seal.KDFParams{Time: 1, Memory: 64, Threads: 1}
+
+func testVaults() []Vault {
+	at := time.Date(2023, 5, 6, 10, 0, 0, 0, time.UTC)
+
+	return []Vault{
+		{
+			ID: 1, Name: "Work", CreatedAt: at, UpdatedAt: at,
+			Items: []Item{
+				{ID: 10, Name: "GitHub", Url: "https://github.com", Username: "octocat", Password: "gh-pass", Notes: "2fa", CreatedAt: at, UpdatedAt: at},
+				{ID: 11, Name: "AWS", Username: "admin", Password: "aws-pass", CreatedAt: at, UpdatedAt: at},
+			},
+		},
+		{ID: 2, Name: "Empty", CreatedAt: at, UpdatedAt: at, Items: []Item{}},
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	// given
+	createdAt := time.Date(2023, 5, 7, 0, 0, 0, 0, time.UTC)
+
+	// when
+	data, err := Encrypt(testVaults(), createdAt, "correct horse battery staple", testParams)
+	assert.Nil(t, err)
+
+	actual, err := Decrypt(data, "correct horse battery staple")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, testVaults(), actual.Vaults)
+	assert.Equal(t, createdAt, actual.Manifest.CreatedAt)
+	assert.Equal(t, 2, actual.Manifest.Vaults)
+	assert.Equal(t, 2, actual.Manifest.Items)
+	assert.Len(t, actual.Manifest.SHA256, 64)
+
+	assert.NotContains(t, string(data), "gh-pass")
+	assert.Contains(t, string(data), `"format": "gopass-backup"`)
+	assert.Contains(t, string(data), `"version": 3`)
+}
+
+func TestEncryptDecryptOrganization(t *testing.T) {
+	// given
+	at := time.Date(2023, 5, 6, 10, 0, 0, 0, time.UTC)
+	cloud, aws := uint(1), uint(2)
+	vaults := []Vault{
+		{
+			ID: 1, Name: "Work", CreatedAt: at, UpdatedAt: at,
+			Folders: []Folder{{ID: cloud, Name: "Cloud"}, {ID: aws, Name: "AWS", ParentID: &cloud}},
+			Items: []Item{
+				{ID: 10, Name: "Console", Url: "https://console.aws.amazon.com", UrlMatch: "host", Password: "aws-pass", FolderID: &aws, Tags: []string{"ops", "prod"}, Favorite: true, CreatedAt: at, UpdatedAt: at},
+				{ID: 11, Name: "Wiki", Password: "wiki-pass", CreatedAt: at, UpdatedAt: at},
+			},
+		},
+	}
+
+	// when
+	data, err := Encrypt(vaults, at, "correct horse battery staple", testParams)
+	assert.Nil(t, err)
+
+	actual, err := Decrypt(data, "correct horse battery staple")
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, vaults, actual.Vaults)
+}
+
+func TestDecryptOlderVersions(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		version := version
+		t.Run(fmt.Sprintf("version %d", version), func(t *testing.T) {
+			// given
+			at := time.Date(2023, 5, 6, 10, 0, 0, 0, time.UTC)
+			vaults := `[{"id":1,"name":"Work","createdAt":"2023-05-06T10:00:00Z","updatedAt":"2023-05-06T10:00:00Z",` +
+				`"items":[{"id":10,"name":"GitHub","url":"https://github.com","username":"octocat","password":"gh-pass","notes":"",` +
+				`"createdAt":"2023-05-06T10:00:00Z","updatedAt":"2023-05-06T10:00:00Z"}]}]`
+			sum := sha256.Sum256([]byte(vaults))
+			payload := `{"manifest":{"createdAt":"2023-05-06T10:00:00Z","vaults":1,"items":1,"sha256":"` + hex.EncodeToString(sum[:]) + `"},"vaults":` + vaults + `}`
+
+			salt, _ := seal.NewSalt()
+			h := header{Format: Format, Version: version, KDF: kdf{Algorithm: kdfAlgorithm, Salt: salt, KDFParams: testParams}, Cipher: cipherName}
+			additionalData, _ := json.Marshal(h)
+			nonce, ciphertext, _ := seal.Seal(seal.DeriveKey("passphrase", salt, testParams), []byte(payload), additionalData)
+			data, _ := json.Marshal(envelope{header: h, Nonce: nonce, Ciphertext: ciphertext})
+
+			// when
+			actual, err := Decrypt(data, "passphrase")
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, []Vault{{
+				ID: 1, Name: "Work", CreatedAt: at, UpdatedAt: at,
+				Items: []Item{{ID: 10, Name: "GitHub", Url: "https://github.com", Username: "octocat", Password: "gh-pass", CreatedAt: at, UpdatedAt: at}},
+			}}, actual.Vaults)
+		})
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	data, err := Encrypt(testVaults(), time.Now().UTC(), "correct horse battery staple", testParams)
+	assert.Nil(t, err)
+
+	tamper := func(fn func(env map[string]interface{})) []byte {
+		env := map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal(data, &env))
+		fn(env)
+		tampered, err := json.Marshal(env)
+		assert.Nil(t, err)
+		return tampered
+	}
+
+	testCases := []struct {
+		name       string
+		data       []byte
+		passphrase string
+		err        error
+	}{
+		{"wrong passphrase", data, "wrong passphrase!", ErrInvalidPassphrase},
+		{"not json", []byte("not a backup"), "correct horse battery staple", ErrInvalidFormat},
+		{"other format", tamper(func(env map[string]interface{}) { env["format"] = "other" }), "correct horse battery staple", ErrInvalidFormat},
+		{"newer version", tamper(func(env map[string]interface{}) { env["version"] = Version + 1 }), "correct horse battery staple", ErrUnsupportedVersion},
+		{"tampered kdf", tamper(func(env map[string]interface{}) { env["kdf"].(map[string]interface{})["time"] = 2 }), "correct horse battery staple", ErrInvalidPassphrase},
+		{"kdf time over limit", tamper(func(env map[string]interface{}) { env["kdf"].(map[string]interface{})["time"] = MaxKDFTime + 1 }), "correct horse battery staple", ErrKDFLimits},
+		{"kdf memory over limit", tamper(func(env map[string]interface{}) { env["kdf"].(map[string]interface{})["memory"] = MaxKDFMemory + 1 }), "correct horse battery staple", ErrKDFLimits},
+		{"kdf threads over limit", tamper(func(env map[string]interface{}) { env["kdf"].(map[string]interface{})["threads"] = MaxKDFThreads + 1 }), "correct horse battery staple", ErrKDFLimits},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			actual, err := Decrypt(tc.data, tc.passphrase)
+
+			// then
+			assert.Nil(t, actual)
+			assert.Equal(t, tc.err, err)
+		})
+	}
+
+	t.Run("manifest mismatch", func(t *testing.T) {
+		// given
+		payload, _ := json.Marshal(Backup{Manifest: Manifest{Vaults: 5}, Vaults: testVaults()})
+		salt, _ := seal.NewSalt()
+		h := header{Format: Format, Version: Version, KDF: kdf{Algorithm: kdfAlgorithm, Salt: salt, KDFParams: testParams}, Cipher: cipherName}
+		additionalData, _ := json.Marshal(h)
+		nonce, ciphertext, _ := seal.Seal(seal.DeriveKey("passphrase", salt, testParams), payload, additionalData)
+		data, _ := json.Marshal(envelope{header: h, Nonce: nonce, Ciphertext: ciphertext})
+
+		// when
+		actual, err := Decrypt(data, "passphrase")
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, ErrCorrupted, err)
+	})
+}
@@ -64,3 +64,55 @@ func BadRequestError(message string) *badRequestError {
 		},
 	}
 }
+
+type notFoundError struct {
+	ApplicationError
+}
+
+func NotFoundError(message string) *notFoundError {
+	return &notFoundError{
+		ApplicationError: ApplicationError{
+			code:    404,
+			message: message,
+		},
+	}
+}
+
+type preconditionFailedError struct {
+	ApplicationError
+}
+
+func PreconditionFailedError(message string) *preconditionFailedError {
+	return &preconditionFailedError{
+		ApplicationError: ApplicationError{
+			code:    412,
+			message: message,
+		},
+	}
+}
+
+type preconditionRequiredError struct {
+	ApplicationError
+}
+
+func PreconditionRequiredError(message string) *preconditionRequiredError {
+	return &preconditionRequiredError{
+		ApplicationError: ApplicationError{
+			code:    428,
+			message: message,
+		},
+	}
+}
+
+type forbiddenError struct {
+	ApplicationError
+}
+
+func ForbiddenError(message string) *forbiddenError {
+	return &forbiddenError{
+		ApplicationError: ApplicationError{
+			code:    403,
+			message: message,
+		},
+	}
+}
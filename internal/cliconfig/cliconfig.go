@@ -0,0 +1,127 @@
+// Package cliconfig stores the settings shared by the gopass command-line tools,
+// such as the server URL and the token from "gopass login".
+package cliconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+)
+
+// DefaultServer is used when neither a flag, $GOPASS_SERVER nor the config file set a server.
+const DefaultServer = "http://localhost:8080"
+
+type Config struct {
+	Server string `json:"server"`
+	Email  string `json:"email,omitempty"`
+	Token  string `json:"token,omitempty"`
+}
+
+// DefaultPath returns $GOPASS_CONFIG, or gopass/config.json in the user config directory.
+func DefaultPath() (string, error) {
+	if path := os.Getenv("GOPASS_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "gopass", "config.json"), nil
+}
+
+// Load reads the config file. A missing file gives an empty config.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(path)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Save writes the config file. Since it holds the token, it is only readable by its owner.
+func (c *Config) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	return WritePrivateFile(path, append(data, '\n'))
+}
+
+// WritePrivateFile replaces the file at path with one only readable by its owner.
+// The data is written to a temporary file first, so the file is never left half written.
+func WritePrivateFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// ServerURL returns the server from the flag, the environment or the config, in this order.
+func (c *Config) ServerURL(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+
+	if env := os.Getenv("GOPASS_SERVER"); env != "" {
+		return env
+	}
+
+	if c.Server != "" {
+		return c.Server
+	}
+
+	return DefaultServer
+}
+
+// Client returns a client authenticated with the cached token.
+func (c *Config) Client() (*client.Client, error) {
+	if c.Token == "" {
+		return nil, errors.New(`not logged in, run "gopass login" first`)
+	}
+
+	return client.New(c.ServerURL(""), c.Token), nil
+}
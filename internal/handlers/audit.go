@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type auditHandler struct {
+	service services.IAuditService
+}
+
+func NewAuditHandler(service services.IAuditService) *auditHandler {
+	return &auditHandler{service}
+}
+
+// Register registers the audit routes on mux.
+func (h *auditHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/audit", h.Events)
+	mux.HandleFunc("/audit/verify", h.Verify)
+}
+
+// Events handles GET /audit?vaultId=&from=&to=&limit=.
+// It returns the audit events of the authenticated user, newest first, only
+// the ones of the vault or within the time range when those are given.
+func (h *auditHandler) Events(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	vaultID, err := queryUint(r, "vaultId")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	from, err := queryTime(r, "from")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	to, err := queryTime(r, "to")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	limit, err := queryUint(r, "limit")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	filter := models.AuditFilter{VaultID: vaultID, From: from, To: to, Limit: int(limit)}
+	events, err := h.service.Events(r.Context(), filter)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, events)
+}
+
+// Verify handles GET /audit/verify.
+// It checks the hash chain of the audit log.
+func (h *auditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	verification, err := h.service.Verify(r.Context())
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, verification)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAuditHandler(t *testing.T) {
+	serviceMock := &mocks.AuditServiceMock{}
+
+	handler := NewAuditHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestAuditRoutes(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	from := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	events := []models.AuditEvent{{
+		Seq: 2, Action: models.AuditItemRead, Outcome: models.AuditSuccess, UserID: 10, VaultID: 1, ItemID: 5,
+		IP: "203.0.113.7", CreatedAt: from, PrevHash: "aa", Hash: "bb",
+	}}
+
+	testCases := []struct {
+		name     string
+		method   string
+		target   string
+		status   int
+		response string
+	}{
+		{"events", http.MethodGet, "/audit?vaultId=1&from=2023-05-01T00:00:00Z&to=2023-06-01T00:00:00Z&limit=20", http.StatusOK,
+			`[{"seq":2,"action":"item.read","outcome":"success","userId":10,"vaultId":1,"itemId":5,"ip":"203.0.113.7",` +
+				`"createdAt":"2023-05-01T00:00:00Z","prevHash":"aa","hash":"bb"}]`},
+		{"all events", http.MethodGet, "/audit", http.StatusOK, `[]`},
+		{"invalid time", http.MethodGet, "/audit?from=yesterday", http.StatusBadRequest, `{"message":"from must be an RFC 3339 time"}`},
+		{"invalid limit", http.MethodGet, "/audit?limit=-1", http.StatusBadRequest, `{"message":"limit must be a positive integer"}`},
+		{"verify", http.MethodGet, "/audit/verify", http.StatusOK, `{"valid":false,"entries":1,"head":"aa","brokenAt":2,"reason":"hash doesn't match the entry"}`},
+		{"method not allowed", http.MethodDelete, "/audit", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.AuditServiceMock{}
+			serviceMock.On("Events", ctx, models.AuditFilter{VaultID: 1, From: from, To: to, Limit: 20}).Return(events, nil)
+			serviceMock.On("Events", ctx, models.AuditFilter{}).Return([]models.AuditEvent{}, nil)
+			serviceMock.On("Verify", ctx).Return(&models.AuditVerification{Entries: 1, Head: "aa", BrokenAt: 2, Reason: "hash doesn't match the entry"}, nil)
+
+			mux := http.NewServeMux()
+			NewAuditHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.target, nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			assert.JSONEq(t, tc.response, rec.Body.String())
+		})
+	}
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// given
+		serviceMock := &mocks.AuditServiceMock{}
+		serviceMock.On("Verify", context.TODO()).Return(nil, cerrors.UnauthorizedError("user is not authenticated"))
+
+		req := httptest.NewRequest(http.MethodGet, "/audit/verify", nil).WithContext(context.TODO())
+		rec := httptest.NewRecorder()
+
+		// when
+		NewAuditHandler(serviceMock).Verify(rec, req)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
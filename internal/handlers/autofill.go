@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type autofillHandler struct {
+	service services.IAutofillService
+}
+
+func NewAutofillHandler(service services.IAutofillService) *autofillHandler {
+	return &autofillHandler{service}
+}
+
+// Register registers the autofill routes on mux.
+func (h *autofillHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/autofill", h.Match)
+}
+
+// Match handles GET /autofill?url=.
+// It returns the items from the authenticated user to fill in the page at url, best match first.
+func (h *autofillHandler) Match(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	matches, err := h.service.Match(r.Context(), r.URL.Query().Get("url"))
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, matches)
+}
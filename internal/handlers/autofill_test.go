@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAutofillHandler(t *testing.T) {
+	serviceMock := &mocks.AutofillServiceMock{}
+
+	handler := NewAutofillHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestAutofillRoute(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	matches := []models.AutofillMatch{
+		{ItemDetail: models.ItemDetail{ID: 5, VaultID: 1, Name: "GitHub", Url: "github.com", Username: "octocat", Password: "secret"}, VaultName: "Work", Match: "host"},
+	}
+
+	testCases := []struct {
+		name   string
+		method string
+		target string
+		url    string
+		err    error
+		status int
+		body   string
+	}{
+		{"success", http.MethodGet, "/autofill?url=https%3A%2F%2Fgithub.com%2Flogin", "https://github.com/login", nil, http.StatusOK,
+			`[{"id":5,"vaultId":1,"vaultName":"Work","name":"GitHub","url":"github.com","username":"octocat","password":"secret","notes":"",` +
+				`"revision":0,"match":"host","createdAt":"0001-01-01T00:00:00Z","updatedAt":"0001-01-01T00:00:00Z"}]`},
+		{"invalid url", http.MethodGet, "/autofill?url=ftp%3A%2F%2Fx", "ftp://x", cerrors.BadRequestError("url must be an http or https url"), http.StatusBadRequest,
+			`{"message":"url must be an http or https url"}`},
+		{"method not allowed", http.MethodPost, "/autofill", "", nil, http.StatusMethodNotAllowed, ""},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.AutofillServiceMock{}
+			serviceMock.On("Match", ctx, tc.url).Return(matches, tc.err)
+
+			mux := http.NewServeMux()
+			NewAutofillHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.target, nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+
+			if tc.body != "" {
+				assert.JSONEq(t, tc.body, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+// maxBackupSize is the largest backup accepted by the restore endpoint.
+const maxBackupSize = 64 << 20
+
+type backupHandler struct {
+	service services.IBackupService
+}
+
+type exportBackupRequest struct {
+	Passphrase string `json:"passphrase"`
+}
+
+func NewBackupHandler(service services.IBackupService) *backupHandler {
+	return &backupHandler{service}
+}
+
+// Register registers the backup routes on mux.
+func (h *backupHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/backups/export", h.Export)
+	mux.HandleFunc("/backups/restore", h.Restore)
+}
+
+// Export handles POST /backups/export.
+// It responds with a backup of the account encrypted with the passphrase from the request body.
+func (h *backupHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req exportBackupRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, cerrors.BadRequestError("invalid request body"))
+		return
+	}
+
+	data, err := h.service.Export(r.Context(), req.Passphrase)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="gopass-backup.json"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// Restore handles POST /backups/restore.
+// The request body is the backup file, decrypted with the passphrase in the
+// X-Backup-Passphrase header. The onConflict query parameter is rename, merge or skip.
+func (h *backupHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBackupSize))
+
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Message: "backup is too large"})
+			return
+		}
+
+		writeError(w, cerrors.BadRequestError("could not read the request body"))
+		return
+	}
+
+	onConflict := models.ConflictStrategy(r.URL.Query().Get("onConflict"))
+	report, err := h.service.Restore(r.Context(), data, r.Header.Get("X-Backup-Passphrase"), onConflict)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, report)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewBackupHandler(t *testing.T) {
+	serviceMock := &mocks.BackupServiceMock{}
+
+	handler := NewBackupHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestExportBackupHandler(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		serviceMock := &mocks.BackupServiceMock{}
+		serviceMock.On("Export", ctx, "my long passphrase").Return([]byte(`{"format":"gopass-backup"}`), nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/backups/export", strings.NewReader(`{"passphrase":"my long passphrase"}`)).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		// when
+		handler := &backupHandler{service: serviceMock}
+		handler.Export(rec, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `attachment; filename="gopass-backup.json"`, rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, `{"format":"gopass-backup"}`, rec.Body.String())
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		// given
+		req := httptest.NewRequest(http.MethodPost, "/backups/export", strings.NewReader(`{`)).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		// when
+		handler := &backupHandler{service: &mocks.BackupServiceMock{}}
+		handler.Export(rec, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"message":"invalid request body"}`, rec.Body.String())
+	})
+}
+
+func TestRestoreBackupHandler(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		serviceMock := &mocks.BackupServiceMock{}
+		serviceMock.On("Restore", ctx, []byte("backup-data"), "my long passphrase", models.ConflictMerge).
+			Return(&models.RestoreReport{Vaults: []models.RestoredVault{}, ItemIDs: map[uint]uint{5: 100}}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/backups/restore?onConflict=merge", strings.NewReader("backup-data")).WithContext(ctx)
+		req.Header.Set("X-Backup-Passphrase", "my long passphrase")
+		rec := httptest.NewRecorder()
+
+		// when
+		handler := &backupHandler{service: serviceMock}
+		handler.Restore(rec, req)
+
+		// then
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"vaults":[],"itemIds":{"5":100}}`, rec.Body.String())
+	})
+
+	t.Run("invalid passphrase", func(t *testing.T) {
+		// given
+		serviceMock := &mocks.BackupServiceMock{}
+		serviceMock.On("Restore", ctx, mock.Anything, "wrong", models.ConflictStrategy("")).
+			Return(nil, cerrors.UnprocessableError("invalid passphrase or corrupted backup"))
+
+		req := httptest.NewRequest(http.MethodPost, "/backups/restore", strings.NewReader("backup-data")).WithContext(ctx)
+		req.Header.Set("X-Backup-Passphrase", "wrong")
+		rec := httptest.NewRecorder()
+
+		// when
+		handler := &backupHandler{service: serviceMock}
+		handler.Restore(rec, req)
+
+		// then
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.JSONEq(t, `{"message":"invalid passphrase or corrupted backup"}`, rec.Body.String())
+	})
+}
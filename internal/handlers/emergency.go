@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type emergencyHandler struct {
+	service services.IEmergencyService
+}
+
+type acceptEmergencyRequest struct {
+	PublicKey []byte `json:"publicKey"`
+}
+
+type confirmEmergencyRequest struct {
+	WrappedKey []byte `json:"wrappedKey"`
+}
+
+func NewEmergencyHandler(service services.IEmergencyService) *emergencyHandler {
+	return &emergencyHandler{service}
+}
+
+// Register registers the emergency access routes on mux. The user manages
+// their emergency contacts under /emergency/contacts and the users whose
+// emergency contact they are under /emergency/grantors.
+func (h *emergencyHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/emergency/contacts", h.handleContacts)
+	mux.HandleFunc("/emergency/contacts/", h.handleContact)
+	mux.HandleFunc("/emergency/grantors", h.Grantors)
+	mux.HandleFunc("/emergency/grantors/", h.handleGrantor)
+}
+
+func (h *emergencyHandler) handleContacts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.Contacts(w, r)
+	case http.MethodPost:
+		h.Invite(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *emergencyHandler) handleContact(w http.ResponseWriter, r *http.Request) {
+	id, action, err := pathAction(r, "/emergency/contacts/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch action {
+	case "":
+		if allowMethod(w, r, http.MethodDelete) {
+			h.Delete(w, r, id)
+		}
+	case "confirm":
+		if allowMethod(w, r, http.MethodPost) {
+			h.Confirm(w, r, id)
+		}
+	case "approve":
+		if allowMethod(w, r, http.MethodPost) {
+			h.respond(w, h.service.Approve(r.Context(), id))
+		}
+	case "reject":
+		if allowMethod(w, r, http.MethodPost) {
+			h.respond(w, h.service.Reject(r.Context(), id))
+		}
+	default:
+		writeError(w, cerrors.NotFoundError("not found"))
+	}
+}
+
+func (h *emergencyHandler) handleGrantor(w http.ResponseWriter, r *http.Request) {
+	id, action, err := pathAction(r, "/emergency/grantors/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch action {
+	case "":
+		if allowMethod(w, r, http.MethodDelete) {
+			h.Delete(w, r, id)
+		}
+	case "accept":
+		if allowMethod(w, r, http.MethodPost) {
+			h.Accept(w, r, id)
+		}
+	case "request":
+		if allowMethod(w, r, http.MethodPost) {
+			h.respond(w, h.service.Request(r.Context(), id))
+		}
+	case "access":
+		if allowMethod(w, r, http.MethodGet) {
+			h.Access(w, r, id)
+		}
+	case "takeover":
+		if allowMethod(w, r, http.MethodPost) {
+			h.Takeover(w, r, id)
+		}
+	default:
+		writeError(w, cerrors.NotFoundError("not found"))
+	}
+}
+
+// Contacts handles GET /emergency/contacts.
+// It returns the emergency contacts of the authenticated user.
+func (h *emergencyHandler) Contacts(w http.ResponseWriter, r *http.Request) {
+	contacts, err := h.service.Contacts(r.Context())
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, contacts)
+}
+
+// Invite handles POST /emergency/contacts.
+// It invites the user with the email in the request body as an emergency contact.
+func (h *emergencyHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	var input models.EmergencyContactInput
+
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	contact, err := h.service.Invite(r.Context(), input)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, contact)
+}
+
+// Grantors handles GET /emergency/grantors.
+// It returns the users whose emergency contact the authenticated user is.
+func (h *emergencyHandler) Grantors(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	grantors, err := h.service.Grantors(r.Context())
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, grantors)
+}
+
+// Delete handles DELETE /emergency/contacts/{id} and DELETE /emergency/grantors/{id}.
+// Either side can end the emergency contact.
+func (h *emergencyHandler) Delete(w http.ResponseWriter, r *http.Request, id uint) {
+	h.respond(w, h.service.Delete(r.Context(), id))
+}
+
+// Accept handles POST /emergency/grantors/{id}/accept.
+// It accepts the invitation with the public key in the request body.
+func (h *emergencyHandler) Accept(w http.ResponseWriter, r *http.Request, id uint) {
+	var req acceptEmergencyRequest
+
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.respond(w, h.service.Accept(r.Context(), id, req.PublicKey))
+}
+
+// Confirm handles POST /emergency/contacts/{id}/confirm.
+// It stores the key wrapped to the public key of the contact in the request body.
+func (h *emergencyHandler) Confirm(w http.ResponseWriter, r *http.Request, id uint) {
+	var req confirmEmergencyRequest
+
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.respond(w, h.service.Confirm(r.Context(), id, req.WrappedKey))
+}
+
+// Access handles GET /emergency/grantors/{id}/access.
+// It responds with the wrapped key and the vaults of the grantor once access is granted.
+func (h *emergencyHandler) Access(w http.ResponseWriter, r *http.Request, id uint) {
+	access, err := h.service.Access(r.Context(), id)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, access)
+}
+
+// Takeover handles POST /emergency/grantors/{id}/takeover.
+// It replaces the auth key and the private key of the grantor with the ones in the request body.
+func (h *emergencyHandler) Takeover(w http.ResponseWriter, r *http.Request, id uint) {
+	var input models.EmergencyTakeoverInput
+
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.respond(w, h.service.Takeover(r.Context(), id, input))
+}
+
+// respond writes err, or an empty 204 response when it is nil.
+func (h *emergencyHandler) respond(w http.ResponseWriter, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEmergencyHandler(t *testing.T) {
+	serviceMock := &mocks.EmergencyServiceMock{}
+
+	handler := NewEmergencyHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestEmergencyRoutes(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	contact := models.EmergencyContactDetail{ID: 3, GrantorID: 10, GrantorEmail: "john@test.com", GranteeID: 20, GranteeEmail: "jane@test.com", Access: models.EmergencyView, WaitDays: 7, Status: models.EmergencyInvited}
+	contactJSON := `{"id":3,"grantorId":10,"grantorEmail":"john@test.com","granteeId":20,"granteeEmail":"jane@test.com","access":"view","waitDays":7,"status":"invited"}`
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+	}{
+		{"list contacts", http.MethodGet, "/emergency/contacts", "", http.StatusOK, "[" + contactJSON + "]"},
+		{"invite", http.MethodPost, "/emergency/contacts", `{"email":"jane@test.com","access":"view"}`, http.StatusCreated, contactJSON},
+		{"invite unknown user", http.MethodPost, "/emergency/contacts", `{"email":"nobody@test.com","access":"view"}`, http.StatusNotFound, `{"message":"user not found"}`},
+		{"confirm", http.MethodPost, "/emergency/contacts/3/confirm", `{"wrappedKey":"d3JhcHBlZA=="}`, http.StatusNoContent, ``},
+		{"approve", http.MethodPost, "/emergency/contacts/3/approve", "", http.StatusNoContent, ``},
+		{"reject", http.MethodPost, "/emergency/contacts/3/reject", "", http.StatusNoContent, ``},
+		{"reject without request", http.MethodPost, "/emergency/contacts/4/reject", "", http.StatusConflict, `{"message":"emergency contact is confirmed, not requested"}`},
+		{"delete contact", http.MethodDelete, "/emergency/contacts/3", "", http.StatusNoContent, ``},
+		{"list grantors", http.MethodGet, "/emergency/grantors", "", http.StatusOK, "[" + contactJSON + "]"},
+		{"accept", http.MethodPost, "/emergency/grantors/3/accept", `{"publicKey":"cHVibGlj"}`, http.StatusNoContent, ``},
+		{"request", http.MethodPost, "/emergency/grantors/3/request", "", http.StatusNoContent, ``},
+		{"access", http.MethodGet, "/emergency/grantors/3/access", "", http.StatusOK, `{"grantorId":10,"access":"view","wrappedKey":"d3JhcHBlZA==","vaults":{"revision":42,"vaults":{"created":null,"updated":null,"deleted":null},"items":{"created":null,"updated":null,"deleted":null}}}`},
+		{"access not granted", http.MethodGet, "/emergency/grantors/4/access", "", http.StatusForbidden, `{"message":"emergency access isn't granted"}`},
+		{"takeover", http.MethodPost, "/emergency/grantors/3/takeover", `{"authKey":"new-key","encryptedPrivateKey":"c2VhbGVk"}`, http.StatusNoContent, ``},
+		{"delete grantor", http.MethodDelete, "/emergency/grantors/3", "", http.StatusNoContent, ``},
+		{"unknown action", http.MethodPost, "/emergency/grantors/3/approve", "", http.StatusNotFound, `{"message":"not found"}`},
+		{"invalid id", http.MethodPost, "/emergency/contacts/abc/approve", "", http.StatusNotFound, `{"message":"not found"}`},
+		{"action method not allowed", http.MethodGet, "/emergency/contacts/3/approve", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+		{"method not allowed", http.MethodPut, "/emergency/contacts", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.EmergencyServiceMock{}
+			serviceMock.On("Contacts", ctx).Return([]models.EmergencyContactDetail{contact}, nil)
+			serviceMock.On("Grantors", ctx).Return([]models.EmergencyContactDetail{contact}, nil)
+			serviceMock.On("Invite", ctx, models.EmergencyContactInput{Email: "jane@test.com", Access: models.EmergencyView}).Return(&contact, nil)
+			serviceMock.On("Invite", ctx, models.EmergencyContactInput{Email: "nobody@test.com", Access: models.EmergencyView}).Return(nil, cerrors.NotFoundError("user not found"))
+			serviceMock.On("Confirm", ctx, uint(3), []byte("wrapped")).Return(nil)
+			serviceMock.On("Approve", ctx, uint(3)).Return(nil)
+			serviceMock.On("Reject", ctx, uint(3)).Return(nil)
+			serviceMock.On("Reject", ctx, uint(4)).Return(cerrors.ConflictError("emergency contact is confirmed, not requested"))
+			serviceMock.On("Delete", ctx, uint(3)).Return(nil)
+			serviceMock.On("Accept", ctx, uint(3), []byte("public")).Return(nil)
+			serviceMock.On("Request", ctx, uint(3)).Return(nil)
+			serviceMock.On("Access", ctx, uint(3)).Return(&models.EmergencyAccess{GrantorID: 10, Access: models.EmergencyView, WrappedKey: []byte("wrapped"), Vaults: &models.SyncChanges{Revision: 42}}, nil)
+			serviceMock.On("Access", ctx, uint(4)).Return(nil, cerrors.ForbiddenError("emergency access isn't granted"))
+			serviceMock.On("Takeover", ctx, uint(3), models.EmergencyTakeoverInput{AuthKey: "new-key", EncryptedPrivateKey: []byte("sealed")}).Return(nil)
+
+			mux := http.NewServeMux()
+			NewEmergencyHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.response == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tc.response, rec.Body.String())
+			}
+		})
+	}
+}
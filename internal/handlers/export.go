@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type exportHandler struct {
+	service services.IExportService
+}
+
+type exportKDBXRequest struct {
+	VaultID  uint   `json:"vaultId"`
+	Password string `json:"password"`
+}
+
+func NewExportHandler(service services.IExportService) *exportHandler {
+	return &exportHandler{service}
+}
+
+// Register registers the export routes on mux.
+func (h *exportHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/exports/kdbx", h.ExportKDBX)
+}
+
+// ExportKDBX handles POST /exports/kdbx.
+// It responds with a KeePass database holding the items of the requested vault,
+// protected with the password from the request body.
+func (h *exportHandler) ExportKDBX(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req exportKDBXRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, cerrors.BadRequestError("invalid request body"))
+		return
+	}
+
+	name, data, err := h.service.ExportKDBX(r.Context(), req.VaultID, req.Password)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", strconv.Quote(name+".kdbx")))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
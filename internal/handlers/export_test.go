@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExportHandler(t *testing.T) {
+	serviceMock := &mocks.ExportServiceMock{}
+
+	handler := NewExportHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestExportKDBXHandler(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		serviceMock := &mocks.ExportServiceMock{}
+		serviceMock.On("ExportKDBX", ctx, uint(1), "master").Return("Work", []byte("kdbx-data"), nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/exports/kdbx", strings.NewReader(`{"vaultId":1,"password":"master"}`)).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		// when
+		handler := &exportHandler{service: serviceMock}
+		handler.ExportKDBX(rec, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/octet-stream", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="Work.kdbx"`, rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "kdbx-data", rec.Body.String())
+
+		serviceMock.AssertExpectations(t)
+	})
+
+	testCases := []struct {
+		name     string
+		method   string
+		body     string
+		status   int
+		response string
+	}{
+		{"method not allowed", http.MethodGet, "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+		{"invalid body", http.MethodPost, "{", http.StatusBadRequest, `{"message":"invalid request body"}`},
+		{"vault not found", http.MethodPost, `{"vaultId":2,"password":"master"}`, http.StatusNotFound, `{"message":"vault not found"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.ExportServiceMock{}
+			serviceMock.On("ExportKDBX", ctx, uint(2), "master").Return("", nil, cerrors.NotFoundError("vault not found"))
+
+			req := httptest.NewRequest(tc.method, "/exports/kdbx", strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			handler := &exportHandler{service: serviceMock}
+			handler.ExportKDBX(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			assert.JSONEq(t, tc.response, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type folderHandler struct {
+	service services.IFolderService
+}
+
+type createFolderRequest struct {
+	VaultID uint `json:"vaultId"`
+	models.FolderInput
+}
+
+func NewFolderHandler(service services.IFolderService) *folderHandler {
+	return &folderHandler{service}
+}
+
+// Register registers the folder routes on mux.
+func (h *folderHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/folders", h.handleFolders)
+	mux.HandleFunc("/folders/", h.handleFolder)
+}
+
+func (h *folderHandler) handleFolders(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *folderHandler) handleFolder(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut:
+		h.Update(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		methodNotAllowed(w, http.MethodPut, http.MethodDelete)
+	}
+}
+
+// GetAll handles GET /folders?vaultId=.
+// It returns all folders from the vault.
+func (h *folderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := queryUint(r, "vaultId")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if vaultID == 0 {
+		writeError(w, cerrors.BadRequestError("vaultId is required"))
+		return
+	}
+
+	folders, err := h.service.GetAll(r.Context(), vaultID)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, folders)
+}
+
+// Create handles POST /folders.
+// It creates a folder in the vault from the request body and responds with its ID.
+func (h *folderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req createFolderRequest
+
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	id, err := h.service.Create(r.Context(), req.VaultID, req.FolderInput)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createdResponse{ID: id})
+}
+
+// Update handles PUT /folders/{id}.
+// It renames the folder and moves it to the parent from the request body, the root of the vault when it is missing.
+func (h *folderHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "/folders/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var input models.FolderInput
+
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Update(r.Context(), id, input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// Delete handles DELETE /folders/{id}.
+// Only empty folders can be deleted.
+func (h *folderHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "/folders/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
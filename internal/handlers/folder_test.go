@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFolderHandler(t *testing.T) {
+	serviceMock := &mocks.FolderServiceMock{}
+
+	handler := NewFolderHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestFolderRoutes(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	parentID := uint(1)
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+	}{
+		{"list", http.MethodGet, "/folders?vaultId=1", "", http.StatusOK, `[{"id":1,"vaultId":1,"name":"Work"},{"id":2,"vaultId":1,"parentId":1,"name":"Cloud"}]`},
+		{"list without vault", http.MethodGet, "/folders", "", http.StatusBadRequest, `{"message":"vaultId is required"}`},
+		{"create", http.MethodPost, "/folders", `{"vaultId":1,"name":"Cloud","parentId":1}`, http.StatusCreated, `{"id":2}`},
+		{"update", http.MethodPut, "/folders/2", `{"name":"Cloud"}`, http.StatusNoContent, ``},
+		{"update into a subfolder", http.MethodPut, "/folders/1", `{"name":"Work","parentId":2}`, http.StatusBadRequest, `{"message":"folder can't be moved into itself or its subfolders"}`},
+		{"delete", http.MethodDelete, "/folders/2", "", http.StatusNoContent, ``},
+		{"delete not empty", http.MethodDelete, "/folders/1", "", http.StatusConflict, `{"message":"folder is not empty"}`},
+		{"method not allowed", http.MethodGet, "/folders/1", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			childID := uint(2)
+			serviceMock := &mocks.FolderServiceMock{}
+			serviceMock.On("GetAll", ctx, uint(1)).Return([]models.FolderDetail{
+				{ID: 1, VaultID: 1, Name: "Work"},
+				{ID: 2, VaultID: 1, ParentID: &parentID, Name: "Cloud"},
+			}, nil)
+			serviceMock.On("Create", ctx, uint(1), models.FolderInput{Name: "Cloud", ParentID: &parentID}).Return(uint(2), nil)
+			serviceMock.On("Update", ctx, uint(2), models.FolderInput{Name: "Cloud"}).Return(nil)
+			serviceMock.On("Update", ctx, uint(1), models.FolderInput{Name: "Work", ParentID: &childID}).
+				Return(cerrors.BadRequestError("folder can't be moved into itself or its subfolders"))
+			serviceMock.On("Delete", ctx, uint(2)).Return(nil)
+			serviceMock.On("Delete", ctx, uint(1)).Return(cerrors.ConflictError("folder is not empty"))
+
+			mux := http.NewServeMux()
+			NewFolderHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.response == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tc.response, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+)
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+type codedError interface {
+	error
+	Code() int
+}
+
+// writeJSON writes body as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if body == nil {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("error while trying to write response: %v", err.Error())
+	}
+}
+
+// writeError writes err as a JSON response.
+// Application errors keep their status code and message, any other error is
+// reported as an internal server error without exposing its details.
+func writeError(w http.ResponseWriter, err error) {
+	var appErr codedError
+
+	if errors.As(err, &appErr) {
+		writeJSON(w, appErr.Code(), errorResponse{Message: appErr.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusInternalServerError, errorResponse{Message: "internal server error"})
+}
+
+// allowMethod writes a 405 response and returns false when the request method is not method.
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+
+	methodNotAllowed(w, method)
+
+	return false
+}
+
+// methodNotAllowed writes a 405 response listing the allowed methods.
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Message: "method not allowed"})
+}
+
+// decodeJSON decodes the JSON request body into v.
+func decodeJSON(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return cerrors.BadRequestError("invalid request body")
+	}
+
+	return nil
+}
+
+// pathID parses the ID that follows prefix in the request path, as in /vaults/{id}.
+func pathID(r *http.Request, prefix string) (uint, error) {
+	value := strings.TrimPrefix(r.URL.Path, prefix)
+	n, err := strconv.ParseUint(value, 10, 0)
+
+	if err != nil || n == 0 {
+		return 0, cerrors.NotFoundError("not found")
+	}
+
+	return uint(n), nil
+}
+
+// pathAction parses the ID and the optional action that follow prefix in the
+// request path, as in /emergency/contacts/{id}/approve. The action is empty
+// when the path ends with the ID.
+func pathAction(r *http.Request, prefix string) (uint, string, error) {
+	value, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	n, err := strconv.ParseUint(value, 10, 0)
+
+	if err != nil || n == 0 || strings.Contains(action, "/") {
+		return 0, "", cerrors.NotFoundError("not found")
+	}
+
+	return uint(n), action, nil
+}
+
+// queryUint64 parses an optional 64-bit unsigned integer query parameter.
+// It returns 0 when the parameter is missing.
+func queryUint64(r *http.Request, name string) (uint64, error) {
+	value := r.URL.Query().Get(name)
+
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseUint(value, 10, 64)
+
+	if err != nil {
+		return 0, cerrors.BadRequestError(name + " must be a positive integer")
+	}
+
+	return n, nil
+}
+
+// queryUint parses an optional unsigned integer query parameter.
+// It returns zero when the parameter is missing.
+func queryUint(r *http.Request, name string) (uint, error) {
+	value := r.URL.Query().Get(name)
+
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseUint(value, 10, 0)
+
+	if err != nil {
+		return 0, cerrors.BadRequestError(name + " must be a positive integer")
+	}
+
+	return uint(n), nil
+}
+
+// queryTime parses an optional RFC 3339 time query parameter.
+// It returns the zero time when the parameter is missing.
+func queryTime(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		return time.Time{}, cerrors.BadRequestError(name + " must be an RFC 3339 time")
+	}
+
+	return t, nil
+}
+
+// queryBool parses an optional boolean query parameter.
+// It returns false when the parameter is missing.
+func queryBool(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+
+	if err != nil {
+		return false, cerrors.BadRequestError(name + " must be true or false")
+	}
+
+	return b, nil
+}
+
+// etag returns the ETag of a record at revision.
+func etag(revision uint64) string {
+	return `"` + strconv.FormatUint(revision, 10) + `"`
+}
+
+// ifMatch returns the revision of the If-Match header, or revision when the
+// request has none. The header must hold a single ETag, as returned by etag.
+func ifMatch(r *http.Request, revision uint64) (uint64, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+
+	if value == "" {
+		return revision, nil
+	}
+
+	// Weak ETags and "*" never match an exact revision.
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, cerrors.PreconditionFailedError("If-Match must be the ETag of the current revision")
+	}
+
+	n, err := strconv.ParseUint(value[1:len(value)-1], 10, 64)
+
+	if err != nil || n == 0 {
+		return 0, cerrors.PreconditionFailedError("If-Match must be the ETag of the current revision")
+	}
+
+	return n, nil
+}
+
+type createdResponse struct {
+	ID uint `json:"id"`
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/importer"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+// maxImportSize is the largest export accepted by the import endpoint.
+const maxImportSize = 32 << 20
+
+type importHandler struct {
+	service services.IImportService
+}
+
+func NewImportHandler(service services.IImportService) *importHandler {
+	return &importHandler{service}
+}
+
+// Register registers the import routes on mux.
+func (h *importHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/imports", h.Import)
+}
+
+// Import handles POST /imports.
+// The request body is the raw export file and the format query parameter names
+// the application it comes from. With dryRun=true the import is only previewed.
+// KeePass KDBX databases are opened with the password in the X-Kdbx-Password header.
+func (h *importHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	format := importer.Format(r.URL.Query().Get("format"))
+
+	if format == "" {
+		writeError(w, cerrors.BadRequestError("format is required"))
+		return
+	}
+
+	dryRun := false
+	if value := r.URL.Query().Get("dryRun"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+
+		if err != nil {
+			writeError(w, cerrors.BadRequestError("dryRun must be a boolean"))
+			return
+		}
+
+		dryRun = parsed
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Message: "export is too large"})
+			return
+		}
+
+		writeError(w, cerrors.BadRequestError("could not read the request body"))
+		return
+	}
+
+	var report *models.ImportReport
+
+	if format == importer.KeePassKDBX {
+		report, err = h.service.ImportKDBX(r.Context(), data, r.Header.Get("X-Kdbx-Password"), dryRun)
+	} else {
+		report, err = h.service.Import(r.Context(), format, data, dryRun)
+	}
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+
+	writeJSON(w, status, report)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/importer"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewImportHandler(t *testing.T) {
+	serviceMock := &mocks.ImportServiceMock{}
+
+	handler := NewImportHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestImportHandler(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	export := "name,url,username,password\nexample.com,https://example.com,alice,pass\n"
+	report := &models.ImportReport{NewVaults: []string{"Imported"}}
+
+	testCases := []struct {
+		name   string
+		url    string
+		dryRun bool
+		status int
+	}{
+		{"import", "/imports?format=csv", false, http.StatusCreated},
+		{"dry run", "/imports?format=csv&dryRun=true", true, http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.ImportServiceMock{}
+			serviceMock.On("Import", ctx, importer.BrowserCSV, []byte(export), tc.dryRun).Return(report, nil)
+
+			req := httptest.NewRequest(http.MethodPost, tc.url, strings.NewReader(export)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			handler := &importHandler{service: serviceMock}
+			handler.Import(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"newVaults":["Imported"]`)
+
+			serviceMock.AssertExpectations(t)
+		})
+	}
+
+	t.Run("kdbx", func(t *testing.T) {
+		// given
+		serviceMock := &mocks.ImportServiceMock{}
+		serviceMock.On("ImportKDBX", ctx, []byte("kdbx-data"), "master", true).Return(report, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/imports?format=kdbx&dryRun=1", strings.NewReader("kdbx-data")).WithContext(ctx)
+		req.Header.Set("X-Kdbx-Password", "master")
+		rec := httptest.NewRecorder()
+
+		// when
+		handler := &importHandler{service: serviceMock}
+		handler.Import(rec, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		serviceMock.AssertExpectations(t)
+	})
+
+	errorCases := []struct {
+		name     string
+		method   string
+		url      string
+		status   int
+		response string
+	}{
+		{"method not allowed", http.MethodGet, "/imports?format=csv", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+		{"missing format", http.MethodPost, "/imports", http.StatusBadRequest, `{"message":"format is required"}`},
+		{"invalid dry run", http.MethodPost, "/imports?format=csv&dryRun=maybe", http.StatusBadRequest, `{"message":"dryRun must be a boolean"}`},
+		{"invalid export", http.MethodPost, "/imports?format=bitwarden", http.StatusUnprocessableEntity, `{"message":"invalid bitwarden export"}`},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.ImportServiceMock{}
+			serviceMock.On("Import", ctx, mock.Anything, mock.Anything, false).
+				Return(nil, cerrors.UnprocessableError("invalid bitwarden export"))
+
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(export)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			handler := &importHandler{service: serviceMock}
+			handler.Import(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			assert.JSONEq(t, tc.response, rec.Body.String())
+		})
+	}
+}
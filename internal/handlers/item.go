@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type itemHandler struct {
+	service services.IItemService
+}
+
+type createItemRequest struct {
+	VaultID uint `json:"vaultId"`
+	models.ItemInput
+}
+
+func NewItemHandler(service services.IItemService) *itemHandler {
+	return &itemHandler{service}
+}
+
+// Register registers the item routes on mux.
+func (h *itemHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/items", h.handleItems)
+	mux.HandleFunc("/items/", h.handleItem)
+	mux.HandleFunc("/favorites/", h.handleFavorite)
+}
+
+func (h *itemHandler) handleItems(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *itemHandler) handleItem(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.Get(w, r)
+	case http.MethodPut:
+		h.Update(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+func (h *itemHandler) handleFavorite(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut:
+		h.SetFavorite(w, r, true)
+	case http.MethodDelete:
+		h.SetFavorite(w, r, false)
+	default:
+		methodNotAllowed(w, http.MethodPut, http.MethodDelete)
+	}
+}
+
+// GetAll handles GET /items?vaultId=&folderId=&tag=&favorite=.
+// It returns the items from the vault, only the ones in the folder and its
+// subfolders, with the tag or favorite when those parameters are given.
+func (h *itemHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := queryUint(r, "vaultId")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if vaultID == 0 {
+		writeError(w, cerrors.BadRequestError("vaultId is required"))
+		return
+	}
+
+	folderID, err := queryUint(r, "folderId")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	favorite, err := queryBool(r, "favorite")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	filter := models.ItemFilter{FolderID: folderID, Tag: r.URL.Query().Get("tag"), Favorite: favorite}
+	items, err := h.service.GetAll(r.Context(), vaultID, filter)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, items)
+}
+
+// Create handles POST /items.
+// It creates an item in the vault from the request body and responds with its ID.
+func (h *itemHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req createItemRequest
+
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	id, err := h.service.Create(r.Context(), req.VaultID, req.ItemInput)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createdResponse{ID: id})
+}
+
+// Get handles GET /items/{id}.
+// The ETag header of the response holds the revision of the item.
+func (h *itemHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "/items/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	item, err := h.service.Get(r.Context(), id)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(item.Revision))
+	writeJSON(w, http.StatusOK, item)
+}
+
+// Update handles PUT /items/{id}.
+// All fields of the item are replaced with the ones from the request body.
+// The revision the update is based on is taken from the If-Match header, or
+// from the request body when the header is missing.
+func (h *itemHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "/items/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var input models.ItemInput
+
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if input.Revision, err = ifMatch(r, input.Revision); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Update(r.Context(), id, input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// Delete handles DELETE /items/{id}.
+func (h *itemHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "/items/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// SetFavorite handles PUT and DELETE /favorites/{itemId}.
+// PUT adds the item to the favorites of the user and DELETE removes it.
+func (h *itemHandler) SetFavorite(w http.ResponseWriter, r *http.Request, favorite bool) {
+	id, err := pathID(r, "/favorites/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.SetFavorite(r.Context(), id, favorite); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
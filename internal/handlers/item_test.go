@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewItemHandler(t *testing.T) {
+	serviceMock := &mocks.ItemServiceMock{}
+
+	handler := NewItemHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestItemRoutes(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	input := models.ItemInput{Name: "GitHub", Username: "octocat", Password: "secret"}
+	item := &models.ItemDetail{ID: 5, VaultID: 1, Name: "GitHub", Username: "octocat", Password: "secret", Revision: 7}
+	itemJSON := `{"id":5,"vaultId":1,"name":"GitHub","url":"","username":"octocat","password":"secret","notes":"","revision":7,` +
+		`"createdAt":"0001-01-01T00:00:00Z","updatedAt":"0001-01-01T00:00:00Z"}`
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+	}{
+		{"list", http.MethodGet, "/items?vaultId=1", "", http.StatusOK, "[" + itemJSON + "]"},
+		{"list filtered", http.MethodGet, "/items?vaultId=1&folderId=3&tag=work&favorite=true", "", http.StatusOK, "[]"},
+		{"list invalid favorite", http.MethodGet, "/items?vaultId=1&favorite=maybe", "", http.StatusBadRequest, `{"message":"favorite must be true or false"}`},
+		{"list without vault", http.MethodGet, "/items", "", http.StatusBadRequest, `{"message":"vaultId is required"}`},
+		{"create", http.MethodPost, "/items", `{"vaultId":1,"name":"GitHub","username":"octocat","password":"secret"}`, http.StatusCreated, `{"id":5}`},
+		{"get", http.MethodGet, "/items/5", "", http.StatusOK, itemJSON},
+		{"get not found", http.MethodGet, "/items/6", "", http.StatusNotFound, `{"message":"item not found"}`},
+		{"update", http.MethodPut, "/items/5", `{"name":"GitHub","username":"octocat","password":"secret","revision":7}`, http.StatusNoContent, ``},
+		{"update invalid body", http.MethodPut, "/items/5", `[]`, http.StatusBadRequest, `{"message":"invalid request body"}`},
+		{"delete", http.MethodDelete, "/items/5", "", http.StatusNoContent, ``},
+		{"favorite", http.MethodPut, "/favorites/5", "", http.StatusNoContent, ``},
+		{"unfavorite", http.MethodDelete, "/favorites/5", "", http.StatusNoContent, ``},
+		{"favorite not found", http.MethodPut, "/favorites/6", "", http.StatusNotFound, `{"message":"item not found"}`},
+		{"method not allowed", http.MethodPatch, "/items/5", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.ItemServiceMock{}
+			serviceMock.On("GetAll", ctx, uint(1), models.ItemFilter{}).Return([]models.ItemDetail{*item}, nil)
+			serviceMock.On("GetAll", ctx, uint(1), models.ItemFilter{FolderID: 3, Tag: "work", Favorite: true}).Return([]models.ItemDetail{}, nil)
+			serviceMock.On("Create", ctx, uint(1), input).Return(uint(5), nil)
+			serviceMock.On("Get", ctx, uint(5)).Return(item, nil)
+			serviceMock.On("Get", ctx, uint(6)).Return(nil, cerrors.NotFoundError("item not found"))
+			serviceMock.On("Update", ctx, uint(5), models.ItemInput{Name: "GitHub", Username: "octocat", Password: "secret", Revision: 7}).Return(nil)
+			serviceMock.On("Delete", ctx, uint(5)).Return(nil)
+			serviceMock.On("SetFavorite", ctx, uint(5), true).Return(nil)
+			serviceMock.On("SetFavorite", ctx, uint(5), false).Return(nil)
+			serviceMock.On("SetFavorite", ctx, uint(6), true).Return(cerrors.NotFoundError("item not found"))
+
+			mux := http.NewServeMux()
+			NewItemHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.response == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tc.response, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestItemRevisionHeaders(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	input := models.ItemInput{Name: "GitHub", Revision: 7}
+
+	t.Run("get sets the etag", func(t *testing.T) {
+		// given
+		serviceMock := &mocks.ItemServiceMock{}
+		serviceMock.On("Get", ctx, uint(5)).Return(&models.ItemDetail{ID: 5, Revision: 7}, nil)
+
+		mux := http.NewServeMux()
+		NewItemHandler(serviceMock).Register(mux)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/5", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		// when
+		mux.ServeHTTP(rec, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"7"`, rec.Header().Get("ETag"))
+	})
+
+	testCases := []struct {
+		name     string
+		ifMatch  string
+		body     string
+		status   int
+		response string
+	}{
+		{"if-match", `"7"`, `{"name":"GitHub"}`, http.StatusNoContent, ``},
+		{"if-match over body", `"7"`, `{"name":"GitHub","revision":3}`, http.StatusNoContent, ``},
+		{"stale if-match", `"6"`, `{"name":"GitHub"}`, http.StatusPreconditionFailed, `{"message":"item was changed at revision 7, since revision 6"}`},
+		{"weak if-match", `W/"7"`, `{"name":"GitHub"}`, http.StatusPreconditionFailed, `{"message":"If-Match must be the ETag of the current revision"}`},
+		{"any if-match", `*`, `{"name":"GitHub"}`, http.StatusPreconditionFailed, `{"message":"If-Match must be the ETag of the current revision"}`},
+		{"no revision", ``, `{"name":"GitHub"}`, http.StatusPreconditionRequired, `{"message":"revision is required"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.ItemServiceMock{}
+			serviceMock.On("Update", ctx, uint(5), input).Return(nil)
+			serviceMock.On("Update", ctx, uint(5), models.ItemInput{Name: "GitHub", Revision: 6}).
+				Return(cerrors.PreconditionFailedError("item was changed at revision 7, since revision 6"))
+			serviceMock.On("Update", ctx, uint(5), models.ItemInput{Name: "GitHub"}).
+				Return(cerrors.PreconditionRequiredError("revision is required"))
+
+			mux := http.NewServeMux()
+			NewItemHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(http.MethodPut, "/items/5", strings.NewReader(tc.body)).WithContext(ctx)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.response == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tc.response, rec.Body.String())
+			}
+		})
+	}
+}
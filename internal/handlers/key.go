@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type keyHandler struct {
+	service services.IKeyService
+}
+
+func NewKeyHandler(service services.IKeyService) *keyHandler {
+	return &keyHandler{service}
+}
+
+// Register registers the key pair routes on mux.
+func (h *keyHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/keys", h.handleKeys)
+	mux.HandleFunc("/keys/lookup", h.Lookup)
+	mux.HandleFunc("/keys/verify", h.Verify)
+	mux.HandleFunc("/keys/wrapped", h.WrappedKeys)
+	mux.HandleFunc("/keys/rotate", h.Rotate)
+}
+
+func (h *keyHandler) handleKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.Keys(w, r)
+	case http.MethodPut:
+		h.SetKeys(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPut)
+	}
+}
+
+// Keys handles GET /keys.
+// It returns the key pair of the authenticated user, the private key encrypted.
+func (h *keyHandler) Keys(w http.ResponseWriter, r *http.Request) {
+	userKeys, err := h.service.Keys(r.Context())
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, userKeys)
+}
+
+// SetKeys handles PUT /keys.
+// It sets the key pair of the authenticated user from the request body.
+func (h *keyHandler) SetKeys(w http.ResponseWriter, r *http.Request) {
+	var userKeys models.UserKeys
+
+	if err := decodeJSON(r, &userKeys); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.SetKeys(r.Context(), userKeys); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// Lookup handles GET /keys/lookup?email=.
+// It returns the public key of the user with the email and its fingerprint.
+func (h *keyHandler) Lookup(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	detail, err := h.service.Lookup(r.Context(), r.URL.Query().Get("email"))
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// Verify handles POST /keys/verify.
+// It marks the public key of a user as verified once the fingerprint in the
+// request body matches it.
+func (h *keyHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var input models.KeyVerificationInput
+
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	detail, err := h.service.Verify(r.Context(), input)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// WrappedKeys handles GET /keys/wrapped.
+// It returns the keys wrapped to the public key of the authenticated user.
+func (h *keyHandler) WrappedKeys(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	wrappedKeys, err := h.service.WrappedKeys(r.Context())
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, wrappedKeys)
+}
+
+// Rotate handles POST /keys/rotate.
+// It replaces the key pair of the authenticated user and the keys wrapped to it.
+func (h *keyHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var rotation models.KeyRotation
+
+	if err := decodeJSON(r, &rotation); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Rotate(r.Context(), rotation); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKeyHandler(t *testing.T) {
+	serviceMock := &mocks.KeyServiceMock{}
+
+	handler := NewKeyHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestKeyRoutes(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	userKeys := models.UserKeys{PublicKey: []byte("public"), EncryptedPrivateKey: []byte("sealed")}
+	detail := &models.PublicKeyDetail{UserID: 20, Email: "jane@test.com", PublicKey: []byte("public"), Fingerprint: "0a1b 2c3d", Verified: true}
+	wrappedKeys := []models.WrappedKey{{Kind: models.WrappedRecoveryShare, ID: 7, WrappedKey: []byte("share")}}
+	detailJSON := `{"userId":20,"email":"jane@test.com","publicKey":"cHVibGlj","fingerprint":"0a1b 2c3d","verified":true}`
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+	}{
+		{"keys", http.MethodGet, "/keys", "", http.StatusOK, `{"publicKey":"cHVibGlj","encryptedPrivateKey":"c2VhbGVk"}`},
+		{"set keys", http.MethodPut, "/keys", `{"publicKey":"cHVibGlj","encryptedPrivateKey":"c2VhbGVk"}`, http.StatusNoContent, ``},
+		{"keys method not allowed", http.MethodDelete, "/keys", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+		{"lookup", http.MethodGet, "/keys/lookup?email=jane@test.com", "", http.StatusOK, detailJSON},
+		{"lookup unknown user", http.MethodGet, "/keys/lookup?email=nobody@test.com", "", http.StatusNotFound, `{"message":"user not found"}`},
+		{"verify", http.MethodPost, "/keys/verify", `{"email":"jane@test.com","fingerprint":"0a1b 2c3d"}`, http.StatusOK, detailJSON},
+		{"verify mismatch", http.MethodPost, "/keys/verify", `{"email":"jane@test.com","fingerprint":"ffff"}`, http.StatusUnprocessableEntity, `{"message":"fingerprint doesn't match the key of jane@test.com"}`},
+		{"verify method not allowed", http.MethodGet, "/keys/verify", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+		{"wrapped keys", http.MethodGet, "/keys/wrapped", "", http.StatusOK, `[{"kind":"recovery","id":7,"wrappedKey":"c2hhcmU="}]`},
+		{"rotate", http.MethodPost, "/keys/rotate", `{"publicKey":"cHVibGlj","encryptedPrivateKey":"c2VhbGVk","wrappedKeys":[{"kind":"recovery","id":7,"wrappedKey":"c2hhcmU="}]}`, http.StatusNoContent, ``},
+		{"rotate stale keys", http.MethodPost, "/keys/rotate", `{"publicKey":"cHVibGlj","encryptedPrivateKey":"c2VhbGVk","wrappedKeys":[]}`, http.StatusConflict, `{"message":"wrapped keys changed, rotate again"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.KeyServiceMock{}
+			serviceMock.On("Keys", ctx).Return(&userKeys, nil)
+			serviceMock.On("SetKeys", ctx, userKeys).Return(nil)
+			serviceMock.On("Lookup", ctx, "jane@test.com").Return(detail, nil)
+			serviceMock.On("Lookup", ctx, "nobody@test.com").Return(nil, cerrors.NotFoundError("user not found"))
+			serviceMock.On("Verify", ctx, models.KeyVerificationInput{Email: "jane@test.com", Fingerprint: "0a1b 2c3d"}).Return(detail, nil)
+			serviceMock.On("Verify", ctx, models.KeyVerificationInput{Email: "jane@test.com", Fingerprint: "ffff"}).Return(nil, cerrors.UnprocessableError("fingerprint doesn't match the key of jane@test.com"))
+
+			serviceMock.On("WrappedKeys", ctx).Return(wrappedKeys, nil)
+			serviceMock.On("Rotate", ctx, models.KeyRotation{PublicKey: []byte("public"), EncryptedPrivateKey: []byte("sealed"), WrappedKeys: wrappedKeys}).Return(nil)
+			serviceMock.On("Rotate", ctx, models.KeyRotation{PublicKey: []byte("public"), EncryptedPrivateKey: []byte("sealed"), WrappedKeys: []models.WrappedKey{}}).Return(cerrors.ConflictError("wrapped keys changed, rotate again"))
+
+			mux := http.NewServeMux()
+			NewKeyHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.response == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tc.response, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/pkg/jwt"
+)
+
+// Authenticate rejects requests without a valid bearer token.
+// The ID of the authenticated user is stored in the request context under keys.UserIDKey.
+func Authenticate(validator jwt.JWTValidator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+
+		if !ok {
+			writeError(w, cerrors.UnauthorizedError("missing bearer token"))
+			return
+		}
+
+		userID, err := validator.Validate(token)
+
+		if err != nil {
+			writeError(w, cerrors.UnauthorizedError("invalid token"))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), keys.UserIDKey, userID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ClientInfo stores the IP address and the User-Agent header of the client in
+// the request context under keys.ClientIPKey and keys.UserAgentKey, for the
+// audit log. The address is the one the request came from: behind a proxy,
+// it is the address of the proxy.
+func ClientInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+
+		ctx := context.WithValue(r.Context(), keys.ClientIPKey, ip)
+		ctx = context.WithValue(ctx, keys.UserAgentKey, r.UserAgent())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
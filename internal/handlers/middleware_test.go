@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// given
+		validatorMock := &mocks.JWTValidatorMock{}
+		validatorMock.On("Validate", "valid-token").Return(uint(10), nil)
+
+		var userID uint
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID = r.Context().Value(keys.UserIDKey).(uint)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		rec := httptest.NewRecorder()
+
+		// when
+		Authenticate(validatorMock, next).ServeHTTP(rec, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, uint(10), userID)
+
+		validatorMock.AssertExpectations(t)
+	})
+
+	headers := []struct {
+		name    string
+		header  string
+		message string
+	}{
+		{"missing header", "", "missing bearer token"},
+		{"wrong scheme", "Basic dXNlcjpwc3c=", "missing bearer token"},
+		{"empty token", "Bearer  ", "missing bearer token"},
+		{"invalid token", "Bearer invalid-token", "invalid token"},
+	}
+	for _, h := range headers {
+		t.Run(h.name, func(t *testing.T) {
+			// given
+			validatorMock := &mocks.JWTValidatorMock{}
+			validatorMock.On("Validate", "invalid-token").Return(uint(0), errors.New("token is expired"))
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("next handler must not be called")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", h.header)
+			rec := httptest.NewRecorder()
+
+			// when
+			Authenticate(validatorMock, next).ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.JSONEq(t, `{"message":"`+h.message+`"}`, rec.Body.String())
+		})
+	}
+}
+
+func TestClientInfo(t *testing.T) {
+	// given
+	var ip, userAgent string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip = r.Context().Value(keys.ClientIPKey).(string)
+		userAgent = r.Context().Value(keys.UserAgentKey).(string)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "[2001:db8::1]:51234"
+	req.Header.Set("User-Agent", "gopass-cli")
+	rec := httptest.NewRecorder()
+
+	// when
+	ClientInfo(next).ServeHTTP(rec, req)
+
+	// then
+	assert.Equal(t, "2001:db8::1", ip)
+	assert.Equal(t, "gopass-cli", userAgent)
+}
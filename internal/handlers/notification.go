@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+// notificationHeartbeat is how often an idle stream gets a comment, so
+// proxies don't close it and clients notice when it is gone.
+const notificationHeartbeat = 15 * time.Second
+
+type notificationHandler struct {
+	service   services.INotificationService
+	heartbeat time.Duration
+}
+
+func NewNotificationHandler(service services.INotificationService) *notificationHandler {
+	return &notificationHandler{service, notificationHeartbeat}
+}
+
+// Register registers the notification routes on mux.
+func (h *notificationHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/events", h.Stream)
+}
+
+// Stream handles GET /events?since=.
+// It streams the changes made to the vaults of the authenticated user as
+// server-sent events, whose IDs are the revisions of the changes. The changes
+// made after the revision given by since, or by the Last-Event-ID header of
+// a reconnecting client, come first.
+func (h *notificationHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	since, err := resumeRevision(r)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+		writeError(w, fmt.Errorf("streaming is not supported by %T", w))
+		return
+	}
+
+	notifications, err := h.service.Subscribe(r.Context(), since)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Proxies buffering responses would hold the events back.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case notification, ok := <-notifications:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(notification)
+
+			if err != nil {
+				log.Printf("error while trying to encode notification: %v", err.Error())
+				return
+			}
+
+			// Notifications without revision keep the last event ID of the client.
+			if notification.Revision != 0 {
+				fmt.Fprintf(w, "id: %d\n", notification.Revision)
+			}
+
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", notification.Type, data)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case <-r.Context().Done():
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+// resumeRevision returns the revision the stream resumes from, nil for none.
+func resumeRevision(r *http.Request) (*uint64, error) {
+	value, name := r.URL.Query().Get("since"), "since"
+
+	if value == "" {
+		value, name = r.Header.Get("Last-Event-ID"), "Last-Event-ID"
+	}
+
+	if value == "" {
+		return nil, nil
+	}
+
+	since, err := strconv.ParseUint(value, 10, 64)
+
+	if err != nil {
+		return nil, cerrors.BadRequestError(name + " must be a positive integer")
+	}
+
+	return &since, nil
+}
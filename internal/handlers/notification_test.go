@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewNotificationHandler(t *testing.T) {
+	serviceMock := &mocks.NotificationServiceMock{}
+
+	handler := NewNotificationHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+	assert.Equal(t, notificationHeartbeat, handler.heartbeat)
+}
+
+// sent returns a closed channel holding notifications.
+func sent(notifications ...models.Notification) <-chan models.Notification {
+	ch := make(chan models.Notification, len(notifications))
+
+	for _, notification := range notifications {
+		ch <- notification
+	}
+
+	close(ch)
+
+	return ch
+}
+
+func TestNotificationRoutes(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	since := uint64(40)
+	ahead := uint64(50)
+
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		lastEventID string
+		status      int
+		response    string
+	}{
+		{"stream", http.MethodGet, "/events", "", http.StatusOK, "event: vault.created\ndata: {\"type\":\"vault.created\",\"vaultId\":2}\n\n"},
+		{"resumed with since", http.MethodGet, "/events?since=40", "", http.StatusOK, "id: 42\nevent: item.updated\ndata: {\"type\":\"item.updated\",\"vaultId\":1,\"itemId\":5,\"revision\":42}\n\n"},
+		{"resumed with Last-Event-ID", http.MethodGet, "/events", "40", http.StatusOK, "id: 42\nevent: item.updated\ndata: {\"type\":\"item.updated\",\"vaultId\":1,\"itemId\":5,\"revision\":42}\n\n"},
+		{"invalid Last-Event-ID", http.MethodGet, "/events", "abc", http.StatusBadRequest, `{"message":"Last-Event-ID must be a positive integer"}` + "\n"},
+		{"revision ahead", http.MethodGet, "/events?since=50", "", http.StatusConflict, `{"message":"revision is ahead of the server, sync from 0"}` + "\n"},
+		{"method not allowed", http.MethodPost, "/events", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}` + "\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.NotificationServiceMock{}
+			serviceMock.On("Subscribe", ctx, (*uint64)(nil)).Return(sent(models.Notification{Type: models.EventVaultCreated, VaultID: 2}), nil)
+			serviceMock.On("Subscribe", ctx, &since).Return(sent(models.Notification{Type: models.EventItemUpdated, VaultID: 1, ItemID: 5, Revision: 42}), nil)
+			serviceMock.On("Subscribe", ctx, &ahead).Return(nil, cerrors.ConflictError("revision is ahead of the server, sync from 0"))
+
+			mux := http.NewServeMux()
+			NewNotificationHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, nil).WithContext(ctx)
+			if tc.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tc.lastEventID)
+			}
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			assert.Equal(t, tc.response, rec.Body.String())
+			if tc.status == http.StatusOK {
+				assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestNotificationHeartbeat(t *testing.T) {
+	// given
+	ctx, cancel := context.WithCancel(context.WithValue(context.TODO(), keys.UserIDKey, uint(10)))
+	serviceMock := &mocks.NotificationServiceMock{}
+	serviceMock.On("Subscribe", mock.Anything, (*uint64)(nil)).Return((<-chan models.Notification)(make(chan models.Notification)), nil)
+
+	handler := NewNotificationHandler(serviceMock)
+	handler.heartbeat = time.Millisecond
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+
+	// when
+	go func() {
+		handler.Stream(rec, req)
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+
+	// then
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), ": heartbeat\n\n")
+}
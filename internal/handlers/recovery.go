@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type recoveryHandler struct {
+	service services.IRecoveryService
+}
+
+type recoveryShareResponse struct {
+	EncryptedShare []byte `json:"encryptedShare"`
+}
+
+type approveRecoveryRequest struct {
+	Share []byte `json:"share"`
+}
+
+func NewRecoveryHandler(service services.IRecoveryService) *recoveryHandler {
+	return &recoveryHandler{service}
+}
+
+// Register registers the recovery key routes on mux. The recovery key of a
+// vault is under /recovery/keys/{vaultId}, its ceremonies under /recovery/ceremonies.
+func (h *recoveryHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/recovery/keys/", h.handleKey)
+	mux.HandleFunc("/recovery/ceremonies/", h.handleCeremony)
+}
+
+func (h *recoveryHandler) handleKey(w http.ResponseWriter, r *http.Request) {
+	vaultID, action, err := pathAction(r, "/recovery/keys/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.Get(w, r, vaultID)
+	case action == "" && r.Method == http.MethodPut:
+		h.Setup(w, r, vaultID)
+	case action == "" && r.Method == http.MethodDelete:
+		h.Delete(w, r, vaultID)
+	case action == "":
+		methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+	case action == "share":
+		if allowMethod(w, r, http.MethodGet) {
+			h.Share(w, r, vaultID)
+		}
+	case action == "ceremonies" && r.Method == http.MethodGet:
+		h.Ceremonies(w, r, vaultID)
+	case action == "ceremonies" && r.Method == http.MethodPost:
+		h.Start(w, r, vaultID)
+	case action == "ceremonies":
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	default:
+		writeError(w, cerrors.NotFoundError("not found"))
+	}
+}
+
+func (h *recoveryHandler) handleCeremony(w http.ResponseWriter, r *http.Request) {
+	id, action, err := pathAction(r, "/recovery/ceremonies/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if action != "approve" && action != "cancel" && action != "complete" {
+		writeError(w, cerrors.NotFoundError("not found"))
+		return
+	}
+
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	switch action {
+	case "approve":
+		h.Approve(w, r, id)
+	case "cancel":
+		h.Cancel(w, r, id)
+	case "complete":
+		h.Complete(w, r, id)
+	}
+}
+
+// Get handles GET /recovery/keys/{vaultId}.
+// It returns the recovery key of the vault, without its shares.
+func (h *recoveryHandler) Get(w http.ResponseWriter, r *http.Request, vaultID uint) {
+	key, err := h.service.Get(r.Context(), vaultID)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, key)
+}
+
+// Setup handles PUT /recovery/keys/{vaultId}.
+// It sets the recovery key of the vault from the shares, encrypted to the
+// admins, in the request body.
+func (h *recoveryHandler) Setup(w http.ResponseWriter, r *http.Request, vaultID uint) {
+	var input models.RecoveryKeyInput
+
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	key, err := h.service.Setup(r.Context(), vaultID, input)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, key)
+}
+
+// Delete handles DELETE /recovery/keys/{vaultId}.
+func (h *recoveryHandler) Delete(w http.ResponseWriter, r *http.Request, vaultID uint) {
+	if err := h.service.Delete(r.Context(), vaultID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// Share handles GET /recovery/keys/{vaultId}/share.
+// It returns the share of the authenticated admin, encrypted to them.
+func (h *recoveryHandler) Share(w http.ResponseWriter, r *http.Request, vaultID uint) {
+	share, err := h.service.Share(r.Context(), vaultID)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, recoveryShareResponse{share})
+}
+
+// Ceremonies handles GET /recovery/keys/{vaultId}/ceremonies.
+// It returns the ceremonies of the recovery key of the vault, newest first.
+func (h *recoveryHandler) Ceremonies(w http.ResponseWriter, r *http.Request, vaultID uint) {
+	ceremonies, err := h.service.Ceremonies(r.Context(), vaultID)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ceremonies)
+}
+
+// Start handles POST /recovery/keys/{vaultId}/ceremonies.
+// It starts a ceremony rebuilding the recovery key of the vault.
+func (h *recoveryHandler) Start(w http.ResponseWriter, r *http.Request, vaultID uint) {
+	var input models.RecoveryCeremonyInput
+
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ceremony, err := h.service.Start(r.Context(), vaultID, input)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, ceremony)
+}
+
+// Approve handles POST /recovery/ceremonies/{id}/approve.
+// It approves the ceremony with the share, encrypted to the requester, in the request body.
+func (h *recoveryHandler) Approve(w http.ResponseWriter, r *http.Request, id uint) {
+	var req approveRecoveryRequest
+
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ceremony, err := h.service.Approve(r.Context(), id, req.Share)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ceremony)
+}
+
+// Cancel handles POST /recovery/ceremonies/{id}/cancel.
+func (h *recoveryHandler) Cancel(w http.ResponseWriter, r *http.Request, id uint) {
+	if err := h.service.Cancel(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// Complete handles POST /recovery/ceremonies/{id}/complete.
+// It responds with the shares of the approved ceremony, which the server then wipes.
+func (h *recoveryHandler) Complete(w http.ResponseWriter, r *http.Request, id uint) {
+	shares, err := h.service.Complete(r.Context(), id)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, shares)
+}
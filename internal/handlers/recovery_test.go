@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRecoveryHandler(t *testing.T) {
+	serviceMock := &mocks.RecoveryServiceMock{}
+
+	handler := NewRecoveryHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestRecoveryRoutes(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	key := &models.RecoveryKeyDetail{ID: 2, VaultID: 1, OwnerID: 10, Threshold: 2, Admins: []models.RecoveryAdmin{{ID: 20, Email: "jane@test.com"}, {ID: 30, Email: "bob@test.com"}}, CreatedAt: createdAt}
+	keyJSON := `{"id":2,"vaultId":1,"ownerId":10,"threshold":2,"admins":[{"id":20,"email":"jane@test.com"},{"id":30,"email":"bob@test.com"}],"createdAt":"2026-10-19T12:00:00Z"}`
+	ceremony := &models.RecoveryCeremonyDetail{ID: 5, VaultID: 1, RequesterID: 20, RequesterEmail: "jane@test.com", Reason: "Owner left", PublicKey: []byte("public"), Status: models.RecoveryPending, Threshold: 2, Approvals: []models.RecoveryApprovalDetail{}, CreatedAt: createdAt}
+	ceremonyJSON := `{"id":5,"vaultId":1,"requesterId":20,"requesterEmail":"jane@test.com","reason":"Owner left","publicKey":"cHVibGlj","status":"pending","threshold":2,"approvals":[],"createdAt":"2026-10-19T12:00:00Z"}`
+	input := models.RecoveryKeyInput{Threshold: 2, Shares: []models.RecoveryShareInput{{Email: "jane@test.com", EncryptedShare: []byte("a")}, {Email: "bob@test.com", EncryptedShare: []byte("b")}}}
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+	}{
+		{"get key", http.MethodGet, "/recovery/keys/1", "", http.StatusOK, keyJSON},
+		{"get missing key", http.MethodGet, "/recovery/keys/2", "", http.StatusNotFound, `{"message":"recovery key not found"}`},
+		{"setup", http.MethodPut, "/recovery/keys/1", `{"threshold":2,"shares":[{"email":"jane@test.com","encryptedShare":"YQ=="},{"email":"bob@test.com","encryptedShare":"Yg=="}]}`, http.StatusOK, keyJSON},
+		{"delete", http.MethodDelete, "/recovery/keys/1", "", http.StatusNoContent, ``},
+		{"share", http.MethodGet, "/recovery/keys/1/share", "", http.StatusOK, `{"encryptedShare":"c2hhcmU="}`},
+		{"ceremonies", http.MethodGet, "/recovery/keys/1/ceremonies", "", http.StatusOK, "[" + ceremonyJSON + "]"},
+		{"start", http.MethodPost, "/recovery/keys/1/ceremonies", `{"reason":"Owner left","publicKey":"cHVibGlj"}`, http.StatusCreated, ceremonyJSON},
+		{"approve", http.MethodPost, "/recovery/ceremonies/5/approve", `{"share":"d3JhcHBlZA=="}`, http.StatusOK, ceremonyJSON},
+		{"cancel", http.MethodPost, "/recovery/ceremonies/5/cancel", "", http.StatusNoContent, ``},
+		{"complete", http.MethodPost, "/recovery/ceremonies/5/complete", "", http.StatusOK, `{"ceremonyId":5,"vaultId":1,"shares":["YQ==","Yg=="]}`},
+		{"complete before approval", http.MethodPost, "/recovery/ceremonies/6/complete", "", http.StatusConflict, `{"message":"recovery ceremony is pending, not approved"}`},
+		{"unknown action", http.MethodPost, "/recovery/ceremonies/5/reject", "", http.StatusNotFound, `{"message":"not found"}`},
+		{"ceremony method not allowed", http.MethodGet, "/recovery/ceremonies/5/complete", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+		{"key method not allowed", http.MethodPost, "/recovery/keys/1", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.RecoveryServiceMock{}
+			serviceMock.On("Get", ctx, uint(1)).Return(key, nil)
+			serviceMock.On("Get", ctx, uint(2)).Return(nil, cerrors.NotFoundError("recovery key not found"))
+			serviceMock.On("Setup", ctx, uint(1), input).Return(key, nil)
+			serviceMock.On("Delete", ctx, uint(1)).Return(nil)
+			serviceMock.On("Share", ctx, uint(1)).Return([]byte("share"), nil)
+			serviceMock.On("Ceremonies", ctx, uint(1)).Return([]models.RecoveryCeremonyDetail{*ceremony}, nil)
+			serviceMock.On("Start", ctx, uint(1), models.RecoveryCeremonyInput{Reason: "Owner left", PublicKey: []byte("public")}).Return(ceremony, nil)
+			serviceMock.On("Approve", ctx, uint(5), []byte("wrapped")).Return(ceremony, nil)
+			serviceMock.On("Cancel", ctx, uint(5)).Return(nil)
+			serviceMock.On("Complete", ctx, uint(5)).Return(&models.RecoveryShares{CeremonyID: 5, VaultID: 1, Shares: [][]byte{[]byte("a"), []byte("b")}}, nil)
+			serviceMock.On("Complete", ctx, uint(6)).Return(nil, cerrors.ConflictError("recovery ceremony is pending, not approved"))
+
+			mux := http.NewServeMux()
+			NewRecoveryHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.response == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tc.response, rec.Body.String())
+			}
+		})
+	}
+}
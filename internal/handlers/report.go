@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type reportHandler struct {
+	service services.IReportService
+}
+
+func NewReportHandler(service services.IReportService) *reportHandler {
+	return &reportHandler{service}
+}
+
+// Register registers the report routes on mux.
+func (h *reportHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/reports/health", h.GetHealth)
+}
+
+// GetHealth handles GET /reports/health.
+// It returns the health report of a single vault when the vaultId query parameter
+// is given, or of all vaults from the authenticated user otherwise. The optional
+// maxAgeDays query parameter sets the age after which passwords are reported as old.
+func (h *reportHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	vaultID, err := queryUint(r, "vaultId")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	maxAgeDays, err := queryUint(r, "maxAgeDays")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var report *models.HealthReport
+
+	if vaultID != 0 {
+		report, err = h.service.GetVaultReport(r.Context(), vaultID, maxAgeDays)
+	} else {
+		report, err = h.service.GetUserReport(r.Context(), maxAgeDays)
+	}
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReportHandler(t *testing.T) {
+	serviceMock := &mocks.ReportServiceMock{}
+
+	handler := NewReportHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestGetHealth(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	report := &models.HealthReport{UserID: 10, Score: 100}
+
+	t.Run("user report", func(t *testing.T) {
+		// given
+		serviceMock := &mocks.ReportServiceMock{}
+		serviceMock.On("GetUserReport", ctx, uint(90)).Return(report, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/reports/health?maxAgeDays=90", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		// when
+		handler := &reportHandler{service: serviceMock}
+		handler.GetHealth(rec, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"userId":10,"score":100,"totalItems":0,"reused":null,"weak":null,"old":null,"breached":null,"insecureUrls":null}`, rec.Body.String())
+
+		serviceMock.AssertExpectations(t)
+	})
+
+	t.Run("vault report", func(t *testing.T) {
+		// given
+		serviceMock := &mocks.ReportServiceMock{}
+		serviceMock.On("GetVaultReport", ctx, uint(1), uint(0)).Return(report, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/reports/health?vaultId=1", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		// when
+		handler := &reportHandler{service: serviceMock}
+		handler.GetHealth(rec, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		serviceMock.AssertExpectations(t)
+	})
+
+	testCases := []struct {
+		name     string
+		method   string
+		url      string
+		err      error
+		status   int
+		response string
+	}{
+		{"method not allowed", http.MethodPost, "/reports/health", nil, http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+		{"invalid vault id", http.MethodGet, "/reports/health?vaultId=abc", nil, http.StatusBadRequest, `{"message":"vaultId must be a positive integer"}`},
+		{"invalid max age", http.MethodGet, "/reports/health?maxAgeDays=-1", nil, http.StatusBadRequest, `{"message":"maxAgeDays must be a positive integer"}`},
+		{"vault not found", http.MethodGet, "/reports/health?vaultId=2", cerrors.NotFoundError("vault not found"), http.StatusNotFound, `{"message":"vault not found"}`},
+		{"unexpected error", http.MethodGet, "/reports/health?vaultId=2", errors.New("connection refused"), http.StatusInternalServerError, `{"message":"internal server error"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.ReportServiceMock{}
+			serviceMock.On("GetVaultReport", ctx, uint(2), uint(0)).Return(nil, tc.err)
+
+			req := httptest.NewRequest(tc.method, tc.url, nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			handler := &reportHandler{service: serviceMock}
+			handler.GetHealth(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			assert.JSONEq(t, tc.response, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type searchHandler struct {
+	service services.ISearchService
+}
+
+func NewSearchHandler(service services.ISearchService) *searchHandler {
+	return &searchHandler{service}
+}
+
+// Register registers the search routes on mux.
+func (h *searchHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/search", h.Search)
+}
+
+// Search handles GET /search?q=&limit=.
+// It returns the vaults and items from the authenticated user matching the query, best first.
+func (h *searchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	limit, err := queryUint(r, "limit")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	results, err := h.service.Search(r.Context(), r.URL.Query().Get("q"), limit)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
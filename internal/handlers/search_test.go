@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSearchHandler(t *testing.T) {
+	serviceMock := &mocks.SearchServiceMock{}
+
+	handler := NewSearchHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestSearchRoute(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	results := []models.SearchResult{{Kind: "item", ID: 5, VaultID: 1, VaultName: "Work", Name: "GitHub", Score: 3, Matches: []string{"name"}}}
+
+	testCases := []struct {
+		name   string
+		method string
+		target string
+		query  string
+		limit  uint
+		err    error
+		status int
+		body   string
+	}{
+		{"success", http.MethodGet, "/search?q=git+hub&limit=5", "git hub", 5, nil, http.StatusOK,
+			`[{"kind":"item","id":5,"vaultId":1,"vaultName":"Work","name":"GitHub","score":3,"matches":["name"]}]`},
+		{"query is required", http.MethodGet, "/search", "", 0, cerrors.BadRequestError("query is required"), http.StatusBadRequest, `{"message":"query is required"}`},
+		{"invalid limit", http.MethodGet, "/search?q=git&limit=-1", "", 0, nil, http.StatusBadRequest, `{"message":"limit must be a positive integer"}`},
+		{"method not allowed", http.MethodPost, "/search?q=git", "", 0, nil, http.StatusMethodNotAllowed, ""},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.SearchServiceMock{}
+			serviceMock.On("Search", ctx, tc.query, tc.limit).Return(results, tc.err)
+
+			mux := http.NewServeMux()
+			NewSearchHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.target, nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+
+			if tc.body != "" {
+				assert.JSONEq(t, tc.body, rec.Body.String())
+			}
+		})
+	}
+}
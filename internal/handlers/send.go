@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type sendHandler struct {
+	service services.ISendService
+}
+
+type accessSendRequest struct {
+	AccessKey string `json:"accessKey"`
+}
+
+func NewSendHandler(service services.ISendService) *sendHandler {
+	return &sendHandler{service}
+}
+
+// Register registers the routes managing the sends of the user on mux.
+func (h *sendHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/sends", h.handleSends)
+	mux.HandleFunc("/sends/", h.Delete)
+}
+
+// RegisterPublic registers the route opening sends on mux.
+// This route is public and must not be wrapped with Authenticate.
+func (h *sendHandler) RegisterPublic(mux *http.ServeMux) {
+	mux.HandleFunc("/send/", h.Access)
+}
+
+func (h *sendHandler) handleSends(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// GetAll handles GET /sends.
+// It returns the sends of the authenticated user, without their ciphertexts.
+func (h *sendHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	sends, err := h.service.GetAll(r.Context())
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sends)
+}
+
+// Create handles POST /sends.
+// It stores the send encrypted by the client in the request body.
+func (h *sendHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input models.SendInput
+
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	send, err := h.service.Create(r.Context(), input)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, send)
+}
+
+// Delete handles DELETE /sends/{id}.
+func (h *sendHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodDelete) {
+		return
+	}
+
+	id, err := pathID(r, "/sends/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// Access handles POST /send/{accessId}.
+// It counts a view of the send and responds with its ciphertext. It is a POST
+// so link previews and prefetches, which GET links, don't use up views.
+func (h *sendHandler) Access(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	accessID := strings.TrimPrefix(r.URL.Path, "/send/")
+
+	if accessID == "" || strings.Contains(accessID, "/") {
+		writeError(w, cerrors.NotFoundError("not found"))
+		return
+	}
+
+	var req accessSendRequest
+
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	content, err := h.service.Access(r.Context(), accessID, req.AccessKey)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, content)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewSendHandler(t *testing.T) {
+	serviceMock := &mocks.SendServiceMock{}
+
+	handler := NewSendHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestSendRoutes(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	expiresAt := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+	}{
+		{"list", http.MethodGet, "/sends", "", http.StatusOK, `[{"id":3,"accessId":"abc","maxViews":2,"views":1,"hasPassword":true,"expiresAt":"2026-10-20T12:00:00Z","createdAt":"2026-10-19T12:00:00Z"}]`},
+		{"create", http.MethodPost, "/sends", `{"ciphertext":"c2VhbGVk","accessKey":"access-key","maxViews":2,"expiresIn":86400}`, http.StatusCreated, `{"id":3,"accessId":"abc","maxViews":2,"views":0,"hasPassword":true,"expiresAt":"2026-10-20T12:00:00Z","createdAt":"2026-10-19T12:00:00Z"}`},
+		{"create without ciphertext", http.MethodPost, "/sends", `{}`, http.StatusBadRequest, `{"message":"ciphertext is required"}`},
+		{"delete", http.MethodDelete, "/sends/3", "", http.StatusNoContent, ``},
+		{"delete not found", http.MethodDelete, "/sends/4", "", http.StatusNotFound, `{"message":"send not found"}`},
+		{"method not allowed", http.MethodPut, "/sends", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.SendServiceMock{}
+			serviceMock.On("GetAll", ctx).Return([]models.SendDetail{
+				{ID: 3, AccessID: "abc", MaxViews: 2, Views: 1, HasPassword: true, ExpiresAt: expiresAt, CreatedAt: createdAt},
+			}, nil)
+			serviceMock.On("Create", ctx, models.SendInput{Ciphertext: []byte("sealed"), AccessKey: "access-key", MaxViews: 2, ExpiresIn: 86400}).
+				Return(&models.SendDetail{ID: 3, AccessID: "abc", MaxViews: 2, HasPassword: true, ExpiresAt: expiresAt, CreatedAt: createdAt}, nil)
+			serviceMock.On("Create", ctx, models.SendInput{}).Return(nil, cerrors.BadRequestError("ciphertext is required"))
+			serviceMock.On("Delete", ctx, uint(3)).Return(nil)
+			serviceMock.On("Delete", ctx, uint(4)).Return(cerrors.NotFoundError("send not found"))
+
+			mux := http.NewServeMux()
+			NewSendHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.response == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tc.response, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAccessSendRoute(t *testing.T) {
+	expiresAt := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+	}{
+		{"access", http.MethodPost, "/send/abc", `{}`, http.StatusOK, `{"ciphertext":"c2VhbGVk","viewsLeft":0,"expiresAt":"2026-10-20T12:00:00Z"}`},
+		{"access with password", http.MethodPost, "/send/def", `{"accessKey":"access-key"}`, http.StatusOK, `{"ciphertext":"c2VhbGVk","viewsLeft":1,"expiresAt":"2026-10-20T12:00:00Z"}`},
+		{"wrong password", http.MethodPost, "/send/def", `{"accessKey":"wrong"}`, http.StatusUnauthorized, `{"message":"invalid access password"}`},
+		{"gone", http.MethodPost, "/send/ghi", `{}`, http.StatusNotFound, `{"message":"send not found"}`},
+		{"no access ID", http.MethodPost, "/send/", `{}`, http.StatusNotFound, `{"message":"not found"}`},
+		{"invalid body", http.MethodPost, "/send/abc", `{`, http.StatusBadRequest, `{"message":"invalid request body"}`},
+		{"link preview", http.MethodGet, "/send/abc", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.SendServiceMock{}
+			serviceMock.On("Access", mock.Anything, "abc", "").Return(&models.SendContent{Ciphertext: []byte("sealed"), ExpiresAt: expiresAt}, nil)
+			serviceMock.On("Access", mock.Anything, "def", "access-key").Return(&models.SendContent{Ciphertext: []byte("sealed"), ViewsLeft: 1, ExpiresAt: expiresAt}, nil)
+			serviceMock.On("Access", mock.Anything, "def", "wrong").Return(nil, cerrors.UnauthorizedError("invalid access password"))
+			serviceMock.On("Access", mock.Anything, "ghi", "").Return(nil, cerrors.NotFoundError("send not found"))
+
+			mux := http.NewServeMux()
+			NewSendHandler(serviceMock).RegisterPublic(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			assert.JSONEq(t, tc.response, rec.Body.String())
+			if tc.status == http.StatusOK {
+				assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type syncHandler struct {
+	service services.ISyncService
+}
+
+func NewSyncHandler(service services.ISyncService) *syncHandler {
+	return &syncHandler{service}
+}
+
+// Register registers the sync routes on mux.
+func (h *syncHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/sync", h.Sync)
+}
+
+// Sync handles GET /sync?since=.
+// It returns the vaults and items from the authenticated user created, updated
+// or deleted since the given revision, and the revision to sync from next.
+func (h *syncHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	since, err := queryUint64(r, "since")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	changes, err := h.service.Changes(r.Context(), since)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, changes)
+}
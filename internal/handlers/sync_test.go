@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSyncHandler(t *testing.T) {
+	serviceMock := &mocks.SyncServiceMock{}
+
+	handler := NewSyncHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestSyncRoute(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	changes := &models.SyncChanges{
+		Revision: 12,
+		Vaults:   models.ChangeSet[models.VaultDetail]{Created: []models.VaultDetail{}, Updated: []models.VaultDetail{{ID: 1, Name: "Work", UserID: 10, Revision: 11}}, Deleted: []uint{}},
+		Items:    models.ChangeSet[models.ItemDetail]{Created: []models.ItemDetail{}, Updated: []models.ItemDetail{}, Deleted: []uint{5}},
+	}
+
+	testCases := []struct {
+		name   string
+		method string
+		target string
+		since  uint64
+		err    error
+		status int
+		body   string
+	}{
+		{"success", http.MethodGet, "/sync?since=7", 7, nil, http.StatusOK,
+			`{"revision":12,"vaults":{"created":[],"updated":[{"id":1,"name":"Work","userId":10,"revision":11}],"deleted":[]},"items":{"created":[],"updated":[],"deleted":[5]}}`},
+		{"full sync", http.MethodGet, "/sync", 0, nil, http.StatusOK, ""},
+		{"revision ahead", http.MethodGet, "/sync?since=99", 99, cerrors.ConflictError("revision is ahead of the server, sync from 0"), http.StatusConflict,
+			`{"message":"revision is ahead of the server, sync from 0"}`},
+		{"invalid since", http.MethodGet, "/sync?since=-1", 0, nil, http.StatusBadRequest, `{"message":"since must be a positive integer"}`},
+		{"method not allowed", http.MethodPost, "/sync", 0, nil, http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.SyncServiceMock{}
+			if tc.err != nil {
+				serviceMock.On("Changes", ctx, tc.since).Return(nil, tc.err)
+			} else {
+				serviceMock.On("Changes", ctx, tc.since).Return(changes, nil)
+			}
+
+			mux := http.NewServeMux()
+			NewSyncHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.target, nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+
+			if tc.body != "" {
+				assert.JSONEq(t, tc.body, rec.Body.String())
+			}
+		})
+	}
+}
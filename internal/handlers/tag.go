@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type tagHandler struct {
+	service services.ITagService
+}
+
+type tagRequest struct {
+	Name string `json:"name"`
+}
+
+func NewTagHandler(service services.ITagService) *tagHandler {
+	return &tagHandler{service}
+}
+
+// Register registers the tag routes on mux.
+func (h *tagHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/tags", h.GetAll)
+	mux.HandleFunc("/tags/", h.handleTag)
+}
+
+func (h *tagHandler) handleTag(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPatch:
+		h.Rename(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		methodNotAllowed(w, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+// GetAll handles GET /tags.
+// It returns all tags from the authenticated user.
+func (h *tagHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	tags, err := h.service.GetAll(r.Context())
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tags)
+}
+
+// Rename handles PATCH /tags/{id}.
+// The new name applies to every item with the tag.
+func (h *tagHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "/tags/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var req tagRequest
+
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Rename(r.Context(), id, req.Name); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// Delete handles DELETE /tags/{id}.
+// The tag is removed from every item that has it.
+func (h *tagHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "/tags/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
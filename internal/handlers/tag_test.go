@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTagHandler(t *testing.T) {
+	serviceMock := &mocks.TagServiceMock{}
+
+	handler := NewTagHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestTagRoutes(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+	}{
+		{"list", http.MethodGet, "/tags", "", http.StatusOK, `[{"id":7,"name":"work"}]`},
+		{"rename", http.MethodPatch, "/tags/7", `{"name":"job"}`, http.StatusNoContent, ``},
+		{"rename conflict", http.MethodPatch, "/tags/7", `{"name":"dev"}`, http.StatusConflict, `{"message":"tag already exists"}`},
+		{"delete", http.MethodDelete, "/tags/7", "", http.StatusNoContent, ``},
+		{"delete not found", http.MethodDelete, "/tags/8", "", http.StatusNotFound, `{"message":"tag not found"}`},
+		{"method not allowed", http.MethodPost, "/tags", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.TagServiceMock{}
+			serviceMock.On("GetAll", ctx).Return([]models.TagDetail{{ID: 7, Name: "work"}}, nil)
+			serviceMock.On("Rename", ctx, uint(7), "job").Return(nil)
+			serviceMock.On("Rename", ctx, uint(7), "dev").Return(cerrors.ConflictError("tag already exists"))
+			serviceMock.On("Delete", ctx, uint(7)).Return(nil)
+			serviceMock.On("Delete", ctx, uint(8)).Return(cerrors.NotFoundError("tag not found"))
+
+			mux := http.NewServeMux()
+			NewTagHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.response == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tc.response, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type totpHandler struct {
+	service services.ITOTPService
+}
+
+func NewTOTPHandler(service services.ITOTPService) *totpHandler {
+	return &totpHandler{service}
+}
+
+// Register registers the TOTP routes on mux.
+func (h *totpHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/totp/", h.GetCode)
+}
+
+// GetCode handles GET /totp/{itemId}.
+// It returns the current one-time password of the item and how long it remains valid.
+func (h *totpHandler) GetCode(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	id, err := pathID(r, "/totp/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	code, err := h.service.GetCode(r.Context(), id)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, code)
+}
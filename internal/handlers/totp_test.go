@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTOTPHandler(t *testing.T) {
+	serviceMock := &mocks.TOTPServiceMock{}
+
+	handler := NewTOTPHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestGetTOTPCode(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		code   *models.TOTPCode
+		err    error
+		status int
+		body   string
+	}{
+		{"success", http.MethodGet, "/totp/5", &models.TOTPCode{Code: "123456", Period: 30, Remaining: 12}, nil, http.StatusOK, `{"code":"123456","period":30,"remaining":12}`},
+		{"item without totp", http.MethodGet, "/totp/5", nil, cerrors.NotFoundError("item has no totp"), http.StatusNotFound, `{"message":"item has no totp"}`},
+		{"invalid id", http.MethodGet, "/totp/abc", nil, nil, http.StatusNotFound, `{"message":"not found"}`},
+		{"method not allowed", http.MethodPost, "/totp/5", nil, nil, http.StatusMethodNotAllowed, ""},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.TOTPServiceMock{}
+			serviceMock.On("GetCode", ctx, uint(5)).Return(tc.code, tc.err)
+
+			mux := http.NewServeMux()
+			NewTOTPHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+
+			if tc.body != "" {
+				assert.JSONEq(t, tc.body, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type userHandler struct {
+	service     services.IUserService
+	authService services.IAuthService
+}
+
+type signupRequest struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	AuthKey string `json:"authKey"`
+}
+
+type loginRequest struct {
+	Email   string `json:"email"`
+	AuthKey string `json:"authKey"`
+}
+
+type loginResponse struct {
+	Token string `json:"token"`
+}
+
+func NewUserHandler(service services.IUserService, authService services.IAuthService) *userHandler {
+	return &userHandler{service, authService}
+}
+
+// Register registers the signup and login routes on mux.
+// These routes are public and must not be wrapped with Authenticate.
+func (h *userHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/users", h.Signup)
+	mux.HandleFunc("/auth/login", h.Login)
+}
+
+// RegisterAccount registers the routes of the account of the authenticated user on mux.
+// These routes must be wrapped with Authenticate.
+func (h *userHandler) RegisterAccount(mux *http.ServeMux) {
+	mux.HandleFunc("/account/password", h.ChangePassword)
+}
+
+// Signup handles POST /users.
+// It creates a user and responds with its ID.
+func (h *userHandler) Signup(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req signupRequest
+
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	id, err := h.service.Create(r.Context(), req.Name, req.Email, req.AuthKey)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createdResponse{ID: id})
+}
+
+// Login handles POST /auth/login.
+// It responds with a bearer token for the user.
+func (h *userHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req loginRequest
+
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	token, err := h.authService.Login(r.Context(), req.Email, req.AuthKey)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, loginResponse{Token: token})
+}
+
+// ChangePassword handles POST /account/password.
+// It changes the master password of the user, revoking all their tokens.
+func (h *userHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var change models.PasswordChange
+
+	if err := decodeJSON(r, &change); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.ChangePassword(r.Context(), change); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUserHandler(t *testing.T) {
+	serviceMock := &mocks.UserServiceMock{}
+	authServiceMock := &mocks.AuthServiceMock{}
+
+	handler := NewUserHandler(serviceMock, authServiceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+	assert.Equal(t, authServiceMock, handler.authService)
+}
+
+func TestSignupHandler(t *testing.T) {
+	ctx := context.TODO()
+
+	testCases := []struct {
+		name     string
+		method   string
+		body     string
+		status   int
+		response string
+	}{
+		{"success", http.MethodPost, `{"name":"John","email":"john@test.com","authKey":"key"}`, http.StatusCreated, `{"id":1}`},
+		{"user already exists", http.MethodPost, `{"name":"John","email":"taken@test.com","authKey":"key"}`, http.StatusConflict, `{"message":"user already exists"}`},
+		{"invalid body", http.MethodPost, `{`, http.StatusBadRequest, `{"message":"invalid request body"}`},
+		{"method not allowed", http.MethodGet, "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.UserServiceMock{}
+			serviceMock.On("Create", ctx, "John", "john@test.com", "key").Return(uint(1), nil)
+			serviceMock.On("Create", ctx, "John", "taken@test.com", "key").Return(uint(0), cerrors.ConflictError("user already exists"))
+
+			req := httptest.NewRequest(tc.method, "/users", strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			handler := &userHandler{serviceMock, &mocks.AuthServiceMock{}}
+			handler.Signup(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			assert.JSONEq(t, tc.response, rec.Body.String())
+		})
+	}
+}
+
+func TestLoginHandler(t *testing.T) {
+	ctx := context.TODO()
+
+	testCases := []struct {
+		name     string
+		body     string
+		status   int
+		response string
+	}{
+		{"success", `{"email":"john@test.com","authKey":"key"}`, http.StatusOK, `{"token":"jwt-token"}`},
+		{"invalid credentials", `{"email":"john@test.com","authKey":"wrong"}`, http.StatusUnauthorized, `{"message":"invalid credentials"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			authServiceMock := &mocks.AuthServiceMock{}
+			authServiceMock.On("Login", ctx, "john@test.com", "key").Return("jwt-token", nil)
+			authServiceMock.On("Login", ctx, "john@test.com", "wrong").Return("", cerrors.UnauthorizedError("invalid credentials"))
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			handler := &userHandler{&mocks.UserServiceMock{}, authServiceMock}
+			handler.Login(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			assert.JSONEq(t, tc.response, rec.Body.String())
+		})
+	}
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	testCases := []struct {
+		name     string
+		method   string
+		body     string
+		status   int
+		response string
+	}{
+		{"success", http.MethodPost, `{"currentAuthKey":"old","newAuthKey":"new","encryptedPrivateKey":"c2VhbGVk"}`, http.StatusNoContent, ``},
+		{"invalid credentials", http.MethodPost, `{"currentAuthKey":"wrong","newAuthKey":"new"}`, http.StatusUnauthorized, `{"message":"invalid credentials"}`},
+		{"method not allowed", http.MethodGet, "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.UserServiceMock{}
+			serviceMock.On("ChangePassword", ctx, models.PasswordChange{CurrentAuthKey: "old", NewAuthKey: "new", EncryptedPrivateKey: []byte("sealed")}).Return(nil)
+			serviceMock.On("ChangePassword", ctx, models.PasswordChange{CurrentAuthKey: "wrong", NewAuthKey: "new"}).Return(cerrors.UnauthorizedError("invalid credentials"))
+
+			mux := http.NewServeMux()
+			NewUserHandler(serviceMock, &mocks.AuthServiceMock{}).RegisterAccount(mux)
+
+			req := httptest.NewRequest(tc.method, "/account/password", strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.response == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tc.response, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type vaultHandler struct {
+	service services.IVaultService
+}
+
+type vaultRequest struct {
+	Name     string `json:"name"`
+	Revision uint64 `json:"revision,omitempty"`
+}
+
+func NewVaultHandler(service services.IVaultService) *vaultHandler {
+	return &vaultHandler{service}
+}
+
+// Register registers the vault routes on mux.
+func (h *vaultHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/vaults", h.handleVaults)
+	mux.HandleFunc("/vaults/", h.handleVault)
+}
+
+func (h *vaultHandler) handleVaults(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *vaultHandler) handleVault(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPatch:
+		h.Rename(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		methodNotAllowed(w, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+// GetAll handles GET /vaults.
+// It returns all vaults from the authenticated user.
+func (h *vaultHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	vaults, err := h.service.GetAll(r.Context())
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, vaults)
+}
+
+// Create handles POST /vaults.
+// It creates a vault and responds with its ID.
+func (h *vaultHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req vaultRequest
+
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	id, err := h.service.Create(r.Context(), req.Name)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createdResponse{ID: id})
+}
+
+// Rename handles PATCH /vaults/{id}.
+// The revision the rename is based on is taken from the If-Match header, or
+// from the request body when the header is missing.
+func (h *vaultHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "/vaults/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var req vaultRequest
+
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	revision, err := ifMatch(r, req.Revision)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Rename(r.Context(), id, req.Name, revision); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// Delete handles DELETE /vaults/{id}.
+// It deletes the vault along with all its items.
+func (h *vaultHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "/vaults/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
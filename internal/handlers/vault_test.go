@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewVaultHandler(t *testing.T) {
+	serviceMock := &mocks.VaultServiceMock{}
+
+	handler := NewVaultHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestVaultRoutes(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+	}{
+		{"list", http.MethodGet, "/vaults", "", http.StatusOK, `[{"id":1,"name":"Work","userId":10,"revision":3}]`},
+		{"create", http.MethodPost, "/vaults", `{"name":"Work"}`, http.StatusCreated, `{"id":1}`},
+		{"create invalid body", http.MethodPost, "/vaults", `{`, http.StatusBadRequest, `{"message":"invalid request body"}`},
+		{"rename", http.MethodPatch, "/vaults/1", `{"name":"Personal","revision":3}`, http.StatusNoContent, ``},
+		{"rename conflict", http.MethodPatch, "/vaults/1", `{"name":"Taken","revision":3}`, http.StatusConflict, `{"message":"vault already exists"}`},
+		{"rename stale", http.MethodPatch, "/vaults/1", `{"name":"Personal","revision":2}`, http.StatusPreconditionFailed, `{"message":"vault was changed at revision 3, since revision 2"}`},
+		{"delete", http.MethodDelete, "/vaults/1", "", http.StatusNoContent, ``},
+		{"delete not found", http.MethodDelete, "/vaults/2", "", http.StatusNotFound, `{"message":"vault not found"}`},
+		{"invalid id", http.MethodDelete, "/vaults/abc", "", http.StatusNotFound, `{"message":"not found"}`},
+		{"method not allowed", http.MethodPut, "/vaults", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.VaultServiceMock{}
+			serviceMock.On("GetAll", ctx).Return([]models.VaultDetail{{ID: 1, Name: "Work", UserID: 10, Revision: 3}}, nil)
+			serviceMock.On("Create", ctx, "Work").Return(uint(1), nil)
+			serviceMock.On("Rename", ctx, uint(1), "Personal", uint64(3)).Return(nil)
+			serviceMock.On("Rename", ctx, uint(1), "Personal", uint64(2)).Return(cerrors.PreconditionFailedError("vault was changed at revision 3, since revision 2"))
+			serviceMock.On("Rename", ctx, uint(1), "Taken", uint64(3)).Return(cerrors.ConflictError("vault already exists"))
+			serviceMock.On("Delete", ctx, uint(1)).Return(nil)
+			serviceMock.On("Delete", ctx, uint(2)).Return(cerrors.NotFoundError("vault not found"))
+
+			mux := http.NewServeMux()
+			NewVaultHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.response == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tc.response, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestVaultRenameIfMatch(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	// given
+	serviceMock := &mocks.VaultServiceMock{}
+	serviceMock.On("Rename", ctx, uint(1), "Personal", uint64(3)).Return(nil)
+
+	mux := http.NewServeMux()
+	NewVaultHandler(serviceMock).Register(mux)
+
+	req := httptest.NewRequest(http.MethodPatch, "/vaults/1", strings.NewReader(`{"name":"Personal"}`)).WithContext(ctx)
+	req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+
+	// when
+	mux.ServeHTTP(rec, req)
+
+	// then
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	serviceMock.AssertExpectations(t)
+}
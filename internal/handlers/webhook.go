@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/services"
+)
+
+type webhookHandler struct {
+	service services.IWebhookService
+}
+
+func NewWebhookHandler(service services.IWebhookService) *webhookHandler {
+	return &webhookHandler{service}
+}
+
+// Register registers the webhook routes on mux.
+func (h *webhookHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/webhooks", h.handleWebhooks)
+	mux.HandleFunc("/webhooks/", h.Delete)
+	mux.HandleFunc("/webhooks/dead-letters", h.DeadLetters)
+	mux.HandleFunc("/webhooks/dead-letters/", h.handleDeadLetter)
+}
+
+func (h *webhookHandler) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *webhookHandler) handleDeadLetter(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Redeliver(w, r)
+	case http.MethodDelete:
+		h.Discard(w, r)
+	default:
+		methodNotAllowed(w, http.MethodPost, http.MethodDelete)
+	}
+}
+
+// GetAll handles GET /webhooks.
+// It returns all webhooks from the authenticated user.
+func (h *webhookHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.GetAll(r.Context())
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+// Create handles POST /webhooks.
+// It creates a webhook from the request body and responds with it, along
+// with the secret signing its deliveries.
+func (h *webhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input models.WebhookInput
+
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	webhook, err := h.service.Create(r.Context(), input)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, webhook)
+}
+
+// Delete handles DELETE /webhooks/{id}.
+func (h *webhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodDelete) {
+		return
+	}
+
+	id, err := pathID(r, "/webhooks/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// DeadLetters handles GET /webhooks/dead-letters.
+// It returns the events that couldn't be delivered, newest first.
+func (h *webhookHandler) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	deadLetters, err := h.service.DeadLetters(r.Context())
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deadLetters)
+}
+
+// Redeliver handles POST /webhooks/dead-letters/{id}.
+// It queues the event for delivery again and responds once it is queued.
+func (h *webhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "/webhooks/dead-letters/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Redeliver(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, nil)
+}
+
+// Discard handles DELETE /webhooks/dead-letters/{id}.
+func (h *webhookHandler) Discard(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "/webhooks/dead-letters/")
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.service.Discard(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
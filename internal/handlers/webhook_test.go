@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWebhookHandler(t *testing.T) {
+	serviceMock := &mocks.WebhookServiceMock{}
+
+	handler := NewWebhookHandler(serviceMock)
+
+	assert.Equal(t, serviceMock, handler.service)
+}
+
+func TestWebhookRoutes(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	vaultID := uint(1)
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+	}{
+		{"list", http.MethodGet, "/webhooks", "", http.StatusOK, `[{"id":3,"url":"https://ci.acme.com/hook","events":["item.updated"],"vaultId":1,"createdAt":"2026-10-19T12:00:00Z"}]`},
+		{"create", http.MethodPost, "/webhooks", `{"url":"https://ci.acme.com/hook","events":["item.updated"],"vaultId":1}`, http.StatusCreated, `{"id":3,"url":"https://ci.acme.com/hook","events":["item.updated"],"vaultId":1,"secret":"whsec_secret","createdAt":"2026-10-19T12:00:00Z"}`},
+		{"create over http", http.MethodPost, "/webhooks", `{"url":"http://ci.acme.com/hook"}`, http.StatusBadRequest, `{"message":"url must use https"}`},
+		{"delete", http.MethodDelete, "/webhooks/3", "", http.StatusNoContent, ``},
+		{"delete not found", http.MethodDelete, "/webhooks/4", "", http.StatusNotFound, `{"message":"webhook not found"}`},
+		{"dead letters", http.MethodGet, "/webhooks/dead-letters", "", http.StatusOK, `[{"id":7,"webhookId":3,"eventId":"e1","eventType":"item.updated","attempts":5,"lastError":"webhook responded with 500 Internal Server Error","failedAt":"2026-10-19T12:00:00Z"}]`},
+		{"redeliver", http.MethodPost, "/webhooks/dead-letters/7", "", http.StatusAccepted, ``},
+		{"redeliver not found", http.MethodPost, "/webhooks/dead-letters/8", "", http.StatusNotFound, `{"message":"dead letter not found"}`},
+		{"discard", http.MethodDelete, "/webhooks/dead-letters/7", "", http.StatusNoContent, ``},
+		{"method not allowed", http.MethodGet, "/webhooks/3", "", http.StatusMethodNotAllowed, `{"message":"method not allowed"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			serviceMock := &mocks.WebhookServiceMock{}
+			serviceMock.On("GetAll", ctx).Return([]models.WebhookDetail{
+				{ID: 3, URL: "https://ci.acme.com/hook", Events: []string{models.EventItemUpdated}, VaultID: &vaultID, CreatedAt: createdAt},
+			}, nil)
+			serviceMock.On("Create", ctx, models.WebhookInput{URL: "https://ci.acme.com/hook", Events: []string{models.EventItemUpdated}, VaultID: &vaultID}).
+				Return(&models.WebhookDetail{ID: 3, URL: "https://ci.acme.com/hook", Events: []string{models.EventItemUpdated}, VaultID: &vaultID, Secret: "whsec_secret", CreatedAt: createdAt}, nil)
+			serviceMock.On("Create", ctx, models.WebhookInput{URL: "http://ci.acme.com/hook"}).Return(nil, cerrors.BadRequestError("url must use https"))
+			serviceMock.On("Delete", ctx, uint(3)).Return(nil)
+			serviceMock.On("Delete", ctx, uint(4)).Return(cerrors.NotFoundError("webhook not found"))
+			serviceMock.On("DeadLetters", ctx).Return([]models.WebhookDeadLetterDetail{
+				{ID: 7, WebhookID: 3, EventID: "e1", EventType: models.EventItemUpdated, Attempts: 5, LastError: "webhook responded with 500 Internal Server Error", FailedAt: createdAt},
+			}, nil)
+			serviceMock.On("Redeliver", ctx, uint(7)).Return(nil)
+			serviceMock.On("Redeliver", ctx, uint(8)).Return(cerrors.NotFoundError("dead letter not found"))
+			serviceMock.On("Discard", ctx, uint(7)).Return(nil)
+
+			mux := http.NewServeMux()
+			NewWebhookHandler(serviceMock).Register(mux)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			// when
+			mux.ServeHTTP(rec, req)
+
+			// then
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.response == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tc.response, rec.Body.String())
+			}
+		})
+	}
+}
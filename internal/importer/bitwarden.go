@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+const (
+	bitwardenLogin      = 1
+	bitwardenSecureNote = 2
+)
+
+type bitwardenExport struct {
+	Encrypted   bool              `json:"encrypted"`
+	Folders     []bitwardenFolder `json:"folders"`
+	Collections []bitwardenFolder `json:"collections"`
+	Items       []bitwardenItem   `json:"items"`
+}
+
+type bitwardenFolder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type bitwardenItem struct {
+	Type          int      `json:"type"`
+	Name          string   `json:"name"`
+	Notes         string   `json:"notes"`
+	FolderID      string   `json:"folderId"`
+	CollectionIDs []string `json:"collectionIds"`
+	Login         *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Uris     []struct {
+			Uri string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+}
+
+// parseBitwarden parses an unencrypted Bitwarden JSON export.
+// Folders, or collections in organization exports, become vaults.
+func parseBitwarden(data []byte) (*Result, error) {
+	var export bitwardenExport
+
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid bitwarden export: %w", err)
+	}
+
+	if export.Encrypted {
+		return nil, errors.New("encrypted bitwarden exports are not supported")
+	}
+
+	folders := map[string]string{}
+	for _, f := range export.Folders {
+		folders[f.ID] = f.Name
+	}
+	for _, c := range export.Collections {
+		folders[c.ID] = c.Name
+	}
+
+	result := &Result{}
+
+	for i, entry := range export.Items {
+		row := i + 1
+
+		vault := folders[entry.FolderID]
+		if vault == "" && len(entry.CollectionIDs) > 0 {
+			vault = folders[entry.CollectionIDs[0]]
+		}
+
+		item := models.Item{Name: entry.Name, Notes: entry.Notes}
+
+		switch entry.Type {
+		case bitwardenLogin:
+			if entry.Login != nil {
+				item.Username = entry.Login.Username
+				item.Password = entry.Login.Password
+				if len(entry.Login.Uris) > 0 {
+					item.Url = entry.Login.Uris[0].Uri
+				}
+			}
+		case bitwardenSecureNote:
+		default:
+			result.fail(row, fmt.Errorf("unsupported item type %d", entry.Type))
+			continue
+		}
+
+		result.add(row, vault, item)
+	}
+
+	return result, nil
+}
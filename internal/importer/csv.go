@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+// lastPassSecureNote is the URL LastPass uses for secure notes.
+const lastPassSecureNote = "http://sn"
+
+// csvTable reads a CSV export with a header row.
+type csvTable struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVTable(data []byte, required ...string) (*csvTable, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing header row")
+		}
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing %q column", name)
+		}
+	}
+
+	return &csvTable{reader, columns}, nil
+}
+
+// next returns the next row and its line number.
+// It returns io.EOF when there are no more rows.
+func (t *csvTable) next() (func(column string) string, int, error) {
+	fields, err := t.reader.Read()
+
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr.StartLine, err
+		}
+		return nil, 0, err
+	}
+
+	line, _ := t.reader.FieldPos(0)
+
+	get := func(column string) string {
+		i, ok := t.columns[column]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+		return fields[i]
+	}
+
+	return get, line, nil
+}
+
+// parseCSV reads every row of a CSV export with fn.
+func parseCSV(data []byte, name string, required []string, fn func(result *Result, row int, get func(string) string)) (*Result, error) {
+	table, err := newCSVTable(data, required...)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s export: %w", name, err)
+	}
+
+	result := &Result{}
+
+	for {
+		get, row, err := table.next()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result.fail(row, parseErr.Err)
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s export: %w", name, err)
+		}
+
+		fn(result, row, get)
+	}
+
+	return result, nil
+}
+
+// parseLastPass parses a LastPass CSV export.
+// Groupings become vaults, with nested groupings joined by "/".
+func parseLastPass(data []byte) (*Result, error) {
+	required := []string{"url", "username", "password", "name"}
+
+	return parseCSV(data, "lastpass", required, func(result *Result, row int, get func(string) string) {
+		item := models.Item{
+			Name:     get("name"),
+			Url:      get("url"),
+			Username: get("username"),
+			Password: get("password"),
+			Notes:    get("extra"),
+		}
+
+		if item.Url == lastPassSecureNote {
+			item.Url = ""
+		}
+
+		vault := strings.ReplaceAll(get("grouping"), "\\", "/")
+
+		result.add(row, vault, item)
+	})
+}
+
+// parseBrowserCSV parses a password export from Chrome, Edge or Firefox.
+// Browsers have no folders, so every item goes to the default vault.
+func parseBrowserCSV(data []byte) (*Result, error) {
+	required := []string{"url", "username", "password"}
+
+	return parseCSV(data, "browser", required, func(result *Result, row int, get func(string) string) {
+		item := models.Item{
+			Name:     get("name"),
+			Url:      get("url"),
+			Username: get("username"),
+			Password: get("password"),
+			Notes:    get("note"),
+		}
+
+		result.add(row, "", item)
+	})
+}
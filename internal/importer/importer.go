@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+// Format identifies the application an export comes from.
+type Format string
+
+const (
+	Bitwarden   Format = "bitwarden"
+	OnePassword Format = "1pux"
+	LastPass    Format = "lastpass"
+	BrowserCSV  Format = "csv"
+	KeePassXML  Format = "keepass"
+	// KeePassKDBX databases are encrypted and are parsed with ParseKDBX instead of Parse.
+	KeePassKDBX Format = "kdbx"
+)
+
+// DefaultVault is the vault used for entries that are not in any folder.
+const DefaultVault = "Imported"
+
+// ErrUnsupportedFormat is returned by Parse for unknown formats.
+var ErrUnsupportedFormat = errors.New("unsupported import format")
+
+// Record is an item read from an export, along with the vault it goes to.
+type Record struct {
+	// Row is the position of the entry in the export, starting at 1.
+	// It is the line number for CSV exports and the entry number otherwise.
+	Row   int
+	Vault string
+	Item  models.Item
+}
+
+// RowError reports an entry of an export that could not be imported.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// Result holds the records parsed from an export.
+// Entries that could not be parsed are reported in Errors and don't stop the parsing.
+type Result struct {
+	Records []Record
+	Errors  []RowError
+}
+
+// Vaults returns the vaults the records go to, in order of appearance.
+func (r *Result) Vaults() []models.Vault {
+	seen := map[string]bool{}
+	vaults := []models.Vault{}
+
+	for _, record := range r.Records {
+		if !seen[record.Vault] {
+			seen[record.Vault] = true
+			vaults = append(vaults, models.Vault{Name: record.Vault})
+		}
+	}
+
+	return vaults
+}
+
+// Parse parses an export in the given format.
+// It returns an error when the export as a whole can't be read.
+func Parse(format Format, data []byte) (*Result, error) {
+	switch format {
+	case Bitwarden:
+		return parseBitwarden(data)
+	case OnePassword:
+		return parseOnePassword(data)
+	case LastPass:
+		return parseLastPass(data)
+	case BrowserCSV:
+		return parseBrowserCSV(data)
+	case KeePassXML:
+		return parseKeePassXML(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// add validates and appends an item to the result.
+// Items without a name are named after their URL host or username.
+func (r *Result) add(row int, vault string, item models.Item) {
+	item.Name = strings.TrimSpace(item.Name)
+	item.Url = strings.TrimSpace(item.Url)
+	item.Username = strings.TrimSpace(item.Username)
+
+	if item.Name == "" {
+		item.Name = host(item.Url)
+	}
+
+	if item.Name == "" {
+		item.Name = item.Username
+	}
+
+	if item.Name == "" {
+		r.fail(row, errors.New("name is required"))
+		return
+	}
+
+	if item.Password == "" && item.Notes == "" && item.Username == "" && item.TOTP == "" && item.SSHKey == "" {
+		r.fail(row, errors.New("entry has no credentials"))
+		return
+	}
+
+	vault = strings.TrimSpace(vault)
+	if vault == "" {
+		vault = DefaultVault
+	}
+
+	r.Records = append(r.Records, Record{Row: row, Vault: vault, Item: item})
+}
+
+func (r *Result) fail(row int, err error) {
+	r.Errors = append(r.Errors, RowError{Row: row, Err: err})
+}
+
+// host returns the host of a URL, or an empty string if it has none.
+func host(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
@@ -0,0 +1,246 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/kdbx"
+	"github.com/stretchr/testify/assert"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	assert.Nil(t, err)
+	return data
+}
+
+func onePasswordFixture(t *testing.T) []byte {
+	exportData := `{
+		"accounts": [{
+			"vaults": [{
+				"attrs": {"name": "Private"},
+				"items": [
+					{
+						"categoryUuid": "001",
+						"overview": {"title": "Dropbox", "url": "https://dropbox.com"},
+						"details": {
+							"loginFields": [
+								{"value": "carol", "designation": "username"},
+								{"value": "drop-pass", "designation": "password"},
+								{"value": "on", "designation": ""}
+							],
+							"notesPlain": "2fa enabled"
+						}
+					},
+					{
+						"categoryUuid": "005",
+						"overview": {"title": "Router"},
+						"details": {"password": "router-pass"}
+					},
+					{
+						"categoryUuid": "002",
+						"overview": {"title": "Amex"},
+						"details": {}
+					}
+				]
+			}]
+		}]
+	}`
+
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+
+	w, err := archive.Create("export.attributes")
+	assert.Nil(t, err)
+	w.Write([]byte(`{"version":3}`))
+
+	w, err = archive.Create("export.data")
+	assert.Nil(t, err)
+	w.Write([]byte(exportData))
+
+	assert.Nil(t, archive.Close())
+
+	return buf.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		format  Format
+		data    []byte
+		records []Record
+		errors  []string
+	}{
+		{
+			name:   "bitwarden",
+			format: Bitwarden,
+			data:   readFixture(t, "bitwarden.json"),
+			records: []Record{
+				{Row: 1, Vault: "Social", Item: models.Item{Name: "Twitter", Url: "https://twitter.com/login", Username: "jdoe", Password: "tw1tter-pass", Notes: "personal account"}},
+				{Row: 2, Vault: DefaultVault, Item: models.Item{Name: "Wifi", Notes: "SSID: home / psk: s3cret"}},
+				{Row: 4, Vault: DefaultVault, Item: models.Item{Name: "github.com", Url: "github.com", Password: "p4ss"}},
+			},
+			errors: []string{"row 3: unsupported item type 3"},
+		},
+		{
+			name:   "1password",
+			format: OnePassword,
+			data:   onePasswordFixture(t),
+			records: []Record{
+				{Row: 1, Vault: "Private", Item: models.Item{Name: "Dropbox", Url: "https://dropbox.com", Username: "carol", Password: "drop-pass", Notes: "2fa enabled"}},
+				{Row: 2, Vault: "Private", Item: models.Item{Name: "Router", Password: "router-pass"}},
+			},
+			errors: []string{`row 3: unsupported item category "002"`},
+		},
+		{
+			name:   "lastpass",
+			format: LastPass,
+			data:   readFixture(t, "lastpass.csv"),
+			records: []Record{
+				{Row: 2, Vault: "Work/Email", Item: models.Item{Name: "Mail", Url: "https://mail.example.com", Username: "john", Password: "mail-pass"}},
+				{Row: 3, Vault: "Work", Item: models.Item{Name: "Office door", Notes: "door code 1234"}},
+				{Row: 4, Vault: DefaultVault, Item: models.Item{Name: "Bank", Url: "https://bank.example.com", Username: "john", Password: "bank-pass"}},
+			},
+			errors: []string{"row 5: name is required"},
+		},
+		{
+			name:   "chrome",
+			format: BrowserCSV,
+			data:   readFixture(t, "chrome.csv"),
+			records: []Record{
+				{Row: 2, Vault: DefaultVault, Item: models.Item{Name: "example.com", Url: "https://example.com/", Username: "alice", Password: "ex-pass"}},
+				{Row: 3, Vault: DefaultVault, Item: models.Item{Name: "accounts.google.com", Url: "https://accounts.google.com/signin", Username: "alice@gmail.com", Password: "g-pass", Notes: "my note"}},
+			},
+		},
+		{
+			name:   "firefox",
+			format: BrowserCSV,
+			data:   readFixture(t, "firefox.csv"),
+			records: []Record{
+				{Row: 2, Vault: DefaultVault, Item: models.Item{Name: "www.mozilla.org", Url: "https://www.mozilla.org", Username: "bob", Password: "moz-pass"}},
+				{Row: 3, Vault: DefaultVault, Item: models.Item{Name: "broken.example.com", Url: "https://broken.example.com", Username: "bob", Password: `bro"ken`}},
+			},
+		},
+		{
+			name:   "keepass",
+			format: KeePassXML,
+			data:   readFixture(t, "keepass.xml"),
+			records: []Record{
+				{Row: 1, Vault: "Passwords", Item: models.Item{Name: "Root entry", Username: "root", Password: "r00t"}},
+				{Row: 2, Vault: "Internet/Email", Item: models.Item{Name: "Webmail", Url: "https://webmail.example.com", Username: "me@example.com", Password: "mail", Notes: "imap"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			result, err := Parse(tc.format, tc.data)
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, tc.records, result.Records)
+
+			errors := []string{}
+			for _, rowErr := range result.Errors {
+				errors = append(errors, rowErr.Error())
+			}
+			if tc.errors == nil {
+				tc.errors = []string{}
+			}
+			assert.Equal(t, tc.errors, errors)
+		})
+	}
+}
+
+func TestParseInvalidExports(t *testing.T) {
+	testCases := []struct {
+		name   string
+		format Format
+		data   string
+		err    string
+	}{
+		{"unsupported format", Format("dashlane"), "", "unsupported import format"},
+		{"invalid bitwarden json", Bitwarden, "{", "invalid bitwarden export: unexpected end of JSON input"},
+		{"encrypted bitwarden", Bitwarden, `{"encrypted": true}`, "encrypted bitwarden exports are not supported"},
+		{"invalid 1pux", OnePassword, "not a zip", "invalid 1pux export: zip: not a valid zip file"},
+		{"missing lastpass columns", LastPass, "url,username\n", `invalid lastpass export: missing "password" column`},
+		{"empty csv", BrowserCSV, "", "invalid browser export: missing header row"},
+		{"invalid keepass xml", KeePassXML, "<KeePassFile>", "invalid keepass export: XML syntax error on line 1: unexpected EOF"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			result, err := Parse(tc.format, []byte(tc.data))
+
+			// then
+			assert.Nil(t, result)
+			assert.Equal(t, tc.err, err.Error())
+		})
+	}
+}
+
+func TestResultVaults(t *testing.T) {
+	// given
+	result, err := Parse(LastPass, readFixture(t, "lastpass.csv"))
+	assert.Nil(t, err)
+
+	// when
+	vaults := result.Vaults()
+
+	// then
+	assert.Equal(t, []models.Vault{{Name: "Work/Email"}, {Name: "Work"}, {Name: DefaultVault}}, vaults)
+}
+
+func TestParseKDBX(t *testing.T) {
+	recycleBin := kdbx.NewUUID()
+	db := &kdbx.Database{
+		Name:       "Passwords",
+		RecycleBin: recycleBin,
+		Root: kdbx.Group{
+			Name:    "Passwords",
+			Entries: []kdbx.Entry{{Title: "Root entry", UserName: "root", Password: "r00t"}},
+			Groups: []kdbx.Group{
+				{
+					Name: "Internet",
+					Groups: []kdbx.Group{{
+						Name:    "Email",
+						Entries: []kdbx.Entry{{Title: "Webmail", URL: "https://webmail.example.com", UserName: "me", Password: "mail", Notes: "imap"}},
+					}},
+				},
+				{UUID: recycleBin, Name: "Recycle Bin", Entries: []kdbx.Entry{{Title: "Deleted", Password: "gone"}}},
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	settings := kdbx.Settings{Cipher: kdbx.ChaCha20, KDF: kdbx.Argon2d, Iterations: 1, Memory: 64 << 10, Parallelism: 1}
+	assert.Nil(t, kdbx.Write(buf, db, kdbx.NewPasswordKey("master"), settings))
+
+	t.Run("success", func(t *testing.T) {
+		// when
+		result, err := ParseKDBX(buf.Bytes(), "master")
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, []Record{
+			{Row: 1, Vault: "Passwords", Item: models.Item{Name: "Root entry", Username: "root", Password: "r00t"}},
+			{Row: 2, Vault: "Internet/Email", Item: models.Item{Name: "Webmail", Url: "https://webmail.example.com", Username: "me", Password: "mail", Notes: "imap"}},
+		}, result.Records)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		// when
+		result, err := ParseKDBX(buf.Bytes(), "wrong")
+
+		// then
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInvalidKDBXKey, err)
+	})
+}
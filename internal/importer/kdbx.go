@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/kdbx"
+)
+
+// Custom fields of KDBX entries holding the TOTP secret and the SSH key of an item.
+// KDBXTOTPField is the field KeePassXC reads TOTP secrets from.
+const (
+	KDBXTOTPField   = "otp"
+	KDBXSSHKeyField = "SSH Key"
+)
+
+// ErrInvalidKDBXKey is returned by ParseKDBX when the password doesn't open the database.
+var ErrInvalidKDBXKey = errors.New("invalid kdbx password")
+
+// ParseKDBX parses a KeePass KDBX 4 database protected with password.
+// Groups become vaults the same way as in KeePass XML exports.
+// Entry tags, and the TOTP secret and SSH key fields, are kept on the items.
+func ParseKDBX(data []byte, password string) (*Result, error) {
+	if password == "" {
+		return nil, errors.New("kdbx password is required")
+	}
+
+	db, err := kdbx.Read(bytes.NewReader(data), kdbx.NewPasswordKey(password))
+
+	if errors.Is(err, kdbx.ErrInvalidCredentials) {
+		return nil, ErrInvalidKDBXKey
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	row := 0
+
+	var walk func(group kdbx.Group, path []string)
+	walk = func(group kdbx.Group, path []string) {
+		if db.RecycleBin != (kdbx.UUID{}) && group.UUID == db.RecycleBin {
+			return
+		}
+
+		vault := group.Name
+		if len(path) > 0 {
+			vault = strings.Join(path, "/")
+		}
+
+		for _, entry := range group.Entries {
+			row++
+
+			item := models.Item{
+				Name:     entry.Title,
+				Url:      entry.URL,
+				Username: entry.UserName,
+				Password: entry.Password,
+				Notes:    entry.Notes,
+			}
+
+			for _, field := range entry.Fields {
+				switch field.Key {
+				case KDBXTOTPField:
+					item.TOTP = field.Value
+				case KDBXSSHKeyField:
+					item.SSHKey = field.Value
+				}
+			}
+
+			for _, tag := range entry.Tags {
+				item.Tags = append(item.Tags, models.Tag{Name: tag})
+			}
+
+			result.add(row, vault, item)
+		}
+
+		for _, child := range group.Groups {
+			walk(child, append(append([]string{}, path...), child.Name))
+		}
+	}
+
+	walk(db.Root, nil)
+
+	return result, nil
+}
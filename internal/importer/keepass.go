@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+type keePassFile struct {
+	Meta struct {
+		RecycleBinUUID string `xml:"RecycleBinUUID"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []keePassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keePassGroup struct {
+	UUID    string         `xml:"UUID"`
+	Name    string         `xml:"Name"`
+	Entries []keePassEntry `xml:"Entry"`
+	Groups  []keePassGroup `xml:"Group"`
+}
+
+type keePassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+}
+
+func (e keePassEntry) get(key string) string {
+	for _, s := range e.Strings {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+// parseKeePassXML parses a KeePass 2.x XML export.
+// Groups become vaults named after their path below the root group, with
+// entries of the root group itself going to a vault named after it.
+// The recycle bin is skipped.
+func parseKeePassXML(data []byte) (*Result, error) {
+	var file keePassFile
+
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid keepass export: %w", err)
+	}
+
+	result := &Result{}
+	row := 0
+
+	var walk func(group keePassGroup, path []string)
+	walk = func(group keePassGroup, path []string) {
+		if group.UUID != "" && group.UUID == file.Meta.RecycleBinUUID {
+			return
+		}
+
+		vault := group.Name
+		if len(path) > 0 {
+			vault = strings.Join(path, "/")
+		}
+
+		for _, entry := range group.Entries {
+			row++
+
+			result.add(row, vault, models.Item{
+				Name:     entry.get("Title"),
+				Url:      entry.get("URL"),
+				Username: entry.get("UserName"),
+				Password: entry.get("Password"),
+				Notes:    entry.get("Notes"),
+			})
+		}
+
+		for _, child := range group.Groups {
+			walk(child, append(append([]string{}, path...), child.Name))
+		}
+	}
+
+	for _, root := range file.Root.Groups {
+		walk(root, nil)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+const (
+	onePasswordLogin      = "001"
+	onePasswordSecureNote = "003"
+	onePasswordPassword   = "005"
+)
+
+type onePasswordExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []onePasswordItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type onePasswordItem struct {
+	CategoryUuid string `json:"categoryUuid"`
+	Overview     struct {
+		Title string `json:"title"`
+		Url   string `json:"url"`
+	} `json:"overview"`
+	Details struct {
+		LoginFields []struct {
+			Value       string `json:"value"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Password   string `json:"password"`
+	} `json:"details"`
+}
+
+// parseOnePassword parses a 1Password 1PUX export.
+// A 1PUX file is a zip archive whose export.data entry holds the accounts,
+// vaults and items as JSON. 1Password vaults keep their name in gopass.
+func parseOnePassword(data []byte) (*Result, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid 1pux export: %w", err)
+	}
+
+	var exportData []byte
+
+	for _, f := range archive.File {
+		if f.Name != "export.data" {
+			continue
+		}
+
+		rc, err := f.Open()
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid 1pux export: %w", err)
+		}
+
+		exportData, err = io.ReadAll(rc)
+		rc.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid 1pux export: %w", err)
+		}
+	}
+
+	if exportData == nil {
+		return nil, errors.New("invalid 1pux export: export.data not found")
+	}
+
+	var export onePasswordExport
+
+	if err := json.Unmarshal(exportData, &export); err != nil {
+		return nil, fmt.Errorf("invalid 1pux export: %w", err)
+	}
+
+	result := &Result{}
+	row := 0
+
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for _, entry := range vault.Items {
+				row++
+
+				item := models.Item{
+					Name:  entry.Overview.Title,
+					Url:   entry.Overview.Url,
+					Notes: entry.Details.NotesPlain,
+				}
+
+				switch entry.CategoryUuid {
+				case onePasswordLogin:
+					for _, field := range entry.Details.LoginFields {
+						switch field.Designation {
+						case "username":
+							item.Username = field.Value
+						case "password":
+							item.Password = field.Value
+						}
+					}
+				case onePasswordPassword:
+					item.Password = entry.Details.Password
+				case onePasswordSecureNote:
+				default:
+					result.fail(row, fmt.Errorf("unsupported item category %q", entry.CategoryUuid))
+					continue
+				}
+
+				result.add(row, vault.Attrs.Name, item)
+			}
+		}
+	}
+
+	return result, nil
+}
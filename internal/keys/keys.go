@@ -1,5 +1,12 @@
 package keys
 
-type userIDKeyType string
+type contextKey string
 
-const UserIDKey userIDKeyType = "user_id"
+const UserIDKey contextKey = "user_id"
+
+// ClientIPKey and UserAgentKey hold the IP address and the User-Agent header
+// of the client of a request, for the audit log.
+const (
+	ClientIPKey  contextKey = "client_ip"
+	UserAgentKey contextKey = "user_agent"
+)
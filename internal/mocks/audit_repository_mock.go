@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *AuditRepositoryMock) Save(ctx context.Context, event *models.AuditEvent) error {
+	args := m.Called(ctx, event)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *AuditRepositoryMock) FindLast(ctx context.Context) (*models.AuditEvent, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*models.AuditEvent), args.Error(1)
+}
+
+func (m *AuditRepositoryMock) FindAfter(ctx context.Context, seq uint64, limit int) ([]models.AuditEvent, error) {
+	args := m.Called(ctx, seq, limit)
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
+
+func (m *AuditRepositoryMock) Find(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
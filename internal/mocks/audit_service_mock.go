@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditServiceMock struct {
+	mock.Mock
+}
+
+func (m *AuditServiceMock) Record(ctx context.Context, event models.AuditEvent) error {
+	args := m.Called(ctx, event)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *AuditServiceMock) Events(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
+
+func (m *AuditServiceMock) Verify(ctx context.Context) (*models.AuditVerification, error) {
+	args := m.Called(ctx)
+	verification, _ := args.Get(0).(*models.AuditVerification)
+	return verification, args.Error(1)
+}
@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AuthServiceMock struct {
+	mock.Mock
+}
+
+func (m *AuthServiceMock) Login(ctx context.Context, email, authKey string) (string, error) {
+	args := m.Called(ctx, email, authKey)
+	return args.String(0), args.Error(1)
+}
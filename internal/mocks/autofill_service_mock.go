@@ -0,0 +1,17 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type AutofillServiceMock struct {
+	mock.Mock
+}
+
+func (m *AutofillServiceMock) Match(ctx context.Context, pageURL string) ([]models.AutofillMatch, error) {
+	args := m.Called(ctx, pageURL)
+	return args.Get(0).([]models.AutofillMatch), args.Error(1)
+}
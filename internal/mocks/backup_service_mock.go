@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type BackupServiceMock struct {
+	mock.Mock
+}
+
+func (m *BackupServiceMock) Export(ctx context.Context, passphrase string) ([]byte, error) {
+	args := m.Called(ctx, passphrase)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+func (m *BackupServiceMock) Restore(ctx context.Context, data []byte, passphrase string, onConflict models.ConflictStrategy) (*models.RestoreReport, error) {
+	args := m.Called(ctx, data, passphrase, onConflict)
+	report, _ := args.Get(0).(*models.RestoreReport)
+	return report, args.Error(1)
+}
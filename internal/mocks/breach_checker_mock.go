@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type BreachCheckerMock struct {
+	mock.Mock
+}
+
+func (m *BreachCheckerMock) Check(ctx context.Context, password string) (uint32, error) {
+	args := m.Called(ctx, password)
+	return args.Get(0).(uint32), args.Error(1)
+}
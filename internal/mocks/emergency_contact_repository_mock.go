@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type EmergencyContactRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *EmergencyContactRepositoryMock) Save(ctx context.Context, contact *models.EmergencyContact) error {
+	args := m.Called(ctx, contact)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *EmergencyContactRepositoryMock) FindByID(ctx context.Context, id uint) (*models.EmergencyContact, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.EmergencyContact), args.Error(1)
+}
+
+func (m *EmergencyContactRepositoryMock) FindByGrantorID(ctx context.Context, grantorID uint) ([]models.EmergencyContact, error) {
+	args := m.Called(ctx, grantorID)
+	return args.Get(0).([]models.EmergencyContact), args.Error(1)
+}
+
+func (m *EmergencyContactRepositoryMock) FindByGranteeID(ctx context.Context, granteeID uint) ([]models.EmergencyContact, error) {
+	args := m.Called(ctx, granteeID)
+	return args.Get(0).([]models.EmergencyContact), args.Error(1)
+}
+
+func (m *EmergencyContactRepositoryMock) FindByStatus(ctx context.Context, status string) ([]models.EmergencyContact, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]models.EmergencyContact), args.Error(1)
+}
+
+func (m *EmergencyContactRepositoryMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
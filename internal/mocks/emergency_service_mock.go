@@ -0,0 +1,97 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type EmergencyServiceMock struct {
+	mock.Mock
+}
+
+func (m *EmergencyServiceMock) Invite(ctx context.Context, input models.EmergencyContactInput) (*models.EmergencyContactDetail, error) {
+	args := m.Called(ctx, input)
+	contact, _ := args.Get(0).(*models.EmergencyContactDetail)
+	return contact, args.Error(1)
+}
+
+func (m *EmergencyServiceMock) Contacts(ctx context.Context) ([]models.EmergencyContactDetail, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.EmergencyContactDetail), args.Error(1)
+}
+
+func (m *EmergencyServiceMock) Grantors(ctx context.Context) ([]models.EmergencyContactDetail, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.EmergencyContactDetail), args.Error(1)
+}
+
+func (m *EmergencyServiceMock) Accept(ctx context.Context, id uint, publicKey []byte) error {
+	args := m.Called(ctx, id, publicKey)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *EmergencyServiceMock) Confirm(ctx context.Context, id uint, wrappedKey []byte) error {
+	args := m.Called(ctx, id, wrappedKey)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *EmergencyServiceMock) Request(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *EmergencyServiceMock) Approve(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *EmergencyServiceMock) Reject(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *EmergencyServiceMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *EmergencyServiceMock) Access(ctx context.Context, id uint) (*models.EmergencyAccess, error) {
+	args := m.Called(ctx, id)
+	access, _ := args.Get(0).(*models.EmergencyAccess)
+	return access, args.Error(1)
+}
+
+func (m *EmergencyServiceMock) Takeover(ctx context.Context, id uint, input models.EmergencyTakeoverInput) error {
+	args := m.Called(ctx, id, input)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ExportServiceMock struct {
+	mock.Mock
+}
+
+func (m *ExportServiceMock) ExportKDBX(ctx context.Context, vaultID uint, password string) (string, []byte, error) {
+	args := m.Called(ctx, vaultID, password)
+	data, _ := args.Get(1).([]byte)
+	return args.String(0), data, args.Error(2)
+}
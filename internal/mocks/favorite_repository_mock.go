@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type FavoriteRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FavoriteRepositoryMock) Save(ctx context.Context, favorite *models.Favorite) error {
+	args := m.Called(ctx, favorite)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *FavoriteRepositoryMock) FindItemIDsByUserID(ctx context.Context, userID uint) ([]uint, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *FavoriteRepositoryMock) Delete(ctx context.Context, userID, itemID uint) error {
+	args := m.Called(ctx, userID, itemID)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
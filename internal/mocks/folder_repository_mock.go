@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type FolderRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FolderRepositoryMock) Save(ctx context.Context, folder *models.Folder) error {
+	args := m.Called(ctx, folder)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *FolderRepositoryMock) FindByVaultID(ctx context.Context, vaultID uint) ([]models.Folder, error) {
+	args := m.Called(ctx, vaultID)
+	return args.Get(0).([]models.Folder), args.Error(1)
+}
+
+func (m *FolderRepositoryMock) FindByID(ctx context.Context, id uint) (*models.Folder, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Folder), args.Error(1)
+}
+
+func (m *FolderRepositoryMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *FolderRepositoryMock) DeleteByVaultID(ctx context.Context, vaultID uint) error {
+	args := m.Called(ctx, vaultID)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type FolderServiceMock struct {
+	mock.Mock
+}
+
+func (m *FolderServiceMock) Create(ctx context.Context, vaultID uint, input models.FolderInput) (uint, error) {
+	args := m.Called(ctx, vaultID, input)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *FolderServiceMock) GetAll(ctx context.Context, vaultID uint) ([]models.FolderDetail, error) {
+	args := m.Called(ctx, vaultID)
+	return args.Get(0).([]models.FolderDetail), args.Error(1)
+}
+
+func (m *FolderServiceMock) Update(ctx context.Context, id uint, input models.FolderInput) error {
+	args := m.Called(ctx, id, input)
+	return args.Error(0)
+}
+
+func (m *FolderServiceMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/importer"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type ImportServiceMock struct {
+	mock.Mock
+}
+
+func (m *ImportServiceMock) Import(ctx context.Context, format importer.Format, data []byte, dryRun bool) (*models.ImportReport, error) {
+	args := m.Called(ctx, format, data, dryRun)
+	report, _ := args.Get(0).(*models.ImportReport)
+	return report, args.Error(1)
+}
+
+func (m *ImportServiceMock) ImportKDBX(ctx context.Context, data []byte, password string, dryRun bool) (*models.ImportReport, error) {
+	args := m.Called(ctx, data, password, dryRun)
+	report, _ := args.Get(0).(*models.ImportReport)
+	return report, args.Error(1)
+}
@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// Define mock repository
+type ItemRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *ItemRepositoryMock) FindByVaultIDs(ctx context.Context, vaultIDs []uint) ([]models.Item, error) {
+	args := m.Called(ctx, vaultIDs)
+	return args.Get(0).([]models.Item), args.Error(1)
+}
+
+func (m *ItemRepositoryMock) Save(ctx context.Context, item *models.Item) error {
+	args := m.Called(ctx, item)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *ItemRepositoryMock) FindByID(ctx context.Context, id uint) (*models.Item, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Item), args.Error(1)
+}
+
+func (m *ItemRepositoryMock) FindChangedByUserID(ctx context.Context, userID uint, since uint64) ([]models.Item, error) {
+	args := m.Called(ctx, userID, since)
+	return args.Get(0).([]models.Item), args.Error(1)
+}
+
+func (m *ItemRepositoryMock) Delete(ctx context.Context, id uint, revision uint64) error {
+	args := m.Called(ctx, id, revision)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *ItemRepositoryMock) DeleteByVaultID(ctx context.Context, vaultID uint, revision uint64) error {
+	args := m.Called(ctx, vaultID, revision)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *ItemRepositoryMock) SetRevisionByTagID(ctx context.Context, tagID uint, revision uint64) error {
+	args := m.Called(ctx, tagID, revision)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type ItemServiceMock struct {
+	mock.Mock
+}
+
+func (m *ItemServiceMock) Create(ctx context.Context, vaultID uint, input models.ItemInput) (uint, error) {
+	args := m.Called(ctx, vaultID, input)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *ItemServiceMock) Get(ctx context.Context, id uint) (*models.ItemDetail, error) {
+	args := m.Called(ctx, id)
+	item, _ := args.Get(0).(*models.ItemDetail)
+	return item, args.Error(1)
+}
+
+func (m *ItemServiceMock) GetAll(ctx context.Context, vaultID uint, filter models.ItemFilter) ([]models.ItemDetail, error) {
+	args := m.Called(ctx, vaultID, filter)
+	return args.Get(0).([]models.ItemDetail), args.Error(1)
+}
+
+func (m *ItemServiceMock) Update(ctx context.Context, id uint, input models.ItemInput) error {
+	args := m.Called(ctx, id, input)
+	return args.Error(0)
+}
+
+func (m *ItemServiceMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *ItemServiceMock) SetFavorite(ctx context.Context, id uint, favorite bool) error {
+	args := m.Called(ctx, id, favorite)
+	return args.Error(0)
+}
@@ -10,7 +10,7 @@ type JWTGeneratorMock struct {
 	mock.Mock
 }
 
-func (m *JWTGeneratorMock) Generate(userID uint, exp time.Time) (string, error) {
-	args := m.Called(userID, exp)
+func (m *JWTGeneratorMock) Generate(userID, sessionVersion uint, exp time.Time) (string, error) {
+	args := m.Called(userID, sessionVersion, exp)
 	return args.String(0), args.Error(1)
 }
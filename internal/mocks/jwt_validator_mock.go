@@ -0,0 +1,12 @@
+package mocks
+
+import "github.com/stretchr/testify/mock"
+
+type JWTValidatorMock struct {
+	mock.Mock
+}
+
+func (m *JWTValidatorMock) Validate(token string) (uint, error) {
+	args := m.Called(token)
+	return args.Get(0).(uint), args.Error(1)
+}
@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type KeyServiceMock struct {
+	mock.Mock
+}
+
+func (m *KeyServiceMock) SetKeys(ctx context.Context, keys models.UserKeys) error {
+	args := m.Called(ctx, keys)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *KeyServiceMock) Keys(ctx context.Context) (*models.UserKeys, error) {
+	args := m.Called(ctx)
+	keys, _ := args.Get(0).(*models.UserKeys)
+	return keys, args.Error(1)
+}
+
+func (m *KeyServiceMock) Lookup(ctx context.Context, email string) (*models.PublicKeyDetail, error) {
+	args := m.Called(ctx, email)
+	key, _ := args.Get(0).(*models.PublicKeyDetail)
+	return key, args.Error(1)
+}
+
+func (m *KeyServiceMock) Verify(ctx context.Context, input models.KeyVerificationInput) (*models.PublicKeyDetail, error) {
+	args := m.Called(ctx, input)
+	key, _ := args.Get(0).(*models.PublicKeyDetail)
+	return key, args.Error(1)
+}
+
+func (m *KeyServiceMock) WrappedKeys(ctx context.Context) ([]models.WrappedKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.WrappedKey), args.Error(1)
+}
+
+func (m *KeyServiceMock) Rotate(ctx context.Context, rotation models.KeyRotation) error {
+	args := m.Called(ctx, rotation)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type KeyVerificationRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *KeyVerificationRepositoryMock) Save(ctx context.Context, verification *models.KeyVerification) error {
+	args := m.Called(ctx, verification)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *KeyVerificationRepositoryMock) FindByUserIDAndContactID(ctx context.Context, userID, contactID uint) (*models.KeyVerification, error) {
+	args := m.Called(ctx, userID, contactID)
+	return args.Get(0).(*models.KeyVerification), args.Error(1)
+}
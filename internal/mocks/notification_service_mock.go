@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type NotificationServiceMock struct {
+	mock.Mock
+}
+
+func (m *NotificationServiceMock) Subscribe(ctx context.Context, since *uint64) (<-chan models.Notification, error) {
+	args := m.Called(ctx, since)
+	notifications, _ := args.Get(0).(<-chan models.Notification)
+	return notifications, args.Error(1)
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/pkg/notify"
+	"github.com/stretchr/testify/mock"
+)
+
+type NotifierMock struct {
+	mock.Mock
+}
+
+func (m *NotifierMock) Notify(ctx context.Context, msg notify.Message) error {
+	args := m.Called(ctx, msg)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type RecoveryRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *RecoveryRepositoryMock) SaveKey(ctx context.Context, key *models.RecoveryKey) error {
+	args := m.Called(ctx, key)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *RecoveryRepositoryMock) FindKeyByVaultID(ctx context.Context, vaultID uint) (*models.RecoveryKey, error) {
+	args := m.Called(ctx, vaultID)
+	return args.Get(0).(*models.RecoveryKey), args.Error(1)
+}
+
+func (m *RecoveryRepositoryMock) DeleteKey(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *RecoveryRepositoryMock) SaveCeremony(ctx context.Context, ceremony *models.RecoveryCeremony) error {
+	args := m.Called(ctx, ceremony)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *RecoveryRepositoryMock) FindCeremonyByID(ctx context.Context, id uint) (*models.RecoveryCeremony, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.RecoveryCeremony), args.Error(1)
+}
+
+func (m *RecoveryRepositoryMock) FindCeremoniesByKeyID(ctx context.Context, keyID uint) ([]models.RecoveryCeremony, error) {
+	args := m.Called(ctx, keyID)
+	return args.Get(0).([]models.RecoveryCeremony), args.Error(1)
+}
+
+func (m *RecoveryRepositoryMock) FindSharesByAdminID(ctx context.Context, adminID uint) ([]models.RecoveryShare, error) {
+	args := m.Called(ctx, adminID)
+	return args.Get(0).([]models.RecoveryShare), args.Error(1)
+}
+
+func (m *RecoveryRepositoryMock) SaveShare(ctx context.Context, share *models.RecoveryShare) error {
+	args := m.Called(ctx, share)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type RecoveryServiceMock struct {
+	mock.Mock
+}
+
+func (m *RecoveryServiceMock) Setup(ctx context.Context, vaultID uint, input models.RecoveryKeyInput) (*models.RecoveryKeyDetail, error) {
+	args := m.Called(ctx, vaultID, input)
+	key, _ := args.Get(0).(*models.RecoveryKeyDetail)
+	return key, args.Error(1)
+}
+
+func (m *RecoveryServiceMock) Get(ctx context.Context, vaultID uint) (*models.RecoveryKeyDetail, error) {
+	args := m.Called(ctx, vaultID)
+	key, _ := args.Get(0).(*models.RecoveryKeyDetail)
+	return key, args.Error(1)
+}
+
+func (m *RecoveryServiceMock) Share(ctx context.Context, vaultID uint) ([]byte, error) {
+	args := m.Called(ctx, vaultID)
+	share, _ := args.Get(0).([]byte)
+	return share, args.Error(1)
+}
+
+func (m *RecoveryServiceMock) Delete(ctx context.Context, vaultID uint) error {
+	args := m.Called(ctx, vaultID)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *RecoveryServiceMock) Start(ctx context.Context, vaultID uint, input models.RecoveryCeremonyInput) (*models.RecoveryCeremonyDetail, error) {
+	args := m.Called(ctx, vaultID, input)
+	ceremony, _ := args.Get(0).(*models.RecoveryCeremonyDetail)
+	return ceremony, args.Error(1)
+}
+
+func (m *RecoveryServiceMock) Ceremonies(ctx context.Context, vaultID uint) ([]models.RecoveryCeremonyDetail, error) {
+	args := m.Called(ctx, vaultID)
+	return args.Get(0).([]models.RecoveryCeremonyDetail), args.Error(1)
+}
+
+func (m *RecoveryServiceMock) Approve(ctx context.Context, id uint, share []byte) (*models.RecoveryCeremonyDetail, error) {
+	args := m.Called(ctx, id, share)
+	ceremony, _ := args.Get(0).(*models.RecoveryCeremonyDetail)
+	return ceremony, args.Error(1)
+}
+
+func (m *RecoveryServiceMock) Cancel(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *RecoveryServiceMock) Complete(ctx context.Context, id uint) (*models.RecoveryShares, error) {
+	args := m.Called(ctx, id)
+	shares, _ := args.Get(0).(*models.RecoveryShares)
+	return shares, args.Error(1)
+}
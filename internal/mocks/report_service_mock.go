@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type ReportServiceMock struct {
+	mock.Mock
+}
+
+func (m *ReportServiceMock) GetUserReport(ctx context.Context, maxAgeDays uint) (*models.HealthReport, error) {
+	args := m.Called(ctx, maxAgeDays)
+	report, _ := args.Get(0).(*models.HealthReport)
+	return report, args.Error(1)
+}
+
+func (m *ReportServiceMock) GetVaultReport(ctx context.Context, vaultID, maxAgeDays uint) (*models.HealthReport, error) {
+	args := m.Called(ctx, vaultID, maxAgeDays)
+	report, _ := args.Get(0).(*models.HealthReport)
+	return report, args.Error(1)
+}
@@ -0,0 +1,17 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type SearchServiceMock struct {
+	mock.Mock
+}
+
+func (m *SearchServiceMock) Search(ctx context.Context, query string, limit uint) ([]models.SearchResult, error) {
+	args := m.Called(ctx, query, limit)
+	return args.Get(0).([]models.SearchResult), args.Error(1)
+}
@@ -0,0 +1,51 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type SendRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *SendRepositoryMock) Save(ctx context.Context, send *models.Send) error {
+	args := m.Called(ctx, send)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *SendRepositoryMock) FindByID(ctx context.Context, id uint) (*models.Send, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Send), args.Error(1)
+}
+
+func (m *SendRepositoryMock) FindByAccessID(ctx context.Context, accessID string) (*models.Send, error) {
+	args := m.Called(ctx, accessID)
+	return args.Get(0).(*models.Send), args.Error(1)
+}
+
+func (m *SendRepositoryMock) FindByUserID(ctx context.Context, userID uint) ([]models.Send, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Send), args.Error(1)
+}
+
+func (m *SendRepositoryMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *SendRepositoryMock) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type SendServiceMock struct {
+	mock.Mock
+}
+
+func (m *SendServiceMock) Create(ctx context.Context, input models.SendInput) (*models.SendDetail, error) {
+	args := m.Called(ctx, input)
+	send, _ := args.Get(0).(*models.SendDetail)
+	return send, args.Error(1)
+}
+
+func (m *SendServiceMock) GetAll(ctx context.Context) ([]models.SendDetail, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.SendDetail), args.Error(1)
+}
+
+func (m *SendServiceMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *SendServiceMock) Access(ctx context.Context, accessID, accessKey string) (*models.SendContent, error) {
+	args := m.Called(ctx, accessID, accessKey)
+	content, _ := args.Get(0).(*models.SendContent)
+	return content, args.Error(1)
+}
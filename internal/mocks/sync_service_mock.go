@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type SyncServiceMock struct {
+	mock.Mock
+}
+
+func (m *SyncServiceMock) Changes(ctx context.Context, since uint64) (*models.SyncChanges, error) {
+	args := m.Called(ctx, since)
+	changes, _ := args.Get(0).(*models.SyncChanges)
+	return changes, args.Error(1)
+}
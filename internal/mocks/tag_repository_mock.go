@@ -0,0 +1,45 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type TagRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *TagRepositoryMock) Save(ctx context.Context, tag *models.Tag) error {
+	args := m.Called(ctx, tag)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *TagRepositoryMock) FindByUserID(ctx context.Context, userID uint) ([]models.Tag, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (m *TagRepositoryMock) FindByNameAndUserID(ctx context.Context, name string, userID uint) (*models.Tag, error) {
+	args := m.Called(ctx, name, userID)
+	return args.Get(0).(*models.Tag), args.Error(1)
+}
+
+func (m *TagRepositoryMock) FindByID(ctx context.Context, id uint) (*models.Tag, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Tag), args.Error(1)
+}
+
+func (m *TagRepositoryMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
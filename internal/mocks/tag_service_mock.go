@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type TagServiceMock struct {
+	mock.Mock
+}
+
+func (m *TagServiceMock) GetAll(ctx context.Context) ([]models.TagDetail, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.TagDetail), args.Error(1)
+}
+
+func (m *TagServiceMock) Rename(ctx context.Context, id uint, name string) error {
+	args := m.Called(ctx, id, name)
+	return args.Error(0)
+}
+
+func (m *TagServiceMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type TOTPServiceMock struct {
+	mock.Mock
+}
+
+func (m *TOTPServiceMock) GetCode(ctx context.Context, itemID uint) (*models.TOTPCode, error) {
+	args := m.Called(ctx, itemID)
+	code, _ := args.Get(0).(*models.TOTPCode)
+	return code, args.Error(1)
+}
@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// TransactorMock runs the transaction function with the given context,
+// returning its error unless another one is set with Return.
+type TransactorMock struct {
+	mock.Mock
+}
+
+func (m *TransactorMock) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := m.Called(ctx)
+	if len(args) > 0 {
+		if err, _ := args[0].(error); err != nil {
+			return err
+		}
+	}
+	return fn(ctx)
+}
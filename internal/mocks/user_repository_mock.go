@@ -17,6 +17,34 @@ func (m *UserRepositoryMock) FindByEmail(ctx context.Context, email string) (*mo
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *UserRepositoryMock) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *UserRepositoryMock) NextRevision(ctx context.Context, id uint) (uint64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *UserRepositoryMock) UpdatePassword(ctx context.Context, id uint, authKeyHash string, encryptedPrivateKey []byte) error {
+	args := m.Called(ctx, id, authKeyHash, encryptedPrivateKey)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *UserRepositoryMock) UpdateKeys(ctx context.Context, id uint, publicKey, encryptedPrivateKey []byte) error {
+	args := m.Called(ctx, id, publicKey, encryptedPrivateKey)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
 func (m *UserRepositoryMock) Save(ctx context.Context, user *models.User) error {
 	args := m.Called(ctx, user)
 	if len(args) > 0 {
@@ -0,0 +1,22 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type UserServiceMock struct {
+	mock.Mock
+}
+
+func (m *UserServiceMock) Create(ctx context.Context, name, email, authKey string) (uint, error) {
+	args := m.Called(ctx, name, email, authKey)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *UserServiceMock) ChangePassword(ctx context.Context, change models.PasswordChange) error {
+	args := m.Called(ctx, change)
+	return args.Error(0)
+}
@@ -12,12 +12,12 @@ type VaultRepositoryMock struct {
 	mock.Mock
 }
 
-func (m VaultRepositoryMock) FindByUserID(ctx context.Context, userID uint) ([]models.Vault, error) {
+func (m *VaultRepositoryMock) FindByUserID(ctx context.Context, userID uint) ([]models.Vault, error) {
 	args := m.Called(ctx, userID)
 	return args.Get(0).([]models.Vault), args.Error(1)
 }
 
-func (m VaultRepositoryMock) FindByNameAndUserID(ctx context.Context, name string, userID uint) (*models.Vault, error) {
+func (m *VaultRepositoryMock) FindByNameAndUserID(ctx context.Context, name string, userID uint) (*models.Vault, error) {
 	args := m.Called(ctx, name, userID)
 	return args.Get(0).(*models.Vault), args.Error(1)
 }
@@ -30,3 +30,22 @@ func (m *VaultRepositoryMock) Save(ctx context.Context, vault *models.Vault) err
 	}
 	return nil
 }
+
+func (m *VaultRepositoryMock) FindByID(ctx context.Context, id uint) (*models.Vault, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Vault), args.Error(1)
+}
+
+func (m *VaultRepositoryMock) FindChangedByUserID(ctx context.Context, userID uint, since uint64) ([]models.Vault, error) {
+	args := m.Called(ctx, userID, since)
+	return args.Get(0).([]models.Vault), args.Error(1)
+}
+
+func (m *VaultRepositoryMock) Delete(ctx context.Context, id uint, revision uint64) error {
+	args := m.Called(ctx, id, revision)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type VaultServiceMock struct {
+	mock.Mock
+}
+
+func (m *VaultServiceMock) Create(ctx context.Context, name string) (uint, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *VaultServiceMock) GetAll(ctx context.Context) ([]models.VaultDetail, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.VaultDetail), args.Error(1)
+}
+
+func (m *VaultServiceMock) Rename(ctx context.Context, id uint, name string, revision uint64) error {
+	args := m.Called(ctx, id, name, revision)
+	return args.Error(0)
+}
+
+func (m *VaultServiceMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
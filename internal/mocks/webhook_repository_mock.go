@@ -0,0 +1,68 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *WebhookRepositoryMock) Save(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *WebhookRepositoryMock) FindByID(ctx context.Context, id uint) (*models.Webhook, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) FindByUserID(ctx context.Context, userID uint) ([]models.Webhook, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *WebhookRepositoryMock) SaveDeadLetter(ctx context.Context, deadLetter *models.WebhookDeadLetter) error {
+	args := m.Called(ctx, deadLetter)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *WebhookRepositoryMock) FindDeadLetterByID(ctx context.Context, id uint) (*models.WebhookDeadLetter, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.WebhookDeadLetter), args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) FindDeadLettersByUserID(ctx context.Context, userID uint) ([]models.WebhookDeadLetter, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.WebhookDeadLetter), args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) DeleteDeadLetter(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
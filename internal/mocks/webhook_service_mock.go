@@ -0,0 +1,55 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookServiceMock struct {
+	mock.Mock
+}
+
+func (m *WebhookServiceMock) Create(ctx context.Context, input models.WebhookInput) (*models.WebhookDetail, error) {
+	args := m.Called(ctx, input)
+	webhook, _ := args.Get(0).(*models.WebhookDetail)
+	return webhook, args.Error(1)
+}
+
+func (m *WebhookServiceMock) GetAll(ctx context.Context) ([]models.WebhookDetail, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.WebhookDetail), args.Error(1)
+}
+
+func (m *WebhookServiceMock) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *WebhookServiceMock) DeadLetters(ctx context.Context) ([]models.WebhookDeadLetterDetail, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.WebhookDeadLetterDetail), args.Error(1)
+}
+
+func (m *WebhookServiceMock) Redeliver(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
+
+func (m *WebhookServiceMock) Discard(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	if len(args) > 0 {
+		err, _ := args[0].(error)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// Actions of audit events.
+const (
+	AuditLogin         = "login"
+	AuditLoginFailed   = "login.failed"
+	AuditTokenIssued   = "token.issued"
+	AuditVaultCreate   = "vault.create"
+	AuditVaultRead     = "vault.read"
+	AuditVaultUpdate   = "vault.update"
+	AuditVaultDelete   = "vault.delete"
+	AuditItemCreate    = "item.create"
+	AuditItemRead      = "item.read"
+	AuditItemUpdate    = "item.update"
+	AuditItemDelete    = "item.delete"
+	AuditSharing       = "sharing.change"
+	AuditExport        = "export"
+	AuditBackupExport  = "backup.export"
+	AuditBackupRestore = "backup.restore"
+	AuditImport        = "import"
+
+	AuditPasswordChange = "password.change"
+	AuditKeyRotate      = "key.rotate"
+
+	AuditRecoverySetup    = "recovery.setup"
+	AuditRecoveryDelete   = "recovery.delete"
+	AuditRecoveryStart    = "recovery.start"
+	AuditRecoveryApprove  = "recovery.approve"
+	AuditRecoveryCancel   = "recovery.cancel"
+	AuditRecoveryComplete = "recovery.complete"
+
+	AuditEmergencyAccess   = "emergency.access"
+	AuditEmergencyTakeover = "emergency.takeover"
+)
+
+// Outcomes of audit events.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditEvent is an entry of the audit log. Entries are numbered by Seq and
+// chained: Hash is the SHA-256 of the entry and of PrevHash, the Hash of the
+// entry before it, so changing or removing an entry breaks the chain.
+// UserID is the user acting or, for failed logins, the user of the email
+// tried; it is zero when there is none. VaultID and ItemID are the vault and
+// item acted on, zero when there are none.
+type AuditEvent struct {
+	Seq       uint64    `gorm:"primaryKey" json:"seq"`
+	Action    string    `json:"action"`
+	Outcome   string    `json:"outcome"`
+	UserID    uint      `gorm:"index" json:"userId,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	VaultID   uint      `gorm:"index" json:"vaultId,omitempty"`
+	ItemID    uint      `json:"itemId,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+	PrevHash  string    `json:"prevHash"`
+	Hash      string    `json:"hash"`
+}
+
+// AuditFilter selects audit events. Zero values select all events.
+type AuditFilter struct {
+	UserID  uint
+	VaultID uint
+	// From and To bound the time of the events, To excluded.
+	From time.Time
+	To   time.Time
+	// Limit is the maximum number of events, newest first.
+	Limit int
+}
+
+// AuditVerification is the result of the verification of the audit chain.
+// Head is the hash of the last entry checked; comparing it with a copy kept
+// elsewhere detects entries removed from the end of the log, which the chain
+// alone can't. When Valid is false, BrokenAt is the first entry not matching
+// the chain and Reason tells why.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  uint64 `json:"entries"`
+	Head     string `json:"head"`
+	BrokenAt uint64 `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// AutofillMatch is an item matching the URL of a page, as found by urlmatch.
+// Match is the quality of the match, such as "exact", "host" or "domain".
+type AutofillMatch struct {
+	ItemDetail
+	VaultName string `json:"vaultName"`
+	Match     string `json:"match"`
+}
@@ -0,0 +1,35 @@
+package models
+
+// ConflictStrategy decides what a restore does with vaults whose name is already taken.
+type ConflictStrategy string
+
+const (
+	// ConflictRename restores the vault under a new, unused name.
+	ConflictRename ConflictStrategy = "rename"
+	// ConflictMerge restores the items into the existing vault, but the ones
+	// already in it.
+	ConflictMerge ConflictStrategy = "merge"
+	// ConflictSkip doesn't restore the vault.
+	ConflictSkip ConflictStrategy = "skip"
+)
+
+type RestoredVault struct {
+	// OldID is the ID of the vault in the exporting account.
+	OldID uint `json:"oldId"`
+	// NewID is the ID of the vault the items were restored to, zero if it was skipped.
+	NewID        uint   `json:"newId"`
+	Name         string `json:"name"`
+	OriginalName string `json:"originalName"`
+	Items        int    `json:"items"`
+	// Skipped is the number of items of a merged vault that weren't restored
+	// because they were in it already.
+	Skipped int `json:"skipped,omitempty"`
+	// Status is one of created, renamed, merged or skipped.
+	Status string `json:"status"`
+}
+
+type RestoreReport struct {
+	Vaults []RestoredVault `json:"vaults"`
+	// ItemIDs maps the item IDs of the exporting account to the new ones.
+	ItemIDs map[uint]uint `json:"itemIds"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Access levels of emergency contacts.
+const (
+	// EmergencyView lets the contact read the vaults of the grantor.
+	EmergencyView = "view"
+	// EmergencyTakeover also lets the contact set a new master password for
+	// the grantor, taking over the account.
+	EmergencyTakeover = "takeover"
+)
+
+// Statuses of emergency contacts, in the order they go through.
+const (
+	EmergencyInvited   = "invited"
+	EmergencyAccepted  = "accepted"
+	EmergencyConfirmed = "confirmed"
+	EmergencyRequested = "requested"
+	EmergencyGranted   = "granted"
+	// EmergencyClosed is the status of the contacts through which the account
+	// of the grantor was taken over; they can't be used again.
+	EmergencyClosed = "closed"
+)
+
+// EmergencyContact is a user, the grantee, whom another user, the grantor,
+// trusts to access their account when they are unavailable. The grantee
+// accepts the invitation with their X25519 PublicKey, to which the client of
+// the grantor then wraps its key as WrappedKey; the server can't unwrap it.
+// Access is granted WaitDays after RequestedAt unless the grantor rejects
+// the request, or earlier when they approve it.
+type EmergencyContact struct {
+	gorm.Model
+	GrantorID    uint `gorm:"index"`
+	GrantorEmail string
+	GranteeID    uint `gorm:"index"`
+	GranteeEmail string
+	Access       string
+	WaitDays     int
+	Status       string `gorm:"index"`
+	PublicKey    []byte
+	WrappedKey   []byte
+	RequestedAt  *time.Time
+}
+
+// EmergencyContactInput holds the fields of an emergency contact set by the grantor.
+// WaitDays picks the default wait when it is zero.
+type EmergencyContactInput struct {
+	Email    string `json:"email"`
+	Access   string `json:"access"`
+	WaitDays int    `json:"waitDays,omitempty"`
+}
+
+// EmergencyTakeoverInput is the new master password a grantee sets for the
+// grantor, as the auth key derived from it. EncryptedPrivateKey is the private
+// key of the grantor sealed under the new master password; it is required
+// once the grantor has keys.
+type EmergencyTakeoverInput struct {
+	AuthKey             string `json:"authKey"`
+	EncryptedPrivateKey []byte `json:"encryptedPrivateKey,omitempty"`
+}
+
+// EmergencyContactDetail is an emergency contact as returned by the API, to
+// the grantor and to the grantee. GrantsAt is when access is granted, unless
+// the grantor rejects the request first; it is set while access is requested.
+type EmergencyContactDetail struct {
+	ID           uint       `json:"id"`
+	GrantorID    uint       `json:"grantorId"`
+	GrantorEmail string     `json:"grantorEmail"`
+	GranteeID    uint       `json:"granteeId"`
+	GranteeEmail string     `json:"granteeEmail"`
+	Access       string     `json:"access"`
+	WaitDays     int        `json:"waitDays"`
+	Status       string     `json:"status"`
+	PublicKey    []byte     `json:"publicKey,omitempty"`
+	RequestedAt  *time.Time `json:"requestedAt,omitempty"`
+	GrantsAt     *time.Time `json:"grantsAt,omitempty"`
+}
+
+// EmergencyAccess is what a grantee gets once access is granted: the key of
+// the grantor wrapped to their public key, and the vaults and items of the grantor.
+type EmergencyAccess struct {
+	GrantorID  uint         `json:"grantorId"`
+	Access     string       `json:"access"`
+	WrappedKey []byte       `json:"wrappedKey"`
+	Vaults     *SyncChanges `json:"vaults"`
+}
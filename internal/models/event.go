@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Types of the domain events published when vaults, items and keys change.
+const (
+	EventVaultCreated = "vault.created"
+	EventVaultRenamed = "vault.renamed"
+	EventVaultDeleted = "vault.deleted"
+	EventItemCreated  = "item.created"
+	EventItemUpdated  = "item.updated"
+	EventItemDeleted  = "item.deleted"
+	// EventVaultsChanged tells of changes to many items at once, by restores,
+	// imports and tag changes, or to folders; subscribers sync to read them.
+	EventVaultsChanged = "vaults.changed"
+	// EventKeysRotated tells that the key pair of the user was replaced.
+	EventKeysRotated = "keys.rotated"
+)
+
+// EventTypes are the types of the domain events, in the order they are documented.
+var EventTypes = []string{
+	EventVaultCreated,
+	EventVaultRenamed,
+	EventVaultDeleted,
+	EventItemCreated,
+	EventItemUpdated,
+	EventItemDeleted,
+	EventVaultsChanged,
+	EventKeysRotated,
+}
+
+// Event is a change made to the vaults of a user. It identifies what changed
+// without holding any secret, subscribers read the changes through the API.
+// Revision is the revision of the user once the change was made, at least the
+// revision of the change, zero when it couldn't be read.
+type Event struct {
+	// ID identifies the event, so subscribers can ignore repeated deliveries.
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	UserID     uint      `json:"userId"`
+	VaultID    uint      `json:"vaultId,omitempty"`
+	ItemID     uint      `json:"itemId,omitempty"`
+	Revision   uint64    `json:"revision,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// Notification tells a client that a vault or item changed, so it syncs.
+// It holds the IDs and the revision of the change only, never secrets.
+type Notification struct {
+	Type     string `json:"type"`
+	VaultID  uint   `json:"vaultId,omitempty"`
+	ItemID   uint   `json:"itemId,omitempty"`
+	Revision uint64 `json:"revision,omitempty"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// Favorite marks an item as a favorite of a user.
+type Favorite struct {
+	UserID    uint `gorm:"primaryKey"`
+	ItemID    uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,24 @@
+package models
+
+import "gorm.io/gorm"
+
+// Folder groups items of a vault. Folders nest, a nil ParentID is the root of the vault.
+type Folder struct {
+	gorm.Model
+	Name     string
+	VaultID  uint
+	ParentID *uint
+}
+
+// FolderInput holds the fields of a folder that can be set by the user.
+type FolderInput struct {
+	Name     string `json:"name"`
+	ParentID *uint  `json:"parentId,omitempty"`
+}
+
+type FolderDetail struct {
+	ID       uint   `json:"id"`
+	VaultID  uint   `json:"vaultId"`
+	ParentID *uint  `json:"parentId,omitempty"`
+	Name     string `json:"name"`
+}
@@ -0,0 +1,22 @@
+package models
+
+type ImportedItem struct {
+	Row      int    `json:"row"`
+	Vault    string `json:"vault"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Url      string `json:"url"`
+}
+
+type ImportError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type ImportReport struct {
+	DryRun     bool           `json:"dryRun"`
+	NewVaults  []string       `json:"newVaults"`
+	Imported   []ImportedItem `json:"imported"`
+	Duplicates []ImportedItem `json:"duplicates"`
+	Errors     []ImportError  `json:"errors"`
+}
@@ -1,12 +1,91 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// Item is a login stored in a vault. Items holding an SSHKey, an OpenSSH or PEM
+// private key, are SSH key items; a key with a passphrase is encrypted with Password.
+// TOTP holds the otpauth URI of the one-time passwords of the login.
+// FolderID is nil for items at the root of the vault. UrlMatch is the urlmatch
+// strategy of Url, empty for the default one. CreatedRevision and
+// UpdatedRevision are the revisions of the user when the item was created and
+// last written, deletion included.
 type Item struct {
 	gorm.Model
-	Name     string
-	Url      string
-	Username string
-	Password string
-	VaultID  uint
+	Name            string
+	Url             string
+	UrlMatch        string
+	Username        string
+	Password        string
+	Notes           string
+	SSHKey          string
+	TOTP            string
+	VaultID         uint
+	FolderID        *uint
+	Tags            []Tag `gorm:"many2many:item_tags;"`
+	CreatedRevision uint64
+	UpdatedRevision uint64
+}
+
+// ItemInput holds the fields of an item that can be set by the user.
+// Tags are given by name, and created when the user has no such tag yet.
+// Revision is the revision of the item the input is based on, required on
+// update so changes made in the meantime aren't overwritten.
+type ItemInput struct {
+	Name     string   `json:"name"`
+	Url      string   `json:"url"`
+	UrlMatch string   `json:"urlMatch,omitempty"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	Notes    string   `json:"notes"`
+	SSHKey   string   `json:"sshKey,omitempty"`
+	TOTP     string   `json:"totp,omitempty"`
+	FolderID *uint    `json:"folderId,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Revision uint64   `json:"revision,omitempty"`
+}
+
+// ItemDetail is an item as returned by the API. Item listings leave TOTP out
+// and only tell whether the item has one with HasTOTP. Revision is the
+// UpdatedRevision of the item, which updates must be based on.
+type ItemDetail struct {
+	ID        uint      `json:"id"`
+	VaultID   uint      `json:"vaultId"`
+	Name      string    `json:"name"`
+	Url       string    `json:"url"`
+	UrlMatch  string    `json:"urlMatch,omitempty"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password"`
+	Notes     string    `json:"notes"`
+	SSHKey    string    `json:"sshKey,omitempty"`
+	TOTP      string    `json:"totp,omitempty"`
+	HasTOTP   bool      `json:"hasTotp,omitempty"`
+	FolderID  *uint     `json:"folderId,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Favorite  bool      `json:"favorite,omitempty"`
+	Revision  uint64    `json:"revision"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ItemFilter selects the items of a vault listing. Zero values select all items.
+type ItemFilter struct {
+	// FolderID selects the items in a folder and its subfolders.
+	FolderID uint
+	// Tag selects the items with a tag, by name.
+	Tag string
+	// Favorite selects the favorite items of the user.
+	Favorite bool
+}
+
+// TOTPCode is the current one-time password of an item.
+type TOTPCode struct {
+	Code string `json:"code"`
+	// Period is how long each code is valid, in seconds.
+	Period uint `json:"period"`
+	// Remaining is how long the code remains valid, in seconds.
+	Remaining uint `json:"remaining"`
 }
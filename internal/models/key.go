@@ -0,0 +1,61 @@
+package models
+
+import "gorm.io/gorm"
+
+// Kinds of the keys wrapped to the public key of a user.
+const (
+	// WrappedEmergencyKey is the key of a grantor wrapped to their emergency contact.
+	WrappedEmergencyKey = "emergency"
+	// WrappedRecoveryShare is a share of a recovery key wrapped to its admin.
+	WrappedRecoveryShare = "recovery"
+)
+
+// KeyVerification records that a user compared out-of-band the fingerprint
+// of PublicKey, the public key of a contact. It no longer holds once the
+// contact has another public key.
+type KeyVerification struct {
+	gorm.Model
+	UserID    uint `gorm:"index"`
+	ContactID uint
+	PublicKey []byte
+}
+
+// UserKeys is the key pair of a user. The private key is encrypted by the
+// client, the server can't read it.
+type UserKeys struct {
+	PublicKey           []byte `json:"publicKey"`
+	EncryptedPrivateKey []byte `json:"encryptedPrivateKey"`
+}
+
+// PublicKeyDetail is the public key of a user, as returned by the API.
+// Verified tells whether the authenticated user verified its fingerprint.
+type PublicKeyDetail struct {
+	UserID      uint   `json:"userId"`
+	Email       string `json:"email"`
+	PublicKey   []byte `json:"publicKey"`
+	Fingerprint string `json:"fingerprint"`
+	Verified    bool   `json:"verified"`
+}
+
+// KeyVerificationInput is the fingerprint of the public key of the user with
+// Email, as compared out-of-band.
+type KeyVerificationInput struct {
+	Email       string `json:"email"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// WrappedKey is a key wrapped to the public key of a user, identified by its
+// Kind and the ID of the emergency contact or recovery share holding it.
+type WrappedKey struct {
+	Kind       string `json:"kind"`
+	ID         uint   `json:"id"`
+	WrappedKey []byte `json:"wrappedKey"`
+}
+
+// KeyRotation is a new key pair of a user, with every key wrapped to their
+// old public key unwrapped and wrapped again to the new one by their client.
+type KeyRotation struct {
+	PublicKey           []byte       `json:"publicKey"`
+	EncryptedPrivateKey []byte       `json:"encryptedPrivateKey"`
+	WrappedKeys         []WrappedKey `json:"wrappedKeys"`
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of recovery ceremonies.
+const (
+	RecoveryPending   = "pending"
+	RecoveryApproved  = "approved"
+	RecoveryCompleted = "completed"
+	RecoveryCancelled = "cancelled"
+)
+
+// RecoveryKey is the recovery key of a vault, split by the client of the
+// owner with Shamir's scheme into one share per admin, any Threshold of which
+// rebuild it. Each share is encrypted to its admin, the server can't read them.
+type RecoveryKey struct {
+	gorm.Model
+	VaultID   uint `gorm:"uniqueIndex"`
+	OwnerID   uint
+	Threshold int
+	Shares    []RecoveryShare
+}
+
+// RecoveryShare is the share of a recovery key held by an admin.
+type RecoveryShare struct {
+	gorm.Model
+	RecoveryKeyID  uint `gorm:"index"`
+	AdminID        uint `gorm:"index"`
+	AdminEmail     string
+	EncryptedShare []byte
+}
+
+// RecoveryCeremony is an admin asking to rebuild a recovery key. The other
+// admins approve it by handing their share re-encrypted to PublicKey, the
+// X25519 public key of the requester. Once Threshold admins approved, the
+// requester completes the ceremony, getting the shares, which are then wiped.
+type RecoveryCeremony struct {
+	gorm.Model
+	RecoveryKeyID  uint `gorm:"index"`
+	VaultID        uint
+	RequesterID    uint
+	RequesterEmail string
+	Reason         string
+	PublicKey      []byte
+	Status         string
+	Approvals      []RecoveryApproval `gorm:"foreignKey:CeremonyID"`
+}
+
+// RecoveryApproval is the approval of a recovery ceremony by an admin, with
+// their share encrypted to the requester.
+type RecoveryApproval struct {
+	gorm.Model
+	CeremonyID uint `gorm:"index"`
+	AdminID    uint
+	AdminEmail string
+	Share      []byte
+}
+
+// RecoveryShareInput is the share of an admin, encrypted to them by the client.
+type RecoveryShareInput struct {
+	Email          string `json:"email"`
+	EncryptedShare []byte `json:"encryptedShare"`
+}
+
+// RecoveryKeyInput holds the fields of a recovery key set by the owner of the vault.
+type RecoveryKeyInput struct {
+	Threshold int                  `json:"threshold"`
+	Shares    []RecoveryShareInput `json:"shares"`
+}
+
+// RecoveryAdmin is an admin holding a share of a recovery key.
+type RecoveryAdmin struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+}
+
+// RecoveryKeyDetail is a recovery key as returned by the API, without its shares.
+type RecoveryKeyDetail struct {
+	ID        uint            `json:"id"`
+	VaultID   uint            `json:"vaultId"`
+	OwnerID   uint            `json:"ownerId"`
+	Threshold int             `json:"threshold"`
+	Admins    []RecoveryAdmin `json:"admins"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// RecoveryCeremonyInput holds the fields of a recovery ceremony set by the requester.
+type RecoveryCeremonyInput struct {
+	Reason    string `json:"reason"`
+	PublicKey []byte `json:"publicKey"`
+}
+
+// RecoveryApprovalDetail is an approval of a recovery ceremony as returned by the API.
+type RecoveryApprovalDetail struct {
+	AdminID    uint      `json:"adminId"`
+	AdminEmail string    `json:"adminEmail"`
+	ApprovedAt time.Time `json:"approvedAt"`
+}
+
+// RecoveryCeremonyDetail is a recovery ceremony as returned by the API, without the shares.
+type RecoveryCeremonyDetail struct {
+	ID             uint                     `json:"id"`
+	VaultID        uint                     `json:"vaultId"`
+	RequesterID    uint                     `json:"requesterId"`
+	RequesterEmail string                   `json:"requesterEmail"`
+	Reason         string                   `json:"reason"`
+	PublicKey      []byte                   `json:"publicKey"`
+	Status         string                   `json:"status"`
+	Threshold      int                      `json:"threshold"`
+	Approvals      []RecoveryApprovalDetail `json:"approvals"`
+	CreatedAt      time.Time                `json:"createdAt"`
+}
+
+// RecoveryShares are the shares of a completed recovery ceremony, encrypted
+// to its requester, who rebuilds the recovery key with them.
+type RecoveryShares struct {
+	CeremonyID uint     `json:"ceremonyId"`
+	VaultID    uint     `json:"vaultId"`
+	Shares     [][]byte `json:"shares"`
+}
@@ -0,0 +1,32 @@
+package models
+
+type ItemReference struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	VaultID uint   `json:"vaultId"`
+}
+
+type ReusedPassword struct {
+	// Fingerprint identifies the reused password within a single report.
+	// It is keyed per report, so it can't be compared across reports or reversed.
+	Fingerprint string          `json:"fingerprint"`
+	Items       []ItemReference `json:"items"`
+}
+
+type BreachedItem struct {
+	ItemReference
+	Occurrences uint32 `json:"occurrences"`
+}
+
+type HealthReport struct {
+	UserID  uint `json:"userId"`
+	VaultID uint `json:"vaultId,omitempty"`
+	// Score goes from 0 to 100, where 100 means no issues were found.
+	Score        int              `json:"score"`
+	TotalItems   int              `json:"totalItems"`
+	Reused       []ReusedPassword `json:"reused"`
+	Weak         []ItemReference  `json:"weak"`
+	Old          []ItemReference  `json:"old"`
+	Breached     []BreachedItem   `json:"breached"`
+	InsecureURLs []ItemReference  `json:"insecureUrls"`
+}
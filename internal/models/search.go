@@ -0,0 +1,22 @@
+package models
+
+// Kinds of search results.
+const (
+	SearchKindVault = "vault"
+	SearchKindItem  = "item"
+)
+
+// SearchResult is a vault or an item matching a search query.
+// It never holds secrets such as passwords or notes.
+type SearchResult struct {
+	Kind      string  `json:"kind"`
+	ID        uint    `json:"id"`
+	VaultID   uint    `json:"vaultId"`
+	VaultName string  `json:"vaultName"`
+	Name      string  `json:"name"`
+	Username  string  `json:"username,omitempty"`
+	Url       string  `json:"url,omitempty"`
+	Score     float64 `json:"score"`
+	// Matches are the names of the fields matching the query.
+	Matches []string `json:"matches"`
+}
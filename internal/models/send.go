@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Send is a secret, a text or an item snapshot, shared by a user through a
+// link with people who may have no account. Ciphertext is encrypted by the
+// client with a key the server never sees. AccessID identifies the send in
+// its link. AccessHash is the SHA-256 of the key derived from the access
+// password, empty when the send has none. The send is deleted after MaxViews
+// views, FailedAttempts wrong access keys, or at ExpiresAt.
+type Send struct {
+	gorm.Model
+	AccessID       string `gorm:"uniqueIndex"`
+	UserID         uint
+	Ciphertext     []byte
+	AccessHash     string
+	MaxViews       int
+	Views          int
+	FailedAttempts int
+	ExpiresAt      time.Time `gorm:"index"`
+}
+
+// SendInput holds the fields of a send set by the user. AccessKey is derived
+// from the access password by the client, empty for sends without one.
+// ExpiresIn is the lifetime of the send in seconds. Zero values pick the defaults.
+type SendInput struct {
+	Ciphertext []byte `json:"ciphertext"`
+	AccessKey  string `json:"accessKey,omitempty"`
+	MaxViews   int    `json:"maxViews,omitempty"`
+	ExpiresIn  int64  `json:"expiresIn,omitempty"`
+}
+
+// SendDetail is a send as returned by the API to its owner, without its ciphertext.
+type SendDetail struct {
+	ID          uint      `json:"id"`
+	AccessID    string    `json:"accessId"`
+	MaxViews    int       `json:"maxViews"`
+	Views       int       `json:"views"`
+	HasPassword bool      `json:"hasPassword,omitempty"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// SendContent is a send as returned to the holders of its link. ViewsLeft is
+// zero on the last view, after which the send is gone.
+type SendContent struct {
+	Ciphertext []byte    `json:"ciphertext"`
+	ViewsLeft  int       `json:"viewsLeft"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// SyncChanges holds the vaults and items of a user created, updated or deleted
+// since a revision. Revision is the revision the changes bring the client to,
+// to sync from on the next call.
+type SyncChanges struct {
+	Revision uint64                 `json:"revision"`
+	Vaults   ChangeSet[VaultDetail] `json:"vaults"`
+	Items    ChangeSet[ItemDetail]  `json:"items"`
+}
+
+// ChangeSet lists created and updated records, and the IDs of deleted ones.
+type ChangeSet[T any] struct {
+	Created []T    `json:"created"`
+	Updated []T    `json:"updated"`
+	Deleted []uint `json:"deleted"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// Tag labels items of a user across vaults.
+type Tag struct {
+	gorm.Model
+	Name   string
+	UserID uint
+}
+
+type TagDetail struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
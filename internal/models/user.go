@@ -2,9 +2,29 @@ package models
 
 import "gorm.io/gorm"
 
+// User is an account. Revision is bumped on every write to the vaults and
+// items of the user, numbering the changes clients sync. PublicKey is the
+// X25519 public key of the user, and EncryptedPrivateKey its private key,
+// sealed by the client under a key derived from the master password. AuthKey
+// is the hex SHA-256 of the auth key the client derives from the master
+// password, so the stored value can't be used to log in.
+// SessionVersion is in the tokens issued to the user; bumping it revokes them.
 type User struct {
 	gorm.Model
-	Name    string
-	Email   string
-	AuthKey string
+	Name                string
+	Email               string
+	AuthKey             string
+	Revision            uint64
+	PublicKey           []byte
+	EncryptedPrivateKey []byte
+	SessionVersion      uint
+}
+
+// PasswordChange is a new master password of a user, as the auth key derived
+// from it. EncryptedPrivateKey is the private key of the user sealed again
+// under the new master password; it is required once the user has keys.
+type PasswordChange struct {
+	CurrentAuthKey      string `json:"currentAuthKey"`
+	NewAuthKey          string `json:"newAuthKey"`
+	EncryptedPrivateKey []byte `json:"encryptedPrivateKey,omitempty"`
 }
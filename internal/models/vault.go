@@ -2,14 +2,21 @@ package models
 
 import "gorm.io/gorm"
 
+// Vault holds items. CreatedRevision and UpdatedRevision are the revisions of
+// the user when it was created and last written, deletion included.
 type Vault struct {
 	gorm.Model
-	Name   string
-	UserID uint
+	Name            string
+	UserID          uint
+	CreatedRevision uint64
+	UpdatedRevision uint64
 }
 
+// VaultDetail is a vault as returned by the API. Revision is the
+// UpdatedRevision of the vault, which renames must be based on.
 type VaultDetail struct {
-	ID     uint
-	Name   string
-	UserID uint
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	UserID   uint   `json:"userId"`
+	Revision uint64 `json:"revision"`
 }
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook is a subscription of a user to the events of their vaults, posted
+// to URL and signed with Secret. Events selects the event types delivered,
+// all of them when it is empty, and VaultID the vault, all of them when it is nil.
+type Webhook struct {
+	gorm.Model
+	UserID  uint
+	URL     string
+	Secret  string
+	Events  []string `gorm:"serializer:json"`
+	VaultID *uint
+}
+
+// WebhookInput holds the fields of a webhook that can be set by the user.
+type WebhookInput struct {
+	URL     string   `json:"url"`
+	Events  []string `json:"events,omitempty"`
+	VaultID *uint    `json:"vaultId,omitempty"`
+}
+
+// WebhookDetail is a webhook as returned by the API. Secret is only returned
+// when the webhook is created.
+type WebhookDetail struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events,omitempty"`
+	VaultID   *uint     `json:"vaultId,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookDeadLetter is an event that couldn't be delivered to a webhook.
+// Payload is the body of the deliveries, and LastError why the last one failed.
+type WebhookDeadLetter struct {
+	gorm.Model
+	WebhookID uint
+	UserID    uint
+	EventID   string
+	EventType string
+	Payload   []byte
+	Attempts  int
+	LastError string
+}
+
+// WebhookDeadLetterDetail is a dead letter as returned by the API.
+type WebhookDeadLetterDetail struct {
+	ID        uint      `json:"id"`
+	WebhookID uint      `json:"webhookId"`
+	EventID   string    `json:"eventId"`
+	EventType string    `json:"eventType"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError"`
+	FailedAt  time.Time `json:"failedAt"`
+}
@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+type IAuditRepository interface {
+	// Save saves an audit event in the database.
+	Save(ctx context.Context, event *models.AuditEvent) error
+	// FindLast finds the audit event with the highest Seq, an empty event when
+	// there is none. It is called in the transaction of the next append,
+	// whose lock on the entry serializes appends until the transaction ends.
+	FindLast(ctx context.Context) (*models.AuditEvent, error)
+	// FindAfter finds at most limit audit events with a Seq greater than seq, by Seq.
+	FindAfter(ctx context.Context, seq uint64, limit int) ([]models.AuditEvent, error)
+	// Find finds the audit events selected by the filter, newest first.
+	Find(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+type IEmergencyContactRepository interface {
+	// Save saves an emergency contact in the database.
+	Save(ctx context.Context, contact *models.EmergencyContact) error
+	// FindByID finds an emergency contact by ID.
+	// Called in a transaction, it locks the contact until the transaction ends,
+	// so concurrent changes to its status are made one at a time.
+	FindByID(ctx context.Context, id uint) (*models.EmergencyContact, error)
+	// FindByGrantorID returns the emergency contacts of a user.
+	FindByGrantorID(ctx context.Context, grantorID uint) ([]models.EmergencyContact, error)
+	// FindByGranteeID returns the emergency contacts a user is.
+	FindByGranteeID(ctx context.Context, granteeID uint) ([]models.EmergencyContact, error)
+	// FindByStatus returns the emergency contacts of all users with a status.
+	FindByStatus(ctx context.Context, status string) ([]models.EmergencyContact, error)
+	// Delete deletes an emergency contact.
+	Delete(ctx context.Context, id uint) error
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+type IFavoriteRepository interface {
+	// Save marks an item as a favorite of a user. Saving an existing favorite does nothing.
+	Save(ctx context.Context, favorite *models.Favorite) error
+	// FindItemIDsByUserID returns the IDs of the favorite items of a user.
+	FindItemIDsByUserID(ctx context.Context, userID uint) ([]uint, error)
+	// Delete removes an item from the favorites of a user.
+	Delete(ctx context.Context, userID, itemID uint) error
+}
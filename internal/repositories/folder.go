@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+type IFolderRepository interface {
+	// Save saves a folder in the database.
+	Save(ctx context.Context, folder *models.Folder) error
+	// FindByVaultID returns all folders from a vault.
+	FindByVaultID(ctx context.Context, vaultID uint) ([]models.Folder, error)
+	// FindByID finds a folder by ID.
+	FindByID(ctx context.Context, id uint) (*models.Folder, error)
+	// Delete deletes a folder.
+	Delete(ctx context.Context, id uint) error
+	// DeleteByVaultID deletes all folders from a vault.
+	DeleteByVaultID(ctx context.Context, vaultID uint) error
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+type IItemRepository interface {
+	// Save saves an item in the database, replacing its tags with item.Tags.
+	Save(ctx context.Context, item *models.Item) error
+	// FindByVaultIDs returns all items stored in the given vaults, with their tags.
+	FindByVaultIDs(ctx context.Context, vaultIDs []uint) ([]models.Item, error)
+	// FindByID finds an item by ID, with its tags.
+	FindByID(ctx context.Context, id uint) (*models.Item, error)
+	// FindChangedByUserID returns the items stored in the vaults from a user,
+	// deleted ones included, written after the given revision, with their tags.
+	FindChangedByUserID(ctx context.Context, userID uint, since uint64) ([]models.Item, error)
+	// Delete soft deletes an item, setting its UpdatedRevision to revision.
+	Delete(ctx context.Context, id uint, revision uint64) error
+	// DeleteByVaultID soft deletes all items stored in a vault, setting their UpdatedRevision to revision.
+	DeleteByVaultID(ctx context.Context, vaultID uint, revision uint64) error
+	// SetRevisionByTagID sets the UpdatedRevision of all items with a tag.
+	SetRevisionByTagID(ctx context.Context, tagID uint, revision uint64) error
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+type IKeyVerificationRepository interface {
+	// Save saves a key verification in the database.
+	Save(ctx context.Context, verification *models.KeyVerification) error
+	// FindByUserIDAndContactID finds the verification of the key of a contact by a user.
+	FindByUserIDAndContactID(ctx context.Context, userID, contactID uint) (*models.KeyVerification, error)
+}
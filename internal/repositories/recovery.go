@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+type IRecoveryRepository interface {
+	// SaveKey saves a recovery key and its shares in the database.
+	SaveKey(ctx context.Context, key *models.RecoveryKey) error
+	// FindKeyByVaultID finds the recovery key of a vault, with its shares.
+	FindKeyByVaultID(ctx context.Context, vaultID uint) (*models.RecoveryKey, error)
+	// DeleteKey deletes a recovery key, its shares and its ceremonies.
+	DeleteKey(ctx context.Context, id uint) error
+	// SaveCeremony saves a recovery ceremony and its approvals in the database.
+	SaveCeremony(ctx context.Context, ceremony *models.RecoveryCeremony) error
+	// FindCeremonyByID finds a recovery ceremony by ID, with its approvals.
+	// Within a transaction, it locks the ceremony until the transaction ends.
+	FindCeremonyByID(ctx context.Context, id uint) (*models.RecoveryCeremony, error)
+	// FindSharesByAdminID returns the shares of all recovery keys held by an admin.
+	FindSharesByAdminID(ctx context.Context, adminID uint) ([]models.RecoveryShare, error)
+	// SaveShare saves a share of a recovery key in the database.
+	SaveShare(ctx context.Context, share *models.RecoveryShare) error
+	// FindCeremoniesByKeyID returns the ceremonies of a recovery key, with their approvals, newest first.
+	FindCeremoniesByKeyID(ctx context.Context, keyID uint) ([]models.RecoveryCeremony, error)
+}
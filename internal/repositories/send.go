@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+type ISendRepository interface {
+	// Save saves a send in the database.
+	Save(ctx context.Context, send *models.Send) error
+	// FindByID finds a send by ID.
+	FindByID(ctx context.Context, id uint) (*models.Send, error)
+	// FindByAccessID finds a send by the access ID of its link.
+	// Called in a transaction, it locks the send until the transaction ends,
+	// so concurrent views are counted one at a time.
+	FindByAccessID(ctx context.Context, accessID string) (*models.Send, error)
+	// FindByUserID returns all sends from a user.
+	FindByUserID(ctx context.Context, userID uint) ([]models.Send, error)
+	// Delete deletes a send for good, ciphertext included.
+	Delete(ctx context.Context, id uint) error
+	// DeleteExpired deletes for good the sends expired at now, and returns their number.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
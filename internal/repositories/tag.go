@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+type ITagRepository interface {
+	// Save saves a tag in the database.
+	Save(ctx context.Context, tag *models.Tag) error
+	// FindByUserID returns all tags from a user.
+	FindByUserID(ctx context.Context, userID uint) ([]models.Tag, error)
+	// FindByNameAndUserID finds a tag by name and user ID.
+	FindByNameAndUserID(ctx context.Context, name string, userID uint) (*models.Tag, error)
+	// FindByID finds a tag by ID.
+	FindByID(ctx context.Context, id uint) (*models.Tag, error)
+	// Delete deletes a tag and removes it from all items.
+	Delete(ctx context.Context, id uint) error
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import "context"
+
+type ITransactor interface {
+	// WithinTransaction runs fn in a database transaction.
+	// Repositories called with the context given to fn take part in the transaction,
+	// which is rolled back if fn returns an error and committed otherwise.
+	// Called with the context of a transaction, fn takes part in that
+	// transaction, which the outermost call commits or rolls back.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
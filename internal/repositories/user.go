@@ -11,4 +11,17 @@ type IUserRepository interface {
 	Save(ctx context.Context, user *models.User) error
 	// FindByEmail finds a user by email.
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// FindByID finds a user by ID.
+	FindByID(ctx context.Context, id uint) (*models.User, error)
+	// NextRevision increments the revision of a user and returns it.
+	// It is called in the transaction of the write it numbers, whose row lock
+	// serializes the writes of the user until the transaction ends.
+	NextRevision(ctx context.Context, id uint) (uint64, error)
+	// UpdatePassword sets the hash of the auth key of a user, and its encrypted private key
+	// unless encryptedPrivateKey is nil, and increments its session version.
+	// Only these columns are written, so concurrent writes of the others are kept.
+	UpdatePassword(ctx context.Context, id uint, authKeyHash string, encryptedPrivateKey []byte) error
+	// UpdateKeys sets the public key and the encrypted private key of a user.
+	// Only these columns are written, so concurrent writes of the others are kept.
+	UpdateKeys(ctx context.Context, id uint, publicKey, encryptedPrivateKey []byte) error
 }
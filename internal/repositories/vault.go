@@ -13,4 +13,11 @@ type IVaultRepository interface {
 	FindByNameAndUserID(ctx context.Context, name string, userID uint) (*models.Vault, error)
 	// FindByUserID returns all vaults from a user.
 	FindByUserID(ctx context.Context, userID uint) ([]models.Vault, error)
+	// FindByID finds a vault by ID.
+	FindByID(ctx context.Context, id uint) (*models.Vault, error)
+	// FindChangedByUserID returns the vaults from a user, deleted ones included,
+	// written after the given revision.
+	FindChangedByUserID(ctx context.Context, userID uint, since uint64) ([]models.Vault, error)
+	// Delete soft deletes a vault, setting its UpdatedRevision to revision.
+	Delete(ctx context.Context, id uint, revision uint64) error
 }
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+type IWebhookRepository interface {
+	// Save saves a webhook in the database.
+	Save(ctx context.Context, webhook *models.Webhook) error
+	// FindByID finds a webhook by ID.
+	FindByID(ctx context.Context, id uint) (*models.Webhook, error)
+	// FindByUserID returns all webhooks from a user.
+	FindByUserID(ctx context.Context, userID uint) ([]models.Webhook, error)
+	// Delete deletes a webhook and its dead letters.
+	Delete(ctx context.Context, id uint) error
+	// SaveDeadLetter saves an undelivered event in the database.
+	SaveDeadLetter(ctx context.Context, deadLetter *models.WebhookDeadLetter) error
+	// FindDeadLetterByID finds a dead letter by ID.
+	FindDeadLetterByID(ctx context.Context, id uint) (*models.WebhookDeadLetter, error)
+	// FindDeadLettersByUserID returns all dead letters from a user, newest first.
+	FindDeadLettersByUserID(ctx context.Context, userID uint) ([]models.WebhookDeadLetter, error)
+	// DeleteDeadLetter deletes a dead letter.
+	DeleteDeadLetter(ctx context.Context, id uint) error
+}
@@ -0,0 +1,741 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/importer"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/pkg/clock"
+)
+
+const (
+	// defaultAuditLimit is the number of events returned when the filter has no limit.
+	defaultAuditLimit = 100
+	// maxAuditLimit is the maximum number of events returned at once.
+	maxAuditLimit = 1000
+	// auditVerifyBatch is the number of events read at once by Verify.
+	auditVerifyBatch = 500
+)
+
+type IAuditService interface {
+	// Record appends an event to the audit log. The time, the IP address and
+	// the user agent of the client, and the user when the event has none, are
+	// taken from the context.
+	Record(ctx context.Context, event models.AuditEvent) error
+	// Events returns the audit events of the authenticated user selected by the filter, newest first.
+	Events(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
+	// Verify checks that the audit log hasn't been tampered with.
+	Verify(ctx context.Context) (*models.AuditVerification, error)
+}
+
+type auditService struct {
+	repository repositories.IAuditRepository
+	transactor repositories.ITransactor
+	clock      clock.Clock
+}
+
+func NewAuditService(repository repositories.IAuditRepository, transactor repositories.ITransactor, clock clock.Clock) *auditService {
+	return &auditService{repository, transactor, clock}
+}
+
+func (a *auditService) Record(ctx context.Context, event models.AuditEvent) error {
+	if event.UserID == 0 {
+		event.UserID, _ = ctx.Value(keys.UserIDKey).(uint)
+	}
+
+	event.IP, _ = ctx.Value(keys.ClientIPKey).(string)
+	event.UserAgent, _ = ctx.Value(keys.UserAgentKey).(string)
+	// Databases keep microseconds at best, the hash must survive the round trip.
+	event.CreatedAt = a.clock.Now().UTC().Truncate(time.Microsecond)
+
+	return a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		last, err := a.repository.FindLast(ctx)
+
+		if err != nil {
+			log.Printf("error while trying to find the last audit event: %v", err.Error())
+			return err
+		}
+
+		event.Seq = last.Seq + 1
+		event.PrevHash = last.Hash
+		event.Hash = hashAuditEvent(event)
+
+		if err := a.repository.Save(ctx, &event); err != nil {
+			log.Printf("error while trying to save audit event: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (a *auditService) Events(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return []models.AuditEvent{}, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return []models.AuditEvent{}, cerrors.BadRequestError("from must be before to")
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+
+	filter.UserID = userID
+	events, err := a.repository.Find(ctx, filter)
+
+	if err != nil {
+		log.Printf("error while trying to find audit events: %v", err.Error())
+		return []models.AuditEvent{}, err
+	}
+
+	return events, nil
+}
+
+func (a *auditService) Verify(ctx context.Context) (*models.AuditVerification, error) {
+	if _, ok := ctx.Value(keys.UserIDKey).(uint); !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	result := &models.AuditVerification{Valid: true}
+
+	for {
+		events, err := a.repository.FindAfter(ctx, result.Entries, auditVerifyBatch)
+
+		if err != nil {
+			log.Printf("error while trying to find audit events after a seq: %v", err.Error())
+			return nil, err
+		}
+
+		for _, event := range events {
+			if reason := checkAuditEvent(event, result.Entries+1, result.Head); reason != "" {
+				result.Valid = false
+				result.BrokenAt = event.Seq
+				result.Reason = reason
+				return result, nil
+			}
+
+			result.Entries = event.Seq
+			result.Head = event.Hash
+		}
+
+		if len(events) < auditVerifyBatch {
+			return result, nil
+		}
+	}
+}
+
+// checkAuditEvent tells why an event doesn't follow the chain, empty when it does.
+func checkAuditEvent(event models.AuditEvent, seq uint64, prevHash string) string {
+	switch {
+	case event.Seq != seq:
+		return fmt.Sprintf("entry %d is missing", seq)
+	case event.PrevHash != prevHash:
+		return "previous hash doesn't match the previous entry"
+	case event.Hash != hashAuditEvent(event):
+		return "hash doesn't match the entry"
+	default:
+		return ""
+	}
+}
+
+// hashAuditEvent returns the hex SHA-256 of the fields of an event but Hash.
+func hashAuditEvent(event models.AuditEvent) string {
+	fields := []any{
+		event.Seq,
+		event.Action,
+		event.Outcome,
+		event.UserID,
+		event.Email,
+		event.VaultID,
+		event.ItemID,
+		event.IP,
+		event.UserAgent,
+		event.Detail,
+		event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		event.PrevHash,
+	}
+
+	// A JSON array keeps the fields apart whatever they hold, and encoding
+	// values of these types can't fail.
+	data, _ := json.Marshal(fields)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// recordOutcome records event with the outcome of err, the error of the
+// action audited. It returns err, or the error recording the event when the
+// action succeeded, so what the action read isn't handed out unaudited. The
+// changes of the action are made already then; recordInTransaction records
+// the actions whose changes mustn't be kept unaudited.
+func recordOutcome(ctx context.Context, audit IAuditService, event models.AuditEvent, err error) error {
+	event.Outcome = models.AuditSuccess
+
+	if err != nil {
+		event.Outcome = models.AuditFailure
+		event.Detail = auditDetail(err)
+	}
+
+	recordErr := audit.Record(ctx, event)
+
+	if err != nil {
+		if recordErr != nil {
+			log.Printf("error while trying to record audit event: %v", recordErr.Error())
+		}
+
+		return err
+	}
+
+	return recordErr
+}
+
+// recordInTransaction runs action and records the event built by event with
+// its outcome in one transaction, so the changes of a successful action are
+// rolled back unless the event is recorded. A failed action is recorded once
+// its transaction is rolled back.
+func recordInTransaction(
+	ctx context.Context,
+	transactor repositories.ITransactor,
+	audit IAuditService,
+	action func(ctx context.Context) error,
+	event func() models.AuditEvent,
+) error {
+	var actionErr error
+
+	err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if actionErr = action(ctx); actionErr != nil {
+			return actionErr
+		}
+
+		return recordOutcome(ctx, audit, event(), nil)
+	})
+
+	if actionErr != nil {
+		return recordOutcome(ctx, audit, event(), actionErr)
+	}
+
+	return err
+}
+
+// auditDetail returns the message of application errors, other errors may
+// hold details not meant for the users reading the log.
+func auditDetail(err error) string {
+	var appErr interface{ Code() int }
+
+	if errors.As(err, &appErr) {
+		return err.Error()
+	}
+
+	return "internal error"
+}
+
+// auditedVaultService is a vault service recording the vaults created,
+// renamed and deleted in the audit log.
+type auditedVaultService struct {
+	IVaultService
+	audit IAuditService
+}
+
+func NewAuditedVaultService(vaultService IVaultService, auditService IAuditService) *auditedVaultService {
+	return &auditedVaultService{vaultService, auditService}
+}
+
+func (v *auditedVaultService) Create(ctx context.Context, name string) (uint, error) {
+	id, err := v.IVaultService.Create(ctx, name)
+
+	return id, recordOutcome(ctx, v.audit, models.AuditEvent{Action: models.AuditVaultCreate, VaultID: id}, err)
+}
+
+func (v *auditedVaultService) Rename(ctx context.Context, id uint, name string, revision uint64) error {
+	err := v.IVaultService.Rename(ctx, id, name, revision)
+
+	return recordOutcome(ctx, v.audit, models.AuditEvent{Action: models.AuditVaultUpdate, VaultID: id}, err)
+}
+
+func (v *auditedVaultService) Delete(ctx context.Context, id uint) error {
+	err := v.IVaultService.Delete(ctx, id)
+
+	return recordOutcome(ctx, v.audit, models.AuditEvent{Action: models.AuditVaultDelete, VaultID: id}, err)
+}
+
+// auditedItemService is an item service recording the items created, read,
+// updated and deleted in the audit log. Listing the items of a vault reads
+// all their secrets, so it is recorded as a read of the vault.
+type auditedItemService struct {
+	IItemService
+	audit IAuditService
+}
+
+func NewAuditedItemService(itemService IItemService, auditService IAuditService) *auditedItemService {
+	return &auditedItemService{itemService, auditService}
+}
+
+func (i *auditedItemService) Create(ctx context.Context, vaultID uint, input models.ItemInput) (uint, error) {
+	id, err := i.IItemService.Create(ctx, vaultID, input)
+
+	return id, recordOutcome(ctx, i.audit, models.AuditEvent{Action: models.AuditItemCreate, VaultID: vaultID, ItemID: id}, err)
+}
+
+func (i *auditedItemService) Get(ctx context.Context, id uint) (*models.ItemDetail, error) {
+	item, err := i.IItemService.Get(ctx, id)
+	event := models.AuditEvent{Action: models.AuditItemRead, ItemID: id}
+
+	if item != nil {
+		event.VaultID = item.VaultID
+	}
+
+	if err := recordOutcome(ctx, i.audit, event, err); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (i *auditedItemService) GetAll(ctx context.Context, vaultID uint, filter models.ItemFilter) ([]models.ItemDetail, error) {
+	items, err := i.IItemService.GetAll(ctx, vaultID, filter)
+
+	if err := recordOutcome(ctx, i.audit, models.AuditEvent{Action: models.AuditVaultRead, VaultID: vaultID}, err); err != nil {
+		return []models.ItemDetail{}, err
+	}
+
+	return items, nil
+}
+
+func (i *auditedItemService) Update(ctx context.Context, id uint, input models.ItemInput) error {
+	vaultID := itemVaultID(ctx, i.IItemService, id)
+	err := i.IItemService.Update(ctx, id, input)
+
+	return recordOutcome(ctx, i.audit, models.AuditEvent{Action: models.AuditItemUpdate, VaultID: vaultID, ItemID: id}, err)
+}
+
+func (i *auditedItemService) Delete(ctx context.Context, id uint) error {
+	vaultID := itemVaultID(ctx, i.IItemService, id)
+	err := i.IItemService.Delete(ctx, id)
+
+	return recordOutcome(ctx, i.audit, models.AuditEvent{Action: models.AuditItemDelete, VaultID: vaultID, ItemID: id}, err)
+}
+
+// auditedExportService is an export service recording exports in the audit log.
+type auditedExportService struct {
+	IExportService
+	audit IAuditService
+}
+
+func NewAuditedExportService(exportService IExportService, auditService IAuditService) *auditedExportService {
+	return &auditedExportService{exportService, auditService}
+}
+
+func (e *auditedExportService) ExportKDBX(ctx context.Context, vaultID uint, password string) (string, []byte, error) {
+	name, data, err := e.IExportService.ExportKDBX(ctx, vaultID, password)
+	event := models.AuditEvent{Action: models.AuditExport, VaultID: vaultID, Detail: "kdbx"}
+
+	if err := recordOutcome(ctx, e.audit, event, err); err != nil {
+		return "", nil, err
+	}
+
+	return name, data, nil
+}
+
+// auditedBackupService is a backup service recording backup exports and
+// restores in the audit log. Restores are recorded in their transaction.
+type auditedBackupService struct {
+	IBackupService
+	audit      IAuditService
+	transactor repositories.ITransactor
+}
+
+func NewAuditedBackupService(
+	backupService IBackupService,
+	auditService IAuditService,
+	transactor repositories.ITransactor,
+) *auditedBackupService {
+	return &auditedBackupService{backupService, auditService, transactor}
+}
+
+func (b *auditedBackupService) Export(ctx context.Context, passphrase string) ([]byte, error) {
+	data, err := b.IBackupService.Export(ctx, passphrase)
+
+	if err := recordOutcome(ctx, b.audit, models.AuditEvent{Action: models.AuditBackupExport}, err); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (b *auditedBackupService) Restore(ctx context.Context, data []byte, passphrase string, onConflict models.ConflictStrategy) (*models.RestoreReport, error) {
+	var report *models.RestoreReport
+
+	err := recordInTransaction(ctx, b.transactor, b.audit, func(ctx context.Context) error {
+		var err error
+		report, err = b.IBackupService.Restore(ctx, data, passphrase, onConflict)
+
+		return err
+	}, func() models.AuditEvent {
+		event := models.AuditEvent{Action: models.AuditBackupRestore}
+
+		if report != nil {
+			event.Detail = fmt.Sprintf("%d vaults, %d items", len(report.Vaults), len(report.ItemIDs))
+		}
+
+		return event
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// auditedImportService is an import service recording imports in the audit
+// log, in their transaction. Dry runs are recorded too.
+type auditedImportService struct {
+	IImportService
+	audit      IAuditService
+	transactor repositories.ITransactor
+}
+
+func NewAuditedImportService(
+	importService IImportService,
+	auditService IAuditService,
+	transactor repositories.ITransactor,
+) *auditedImportService {
+	return &auditedImportService{importService, auditService, transactor}
+}
+
+func (i *auditedImportService) Import(ctx context.Context, format importer.Format, data []byte, dryRun bool) (*models.ImportReport, error) {
+	return i.record(ctx, string(format), func(ctx context.Context) (*models.ImportReport, error) {
+		return i.IImportService.Import(ctx, format, data, dryRun)
+	})
+}
+
+func (i *auditedImportService) ImportKDBX(ctx context.Context, data []byte, password string, dryRun bool) (*models.ImportReport, error) {
+	return i.record(ctx, string(importer.KeePassKDBX), func(ctx context.Context) (*models.ImportReport, error) {
+		return i.IImportService.ImportKDBX(ctx, data, password, dryRun)
+	})
+}
+
+// record runs an import of format and records it.
+func (i *auditedImportService) record(
+	ctx context.Context,
+	format string,
+	run func(ctx context.Context) (*models.ImportReport, error),
+) (*models.ImportReport, error) {
+	var report *models.ImportReport
+
+	err := recordInTransaction(ctx, i.transactor, i.audit, func(ctx context.Context) error {
+		var err error
+		report, err = run(ctx)
+
+		return err
+	}, func() models.AuditEvent {
+		event := models.AuditEvent{Action: models.AuditImport, Detail: format}
+
+		if report != nil {
+			event.Detail = fmt.Sprintf("%s: %d imported, %d duplicates", format, len(report.Imported), len(report.Duplicates))
+
+			if report.DryRun {
+				event.Detail += " (dry run)"
+			}
+		}
+
+		return event
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// auditedRecoveryService is a recovery service recording the changes to
+// recovery keys and every step of their ceremonies in the audit log. The
+// shares approved and released are recorded in the transaction that changes
+// the ceremony, so no share is taken in or handed out unaudited.
+type auditedRecoveryService struct {
+	IRecoveryService
+	audit      IAuditService
+	transactor repositories.ITransactor
+}
+
+func NewAuditedRecoveryService(
+	recoveryService IRecoveryService,
+	auditService IAuditService,
+	transactor repositories.ITransactor,
+) *auditedRecoveryService {
+	return &auditedRecoveryService{recoveryService, auditService, transactor}
+}
+
+func (r *auditedRecoveryService) Setup(ctx context.Context, vaultID uint, input models.RecoveryKeyInput) (*models.RecoveryKeyDetail, error) {
+	key, err := r.IRecoveryService.Setup(ctx, vaultID, input)
+	event := models.AuditEvent{Action: models.AuditRecoverySetup, VaultID: vaultID}
+
+	if key != nil {
+		event.Detail = fmt.Sprintf("%d of %d admins", key.Threshold, len(key.Admins))
+	}
+
+	if err := recordOutcome(ctx, r.audit, event, err); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *auditedRecoveryService) Delete(ctx context.Context, vaultID uint) error {
+	err := r.IRecoveryService.Delete(ctx, vaultID)
+
+	return recordOutcome(ctx, r.audit, models.AuditEvent{Action: models.AuditRecoveryDelete, VaultID: vaultID}, err)
+}
+
+func (r *auditedRecoveryService) Start(ctx context.Context, vaultID uint, input models.RecoveryCeremonyInput) (*models.RecoveryCeremonyDetail, error) {
+	ceremony, err := r.IRecoveryService.Start(ctx, vaultID, input)
+	event := models.AuditEvent{Action: models.AuditRecoveryStart, VaultID: vaultID}
+
+	if ceremony != nil {
+		event.Detail = fmt.Sprintf("ceremony %d: %s", ceremony.ID, ceremony.Reason)
+	}
+
+	if err := recordOutcome(ctx, r.audit, event, err); err != nil {
+		return nil, err
+	}
+
+	return ceremony, nil
+}
+
+func (r *auditedRecoveryService) Approve(ctx context.Context, id uint, share []byte) (*models.RecoveryCeremonyDetail, error) {
+	var ceremony *models.RecoveryCeremonyDetail
+
+	err := recordInTransaction(ctx, r.transactor, r.audit, func(ctx context.Context) error {
+		var err error
+		ceremony, err = r.IRecoveryService.Approve(ctx, id, share)
+
+		return err
+	}, func() models.AuditEvent {
+		event := models.AuditEvent{Action: models.AuditRecoveryApprove, Detail: fmt.Sprintf("ceremony %d", id)}
+
+		if ceremony != nil {
+			event.VaultID = ceremony.VaultID
+			event.Detail = fmt.Sprintf("ceremony %d: %d of %d approvals", id, len(ceremony.Approvals), ceremony.Threshold)
+		}
+
+		return event
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ceremony, nil
+}
+
+func (r *auditedRecoveryService) Cancel(ctx context.Context, id uint) error {
+	err := r.IRecoveryService.Cancel(ctx, id)
+
+	return recordOutcome(ctx, r.audit, models.AuditEvent{Action: models.AuditRecoveryCancel, Detail: fmt.Sprintf("ceremony %d", id)}, err)
+}
+
+func (r *auditedRecoveryService) Complete(ctx context.Context, id uint) (*models.RecoveryShares, error) {
+	var shares *models.RecoveryShares
+
+	err := recordInTransaction(ctx, r.transactor, r.audit, func(ctx context.Context) error {
+		var err error
+		shares, err = r.IRecoveryService.Complete(ctx, id)
+
+		return err
+	}, func() models.AuditEvent {
+		event := models.AuditEvent{Action: models.AuditRecoveryComplete, Detail: fmt.Sprintf("ceremony %d", id)}
+
+		if shares != nil {
+			event.VaultID = shares.VaultID
+			event.Detail = fmt.Sprintf("ceremony %d: %d shares released", id, len(shares.Shares))
+		}
+
+		return event
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// auditedEmergencyService is an emergency service recording the changes to
+// emergency contacts, the accesses to the accounts of grantors and their
+// takeovers in the audit log. Changes are recorded in their transaction, and
+// the key and the vaults of a grantor are only handed out once their access
+// is recorded.
+type auditedEmergencyService struct {
+	IEmergencyService
+	audit      IAuditService
+	transactor repositories.ITransactor
+}
+
+func NewAuditedEmergencyService(
+	emergencyService IEmergencyService,
+	auditService IAuditService,
+	transactor repositories.ITransactor,
+) *auditedEmergencyService {
+	return &auditedEmergencyService{emergencyService, auditService, transactor}
+}
+
+func (e *auditedEmergencyService) Invite(ctx context.Context, input models.EmergencyContactInput) (*models.EmergencyContactDetail, error) {
+	var contact *models.EmergencyContactDetail
+
+	err := recordInTransaction(ctx, e.transactor, e.audit, func(ctx context.Context) error {
+		var err error
+		contact, err = e.IEmergencyService.Invite(ctx, input)
+
+		return err
+	}, func() models.AuditEvent {
+		event := models.AuditEvent{Action: models.AuditSharing, Detail: "emergency contact invited"}
+
+		if contact != nil {
+			event.Detail = fmt.Sprintf("emergency contact %d invited: %s access for user %d", contact.ID, contact.Access, contact.GranteeID)
+		}
+
+		return event
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+func (e *auditedEmergencyService) Confirm(ctx context.Context, id uint, wrappedKey []byte) error {
+	return e.recordSharing(ctx, fmt.Sprintf("emergency contact %d confirmed", id), func(ctx context.Context) error {
+		return e.IEmergencyService.Confirm(ctx, id, wrappedKey)
+	})
+}
+
+func (e *auditedEmergencyService) Approve(ctx context.Context, id uint) error {
+	return e.recordSharing(ctx, fmt.Sprintf("emergency contact %d approved", id), func(ctx context.Context) error {
+		return e.IEmergencyService.Approve(ctx, id)
+	})
+}
+
+func (e *auditedEmergencyService) Reject(ctx context.Context, id uint) error {
+	return e.recordSharing(ctx, fmt.Sprintf("emergency contact %d rejected", id), func(ctx context.Context) error {
+		return e.IEmergencyService.Reject(ctx, id)
+	})
+}
+
+func (e *auditedEmergencyService) Delete(ctx context.Context, id uint) error {
+	return e.recordSharing(ctx, fmt.Sprintf("emergency contact %d deleted", id), func(ctx context.Context) error {
+		return e.IEmergencyService.Delete(ctx, id)
+	})
+}
+
+// recordSharing runs a change to an emergency contact and records it as a sharing change.
+func (e *auditedEmergencyService) recordSharing(ctx context.Context, detail string, change func(ctx context.Context) error) error {
+	return recordInTransaction(ctx, e.transactor, e.audit, change, func() models.AuditEvent {
+		return models.AuditEvent{Action: models.AuditSharing, Detail: detail}
+	})
+}
+
+func (e *auditedEmergencyService) Access(ctx context.Context, id uint) (*models.EmergencyAccess, error) {
+	access, err := e.IEmergencyService.Access(ctx, id)
+	event := models.AuditEvent{Action: models.AuditEmergencyAccess, Detail: fmt.Sprintf("emergency contact %d", id)}
+
+	if access != nil {
+		event.Detail = fmt.Sprintf("emergency contact %d: %s access to user %d", id, access.Access, access.GrantorID)
+	}
+
+	if err := recordOutcome(ctx, e.audit, event, err); err != nil {
+		return nil, err
+	}
+
+	return access, nil
+}
+
+func (e *auditedEmergencyService) Takeover(ctx context.Context, id uint, input models.EmergencyTakeoverInput) error {
+	return recordInTransaction(ctx, e.transactor, e.audit, func(ctx context.Context) error {
+		return e.IEmergencyService.Takeover(ctx, id, input)
+	}, func() models.AuditEvent {
+		return models.AuditEvent{Action: models.AuditEmergencyTakeover, Detail: fmt.Sprintf("emergency contact %d", id)}
+	})
+}
+
+// auditedUserService is a user service recording the changes of master
+// password in the audit log.
+type auditedUserService struct {
+	IUserService
+	audit IAuditService
+}
+
+func NewAuditedUserService(userService IUserService, auditService IAuditService) *auditedUserService {
+	return &auditedUserService{userService, auditService}
+}
+
+func (u *auditedUserService) ChangePassword(ctx context.Context, change models.PasswordChange) error {
+	err := u.IUserService.ChangePassword(ctx, change)
+
+	return recordOutcome(ctx, u.audit, models.AuditEvent{Action: models.AuditPasswordChange}, err)
+}
+
+// auditedKeyService is a key service recording the rotations of key pairs
+// and the verifications of the keys of other users in the audit log, in the
+// transaction that makes them.
+type auditedKeyService struct {
+	IKeyService
+	audit      IAuditService
+	transactor repositories.ITransactor
+}
+
+func NewAuditedKeyService(keyService IKeyService, auditService IAuditService, transactor repositories.ITransactor) *auditedKeyService {
+	return &auditedKeyService{keyService, auditService, transactor}
+}
+
+func (k *auditedKeyService) Verify(ctx context.Context, input models.KeyVerificationInput) (*models.PublicKeyDetail, error) {
+	var key *models.PublicKeyDetail
+
+	err := recordInTransaction(ctx, k.transactor, k.audit, func(ctx context.Context) error {
+		var err error
+		key, err = k.IKeyService.Verify(ctx, input)
+
+		return err
+	}, func() models.AuditEvent {
+		event := models.AuditEvent{Action: models.AuditSharing, Detail: fmt.Sprintf("key of %s verified", input.Email)}
+
+		if key != nil {
+			event.Detail = fmt.Sprintf("key of user %d verified: %s", key.UserID, key.Fingerprint)
+		}
+
+		return event
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (k *auditedKeyService) Rotate(ctx context.Context, rotation models.KeyRotation) error {
+	return recordInTransaction(ctx, k.transactor, k.audit, func(ctx context.Context) error {
+		return k.IKeyService.Rotate(ctx, rotation)
+	}, func() models.AuditEvent {
+		return models.AuditEvent{Action: models.AuditKeyRotate, Detail: fmt.Sprintf("%d wrapped keys", len(rotation.WrappedKeys))}
+	})
+}
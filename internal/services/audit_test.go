@@ -0,0 +1,699 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/importer"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// auditChain returns n events chained as Record chains them.
+func auditChain(n int) []models.AuditEvent {
+	events := make([]models.AuditEvent, n)
+	prevHash := ""
+
+	for i := range events {
+		events[i] = models.AuditEvent{
+			Seq:       uint64(i + 1),
+			Action:    models.AuditItemRead,
+			Outcome:   models.AuditSuccess,
+			UserID:    10,
+			ItemID:    uint(i + 1),
+			CreatedAt: time.Date(2023, 5, 6, 10, i, 0, 0, time.UTC),
+			PrevHash:  prevHash,
+		}
+		events[i].Hash = hashAuditEvent(events[i])
+		prevHash = events[i].Hash
+	}
+
+	return events
+}
+
+func TestNewAuditService(t *testing.T) {
+	repoMock := &mocks.AuditRepositoryMock{}
+	transactorMock := &mocks.TransactorMock{}
+	clockMock := clock.Clock{}
+
+	auditSvc := NewAuditService(repoMock, transactorMock, clockMock)
+
+	assert.Equal(t, repoMock, auditSvc.repository)
+	assert.Equal(t, transactorMock, auditSvc.transactor)
+	assert.Equal(t, clockMock, auditSvc.clock)
+}
+
+func TestRecordAudit(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	ctx = context.WithValue(ctx, keys.ClientIPKey, "203.0.113.7")
+	ctx = context.WithValue(ctx, keys.UserAgentKey, "gopass-cli")
+	now := time.Date(2023, 5, 6, 10, 0, 0, 123456789, time.FixedZone("BRT", -3*60*60))
+	clockMock := clock.Clock{NowFn: func() time.Time { return now }}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.AuditRepositoryMock{}
+		last := auditChain(4)[3]
+
+		repoMock.On("FindLast", ctx).Return(&last, nil)
+		repoMock.On("Save", ctx, mock.Anything)
+
+		// when
+		auditSvc := NewAuditService(repoMock, transactionMock(), clockMock)
+		error := auditSvc.Record(ctx, models.AuditEvent{Action: models.AuditVaultCreate, Outcome: models.AuditSuccess, VaultID: 1})
+
+		// then
+		assert.Nil(t, error)
+
+		saved := repoMock.Calls[1].Arguments.Get(1).(*models.AuditEvent)
+		expected := models.AuditEvent{
+			Seq:       5,
+			Action:    models.AuditVaultCreate,
+			Outcome:   models.AuditSuccess,
+			UserID:    10,
+			VaultID:   1,
+			IP:        "203.0.113.7",
+			UserAgent: "gopass-cli",
+			CreatedAt: time.Date(2023, 5, 6, 13, 0, 0, 123456000, time.UTC),
+			PrevHash:  last.Hash,
+		}
+		expected.Hash = hashAuditEvent(expected)
+		assert.Equal(t, &expected, saved)
+	})
+
+	t.Run("first event", func(t *testing.T) {
+		// given
+		repoMock := &mocks.AuditRepositoryMock{}
+		anonymous := context.TODO()
+
+		repoMock.On("FindLast", anonymous).Return(&models.AuditEvent{}, nil)
+		repoMock.On("Save", anonymous, mock.Anything)
+
+		// when
+		auditSvc := NewAuditService(repoMock, transactionMock(), clockMock)
+		error := auditSvc.Record(anonymous, models.AuditEvent{Action: models.AuditLoginFailed, UserID: 3})
+
+		// then
+		assert.Nil(t, error)
+
+		saved := repoMock.Calls[1].Arguments.Get(1).(*models.AuditEvent)
+		assert.Equal(t, uint64(1), saved.Seq)
+		assert.Equal(t, uint(3), saved.UserID)
+		assert.Empty(t, saved.PrevHash)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		repoMock := &mocks.AuditRepositoryMock{}
+
+		repoMock.On("FindLast", ctx).Return(&models.AuditEvent{}, nil)
+		repoMock.On("Save", ctx, mock.Anything).Return(errors.New("error when saving event"))
+
+		// when
+		auditSvc := NewAuditService(repoMock, transactionMock(), clockMock)
+		error := auditSvc.Record(ctx, models.AuditEvent{Action: models.AuditItemRead})
+
+		// then
+		assert.Equal(t, "error when saving event", error.Error())
+	})
+}
+
+func TestAuditEvents(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+	from := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		filter   models.AuditFilter
+		expected models.AuditFilter
+	}{
+		{"own events only", models.AuditFilter{UserID: 99, VaultID: 1}, models.AuditFilter{UserID: userID, VaultID: 1, Limit: 100}},
+		{"time range", models.AuditFilter{From: from, To: to, Limit: 5}, models.AuditFilter{UserID: userID, From: from, To: to, Limit: 5}},
+		{"limit capped", models.AuditFilter{Limit: 5000}, models.AuditFilter{UserID: userID, Limit: 1000}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock := &mocks.AuditRepositoryMock{}
+			events := auditChain(2)
+
+			repoMock.On("Find", ctx, tc.expected).Return(events, nil)
+
+			// when
+			auditSvc := NewAuditService(repoMock, &mocks.TransactorMock{}, clock.Clock{})
+			actual, error := auditSvc.Events(ctx, tc.filter)
+
+			// then
+			assert.Nil(t, error)
+			assert.Equal(t, events, actual)
+		})
+	}
+
+	t.Run("from after to", func(t *testing.T) {
+		// when
+		auditSvc := NewAuditService(&mocks.AuditRepositoryMock{}, &mocks.TransactorMock{}, clock.Clock{})
+		actual, error := auditSvc.Events(ctx, models.AuditFilter{From: to, To: from})
+
+		// then
+		assert.Equal(t, []models.AuditEvent{}, actual)
+		assert.Equal(t, "from must be before to", error.Error())
+	})
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// when
+		auditSvc := NewAuditService(&mocks.AuditRepositoryMock{}, &mocks.TransactorMock{}, clock.Clock{})
+		actual, error := auditSvc.Events(context.TODO(), models.AuditFilter{})
+
+		// then
+		assert.Equal(t, []models.AuditEvent{}, actual)
+		assert.Equal(t, "user is not authenticated", error.Error())
+	})
+}
+
+func TestVerifyAudit(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	testCases := []struct {
+		name     string
+		tamper   func(events []models.AuditEvent) []models.AuditEvent
+		expected func(events []models.AuditEvent) *models.AuditVerification
+	}{
+		{
+			name:   "valid",
+			tamper: func(events []models.AuditEvent) []models.AuditEvent { return events },
+			expected: func(events []models.AuditEvent) *models.AuditVerification {
+				return &models.AuditVerification{Valid: true, Entries: 3, Head: events[2].Hash}
+			},
+		},
+		{
+			name: "entry changed",
+			tamper: func(events []models.AuditEvent) []models.AuditEvent {
+				events[1].ItemID = 99
+				return events
+			},
+			expected: func(events []models.AuditEvent) *models.AuditVerification {
+				return &models.AuditVerification{Entries: 1, Head: events[0].Hash, BrokenAt: 2, Reason: "hash doesn't match the entry"}
+			},
+		},
+		{
+			name: "entry changed and rehashed",
+			tamper: func(events []models.AuditEvent) []models.AuditEvent {
+				events[1].ItemID = 99
+				events[1].Hash = hashAuditEvent(events[1])
+				return events
+			},
+			expected: func(events []models.AuditEvent) *models.AuditVerification {
+				return &models.AuditVerification{Entries: 2, Head: events[1].Hash, BrokenAt: 3, Reason: "previous hash doesn't match the previous entry"}
+			},
+		},
+		{
+			name: "entry removed",
+			tamper: func(events []models.AuditEvent) []models.AuditEvent {
+				return append(events[:1], events[2:]...)
+			},
+			expected: func(events []models.AuditEvent) *models.AuditVerification {
+				return &models.AuditVerification{Entries: 1, Head: events[0].Hash, BrokenAt: 3, Reason: "entry 2 is missing"}
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock := &mocks.AuditRepositoryMock{}
+			events := tc.tamper(auditChain(3))
+
+			repoMock.On("FindAfter", ctx, uint64(0), auditVerifyBatch).Return(events, nil)
+
+			// when
+			auditSvc := NewAuditService(repoMock, &mocks.TransactorMock{}, clock.Clock{})
+			actual, error := auditSvc.Verify(ctx)
+
+			// then
+			assert.Nil(t, error)
+			assert.Equal(t, tc.expected(events), actual)
+		})
+	}
+
+	t.Run("reads the log in batches", func(t *testing.T) {
+		// given
+		repoMock := &mocks.AuditRepositoryMock{}
+		events := auditChain(auditVerifyBatch + 1)
+
+		repoMock.On("FindAfter", ctx, uint64(0), auditVerifyBatch).Return(events[:auditVerifyBatch], nil)
+		repoMock.On("FindAfter", ctx, uint64(auditVerifyBatch), auditVerifyBatch).Return(events[auditVerifyBatch:], nil)
+
+		// when
+		auditSvc := NewAuditService(repoMock, &mocks.TransactorMock{}, clock.Clock{})
+		actual, error := auditSvc.Verify(ctx)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, &models.AuditVerification{Valid: true, Entries: auditVerifyBatch + 1, Head: events[auditVerifyBatch].Hash}, actual)
+	})
+
+	t.Run("empty log", func(t *testing.T) {
+		// given
+		repoMock := &mocks.AuditRepositoryMock{}
+
+		repoMock.On("FindAfter", ctx, uint64(0), auditVerifyBatch).Return([]models.AuditEvent{}, nil)
+
+		// when
+		auditSvc := NewAuditService(repoMock, &mocks.TransactorMock{}, clock.Clock{})
+		actual, error := auditSvc.Verify(ctx)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, &models.AuditVerification{Valid: true}, actual)
+	})
+}
+
+func TestAuditedServices(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	t.Run("vault created", func(t *testing.T) {
+		// given
+		vaultSvcMock := &mocks.VaultServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+
+		vaultSvcMock.On("Create", ctx, "Work").Return(uint(1), nil)
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditVaultCreate, Outcome: models.AuditSuccess, VaultID: 1})
+
+		// when
+		actual, error := NewAuditedVaultService(vaultSvcMock, auditMock).Create(ctx, "Work")
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, uint(1), actual)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("item read", func(t *testing.T) {
+		// given
+		itemSvcMock := &mocks.ItemServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		item := &models.ItemDetail{ID: 5, VaultID: 1, Password: "secret"}
+
+		itemSvcMock.On("Get", ctx, uint(5)).Return(item, nil)
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditItemRead, Outcome: models.AuditSuccess, VaultID: 1, ItemID: 5})
+
+		// when
+		actual, error := NewAuditedItemService(itemSvcMock, auditMock).Get(ctx, 5)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, item, actual)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("item read not recorded", func(t *testing.T) {
+		// given
+		itemSvcMock := &mocks.ItemServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+
+		itemSvcMock.On("Get", ctx, uint(5)).Return(&models.ItemDetail{ID: 5, VaultID: 1, Password: "secret"}, nil)
+		auditMock.On("Record", ctx, mock.Anything).Return(errors.New("error when recording"))
+
+		// when
+		actual, error := NewAuditedItemService(itemSvcMock, auditMock).Get(ctx, 5)
+
+		// then: the secret isn't returned unaudited
+		assert.Nil(t, actual)
+		assert.Equal(t, "error when recording", error.Error())
+	})
+
+	t.Run("item deleted", func(t *testing.T) {
+		// given
+		itemSvcMock := &mocks.ItemServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+
+		itemSvcMock.On("Get", ctx, uint(5)).Return(&models.ItemDetail{ID: 5, VaultID: 1}, nil)
+		itemSvcMock.On("Delete", ctx, uint(5)).Return(nil)
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditItemDelete, Outcome: models.AuditSuccess, VaultID: 1, ItemID: 5})
+
+		// when
+		error := NewAuditedItemService(itemSvcMock, auditMock).Delete(ctx, 5)
+
+		// then
+		assert.Nil(t, error)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("item update failed", func(t *testing.T) {
+		// given
+		itemSvcMock := &mocks.ItemServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		input := models.ItemInput{Name: "GitHub", Revision: 1}
+		stale := cerrors.PreconditionFailedError("item was changed at revision 2, since revision 1")
+
+		itemSvcMock.On("Get", ctx, uint(5)).Return(&models.ItemDetail{ID: 5, VaultID: 1}, nil)
+		itemSvcMock.On("Update", ctx, uint(5), input).Return(stale)
+		auditMock.On("Record", ctx, models.AuditEvent{
+			Action: models.AuditItemUpdate, Outcome: models.AuditFailure, VaultID: 1, ItemID: 5, Detail: stale.Error(),
+		}).Return(errors.New("error when recording"))
+
+		// when
+		error := NewAuditedItemService(itemSvcMock, auditMock).Update(ctx, 5, input)
+
+		// then: the error of the update is returned
+		assert.Equal(t, stale, error)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("vault items read", func(t *testing.T) {
+		// given
+		itemSvcMock := &mocks.ItemServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+
+		itemSvcMock.On("GetAll", ctx, uint(1), models.ItemFilter{}).Return([]models.ItemDetail{}, errors.New("error when finding items"))
+		auditMock.On("Record", ctx, models.AuditEvent{
+			Action: models.AuditVaultRead, Outcome: models.AuditFailure, VaultID: 1, Detail: "internal error",
+		})
+
+		// when
+		actual, error := NewAuditedItemService(itemSvcMock, auditMock).GetAll(ctx, 1, models.ItemFilter{})
+
+		// then
+		assert.Equal(t, []models.ItemDetail{}, actual)
+		assert.Equal(t, "error when finding items", error.Error())
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("exports", func(t *testing.T) {
+		// given
+		exportSvcMock := &mocks.ExportServiceMock{}
+		backupSvcMock := &mocks.BackupServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+
+		exportSvcMock.On("ExportKDBX", ctx, uint(1), "secret").Return("Work", []byte("kdbx"), nil)
+		backupSvcMock.On("Export", ctx, "passphrase").Return([]byte("backup"), nil)
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditExport, Outcome: models.AuditSuccess, VaultID: 1, Detail: "kdbx"})
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditBackupExport, Outcome: models.AuditSuccess})
+
+		// when
+		_, _, exportErr := NewAuditedExportService(exportSvcMock, auditMock).ExportKDBX(ctx, 1, "secret")
+		_, backupErr := NewAuditedBackupService(backupSvcMock, auditMock, &mocks.TransactorMock{}).Export(ctx, "passphrase")
+
+		// then
+		assert.Nil(t, exportErr)
+		assert.Nil(t, backupErr)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("recovery ceremony approved", func(t *testing.T) {
+		// given
+		recoverySvcMock := &mocks.RecoveryServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		transactorMock := &mocks.TransactorMock{}
+		ceremony := &models.RecoveryCeremonyDetail{ID: 5, VaultID: 1, Status: models.RecoveryApproved, Threshold: 2, Approvals: make([]models.RecoveryApprovalDetail, 2)}
+
+		var calls []string
+		transactorMock.On("WithinTransaction", ctx).Run(func(mock.Arguments) { calls = append(calls, "WithinTransaction") })
+		recoverySvcMock.On("Approve", ctx, uint(5), []byte("share")).Run(func(mock.Arguments) { calls = append(calls, "Approve") }).Return(ceremony, nil)
+		auditMock.On("Record", ctx, models.AuditEvent{
+			Action: models.AuditRecoveryApprove, Outcome: models.AuditSuccess, VaultID: 1, Detail: "ceremony 5: 2 of 2 approvals",
+		}).Run(func(mock.Arguments) { calls = append(calls, "Record") })
+
+		// when
+		actual, error := NewAuditedRecoveryService(recoverySvcMock, auditMock, transactorMock).Approve(ctx, 5, []byte("share"))
+
+		// then: the approval is recorded in its transaction
+		assert.Nil(t, error)
+		assert.Equal(t, ceremony, actual)
+		assert.Equal(t, []string{"WithinTransaction", "Approve", "Record"}, calls)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("recovery ceremony approval failed", func(t *testing.T) {
+		// given
+		recoverySvcMock := &mocks.RecoveryServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		transactorMock.On("WithinTransaction", ctx)
+		recoverySvcMock.On("Approve", ctx, uint(5), []byte("share")).Return(nil, cerrors.ConflictError("ceremony is cancelled"))
+		auditMock.On("Record", ctx, models.AuditEvent{
+			Action: models.AuditRecoveryApprove, Outcome: models.AuditFailure, Detail: "ceremony is cancelled",
+		})
+
+		// when
+		actual, error := NewAuditedRecoveryService(recoverySvcMock, auditMock, transactorMock).Approve(ctx, 5, []byte("share"))
+
+		// then: the failure is recorded once the transaction is rolled back
+		assert.Nil(t, actual)
+		assert.Equal(t, cerrors.ConflictError("ceremony is cancelled"), error)
+
+		auditMock.AssertExpectations(t)
+		auditMock.AssertNumberOfCalls(t, "Record", 1)
+	})
+
+	t.Run("recovery shares released unaudited", func(t *testing.T) {
+		// given
+		recoverySvcMock := &mocks.RecoveryServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		transactorMock.On("WithinTransaction", ctx)
+		recoverySvcMock.On("Complete", ctx, uint(5)).Return(&models.RecoveryShares{CeremonyID: 5, VaultID: 1, Shares: [][]byte{[]byte("a"), []byte("b")}}, nil)
+		auditMock.On("Record", ctx, models.AuditEvent{
+			Action: models.AuditRecoveryComplete, Outcome: models.AuditSuccess, VaultID: 1, Detail: "ceremony 5: 2 shares released",
+		}).Return(errors.New("error when recording"))
+
+		// when
+		actual, error := NewAuditedRecoveryService(recoverySvcMock, auditMock, transactorMock).Complete(ctx, 5)
+
+		// then: the shares aren't handed out, and the transaction that wiped
+		// them is rolled back
+		assert.Nil(t, actual)
+		assert.Equal(t, "error when recording", error.Error())
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("emergency access unaudited", func(t *testing.T) {
+		// given
+		emergencySvcMock := &mocks.EmergencyServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+
+		emergencySvcMock.On("Access", ctx, uint(3)).Return(&models.EmergencyAccess{GrantorID: 20, Access: models.EmergencyView, WrappedKey: []byte("wrapped")}, nil)
+		auditMock.On("Record", ctx, models.AuditEvent{
+			Action: models.AuditEmergencyAccess, Outcome: models.AuditSuccess, Detail: "emergency contact 3: view access to user 20",
+		}).Return(errors.New("error when recording"))
+
+		// when
+		actual, error := NewAuditedEmergencyService(emergencySvcMock, auditMock, &mocks.TransactorMock{}).Access(ctx, 3)
+
+		// then: the key isn't handed out
+		assert.Nil(t, actual)
+		assert.Equal(t, "error when recording", error.Error())
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("emergency takeover", func(t *testing.T) {
+		// given
+		emergencySvcMock := &mocks.EmergencyServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		transactorMock := &mocks.TransactorMock{}
+		input := models.EmergencyTakeoverInput{AuthKey: "new-key"}
+
+		transactorMock.On("WithinTransaction", ctx)
+		emergencySvcMock.On("Takeover", ctx, uint(3), input).Return(cerrors.BadRequestError("encryptedPrivateKey is required"))
+		auditMock.On("Record", ctx, models.AuditEvent{
+			Action: models.AuditEmergencyTakeover, Outcome: models.AuditFailure, Detail: "encryptedPrivateKey is required",
+		})
+
+		// when
+		error := NewAuditedEmergencyService(emergencySvcMock, auditMock, transactorMock).Takeover(ctx, 3, input)
+
+		// then
+		assert.Equal(t, cerrors.BadRequestError("encryptedPrivateKey is required"), error)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("emergency contact rejected unaudited", func(t *testing.T) {
+		// given
+		emergencySvcMock := &mocks.EmergencyServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		var calls []string
+		transactorMock.On("WithinTransaction", ctx).Run(func(mock.Arguments) { calls = append(calls, "WithinTransaction") })
+		emergencySvcMock.On("Reject", ctx, uint(3)).Run(func(mock.Arguments) { calls = append(calls, "Reject") }).Return(nil)
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditSharing, Outcome: models.AuditSuccess, Detail: "emergency contact 3 rejected"}).
+			Run(func(mock.Arguments) { calls = append(calls, "Record") }).Return(errors.New("error when recording"))
+
+		// when
+		error := NewAuditedEmergencyService(emergencySvcMock, auditMock, transactorMock).Reject(ctx, 3)
+
+		// then: the rejection is rolled back along with its transaction
+		assert.Equal(t, "error when recording", error.Error())
+		assert.Equal(t, []string{"WithinTransaction", "Reject", "Record"}, calls)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("emergency contact invited", func(t *testing.T) {
+		// given
+		emergencySvcMock := &mocks.EmergencyServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		transactorMock := &mocks.TransactorMock{}
+		input := models.EmergencyContactInput{Email: "jane@test.com", Access: models.EmergencyView}
+		contact := &models.EmergencyContactDetail{ID: 3, GrantorID: 10, GranteeID: 20, Access: models.EmergencyView, Status: models.EmergencyInvited}
+
+		transactorMock.On("WithinTransaction", ctx)
+		emergencySvcMock.On("Invite", ctx, input).Return(contact, nil)
+		auditMock.On("Record", ctx, models.AuditEvent{
+			Action: models.AuditSharing, Outcome: models.AuditSuccess, Detail: "emergency contact 3 invited: view access for user 20",
+		})
+
+		// when
+		actual, error := NewAuditedEmergencyService(emergencySvcMock, auditMock, transactorMock).Invite(ctx, input)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, contact, actual)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("key verified", func(t *testing.T) {
+		// given
+		keySvcMock := &mocks.KeyServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		transactorMock := &mocks.TransactorMock{}
+		input := models.KeyVerificationInput{Email: "jane@test.com", Fingerprint: "abcd"}
+		key := &models.PublicKeyDetail{UserID: 20, Email: "jane@test.com", Fingerprint: "abcd", Verified: true}
+
+		transactorMock.On("WithinTransaction", ctx)
+		keySvcMock.On("Verify", ctx, input).Return(key, nil)
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditSharing, Outcome: models.AuditSuccess, Detail: "key of user 20 verified: abcd"})
+
+		// when
+		actual, error := NewAuditedKeyService(keySvcMock, auditMock, transactorMock).Verify(ctx, input)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, key, actual)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("backup restored", func(t *testing.T) {
+		// given
+		backupSvcMock := &mocks.BackupServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		transactorMock := &mocks.TransactorMock{}
+		report := &models.RestoreReport{Vaults: []models.RestoredVault{{OldID: 1, NewID: 4}}, ItemIDs: map[uint]uint{1: 7, 2: 8}}
+
+		transactorMock.On("WithinTransaction", ctx)
+		backupSvcMock.On("Restore", ctx, []byte("backup"), "passphrase", models.ConflictRename).Return(report, nil)
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditBackupRestore, Outcome: models.AuditSuccess, Detail: "1 vaults, 2 items"})
+
+		// when
+		actual, error := NewAuditedBackupService(backupSvcMock, auditMock, transactorMock).Restore(ctx, []byte("backup"), "passphrase", models.ConflictRename)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, report, actual)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("import dry run", func(t *testing.T) {
+		// given
+		importSvcMock := &mocks.ImportServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		transactorMock := &mocks.TransactorMock{}
+		report := &models.ImportReport{DryRun: true, Imported: make([]models.ImportedItem, 3), Duplicates: make([]models.ImportedItem, 1)}
+
+		transactorMock.On("WithinTransaction", ctx)
+		importSvcMock.On("Import", ctx, importer.Bitwarden, []byte("data"), true).Return(report, nil)
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditImport, Outcome: models.AuditSuccess, Detail: "bitwarden: 3 imported, 1 duplicates (dry run)"})
+
+		// when
+		actual, error := NewAuditedImportService(importSvcMock, auditMock, transactorMock).Import(ctx, importer.Bitwarden, []byte("data"), true)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, report, actual)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("kdbx import failed", func(t *testing.T) {
+		// given
+		importSvcMock := &mocks.ImportServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		transactorMock.On("WithinTransaction", ctx)
+		importSvcMock.On("ImportKDBX", ctx, []byte("data"), "secret", false).Return(nil, cerrors.UnauthorizedError("invalid password"))
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditImport, Outcome: models.AuditFailure, Detail: "invalid password"})
+
+		// when
+		actual, error := NewAuditedImportService(importSvcMock, auditMock, transactorMock).ImportKDBX(ctx, []byte("data"), "secret", false)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, cerrors.UnauthorizedError("invalid password"), error)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("password changed", func(t *testing.T) {
+		// given
+		userSvcMock := &mocks.UserServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		change := models.PasswordChange{CurrentAuthKey: "old-key", NewAuthKey: "new-key"}
+
+		userSvcMock.On("ChangePassword", ctx, change).Return(cerrors.UnauthorizedError("invalid credentials"))
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditPasswordChange, Outcome: models.AuditFailure, Detail: "invalid credentials"})
+
+		// when
+		error := NewAuditedUserService(userSvcMock, auditMock).ChangePassword(ctx, change)
+
+		// then
+		assert.Equal(t, cerrors.UnauthorizedError("invalid credentials"), error)
+
+		auditMock.AssertExpectations(t)
+	})
+
+	t.Run("key rotated", func(t *testing.T) {
+		// given
+		keySvcMock := &mocks.KeyServiceMock{}
+		auditMock := &mocks.AuditServiceMock{}
+		transactorMock := &mocks.TransactorMock{}
+		rotation := models.KeyRotation{PublicKey: []byte("public"), WrappedKeys: make([]models.WrappedKey, 2)}
+
+		transactorMock.On("WithinTransaction", ctx)
+		keySvcMock.On("Rotate", ctx, rotation).Return(nil)
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditKeyRotate, Outcome: models.AuditSuccess, Detail: "2 wrapped keys"})
+
+		// when
+		error := NewAuditedKeyService(keySvcMock, auditMock, transactorMock).Rotate(ctx, rotation)
+
+		// then
+		assert.Nil(t, error)
+
+		auditMock.AssertExpectations(t)
+	})
+}
@@ -2,10 +2,14 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"log"
 	"time"
 
 	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/models"
 	"github.com/edgardjr92/gopass/internal/repositories"
 	"github.com/edgardjr92/gopass/internal/utils"
 	"github.com/edgardjr92/gopass/pkg/clock"
@@ -22,10 +26,16 @@ type authService struct {
 	jwt        jwt.JWTGenerator
 	clock      clock.Clock
 	repository repositories.IUserRepository
+	audit      IAuditService
 }
 
-func NewAuthService(jwt jwt.JWTGenerator, repository repositories.IUserRepository, clock clock.Clock) *authService {
-	return &authService{jwt, clock, repository}
+func NewAuthService(
+	jwt jwt.JWTGenerator,
+	repository repositories.IUserRepository,
+	audit IAuditService,
+	clock clock.Clock,
+) *authService {
+	return &authService{jwt, clock, repository, audit}
 }
 
 func (a *authService) Login(ctx context.Context, email, authKey string) (string, error) {
@@ -44,16 +54,71 @@ func (a *authService) Login(ctx context.Context, email, authKey string) (string,
 		return "", err
 	}
 
-	if user.ID == 0 || user.AuthKey != authKey {
+	if user.ID == 0 || !checkAuthKey(user.AuthKey, authKey) {
+		event := models.AuditEvent{Action: models.AuditLoginFailed, Outcome: models.AuditFailure, UserID: user.ID, Email: email}
+
+		if err := a.audit.Record(ctx, event); err != nil {
+			log.Printf("error while trying to record audit event: %v", err.Error())
+		}
+
 		return "", cerrors.UnauthorizedError("invalid credentials")
 	}
 
-	token, err := a.jwt.Generate(user.ID, a.clock.Now().Add(24*time.Hour))
+	if err := a.audit.Record(ctx, models.AuditEvent{Action: models.AuditLogin, Outcome: models.AuditSuccess, UserID: user.ID, Email: email}); err != nil {
+		return "", err
+	}
+
+	expiresAt := a.clock.Now().Add(24 * time.Hour)
+	token, err := a.jwt.Generate(user.ID, user.SessionVersion, expiresAt)
 
 	if err != nil {
 		log.Printf("error while trying to generate JWT token: %v", err.Error())
 		return "", err
 	}
 
+	event := models.AuditEvent{
+		Action:  models.AuditTokenIssued,
+		Outcome: models.AuditSuccess,
+		UserID:  user.ID,
+		Detail:  "expires at " + expiresAt.UTC().Format(time.RFC3339),
+	}
+
+	if err := a.audit.Record(ctx, event); err != nil {
+		return "", err
+	}
+
 	return token, nil
 }
+
+// hashAuthKey returns the hex SHA-256 of an auth key, the value stored for
+// the user. Auth keys are derived with Argon2 by the clients already, so a
+// fast hash is enough to keep a leaked database from logging in.
+func hashAuthKey(authKey string) string {
+	sum := sha256.Sum256([]byte(authKey))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// checkAuthKey tells whether authKey matches the stored hash, in constant time.
+func checkAuthKey(hash, authKey string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(hashAuthKey(authKey))) == 1
+}
+
+// SessionVersion returns the session version of the users in repository, for
+// jwt.NewSessionValidator to revoke the tokens issued before it was bumped.
+func SessionVersion(repository repositories.IUserRepository) jwt.SessionVersion {
+	return func(userID uint) (uint, error) {
+		user, err := repository.FindByID(context.Background(), userID)
+
+		if err != nil {
+			log.Printf("error while trying to find user by id: %v", err.Error())
+			return 0, err
+		}
+
+		if user.ID == 0 {
+			return 0, cerrors.UnauthorizedError("user not found")
+		}
+
+		return user.SessionVersion, nil
+	}
+}
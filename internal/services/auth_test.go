@@ -2,10 +2,12 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/edgardjr92/gopass/internal/cerrors"
 	"github.com/edgardjr92/gopass/internal/mocks"
 	"github.com/edgardjr92/gopass/internal/models"
 	"github.com/edgardjr92/gopass/pkg/clock"
@@ -17,13 +19,15 @@ import (
 func TestNewAuthService(t *testing.T) {
 	jwtMock := &mocks.JWTGeneratorMock{}
 	repoMock := &mocks.UserRepositoryMock{}
+	auditMock := &mocks.AuditServiceMock{}
 	clockMock := clock.Clock{}
 
-	authSrv := NewAuthService(jwtMock, repoMock, clockMock)
+	authSrv := NewAuthService(jwtMock, repoMock, auditMock, clockMock)
 
 	assert.NotNil(t, authSrv)
 	assert.Equal(t, jwtMock, authSrv.jwt)
 	assert.Equal(t, repoMock, authSrv.repository)
+	assert.Equal(t, auditMock, authSrv.audit)
 	assert.Equal(t, clockMock, authSrv.clock)
 }
 
@@ -46,13 +50,19 @@ func TestLogin(t *testing.T) {
 		repoMock := &mocks.UserRepositoryMock{}
 
 		repoMock.On("FindByEmail", ctx, email).
-			Return(&models.User{Model: gorm.Model{ID: 1}, AuthKey: authKey}, nil)
+			Return(&models.User{Model: gorm.Model{ID: 1}, AuthKey: hashAuthKey(authKey), SessionVersion: 3}, nil)
 
 		tomorrow := time.Date(2023, 5, 7, 0, 0, 0, 0, time.UTC)
-		jwtMock.On("Generate", uint(1), tomorrow).Return(token, nil)
+		jwtMock.On("Generate", uint(1), uint(3), tomorrow).Return(token, nil)
+
+		auditMock := &mocks.AuditServiceMock{}
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditLogin, Outcome: models.AuditSuccess, UserID: 1, Email: email})
+		auditMock.On("Record", ctx, models.AuditEvent{
+			Action: models.AuditTokenIssued, Outcome: models.AuditSuccess, UserID: 1, Detail: "expires at 2023-05-07T00:00:00Z",
+		})
 
 		// when
-		authSrv := &authService{jwtMock, clockMock, repoMock}
+		authSrv := &authService{jwtMock, clockMock, repoMock, auditMock}
 		actual, error := authSrv.Login(ctx, email, authKey)
 
 		// then
@@ -61,6 +71,7 @@ func TestLogin(t *testing.T) {
 
 		repoMock.AssertExpectations(t)
 		jwtMock.AssertExpectations(t)
+		auditMock.AssertExpectations(t)
 	})
 
 	args := []struct {
@@ -81,7 +92,7 @@ func TestLogin(t *testing.T) {
 			repoMock := &mocks.UserRepositoryMock{}
 
 			// when
-			authSrv := &authService{jwtMock, clockMock, repoMock}
+			authSrv := &authService{jwtMock, clockMock, repoMock, &mocks.AuditServiceMock{}}
 			actual, error := authSrv.Login(ctx, arg.email, arg.authKey)
 
 			// then
@@ -94,16 +105,20 @@ func TestLogin(t *testing.T) {
 		// given
 		jwtMock := &mocks.JWTGeneratorMock{}
 		repoMock := &mocks.UserRepositoryMock{}
+		auditMock := &mocks.AuditServiceMock{}
 
 		repoMock.On("FindByEmail", ctx, email).Return(&models.User{}, nil)
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditLoginFailed, Outcome: models.AuditFailure, Email: email})
 
 		// when
-		authSrv := &authService{jwtMock, clockMock, repoMock}
+		authSrv := &authService{jwtMock, clockMock, repoMock, auditMock}
 		actual, error := authSrv.Login(ctx, email, authKey)
 
 		// then
 		assert.Equal(t, "", actual)
 		assert.Equal(t, "invalid credentials", error.Error())
+
+		auditMock.AssertExpectations(t)
 	})
 
 	t.Run("invalid authKey", func(t *testing.T) {
@@ -112,15 +127,22 @@ func TestLogin(t *testing.T) {
 		repoMock := &mocks.UserRepositoryMock{}
 
 		repoMock.On("FindByEmail", ctx, email).
-			Return(&models.User{Model: gorm.Model{ID: 1}, AuthKey: authKey}, nil)
+			Return(&models.User{Model: gorm.Model{ID: 1}, AuthKey: hashAuthKey(authKey)}, nil)
+
+		// the failure is recorded against the user, who can see it in their log
+		auditMock := &mocks.AuditServiceMock{}
+		auditMock.On("Record", ctx, models.AuditEvent{Action: models.AuditLoginFailed, Outcome: models.AuditFailure, UserID: 1, Email: email}).
+			Return(errors.New("error when recording"))
 
 		// when
-		authSrv := &authService{jwtMock, clockMock, repoMock}
+		authSrv := &authService{jwtMock, clockMock, repoMock, auditMock}
 		actual, error := authSrv.Login(ctx, email, "invalid-auth-key")
 
-		// then
+		// then: the login fails with invalid credentials even when recording fails
 		assert.Equal(t, "", actual)
 		assert.Equal(t, "invalid credentials", error.Error())
+
+		auditMock.AssertExpectations(t)
 	})
 
 	testCases := []struct {
@@ -149,12 +171,15 @@ func TestLogin(t *testing.T) {
 			repoMock := &mocks.UserRepositoryMock{}
 
 			repoMock.On("FindByEmail", ctx, email).
-				Return(&models.User{Model: gorm.Model{ID: 1}, AuthKey: authKey}, tc.findByEmailError)
+				Return(&models.User{Model: gorm.Model{ID: 1}, AuthKey: hashAuthKey(authKey)}, tc.findByEmailError)
+
+			jwtMock.On("Generate", uint(1), uint(0), mock.Anything).Return("", tc.generateError)
 
-			jwtMock.On("Generate", uint(1), mock.Anything).Return("", tc.generateError)
+			auditMock := &mocks.AuditServiceMock{}
+			auditMock.On("Record", ctx, mock.Anything).Maybe()
 
 			// when
-			authSrv := &authService{jwtMock, clockMock, repoMock}
+			authSrv := &authService{jwtMock, clockMock, repoMock, auditMock}
 			actual, error := authSrv.Login(ctx, email, authKey)
 
 			// then
@@ -163,4 +188,44 @@ func TestLogin(t *testing.T) {
 		})
 	}
 
+	t.Run("login not recorded", func(t *testing.T) {
+		// given
+		jwtMock := &mocks.JWTGeneratorMock{}
+		repoMock := &mocks.UserRepositoryMock{}
+		auditMock := &mocks.AuditServiceMock{}
+
+		repoMock.On("FindByEmail", ctx, email).
+			Return(&models.User{Model: gorm.Model{ID: 1}, AuthKey: hashAuthKey(authKey)}, nil)
+		auditMock.On("Record", ctx, mock.Anything).Return(errors.New("error when recording"))
+
+		// when
+		authSrv := &authService{jwtMock, clockMock, repoMock, auditMock}
+		actual, error := authSrv.Login(ctx, email, authKey)
+
+		// then: no token is issued unaudited
+		assert.Equal(t, "", actual)
+		assert.Equal(t, "error when recording", error.Error())
+
+		jwtMock.AssertNotCalled(t, "Generate", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSessionVersion(t *testing.T) {
+	// given
+	repoMock := &mocks.UserRepositoryMock{}
+	repoMock.On("FindByID", mock.Anything, uint(1)).Return(&models.User{Model: gorm.Model{ID: 1}, SessionVersion: 4}, nil)
+	repoMock.On("FindByID", mock.Anything, uint(2)).Return(&models.User{}, nil)
+
+	// when
+	version, error := SessionVersion(repoMock)(1)
+
+	// then
+	assert.Nil(t, error)
+	assert.Equal(t, uint(4), version)
+
+	// when: the user was deleted
+	_, error = SessionVersion(repoMock)(2)
+
+	// then
+	assert.Equal(t, cerrors.UnauthorizedError("user not found"), error)
 }
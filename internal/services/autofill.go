@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/urlmatch"
+)
+
+type IAutofillService interface {
+	// Match returns the items from the authenticated user matching the URL of a page, best first.
+	Match(ctx context.Context, pageURL string) ([]models.AutofillMatch, error)
+}
+
+type autofillService struct {
+	vaultRepository repositories.IVaultRepository
+	itemRepository  repositories.IItemRepository
+	matcher         *urlmatch.Matcher
+}
+
+func NewAutofillService(
+	vaultRepository repositories.IVaultRepository,
+	itemRepository repositories.IItemRepository,
+	matcher *urlmatch.Matcher,
+) *autofillService {
+	return &autofillService{vaultRepository, itemRepository, matcher}
+}
+
+func (a *autofillService) Match(ctx context.Context, pageURL string) ([]models.AutofillMatch, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return []models.AutofillMatch{}, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(pageURL) {
+		return []models.AutofillMatch{}, cerrors.BadRequestError("url is required")
+	}
+
+	page, err := urlmatch.ParsePage(pageURL)
+
+	if err != nil {
+		return []models.AutofillMatch{}, cerrors.BadRequestError("url must be an http or https url")
+	}
+
+	vaults, err := a.vaultRepository.FindByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find all vaults by userId: %v", err.Error())
+		return []models.AutofillMatch{}, err
+	}
+
+	if len(vaults) == 0 {
+		return []models.AutofillMatch{}, nil
+	}
+
+	vaultNames := make(map[uint]string, len(vaults))
+
+	for _, v := range vaults {
+		vaultNames[v.ID] = v.Name
+	}
+
+	items, err := a.itemRepository.FindByVaultIDs(ctx, utils.Map(vaults, func(v models.Vault) uint { return v.ID }))
+
+	if err != nil {
+		log.Printf("error while trying to find items by vaultIds: %v", err.Error())
+		return []models.AutofillMatch{}, err
+	}
+
+	matches := []models.AutofillMatch{}
+	qualities := map[uint]urlmatch.Quality{}
+
+	for _, item := range items {
+		quality := a.matcher.Match(page, item.Url, urlmatch.Strategy(item.UrlMatch))
+
+		if quality == urlmatch.NoMatch {
+			continue
+		}
+
+		qualities[item.ID] = quality
+		matches = append(matches, models.AutofillMatch{
+			ItemDetail: toItemListing(item),
+			VaultName:  vaultNames[item.VaultID],
+			Match:      quality.String(),
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		qi, qj := qualities[matches[i].ID], qualities[matches[j].ID]
+
+		if qi != qj {
+			return qi > qj
+		}
+
+		return matches[i].Name < matches[j].Name
+	})
+
+	return matches, nil
+}
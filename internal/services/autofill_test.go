@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/urlmatch"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNewAutofillService(t *testing.T) {
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	itemRepoMock := &mocks.ItemRepositoryMock{}
+	matcher := urlmatch.NewMatcher(nil)
+
+	autofillSvc := NewAutofillService(vaultRepoMock, itemRepoMock, matcher)
+
+	assert.Equal(t, vaultRepoMock, autofillSvc.vaultRepository)
+	assert.Equal(t, itemRepoMock, autofillSvc.itemRepository)
+	assert.Equal(t, matcher, autofillSvc.matcher)
+}
+
+func TestAutofillMatch(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+	matcher := urlmatch.NewMatcher(urlmatch.DefaultEquivalentDomains)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return([]models.Vault{
+			{Model: gorm.Model{ID: 1}, Name: "Work", UserID: userID},
+			{Model: gorm.Model{ID: 2}, Name: "Personal", UserID: userID},
+		}, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1, 2}).Return([]models.Item{
+			{Model: gorm.Model{ID: 5}, Name: "Google", Url: "https://accounts.google.com", VaultID: 2},
+			{Model: gorm.Model{ID: 6}, Name: "YouTube", Url: "youtube.com", Password: "secret", TOTP: "otpauth://totp/x?secret=JBSWY3DPEHPK3PXP", VaultID: 2},
+			{Model: gorm.Model{ID: 7}, Name: "YouTube Studio", Url: "https://studio.youtube.com/", VaultID: 1},
+			{Model: gorm.Model{ID: 8}, Name: "YouTube login", Url: "https://www.youtube.com/login", UrlMatch: "exact", VaultID: 1},
+			{Model: gorm.Model{ID: 9}, Name: "Never", Url: "youtube.com", UrlMatch: "never", VaultID: 1},
+			{Model: gorm.Model{ID: 10}, Name: "GitHub", Url: "github.com", VaultID: 1},
+		}, nil)
+
+		// when
+		autofillSvc := &autofillService{vaultRepoMock, itemRepoMock, matcher}
+		actual, error := autofillSvc.Match(ctx, "https://www.youtube.com/watch?v=1#t=10")
+
+		// then: listings never hold the totp secret
+		assert.Nil(t, error)
+		assert.Equal(t, []models.AutofillMatch{
+			{ItemDetail: models.ItemDetail{ID: 6, VaultID: 2, Name: "YouTube", Url: "youtube.com", Password: "secret", HasTOTP: true}, VaultName: "Personal", Match: "domain"},
+			{ItemDetail: models.ItemDetail{ID: 7, VaultID: 1, Name: "YouTube Studio", Url: "https://studio.youtube.com/"}, VaultName: "Work", Match: "domain"},
+			{ItemDetail: models.ItemDetail{ID: 5, VaultID: 2, Name: "Google", Url: "https://accounts.google.com"}, VaultName: "Personal", Match: "equivalent"},
+		}, actual)
+	})
+
+	testCases := []struct {
+		name string
+		ctx  context.Context
+		url  string
+		err  string
+	}{
+		{"user not authenticated", context.TODO(), "https://github.com", "user is not authenticated"},
+		{"url is required", ctx, " ", "url is required"},
+		{"invalid url", ctx, "file:///etc/passwd", "url must be an http or https url"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			autofillSvc := &autofillService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, matcher}
+			actual, error := autofillSvc.Match(tc.ctx, tc.url)
+
+			// then
+			assert.Equal(t, []models.AutofillMatch{}, actual)
+			assert.Equal(t, tc.err, error.Error())
+		})
+	}
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return([]models.Vault{}, errors.New("error when finding vaults"))
+
+		// when
+		autofillSvc := &autofillService{vaultRepoMock, &mocks.ItemRepositoryMock{}, matcher}
+		_, error := autofillSvc.Match(ctx, "https://github.com")
+
+		// then
+		assert.Equal(t, "error when finding vaults", error.Error())
+	})
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/edgardjr92/gopass/internal/backup"
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/edgardjr92/gopass/pkg/seal"
+)
+
+// minPassphraseLength is the minimum length of backup passphrases.
+const minPassphraseLength = 12
+
+type IBackupService interface {
+	// Export exports all vaults and items from the authenticated user to a backup encrypted with passphrase.
+	Export(ctx context.Context, passphrase string) ([]byte, error)
+	// Restore restores an encrypted backup into the account of the authenticated user.
+	// Vaults and items get new IDs. Vaults whose name is already taken are handled according to onConflict.
+	Restore(ctx context.Context, data []byte, passphrase string, onConflict models.ConflictStrategy) (*models.RestoreReport, error)
+}
+
+type backupService struct {
+	vaultRepository    repositories.IVaultRepository
+	itemRepository     repositories.IItemRepository
+	folderRepository   repositories.IFolderRepository
+	tagRepository      repositories.ITagRepository
+	favoriteRepository repositories.IFavoriteRepository
+	userRepository     repositories.IUserRepository
+	transactor         repositories.ITransactor
+	clock              clock.Clock
+	kdfParams          seal.KDFParams
+}
+
+func NewBackupService(
+	vaultRepository repositories.IVaultRepository,
+	itemRepository repositories.IItemRepository,
+	folderRepository repositories.IFolderRepository,
+	tagRepository repositories.ITagRepository,
+	favoriteRepository repositories.IFavoriteRepository,
+	userRepository repositories.IUserRepository,
+	transactor repositories.ITransactor,
+	clock clock.Clock,
+) *backupService {
+	return &backupService{
+		vaultRepository, itemRepository, folderRepository, tagRepository, favoriteRepository,
+		userRepository, transactor, clock, seal.DefaultKDFParams,
+	}
+}
+
+func (b *backupService) Export(ctx context.Context, passphrase string) ([]byte, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if len([]rune(passphrase)) < minPassphraseLength {
+		return nil, cerrors.BadRequestError(fmt.Sprintf("passphrase must have at least %d characters", minPassphraseLength))
+	}
+
+	vaults, err := b.vaultRepository.FindByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find all vaults by userId: %v", err.Error())
+		return nil, err
+	}
+
+	backupVaults := make([]backup.Vault, 0, len(vaults))
+	indexes := map[uint]int{}
+	vaultIDs := make([]uint, 0, len(vaults))
+
+	for _, vault := range vaults {
+		folders, err := b.folderRepository.FindByVaultID(ctx, vault.ID)
+
+		if err != nil {
+			log.Printf("error while trying to find folders by vaultId: %v", err.Error())
+			return nil, err
+		}
+
+		indexes[vault.ID] = len(backupVaults)
+		vaultIDs = append(vaultIDs, vault.ID)
+		backupVaults = append(backupVaults, backup.Vault{
+			ID:        vault.ID,
+			Name:      vault.Name,
+			CreatedAt: vault.CreatedAt.UTC(),
+			UpdatedAt: vault.UpdatedAt.UTC(),
+			Folders:   utils.Map(folders, toBackupFolder),
+			Items:     []backup.Item{},
+		})
+	}
+
+	if len(vaultIDs) > 0 {
+		favorites, err := favoriteItemIDs(ctx, b.favoriteRepository, userID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items, err := b.itemRepository.FindByVaultIDs(ctx, vaultIDs)
+
+		if err != nil {
+			log.Printf("error while trying to find items by vaultIds: %v", err.Error())
+			return nil, err
+		}
+
+		for _, item := range items {
+			i, ok := indexes[item.VaultID]
+
+			if !ok {
+				continue
+			}
+
+			backupVaults[i].Items = append(backupVaults[i].Items, backup.Item{
+				ID:        item.ID,
+				Name:      item.Name,
+				Url:       item.Url,
+				UrlMatch:  item.UrlMatch,
+				Username:  item.Username,
+				Password:  item.Password,
+				Notes:     item.Notes,
+				SSHKey:    item.SSHKey,
+				TOTP:      item.TOTP,
+				FolderID:  item.FolderID,
+				Tags:      tagNames(item.Tags),
+				Favorite:  favorites[item.ID],
+				CreatedAt: item.CreatedAt.UTC(),
+				UpdatedAt: item.UpdatedAt.UTC(),
+			})
+		}
+	}
+
+	data, err := backup.Encrypt(backupVaults, b.clock.Now().UTC(), passphrase, b.kdfParams)
+
+	if err != nil {
+		log.Printf("error while trying to encrypt backup: %v", err.Error())
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (b *backupService) Restore(ctx context.Context, data []byte, passphrase string, onConflict models.ConflictStrategy) (*models.RestoreReport, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(passphrase) {
+		return nil, cerrors.BadRequestError("passphrase is required")
+	}
+
+	if onConflict == "" {
+		onConflict = models.ConflictRename
+	}
+
+	if onConflict != models.ConflictRename && onConflict != models.ConflictMerge && onConflict != models.ConflictSkip {
+		return nil, cerrors.BadRequestError("onConflict must be one of rename, merge or skip")
+	}
+
+	restored, err := backup.Decrypt(data, passphrase)
+
+	if err != nil {
+		if errors.Is(err, backup.ErrInvalidFormat) || errors.Is(err, backup.ErrUnsupportedVersion) ||
+			errors.Is(err, backup.ErrInvalidPassphrase) || errors.Is(err, backup.ErrCorrupted) ||
+			errors.Is(err, backup.ErrKDFLimits) {
+			return nil, cerrors.UnprocessableError(err.Error())
+		}
+		return nil, err
+	}
+
+	var report *models.RestoreReport
+
+	err = withRevision(ctx, b.transactor, b.userRepository, userID, func(ctx context.Context, revision uint64) error {
+		report = &models.RestoreReport{Vaults: []models.RestoredVault{}, ItemIDs: map[uint]uint{}}
+
+		for _, v := range restored.Vaults {
+			result := models.RestoredVault{OldID: v.ID, Name: v.Name, OriginalName: v.Name, Status: "created"}
+
+			existing, err := b.vaultRepository.FindByNameAndUserID(ctx, v.Name, userID)
+
+			if err != nil {
+				log.Printf("error while trying to find a vault by name,userId: %v", err.Error())
+				return err
+			}
+
+			if existing.ID != 0 {
+				switch onConflict {
+				case models.ConflictSkip:
+					result.Status = "skipped"
+					report.Vaults = append(report.Vaults, result)
+					continue
+				case models.ConflictMerge:
+					result.NewID = existing.ID
+					result.Status = "merged"
+				case models.ConflictRename:
+					name, err := b.availableName(ctx, v.Name, userID)
+
+					if err != nil {
+						return err
+					}
+
+					result.Name = name
+					result.Status = "renamed"
+				}
+			}
+
+			if result.NewID == 0 {
+				vault := models.Vault{Name: result.Name, UserID: userID, CreatedRevision: revision, UpdatedRevision: revision}
+
+				if err := b.vaultRepository.Save(ctx, &vault); err != nil {
+					log.Printf("error while trying to save vault: %v", err.Error())
+					return err
+				}
+
+				result.NewID = vault.ID
+			}
+
+			folderIDs, err := b.restoreFolders(ctx, result.NewID, v.Folders, result.Status == "merged")
+
+			if err != nil {
+				return err
+			}
+
+			// Items of a merged vault already in it are skipped, as imports skip them.
+			seen := map[string]bool{}
+
+			if result.Status == "merged" {
+				items, err := b.itemRepository.FindByVaultIDs(ctx, []uint{result.NewID})
+
+				if err != nil {
+					log.Printf("error while trying to find items by vaultIds: %v", err.Error())
+					return err
+				}
+
+				for _, item := range items {
+					seen[duplicateKey(result.Name, item)] = true
+				}
+			}
+
+			for _, i := range v.Items {
+				item := models.Item{
+					Name:            i.Name,
+					Url:             i.Url,
+					UrlMatch:        i.UrlMatch,
+					Username:        i.Username,
+					Password:        i.Password,
+					Notes:           i.Notes,
+					SSHKey:          i.SSHKey,
+					TOTP:            i.TOTP,
+					VaultID:         result.NewID,
+					CreatedRevision: revision,
+					UpdatedRevision: revision,
+				}
+
+				if seen[duplicateKey(result.Name, item)] {
+					result.Skipped++
+					continue
+				}
+
+				if item.Tags, err = resolveTags(ctx, b.tagRepository, userID, i.Tags); err != nil {
+					return err
+				}
+
+				if i.FolderID != nil {
+					if id, ok := folderIDs[*i.FolderID]; ok {
+						item.FolderID = &id
+					}
+				}
+
+				if err := b.itemRepository.Save(ctx, &item); err != nil {
+					log.Printf("error while trying to save item: %v", err.Error())
+					return err
+				}
+
+				if i.Favorite {
+					if err := b.favoriteRepository.Save(ctx, &models.Favorite{UserID: userID, ItemID: item.ID}); err != nil {
+						log.Printf("error while trying to save favorite: %v", err.Error())
+						return err
+					}
+				}
+
+				report.ItemIDs[i.ID] = item.ID
+				result.Items++
+			}
+
+			report.Vaults = append(report.Vaults, result)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// restoreFolders creates the folders of a backup vault in the vault with the given ID and
+// returns their new IDs by their IDs in the backup. Folders of a merged vault reuse the
+// existing folder with the same name and parent. Folders whose parent isn't in the backup,
+// or that are part of a cycle, are restored at the root of the vault.
+func (b *backupService) restoreFolders(ctx context.Context, vaultID uint, folders []backup.Folder, merged bool) (map[uint]uint, error) {
+	ids := map[uint]uint{}
+
+	if len(folders) == 0 {
+		return ids, nil
+	}
+
+	var existing []models.Folder
+
+	if merged {
+		var err error
+
+		if existing, err = b.folderRepository.FindByVaultID(ctx, vaultID); err != nil {
+			log.Printf("error while trying to find folders by vaultId: %v", err.Error())
+			return nil, err
+		}
+	}
+
+	inBackup := map[uint]bool{}
+
+	for _, f := range folders {
+		inBackup[f.ID] = true
+	}
+
+	// Folders are restored once their parent is, which takes as many passes as the
+	// depth of the tree. When a pass restores nothing, the rest are in cycles.
+	for pending := folders; len(pending) > 0; {
+		var next []backup.Folder
+
+		for _, f := range pending {
+			var parentID *uint
+
+			if f.ParentID != nil && inBackup[*f.ParentID] {
+				id, ok := ids[*f.ParentID]
+
+				if !ok {
+					next = append(next, f)
+					continue
+				}
+
+				parentID = &id
+			}
+
+			folder := findFolder(existing, f.Name, parentID)
+
+			if folder == nil {
+				folder = &models.Folder{Name: f.Name, VaultID: vaultID, ParentID: parentID}
+
+				if err := b.folderRepository.Save(ctx, folder); err != nil {
+					log.Printf("error while trying to save folder: %v", err.Error())
+					return nil, err
+				}
+			}
+
+			ids[f.ID] = folder.ID
+		}
+
+		if len(next) == len(pending) {
+			next[0].ParentID = nil
+		}
+
+		pending = next
+	}
+
+	return ids, nil
+}
+
+// findFolder returns the folder with the given name and parent, or nil.
+func findFolder(folders []models.Folder, name string, parentID *uint) *models.Folder {
+	for i := range folders {
+		f := &folders[i]
+
+		if f.Name == name && ((f.ParentID == nil && parentID == nil) || (f.ParentID != nil && parentID != nil && *f.ParentID == *parentID)) {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func toBackupFolder(folder models.Folder) backup.Folder {
+	return backup.Folder{ID: folder.ID, Name: folder.Name, ParentID: folder.ParentID}
+}
+
+// availableName returns the first of "name (restored)", "name (restored 2)", ...
+// that is not the name of a vault from the user.
+func (b *backupService) availableName(ctx context.Context, name string, userID uint) (string, error) {
+	for n := 1; ; n++ {
+		candidate := name + " (restored)"
+		if n > 1 {
+			candidate = fmt.Sprintf("%s (restored %d)", name, n)
+		}
+
+		vault, err := b.vaultRepository.FindByNameAndUserID(ctx, candidate, userID)
+
+		if err != nil {
+			log.Printf("error while trying to find a vault by name,userId: %v", err.Error())
+			return "", err
+		}
+
+		if vault.ID == 0 {
+			return candidate, nil
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/backup"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/edgardjr92/gopass/pkg/seal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var testKDFParams = seal.KDFParams{Time: 1, Memory: 64, Threads: 1}
+
+const testPassphrase = "correct horse battery staple"
+
+func TestNewBackupService(t *testing.T) {
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	itemRepoMock := &mocks.ItemRepositoryMock{}
+	folderRepoMock := &mocks.FolderRepositoryMock{}
+	tagRepoMock := &mocks.TagRepositoryMock{}
+	favoriteRepoMock := &mocks.FavoriteRepositoryMock{}
+	userRepoMock := &mocks.UserRepositoryMock{}
+	transactorMock := &mocks.TransactorMock{}
+	clockMock := clock.Clock{}
+
+	backupSvc := NewBackupService(vaultRepoMock, itemRepoMock, folderRepoMock, tagRepoMock, favoriteRepoMock, userRepoMock, transactorMock, clockMock)
+
+	assert.Equal(t, vaultRepoMock, backupSvc.vaultRepository)
+	assert.Equal(t, itemRepoMock, backupSvc.itemRepository)
+	assert.Equal(t, folderRepoMock, backupSvc.folderRepository)
+	assert.Equal(t, tagRepoMock, backupSvc.tagRepository)
+	assert.Equal(t, favoriteRepoMock, backupSvc.favoriteRepository)
+	assert.Equal(t, userRepoMock, backupSvc.userRepository)
+	assert.Equal(t, transactorMock, backupSvc.transactor)
+	assert.Equal(t, clockMock, backupSvc.clock)
+	assert.Equal(t, seal.DefaultKDFParams, backupSvc.kdfParams)
+}
+
+func TestExportBackup(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+	now := time.Date(2023, 5, 6, 10, 0, 0, 0, time.UTC)
+	clockMock := clock.Clock{NowFn: func() time.Time { return now }}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		folderRepoMock := &mocks.FolderRepositoryMock{}
+		favoriteRepoMock := &mocks.FavoriteRepositoryMock{}
+		folderID := uint(3)
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return([]models.Vault{
+			{Model: gorm.Model{ID: 1, CreatedAt: now, UpdatedAt: now}, Name: "Work", UserID: userID},
+			{Model: gorm.Model{ID: 2, CreatedAt: now, UpdatedAt: now}, Name: "Empty", UserID: userID},
+		}, nil)
+		folderRepoMock.On("FindByVaultID", ctx, uint(1)).Return([]models.Folder{{Model: gorm.Model{ID: folderID}, Name: "Dev", VaultID: 1}}, nil)
+		folderRepoMock.On("FindByVaultID", ctx, uint(2)).Return([]models.Folder{}, nil)
+		favoriteRepoMock.On("FindItemIDsByUserID", ctx, userID).Return([]uint{5}, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1, 2}).Return([]models.Item{
+			{
+				Model: gorm.Model{ID: 5, CreatedAt: now, UpdatedAt: now}, Name: "GitHub", UrlMatch: "exact", Username: "octocat", Password: "gh-pass", VaultID: 1,
+				FolderID: &folderID, Tags: []models.Tag{{Name: "dev"}},
+			},
+		}, nil)
+
+		// when
+		backupSvc := &backupService{vaultRepoMock, itemRepoMock, folderRepoMock, &mocks.TagRepositoryMock{}, favoriteRepoMock, &mocks.UserRepositoryMock{}, &mocks.TransactorMock{}, clockMock, testKDFParams}
+		data, error := backupSvc.Export(ctx, testPassphrase)
+
+		// then
+		assert.Nil(t, error)
+
+		restored, err := backup.Decrypt(data, testPassphrase)
+		assert.Nil(t, err)
+		assert.Equal(t, now, restored.Manifest.CreatedAt)
+		assert.Equal(t, []backup.Vault{
+			{
+				ID: 1, Name: "Work", CreatedAt: now, UpdatedAt: now,
+				Folders: []backup.Folder{{ID: folderID, Name: "Dev"}},
+				Items: []backup.Item{{
+					ID: 5, Name: "GitHub", UrlMatch: "exact", Username: "octocat", Password: "gh-pass",
+					FolderID: &folderID, Tags: []string{"dev"}, Favorite: true, CreatedAt: now, UpdatedAt: now,
+				}},
+			},
+			{ID: 2, Name: "Empty", CreatedAt: now, UpdatedAt: now, Items: []backup.Item{}},
+		}, restored.Vaults)
+
+		vaultRepoMock.AssertExpectations(t)
+		itemRepoMock.AssertExpectations(t)
+	})
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// when
+		backupSvc := &backupService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, &mocks.FolderRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.FavoriteRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TransactorMock{}, clockMock, testKDFParams}
+		data, error := backupSvc.Export(context.TODO(), testPassphrase)
+
+		// then
+		assert.Nil(t, data)
+		assert.Equal(t, "user is not authenticated", error.Error())
+	})
+
+	t.Run("passphrase too short", func(t *testing.T) {
+		// when
+		backupSvc := &backupService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, &mocks.FolderRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.FavoriteRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TransactorMock{}, clockMock, testKDFParams}
+		data, error := backupSvc.Export(ctx, "short")
+
+		// then
+		assert.Nil(t, data)
+		assert.Equal(t, "passphrase must have at least 12 characters", error.Error())
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return([]models.Vault{}, errors.New("error when finding vaults"))
+
+		// when
+		backupSvc := &backupService{vaultRepoMock, &mocks.ItemRepositoryMock{}, &mocks.FolderRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.FavoriteRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TransactorMock{}, clockMock, testKDFParams}
+		data, error := backupSvc.Export(ctx, testPassphrase)
+
+		// then
+		assert.Nil(t, data)
+		assert.Equal(t, "error when finding vaults", error.Error())
+	})
+}
+
+func TestRestoreBackup(t *testing.T) {
+	userID := uint(20)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	data, err := backup.Encrypt([]backup.Vault{
+		{ID: 1, Name: "Work", Items: []backup.Item{{ID: 5, Name: "GitHub", Password: "gh-pass"}}},
+		{ID: 2, Name: "Personal", Items: []backup.Item{{ID: 6, Name: "Bank", Password: "bank-pass"}, {ID: 7, Name: "Mail", Password: "mail-pass"}}},
+	}, time.Now().UTC(), testPassphrase, testKDFParams)
+	assert.Nil(t, err)
+
+	newMocks := func() (*mocks.VaultRepositoryMock, *mocks.ItemRepositoryMock, *mocks.UserRepositoryMock, *mocks.TransactorMock) {
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		userRepoMock := &mocks.UserRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		transactorMock.On("WithinTransaction", ctx)
+		userRepoMock.On("NextRevision", ctx, userID).Return(uint64(3), nil)
+		vaultRepoMock.On("FindByNameAndUserID", ctx, "Work", userID).Return(&models.Vault{}, nil)
+		vaultRepoMock.On("FindByNameAndUserID", ctx, "Personal", userID).Return(&models.Vault{Model: gorm.Model{ID: 30}, Name: "Personal"}, nil)
+
+		nextItemID := uint(100)
+		itemRepoMock.On("Save", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Item).ID = nextItemID
+			nextItemID++
+		})
+
+		return vaultRepoMock, itemRepoMock, userRepoMock, transactorMock
+	}
+
+	t.Run("rename conflicts", func(t *testing.T) {
+		// given
+		vaultRepoMock, itemRepoMock, userRepoMock, transactorMock := newMocks()
+
+		vaultRepoMock.On("FindByNameAndUserID", ctx, "Personal (restored)", userID).Return(&models.Vault{Model: gorm.Model{ID: 31}}, nil)
+		vaultRepoMock.On("FindByNameAndUserID", ctx, "Personal (restored 2)", userID).Return(&models.Vault{}, nil)
+		vaultRepoMock.On("Save", ctx, &models.Vault{Name: "Work", UserID: userID, CreatedRevision: 3, UpdatedRevision: 3}).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Vault).ID = 40
+		})
+		vaultRepoMock.On("Save", ctx, &models.Vault{Name: "Personal (restored 2)", UserID: userID, CreatedRevision: 3, UpdatedRevision: 3}).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Vault).ID = 41
+		})
+
+		// when
+		backupSvc := &backupService{vaultRepoMock, itemRepoMock, &mocks.FolderRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.FavoriteRepositoryMock{}, userRepoMock, transactorMock, clock.Clock{}, testKDFParams}
+		actual, error := backupSvc.Restore(ctx, data, testPassphrase, "")
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, &models.RestoreReport{
+			Vaults: []models.RestoredVault{
+				{OldID: 1, NewID: 40, Name: "Work", OriginalName: "Work", Items: 1, Status: "created"},
+				{OldID: 2, NewID: 41, Name: "Personal (restored 2)", OriginalName: "Personal", Items: 2, Status: "renamed"},
+			},
+			ItemIDs: map[uint]uint{5: 100, 6: 101, 7: 102},
+		}, actual)
+
+		itemRepoMock.AssertCalled(t, "Save", ctx, &models.Item{Model: gorm.Model{ID: 101}, Name: "Bank", Password: "bank-pass", VaultID: 41, CreatedRevision: 3, UpdatedRevision: 3})
+		vaultRepoMock.AssertExpectations(t)
+		transactorMock.AssertExpectations(t)
+	})
+
+	t.Run("merge conflicts", func(t *testing.T) {
+		// given
+		vaultRepoMock, itemRepoMock, userRepoMock, transactorMock := newMocks()
+
+		vaultRepoMock.On("Save", ctx, &models.Vault{Name: "Work", UserID: userID, CreatedRevision: 3, UpdatedRevision: 3}).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Vault).ID = 40
+		})
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{30}).Return([]models.Item{{Model: gorm.Model{ID: 80}, Name: " bank", Password: "old-pass", VaultID: 30}}, nil)
+
+		// when
+		backupSvc := &backupService{vaultRepoMock, itemRepoMock, &mocks.FolderRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.FavoriteRepositoryMock{}, userRepoMock, transactorMock, clock.Clock{}, testKDFParams}
+		actual, error := backupSvc.Restore(ctx, data, testPassphrase, models.ConflictMerge)
+
+		// then: the items already in the vault are skipped
+		assert.Nil(t, error)
+		assert.Equal(t, models.RestoredVault{OldID: 2, NewID: 30, Name: "Personal", OriginalName: "Personal", Items: 1, Skipped: 1, Status: "merged"}, actual.Vaults[1])
+		assert.Equal(t, map[uint]uint{5: 100, 7: 101}, actual.ItemIDs)
+		vaultRepoMock.AssertNumberOfCalls(t, "Save", 1)
+		itemRepoMock.AssertNumberOfCalls(t, "Save", 2)
+		itemRepoMock.AssertNotCalled(t, "FindByVaultIDs", ctx, []uint{40})
+	})
+
+	t.Run("skip conflicts", func(t *testing.T) {
+		// given
+		vaultRepoMock, itemRepoMock, userRepoMock, transactorMock := newMocks()
+
+		vaultRepoMock.On("Save", ctx, &models.Vault{Name: "Work", UserID: userID, CreatedRevision: 3, UpdatedRevision: 3}).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Vault).ID = 40
+		})
+
+		// when
+		backupSvc := &backupService{vaultRepoMock, itemRepoMock, &mocks.FolderRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.FavoriteRepositoryMock{}, userRepoMock, transactorMock, clock.Clock{}, testKDFParams}
+		actual, error := backupSvc.Restore(ctx, data, testPassphrase, models.ConflictSkip)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, models.RestoredVault{OldID: 2, Name: "Personal", OriginalName: "Personal", Status: "skipped"}, actual.Vaults[1])
+		assert.Equal(t, map[uint]uint{5: 100}, actual.ItemIDs)
+	})
+
+	t.Run("restores folders, tags and favorites", func(t *testing.T) {
+		// given
+		cloud, aws, loop := uint(1), uint(2), uint(3)
+		organized, err := backup.Encrypt([]backup.Vault{{
+			ID: 1, Name: "Personal",
+			Folders: []backup.Folder{
+				{ID: aws, Name: "AWS", ParentID: &cloud},
+				{ID: cloud, Name: "Cloud"},
+				{ID: loop, Name: "Loop", ParentID: &loop},
+			},
+			Items: []backup.Item{
+				{ID: 5, Name: "Console", UrlMatch: "host", Password: "aws-pass", FolderID: &aws, Tags: []string{"ops", "prod"}, Favorite: true},
+				{ID: 6, Name: "Wiki", Password: "wiki-pass", FolderID: &loop},
+			},
+		}}, time.Now().UTC(), testPassphrase, testKDFParams)
+		assert.Nil(t, err)
+
+		vaultRepoMock, itemRepoMock, userRepoMock, transactorMock := newMocks()
+		folderRepoMock := &mocks.FolderRepositoryMock{}
+		tagRepoMock := &mocks.TagRepositoryMock{}
+		favoriteRepoMock := &mocks.FavoriteRepositoryMock{}
+		existingCloud := uint(50)
+
+		folderRepoMock.On("FindByVaultID", ctx, uint(30)).Return([]models.Folder{{Model: gorm.Model{ID: existingCloud}, Name: "Cloud", VaultID: 30}}, nil)
+		folderRepoMock.On("Save", ctx, &models.Folder{Name: "AWS", VaultID: 30, ParentID: &existingCloud}).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Folder).ID = 51
+		})
+		folderRepoMock.On("Save", ctx, &models.Folder{Name: "Loop", VaultID: 30}).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Folder).ID = 52
+		})
+		tagRepoMock.On("FindByNameAndUserID", ctx, "ops", userID).Return(&models.Tag{Model: gorm.Model{ID: 60}, Name: "ops", UserID: userID}, nil)
+		tagRepoMock.On("FindByNameAndUserID", ctx, "prod", userID).Return(&models.Tag{}, nil)
+		tagRepoMock.On("Save", ctx, &models.Tag{Name: "prod", UserID: userID}).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Tag).ID = 61
+		})
+		favoriteRepoMock.On("Save", ctx, &models.Favorite{UserID: userID, ItemID: 100})
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{30}).Return([]models.Item{}, nil)
+
+		// when
+		backupSvc := &backupService{vaultRepoMock, itemRepoMock, folderRepoMock, tagRepoMock, favoriteRepoMock, userRepoMock, transactorMock, clock.Clock{}, testKDFParams}
+		actual, error := backupSvc.Restore(ctx, organized, testPassphrase, models.ConflictMerge)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, map[uint]uint{5: 100, 6: 101}, actual.ItemIDs)
+
+		awsID, loopID := uint(51), uint(52)
+		itemRepoMock.AssertCalled(t, "Save", ctx, &models.Item{
+			Model: gorm.Model{ID: 100}, Name: "Console", UrlMatch: "host", Password: "aws-pass", VaultID: 30, FolderID: &awsID,
+			Tags:            []models.Tag{{Model: gorm.Model{ID: 60}, Name: "ops", UserID: userID}, {Model: gorm.Model{ID: 61}, Name: "prod", UserID: userID}},
+			CreatedRevision: 3, UpdatedRevision: 3,
+		})
+		itemRepoMock.AssertCalled(t, "Save", ctx, &models.Item{
+			Model: gorm.Model{ID: 101}, Name: "Wiki", Password: "wiki-pass", VaultID: 30, FolderID: &loopID, CreatedRevision: 3, UpdatedRevision: 3,
+		})
+		folderRepoMock.AssertExpectations(t)
+		tagRepoMock.AssertExpectations(t)
+		favoriteRepoMock.AssertExpectations(t)
+	})
+
+	testCases := []struct {
+		name       string
+		ctx        context.Context
+		data       []byte
+		passphrase string
+		onConflict models.ConflictStrategy
+		err        string
+	}{
+		{"user not authenticated", context.TODO(), data, testPassphrase, "", "user is not authenticated"},
+		{"passphrase is required", ctx, data, " ", "", "passphrase is required"},
+		{"invalid conflict strategy", ctx, data, testPassphrase, "overwrite", "onConflict must be one of rename, merge or skip"},
+		{"wrong passphrase", ctx, data, "wrong passphrase", "", "invalid passphrase or corrupted backup"},
+		{"not a backup", ctx, []byte("{}"), testPassphrase, "", "not a gopass backup"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			backupSvc := &backupService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, &mocks.FolderRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.FavoriteRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TransactorMock{}, clock.Clock{}, testKDFParams}
+			actual, error := backupSvc.Restore(tc.ctx, tc.data, tc.passphrase, tc.onConflict)
+
+			// then
+			assert.Nil(t, actual)
+			assert.Equal(t, tc.err, error.Error())
+		})
+	}
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		userRepoMock := &mocks.UserRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		transactorMock.On("WithinTransaction", ctx)
+		userRepoMock.On("NextRevision", ctx, userID).Return(uint64(3), nil)
+		vaultRepoMock.On("FindByNameAndUserID", ctx, "Work", userID).Return(&models.Vault{}, nil)
+		vaultRepoMock.On("Save", ctx, mock.Anything).Return(errors.New("error when saving vault"))
+
+		// when
+		backupSvc := &backupService{vaultRepoMock, &mocks.ItemRepositoryMock{}, &mocks.FolderRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.FavoriteRepositoryMock{}, userRepoMock, transactorMock, clock.Clock{}, testKDFParams}
+		actual, error := backupSvc.Restore(ctx, data, testPassphrase, "")
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "error when saving vault", error.Error())
+	})
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/edgardjr92/gopass/pkg/breach"
+)
+
+type IBreachChecker interface {
+	// Check checks a password against the local breach corpus.
+	// It returns how many times the password was seen in known breaches, zero if it was never seen.
+	Check(ctx context.Context, password string) (uint32, error)
+}
+
+type breachChecker struct {
+	index *breach.Index
+}
+
+func NewBreachChecker(index *breach.Index) *breachChecker {
+	return &breachChecker{index}
+}
+
+func (b *breachChecker) Check(ctx context.Context, password string) (uint32, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if password == "" {
+		return 0, nil
+	}
+
+	count, err := b.index.Count(password)
+
+	if err != nil {
+		log.Printf("error while trying to look up password in breach index: %v", err.Error())
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/pkg/breach"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBreachIndex(t *testing.T) *breach.Index {
+	// SHA-1 of "password" and "123456"
+	corpus := "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\n" +
+		"7C4A8D09CA3762AF61E59520943DC26494F8941B:37359195\n"
+
+	path := filepath.Join(t.TempDir(), "pwned.idx")
+	assert.Nil(t, breach.BuildFile(path, strings.NewReader(corpus)))
+
+	index, err := breach.Open(path)
+	assert.Nil(t, err)
+	t.Cleanup(func() { index.Close() })
+
+	return index
+}
+
+func TestNewBreachChecker(t *testing.T) {
+	index := newBreachIndex(t)
+
+	checker := NewBreachChecker(index)
+
+	assert.Equal(t, index, checker.index)
+}
+
+func TestCheckBreach(t *testing.T) {
+	ctx := context.TODO()
+	index := newBreachIndex(t)
+
+	testCases := []struct {
+		name     string
+		password string
+		expected uint32
+	}{
+		{"breached", "password", 9545824},
+		{"breached too", "123456", 37359195},
+		{"not breached", "n0t-in-the-c0rpus", 0},
+		{"empty", "", 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			checker := &breachChecker{index: index}
+			actual, error := checker.Check(ctx, tc.password)
+
+			// then
+			assert.Equal(t, tc.expected, actual)
+			assert.Nil(t, error)
+		})
+	}
+
+	t.Run("context canceled", func(t *testing.T) {
+		// given
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		// when
+		checker := &breachChecker{index: index}
+		actual, error := checker.Check(ctx, "password")
+
+		// then
+		assert.Equal(t, uint32(0), actual)
+		assert.Equal(t, context.Canceled, error)
+	})
+}
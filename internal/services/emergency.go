@@ -0,0 +1,665 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/edgardjr92/gopass/pkg/keywrap"
+	"github.com/edgardjr92/gopass/pkg/notify"
+)
+
+const (
+	// emergencyDefaultWaitDays is the wait of the emergency contacts invited
+	// without one, and emergencyMaxWaitDays the longest one.
+	emergencyDefaultWaitDays = 7
+	emergencyMaxWaitDays     = 90
+	// emergencyCheckInterval is the time between two checks for the requests
+	// whose wait is over.
+	emergencyCheckInterval = time.Minute
+)
+
+type IEmergencyService interface {
+	// Invite makes a user an emergency contact of the authenticated user.
+	Invite(ctx context.Context, input models.EmergencyContactInput) (*models.EmergencyContactDetail, error)
+	// Contacts returns the emergency contacts of the authenticated user.
+	Contacts(ctx context.Context) ([]models.EmergencyContactDetail, error)
+	// Grantors returns the emergency contacts the authenticated user is.
+	Grantors(ctx context.Context) ([]models.EmergencyContactDetail, error)
+	// Accept accepts an invitation to be an emergency contact, with the X25519
+	// public key the key of the grantor is to be wrapped to.
+	Accept(ctx context.Context, id uint, publicKey []byte) error
+	// Confirm stores the key of the authenticated user, wrapped by their
+	// client to the public key of an emergency contact who accepted.
+	Confirm(ctx context.Context, id uint, wrappedKey []byte) error
+	// Request requests emergency access to the account of a grantor. Access is
+	// granted after the wait of the contact unless the grantor rejects it.
+	Request(ctx context.Context, id uint) error
+	// Approve grants a requested emergency access without waiting.
+	Approve(ctx context.Context, id uint) error
+	// Reject rejects a requested emergency access, or revokes a granted one.
+	Reject(ctx context.Context, id uint) error
+	// Delete deletes an emergency contact, for the grantor and the grantee alike.
+	Delete(ctx context.Context, id uint) error
+	// Access returns the wrapped key and the vaults of a grantor to their
+	// emergency contact, once access is granted.
+	Access(ctx context.Context, id uint) (*models.EmergencyAccess, error)
+	// Takeover replaces the auth key of a grantor with one derived from a new
+	// master password, and their private key with one sealed under it, once
+	// takeover access is granted. The emergency contact is closed then.
+	Takeover(ctx context.Context, id uint, input models.EmergencyTakeoverInput) error
+}
+
+type emergencyService struct {
+	repository     repositories.IEmergencyContactRepository
+	userRepository repositories.IUserRepository
+	syncService    ISyncService
+	transactor     repositories.ITransactor
+	notifier       notify.Notifier
+	clock          clock.Clock
+	checkInterval  time.Duration
+}
+
+// NewEmergencyService creates an emergency access service. Both parties of
+// an emergency contact are told of its changes through notifier. Requests
+// whose wait is over are granted while Run runs, or when the grantee uses them.
+func NewEmergencyService(
+	repository repositories.IEmergencyContactRepository,
+	userRepository repositories.IUserRepository,
+	syncService ISyncService,
+	transactor repositories.ITransactor,
+	notifier notify.Notifier,
+	clock clock.Clock,
+) *emergencyService {
+	return &emergencyService{repository, userRepository, syncService, transactor, notifier, clock, emergencyCheckInterval}
+}
+
+func (e *emergencyService) Invite(ctx context.Context, input models.EmergencyContactInput) (*models.EmergencyContactDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(input.Email) {
+		return nil, cerrors.BadRequestError("email is required")
+	}
+
+	if input.Access != models.EmergencyView && input.Access != models.EmergencyTakeover {
+		return nil, cerrors.BadRequestError(fmt.Sprintf("access must be %q or %q", models.EmergencyView, models.EmergencyTakeover))
+	}
+
+	if input.WaitDays < 0 || input.WaitDays > emergencyMaxWaitDays {
+		return nil, cerrors.BadRequestError(fmt.Sprintf("waitDays must be between 1 and %d", emergencyMaxWaitDays))
+	}
+
+	if input.WaitDays == 0 {
+		input.WaitDays = emergencyDefaultWaitDays
+	}
+
+	grantee, err := e.userRepository.FindByEmail(ctx, input.Email)
+
+	if err != nil {
+		log.Printf("error while trying to find user by email: %v", err.Error())
+		return nil, err
+	}
+
+	if grantee.ID == 0 {
+		return nil, cerrors.NotFoundError("user not found")
+	}
+
+	if grantee.ID == userID {
+		return nil, cerrors.BadRequestError("you can't be your own emergency contact")
+	}
+
+	contacts, err := e.repository.FindByGrantorID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find emergency contacts by grantorId: %v", err.Error())
+		return nil, err
+	}
+
+	for _, contact := range contacts {
+		if contact.GranteeID == grantee.ID {
+			return nil, cerrors.ConflictError(fmt.Sprintf("%s is already an emergency contact", grantee.Email))
+		}
+	}
+
+	grantor, err := e.userRepository.FindByID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find user by id: %v", err.Error())
+		return nil, err
+	}
+
+	contact := models.EmergencyContact{
+		GrantorID:    userID,
+		GrantorEmail: grantor.Email,
+		GranteeID:    grantee.ID,
+		GranteeEmail: grantee.Email,
+		Access:       input.Access,
+		WaitDays:     input.WaitDays,
+		Status:       models.EmergencyInvited,
+	}
+
+	if err := e.save(ctx, &contact); err != nil {
+		return nil, err
+	}
+
+	e.notify(ctx, contact.GranteeEmail, "Emergency contact invitation",
+		fmt.Sprintf("%s invited you to be their emergency contact, with %s access after %d days. Accept the invitation in gopass.", contact.GrantorEmail, contact.Access, contact.WaitDays))
+
+	detail := e.toDetail(contact)
+
+	return &detail, nil
+}
+
+func (e *emergencyService) Contacts(ctx context.Context) ([]models.EmergencyContactDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return []models.EmergencyContactDetail{}, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	contacts, err := e.repository.FindByGrantorID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find emergency contacts by grantorId: %v", err.Error())
+		return []models.EmergencyContactDetail{}, err
+	}
+
+	return utils.Map(contacts, e.toDetail), nil
+}
+
+func (e *emergencyService) Grantors(ctx context.Context) ([]models.EmergencyContactDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return []models.EmergencyContactDetail{}, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	contacts, err := e.repository.FindByGranteeID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find emergency contacts by granteeId: %v", err.Error())
+		return []models.EmergencyContactDetail{}, err
+	}
+
+	return utils.Map(contacts, e.toDetail), nil
+}
+
+func (e *emergencyService) Accept(ctx context.Context, id uint, publicKey []byte) error {
+	contact, err := e.update(ctx, id, e.findAsGrantee, func(ctx context.Context, contact *models.EmergencyContact) error {
+		if err := checkEmergencyStatus(contact, models.EmergencyInvited); err != nil {
+			return err
+		}
+
+		if len(publicKey) != keywrap.KeySize {
+			return cerrors.BadRequestError("publicKey must be an X25519 public key")
+		}
+
+		contact.Status = models.EmergencyAccepted
+		contact.PublicKey = publicKey
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	e.notify(ctx, contact.GrantorEmail, "Emergency contact accepted",
+		fmt.Sprintf("%s accepted to be your emergency contact. Confirm them in gopass to hand them your key.", contact.GranteeEmail))
+
+	return nil
+}
+
+func (e *emergencyService) Confirm(ctx context.Context, id uint, wrappedKey []byte) error {
+	contact, err := e.update(ctx, id, e.findAsGrantor, func(ctx context.Context, contact *models.EmergencyContact) error {
+		if err := checkEmergencyStatus(contact, models.EmergencyAccepted); err != nil {
+			return err
+		}
+
+		if len(wrappedKey) == 0 {
+			return cerrors.BadRequestError("wrappedKey is required")
+		}
+
+		contact.Status = models.EmergencyConfirmed
+		contact.WrappedKey = wrappedKey
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	e.notify(ctx, contact.GranteeEmail, "Emergency contact confirmed",
+		fmt.Sprintf("%s confirmed you as their emergency contact. You can request access to their account in gopass.", contact.GrantorEmail))
+
+	return nil
+}
+
+func (e *emergencyService) Request(ctx context.Context, id uint) error {
+	contact, err := e.update(ctx, id, e.findAsGrantee, func(ctx context.Context, contact *models.EmergencyContact) error {
+		if err := checkEmergencyStatus(contact, models.EmergencyConfirmed); err != nil {
+			return err
+		}
+
+		requestedAt := e.clock.Now().UTC()
+		contact.Status = models.EmergencyRequested
+		contact.RequestedAt = &requestedAt
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	e.notify(ctx, contact.GrantorEmail, "Emergency access requested",
+		fmt.Sprintf("%s requested %s access to your account. It will be granted on %s unless you reject it in gopass.",
+			contact.GranteeEmail, contact.Access, grantsAt(*contact).Format(time.RFC1123)))
+
+	return nil
+}
+
+func (e *emergencyService) Approve(ctx context.Context, id uint) error {
+	_, err := e.grant(ctx, id, e.findAsGrantor, true)
+
+	return err
+}
+
+func (e *emergencyService) Reject(ctx context.Context, id uint) error {
+	contact, err := e.update(ctx, id, e.findAsGrantor, func(ctx context.Context, contact *models.EmergencyContact) error {
+		if contact.Status != models.EmergencyGranted {
+			if err := checkEmergencyStatus(contact, models.EmergencyRequested); err != nil {
+				return err
+			}
+		}
+
+		contact.Status = models.EmergencyConfirmed
+		contact.RequestedAt = nil
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	e.notify(ctx, contact.GranteeEmail, "Emergency access rejected",
+		fmt.Sprintf("%s rejected your access to their account.", contact.GrantorEmail))
+
+	return nil
+}
+
+func (e *emergencyService) Delete(ctx context.Context, id uint) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	contact, err := e.find(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if contact.GrantorID != userID && contact.GranteeID != userID {
+		return cerrors.NotFoundError("emergency contact not found")
+	}
+
+	if err := e.repository.Delete(ctx, id); err != nil {
+		log.Printf("error while trying to delete emergency contact: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (e *emergencyService) Access(ctx context.Context, id uint) (*models.EmergencyAccess, error) {
+	contact, err := e.findGranted(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Read as the grantor, whom the grantee acts for.
+	vaults, err := e.syncService.Changes(context.WithValue(ctx, keys.UserIDKey, contact.GrantorID), 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EmergencyAccess{
+		GrantorID:  contact.GrantorID,
+		Access:     contact.Access,
+		WrappedKey: contact.WrappedKey,
+		Vaults:     vaults,
+	}, nil
+}
+
+func (e *emergencyService) Takeover(ctx context.Context, id uint, input models.EmergencyTakeoverInput) error {
+	// Grants the request first when the wait is over.
+	if _, err := e.findGranted(ctx, id); err != nil {
+		return err
+	}
+
+	contact, err := e.update(ctx, id, e.findAsGrantee, func(ctx context.Context, contact *models.EmergencyContact) error {
+		// Access may have been revoked meanwhile.
+		if err := checkGranted(contact); err != nil {
+			return err
+		}
+
+		if contact.Access != models.EmergencyTakeover {
+			return cerrors.ForbiddenError("emergency access is view only")
+		}
+
+		if utils.IsBlank(input.AuthKey) {
+			return cerrors.BadRequestError("authKey is required")
+		}
+
+		grantor, err := e.userRepository.FindByID(ctx, contact.GrantorID)
+
+		if err != nil {
+			log.Printf("error while trying to find user by id: %v", err.Error())
+			return err
+		}
+
+		if grantor.ID == 0 {
+			return cerrors.NotFoundError("user not found")
+		}
+
+		if len(grantor.PublicKey) != 0 && len(input.EncryptedPrivateKey) == 0 {
+			return cerrors.BadRequestError("encryptedPrivateKey is required")
+		}
+
+		if len(grantor.PublicKey) == 0 && len(input.EncryptedPrivateKey) != 0 {
+			return cerrors.BadRequestError("keys not set")
+		}
+
+		// The tokens of the grantor are revoked along with their master password.
+		if err := e.userRepository.UpdatePassword(ctx, grantor.ID, hashAuthKey(input.AuthKey), input.EncryptedPrivateKey); err != nil {
+			log.Printf("error while trying to update the password of a user: %v", err.Error())
+			return err
+		}
+
+		contact.Status = models.EmergencyClosed
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	e.notify(ctx, contact.GrantorEmail, "Account taken over",
+		fmt.Sprintf("%s took over your account through emergency access and set a new master password.", contact.GranteeEmail))
+	e.notify(ctx, contact.GranteeEmail, "Account taken over",
+		fmt.Sprintf("You took over the account of %s. Log in with their email and the new master password.", contact.GrantorEmail))
+
+	return nil
+}
+
+// Run grants the requests whose wait is over every checkInterval, until ctx is done.
+func (e *emergencyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		e.grantDue(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// grantDue grants the requests whose wait is over.
+func (e *emergencyService) grantDue(ctx context.Context) {
+	contacts, err := e.repository.FindByStatus(ctx, models.EmergencyRequested)
+
+	if err != nil {
+		log.Printf("error while trying to find emergency contacts by status: %v", err.Error())
+		return
+	}
+
+	for _, contact := range contacts {
+		if e.due(contact) {
+			// Logged by grant, and retried on the next check.
+			e.grant(ctx, contact.ID, e.find, false)
+		}
+	}
+}
+
+// grant grants the access requested by an emergency contact found by find,
+// once its wait is over or right away when the grantor approves it. The
+// contact is read again in a transaction that locks it, so a request
+// rejected meanwhile isn't granted. It returns the contact as read.
+func (e *emergencyService) grant(ctx context.Context, id uint, find contactFinder, approved bool) (*models.EmergencyContact, error) {
+	var contact *models.EmergencyContact
+	granted := false
+
+	err := e.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		if contact, err = find(ctx, id); err != nil {
+			return err
+		}
+
+		if approved {
+			if err := checkEmergencyStatus(contact, models.EmergencyRequested); err != nil {
+				return err
+			}
+		} else if !e.due(*contact) {
+			return nil
+		}
+
+		contact.Status = models.EmergencyGranted
+		granted = true
+
+		return e.save(ctx, contact)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if granted {
+		e.notify(ctx, contact.GrantorEmail, "Emergency access granted",
+			fmt.Sprintf("%s was granted %s access to your account. You can revoke it in gopass.", contact.GranteeEmail, contact.Access))
+		e.notify(ctx, contact.GranteeEmail, "Emergency access granted",
+			fmt.Sprintf("You were granted %s access to the account of %s.", contact.Access, contact.GrantorEmail))
+	}
+
+	return contact, nil
+}
+
+// findGranted finds an emergency contact the authenticated user is, granting
+// its request first when the wait is over, and fails unless access is granted.
+func (e *emergencyService) findGranted(ctx context.Context, id uint) (*models.EmergencyContact, error) {
+	contact, err := e.grant(ctx, id, e.findAsGrantee, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkGranted(contact); err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+func (e *emergencyService) findAsGrantor(ctx context.Context, id uint) (*models.EmergencyContact, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	contact, err := e.find(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if contact.GrantorID != userID {
+		return nil, cerrors.NotFoundError("emergency contact not found")
+	}
+
+	return contact, nil
+}
+
+func (e *emergencyService) findAsGrantee(ctx context.Context, id uint) (*models.EmergencyContact, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	contact, err := e.find(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if contact.GranteeID != userID {
+		return nil, cerrors.NotFoundError("emergency contact not found")
+	}
+
+	return contact, nil
+}
+
+func (e *emergencyService) find(ctx context.Context, id uint) (*models.EmergencyContact, error) {
+	contact, err := e.repository.FindByID(ctx, id)
+
+	if err != nil {
+		log.Printf("error while trying to find an emergency contact by id: %v", err.Error())
+		return nil, err
+	}
+
+	if contact.ID == 0 {
+		return nil, cerrors.NotFoundError("emergency contact not found")
+	}
+
+	return contact, nil
+}
+
+// contactFinder finds an emergency contact the authenticated user may change.
+type contactFinder func(ctx context.Context, id uint) (*models.EmergencyContact, error)
+
+// update finds an emergency contact with find and saves the changes change
+// makes to it, in a transaction that locks the contact so concurrent changes
+// are made one at a time.
+func (e *emergencyService) update(
+	ctx context.Context,
+	id uint,
+	find contactFinder,
+	change func(ctx context.Context, contact *models.EmergencyContact) error,
+) (*models.EmergencyContact, error) {
+	var contact *models.EmergencyContact
+
+	err := e.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		if contact, err = find(ctx, id); err != nil {
+			return err
+		}
+
+		if err := change(ctx, contact); err != nil {
+			return err
+		}
+
+		return e.save(ctx, contact)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+func (e *emergencyService) save(ctx context.Context, contact *models.EmergencyContact) error {
+	if err := e.repository.Save(ctx, contact); err != nil {
+		log.Printf("error while trying to save emergency contact: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// notify notifies a user of a change to an emergency contact. Notifications
+// that can't be sent don't undo the change.
+func (e *emergencyService) notify(ctx context.Context, to, subject, body string) {
+	if err := e.notifier.Notify(ctx, notify.Message{To: to, Subject: subject, Body: body}); err != nil {
+		log.Printf("error while trying to send notification: %v", err.Error())
+	}
+}
+
+// due tells whether the wait of a request is over.
+func (e *emergencyService) due(contact models.EmergencyContact) bool {
+	return contact.Status == models.EmergencyRequested && !e.clock.Now().Before(grantsAt(contact))
+}
+
+func (e *emergencyService) toDetail(contact models.EmergencyContact) models.EmergencyContactDetail {
+	detail := models.EmergencyContactDetail{
+		ID:           contact.ID,
+		GrantorID:    contact.GrantorID,
+		GrantorEmail: contact.GrantorEmail,
+		GranteeID:    contact.GranteeID,
+		GranteeEmail: contact.GranteeEmail,
+		Access:       contact.Access,
+		WaitDays:     contact.WaitDays,
+		Status:       contact.Status,
+		PublicKey:    contact.PublicKey,
+		RequestedAt:  contact.RequestedAt,
+	}
+
+	if contact.Status == models.EmergencyRequested {
+		at := grantsAt(contact)
+		detail.GrantsAt = &at
+
+		// Granted already, until Run catches up.
+		if e.due(contact) {
+			detail.Status = models.EmergencyGranted
+		}
+	}
+
+	return detail
+}
+
+// grantsAt returns when a request is granted unless it is rejected.
+func grantsAt(contact models.EmergencyContact) time.Time {
+	return contact.RequestedAt.Add(time.Duration(contact.WaitDays) * 24 * time.Hour)
+}
+
+// checkEmergencyStatus fails unless an emergency contact has the status an operation requires.
+func checkEmergencyStatus(contact *models.EmergencyContact, status string) error {
+	if contact.Status != status {
+		return cerrors.ConflictError(fmt.Sprintf("emergency contact is %s, not %s", contact.Status, status))
+	}
+
+	return nil
+}
+
+// checkGranted fails unless emergency access is granted to a contact.
+func checkGranted(contact *models.EmergencyContact) error {
+	if contact.Status != models.EmergencyGranted {
+		return cerrors.ForbiddenError("emergency access isn't granted")
+	}
+
+	return nil
+}
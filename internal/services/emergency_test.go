@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/edgardjr92/gopass/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// emergencyMocks are the dependencies of an emergency service.
+type emergencyMocks struct {
+	repo       *mocks.EmergencyContactRepositoryMock
+	userRepo   *mocks.UserRepositoryMock
+	syncSvc    *mocks.SyncServiceMock
+	transactor *mocks.TransactorMock
+	notifier   *mocks.NotifierMock
+}
+
+func newEmergencyService(now time.Time) (*emergencyService, emergencyMocks) {
+	m := emergencyMocks{&mocks.EmergencyContactRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.SyncServiceMock{}, &mocks.TransactorMock{}, &mocks.NotifierMock{}}
+	m.transactor.On("WithinTransaction", mock.Anything)
+	m.notifier.On("Notify", mock.Anything, mock.Anything).Return(nil)
+
+	return NewEmergencyService(m.repo, m.userRepo, m.syncSvc, m.transactor, m.notifier, clock.Clock{NowFn: func() time.Time { return now }}), m
+}
+
+// notified returns the recipients of the notifications sent.
+func (m emergencyMocks) notified() []string {
+	var recipients []string
+
+	for _, call := range m.notifier.Calls {
+		recipients = append(recipients, call.Arguments.Get(1).(notify.Message).To)
+	}
+
+	return recipients
+}
+
+func TestNewEmergencyService(t *testing.T) {
+	repoMock := &mocks.EmergencyContactRepositoryMock{}
+	userRepoMock := &mocks.UserRepositoryMock{}
+	syncSvcMock := &mocks.SyncServiceMock{}
+	transactorMock := &mocks.TransactorMock{}
+	notifierMock := &mocks.NotifierMock{}
+
+	emergencySvc := NewEmergencyService(repoMock, userRepoMock, syncSvcMock, transactorMock, notifierMock, clock.Clock{})
+
+	assert.Equal(t, repoMock, emergencySvc.repository)
+	assert.Equal(t, userRepoMock, emergencySvc.userRepository)
+	assert.Equal(t, syncSvcMock, emergencySvc.syncService)
+	assert.Equal(t, transactorMock, emergencySvc.transactor)
+	assert.Equal(t, notifierMock, emergencySvc.notifier)
+	assert.Equal(t, emergencyCheckInterval, emergencySvc.checkInterval)
+}
+
+func TestInviteEmergencyContact(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		input    models.EmergencyContactInput
+		waitDays int
+		expected error
+	}{
+		{"view", models.EmergencyContactInput{Email: "jane@test.com", Access: models.EmergencyView, WaitDays: 2}, 2, nil},
+		{"default wait", models.EmergencyContactInput{Email: "jane@test.com", Access: models.EmergencyTakeover}, 7, nil},
+		{"missing email", models.EmergencyContactInput{Access: models.EmergencyView}, 0, cerrors.BadRequestError("email is required")},
+		{"unknown access", models.EmergencyContactInput{Email: "jane@test.com", Access: "admin"}, 0, cerrors.BadRequestError(`access must be "view" or "takeover"`)},
+		{"wait too long", models.EmergencyContactInput{Email: "jane@test.com", Access: models.EmergencyView, WaitDays: 91}, 0, cerrors.BadRequestError("waitDays must be between 1 and 90")},
+		{"unknown user", models.EmergencyContactInput{Email: "nobody@test.com", Access: models.EmergencyView}, 0, cerrors.NotFoundError("user not found")},
+		{"self", models.EmergencyContactInput{Email: "john@test.com", Access: models.EmergencyView}, 0, cerrors.BadRequestError("you can't be your own emergency contact")},
+		{"already a contact", models.EmergencyContactInput{Email: "bob@test.com", Access: models.EmergencyView}, 0, cerrors.ConflictError("bob@test.com is already an emergency contact")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			emergencySvc, m := newEmergencyService(now)
+			m.userRepo.On("FindByEmail", ctx, "jane@test.com").Return(&models.User{Model: gorm.Model{ID: 20}, Email: "jane@test.com"}, nil)
+			m.userRepo.On("FindByEmail", ctx, "bob@test.com").Return(&models.User{Model: gorm.Model{ID: 30}, Email: "bob@test.com"}, nil)
+			m.userRepo.On("FindByEmail", ctx, "john@test.com").Return(&models.User{Model: gorm.Model{ID: 10}, Email: "john@test.com"}, nil)
+			m.userRepo.On("FindByEmail", ctx, "nobody@test.com").Return(&models.User{}, nil)
+			m.userRepo.On("FindByID", ctx, uint(10)).Return(&models.User{Model: gorm.Model{ID: 10}, Email: "john@test.com"}, nil)
+			m.repo.On("FindByGrantorID", ctx, uint(10)).Return([]models.EmergencyContact{{GrantorID: 10, GranteeID: 30}}, nil)
+			m.repo.On("Save", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				args.Get(1).(*models.EmergencyContact).ID = 3
+			})
+
+			// when
+			actual, error := emergencySvc.Invite(ctx, tc.input)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+
+			if tc.expected != nil {
+				assert.Nil(t, actual)
+				m.repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				assert.Empty(t, m.notified())
+				return
+			}
+
+			assert.Equal(t, &models.EmergencyContactDetail{
+				ID:           3,
+				GrantorID:    10,
+				GrantorEmail: "john@test.com",
+				GranteeID:    20,
+				GranteeEmail: "jane@test.com",
+				Access:       tc.input.Access,
+				WaitDays:     tc.waitDays,
+				Status:       models.EmergencyInvited,
+			}, actual)
+			assert.Equal(t, []string{"jane@test.com"}, m.notified())
+		})
+	}
+}
+
+func TestEmergencyContactFlow(t *testing.T) {
+	grantor := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	grantee := context.WithValue(context.TODO(), keys.UserIDKey, uint(20))
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-24 * time.Hour)
+	publicKey := make([]byte, 32)
+	contact := func(status string) *models.EmergencyContact {
+		c := &models.EmergencyContact{
+			Model:        gorm.Model{ID: 3},
+			GrantorID:    10,
+			GrantorEmail: "john@test.com",
+			GranteeID:    20,
+			GranteeEmail: "jane@test.com",
+			Access:       models.EmergencyView,
+			WaitDays:     2,
+			Status:       status,
+		}
+
+		if status == models.EmergencyRequested || status == models.EmergencyGranted {
+			c.RequestedAt = &earlier
+		}
+
+		return c
+	}
+
+	testCases := []struct {
+		name     string
+		status   string
+		call     func(s *emergencyService) error
+		expected error
+		// saved is the status saved, empty when nothing is.
+		saved    string
+		notified []string
+	}{
+		{"accept", models.EmergencyInvited, func(s *emergencyService) error { return s.Accept(grantee, 3, publicKey) }, nil, models.EmergencyAccepted, []string{"john@test.com"}},
+		{"accept twice", models.EmergencyAccepted, func(s *emergencyService) error { return s.Accept(grantee, 3, publicKey) }, cerrors.ConflictError("emergency contact is accepted, not invited"), "", nil},
+		{"accept invalid key", models.EmergencyInvited, func(s *emergencyService) error { return s.Accept(grantee, 3, []byte("key")) }, cerrors.BadRequestError("publicKey must be an X25519 public key"), "", nil},
+		{"accept as grantor", models.EmergencyInvited, func(s *emergencyService) error { return s.Accept(grantor, 3, publicKey) }, cerrors.NotFoundError("emergency contact not found"), "", nil},
+		{"confirm", models.EmergencyAccepted, func(s *emergencyService) error { return s.Confirm(grantor, 3, []byte("wrapped")) }, nil, models.EmergencyConfirmed, []string{"jane@test.com"}},
+		{"confirm without key", models.EmergencyAccepted, func(s *emergencyService) error { return s.Confirm(grantor, 3, nil) }, cerrors.BadRequestError("wrappedKey is required"), "", nil},
+		{"confirm before accept", models.EmergencyInvited, func(s *emergencyService) error { return s.Confirm(grantor, 3, []byte("wrapped")) }, cerrors.ConflictError("emergency contact is invited, not accepted"), "", nil},
+		{"request", models.EmergencyConfirmed, func(s *emergencyService) error { return s.Request(grantee, 3) }, nil, models.EmergencyRequested, []string{"john@test.com"}},
+		{"request before confirm", models.EmergencyAccepted, func(s *emergencyService) error { return s.Request(grantee, 3) }, cerrors.ConflictError("emergency contact is accepted, not confirmed"), "", nil},
+		{"approve", models.EmergencyRequested, func(s *emergencyService) error { return s.Approve(grantor, 3) }, nil, models.EmergencyGranted, []string{"john@test.com", "jane@test.com"}},
+		{"approve rejected", models.EmergencyConfirmed, func(s *emergencyService) error { return s.Approve(grantor, 3) }, cerrors.ConflictError("emergency contact is confirmed, not requested"), "", nil},
+		{"approve as grantee", models.EmergencyRequested, func(s *emergencyService) error { return s.Approve(grantee, 3) }, cerrors.NotFoundError("emergency contact not found"), "", nil},
+		{"reject", models.EmergencyRequested, func(s *emergencyService) error { return s.Reject(grantor, 3) }, nil, models.EmergencyConfirmed, []string{"jane@test.com"}},
+		{"revoke", models.EmergencyGranted, func(s *emergencyService) error { return s.Reject(grantor, 3) }, nil, models.EmergencyConfirmed, []string{"jane@test.com"}},
+		{"reject closed", models.EmergencyClosed, func(s *emergencyService) error { return s.Reject(grantor, 3) }, cerrors.ConflictError("emergency contact is closed, not requested"), "", nil},
+		{"reject without request", models.EmergencyConfirmed, func(s *emergencyService) error { return s.Reject(grantor, 3) }, cerrors.ConflictError("emergency contact is confirmed, not requested"), "", nil},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			emergencySvc, m := newEmergencyService(now)
+			m.repo.On("FindByID", mock.Anything, uint(3)).Return(contact(tc.status), nil)
+			var saved *models.EmergencyContact
+			m.repo.On("Save", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(*models.EmergencyContact)
+			})
+
+			// when
+			error := tc.call(emergencySvc)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+			assert.Equal(t, tc.notified, m.notified())
+
+			if tc.saved == "" {
+				assert.Nil(t, saved)
+				return
+			}
+
+			assert.Equal(t, tc.saved, saved.Status)
+
+			switch tc.saved {
+			case models.EmergencyAccepted:
+				assert.Equal(t, publicKey, saved.PublicKey)
+			case models.EmergencyConfirmed:
+				if tc.status == models.EmergencyAccepted {
+					assert.Equal(t, []byte("wrapped"), saved.WrappedKey)
+				}
+				assert.Nil(t, saved.RequestedAt)
+			case models.EmergencyRequested:
+				assert.Equal(t, &now, saved.RequestedAt)
+			}
+		})
+	}
+}
+
+func TestEmergencyAccess(t *testing.T) {
+	grantee := context.WithValue(context.TODO(), keys.UserIDKey, uint(20))
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	requested := func(daysAgo int, access string) *models.EmergencyContact {
+		requestedAt := now.Add(-time.Duration(daysAgo) * 24 * time.Hour)
+
+		return &models.EmergencyContact{
+			Model:        gorm.Model{ID: 3},
+			GrantorID:    10,
+			GrantorEmail: "john@test.com",
+			GranteeID:    20,
+			GranteeEmail: "jane@test.com",
+			Access:       access,
+			WaitDays:     2,
+			Status:       models.EmergencyRequested,
+			WrappedKey:   []byte("wrapped"),
+			RequestedAt:  &requestedAt,
+		}
+	}
+
+	t.Run("granted once the wait is over", func(t *testing.T) {
+		// given
+		emergencySvc, m := newEmergencyService(now)
+		vaults := &models.SyncChanges{Revision: 42, Vaults: models.ChangeSet[models.VaultDetail]{Created: []models.VaultDetail{{ID: 1, Name: "Personal", UserID: 10}}}}
+		m.repo.On("FindByID", grantee, uint(3)).Return(requested(2, models.EmergencyView), nil)
+		m.repo.On("Save", grantee, mock.Anything).Return(nil)
+		m.syncSvc.On("Changes", mock.Anything, uint64(0)).Return(vaults, nil)
+
+		// when
+		actual, error := emergencySvc.Access(grantee, 3)
+
+		// then: the vaults are read as the grantor
+		assert.Nil(t, error)
+		assert.Equal(t, &models.EmergencyAccess{GrantorID: 10, Access: models.EmergencyView, WrappedKey: []byte("wrapped"), Vaults: vaults}, actual)
+		assert.Equal(t, uint(10), m.syncSvc.Calls[0].Arguments.Get(0).(context.Context).Value(keys.UserIDKey))
+		assert.Equal(t, []string{"john@test.com", "jane@test.com"}, m.notified())
+	})
+
+	t.Run("still waiting", func(t *testing.T) {
+		// given
+		emergencySvc, m := newEmergencyService(now)
+		m.repo.On("FindByID", grantee, uint(3)).Return(requested(1, models.EmergencyView), nil)
+
+		// when
+		actual, error := emergencySvc.Access(grantee, 3)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, cerrors.ForbiddenError("emergency access isn't granted"), error)
+		m.syncSvc.AssertNotCalled(t, "Changes", mock.Anything, mock.Anything)
+	})
+
+	t.Run("takeover", func(t *testing.T) {
+		// given
+		emergencySvc, m := newEmergencyService(now)
+		contact := requested(3, models.EmergencyTakeover)
+		m.repo.On("FindByID", grantee, uint(3)).Return(contact, nil)
+		m.repo.On("Save", grantee, mock.Anything).Return(nil)
+		m.userRepo.On("FindByID", grantee, uint(10)).Return(&models.User{Model: gorm.Model{ID: 10}, PublicKey: []byte("public")}, nil)
+		m.userRepo.On("UpdatePassword", grantee, uint(10), hashAuthKey("new-key"), []byte("sealed")).Return(nil)
+
+		// when
+		error := emergencySvc.Takeover(grantee, 3, models.EmergencyTakeoverInput{AuthKey: "new-key", EncryptedPrivateKey: []byte("sealed")})
+
+		// then: the contact can't be used again
+		assert.Nil(t, error)
+		m.userRepo.AssertCalled(t, "UpdatePassword", grantee, uint(10), hashAuthKey("new-key"), []byte("sealed"))
+		m.userRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		assert.Equal(t, models.EmergencyClosed, contact.Status)
+		m.repo.AssertNumberOfCalls(t, "Save", 2)
+		assert.Equal(t, []string{"john@test.com", "jane@test.com", "john@test.com", "jane@test.com"}, m.notified())
+	})
+
+	testCases := []struct {
+		name     string
+		access   string
+		input    models.EmergencyTakeoverInput
+		grantor  *models.User
+		expected error
+	}{
+		{"takeover with view access", models.EmergencyView, models.EmergencyTakeoverInput{AuthKey: "new-key"}, &models.User{Model: gorm.Model{ID: 10}}, cerrors.ForbiddenError("emergency access is view only")},
+		{"takeover without auth key", models.EmergencyTakeover, models.EmergencyTakeoverInput{}, &models.User{Model: gorm.Model{ID: 10}}, cerrors.BadRequestError("authKey is required")},
+		{"takeover without private key", models.EmergencyTakeover, models.EmergencyTakeoverInput{AuthKey: "new-key"}, &models.User{Model: gorm.Model{ID: 10}, PublicKey: []byte("public")}, cerrors.BadRequestError("encryptedPrivateKey is required")},
+		{"takeover of a grantor without keys", models.EmergencyTakeover, models.EmergencyTakeoverInput{AuthKey: "new-key", EncryptedPrivateKey: []byte("sealed")}, &models.User{Model: gorm.Model{ID: 10}}, cerrors.BadRequestError("keys not set")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			emergencySvc, m := newEmergencyService(now)
+			m.repo.On("FindByID", grantee, uint(3)).Return(requested(3, tc.access), nil)
+			m.repo.On("Save", grantee, mock.Anything).Return(nil)
+			m.userRepo.On("FindByID", grantee, uint(10)).Return(tc.grantor, nil)
+
+			// when
+			error := emergencySvc.Takeover(grantee, 3, tc.input)
+
+			// then: only the grant is saved
+			assert.Equal(t, tc.expected, error)
+			m.userRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			m.repo.AssertNumberOfCalls(t, "Save", 1)
+		})
+	}
+
+	t.Run("takeover revoked meanwhile", func(t *testing.T) {
+		// given
+		emergencySvc, m := newEmergencyService(now)
+		revoked := requested(3, models.EmergencyTakeover)
+		revoked.Status = models.EmergencyConfirmed
+		m.repo.On("FindByID", grantee, uint(3)).Return(requested(3, models.EmergencyTakeover), nil).Once()
+		m.repo.On("FindByID", grantee, uint(3)).Return(revoked, nil).Once()
+		m.repo.On("Save", grantee, mock.Anything).Return(nil)
+
+		// when
+		error := emergencySvc.Takeover(grantee, 3, models.EmergencyTakeoverInput{AuthKey: "new-key"})
+
+		// then
+		assert.Equal(t, cerrors.ForbiddenError("emergency access isn't granted"), error)
+		m.userRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestListEmergencyContacts(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	requestedAt := now.Add(-24 * time.Hour)
+	grantsAt := requestedAt.Add(2 * 24 * time.Hour)
+
+	// given
+	emergencySvc, m := newEmergencyService(now)
+	m.repo.On("FindByGrantorID", ctx, uint(10)).Return([]models.EmergencyContact{
+		{Model: gorm.Model{ID: 3}, GrantorID: 10, GranteeID: 20, Access: models.EmergencyView, WaitDays: 2, Status: models.EmergencyRequested, RequestedAt: &requestedAt, WrappedKey: []byte("wrapped")},
+		{Model: gorm.Model{ID: 4}, GrantorID: 10, GranteeID: 30, Access: models.EmergencyView, WaitDays: 1, Status: models.EmergencyRequested, RequestedAt: &requestedAt},
+	}, nil)
+
+	// when
+	actual, error := emergencySvc.Contacts(ctx)
+
+	// then: requests whose wait is over show as granted
+	dueAt := requestedAt.Add(24 * time.Hour)
+	assert.Nil(t, error)
+	assert.Equal(t, []models.EmergencyContactDetail{
+		{ID: 3, GrantorID: 10, GranteeID: 20, Access: models.EmergencyView, WaitDays: 2, Status: models.EmergencyRequested, RequestedAt: &requestedAt, GrantsAt: &grantsAt},
+		{ID: 4, GrantorID: 10, GranteeID: 30, Access: models.EmergencyView, WaitDays: 1, Status: models.EmergencyGranted, RequestedAt: &requestedAt, GrantsAt: &dueAt},
+	}, actual)
+}
+
+func TestDeleteEmergencyContact(t *testing.T) {
+	testCases := []struct {
+		name     string
+		userID   uint
+		expected error
+	}{
+		{"by the grantor", 10, nil},
+		{"by the grantee", 20, nil},
+		{"by someone else", 30, cerrors.NotFoundError("emergency contact not found")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			ctx := context.WithValue(context.TODO(), keys.UserIDKey, tc.userID)
+			emergencySvc, m := newEmergencyService(time.Now())
+			m.repo.On("FindByID", ctx, uint(3)).Return(&models.EmergencyContact{Model: gorm.Model{ID: 3}, GrantorID: 10, GranteeID: 20}, nil)
+			m.repo.On("Delete", ctx, uint(3)).Return(nil)
+
+			// when
+			error := emergencySvc.Delete(ctx, 3)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+			if tc.expected != nil {
+				m.repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGrantDueEmergencyAccess(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	due, waiting := now.Add(-3*24*time.Hour), now.Add(-time.Hour)
+
+	// given
+	contacts := []models.EmergencyContact{
+		{Model: gorm.Model{ID: 3}, GrantorEmail: "john@test.com", GranteeEmail: "jane@test.com", WaitDays: 2, Status: models.EmergencyRequested, RequestedAt: &due},
+		{Model: gorm.Model{ID: 4}, GrantorEmail: "john@test.com", GranteeEmail: "bob@test.com", WaitDays: 2, Status: models.EmergencyRequested, RequestedAt: &waiting},
+		{Model: gorm.Model{ID: 5}, GrantorEmail: "ann@test.com", GranteeEmail: "joe@test.com", WaitDays: 1, Status: models.EmergencyRequested, RequestedAt: &due},
+		{Model: gorm.Model{ID: 6}, GrantorEmail: "ann@test.com", GranteeEmail: "bob@test.com", WaitDays: 1, Status: models.EmergencyRequested, RequestedAt: &due},
+	}
+	rejected := contacts[3]
+	rejected.Status = models.EmergencyConfirmed
+	rejected.RequestedAt = nil
+
+	emergencySvc, m := newEmergencyService(now)
+	m.repo.On("FindByStatus", mock.Anything, models.EmergencyRequested).Return(contacts, nil)
+	m.repo.On("FindByID", mock.Anything, uint(3)).Return(&contacts[0], nil)
+	m.repo.On("FindByID", mock.Anything, uint(5)).Return(&contacts[2], nil)
+	m.repo.On("FindByID", mock.Anything, uint(6)).Return(&rejected, nil)
+	m.repo.On("Save", mock.Anything, mock.MatchedBy(func(c *models.EmergencyContact) bool { return c.ID == 3 })).Return(nil)
+	m.repo.On("Save", mock.Anything, mock.MatchedBy(func(c *models.EmergencyContact) bool { return c.ID == 5 })).Return(errors.New("database is down"))
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	// when
+	emergencySvc.Run(ctx)
+
+	// then: due requests are granted unless rejected meanwhile, failures are
+	// retried on the next check
+	m.repo.AssertNumberOfCalls(t, "Save", 2)
+	m.repo.AssertNotCalled(t, "FindByID", mock.Anything, uint(4))
+	m.transactor.AssertNumberOfCalls(t, "WithinTransaction", 3)
+	assert.Equal(t, []string{"john@test.com", "jane@test.com"}, m.notified())
+}
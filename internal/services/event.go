@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+
+	"github.com/edgardjr92/gopass/internal/importer"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/edgardjr92/gopass/pkg/eventbus"
+)
+
+// eventPublisher publishes the domain events of the authenticated user.
+type eventPublisher struct {
+	userRepository repositories.IUserRepository
+	bus            *eventbus.Bus[models.Event]
+	clock          clock.Clock
+}
+
+func (p eventPublisher) publish(ctx context.Context, event models.Event) {
+	event.ID = newEventID()
+	event.UserID, _ = ctx.Value(keys.UserIDKey).(uint)
+	event.OccurredAt = p.clock.Now().UTC()
+
+	// Read once the write is committed, the revision of the user is the
+	// revision of the change, or of a change of the user made since.
+	if user, err := p.userRepository.FindByID(ctx, event.UserID); err != nil {
+		log.Printf("error while trying to find a user by id: %v", err.Error())
+	} else {
+		event.Revision = user.Revision
+	}
+
+	p.bus.Publish(ctx, event)
+}
+
+// newEventID returns a random event ID.
+func newEventID() string {
+	id := make([]byte, 16)
+
+	// crypto/rand doesn't fail on the supported platforms.
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// publishingVaultService is a vault service publishing the vaults created,
+// renamed and deleted on an event bus.
+type publishingVaultService struct {
+	IVaultService
+	eventPublisher
+}
+
+func NewPublishingVaultService(
+	vaultService IVaultService,
+	userRepository repositories.IUserRepository,
+	bus *eventbus.Bus[models.Event],
+	clock clock.Clock,
+) *publishingVaultService {
+	return &publishingVaultService{vaultService, eventPublisher{userRepository, bus, clock}}
+}
+
+func (v *publishingVaultService) Create(ctx context.Context, name string) (uint, error) {
+	id, err := v.IVaultService.Create(ctx, name)
+
+	if err != nil {
+		return 0, err
+	}
+
+	v.publish(ctx, models.Event{Type: models.EventVaultCreated, VaultID: id})
+
+	return id, nil
+}
+
+func (v *publishingVaultService) Rename(ctx context.Context, id uint, name string, revision uint64) error {
+	if err := v.IVaultService.Rename(ctx, id, name, revision); err != nil {
+		return err
+	}
+
+	v.publish(ctx, models.Event{Type: models.EventVaultRenamed, VaultID: id})
+
+	return nil
+}
+
+func (v *publishingVaultService) Delete(ctx context.Context, id uint) error {
+	if err := v.IVaultService.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	v.publish(ctx, models.Event{Type: models.EventVaultDeleted, VaultID: id})
+
+	return nil
+}
+
+// publishingItemService is an item service publishing the items created,
+// updated and deleted on an event bus.
+type publishingItemService struct {
+	IItemService
+	eventPublisher
+}
+
+func NewPublishingItemService(
+	itemService IItemService,
+	userRepository repositories.IUserRepository,
+	bus *eventbus.Bus[models.Event],
+	clock clock.Clock,
+) *publishingItemService {
+	return &publishingItemService{itemService, eventPublisher{userRepository, bus, clock}}
+}
+
+func (i *publishingItemService) Create(ctx context.Context, vaultID uint, input models.ItemInput) (uint, error) {
+	id, err := i.IItemService.Create(ctx, vaultID, input)
+
+	if err != nil {
+		return 0, err
+	}
+
+	i.publish(ctx, models.Event{Type: models.EventItemCreated, VaultID: vaultID, ItemID: id})
+
+	return id, nil
+}
+
+func (i *publishingItemService) Update(ctx context.Context, id uint, input models.ItemInput) error {
+	if err := i.IItemService.Update(ctx, id, input); err != nil {
+		return err
+	}
+
+	i.publish(ctx, models.Event{Type: models.EventItemUpdated, VaultID: itemVaultID(ctx, i.IItemService, id), ItemID: id})
+
+	return nil
+}
+
+func (i *publishingItemService) Delete(ctx context.Context, id uint) error {
+	vaultID := itemVaultID(ctx, i.IItemService, id)
+
+	if err := i.IItemService.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	i.publish(ctx, models.Event{Type: models.EventItemDeleted, VaultID: vaultID, ItemID: id})
+
+	return nil
+}
+
+func (i *publishingItemService) SetFavorite(ctx context.Context, id uint, favorite bool) error {
+	if err := i.IItemService.SetFavorite(ctx, id, favorite); err != nil {
+		return err
+	}
+
+	i.publish(ctx, models.Event{Type: models.EventItemUpdated, VaultID: itemVaultID(ctx, i.IItemService, id), ItemID: id})
+
+	return nil
+}
+
+// publishingFolderService is a folder service publishing the changes to
+// folders on an event bus.
+type publishingFolderService struct {
+	IFolderService
+	eventPublisher
+}
+
+func NewPublishingFolderService(
+	folderService IFolderService,
+	userRepository repositories.IUserRepository,
+	bus *eventbus.Bus[models.Event],
+	clock clock.Clock,
+) *publishingFolderService {
+	return &publishingFolderService{folderService, eventPublisher{userRepository, bus, clock}}
+}
+
+func (f *publishingFolderService) Create(ctx context.Context, vaultID uint, input models.FolderInput) (uint, error) {
+	id, err := f.IFolderService.Create(ctx, vaultID, input)
+
+	if err != nil {
+		return 0, err
+	}
+
+	f.publish(ctx, models.Event{Type: models.EventVaultsChanged, VaultID: vaultID})
+
+	return id, nil
+}
+
+func (f *publishingFolderService) Update(ctx context.Context, id uint, input models.FolderInput) error {
+	if err := f.IFolderService.Update(ctx, id, input); err != nil {
+		return err
+	}
+
+	f.publish(ctx, models.Event{Type: models.EventVaultsChanged})
+
+	return nil
+}
+
+func (f *publishingFolderService) Delete(ctx context.Context, id uint) error {
+	if err := f.IFolderService.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	f.publish(ctx, models.Event{Type: models.EventVaultsChanged})
+
+	return nil
+}
+
+// publishingTagService is a tag service publishing the renames and deletions
+// of tags, which change the items tagged, on an event bus.
+type publishingTagService struct {
+	ITagService
+	eventPublisher
+}
+
+func NewPublishingTagService(
+	tagService ITagService,
+	userRepository repositories.IUserRepository,
+	bus *eventbus.Bus[models.Event],
+	clock clock.Clock,
+) *publishingTagService {
+	return &publishingTagService{tagService, eventPublisher{userRepository, bus, clock}}
+}
+
+func (t *publishingTagService) Rename(ctx context.Context, id uint, name string) error {
+	if err := t.ITagService.Rename(ctx, id, name); err != nil {
+		return err
+	}
+
+	t.publish(ctx, models.Event{Type: models.EventVaultsChanged})
+
+	return nil
+}
+
+func (t *publishingTagService) Delete(ctx context.Context, id uint) error {
+	if err := t.ITagService.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	t.publish(ctx, models.Event{Type: models.EventVaultsChanged})
+
+	return nil
+}
+
+// publishingBackupService is a backup service publishing restores on an event bus.
+type publishingBackupService struct {
+	IBackupService
+	eventPublisher
+}
+
+func NewPublishingBackupService(
+	backupService IBackupService,
+	userRepository repositories.IUserRepository,
+	bus *eventbus.Bus[models.Event],
+	clock clock.Clock,
+) *publishingBackupService {
+	return &publishingBackupService{backupService, eventPublisher{userRepository, bus, clock}}
+}
+
+func (b *publishingBackupService) Restore(ctx context.Context, data []byte, passphrase string, onConflict models.ConflictStrategy) (*models.RestoreReport, error) {
+	report, err := b.IBackupService.Restore(ctx, data, passphrase, onConflict)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b.publish(ctx, models.Event{Type: models.EventVaultsChanged})
+
+	return report, nil
+}
+
+// publishingImportService is an import service publishing imports on an
+// event bus. Dry runs change nothing and aren't published.
+type publishingImportService struct {
+	IImportService
+	eventPublisher
+}
+
+func NewPublishingImportService(
+	importService IImportService,
+	userRepository repositories.IUserRepository,
+	bus *eventbus.Bus[models.Event],
+	clock clock.Clock,
+) *publishingImportService {
+	return &publishingImportService{importService, eventPublisher{userRepository, bus, clock}}
+}
+
+func (i *publishingImportService) Import(ctx context.Context, format importer.Format, data []byte, dryRun bool) (*models.ImportReport, error) {
+	report, err := i.IImportService.Import(ctx, format, data, dryRun)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		i.publish(ctx, models.Event{Type: models.EventVaultsChanged})
+	}
+
+	return report, nil
+}
+
+func (i *publishingImportService) ImportKDBX(ctx context.Context, data []byte, password string, dryRun bool) (*models.ImportReport, error) {
+	report, err := i.IImportService.ImportKDBX(ctx, data, password, dryRun)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		i.publish(ctx, models.Event{Type: models.EventVaultsChanged})
+	}
+
+	return report, nil
+}
+
+// publishingKeyService is a key service publishing the rotations of key
+// pairs on an event bus, so the other clients of the user read the new one.
+type publishingKeyService struct {
+	IKeyService
+	eventPublisher
+}
+
+func NewPublishingKeyService(
+	keyService IKeyService,
+	userRepository repositories.IUserRepository,
+	bus *eventbus.Bus[models.Event],
+	clock clock.Clock,
+) *publishingKeyService {
+	return &publishingKeyService{keyService, eventPublisher{userRepository, bus, clock}}
+}
+
+func (k *publishingKeyService) Rotate(ctx context.Context, rotation models.KeyRotation) error {
+	if err := k.IKeyService.Rotate(ctx, rotation); err != nil {
+		return err
+	}
+
+	k.publish(ctx, models.Event{Type: models.EventKeysRotated})
+
+	return nil
+}
+
+// itemVaultID returns the vault of an item, so events can be selected by
+// vault, or zero when the item can't be read.
+func itemVaultID(ctx context.Context, itemService IItemService, id uint) uint {
+	item, err := itemService.Get(ctx, id)
+
+	if err != nil {
+		return 0
+	}
+
+	return item.VaultID
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/importer"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/edgardjr92/gopass/pkg/eventbus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// eventRecorder returns a bus and the events published on it, without their random IDs.
+func eventRecorder(t *testing.T) (*eventbus.Bus[models.Event], *[]models.Event) {
+	bus := eventbus.New[models.Event]()
+	events := &[]models.Event{}
+
+	bus.Subscribe(func(ctx context.Context, event models.Event) {
+		assert.Len(t, event.ID, 32)
+		event.ID = ""
+		*events = append(*events, event)
+	})
+
+	return bus, events
+}
+
+func TestPublishingServices(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	fixedClock := clock.Clock{NowFn: func() time.Time { return now }}
+	userRepoMock := &mocks.UserRepositoryMock{}
+	userRepoMock.On("FindByID", ctx, uint(10)).Return(&models.User{Model: gorm.Model{ID: 10}, Revision: 42}, nil)
+
+	t.Run("vault created", func(t *testing.T) {
+		// given
+		vaultSvcMock := &mocks.VaultServiceMock{}
+		bus, events := eventRecorder(t)
+
+		vaultSvcMock.On("Create", ctx, "Work").Return(uint(1), nil)
+
+		// when
+		actual, error := NewPublishingVaultService(vaultSvcMock, userRepoMock, bus, fixedClock).Create(ctx, "Work")
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, uint(1), actual)
+		assert.Equal(t, []models.Event{{Type: models.EventVaultCreated, UserID: 10, VaultID: 1, Revision: 42, OccurredAt: now}}, *events)
+	})
+
+	t.Run("vault rename failed", func(t *testing.T) {
+		// given
+		vaultSvcMock := &mocks.VaultServiceMock{}
+		bus, events := eventRecorder(t)
+
+		vaultSvcMock.On("Rename", ctx, uint(1), "Personal", uint64(3)).Return(errors.New("error when renaming"))
+
+		// when
+		error := NewPublishingVaultService(vaultSvcMock, userRepoMock, bus, fixedClock).Rename(ctx, 1, "Personal", 3)
+
+		// then
+		assert.Equal(t, "error when renaming", error.Error())
+		assert.Empty(t, *events)
+	})
+
+	t.Run("item updated", func(t *testing.T) {
+		// given
+		itemSvcMock := &mocks.ItemServiceMock{}
+		bus, events := eventRecorder(t)
+		input := models.ItemInput{Name: "GitHub", Revision: 4}
+
+		itemSvcMock.On("Update", ctx, uint(5), input).Return(nil)
+		itemSvcMock.On("Get", ctx, uint(5)).Return(&models.ItemDetail{ID: 5, VaultID: 1}, nil)
+
+		// when
+		error := NewPublishingItemService(itemSvcMock, userRepoMock, bus, fixedClock).Update(ctx, 5, input)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []models.Event{{Type: models.EventItemUpdated, UserID: 10, VaultID: 1, ItemID: 5, Revision: 42, OccurredAt: now}}, *events)
+	})
+
+	t.Run("item deleted", func(t *testing.T) {
+		// given
+		itemSvcMock := &mocks.ItemServiceMock{}
+		bus, events := eventRecorder(t)
+
+		itemSvcMock.On("Get", ctx, uint(5)).Return(&models.ItemDetail{ID: 5, VaultID: 1}, nil)
+		itemSvcMock.On("Delete", ctx, uint(5)).Return(nil)
+
+		// when
+		error := NewPublishingItemService(itemSvcMock, userRepoMock, bus, fixedClock).Delete(ctx, 5)
+
+		// then: the vault is looked up before the item is gone
+		assert.Nil(t, error)
+		assert.Equal(t, []models.Event{{Type: models.EventItemDeleted, UserID: 10, VaultID: 1, ItemID: 5, Revision: 42, OccurredAt: now}}, *events)
+	})
+
+	t.Run("item create failed", func(t *testing.T) {
+		// given
+		itemSvcMock := &mocks.ItemServiceMock{}
+		bus, events := eventRecorder(t)
+		input := models.ItemInput{Name: "GitHub"}
+
+		itemSvcMock.On("Create", ctx, uint(1), input).Return(uint(0), errors.New("error when creating"))
+
+		// when
+		actual, error := NewPublishingItemService(itemSvcMock, userRepoMock, bus, fixedClock).Create(ctx, 1, input)
+
+		// then
+		assert.Equal(t, uint(0), actual)
+		assert.Equal(t, "error when creating", error.Error())
+		assert.Empty(t, *events)
+	})
+
+	t.Run("favorite set", func(t *testing.T) {
+		// given
+		itemSvcMock := &mocks.ItemServiceMock{}
+		bus, events := eventRecorder(t)
+
+		itemSvcMock.On("SetFavorite", ctx, uint(5), true).Return(nil)
+		itemSvcMock.On("Get", ctx, uint(5)).Return(&models.ItemDetail{ID: 5, VaultID: 1}, nil)
+
+		// when
+		error := NewPublishingItemService(itemSvcMock, userRepoMock, bus, fixedClock).SetFavorite(ctx, 5, true)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []models.Event{{Type: models.EventItemUpdated, UserID: 10, VaultID: 1, ItemID: 5, Revision: 42, OccurredAt: now}}, *events)
+	})
+
+	t.Run("folder moved", func(t *testing.T) {
+		// given
+		folderSvcMock := &mocks.FolderServiceMock{}
+		bus, events := eventRecorder(t)
+		parentID := uint(2)
+		input := models.FolderInput{Name: "Work", ParentID: &parentID}
+
+		folderSvcMock.On("Update", ctx, uint(3), input).Return(nil)
+
+		// when
+		error := NewPublishingFolderService(folderSvcMock, userRepoMock, bus, fixedClock).Update(ctx, 3, input)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []models.Event{{Type: models.EventVaultsChanged, UserID: 10, Revision: 42, OccurredAt: now}}, *events)
+	})
+
+	t.Run("tag renamed", func(t *testing.T) {
+		// given
+		tagSvcMock := &mocks.TagServiceMock{}
+		bus, events := eventRecorder(t)
+
+		tagSvcMock.On("Rename", ctx, uint(4), "work").Return(nil)
+
+		// when
+		error := NewPublishingTagService(tagSvcMock, userRepoMock, bus, fixedClock).Rename(ctx, 4, "work")
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []models.Event{{Type: models.EventVaultsChanged, UserID: 10, Revision: 42, OccurredAt: now}}, *events)
+	})
+
+	t.Run("backup restored", func(t *testing.T) {
+		// given
+		backupSvcMock := &mocks.BackupServiceMock{}
+		bus, events := eventRecorder(t)
+		report := &models.RestoreReport{Vaults: []models.RestoredVault{{OldID: 1, NewID: 4}}}
+
+		backupSvcMock.On("Restore", ctx, []byte("backup"), "passphrase", models.ConflictMerge).Return(report, nil)
+
+		// when
+		actual, error := NewPublishingBackupService(backupSvcMock, userRepoMock, bus, fixedClock).Restore(ctx, []byte("backup"), "passphrase", models.ConflictMerge)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, report, actual)
+		assert.Equal(t, []models.Event{{Type: models.EventVaultsChanged, UserID: 10, Revision: 42, OccurredAt: now}}, *events)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		// given
+		importSvcMock := &mocks.ImportServiceMock{}
+		bus, events := eventRecorder(t)
+
+		importSvcMock.On("Import", ctx, importer.Bitwarden, []byte("data"), true).Return(&models.ImportReport{DryRun: true}, nil)
+		importSvcMock.On("Import", ctx, importer.Bitwarden, []byte("data"), false).Return(&models.ImportReport{}, nil)
+		importSvcMock.On("ImportKDBX", ctx, []byte("data"), "secret", false).Return(nil, errors.New("error when importing"))
+		importSvc := NewPublishingImportService(importSvcMock, userRepoMock, bus, fixedClock)
+
+		// when
+		_, dryRunErr := importSvc.Import(ctx, importer.Bitwarden, []byte("data"), true)
+		_, importErr := importSvc.Import(ctx, importer.Bitwarden, []byte("data"), false)
+		_, kdbxErr := importSvc.ImportKDBX(ctx, []byte("data"), "secret", false)
+
+		// then: dry runs and failed imports change nothing
+		assert.Nil(t, dryRunErr)
+		assert.Nil(t, importErr)
+		assert.Equal(t, "error when importing", kdbxErr.Error())
+		assert.Equal(t, []models.Event{{Type: models.EventVaultsChanged, UserID: 10, Revision: 42, OccurredAt: now}}, *events)
+	})
+
+	t.Run("keys rotated", func(t *testing.T) {
+		// given
+		keySvcMock := &mocks.KeyServiceMock{}
+		bus, events := eventRecorder(t)
+		rotation := models.KeyRotation{PublicKey: []byte("public")}
+
+		keySvcMock.On("Rotate", ctx, rotation).Return(nil)
+
+		// when
+		error := NewPublishingKeyService(keySvcMock, userRepoMock, bus, fixedClock).Rotate(ctx, rotation)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []models.Event{{Type: models.EventKeysRotated, UserID: 10, Revision: 42, OccurredAt: now}}, *events)
+	})
+
+	t.Run("revision not found", func(t *testing.T) {
+		// given
+		vaultSvcMock := &mocks.VaultServiceMock{}
+		failingUserRepoMock := &mocks.UserRepositoryMock{}
+		bus, events := eventRecorder(t)
+
+		vaultSvcMock.On("Delete", ctx, uint(1)).Return(nil)
+		failingUserRepoMock.On("FindByID", ctx, uint(10)).Return(&models.User{}, errors.New("error when finding"))
+
+		// when
+		error := NewPublishingVaultService(vaultSvcMock, failingUserRepoMock, bus, fixedClock).Delete(ctx, 1)
+
+		// then: the vault is deleted, the event is published without revision
+		assert.Nil(t, error)
+		assert.Equal(t, []models.Event{{Type: models.EventVaultDeleted, UserID: 10, VaultID: 1, OccurredAt: now}}, *events)
+	})
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"log"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/importer"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/kdbx"
+)
+
+type IExportService interface {
+	// ExportKDBX exports a vault from the authenticated user to a KeePass KDBX 4 database protected with password.
+	// Folders become nested groups, tags become entry tags, and TOTP secrets and SSH keys
+	// are kept in the protected importer.KDBXTOTPField and importer.KDBXSSHKeyField fields.
+	// It returns the name of the vault and the encrypted database.
+	ExportKDBX(ctx context.Context, vaultID uint, password string) (string, []byte, error)
+}
+
+type exportService struct {
+	vaultRepository  repositories.IVaultRepository
+	itemRepository   repositories.IItemRepository
+	folderRepository repositories.IFolderRepository
+	kdbxSettings     kdbx.Settings
+}
+
+func NewExportService(
+	vaultRepository repositories.IVaultRepository,
+	itemRepository repositories.IItemRepository,
+	folderRepository repositories.IFolderRepository,
+) *exportService {
+	return &exportService{vaultRepository, itemRepository, folderRepository, kdbx.DefaultSettings()}
+}
+
+func (e *exportService) ExportKDBX(ctx context.Context, vaultID uint, password string) (string, []byte, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return "", nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(password) {
+		return "", nil, cerrors.BadRequestError("password is required")
+	}
+
+	vault, err := e.findVault(ctx, userID, vaultID)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	items, err := e.itemRepository.FindByVaultIDs(ctx, []uint{vault.ID})
+
+	if err != nil {
+		log.Printf("error while trying to find items by vaultIds: %v", err.Error())
+		return "", nil, err
+	}
+
+	folders, err := e.folderRepository.FindByVaultID(ctx, vault.ID)
+
+	if err != nil {
+		log.Printf("error while trying to find folders by vaultId: %v", err.Error())
+		return "", nil, err
+	}
+
+	db := &kdbx.Database{
+		Name: vault.Name,
+		Root: kdbxGroup(vault.Name, nil, folders, items),
+	}
+
+	buf := &bytes.Buffer{}
+
+	if err := kdbx.Write(buf, db, kdbx.NewPasswordKey(password), e.kdbxSettings); err != nil {
+		log.Printf("error while trying to write kdbx database: %v", err.Error())
+		return "", nil, err
+	}
+
+	return vault.Name, buf.Bytes(), nil
+}
+
+// kdbxGroup returns the group of a folder, or of the vault root when folderID is nil,
+// with the items of the folder and the groups of its subfolders.
+// Items of folders missing from folders go to the root.
+func kdbxGroup(name string, folderID *uint, folders []models.Folder, items []models.Item) kdbx.Group {
+	known := map[uint]bool{}
+	for _, folder := range folders {
+		known[folder.ID] = true
+	}
+
+	group := kdbx.Group{UUID: kdbx.NewUUID(), Name: name}
+
+	for _, item := range items {
+		inFolder := item.FolderID != nil && known[*item.FolderID]
+
+		if folderID == nil && !inFolder || folderID != nil && inFolder && *item.FolderID == *folderID {
+			group.Entries = append(group.Entries, kdbxEntry(item))
+		}
+	}
+
+	for _, folder := range folders {
+		isChild := folder.ParentID == nil || !known[*folder.ParentID]
+		if folderID != nil {
+			isChild = folder.ParentID != nil && *folder.ParentID == *folderID
+		}
+
+		if isChild {
+			id := folder.ID
+			group.Groups = append(group.Groups, kdbxGroup(folder.Name, &id, folders, items))
+		}
+	}
+
+	return group
+}
+
+func kdbxEntry(item models.Item) kdbx.Entry {
+	entry := kdbx.Entry{
+		UUID:       kdbx.NewUUID(),
+		Title:      item.Name,
+		UserName:   item.Username,
+		Password:   item.Password,
+		URL:        item.Url,
+		Notes:      item.Notes,
+		Tags:       tagNames(item.Tags),
+		CreatedAt:  item.CreatedAt,
+		ModifiedAt: item.UpdatedAt,
+	}
+
+	if item.TOTP != "" {
+		entry.Fields = append(entry.Fields, kdbx.Field{Key: importer.KDBXTOTPField, Value: item.TOTP, Protected: true})
+	}
+
+	if item.SSHKey != "" {
+		entry.Fields = append(entry.Fields, kdbx.Field{Key: importer.KDBXSSHKeyField, Value: item.SSHKey, Protected: true})
+	}
+
+	return entry
+}
+
+// findVault returns a vault from the user, or a not found error if the user has no such vault.
+func (e *exportService) findVault(ctx context.Context, userID, vaultID uint) (*models.Vault, error) {
+	vaults, err := e.vaultRepository.FindByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find all vaults by userId: %v", err.Error())
+		return nil, err
+	}
+
+	for _, vault := range vaults {
+		if vault.ID == vaultID {
+			return &vault, nil
+		}
+	}
+
+	return nil, cerrors.NotFoundError("vault not found")
+}
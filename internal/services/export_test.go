@@ -0,0 +1,180 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/kdbx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var testKDBXSettings = kdbx.Settings{Cipher: kdbx.ChaCha20, KDF: kdbx.Argon2id, Iterations: 1, Memory: 64 << 10, Parallelism: 1}
+
+func TestNewExportService(t *testing.T) {
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	itemRepoMock := &mocks.ItemRepositoryMock{}
+	folderRepoMock := &mocks.FolderRepositoryMock{}
+
+	exportSvc := NewExportService(vaultRepoMock, itemRepoMock, folderRepoMock)
+
+	assert.Equal(t, vaultRepoMock, exportSvc.vaultRepository)
+	assert.Equal(t, itemRepoMock, exportSvc.itemRepository)
+	assert.Equal(t, folderRepoMock, exportSvc.folderRepository)
+	assert.Equal(t, kdbx.DefaultSettings(), exportSvc.kdbxSettings)
+}
+
+func TestExportKDBX(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+	updatedAt := time.Date(2023, 5, 6, 10, 0, 0, 0, time.UTC)
+
+	vaults := []models.Vault{{Model: gorm.Model{ID: 1}, Name: "Work", UserID: userID}}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		folderRepoMock := &mocks.FolderRepositoryMock{}
+
+		dev, ops := uint(20), uint(21)
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1}).Return([]models.Item{
+			{
+				Model:    gorm.Model{ID: 1, CreatedAt: updatedAt, UpdatedAt: updatedAt},
+				Name:     "GitHub",
+				Url:      "https://github.com",
+				Username: "octocat",
+				Password: "gh-pass",
+				Notes:    "2fa",
+				SSHKey:   "ssh-key",
+				TOTP:     "JBSWY3DP",
+				VaultID:  1,
+				Tags:     []models.Tag{{Name: "dev"}, {Name: "2fa"}},
+			},
+			{Model: gorm.Model{ID: 2}, Name: "Jira", Password: "jira-pass", VaultID: 1, FolderID: &dev},
+			{Model: gorm.Model{ID: 3}, Name: "Server", Password: "server-pass", VaultID: 1, FolderID: &ops},
+		}, nil)
+		folderRepoMock.On("FindByVaultID", ctx, uint(1)).Return([]models.Folder{
+			{Model: gorm.Model{ID: ops}, Name: "Ops", VaultID: 1, ParentID: &dev},
+			{Model: gorm.Model{ID: dev}, Name: "Dev", VaultID: 1},
+		}, nil)
+
+		// when
+		exportSvc := &exportService{vaultRepoMock, itemRepoMock, folderRepoMock, testKDBXSettings}
+		name, data, error := exportSvc.ExportKDBX(ctx, 1, "master")
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, "Work", name)
+
+		db, err := kdbx.Read(bytes.NewReader(data), kdbx.NewPasswordKey("master"))
+		assert.Nil(t, err)
+		assert.Equal(t, "Work", db.Name)
+		assert.Equal(t, "Work", db.Root.Name)
+		assert.Len(t, db.Root.Entries, 1)
+
+		entry := db.Root.Entries[0]
+		assert.Equal(t, "GitHub", entry.Title)
+		assert.Equal(t, "octocat", entry.UserName)
+		assert.Equal(t, "gh-pass", entry.Password)
+		assert.Equal(t, "https://github.com", entry.URL)
+		assert.Equal(t, "2fa", entry.Notes)
+		assert.Equal(t, []string{"dev", "2fa"}, entry.Tags)
+		assert.Equal(t, []kdbx.Field{{Key: "otp", Value: "JBSWY3DP", Protected: true}, {Key: "SSH Key", Value: "ssh-key", Protected: true}}, entry.Fields)
+		assert.Equal(t, updatedAt, entry.ModifiedAt)
+
+		assert.Len(t, db.Root.Groups, 1)
+		assert.Equal(t, "Dev", db.Root.Groups[0].Name)
+		assert.Equal(t, "Jira", db.Root.Groups[0].Entries[0].Title)
+		assert.Len(t, db.Root.Groups[0].Groups, 1)
+		assert.Equal(t, "Ops", db.Root.Groups[0].Groups[0].Name)
+		assert.Equal(t, "Server", db.Root.Groups[0].Groups[0].Entries[0].Title)
+
+		vaultRepoMock.AssertExpectations(t)
+		itemRepoMock.AssertExpectations(t)
+		folderRepoMock.AssertExpectations(t)
+	})
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// when
+		exportSvc := &exportService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, &mocks.FolderRepositoryMock{}, testKDBXSettings}
+		_, data, error := exportSvc.ExportKDBX(context.TODO(), 1, "master")
+
+		// then
+		assert.Nil(t, data)
+		assert.Equal(t, "user is not authenticated", error.Error())
+	})
+
+	t.Run("password is required", func(t *testing.T) {
+		// when
+		exportSvc := &exportService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, &mocks.FolderRepositoryMock{}, testKDBXSettings}
+		_, data, error := exportSvc.ExportKDBX(ctx, 1, " ")
+
+		// then
+		assert.Nil(t, data)
+		assert.Equal(t, "password is required", error.Error())
+	})
+
+	t.Run("vault not found", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+
+		// when
+		exportSvc := &exportService{vaultRepoMock, itemRepoMock, &mocks.FolderRepositoryMock{}, testKDBXSettings}
+		_, data, error := exportSvc.ExportKDBX(ctx, 2, "master")
+
+		// then
+		assert.Nil(t, data)
+		assert.Equal(t, "vault not found", error.Error())
+
+		itemRepoMock.AssertNotCalled(t, "FindByVaultIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1}).Return([]models.Item{}, errors.New("error when finding items"))
+
+		// when
+		exportSvc := &exportService{vaultRepoMock, itemRepoMock, &mocks.FolderRepositoryMock{}, testKDBXSettings}
+		_, data, error := exportSvc.ExportKDBX(ctx, 1, "master")
+
+		// then
+		assert.Nil(t, data)
+		assert.Equal(t, "error when finding items", error.Error())
+	})
+
+	t.Run("error when finding folders", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		folderRepoMock := &mocks.FolderRepositoryMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1}).Return([]models.Item{}, nil)
+		folderRepoMock.On("FindByVaultID", ctx, uint(1)).Return([]models.Folder{}, errors.New("error when finding folders"))
+
+		// when
+		exportSvc := &exportService{vaultRepoMock, itemRepoMock, folderRepoMock, testKDBXSettings}
+		_, data, error := exportSvc.ExportKDBX(ctx, 1, "master")
+
+		// then
+		assert.Nil(t, data)
+		assert.Equal(t, "error when finding folders", error.Error())
+	})
+}
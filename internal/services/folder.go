@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+)
+
+type IFolderService interface {
+	// Create creates a folder in a vault, inside input.ParentID when it isn't nil.
+	// It returns the ID of the newly created folder.
+	Create(ctx context.Context, vaultID uint, input models.FolderInput) (uint, error)
+	// GetAll returns all folders from a vault.
+	GetAll(ctx context.Context, vaultID uint) ([]models.FolderDetail, error)
+	// Update renames a folder and moves it to input.ParentID.
+	// A folder can't be moved into itself or one of its subfolders.
+	Update(ctx context.Context, id uint, input models.FolderInput) error
+	// Delete deletes an empty folder.
+	Delete(ctx context.Context, id uint) error
+}
+
+type folderService struct {
+	repository      repositories.IFolderRepository
+	vaultRepository repositories.IVaultRepository
+	itemRepository  repositories.IItemRepository
+	userRepository  repositories.IUserRepository
+	transactor      repositories.ITransactor
+}
+
+func NewFolderService(
+	repository repositories.IFolderRepository,
+	vaultRepository repositories.IVaultRepository,
+	itemRepository repositories.IItemRepository,
+	userRepository repositories.IUserRepository,
+	transactor repositories.ITransactor,
+) *folderService {
+	return &folderService{repository, vaultRepository, itemRepository, userRepository, transactor}
+}
+
+func (f *folderService) Create(ctx context.Context, vaultID uint, input models.FolderInput) (uint, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return 0, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(input.Name) {
+		return 0, cerrors.BadRequestError("name is required")
+	}
+
+	if _, err := findUserVault(ctx, f.vaultRepository, userID, vaultID); err != nil {
+		return 0, err
+	}
+
+	if _, err := findVaultFolder(ctx, f.repository, vaultID, input.ParentID); err != nil {
+		return 0, err
+	}
+
+	newFolder := models.Folder{
+		Name:     input.Name,
+		VaultID:  vaultID,
+		ParentID: input.ParentID,
+	}
+
+	if err := f.repository.Save(ctx, &newFolder); err != nil {
+		log.Printf("error while trying to save folder: %v", err.Error())
+		return 0, err
+	}
+
+	return newFolder.ID, nil
+}
+
+func (f *folderService) GetAll(ctx context.Context, vaultID uint) ([]models.FolderDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return []models.FolderDetail{}, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if _, err := findUserVault(ctx, f.vaultRepository, userID, vaultID); err != nil {
+		return []models.FolderDetail{}, err
+	}
+
+	folders, err := f.repository.FindByVaultID(ctx, vaultID)
+
+	if err != nil {
+		log.Printf("error while trying to find folders by vaultId: %v", err.Error())
+		return []models.FolderDetail{}, err
+	}
+
+	return utils.Map(folders, toFolderDetail), nil
+}
+
+func (f *folderService) Update(ctx context.Context, id uint, input models.FolderInput) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(input.Name) {
+		return cerrors.BadRequestError("name is required")
+	}
+
+	// The folders are read once the revision of the user is taken, which locks
+	// out other writes of the user, so two moves can't both pass the cycle
+	// check and together make a cycle.
+	return withRevision(ctx, f.transactor, f.userRepository, userID, func(ctx context.Context, revision uint64) error {
+		folder, err := f.findFolder(ctx, userID, id)
+
+		if err != nil {
+			return err
+		}
+
+		if input.ParentID != nil {
+			folders, err := f.repository.FindByVaultID(ctx, folder.VaultID)
+
+			if err != nil {
+				log.Printf("error while trying to find folders by vaultId: %v", err.Error())
+				return err
+			}
+
+			if !containsFolder(folders, *input.ParentID) {
+				return cerrors.NotFoundError("folder not found")
+			}
+
+			if subfolderIDs(folders, folder.ID)[*input.ParentID] {
+				return cerrors.BadRequestError("folder can't be moved into itself or its subfolders")
+			}
+		}
+
+		folder.Name = input.Name
+		folder.ParentID = input.ParentID
+
+		if err := f.repository.Save(ctx, folder); err != nil {
+			log.Printf("error while trying to save folder: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (f *folderService) Delete(ctx context.Context, id uint) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	folder, err := f.findFolder(ctx, userID, id)
+
+	if err != nil {
+		return err
+	}
+
+	folders, err := f.repository.FindByVaultID(ctx, folder.VaultID)
+
+	if err != nil {
+		log.Printf("error while trying to find folders by vaultId: %v", err.Error())
+		return err
+	}
+
+	for _, other := range folders {
+		if other.ParentID != nil && *other.ParentID == folder.ID {
+			return cerrors.ConflictError("folder is not empty")
+		}
+	}
+
+	items, err := f.itemRepository.FindByVaultIDs(ctx, []uint{folder.VaultID})
+
+	if err != nil {
+		log.Printf("error while trying to find items by vaultIds: %v", err.Error())
+		return err
+	}
+
+	for _, item := range items {
+		if item.FolderID != nil && *item.FolderID == folder.ID {
+			return cerrors.ConflictError("folder is not empty")
+		}
+	}
+
+	if err := f.repository.Delete(ctx, folder.ID); err != nil {
+		log.Printf("error while trying to delete folder: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// findFolder finds a folder by ID, or returns a not found error if it isn't in a vault from the user.
+func (f *folderService) findFolder(ctx context.Context, userID, id uint) (*models.Folder, error) {
+	folder, err := f.repository.FindByID(ctx, id)
+
+	if err != nil {
+		log.Printf("error while trying to find a folder by id: %v", err.Error())
+		return nil, err
+	}
+
+	if folder.ID == 0 {
+		return nil, cerrors.NotFoundError("folder not found")
+	}
+
+	vault, err := f.vaultRepository.FindByID(ctx, folder.VaultID)
+
+	if err != nil {
+		log.Printf("error while trying to find a vault by id: %v", err.Error())
+		return nil, err
+	}
+
+	if vault.ID == 0 || vault.UserID != userID {
+		return nil, cerrors.NotFoundError("folder not found")
+	}
+
+	return folder, nil
+}
+
+// findVaultFolder finds a folder by ID, or returns a not found error if it isn't in the vault.
+// It returns nil when id is nil, which is the root of the vault.
+func findVaultFolder(ctx context.Context, repository repositories.IFolderRepository, vaultID uint, id *uint) (*models.Folder, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	folder, err := repository.FindByID(ctx, *id)
+
+	if err != nil {
+		log.Printf("error while trying to find a folder by id: %v", err.Error())
+		return nil, err
+	}
+
+	if folder.ID == 0 || folder.VaultID != vaultID {
+		return nil, cerrors.NotFoundError("folder not found")
+	}
+
+	return folder, nil
+}
+
+// subfolderIDs returns the set of the folder with the given ID and all folders nested in it,
+// or nil when there is no such folder.
+func subfolderIDs(folders []models.Folder, id uint) map[uint]bool {
+	if !containsFolder(folders, id) {
+		return nil
+	}
+
+	children := map[uint][]uint{}
+
+	for _, folder := range folders {
+		if folder.ParentID != nil {
+			children[*folder.ParentID] = append(children[*folder.ParentID], folder.ID)
+		}
+	}
+
+	ids := map[uint]bool{id: true}
+	pending := []uint{id}
+
+	for len(pending) > 0 {
+		parent := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		for _, child := range children[parent] {
+			// Checking for visited folders keeps a corrupted tree with a cycle from looping forever.
+			if !ids[child] {
+				ids[child] = true
+				pending = append(pending, child)
+			}
+		}
+	}
+
+	return ids
+}
+
+func containsFolder(folders []models.Folder, id uint) bool {
+	for _, folder := range folders {
+		if folder.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toFolderDetail(folder models.Folder) models.FolderDetail {
+	return models.FolderDetail{
+		ID:       folder.ID,
+		VaultID:  folder.VaultID,
+		ParentID: folder.ParentID,
+		Name:     folder.Name,
+	}
+}
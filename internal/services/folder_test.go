@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestNewFolderService(t *testing.T) {
+	repoMock := &mocks.FolderRepositoryMock{}
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	itemRepoMock := &mocks.ItemRepositoryMock{}
+
+	userRepoMock := &mocks.UserRepositoryMock{}
+	transactorMock := &mocks.TransactorMock{}
+
+	folderSvc := NewFolderService(repoMock, vaultRepoMock, itemRepoMock, userRepoMock, transactorMock)
+
+	assert.Equal(t, repoMock, folderSvc.repository)
+	assert.Equal(t, vaultRepoMock, folderSvc.vaultRepository)
+	assert.Equal(t, itemRepoMock, folderSvc.itemRepository)
+	assert.Equal(t, userRepoMock, folderSvc.userRepository)
+	assert.Equal(t, transactorMock, folderSvc.transactor)
+}
+
+// folderTree mocks the folders of vault 1 from user 10: 1 > 2 > 3, and 4 at the root.
+func folderTree(ctx context.Context) (*mocks.FolderRepositoryMock, *mocks.VaultRepositoryMock) {
+	repoMock := &mocks.FolderRepositoryMock{}
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	one, two := uint(1), uint(2)
+
+	folders := []models.Folder{
+		{Model: gorm.Model{ID: 1}, Name: "Work", VaultID: 1},
+		{Model: gorm.Model{ID: 2}, Name: "Cloud", VaultID: 1, ParentID: &one},
+		{Model: gorm.Model{ID: 3}, Name: "AWS", VaultID: 1, ParentID: &two},
+		{Model: gorm.Model{ID: 4}, Name: "Personal", VaultID: 1},
+	}
+
+	vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: 10}, nil)
+	repoMock.On("FindByVaultID", ctx, uint(1)).Return(folders, nil)
+
+	for i := range folders {
+		folder := folders[i]
+		repoMock.On("FindByID", ctx, folder.ID).Return(&folder, nil)
+	}
+
+	repoMock.On("FindByID", ctx, mock.Anything).Return(&models.Folder{}, nil)
+
+	return repoMock, vaultRepoMock
+}
+
+func TestCreateFolder(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	parentID := uint(2)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock, vaultRepoMock := folderTree(ctx)
+		repoMock.On("Save", ctx, &models.Folder{Name: "GCP", VaultID: 1, ParentID: &parentID}).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Folder).ID = 5
+		})
+
+		// when
+		folderSvc := &folderService{repoMock, vaultRepoMock, &mocks.ItemRepositoryMock{}, revisionMock(2), transactionMock()}
+		actual, error := folderSvc.Create(ctx, 1, models.FolderInput{Name: "GCP", ParentID: &parentID})
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, uint(5), actual)
+	})
+
+	missingID := uint(9)
+
+	testCases := []struct {
+		name  string
+		ctx   context.Context
+		input models.FolderInput
+		err   string
+	}{
+		{"user not authenticated", context.TODO(), models.FolderInput{Name: "GCP"}, "user is not authenticated"},
+		{"name is required", ctx, models.FolderInput{Name: " "}, "name is required"},
+		{"parent not found", ctx, models.FolderInput{Name: "GCP", ParentID: &missingID}, "folder not found"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock, vaultRepoMock := folderTree(ctx)
+
+			// when
+			folderSvc := &folderService{repoMock, vaultRepoMock, &mocks.ItemRepositoryMock{}, revisionMock(2), transactionMock()}
+			actual, error := folderSvc.Create(tc.ctx, 1, tc.input)
+
+			// then
+			assert.Equal(t, uint(0), actual)
+			assert.Equal(t, tc.err, error.Error())
+
+			repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetAllFolders(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	// given
+	repoMock, vaultRepoMock := folderTree(ctx)
+
+	// when
+	folderSvc := &folderService{repoMock, vaultRepoMock, &mocks.ItemRepositoryMock{}, revisionMock(2), transactionMock()}
+	actual, error := folderSvc.GetAll(ctx, 1)
+
+	// then
+	one := uint(1)
+	assert.Nil(t, error)
+	assert.Len(t, actual, 4)
+	assert.Equal(t, models.FolderDetail{ID: 2, VaultID: 1, ParentID: &one, Name: "Cloud"}, actual[1])
+}
+
+func TestUpdateFolder(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	id := func(n uint) *uint { return &n }
+
+	testCases := []struct {
+		name   string
+		folder uint
+		input  models.FolderInput
+		err    string
+	}{
+		{"rename", 3, models.FolderInput{Name: "Amazon", ParentID: id(2)}, ""},
+		{"move to the root", 3, models.FolderInput{Name: "AWS"}, ""},
+		{"move to another branch", 2, models.FolderInput{Name: "Cloud", ParentID: id(4)}, ""},
+		{"move into itself", 2, models.FolderInput{Name: "Cloud", ParentID: id(2)}, "folder can't be moved into itself or its subfolders"},
+		{"move into a subfolder", 1, models.FolderInput{Name: "Work", ParentID: id(3)}, "folder can't be moved into itself or its subfolders"},
+		{"parent not found", 3, models.FolderInput{Name: "AWS", ParentID: id(9)}, "folder not found"},
+		{"folder not found", 9, models.FolderInput{Name: "AWS"}, "folder not found"},
+		{"name is required", 3, models.FolderInput{}, "name is required"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock, vaultRepoMock := folderTree(ctx)
+			repoMock.On("Save", ctx, mock.Anything)
+
+			// when
+			folderSvc := &folderService{repoMock, vaultRepoMock, &mocks.ItemRepositoryMock{}, revisionMock(2), transactionMock()}
+			error := folderSvc.Update(ctx, tc.folder, tc.input)
+
+			// then
+			if tc.err == "" {
+				assert.Nil(t, error)
+				repoMock.AssertCalled(t, "Save", ctx, mock.MatchedBy(func(f *models.Folder) bool {
+					return f.ID == tc.folder && f.Name == tc.input.Name && assert.ObjectsAreEqual(tc.input.ParentID, f.ParentID)
+				}))
+			} else {
+				assert.Equal(t, tc.err, error.Error())
+				repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+			}
+		})
+	}
+
+	t.Run("folder from another user", func(t *testing.T) {
+		// given
+		repoMock := &mocks.FolderRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(3)).Return(&models.Folder{Model: gorm.Model{ID: 3}, VaultID: 2}, nil)
+		vaultRepoMock.On("FindByID", ctx, uint(2)).Return(&models.Vault{Model: gorm.Model{ID: 2}, UserID: 99}, nil)
+
+		// when
+		folderSvc := &folderService{repoMock, vaultRepoMock, &mocks.ItemRepositoryMock{}, revisionMock(2), transactionMock()}
+		error := folderSvc.Update(ctx, 3, models.FolderInput{Name: "AWS"})
+
+		// then
+		assert.Equal(t, "folder not found", error.Error())
+	})
+
+	t.Run("checks for cycles once the revision is taken", func(t *testing.T) {
+		// given
+		repoMock := &mocks.FolderRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		userRepoMock := &mocks.UserRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		// taking the revision locks out other writes of the user, so folders
+		// read before it could be moved concurrently into a cycle
+		var calls []string
+		record := func(name string) func(mock.Arguments) {
+			return func(mock.Arguments) { calls = append(calls, name) }
+		}
+
+		transactorMock.On("WithinTransaction", ctx)
+		userRepoMock.On("NextRevision", ctx, uint(10)).Return(uint64(3), nil).Run(record("NextRevision"))
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: 10}, nil)
+		repoMock.On("FindByID", ctx, uint(2)).Return(&models.Folder{Model: gorm.Model{ID: 2}, Name: "Cloud", VaultID: 1}, nil).Run(record("FindByID"))
+		repoMock.On("FindByVaultID", ctx, uint(1)).Return([]models.Folder{
+			{Model: gorm.Model{ID: 2}, Name: "Cloud", VaultID: 1},
+			{Model: gorm.Model{ID: 4}, Name: "Personal", VaultID: 1},
+		}, nil).Run(record("FindByVaultID"))
+		repoMock.On("Save", ctx, mock.Anything).Run(record("Save"))
+
+		// when
+		folderSvc := &folderService{repoMock, vaultRepoMock, &mocks.ItemRepositoryMock{}, userRepoMock, transactorMock}
+		error := folderSvc.Update(ctx, 2, models.FolderInput{Name: "Cloud", ParentID: id(4)})
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []string{"NextRevision", "FindByID", "FindByVaultID", "Save"}, calls)
+		transactorMock.AssertExpectations(t)
+	})
+}
+
+func TestDeleteFolder(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	four := uint(4)
+
+	testCases := []struct {
+		name   string
+		folder uint
+		items  []models.Item
+		err    string
+	}{
+		{"success", 3, []models.Item{{Model: gorm.Model{ID: 5}, VaultID: 1, FolderID: &four}}, ""},
+		{"has subfolders", 2, []models.Item{}, "folder is not empty"},
+		{"has items", 4, []models.Item{{Model: gorm.Model{ID: 5}, VaultID: 1, FolderID: &four}}, "folder is not empty"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock, vaultRepoMock := folderTree(ctx)
+			itemRepoMock := &mocks.ItemRepositoryMock{}
+
+			itemRepoMock.On("FindByVaultIDs", ctx, []uint{1}).Return(tc.items, nil)
+			repoMock.On("Delete", ctx, tc.folder)
+
+			// when
+			folderSvc := &folderService{repoMock, vaultRepoMock, itemRepoMock, revisionMock(2), transactionMock()}
+			error := folderSvc.Delete(ctx, tc.folder)
+
+			// then
+			if tc.err == "" {
+				assert.Nil(t, error)
+				repoMock.AssertCalled(t, "Delete", ctx, tc.folder)
+			} else {
+				assert.Equal(t, tc.err, error.Error())
+				repoMock.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			}
+		})
+	}
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		repoMock, vaultRepoMock := folderTree(ctx)
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1}).Return([]models.Item{}, errors.New("error when finding items"))
+
+		// when
+		folderSvc := &folderService{repoMock, vaultRepoMock, itemRepoMock, revisionMock(2), transactionMock()}
+		error := folderSvc.Delete(ctx, 3)
+
+		// then
+		assert.Equal(t, "error when finding items", error.Error())
+	})
+}
+
+func TestSubfolderIDs(t *testing.T) {
+	one, two := uint(1), uint(2)
+
+	// given: a corrupted tree where 1 and 2 are each other's parent
+	folders := []models.Folder{
+		{Model: gorm.Model{ID: 1}, ParentID: &two},
+		{Model: gorm.Model{ID: 2}, ParentID: &one},
+		{Model: gorm.Model{ID: 3}, ParentID: &two},
+	}
+
+	// when
+	actual := subfolderIDs(folders, 1)
+
+	// then
+	assert.Equal(t, map[uint]bool{1: true, 2: true, 3: true}, actual)
+	assert.Nil(t, subfolderIDs(folders, 9))
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/importer"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+)
+
+type IImportService interface {
+	// Import imports an export from another password manager into the vaults of the authenticated user.
+	// Folders go to the vault with the same name, which is created when it doesn't exist yet.
+	// Items already stored, or repeated in the export, are skipped as duplicates.
+	// When dryRun is true nothing is saved and the returned report previews the import.
+	Import(ctx context.Context, format importer.Format, data []byte, dryRun bool) (*models.ImportReport, error)
+	// ImportKDBX imports a KeePass KDBX 4 database protected with password, the same way as Import.
+	ImportKDBX(ctx context.Context, data []byte, password string, dryRun bool) (*models.ImportReport, error)
+}
+
+type importService struct {
+	vaultRepository repositories.IVaultRepository
+	itemRepository  repositories.IItemRepository
+	userRepository  repositories.IUserRepository
+	tagRepository   repositories.ITagRepository
+	transactor      repositories.ITransactor
+}
+
+func NewImportService(
+	vaultRepository repositories.IVaultRepository,
+	itemRepository repositories.IItemRepository,
+	userRepository repositories.IUserRepository,
+	tagRepository repositories.ITagRepository,
+	transactor repositories.ITransactor,
+) *importService {
+	return &importService{vaultRepository, itemRepository, userRepository, tagRepository, transactor}
+}
+
+func (i *importService) Import(ctx context.Context, format importer.Format, data []byte, dryRun bool) (*models.ImportReport, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	result, err := importer.Parse(format, data)
+
+	if errors.Is(err, importer.ErrUnsupportedFormat) {
+		return nil, cerrors.BadRequestError("unsupported import format")
+	}
+
+	if err != nil {
+		return nil, cerrors.UnprocessableError(err.Error())
+	}
+
+	return i.importResult(ctx, userID, result, dryRun)
+}
+
+func (i *importService) ImportKDBX(ctx context.Context, data []byte, password string, dryRun bool) (*models.ImportReport, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(password) {
+		return nil, cerrors.BadRequestError("password is required")
+	}
+
+	result, err := importer.ParseKDBX(data, password)
+
+	if err != nil {
+		return nil, cerrors.UnprocessableError(err.Error())
+	}
+
+	return i.importResult(ctx, userID, result, dryRun)
+}
+
+// importResult saves the parsed records that are not duplicates in a single transaction.
+func (i *importService) importResult(ctx context.Context, userID uint, result *importer.Result, dryRun bool) (*models.ImportReport, error) {
+	vaults, err := i.vaultRepository.FindByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find all vaults by userId: %v", err.Error())
+		return nil, err
+	}
+
+	vaultIDs := map[string]uint{}
+	vaultNames := map[uint]string{}
+	for _, vault := range vaults {
+		vaultIDs[vault.Name] = vault.ID
+		vaultNames[vault.ID] = vault.Name
+	}
+
+	seen := map[string]bool{}
+
+	if len(vaults) > 0 {
+		ids := make([]uint, 0, len(vaults))
+		for _, vault := range vaults {
+			ids = append(ids, vault.ID)
+		}
+
+		items, err := i.itemRepository.FindByVaultIDs(ctx, ids)
+
+		if err != nil {
+			log.Printf("error while trying to find items by vaultIds: %v", err.Error())
+			return nil, err
+		}
+
+		for _, item := range items {
+			seen[duplicateKey(vaultNames[item.VaultID], item)] = true
+		}
+	}
+
+	report := &models.ImportReport{
+		DryRun:     dryRun,
+		NewVaults:  []string{},
+		Imported:   []models.ImportedItem{},
+		Duplicates: []models.ImportedItem{},
+		Errors:     []models.ImportError{},
+	}
+
+	for _, rowErr := range result.Errors {
+		report.Errors = append(report.Errors, models.ImportError{Row: rowErr.Row, Message: rowErr.Err.Error()})
+	}
+
+	records := []importer.Record{}
+	for _, record := range result.Records {
+		imported := models.ImportedItem{
+			Row:      record.Row,
+			Vault:    record.Vault,
+			Name:     record.Item.Name,
+			Username: record.Item.Username,
+			Url:      record.Item.Url,
+		}
+
+		key := duplicateKey(record.Vault, record.Item)
+
+		if seen[key] {
+			report.Duplicates = append(report.Duplicates, imported)
+			continue
+		}
+		seen[key] = true
+
+		if _, ok := vaultIDs[record.Vault]; !ok {
+			vaultIDs[record.Vault] = 0
+			report.NewVaults = append(report.NewVaults, record.Vault)
+		}
+
+		report.Imported = append(report.Imported, imported)
+		records = append(records, record)
+	}
+
+	if dryRun || len(records) == 0 {
+		return report, nil
+	}
+
+	err = withRevision(ctx, i.transactor, i.userRepository, userID, func(ctx context.Context, revision uint64) error {
+		for _, name := range report.NewVaults {
+			vault := models.Vault{Name: name, UserID: userID, CreatedRevision: revision, UpdatedRevision: revision}
+
+			if err := i.vaultRepository.Save(ctx, &vault); err != nil {
+				log.Printf("error while trying to save vault: %v", err.Error())
+				return err
+			}
+
+			vaultIDs[name] = vault.ID
+		}
+
+		for _, record := range records {
+			item := record.Item
+			item.VaultID = vaultIDs[record.Vault]
+			item.CreatedRevision = revision
+			item.UpdatedRevision = revision
+
+			tags, err := resolveTags(ctx, i.tagRepository, userID, tagNames(item.Tags))
+
+			if err != nil {
+				return err
+			}
+
+			item.Tags = tags
+
+			if err := i.itemRepository.Save(ctx, &item); err != nil {
+				log.Printf("error while trying to save item: %v", err.Error())
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// duplicateKey identifies an item by vault, name, username and URL, ignoring case.
+func duplicateKey(vault string, item models.Item) string {
+	url := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(item.Url)), "/")
+
+	return strings.Join([]string{
+		vault,
+		strings.ToLower(strings.TrimSpace(item.Name)),
+		strings.ToLower(strings.TrimSpace(item.Username)),
+		url,
+	}, "\x00")
+}
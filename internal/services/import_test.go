@@ -0,0 +1,261 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/importer"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/kdbx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestNewImportService(t *testing.T) {
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	itemRepoMock := &mocks.ItemRepositoryMock{}
+	userRepoMock := &mocks.UserRepositoryMock{}
+	tagRepoMock := &mocks.TagRepositoryMock{}
+	transactorMock := &mocks.TransactorMock{}
+
+	importSvc := NewImportService(vaultRepoMock, itemRepoMock, userRepoMock, tagRepoMock, transactorMock)
+
+	assert.Equal(t, vaultRepoMock, importSvc.vaultRepository)
+	assert.Equal(t, itemRepoMock, importSvc.itemRepository)
+	assert.Equal(t, userRepoMock, importSvc.userRepository)
+	assert.Equal(t, tagRepoMock, importSvc.tagRepository)
+	assert.Equal(t, transactorMock, importSvc.transactor)
+}
+
+func TestImport(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	export := []byte("url,username,password,totp,extra,name,grouping,fav\n" +
+		"https://mail.example.com,john,mail-pass,,,Mail,Work,0\n" +
+		"https://MAIL.example.com/,John,other-pass,,,mail,Work,0\n" +
+		"https://bank.example.com,john,bank-pass,,,Bank,Finance,0\n" +
+		"https://github.com,john,gh-pass,,,GitHub,Work,0\n" +
+		",,,,,,,0\n")
+
+	vaults := []models.Vault{{Model: gorm.Model{ID: 1}, Name: "Work", UserID: userID}}
+	items := []models.Item{{Model: gorm.Model{ID: 7}, Name: "GitHub", Url: "https://github.com", Username: "john", VaultID: 1}}
+
+	expectedReport := func(dryRun bool) *models.ImportReport {
+		return &models.ImportReport{
+			DryRun:    dryRun,
+			NewVaults: []string{"Finance"},
+			Imported: []models.ImportedItem{
+				{Row: 2, Vault: "Work", Name: "Mail", Username: "john", Url: "https://mail.example.com"},
+				{Row: 4, Vault: "Finance", Name: "Bank", Username: "john", Url: "https://bank.example.com"},
+			},
+			Duplicates: []models.ImportedItem{
+				{Row: 3, Vault: "Work", Name: "mail", Username: "John", Url: "https://MAIL.example.com/"},
+				{Row: 5, Vault: "Work", Name: "GitHub", Username: "john", Url: "https://github.com"},
+			},
+			Errors: []models.ImportError{{Row: 6, Message: "name is required"}},
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		userRepoMock := &mocks.UserRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1}).Return(items, nil)
+		transactorMock.On("WithinTransaction", ctx)
+		userRepoMock.On("NextRevision", ctx, userID).Return(uint64(4), nil)
+		vaultRepoMock.On("Save", ctx, &models.Vault{Name: "Finance", UserID: userID, CreatedRevision: 4, UpdatedRevision: 4}).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Vault).ID = 2
+		})
+		itemRepoMock.On("Save", ctx, &models.Item{Name: "Mail", Url: "https://mail.example.com", Username: "john", Password: "mail-pass", VaultID: 1, CreatedRevision: 4, UpdatedRevision: 4}).Return(nil)
+		itemRepoMock.On("Save", ctx, &models.Item{Name: "Bank", Url: "https://bank.example.com", Username: "john", Password: "bank-pass", VaultID: 2, CreatedRevision: 4, UpdatedRevision: 4}).Return(nil)
+
+		// when
+		importSvc := &importService{vaultRepoMock, itemRepoMock, userRepoMock, &mocks.TagRepositoryMock{}, transactorMock}
+		actual, error := importSvc.Import(ctx, importer.LastPass, export, false)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, expectedReport(false), actual)
+
+		vaultRepoMock.AssertExpectations(t)
+		itemRepoMock.AssertExpectations(t)
+		transactorMock.AssertExpectations(t)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		userRepoMock := &mocks.UserRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1}).Return(items, nil)
+
+		// when
+		importSvc := &importService{vaultRepoMock, itemRepoMock, userRepoMock, &mocks.TagRepositoryMock{}, transactorMock}
+		actual, error := importSvc.Import(ctx, importer.LastPass, export, true)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, expectedReport(true), actual)
+
+		vaultRepoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		itemRepoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		transactorMock.AssertNotCalled(t, "WithinTransaction", mock.Anything)
+	})
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// when
+		importSvc := &importService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.TransactorMock{}}
+		actual, error := importSvc.Import(context.TODO(), importer.LastPass, export, false)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "user is not authenticated", error.Error())
+	})
+
+	invalid := []struct {
+		name   string
+		format importer.Format
+		data   string
+		err    string
+	}{
+		{"unsupported format", importer.Format("dashlane"), "", "unsupported import format"},
+		{"invalid export", importer.Bitwarden, "{", "invalid bitwarden export: unexpected end of JSON input"},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			importSvc := &importService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.TransactorMock{}}
+			actual, error := importSvc.Import(ctx, tc.format, []byte(tc.data), false)
+
+			// then
+			assert.Nil(t, actual)
+			assert.Equal(t, tc.err, error.Error())
+		})
+	}
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		userRepoMock := &mocks.UserRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1}).Return(items, nil)
+		transactorMock.On("WithinTransaction", ctx)
+		userRepoMock.On("NextRevision", ctx, userID).Return(uint64(4), nil)
+		vaultRepoMock.On("Save", ctx, mock.Anything).Return(nil)
+		itemRepoMock.On("Save", ctx, mock.Anything).Return(errors.New("error when saving item"))
+
+		// when
+		importSvc := &importService{vaultRepoMock, itemRepoMock, userRepoMock, &mocks.TagRepositoryMock{}, transactorMock}
+		actual, error := importSvc.Import(ctx, importer.LastPass, export, false)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "error when saving item", error.Error())
+
+		itemRepoMock.AssertNumberOfCalls(t, "Save", 1)
+	})
+}
+
+func TestImportKDBX(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	db := &kdbx.Database{
+		Name: "Personal",
+		Root: kdbx.Group{
+			Name: "Personal",
+			Entries: []kdbx.Entry{{
+				Title:    "Bank",
+				UserName: "john",
+				Password: "bank-pass",
+				URL:      "https://bank.example.com",
+				Fields:   []kdbx.Field{{Key: "otp", Value: "JBSWY3DP", Protected: true}, {Key: "SSH Key", Value: "ssh-key", Protected: true}},
+				Tags:     []string{"finance"},
+			}},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, kdbx.Write(buf, db, kdbx.NewPasswordKey("master"), testKDBXSettings))
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		userRepoMock := &mocks.UserRepositoryMock{}
+		tagRepoMock := &mocks.TagRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		finance := models.Tag{Model: gorm.Model{ID: 7}, Name: "finance", UserID: userID}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return([]models.Vault{}, nil)
+		transactorMock.On("WithinTransaction", ctx)
+		userRepoMock.On("NextRevision", ctx, userID).Return(uint64(4), nil)
+		tagRepoMock.On("FindByNameAndUserID", ctx, "finance", userID).Return(&finance, nil)
+		vaultRepoMock.On("Save", ctx, &models.Vault{Name: "Personal", UserID: userID, CreatedRevision: 4, UpdatedRevision: 4}).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Vault).ID = 3
+		})
+		itemRepoMock.On("Save", ctx, &models.Item{
+			Name:            "Bank",
+			Url:             "https://bank.example.com",
+			Username:        "john",
+			Password:        "bank-pass",
+			SSHKey:          "ssh-key",
+			TOTP:            "JBSWY3DP",
+			VaultID:         3,
+			Tags:            []models.Tag{finance},
+			CreatedRevision: 4,
+			UpdatedRevision: 4,
+		}).Return(nil)
+
+		// when
+		importSvc := &importService{vaultRepoMock, itemRepoMock, userRepoMock, tagRepoMock, transactorMock}
+		actual, error := importSvc.ImportKDBX(ctx, buf.Bytes(), "master", false)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []string{"Personal"}, actual.NewVaults)
+		assert.Len(t, actual.Imported, 1)
+
+		vaultRepoMock.AssertExpectations(t)
+		itemRepoMock.AssertExpectations(t)
+		tagRepoMock.AssertExpectations(t)
+		transactorMock.AssertExpectations(t)
+	})
+
+	testCases := []struct {
+		name     string
+		password string
+		err      string
+	}{
+		{"password is required", "", "password is required"},
+		{"wrong password", "wrong", "invalid kdbx password"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			importSvc := &importService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.TransactorMock{}}
+			actual, error := importSvc.ImportKDBX(ctx, buf.Bytes(), tc.password, false)
+
+			// then
+			assert.Nil(t, actual)
+			assert.Equal(t, tc.err, error.Error())
+		})
+	}
+}
@@ -0,0 +1,523 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/totp"
+	"github.com/edgardjr92/gopass/pkg/urlmatch"
+	"golang.org/x/crypto/ssh"
+)
+
+type IItemService interface {
+	// Create creates a new item in a vault.
+	// It returns the ID of the newly created item.
+	Create(ctx context.Context, vaultID uint, input models.ItemInput) (uint, error)
+	// Get returns an item.
+	Get(ctx context.Context, id uint) (*models.ItemDetail, error)
+	// GetAll returns the items from a vault selected by the filter.
+	GetAll(ctx context.Context, vaultID uint, filter models.ItemFilter) ([]models.ItemDetail, error)
+	// Update replaces the fields of an item, if it is still at the revision of the input.
+	Update(ctx context.Context, id uint, input models.ItemInput) error
+	// Delete deletes an item.
+	Delete(ctx context.Context, id uint) error
+	// SetFavorite adds an item to the favorites of the user, or removes it.
+	SetFavorite(ctx context.Context, id uint, favorite bool) error
+}
+
+type itemService struct {
+	repository         repositories.IItemRepository
+	vaultRepository    repositories.IVaultRepository
+	tagRepository      repositories.ITagRepository
+	folderRepository   repositories.IFolderRepository
+	favoriteRepository repositories.IFavoriteRepository
+	userRepository     repositories.IUserRepository
+	transactor         repositories.ITransactor
+}
+
+func NewItemService(
+	repository repositories.IItemRepository,
+	vaultRepository repositories.IVaultRepository,
+	tagRepository repositories.ITagRepository,
+	folderRepository repositories.IFolderRepository,
+	favoriteRepository repositories.IFavoriteRepository,
+	userRepository repositories.IUserRepository,
+	transactor repositories.ITransactor,
+) *itemService {
+	return &itemService{repository, vaultRepository, tagRepository, folderRepository, favoriteRepository, userRepository, transactor}
+}
+
+func (i *itemService) Create(ctx context.Context, vaultID uint, input models.ItemInput) (uint, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return 0, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(input.Name) {
+		return 0, cerrors.BadRequestError("name is required")
+	}
+
+	if err := validateSSHKey(input); err != nil {
+		return 0, err
+	}
+
+	if err := validateTOTP(input); err != nil {
+		return 0, err
+	}
+
+	if err := validateURLMatch(input); err != nil {
+		return 0, err
+	}
+
+	if _, err := findUserVault(ctx, i.vaultRepository, userID, vaultID); err != nil {
+		return 0, err
+	}
+
+	if _, err := findVaultFolder(ctx, i.folderRepository, vaultID, input.FolderID); err != nil {
+		return 0, err
+	}
+
+	tags, err := resolveTags(ctx, i.tagRepository, userID, input.Tags)
+
+	if err != nil {
+		return 0, err
+	}
+
+	newItem := models.Item{
+		Name:     input.Name,
+		Url:      input.Url,
+		UrlMatch: input.UrlMatch,
+		Username: input.Username,
+		Password: input.Password,
+		Notes:    input.Notes,
+		SSHKey:   input.SSHKey,
+		TOTP:     input.TOTP,
+		VaultID:  vaultID,
+		FolderID: input.FolderID,
+		Tags:     tags,
+	}
+
+	err = withRevision(ctx, i.transactor, i.userRepository, userID, func(ctx context.Context, revision uint64) error {
+		newItem.CreatedRevision = revision
+		newItem.UpdatedRevision = revision
+
+		if err := i.repository.Save(ctx, &newItem); err != nil {
+			log.Printf("error while trying to save item: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newItem.ID, nil
+}
+
+func (i *itemService) Get(ctx context.Context, id uint) (*models.ItemDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	item, err := i.findItem(ctx, userID, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	favorites, err := favoriteItemIDs(ctx, i.favoriteRepository, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	detail := toItemDetail(*item)
+	detail.Favorite = favorites[item.ID]
+
+	return &detail, nil
+}
+
+func (i *itemService) GetAll(ctx context.Context, vaultID uint, filter models.ItemFilter) ([]models.ItemDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return []models.ItemDetail{}, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if _, err := findUserVault(ctx, i.vaultRepository, userID, vaultID); err != nil {
+		return []models.ItemDetail{}, err
+	}
+
+	var folderIDs map[uint]bool
+
+	if filter.FolderID != 0 {
+		folders, err := i.folderRepository.FindByVaultID(ctx, vaultID)
+
+		if err != nil {
+			log.Printf("error while trying to find folders by vaultId: %v", err.Error())
+			return []models.ItemDetail{}, err
+		}
+
+		if folderIDs = subfolderIDs(folders, filter.FolderID); folderIDs == nil {
+			return []models.ItemDetail{}, cerrors.NotFoundError("folder not found")
+		}
+	}
+
+	items, err := i.repository.FindByVaultIDs(ctx, []uint{vaultID})
+
+	if err != nil {
+		log.Printf("error while trying to find items by vaultIds: %v", err.Error())
+		return []models.ItemDetail{}, err
+	}
+
+	favorites, err := favoriteItemIDs(ctx, i.favoriteRepository, userID)
+
+	if err != nil {
+		return []models.ItemDetail{}, err
+	}
+
+	details := []models.ItemDetail{}
+
+	for _, item := range items {
+		if folderIDs != nil && (item.FolderID == nil || !folderIDs[*item.FolderID]) {
+			continue
+		}
+
+		if filter.Tag != "" && !hasTag(item, filter.Tag) {
+			continue
+		}
+
+		if filter.Favorite && !favorites[item.ID] {
+			continue
+		}
+
+		detail := toItemListing(item)
+		detail.Favorite = favorites[item.ID]
+		details = append(details, detail)
+	}
+
+	return details, nil
+}
+
+func (i *itemService) Update(ctx context.Context, id uint, input models.ItemInput) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(input.Name) {
+		return cerrors.BadRequestError("name is required")
+	}
+
+	if err := validateSSHKey(input); err != nil {
+		return err
+	}
+
+	if err := validateTOTP(input); err != nil {
+		return err
+	}
+
+	if err := validateURLMatch(input); err != nil {
+		return err
+	}
+
+	if input.Revision == 0 {
+		return cerrors.PreconditionRequiredError("revision is required")
+	}
+
+	// The item is read once the revision of the user is taken, which locks
+	// out other writes of the user, so two updates can't both pass the check.
+	return withRevision(ctx, i.transactor, i.userRepository, userID, func(ctx context.Context, revision uint64) error {
+		item, err := i.findItem(ctx, userID, id)
+
+		if err != nil {
+			return err
+		}
+
+		if err := checkRevision("item", item.UpdatedRevision, input.Revision); err != nil {
+			return err
+		}
+
+		if _, err := findVaultFolder(ctx, i.folderRepository, item.VaultID, input.FolderID); err != nil {
+			return err
+		}
+
+		tags, err := resolveTags(ctx, i.tagRepository, userID, input.Tags)
+
+		if err != nil {
+			return err
+		}
+
+		item.Name = input.Name
+		item.Url = input.Url
+		item.UrlMatch = input.UrlMatch
+		item.Username = input.Username
+		item.Password = input.Password
+		item.Notes = input.Notes
+		item.SSHKey = input.SSHKey
+		item.TOTP = input.TOTP
+		item.FolderID = input.FolderID
+		item.Tags = tags
+		item.UpdatedRevision = revision
+
+		if err := i.repository.Save(ctx, item); err != nil {
+			log.Printf("error while trying to save item: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (i *itemService) Delete(ctx context.Context, id uint) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	item, err := i.findItem(ctx, userID, id)
+
+	if err != nil {
+		return err
+	}
+
+	return withRevision(ctx, i.transactor, i.userRepository, userID, func(ctx context.Context, revision uint64) error {
+		if err := i.repository.Delete(ctx, item.ID, revision); err != nil {
+			log.Printf("error while trying to delete item: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (i *itemService) SetFavorite(ctx context.Context, id uint, favorite bool) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	// Favorites are synced with the items, so changing one is a write to the item.
+	// As in Update, the item is read once the revision of the user is taken, so
+	// saving it can't revert an update made in the meantime.
+	return withRevision(ctx, i.transactor, i.userRepository, userID, func(ctx context.Context, revision uint64) error {
+		item, err := i.findItem(ctx, userID, id)
+
+		if err != nil {
+			return err
+		}
+
+		if favorite {
+			if err := i.favoriteRepository.Save(ctx, &models.Favorite{UserID: userID, ItemID: item.ID}); err != nil {
+				log.Printf("error while trying to save favorite: %v", err.Error())
+				return err
+			}
+		} else if err := i.favoriteRepository.Delete(ctx, userID, item.ID); err != nil {
+			log.Printf("error while trying to delete favorite: %v", err.Error())
+			return err
+		}
+
+		item.UpdatedRevision = revision
+
+		if err := i.repository.Save(ctx, item); err != nil {
+			log.Printf("error while trying to save item: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+}
+
+// findItem finds an item by ID, or returns a not found error if it isn't stored in a vault from the user.
+func (i *itemService) findItem(ctx context.Context, userID, id uint) (*models.Item, error) {
+	item, err := i.repository.FindByID(ctx, id)
+
+	if err != nil {
+		log.Printf("error while trying to find an item by id: %v", err.Error())
+		return nil, err
+	}
+
+	if item.ID == 0 {
+		return nil, cerrors.NotFoundError("item not found")
+	}
+
+	vault, err := i.vaultRepository.FindByID(ctx, item.VaultID)
+
+	if err != nil {
+		log.Printf("error while trying to find a vault by id: %v", err.Error())
+		return nil, err
+	}
+
+	if vault.ID == 0 || vault.UserID != userID {
+		return nil, cerrors.NotFoundError("item not found")
+	}
+
+	return item, nil
+}
+
+// resolveTags returns the tags of the user with the given names, creating the missing ones.
+// Blank and repeated names are ignored.
+func resolveTags(ctx context.Context, repository repositories.ITagRepository, userID uint, names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+
+	seen := map[string]bool{}
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+
+		if name == "" || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+
+		tag, err := repository.FindByNameAndUserID(ctx, name, userID)
+
+		if err != nil {
+			log.Printf("error while trying to find a tag by name,userId: %v", err.Error())
+			return nil, err
+		}
+
+		if tag.ID == 0 {
+			tag = &models.Tag{Name: name, UserID: userID}
+
+			if err := repository.Save(ctx, tag); err != nil {
+				log.Printf("error while trying to save tag: %v", err.Error())
+				return nil, err
+			}
+		}
+
+		tags = append(tags, *tag)
+	}
+
+	return tags, nil
+}
+
+// favoriteItemIDs returns the set of the favorite items of a user.
+func favoriteItemIDs(ctx context.Context, repository repositories.IFavoriteRepository, userID uint) (map[uint]bool, error) {
+	ids, err := repository.FindItemIDsByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find favorites by userId: %v", err.Error())
+		return nil, err
+	}
+
+	favorites := make(map[uint]bool, len(ids))
+
+	for _, id := range ids {
+		favorites[id] = true
+	}
+
+	return favorites, nil
+}
+
+func hasTag(item models.Item, name string) bool {
+	for _, tag := range item.Tags {
+		if tag.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateSSHKey checks that the SSH key of an item, if any, is a private key
+// that can be decrypted with the item password when it has a passphrase.
+func validateSSHKey(input models.ItemInput) error {
+	if utils.IsBlank(input.SSHKey) {
+		return nil
+	}
+
+	_, err := ssh.ParseRawPrivateKey([]byte(input.SSHKey))
+
+	var missing *ssh.PassphraseMissingError
+
+	if errors.As(err, &missing) {
+		_, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(input.SSHKey), []byte(input.Password))
+	}
+
+	if err != nil {
+		return cerrors.BadRequestError("invalid ssh key")
+	}
+
+	return nil
+}
+
+// validateTOTP checks that the TOTP of an item, if any, is a valid otpauth URI.
+func validateTOTP(input models.ItemInput) error {
+	if utils.IsBlank(input.TOTP) {
+		return nil
+	}
+
+	if _, err := totp.Parse(input.TOTP); err != nil {
+		return cerrors.BadRequestError("invalid totp uri")
+	}
+
+	return nil
+}
+
+// validateURLMatch checks that the url match strategy of an item is known,
+// and that the url is a valid regular expression for the regex strategy.
+func validateURLMatch(input models.ItemInput) error {
+	switch err := urlmatch.Validate(input.Url, urlmatch.Strategy(input.UrlMatch)); err {
+	case nil:
+		return nil
+	case urlmatch.ErrInvalidRegex:
+		return cerrors.BadRequestError("invalid url regex")
+	default:
+		return cerrors.BadRequestError("invalid url match")
+	}
+}
+
+// toItemListing converts an item for listings, which never hold its TOTP secret.
+func toItemListing(item models.Item) models.ItemDetail {
+	detail := toItemDetail(item)
+	detail.TOTP = ""
+
+	return detail
+}
+
+func toItemDetail(item models.Item) models.ItemDetail {
+	return models.ItemDetail{
+		ID:        item.ID,
+		VaultID:   item.VaultID,
+		Name:      item.Name,
+		Url:       item.Url,
+		UrlMatch:  item.UrlMatch,
+		Username:  item.Username,
+		Password:  item.Password,
+		Notes:     item.Notes,
+		SSHKey:    item.SSHKey,
+		TOTP:      item.TOTP,
+		HasTOTP:   item.TOTP != "",
+		FolderID:  item.FolderID,
+		Tags:      tagNames(item.Tags),
+		Revision:  item.UpdatedRevision,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
+
+// tagNames returns the names of the tags, nil when there are none.
+func tagNames(tags []models.Tag) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	return utils.Map(tags, func(t models.Tag) string { return t.Name })
+}
@@ -0,0 +1,600 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestNewItemService(t *testing.T) {
+	repoMock := &mocks.ItemRepositoryMock{}
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	tagRepoMock := &mocks.TagRepositoryMock{}
+	folderRepoMock := &mocks.FolderRepositoryMock{}
+	favoriteRepoMock := &mocks.FavoriteRepositoryMock{}
+	userRepoMock := &mocks.UserRepositoryMock{}
+	transactorMock := &mocks.TransactorMock{}
+
+	itemSvc := NewItemService(repoMock, vaultRepoMock, tagRepoMock, folderRepoMock, favoriteRepoMock, userRepoMock, transactorMock)
+
+	assert.Equal(t, repoMock, itemSvc.repository)
+	assert.Equal(t, vaultRepoMock, itemSvc.vaultRepository)
+	assert.Equal(t, tagRepoMock, itemSvc.tagRepository)
+	assert.Equal(t, folderRepoMock, itemSvc.folderRepository)
+	assert.Equal(t, favoriteRepoMock, itemSvc.favoriteRepository)
+	assert.Equal(t, userRepoMock, itemSvc.userRepository)
+	assert.Equal(t, transactorMock, itemSvc.transactor)
+}
+
+// newTestItemService returns an item service without tags, folders or favorites,
+// numbering writes with revision 2.
+func newTestItemService(repoMock *mocks.ItemRepositoryMock, vaultRepoMock *mocks.VaultRepositoryMock) *itemService {
+	favoriteRepoMock := &mocks.FavoriteRepositoryMock{}
+	favoriteRepoMock.On("FindItemIDsByUserID", mock.Anything, mock.Anything).Return([]uint{}, nil).Maybe()
+
+	return &itemService{repoMock, vaultRepoMock, &mocks.TagRepositoryMock{}, &mocks.FolderRepositoryMock{}, favoriteRepoMock, revisionMock(2), transactionMock()}
+}
+
+func TestCreateItem(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+	input := models.ItemInput{Name: "GitHub", Url: "https://github.com", Username: "octocat", Password: "secret"}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		repoMock.On("Save", ctx, &models.Item{
+			Name: "GitHub", Url: "https://github.com", Username: "octocat", Password: "secret", VaultID: 1,
+			CreatedRevision: 2, UpdatedRevision: 2,
+		}).Run(func(args mock.Arguments) {
+			item := args.Get(1).(*models.Item)
+			item.ID = uint(100)
+		})
+
+		// when
+		itemSvc := newTestItemService(repoMock, vaultRepoMock)
+		actual, error := itemSvc.Create(ctx, 1, input)
+
+		// then
+		assert.Equal(t, uint(100), actual)
+		assert.Nil(t, error)
+
+		repoMock.AssertExpectations(t)
+		vaultRepoMock.AssertExpectations(t)
+	})
+
+	testCases := []struct {
+		name  string
+		ctx   context.Context
+		input models.ItemInput
+		vault *models.Vault
+		err   string
+	}{
+		{"user not authenticated", context.TODO(), input, &models.Vault{}, "user is not authenticated"},
+		{"name is required", ctx, models.ItemInput{Name: " "}, &models.Vault{}, "name is required"},
+		{"invalid ssh key", ctx, models.ItemInput{Name: "deploy", SSHKey: "not a key"}, &models.Vault{}, "invalid ssh key"},
+		{"invalid totp uri", ctx, models.ItemInput{Name: "GitHub", TOTP: "otpauth://totp/alice"}, &models.Vault{}, "invalid totp uri"},
+		{"invalid url match", ctx, models.ItemInput{Name: "GitHub", Url: "github.com", UrlMatch: "fuzzy"}, &models.Vault{}, "invalid url match"},
+		{"invalid url regex", ctx, models.ItemInput{Name: "GitHub", Url: "(github", UrlMatch: "regex"}, &models.Vault{}, "invalid url regex"},
+		{"vault not found", ctx, input, &models.Vault{}, "vault not found"},
+		{"vault from another user", ctx, input, &models.Vault{Model: gorm.Model{ID: 1}, UserID: 99}, "vault not found"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock := &mocks.ItemRepositoryMock{}
+			vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+			vaultRepoMock.On("FindByID", ctx, uint(1)).Return(tc.vault, nil)
+
+			// when
+			itemSvc := newTestItemService(repoMock, vaultRepoMock)
+			actual, error := itemSvc.Create(tc.ctx, 1, tc.input)
+
+			// then
+			assert.Equal(t, uint(0), actual)
+			assert.Equal(t, tc.err, error.Error())
+
+			repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestValidateSSHKey(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(priv)
+	plain := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecDER, _ := x509.MarshalECPrivateKey(key)
+	// Legacy encrypted PEM keys are deprecated but still found in the wild.
+	block, _ := x509.EncryptPEMBlock(rand.Reader, "EC PRIVATE KEY", ecDER, []byte("passphrase"), x509.PEMCipherAES256)
+	encrypted := string(pem.EncodeToMemory(block))
+
+	testCases := []struct {
+		name  string
+		input models.ItemInput
+		err   string
+	}{
+		{"no key", models.ItemInput{Name: "GitHub"}, ""},
+		{"plain key", models.ItemInput{Name: "deploy", SSHKey: plain}, ""},
+		{"encrypted key", models.ItemInput{Name: "deploy", SSHKey: encrypted, Password: "passphrase"}, ""},
+		{"wrong passphrase", models.ItemInput{Name: "deploy", SSHKey: encrypted, Password: "wrong"}, "invalid ssh key"},
+		{"not a key", models.ItemInput{Name: "deploy", SSHKey: "ssh-ed25519 AAAA"}, "invalid ssh key"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			error := validateSSHKey(tc.input)
+
+			// then
+			if tc.err == "" {
+				assert.Nil(t, error)
+			} else {
+				assert.Equal(t, tc.err, error.Error())
+			}
+		})
+	}
+}
+
+func TestGetItem(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+	now := time.Date(2023, 5, 6, 10, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{
+			Model: gorm.Model{ID: 5, CreatedAt: now, UpdatedAt: now}, Name: "GitHub", Password: "secret", TOTP: "steam://JBSWY3DPEHPK3PXP", VaultID: 1,
+		}, nil)
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+
+		// when
+		itemSvc := newTestItemService(repoMock, vaultRepoMock)
+		actual, error := itemSvc.Get(ctx, 5)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, &models.ItemDetail{
+			ID: 5, VaultID: 1, Name: "GitHub", Password: "secret", TOTP: "steam://JBSWY3DPEHPK3PXP", HasTOTP: true, CreatedAt: now, UpdatedAt: now,
+		}, actual)
+	})
+
+	testCases := []struct {
+		name  string
+		item  *models.Item
+		vault *models.Vault
+		err   string
+	}{
+		{"item not found", &models.Item{}, &models.Vault{}, "item not found"},
+		{"item from another user", &models.Item{Model: gorm.Model{ID: 5}, VaultID: 1}, &models.Vault{Model: gorm.Model{ID: 1}, UserID: 99}, "item not found"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock := &mocks.ItemRepositoryMock{}
+			vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+			repoMock.On("FindByID", ctx, uint(5)).Return(tc.item, nil)
+			vaultRepoMock.On("FindByID", ctx, uint(1)).Return(tc.vault, nil)
+
+			// when
+			itemSvc := newTestItemService(repoMock, vaultRepoMock)
+			actual, error := itemSvc.Get(ctx, 5)
+
+			// then
+			assert.Nil(t, actual)
+			assert.Equal(t, tc.err, error.Error())
+		})
+	}
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{}, errors.New("error when finding item"))
+
+		// when
+		itemSvc := newTestItemService(repoMock, &mocks.VaultRepositoryMock{})
+		actual, error := itemSvc.Get(ctx, 5)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "error when finding item", error.Error())
+	})
+}
+
+func TestGetAllItems(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		repoMock.On("FindByVaultIDs", ctx, []uint{1}).Return([]models.Item{
+			{Model: gorm.Model{ID: 5}, Name: "GitHub", TOTP: "steam://JBSWY3DPEHPK3PXP", VaultID: 1},
+			{Model: gorm.Model{ID: 6}, Name: "GitLab", VaultID: 1},
+		}, nil)
+
+		// when
+		itemSvc := newTestItemService(repoMock, vaultRepoMock)
+		actual, error := itemSvc.GetAll(ctx, 1, models.ItemFilter{})
+
+		// then: listings only tell whether an item has a totp
+		assert.Nil(t, error)
+		assert.Equal(t, []models.ItemDetail{
+			{ID: 5, VaultID: 1, Name: "GitHub", HasTOTP: true},
+			{ID: 6, VaultID: 1, Name: "GitLab"},
+		}, actual)
+	})
+
+	t.Run("vault not found", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{}, nil)
+
+		// when
+		itemSvc := newTestItemService(&mocks.ItemRepositoryMock{}, vaultRepoMock)
+		actual, error := itemSvc.GetAll(ctx, 1, models.ItemFilter{})
+
+		// then
+		assert.Equal(t, []models.ItemDetail{}, actual)
+		assert.Equal(t, "vault not found", error.Error())
+	})
+}
+
+func TestUpdateItem(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{Model: gorm.Model{ID: 5}, Name: "GitHub", Notes: "old", VaultID: 1, UpdatedRevision: 1}, nil)
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		repoMock.On("Save", ctx, &models.Item{Model: gorm.Model{ID: 5}, Name: "GitHub", Password: "new-secret", VaultID: 1, UpdatedRevision: 2})
+
+		// when
+		itemSvc := newTestItemService(repoMock, vaultRepoMock)
+		error := itemSvc.Update(ctx, 5, models.ItemInput{Name: "GitHub", Password: "new-secret", Revision: 1})
+
+		// then
+		assert.Nil(t, error)
+
+		repoMock.AssertExpectations(t)
+	})
+
+	t.Run("name is required", func(t *testing.T) {
+		// when
+		itemSvc := newTestItemService(&mocks.ItemRepositoryMock{}, &mocks.VaultRepositoryMock{})
+		error := itemSvc.Update(ctx, 5, models.ItemInput{})
+
+		// then
+		assert.Equal(t, "name is required", error.Error())
+	})
+
+	t.Run("revision is required", func(t *testing.T) {
+		// when
+		itemSvc := newTestItemService(&mocks.ItemRepositoryMock{}, &mocks.VaultRepositoryMock{})
+		error := itemSvc.Update(ctx, 5, models.ItemInput{Name: "GitHub"})
+
+		// then
+		assert.Equal(t, "revision is required", error.Error())
+	})
+
+	t.Run("item changed since revision", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{Model: gorm.Model{ID: 5}, VaultID: 1, UpdatedRevision: 3}, nil)
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+
+		// when
+		itemSvc := newTestItemService(repoMock, vaultRepoMock)
+		error := itemSvc.Update(ctx, 5, models.ItemInput{Name: "GitHub", Revision: 1})
+
+		// then
+		assert.Equal(t, cerrors.PreconditionFailedError("item was changed at revision 3, since revision 1"), error)
+
+		repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{Model: gorm.Model{ID: 5}, VaultID: 1, UpdatedRevision: 1}, nil)
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		repoMock.On("Save", ctx, mock.Anything).Return(errors.New("error when saving item"))
+
+		// when
+		itemSvc := newTestItemService(repoMock, vaultRepoMock)
+		error := itemSvc.Update(ctx, 5, models.ItemInput{Name: "GitHub", Revision: 1})
+
+		// then
+		assert.Equal(t, "error when saving item", error.Error())
+	})
+}
+
+func TestDeleteItem(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{Model: gorm.Model{ID: 5}, VaultID: 1}, nil)
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		repoMock.On("Delete", ctx, uint(5), uint64(2))
+
+		// when
+		itemSvc := newTestItemService(repoMock, vaultRepoMock)
+		error := itemSvc.Delete(ctx, 5)
+
+		// then
+		assert.Nil(t, error)
+
+		repoMock.AssertExpectations(t)
+	})
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// when
+		itemSvc := newTestItemService(&mocks.ItemRepositoryMock{}, &mocks.VaultRepositoryMock{})
+		error := itemSvc.Delete(context.TODO(), 5)
+
+		// then
+		assert.Equal(t, "user is not authenticated", error.Error())
+	})
+
+	t.Run("item not found", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{}, nil)
+
+		// when
+		itemSvc := newTestItemService(repoMock, &mocks.VaultRepositoryMock{})
+		error := itemSvc.Delete(ctx, 5)
+
+		// then
+		assert.Equal(t, "item not found", error.Error())
+
+		repoMock.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestItemTagsAndFolders(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+	folderID := uint(3)
+
+	t.Run("create resolves tags by name", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		tagRepoMock := &mocks.TagRepositoryMock{}
+		folderRepoMock := &mocks.FolderRepositoryMock{}
+
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		folderRepoMock.On("FindByID", ctx, folderID).Return(&models.Folder{Model: gorm.Model{ID: 3}, VaultID: 1}, nil)
+		tagRepoMock.On("FindByNameAndUserID", ctx, "work", userID).Return(&models.Tag{Model: gorm.Model{ID: 7}, Name: "work", UserID: userID}, nil)
+		tagRepoMock.On("FindByNameAndUserID", ctx, "dev", userID).Return(&models.Tag{}, nil)
+		tagRepoMock.On("Save", ctx, &models.Tag{Name: "dev", UserID: userID}).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Tag).ID = 8
+		})
+		repoMock.On("Save", ctx, &models.Item{
+			Name: "GitHub", VaultID: 1, FolderID: &folderID,
+			Tags: []models.Tag{
+				{Model: gorm.Model{ID: 7}, Name: "work", UserID: userID},
+				{Model: gorm.Model{ID: 8}, Name: "dev", UserID: userID},
+			},
+			CreatedRevision: 2, UpdatedRevision: 2,
+		})
+
+		// when
+		itemSvc := &itemService{repoMock, vaultRepoMock, tagRepoMock, folderRepoMock, &mocks.FavoriteRepositoryMock{}, revisionMock(2), transactionMock()}
+		_, error := itemSvc.Create(ctx, 1, models.ItemInput{Name: "GitHub", FolderID: &folderID, Tags: []string{"work", " dev ", "work", ""}})
+
+		// then
+		assert.Nil(t, error)
+
+		repoMock.AssertExpectations(t)
+		tagRepoMock.AssertExpectations(t)
+	})
+
+	t.Run("folder from another vault", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		folderRepoMock := &mocks.FolderRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{Model: gorm.Model{ID: 5}, VaultID: 1, UpdatedRevision: 1}, nil)
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		folderRepoMock.On("FindByID", ctx, folderID).Return(&models.Folder{Model: gorm.Model{ID: 3}, VaultID: 2}, nil)
+
+		// when
+		itemSvc := &itemService{repoMock, vaultRepoMock, &mocks.TagRepositoryMock{}, folderRepoMock, &mocks.FavoriteRepositoryMock{}, revisionMock(2), transactionMock()}
+		error := itemSvc.Update(ctx, 5, models.ItemInput{Name: "GitHub", FolderID: &folderID, Revision: 1})
+
+		// then
+		assert.Equal(t, "folder not found", error.Error())
+
+		repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetAllItemsFilter(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+	root, child, other := uint(1), uint(2), uint(3)
+
+	testCases := []struct {
+		name   string
+		filter models.ItemFilter
+		ids    []uint
+	}{
+		{"no filter", models.ItemFilter{}, []uint{5, 6, 7, 8}},
+		{"folder and subfolders", models.ItemFilter{FolderID: root}, []uint{5, 6}},
+		{"subfolder", models.ItemFilter{FolderID: child}, []uint{6}},
+		{"tag", models.ItemFilter{Tag: "work"}, []uint{5, 7}},
+		{"favorite", models.ItemFilter{Favorite: true}, []uint{6, 7}},
+		{"all filters", models.ItemFilter{FolderID: root, Tag: "work", Favorite: true}, []uint{}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock := &mocks.ItemRepositoryMock{}
+			vaultRepoMock := &mocks.VaultRepositoryMock{}
+			folderRepoMock := &mocks.FolderRepositoryMock{}
+			favoriteRepoMock := &mocks.FavoriteRepositoryMock{}
+
+			vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+			folderRepoMock.On("FindByVaultID", ctx, uint(1)).Return([]models.Folder{
+				{Model: gorm.Model{ID: root}, VaultID: 1},
+				{Model: gorm.Model{ID: child}, VaultID: 1, ParentID: &root},
+				{Model: gorm.Model{ID: other}, VaultID: 1},
+			}, nil)
+			repoMock.On("FindByVaultIDs", ctx, []uint{1}).Return([]models.Item{
+				{Model: gorm.Model{ID: 5}, VaultID: 1, FolderID: &root, Tags: []models.Tag{{Name: "work"}}},
+				{Model: gorm.Model{ID: 6}, VaultID: 1, FolderID: &child},
+				{Model: gorm.Model{ID: 7}, VaultID: 1, FolderID: &other, Tags: []models.Tag{{Name: "work"}}},
+				{Model: gorm.Model{ID: 8}, VaultID: 1},
+			}, nil)
+			favoriteRepoMock.On("FindItemIDsByUserID", ctx, userID).Return([]uint{6, 7}, nil)
+
+			// when
+			itemSvc := &itemService{repoMock, vaultRepoMock, &mocks.TagRepositoryMock{}, folderRepoMock, favoriteRepoMock, revisionMock(2), transactionMock()}
+			actual, error := itemSvc.GetAll(ctx, 1, tc.filter)
+
+			// then
+			assert.Nil(t, error)
+			assert.Equal(t, tc.ids, utils.Map(actual, func(i models.ItemDetail) uint { return i.ID }))
+		})
+	}
+
+	t.Run("folder not found", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		folderRepoMock := &mocks.FolderRepositoryMock{}
+
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		folderRepoMock.On("FindByVaultID", ctx, uint(1)).Return([]models.Folder{}, nil)
+
+		// when
+		itemSvc := &itemService{&mocks.ItemRepositoryMock{}, vaultRepoMock, &mocks.TagRepositoryMock{}, folderRepoMock, &mocks.FavoriteRepositoryMock{}, revisionMock(2), transactionMock()}
+		actual, error := itemSvc.GetAll(ctx, 1, models.ItemFilter{FolderID: 9})
+
+		// then
+		assert.Equal(t, []models.ItemDetail{}, actual)
+		assert.Equal(t, "folder not found", error.Error())
+	})
+}
+
+func TestSetFavorite(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		favoriteRepoMock := &mocks.FavoriteRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{Model: gorm.Model{ID: 5}, VaultID: 1}, nil)
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		favoriteRepoMock.On("Save", ctx, &models.Favorite{UserID: userID, ItemID: 5})
+		favoriteRepoMock.On("Delete", ctx, userID, uint(5))
+		repoMock.On("Save", ctx, &models.Item{Model: gorm.Model{ID: 5}, VaultID: 1, UpdatedRevision: 2})
+
+		// when
+		itemSvc := &itemService{repoMock, vaultRepoMock, &mocks.TagRepositoryMock{}, &mocks.FolderRepositoryMock{}, favoriteRepoMock, revisionMock(2), transactionMock()}
+
+		// then
+		assert.Nil(t, itemSvc.SetFavorite(ctx, 5, true))
+		assert.Nil(t, itemSvc.SetFavorite(ctx, 5, false))
+
+		favoriteRepoMock.AssertExpectations(t)
+		repoMock.AssertNumberOfCalls(t, "Save", 2)
+	})
+
+	t.Run("reads the item once the revision is taken", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		favoriteRepoMock := &mocks.FavoriteRepositoryMock{}
+		userRepoMock := &mocks.UserRepositoryMock{}
+
+		// taking the revision locks out other writes of the user, so an
+		// item read before it could be saved over a concurrent update
+		var calls []string
+		userRepoMock.On("NextRevision", ctx, userID).Return(uint64(3), nil).Run(func(args mock.Arguments) {
+			calls = append(calls, "NextRevision")
+		})
+		repoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{Model: gorm.Model{ID: 5}, VaultID: 1}, nil).Run(func(args mock.Arguments) {
+			calls = append(calls, "FindByID")
+		})
+		vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		favoriteRepoMock.On("Save", ctx, &models.Favorite{UserID: userID, ItemID: 5})
+		repoMock.On("Save", ctx, &models.Item{Model: gorm.Model{ID: 5}, VaultID: 1, UpdatedRevision: 3})
+
+		// when
+		itemSvc := &itemService{repoMock, vaultRepoMock, &mocks.TagRepositoryMock{}, &mocks.FolderRepositoryMock{}, favoriteRepoMock, userRepoMock, transactionMock()}
+		error := itemSvc.SetFavorite(ctx, 5, true)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []string{"NextRevision", "FindByID"}, calls)
+		repoMock.AssertExpectations(t)
+	})
+
+	t.Run("item not found", func(t *testing.T) {
+		// given
+		repoMock := &mocks.ItemRepositoryMock{}
+		favoriteRepoMock := &mocks.FavoriteRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{}, nil)
+
+		// when
+		itemSvc := &itemService{repoMock, &mocks.VaultRepositoryMock{}, &mocks.TagRepositoryMock{}, &mocks.FolderRepositoryMock{}, favoriteRepoMock, revisionMock(2), transactionMock()}
+		error := itemSvc.SetFavorite(ctx, 5, true)
+
+		// then
+		assert.Equal(t, "item not found", error.Error())
+
+		favoriteRepoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/keywrap"
+)
+
+type IKeyService interface {
+	// SetKeys sets the key pair of the authenticated user, generated and
+	// encrypted by their client. A user's key pair is set once.
+	SetKeys(ctx context.Context, keys models.UserKeys) error
+	// Keys returns the key pair of the authenticated user.
+	Keys(ctx context.Context) (*models.UserKeys, error)
+	// Lookup returns the public key of the user with an email.
+	Lookup(ctx context.Context, email string) (*models.PublicKeyDetail, error)
+	// Verify records that the authenticated user compared out-of-band the
+	// fingerprint of the public key of the user with an email. It fails when
+	// the fingerprint isn't the one of the key.
+	Verify(ctx context.Context, input models.KeyVerificationInput) (*models.PublicKeyDetail, error)
+	// WrappedKeys returns the keys wrapped to the public key of the authenticated user.
+	WrappedKeys(ctx context.Context) ([]models.WrappedKey, error)
+	// Rotate replaces the key pair of the authenticated user, along with every
+	// key wrapped to it. It fails when the wrapped keys aren't exactly the ones
+	// WrappedKeys returns, so none is left wrapped to the old key.
+	Rotate(ctx context.Context, rotation models.KeyRotation) error
+}
+
+type keyService struct {
+	userRepository         repositories.IUserRepository
+	verificationRepository repositories.IKeyVerificationRepository
+	emergencyRepository    repositories.IEmergencyContactRepository
+	recoveryRepository     repositories.IRecoveryRepository
+	transactor             repositories.ITransactor
+}
+
+// wrappedKeyID identifies a wrapped key by its kind and the ID of its holder.
+type wrappedKeyID struct {
+	kind string
+	id   uint
+}
+
+func NewKeyService(
+	userRepository repositories.IUserRepository,
+	verificationRepository repositories.IKeyVerificationRepository,
+	emergencyRepository repositories.IEmergencyContactRepository,
+	recoveryRepository repositories.IRecoveryRepository,
+	transactor repositories.ITransactor,
+) *keyService {
+	return &keyService{userRepository, verificationRepository, emergencyRepository, recoveryRepository, transactor}
+}
+
+func (k *keyService) SetKeys(ctx context.Context, userKeys models.UserKeys) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if len(userKeys.PublicKey) != keywrap.KeySize {
+		return cerrors.BadRequestError("publicKey must be an X25519 public key")
+	}
+
+	if len(userKeys.EncryptedPrivateKey) == 0 {
+		return cerrors.BadRequestError("encryptedPrivateKey is required")
+	}
+
+	user, err := k.userRepository.FindByID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find user by id: %v", err.Error())
+		return err
+	}
+
+	if len(user.PublicKey) != 0 {
+		return cerrors.ConflictError("keys are already set")
+	}
+
+	if err := k.userRepository.UpdateKeys(ctx, user.ID, userKeys.PublicKey, userKeys.EncryptedPrivateKey); err != nil {
+		log.Printf("error while trying to update the keys of a user: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (k *keyService) Keys(ctx context.Context) (*models.UserKeys, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	user, err := k.userRepository.FindByID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find user by id: %v", err.Error())
+		return nil, err
+	}
+
+	if len(user.PublicKey) == 0 {
+		return nil, cerrors.NotFoundError("keys not set")
+	}
+
+	return &models.UserKeys{PublicKey: user.PublicKey, EncryptedPrivateKey: user.EncryptedPrivateKey}, nil
+}
+
+func (k *keyService) Lookup(ctx context.Context, email string) (*models.PublicKeyDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(email) {
+		return nil, cerrors.BadRequestError("email is required")
+	}
+
+	contact, err := k.findPublicKey(ctx, email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	verification, err := k.verificationRepository.FindByUserIDAndContactID(ctx, userID, contact.ID)
+
+	if err != nil {
+		log.Printf("error while trying to find key verification: %v", err.Error())
+		return nil, err
+	}
+
+	detail := toPublicKeyDetail(*contact)
+	detail.Verified = verification.ID != 0 && bytes.Equal(verification.PublicKey, contact.PublicKey)
+
+	return &detail, nil
+}
+
+func (k *keyService) Verify(ctx context.Context, input models.KeyVerificationInput) (*models.PublicKeyDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(input.Email) {
+		return nil, cerrors.BadRequestError("email is required")
+	}
+
+	if utils.IsBlank(input.Fingerprint) {
+		return nil, cerrors.BadRequestError("fingerprint is required")
+	}
+
+	contact, err := k.findPublicKey(ctx, input.Email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if contact.ID == userID {
+		return nil, cerrors.BadRequestError("you can't verify your own key")
+	}
+
+	detail := toPublicKeyDetail(*contact)
+
+	if normalizeFingerprint(input.Fingerprint) != normalizeFingerprint(detail.Fingerprint) {
+		return nil, cerrors.UnprocessableError("fingerprint doesn't match the key of " + contact.Email)
+	}
+
+	verification, err := k.verificationRepository.FindByUserIDAndContactID(ctx, userID, contact.ID)
+
+	if err != nil {
+		log.Printf("error while trying to find key verification: %v", err.Error())
+		return nil, err
+	}
+
+	verification.UserID = userID
+	verification.ContactID = contact.ID
+	verification.PublicKey = contact.PublicKey
+
+	if err := k.verificationRepository.Save(ctx, verification); err != nil {
+		log.Printf("error while trying to save key verification: %v", err.Error())
+		return nil, err
+	}
+
+	detail.Verified = true
+
+	return &detail, nil
+}
+
+func (k *keyService) WrappedKeys(ctx context.Context) ([]models.WrappedKey, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	contacts, shares, err := k.findWrapped(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedKeys := []models.WrappedKey{}
+
+	for _, contact := range contacts {
+		if len(contact.WrappedKey) != 0 {
+			wrappedKeys = append(wrappedKeys, models.WrappedKey{Kind: models.WrappedEmergencyKey, ID: contact.ID, WrappedKey: contact.WrappedKey})
+		}
+	}
+
+	for _, share := range shares {
+		wrappedKeys = append(wrappedKeys, models.WrappedKey{Kind: models.WrappedRecoveryShare, ID: share.ID, WrappedKey: share.EncryptedShare})
+	}
+
+	return wrappedKeys, nil
+}
+
+func (k *keyService) Rotate(ctx context.Context, rotation models.KeyRotation) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if len(rotation.PublicKey) != keywrap.KeySize {
+		return cerrors.BadRequestError("publicKey must be an X25519 public key")
+	}
+
+	if len(rotation.EncryptedPrivateKey) == 0 {
+		return cerrors.BadRequestError("encryptedPrivateKey is required")
+	}
+
+	rewrapped := make(map[wrappedKeyID][]byte, len(rotation.WrappedKeys))
+
+	for _, wrappedKey := range rotation.WrappedKeys {
+		if wrappedKey.Kind != models.WrappedEmergencyKey && wrappedKey.Kind != models.WrappedRecoveryShare {
+			return cerrors.BadRequestError("unknown wrapped key kind: " + wrappedKey.Kind)
+		}
+
+		if len(wrappedKey.WrappedKey) == 0 {
+			return cerrors.BadRequestError("wrappedKey is required")
+		}
+
+		rewrapped[wrappedKeyID{wrappedKey.Kind, wrappedKey.ID}] = wrappedKey.WrappedKey
+	}
+
+	if len(rewrapped) != len(rotation.WrappedKeys) {
+		return cerrors.BadRequestError("wrapped keys must be unique")
+	}
+
+	return k.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := k.userRepository.FindByID(ctx, userID)
+
+		if err != nil {
+			log.Printf("error while trying to find user by id: %v", err.Error())
+			return err
+		}
+
+		if len(user.PublicKey) == 0 {
+			return cerrors.NotFoundError("keys not set")
+		}
+
+		contacts, shares, err := k.findWrapped(ctx, userID)
+
+		if err != nil {
+			return err
+		}
+
+		changed := cerrors.ConflictError("wrapped keys changed, rotate again")
+		matched := 0
+
+		for i := range contacts {
+			contact := &contacts[i]
+
+			// An accepted contact only has the public key, the grantor wraps their key to the new one.
+			if len(contact.WrappedKey) != 0 {
+				wrappedKey, found := rewrapped[wrappedKeyID{models.WrappedEmergencyKey, contact.ID}]
+
+				if !found {
+					return changed
+				}
+
+				contact.WrappedKey = wrappedKey
+				matched++
+			}
+
+			contact.PublicKey = rotation.PublicKey
+		}
+
+		for i := range shares {
+			wrappedKey, found := rewrapped[wrappedKeyID{models.WrappedRecoveryShare, shares[i].ID}]
+
+			if !found {
+				return changed
+			}
+
+			shares[i].EncryptedShare = wrappedKey
+			matched++
+		}
+
+		if matched != len(rewrapped) {
+			return changed
+		}
+
+		for i := range contacts {
+			if err := k.emergencyRepository.Save(ctx, &contacts[i]); err != nil {
+				log.Printf("error while trying to save emergency contact: %v", err.Error())
+				return err
+			}
+		}
+
+		for i := range shares {
+			if err := k.recoveryRepository.SaveShare(ctx, &shares[i]); err != nil {
+				log.Printf("error while trying to save recovery share: %v", err.Error())
+				return err
+			}
+		}
+
+		if err := k.userRepository.UpdateKeys(ctx, user.ID, rotation.PublicKey, rotation.EncryptedPrivateKey); err != nil {
+			log.Printf("error while trying to update the keys of a user: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+}
+
+// findWrapped returns the emergency contacts a user accepted to be, holding
+// their public key, and the recovery shares the user holds.
+func (k *keyService) findWrapped(ctx context.Context, userID uint) ([]models.EmergencyContact, []models.RecoveryShare, error) {
+	grantors, err := k.emergencyRepository.FindByGranteeID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find emergency contacts by granteeId: %v", err.Error())
+		return nil, nil, err
+	}
+
+	var contacts []models.EmergencyContact
+
+	for _, contact := range grantors {
+		if len(contact.PublicKey) != 0 {
+			contacts = append(contacts, contact)
+		}
+	}
+
+	shares, err := k.recoveryRepository.FindSharesByAdminID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find recovery shares by adminId: %v", err.Error())
+		return nil, nil, err
+	}
+
+	return contacts, shares, nil
+}
+
+// findPublicKey finds the user with an email, who must have a public key.
+func (k *keyService) findPublicKey(ctx context.Context, email string) (*models.User, error) {
+	user, err := k.userRepository.FindByEmail(ctx, email)
+
+	if err != nil {
+		log.Printf("error while trying to find user by email: %v", err.Error())
+		return nil, err
+	}
+
+	if user.ID == 0 {
+		return nil, cerrors.NotFoundError("user not found")
+	}
+
+	if len(user.PublicKey) == 0 {
+		return nil, cerrors.NotFoundError(user.Email + " has no public key yet")
+	}
+
+	return user, nil
+}
+
+func toPublicKeyDetail(user models.User) models.PublicKeyDetail {
+	return models.PublicKeyDetail{
+		UserID:      user.ID,
+		Email:       user.Email,
+		PublicKey:   user.PublicKey,
+		Fingerprint: keywrap.Fingerprint(user.PublicKey),
+	}
+}
+
+// normalizeFingerprint ignores the case and the spacing of fingerprints, which people type.
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.Join(strings.Fields(fingerprint), ""))
+}
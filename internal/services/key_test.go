@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/keywrap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestNewKeyService(t *testing.T) {
+	userRepoMock := &mocks.UserRepositoryMock{}
+	verificationRepoMock := &mocks.KeyVerificationRepositoryMock{}
+	emergencyRepoMock := &mocks.EmergencyContactRepositoryMock{}
+	recoveryRepoMock := &mocks.RecoveryRepositoryMock{}
+	transactorMock := &mocks.TransactorMock{}
+
+	keySvc := NewKeyService(userRepoMock, verificationRepoMock, emergencyRepoMock, recoveryRepoMock, transactorMock)
+
+	assert.Equal(t, userRepoMock, keySvc.userRepository)
+	assert.Equal(t, verificationRepoMock, keySvc.verificationRepository)
+	assert.Equal(t, emergencyRepoMock, keySvc.emergencyRepository)
+	assert.Equal(t, recoveryRepoMock, keySvc.recoveryRepository)
+	assert.Equal(t, transactorMock, keySvc.transactor)
+}
+
+func TestSetKeys(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	publicKey := make([]byte, keywrap.KeySize)
+	publicKey[0] = 9
+
+	testCases := []struct {
+		name     string
+		user     *models.User
+		input    models.UserKeys
+		expected error
+	}{
+		{"success", &models.User{Model: gorm.Model{ID: 10}}, models.UserKeys{PublicKey: publicKey, EncryptedPrivateKey: []byte("sealed")}, nil},
+		{"invalid public key", &models.User{Model: gorm.Model{ID: 10}}, models.UserKeys{PublicKey: []byte("key"), EncryptedPrivateKey: []byte("sealed")}, cerrors.BadRequestError("publicKey must be an X25519 public key")},
+		{"missing private key", &models.User{Model: gorm.Model{ID: 10}}, models.UserKeys{PublicKey: publicKey}, cerrors.BadRequestError("encryptedPrivateKey is required")},
+		{"already set", &models.User{Model: gorm.Model{ID: 10}, PublicKey: publicKey}, models.UserKeys{PublicKey: publicKey, EncryptedPrivateKey: []byte("sealed")}, cerrors.ConflictError("keys are already set")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			userRepoMock := &mocks.UserRepositoryMock{}
+			userRepoMock.On("FindByID", ctx, uint(10)).Return(tc.user, nil)
+			userRepoMock.On("UpdateKeys", ctx, uint(10), mock.Anything, mock.Anything).Return(nil)
+
+			// when
+			error := (&keyService{userRepository: userRepoMock}).SetKeys(ctx, tc.input)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+
+			if tc.expected != nil {
+				userRepoMock.AssertNotCalled(t, "UpdateKeys", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			userRepoMock.AssertCalled(t, "UpdateKeys", ctx, uint(10), publicKey, []byte("sealed"))
+			userRepoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetKeys(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	t.Run("set", func(t *testing.T) {
+		// given
+		userRepoMock := &mocks.UserRepositoryMock{}
+		userRepoMock.On("FindByID", ctx, uint(10)).Return(&models.User{Model: gorm.Model{ID: 10}, PublicKey: []byte("public"), EncryptedPrivateKey: []byte("sealed")}, nil)
+
+		// when
+		actual, error := (&keyService{userRepository: userRepoMock}).Keys(ctx)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, &models.UserKeys{PublicKey: []byte("public"), EncryptedPrivateKey: []byte("sealed")}, actual)
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		// given
+		userRepoMock := &mocks.UserRepositoryMock{}
+		userRepoMock.On("FindByID", ctx, uint(10)).Return(&models.User{Model: gorm.Model{ID: 10}}, nil)
+
+		// when
+		actual, error := (&keyService{userRepository: userRepoMock}).Keys(ctx)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, cerrors.NotFoundError("keys not set"), error)
+	})
+}
+
+func TestLookupPublicKey(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	publicKey, _, _ := keywrap.GenerateKey()
+	oldPublicKey, _, _ := keywrap.GenerateKey()
+
+	testCases := []struct {
+		name         string
+		email        string
+		verification *models.KeyVerification
+		expected     *models.PublicKeyDetail
+		err          error
+	}{
+		{"unverified", "jane@test.com", &models.KeyVerification{}, &models.PublicKeyDetail{UserID: 20, Email: "jane@test.com", PublicKey: publicKey, Fingerprint: keywrap.Fingerprint(publicKey)}, nil},
+		{"verified", "jane@test.com", &models.KeyVerification{Model: gorm.Model{ID: 1}, PublicKey: publicKey}, &models.PublicKeyDetail{UserID: 20, Email: "jane@test.com", PublicKey: publicKey, Fingerprint: keywrap.Fingerprint(publicKey), Verified: true}, nil},
+		{"verified another key", "jane@test.com", &models.KeyVerification{Model: gorm.Model{ID: 1}, PublicKey: oldPublicKey}, &models.PublicKeyDetail{UserID: 20, Email: "jane@test.com", PublicKey: publicKey, Fingerprint: keywrap.Fingerprint(publicKey)}, nil},
+		{"no public key", "bob@test.com", nil, nil, cerrors.NotFoundError("bob@test.com has no public key yet")},
+		{"unknown user", "nobody@test.com", nil, nil, cerrors.NotFoundError("user not found")},
+		{"missing email", " ", nil, nil, cerrors.BadRequestError("email is required")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			userRepoMock := &mocks.UserRepositoryMock{}
+			verificationRepoMock := &mocks.KeyVerificationRepositoryMock{}
+			userRepoMock.On("FindByEmail", ctx, "jane@test.com").Return(&models.User{Model: gorm.Model{ID: 20}, Email: "jane@test.com", PublicKey: publicKey}, nil)
+			userRepoMock.On("FindByEmail", ctx, "bob@test.com").Return(&models.User{Model: gorm.Model{ID: 30}, Email: "bob@test.com"}, nil)
+			userRepoMock.On("FindByEmail", ctx, "nobody@test.com").Return(&models.User{}, nil)
+			verificationRepoMock.On("FindByUserIDAndContactID", ctx, uint(10), uint(20)).Return(tc.verification, nil)
+
+			// when
+			actual, error := (&keyService{userRepository: userRepoMock, verificationRepository: verificationRepoMock}).Lookup(ctx, tc.email)
+
+			// then
+			assert.Equal(t, tc.err, error)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestVerifyPublicKey(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	publicKey, _, _ := keywrap.GenerateKey()
+	fingerprint := keywrap.Fingerprint(publicKey)
+
+	testCases := []struct {
+		name     string
+		input    models.KeyVerificationInput
+		expected error
+	}{
+		{"success", models.KeyVerificationInput{Email: "jane@test.com", Fingerprint: fingerprint}, nil},
+		{"typed without spaces", models.KeyVerificationInput{Email: "jane@test.com", Fingerprint: strings.ToUpper(strings.ReplaceAll(fingerprint, " ", ""))}, nil},
+		{"mismatch", models.KeyVerificationInput{Email: "jane@test.com", Fingerprint: "0000 " + fingerprint[5:]}, cerrors.UnprocessableError("fingerprint doesn't match the key of jane@test.com")},
+		{"own key", models.KeyVerificationInput{Email: "john@test.com", Fingerprint: fingerprint}, cerrors.BadRequestError("you can't verify your own key")},
+		{"missing fingerprint", models.KeyVerificationInput{Email: "jane@test.com"}, cerrors.BadRequestError("fingerprint is required")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			userRepoMock := &mocks.UserRepositoryMock{}
+			verificationRepoMock := &mocks.KeyVerificationRepositoryMock{}
+			userRepoMock.On("FindByEmail", ctx, "jane@test.com").Return(&models.User{Model: gorm.Model{ID: 20}, Email: "jane@test.com", PublicKey: publicKey}, nil)
+			userRepoMock.On("FindByEmail", ctx, "john@test.com").Return(&models.User{Model: gorm.Model{ID: 10}, Email: "john@test.com", PublicKey: publicKey}, nil)
+			verificationRepoMock.On("FindByUserIDAndContactID", ctx, uint(10), uint(20)).Return(&models.KeyVerification{}, nil)
+			verificationRepoMock.On("Save", ctx, mock.Anything).Return(nil)
+
+			// when
+			actual, error := (&keyService{userRepository: userRepoMock, verificationRepository: verificationRepoMock}).Verify(ctx, tc.input)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+
+			if tc.expected != nil {
+				assert.Nil(t, actual)
+				verificationRepoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.Equal(t, &models.PublicKeyDetail{UserID: 20, Email: "jane@test.com", PublicKey: publicKey, Fingerprint: fingerprint, Verified: true}, actual)
+			verificationRepoMock.AssertCalled(t, "Save", ctx, &models.KeyVerification{UserID: 10, ContactID: 20, PublicKey: publicKey})
+		})
+	}
+}
+
+func TestWrappedKeys(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	// given
+	emergencyRepoMock := &mocks.EmergencyContactRepositoryMock{}
+	recoveryRepoMock := &mocks.RecoveryRepositoryMock{}
+	emergencyRepoMock.On("FindByGranteeID", ctx, uint(10)).Return([]models.EmergencyContact{
+		{Model: gorm.Model{ID: 3}, GranteeID: 10, PublicKey: []byte("public"), WrappedKey: []byte("wrapped")},
+		{Model: gorm.Model{ID: 4}, GranteeID: 10, PublicKey: []byte("public")},
+		{Model: gorm.Model{ID: 5}, GranteeID: 10},
+	}, nil)
+	recoveryRepoMock.On("FindSharesByAdminID", ctx, uint(10)).Return([]models.RecoveryShare{{Model: gorm.Model{ID: 7}, AdminID: 10, EncryptedShare: []byte("share")}}, nil)
+
+	// when
+	actual, error := (&keyService{emergencyRepository: emergencyRepoMock, recoveryRepository: recoveryRepoMock}).WrappedKeys(ctx)
+
+	// then
+	assert.Nil(t, error)
+	assert.Equal(t, []models.WrappedKey{
+		{Kind: models.WrappedEmergencyKey, ID: 3, WrappedKey: []byte("wrapped")},
+		{Kind: models.WrappedRecoveryShare, ID: 7, WrappedKey: []byte("share")},
+	}, actual)
+}
+
+func TestRotateKeys(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	newPublicKey, _, _ := keywrap.GenerateKey()
+	rewrapped := []models.WrappedKey{
+		{Kind: models.WrappedEmergencyKey, ID: 3, WrappedKey: []byte("rewrapped")},
+		{Kind: models.WrappedRecoveryShare, ID: 7, WrappedKey: []byte("reshare")},
+	}
+
+	testCases := []struct {
+		name     string
+		rotation models.KeyRotation
+		expected error
+	}{
+		{"success", models.KeyRotation{PublicKey: newPublicKey, EncryptedPrivateKey: []byte("new-sealed"), WrappedKeys: rewrapped}, nil},
+		{"missing wrapped key", models.KeyRotation{PublicKey: newPublicKey, EncryptedPrivateKey: []byte("new-sealed"), WrappedKeys: rewrapped[:1]}, cerrors.ConflictError("wrapped keys changed, rotate again")},
+		{"extra wrapped key", models.KeyRotation{PublicKey: newPublicKey, EncryptedPrivateKey: []byte("new-sealed"), WrappedKeys: append([]models.WrappedKey{{Kind: models.WrappedRecoveryShare, ID: 8, WrappedKey: []byte("x")}}, rewrapped...)}, cerrors.ConflictError("wrapped keys changed, rotate again")},
+		{"duplicate wrapped key", models.KeyRotation{PublicKey: newPublicKey, EncryptedPrivateKey: []byte("new-sealed"), WrappedKeys: append([]models.WrappedKey{rewrapped[0]}, rewrapped...)}, cerrors.BadRequestError("wrapped keys must be unique")},
+		{"unknown kind", models.KeyRotation{PublicKey: newPublicKey, EncryptedPrivateKey: []byte("new-sealed"), WrappedKeys: []models.WrappedKey{{Kind: "vault", ID: 1, WrappedKey: []byte("x")}}}, cerrors.BadRequestError("unknown wrapped key kind: vault")},
+		{"invalid public key", models.KeyRotation{PublicKey: []byte("key"), EncryptedPrivateKey: []byte("new-sealed"), WrappedKeys: rewrapped}, cerrors.BadRequestError("publicKey must be an X25519 public key")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			userRepoMock := &mocks.UserRepositoryMock{}
+			emergencyRepoMock := &mocks.EmergencyContactRepositoryMock{}
+			recoveryRepoMock := &mocks.RecoveryRepositoryMock{}
+			transactorMock := &mocks.TransactorMock{}
+			userRepoMock.On("FindByID", ctx, uint(10)).Return(&models.User{Model: gorm.Model{ID: 10}, PublicKey: []byte("old-public"), EncryptedPrivateKey: []byte("old-sealed")}, nil)
+			userRepoMock.On("UpdateKeys", ctx, uint(10), mock.Anything, mock.Anything).Return(nil)
+			emergencyRepoMock.On("FindByGranteeID", ctx, uint(10)).Return([]models.EmergencyContact{
+				{Model: gorm.Model{ID: 3}, GranteeID: 10, PublicKey: []byte("old-public"), WrappedKey: []byte("wrapped")},
+				{Model: gorm.Model{ID: 4}, GranteeID: 10, PublicKey: []byte("old-public")},
+				{Model: gorm.Model{ID: 5}, GranteeID: 10},
+			}, nil)
+			emergencyRepoMock.On("Save", ctx, mock.Anything).Return(nil)
+			recoveryRepoMock.On("FindSharesByAdminID", ctx, uint(10)).Return([]models.RecoveryShare{{Model: gorm.Model{ID: 7}, AdminID: 10, EncryptedShare: []byte("share")}}, nil)
+			recoveryRepoMock.On("SaveShare", ctx, mock.Anything).Return(nil)
+			transactorMock.On("WithinTransaction", ctx)
+
+			keySvc := NewKeyService(userRepoMock, &mocks.KeyVerificationRepositoryMock{}, emergencyRepoMock, recoveryRepoMock, transactorMock)
+
+			// when
+			error := keySvc.Rotate(ctx, tc.rotation)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+
+			if tc.expected != nil {
+				userRepoMock.AssertNotCalled(t, "UpdateKeys", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				emergencyRepoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				recoveryRepoMock.AssertNotCalled(t, "SaveShare", mock.Anything, mock.Anything)
+				return
+			}
+
+			userRepoMock.AssertCalled(t, "UpdateKeys", ctx, uint(10), newPublicKey, []byte("new-sealed"))
+			userRepoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+			emergencyRepoMock.AssertCalled(t, "Save", ctx, &models.EmergencyContact{Model: gorm.Model{ID: 3}, GranteeID: 10, PublicKey: newPublicKey, WrappedKey: []byte("rewrapped")})
+			emergencyRepoMock.AssertCalled(t, "Save", ctx, &models.EmergencyContact{Model: gorm.Model{ID: 4}, GranteeID: 10, PublicKey: newPublicKey})
+			emergencyRepoMock.AssertNumberOfCalls(t, "Save", 2)
+			recoveryRepoMock.AssertCalled(t, "SaveShare", ctx, &models.RecoveryShare{Model: gorm.Model{ID: 7}, AdminID: 10, EncryptedShare: []byte("reshare")})
+		})
+	}
+
+	t.Run("keys not set", func(t *testing.T) {
+		// given
+		userRepoMock := &mocks.UserRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+		userRepoMock.On("FindByID", ctx, uint(10)).Return(&models.User{Model: gorm.Model{ID: 10}}, nil)
+		transactorMock.On("WithinTransaction", ctx)
+
+		// when
+		error := (&keyService{userRepository: userRepoMock, transactor: transactorMock}).Rotate(ctx, models.KeyRotation{PublicKey: newPublicKey, EncryptedPrivateKey: []byte("sealed")})
+
+		// then
+		assert.Equal(t, cerrors.NotFoundError("keys not set"), error)
+	})
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+)
+
+// notificationBuffer is the number of notifications waiting for a slow
+// connection, past which the connection is dropped so the client resumes it.
+const notificationBuffer = 64
+
+type INotificationService interface {
+	// Subscribe returns the notifications of the changes made to the vaults of
+	// the authenticated user, until ctx is done. When since isn't nil, the
+	// changes made after that revision come first. The channel is closed when
+	// ctx is done, or early when the subscriber doesn't keep up, in which case
+	// it should subscribe again from the last revision it got.
+	Subscribe(ctx context.Context, since *uint64) (<-chan models.Notification, error)
+}
+
+// notificationSubscriber is a connection of a user waiting for notifications.
+type notificationSubscriber struct {
+	notifications chan models.Notification
+}
+
+type notificationService struct {
+	syncService ISyncService
+
+	mu sync.Mutex
+	// subscribers are the connections of each user.
+	subscribers map[uint]map[*notificationSubscriber]struct{}
+}
+
+// NewNotificationService creates a notification service fanning out the
+// events given to Notify, which is meant to be subscribed to the event bus,
+// to the connections of their users.
+func NewNotificationService(syncService ISyncService) *notificationService {
+	return &notificationService{
+		syncService: syncService,
+		subscribers: map[uint]map[*notificationSubscriber]struct{}{},
+	}
+}
+
+func (n *notificationService) Subscribe(ctx context.Context, since *uint64) (<-chan models.Notification, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	// Subscribed before the changes are read, so the changes made meanwhile
+	// aren't missed.
+	subscriber := n.subscribe(userID)
+	var missed []models.Notification
+	var replayed uint64
+
+	if since != nil {
+		changes, err := n.syncService.Changes(ctx, *since)
+
+		if err != nil {
+			n.unsubscribe(userID, subscriber)
+			return nil, err
+		}
+
+		missed = changeNotifications(changes)
+		replayed = changes.Revision
+	}
+
+	notifications := make(chan models.Notification)
+
+	go func() {
+		defer close(notifications)
+		defer n.unsubscribe(userID, subscriber)
+
+		for _, notification := range missed {
+			select {
+			case notifications <- notification:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for {
+			select {
+			case notification, ok := <-subscriber.notifications:
+				if !ok {
+					return
+				}
+
+				// Changes up to the revision replayed were in the replay.
+				if notification.Revision != 0 && notification.Revision <= replayed {
+					continue
+				}
+
+				select {
+				case notifications <- notification:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return notifications, nil
+}
+
+// Notify hands the notification of event to the connections of its user.
+// Connections whose buffer is full are dropped rather than waited for.
+func (n *notificationService) Notify(ctx context.Context, event models.Event) {
+	notification := models.Notification{
+		Type:     event.Type,
+		VaultID:  event.VaultID,
+		ItemID:   event.ItemID,
+		Revision: event.Revision,
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for subscriber := range n.subscribers[event.UserID] {
+		select {
+		case subscriber.notifications <- notification:
+		default:
+			n.drop(event.UserID, subscriber)
+		}
+	}
+}
+
+func (n *notificationService) subscribe(userID uint) *notificationSubscriber {
+	subscriber := &notificationSubscriber{make(chan models.Notification, notificationBuffer)}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.subscribers[userID] == nil {
+		n.subscribers[userID] = map[*notificationSubscriber]struct{}{}
+	}
+
+	n.subscribers[userID][subscriber] = struct{}{}
+
+	return subscriber
+}
+
+func (n *notificationService) unsubscribe(userID uint, subscriber *notificationSubscriber) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, ok := n.subscribers[userID][subscriber]; ok {
+		n.drop(userID, subscriber)
+	}
+}
+
+// drop removes a subscriber and closes its channel. n.mu must be held.
+func (n *notificationService) drop(userID uint, subscriber *notificationSubscriber) {
+	delete(n.subscribers[userID], subscriber)
+	close(subscriber.notifications)
+
+	if len(n.subscribers[userID]) == 0 {
+		delete(n.subscribers, userID)
+	}
+}
+
+// changeNotifications returns the notifications of sync changes, all at the
+// revision they bring the client to.
+func changeNotifications(changes *models.SyncChanges) []models.Notification {
+	var notifications []models.Notification
+	add := func(eventType string, vaultID, itemID uint) {
+		notifications = append(notifications, models.Notification{Type: eventType, VaultID: vaultID, ItemID: itemID, Revision: changes.Revision})
+	}
+
+	for _, vault := range changes.Vaults.Created {
+		add(models.EventVaultCreated, vault.ID, 0)
+	}
+
+	for _, vault := range changes.Vaults.Updated {
+		add(models.EventVaultRenamed, vault.ID, 0)
+	}
+
+	for _, id := range changes.Vaults.Deleted {
+		add(models.EventVaultDeleted, id, 0)
+	}
+
+	for _, item := range changes.Items.Created {
+		add(models.EventItemCreated, item.VaultID, item.ID)
+	}
+
+	for _, item := range changes.Items.Updated {
+		add(models.EventItemUpdated, item.VaultID, item.ID)
+	}
+
+	for _, id := range changes.Items.Deleted {
+		add(models.EventItemDeleted, 0, id)
+	}
+
+	return notifications
+}
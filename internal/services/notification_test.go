@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// receiveAll returns the notifications until the channel is closed.
+func receiveAll(notifications <-chan models.Notification) []models.Notification {
+	var received []models.Notification
+
+	for notification := range notifications {
+		received = append(received, notification)
+	}
+
+	return received
+}
+
+func TestNewNotificationService(t *testing.T) {
+	syncSvcMock := &mocks.SyncServiceMock{}
+
+	notificationSvc := NewNotificationService(syncSvcMock)
+
+	assert.Equal(t, syncSvcMock, notificationSvc.syncService)
+	assert.Empty(t, notificationSvc.subscribers)
+}
+
+func TestSubscribeNotifications(t *testing.T) {
+	user := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	updated := models.Event{ID: "e1", Type: models.EventItemUpdated, UserID: 10, VaultID: 1, ItemID: 5, Revision: 42}
+
+	t.Run("fan-out to the connections of the user", func(t *testing.T) {
+		// given
+		notificationSvc := NewNotificationService(&mocks.SyncServiceMock{})
+		ctx, cancel := context.WithCancel(user)
+		other, cancelOther := context.WithCancel(context.WithValue(context.TODO(), keys.UserIDKey, uint(20)))
+		defer cancelOther()
+
+		first, _ := notificationSvc.Subscribe(ctx, nil)
+		second, _ := notificationSvc.Subscribe(ctx, nil)
+		otherUser, _ := notificationSvc.Subscribe(other, nil)
+
+		// when
+		notificationSvc.Notify(context.TODO(), updated)
+
+		// then: notifications hold IDs and revisions only
+		expected := models.Notification{Type: models.EventItemUpdated, VaultID: 1, ItemID: 5, Revision: 42}
+		assert.Equal(t, expected, <-first)
+		assert.Equal(t, expected, <-second)
+		assert.Empty(t, otherUser)
+
+		cancel()
+		assert.Empty(t, receiveAll(first))
+		assert.Empty(t, receiveAll(second))
+	})
+
+	t.Run("resumed from a revision", func(t *testing.T) {
+		// given
+		syncSvcMock := &mocks.SyncServiceMock{}
+		notificationSvc := NewNotificationService(syncSvcMock)
+		ctx, cancel := context.WithCancel(user)
+		since := uint64(40)
+
+		syncSvcMock.On("Changes", ctx, since).Return(&models.SyncChanges{
+			Revision: 42,
+			Vaults:   models.ChangeSet[models.VaultDetail]{Created: []models.VaultDetail{{ID: 2, Name: "Personal"}}, Deleted: []uint{3}},
+			Items:    models.ChangeSet[models.ItemDetail]{Updated: []models.ItemDetail{{ID: 5, VaultID: 1, Password: "secret"}}},
+		}, nil)
+
+		notifications, error := notificationSvc.Subscribe(ctx, &since)
+
+		// when: a change already replayed and a new one are notified
+		notificationSvc.Notify(context.TODO(), updated)
+		notificationSvc.Notify(context.TODO(), models.Event{Type: models.EventItemDeleted, UserID: 10, VaultID: 1, ItemID: 5, Revision: 43})
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []models.Notification{
+			{Type: models.EventVaultCreated, VaultID: 2, Revision: 42},
+			{Type: models.EventVaultDeleted, VaultID: 3, Revision: 42},
+			{Type: models.EventItemUpdated, VaultID: 1, ItemID: 5, Revision: 42},
+			{Type: models.EventItemDeleted, VaultID: 1, ItemID: 5, Revision: 43},
+		}, []models.Notification{<-notifications, <-notifications, <-notifications, <-notifications})
+
+		cancel()
+		assert.Empty(t, receiveAll(notifications))
+	})
+
+	t.Run("revision ahead of the server", func(t *testing.T) {
+		// given
+		syncSvcMock := &mocks.SyncServiceMock{}
+		notificationSvc := NewNotificationService(syncSvcMock)
+		since := uint64(50)
+
+		syncSvcMock.On("Changes", user, since).Return(nil, cerrors.ConflictError("revision is ahead of the server, sync from 0"))
+
+		// when
+		actual, error := notificationSvc.Subscribe(user, &since)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, cerrors.ConflictError("revision is ahead of the server, sync from 0"), error)
+		assert.Empty(t, notificationSvc.subscribers)
+	})
+
+	t.Run("slow connection dropped", func(t *testing.T) {
+		// given
+		notificationSvc := NewNotificationService(&mocks.SyncServiceMock{})
+		ctx, cancel := context.WithCancel(user)
+		defer cancel()
+
+		notifications, _ := notificationSvc.Subscribe(ctx, nil)
+
+		// when
+		for i := 0; i < 2*notificationBuffer; i++ {
+			notificationSvc.Notify(context.TODO(), updated)
+		}
+
+		// then: the buffered notifications are received, then the channel is closed
+		received := receiveAll(notifications)
+		assert.GreaterOrEqual(t, len(received), notificationBuffer)
+		assert.Less(t, len(received), 2*notificationBuffer)
+		assert.Empty(t, notificationSvc.subscribers)
+	})
+
+	t.Run("not authenticated", func(t *testing.T) {
+		// when
+		actual, error := NewNotificationService(&mocks.SyncServiceMock{}).Subscribe(context.TODO(), nil)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, cerrors.UnauthorizedError("user is not authenticated"), error)
+	})
+}
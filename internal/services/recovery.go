@@ -0,0 +1,521 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/keywrap"
+	"github.com/edgardjr92/gopass/pkg/shamir"
+)
+
+type IRecoveryService interface {
+	// Setup sets the recovery key of a vault of the authenticated user,
+	// replacing the one it had. The key is split and its shares encrypted to
+	// the admins by the client.
+	Setup(ctx context.Context, vaultID uint, input models.RecoveryKeyInput) (*models.RecoveryKeyDetail, error)
+	// Get returns the recovery key of a vault, to its owner and its admins.
+	Get(ctx context.Context, vaultID uint) (*models.RecoveryKeyDetail, error)
+	// Share returns the share of the recovery key of a vault encrypted to the authenticated admin.
+	Share(ctx context.Context, vaultID uint) ([]byte, error)
+	// Delete deletes the recovery key of a vault of the authenticated user.
+	Delete(ctx context.Context, vaultID uint) error
+	// Start starts a ceremony rebuilding the recovery key of a vault, requested by the authenticated admin.
+	Start(ctx context.Context, vaultID uint, input models.RecoveryCeremonyInput) (*models.RecoveryCeremonyDetail, error)
+	// Ceremonies returns the ceremonies of the recovery key of a vault, newest first.
+	Ceremonies(ctx context.Context, vaultID uint) ([]models.RecoveryCeremonyDetail, error)
+	// Approve approves a ceremony with the share of the authenticated admin,
+	// encrypted to the requester. The ceremony is approved once the threshold
+	// of the recovery key is reached.
+	Approve(ctx context.Context, id uint, share []byte) (*models.RecoveryCeremonyDetail, error)
+	// Cancel cancels a ceremony, for its requester and the owner of the vault.
+	Cancel(ctx context.Context, id uint) error
+	// Complete returns the shares of an approved ceremony to its requester,
+	// once, to rebuild the recovery key with.
+	Complete(ctx context.Context, id uint) (*models.RecoveryShares, error)
+}
+
+type recoveryService struct {
+	repository      repositories.IRecoveryRepository
+	vaultRepository repositories.IVaultRepository
+	userRepository  repositories.IUserRepository
+	transactor      repositories.ITransactor
+}
+
+func NewRecoveryService(
+	repository repositories.IRecoveryRepository,
+	vaultRepository repositories.IVaultRepository,
+	userRepository repositories.IUserRepository,
+	transactor repositories.ITransactor,
+) *recoveryService {
+	return &recoveryService{repository, vaultRepository, userRepository, transactor}
+}
+
+func (r *recoveryService) Setup(ctx context.Context, vaultID uint, input models.RecoveryKeyInput) (*models.RecoveryKeyDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if err := r.checkOwner(ctx, vaultID, userID); err != nil {
+		return nil, err
+	}
+
+	if len(input.Shares) < 2 || len(input.Shares) > shamir.MaxShares {
+		return nil, cerrors.BadRequestError(fmt.Sprintf("shares must be between 2 and %d", shamir.MaxShares))
+	}
+
+	if input.Threshold < 2 || input.Threshold > len(input.Shares) {
+		return nil, cerrors.BadRequestError("threshold must be between 2 and the number of shares")
+	}
+
+	key := models.RecoveryKey{VaultID: vaultID, OwnerID: userID, Threshold: input.Threshold}
+
+	for _, share := range input.Shares {
+		if utils.IsBlank(share.Email) {
+			return nil, cerrors.BadRequestError("email is required")
+		}
+
+		if len(share.EncryptedShare) == 0 {
+			return nil, cerrors.BadRequestError("encryptedShare is required")
+		}
+
+		admin, err := r.userRepository.FindByEmail(ctx, share.Email)
+
+		if err != nil {
+			log.Printf("error while trying to find user by email: %v", err.Error())
+			return nil, err
+		}
+
+		if admin.ID == 0 {
+			return nil, cerrors.NotFoundError(fmt.Sprintf("user %s not found", share.Email))
+		}
+
+		if _, found := findRecoveryShare(key, admin.ID); found {
+			return nil, cerrors.BadRequestError(fmt.Sprintf("%s holds more than one share", admin.Email))
+		}
+
+		key.Shares = append(key.Shares, models.RecoveryShare{AdminID: admin.ID, AdminEmail: admin.Email, EncryptedShare: share.EncryptedShare})
+	}
+
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := r.repository.FindKeyByVaultID(ctx, vaultID)
+
+		if err != nil {
+			log.Printf("error while trying to find recovery key by vaultId: %v", err.Error())
+			return err
+		}
+
+		if existing.ID != 0 {
+			if err := r.repository.DeleteKey(ctx, existing.ID); err != nil {
+				log.Printf("error while trying to delete recovery key: %v", err.Error())
+				return err
+			}
+		}
+
+		if err := r.repository.SaveKey(ctx, &key); err != nil {
+			log.Printf("error while trying to save recovery key: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	detail := toRecoveryKeyDetail(key)
+
+	return &detail, nil
+}
+
+func (r *recoveryService) Get(ctx context.Context, vaultID uint) (*models.RecoveryKeyDetail, error) {
+	key, err := r.findKey(ctx, vaultID, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	detail := toRecoveryKeyDetail(*key)
+
+	return &detail, nil
+}
+
+func (r *recoveryService) Share(ctx context.Context, vaultID uint) ([]byte, error) {
+	key, err := r.findKey(ctx, vaultID, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	share, _ := findRecoveryShare(*key, ctx.Value(keys.UserIDKey).(uint))
+
+	return share.EncryptedShare, nil
+}
+
+func (r *recoveryService) Delete(ctx context.Context, vaultID uint) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if err := r.checkOwner(ctx, vaultID, userID); err != nil {
+		return err
+	}
+
+	key, err := r.repository.FindKeyByVaultID(ctx, vaultID)
+
+	if err != nil {
+		log.Printf("error while trying to find recovery key by vaultId: %v", err.Error())
+		return err
+	}
+
+	if key.ID == 0 {
+		return cerrors.NotFoundError("recovery key not found")
+	}
+
+	if err := r.repository.DeleteKey(ctx, key.ID); err != nil {
+		log.Printf("error while trying to delete recovery key: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *recoveryService) Start(ctx context.Context, vaultID uint, input models.RecoveryCeremonyInput) (*models.RecoveryCeremonyDetail, error) {
+	key, err := r.findKey(ctx, vaultID, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if utils.IsBlank(input.Reason) {
+		return nil, cerrors.BadRequestError("reason is required")
+	}
+
+	if len(input.PublicKey) != keywrap.KeySize {
+		return nil, cerrors.BadRequestError("publicKey must be an X25519 public key")
+	}
+
+	ceremonies, err := r.repository.FindCeremoniesByKeyID(ctx, key.ID)
+
+	if err != nil {
+		log.Printf("error while trying to find recovery ceremonies by keyId: %v", err.Error())
+		return nil, err
+	}
+
+	for _, ceremony := range ceremonies {
+		if ceremony.Status == models.RecoveryPending || ceremony.Status == models.RecoveryApproved {
+			return nil, cerrors.ConflictError(fmt.Sprintf("recovery ceremony %d is %s", ceremony.ID, ceremony.Status))
+		}
+	}
+
+	requester, _ := findRecoveryShare(*key, ctx.Value(keys.UserIDKey).(uint))
+	ceremony := models.RecoveryCeremony{
+		RecoveryKeyID:  key.ID,
+		VaultID:        vaultID,
+		RequesterID:    requester.AdminID,
+		RequesterEmail: requester.AdminEmail,
+		Reason:         strings.TrimSpace(input.Reason),
+		PublicKey:      input.PublicKey,
+		Status:         models.RecoveryPending,
+	}
+
+	if err := r.saveCeremony(ctx, &ceremony); err != nil {
+		return nil, err
+	}
+
+	detail := toRecoveryCeremonyDetail(ceremony, key.Threshold)
+
+	return &detail, nil
+}
+
+func (r *recoveryService) Ceremonies(ctx context.Context, vaultID uint) ([]models.RecoveryCeremonyDetail, error) {
+	key, err := r.findKey(ctx, vaultID, true)
+
+	if err != nil {
+		return []models.RecoveryCeremonyDetail{}, err
+	}
+
+	ceremonies, err := r.repository.FindCeremoniesByKeyID(ctx, key.ID)
+
+	if err != nil {
+		log.Printf("error while trying to find recovery ceremonies by keyId: %v", err.Error())
+		return []models.RecoveryCeremonyDetail{}, err
+	}
+
+	return utils.Map(ceremonies, func(ceremony models.RecoveryCeremony) models.RecoveryCeremonyDetail {
+		return toRecoveryCeremonyDetail(ceremony, key.Threshold)
+	}), nil
+}
+
+func (r *recoveryService) Approve(ctx context.Context, id uint, share []byte) (*models.RecoveryCeremonyDetail, error) {
+	if _, ok := ctx.Value(keys.UserIDKey).(uint); !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if len(share) == 0 {
+		return nil, cerrors.BadRequestError("share is required")
+	}
+
+	var detail models.RecoveryCeremonyDetail
+
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		ceremony, key, err := r.findCeremony(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		if err := checkRecoveryStatus(ceremony, models.RecoveryPending); err != nil {
+			return err
+		}
+
+		admin, ok := findRecoveryShare(*key, ctx.Value(keys.UserIDKey).(uint))
+
+		if !ok {
+			return cerrors.ForbiddenError("only the admins of the recovery key can approve a recovery ceremony")
+		}
+
+		for _, approval := range ceremony.Approvals {
+			if approval.AdminID == admin.AdminID {
+				return cerrors.ConflictError("you already approved this recovery ceremony")
+			}
+		}
+
+		ceremony.Approvals = append(ceremony.Approvals, models.RecoveryApproval{
+			CeremonyID: ceremony.ID,
+			AdminID:    admin.AdminID,
+			AdminEmail: admin.AdminEmail,
+			Share:      share,
+		})
+
+		if len(ceremony.Approvals) >= key.Threshold {
+			ceremony.Status = models.RecoveryApproved
+		}
+
+		if err := r.saveCeremony(ctx, ceremony); err != nil {
+			return err
+		}
+
+		detail = toRecoveryCeremonyDetail(*ceremony, key.Threshold)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &detail, nil
+}
+
+func (r *recoveryService) Cancel(ctx context.Context, id uint) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		ceremony, key, err := r.findCeremony(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		if ceremony.RequesterID != userID && key.OwnerID != userID {
+			return cerrors.ForbiddenError("only the requester and the owner of the vault can cancel a recovery ceremony")
+		}
+
+		if ceremony.Status != models.RecoveryPending && ceremony.Status != models.RecoveryApproved {
+			return cerrors.ConflictError(fmt.Sprintf("recovery ceremony is %s", ceremony.Status))
+		}
+
+		ceremony.Status = models.RecoveryCancelled
+		wipeRecoveryShares(ceremony)
+
+		return r.saveCeremony(ctx, ceremony)
+	})
+}
+
+func (r *recoveryService) Complete(ctx context.Context, id uint) (*models.RecoveryShares, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	var shares models.RecoveryShares
+
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		ceremony, _, err := r.findCeremony(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		if ceremony.RequesterID != userID {
+			return cerrors.ForbiddenError("only the requester can complete a recovery ceremony")
+		}
+
+		if err := checkRecoveryStatus(ceremony, models.RecoveryApproved); err != nil {
+			return err
+		}
+
+		shares = models.RecoveryShares{CeremonyID: ceremony.ID, VaultID: ceremony.VaultID}
+
+		for _, approval := range ceremony.Approvals {
+			shares.Shares = append(shares.Shares, approval.Share)
+		}
+
+		ceremony.Status = models.RecoveryCompleted
+		wipeRecoveryShares(ceremony)
+
+		return r.saveCeremony(ctx, ceremony)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &shares, nil
+}
+
+// checkOwner fails unless the user owns the vault.
+func (r *recoveryService) checkOwner(ctx context.Context, vaultID, userID uint) error {
+	vault, err := r.vaultRepository.FindByID(ctx, vaultID)
+
+	if err != nil {
+		log.Printf("error while trying to find vault by id: %v", err.Error())
+		return err
+	}
+
+	if vault.ID == 0 || vault.UserID != userID {
+		return cerrors.NotFoundError("vault not found")
+	}
+
+	return nil
+}
+
+// findKey finds the recovery key of a vault the authenticated user is an
+// admin of, or the owner of when owner is true.
+func (r *recoveryService) findKey(ctx context.Context, vaultID uint, owner bool) (*models.RecoveryKey, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	key, err := r.repository.FindKeyByVaultID(ctx, vaultID)
+
+	if err != nil {
+		log.Printf("error while trying to find recovery key by vaultId: %v", err.Error())
+		return nil, err
+	}
+
+	if _, admin := findRecoveryShare(*key, userID); key.ID == 0 || !admin && !(owner && key.OwnerID == userID) {
+		return nil, cerrors.NotFoundError("recovery key not found")
+	}
+
+	return key, nil
+}
+
+// findCeremony finds a ceremony of a recovery key the authenticated user is
+// an admin or the owner of, along with the key.
+func (r *recoveryService) findCeremony(ctx context.Context, id uint) (*models.RecoveryCeremony, *models.RecoveryKey, error) {
+	ceremony, err := r.repository.FindCeremonyByID(ctx, id)
+
+	if err != nil {
+		log.Printf("error while trying to find recovery ceremony by id: %v", err.Error())
+		return nil, nil, err
+	}
+
+	if ceremony.ID == 0 {
+		return nil, nil, cerrors.NotFoundError("recovery ceremony not found")
+	}
+
+	key, err := r.findKey(ctx, ceremony.VaultID, true)
+
+	if err != nil || key.ID != ceremony.RecoveryKeyID {
+		return nil, nil, cerrors.NotFoundError("recovery ceremony not found")
+	}
+
+	return ceremony, key, nil
+}
+
+func (r *recoveryService) saveCeremony(ctx context.Context, ceremony *models.RecoveryCeremony) error {
+	if err := r.repository.SaveCeremony(ctx, ceremony); err != nil {
+		log.Printf("error while trying to save recovery ceremony: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// findRecoveryShare returns the share of an admin of a recovery key.
+func findRecoveryShare(key models.RecoveryKey, adminID uint) (models.RecoveryShare, bool) {
+	for _, share := range key.Shares {
+		if share.AdminID == adminID {
+			return share, true
+		}
+	}
+
+	return models.RecoveryShare{}, false
+}
+
+// wipeRecoveryShares removes the shares of a ceremony that is over, so they
+// can't be handed out again.
+func wipeRecoveryShares(ceremony *models.RecoveryCeremony) {
+	for i := range ceremony.Approvals {
+		ceremony.Approvals[i].Share = nil
+	}
+}
+
+// checkRecoveryStatus fails unless a ceremony has the status an operation requires.
+func checkRecoveryStatus(ceremony *models.RecoveryCeremony, status string) error {
+	if ceremony.Status != status {
+		return cerrors.ConflictError(fmt.Sprintf("recovery ceremony is %s, not %s", ceremony.Status, status))
+	}
+
+	return nil
+}
+
+func toRecoveryKeyDetail(key models.RecoveryKey) models.RecoveryKeyDetail {
+	return models.RecoveryKeyDetail{
+		ID:        key.ID,
+		VaultID:   key.VaultID,
+		OwnerID:   key.OwnerID,
+		Threshold: key.Threshold,
+		Admins: utils.Map(key.Shares, func(share models.RecoveryShare) models.RecoveryAdmin {
+			return models.RecoveryAdmin{ID: share.AdminID, Email: share.AdminEmail}
+		}),
+		CreatedAt: key.CreatedAt,
+	}
+}
+
+func toRecoveryCeremonyDetail(ceremony models.RecoveryCeremony, threshold int) models.RecoveryCeremonyDetail {
+	return models.RecoveryCeremonyDetail{
+		ID:             ceremony.ID,
+		VaultID:        ceremony.VaultID,
+		RequesterID:    ceremony.RequesterID,
+		RequesterEmail: ceremony.RequesterEmail,
+		Reason:         ceremony.Reason,
+		PublicKey:      ceremony.PublicKey,
+		Status:         ceremony.Status,
+		Threshold:      threshold,
+		Approvals: utils.Map(ceremony.Approvals, func(approval models.RecoveryApproval) models.RecoveryApprovalDetail {
+			return models.RecoveryApprovalDetail{AdminID: approval.AdminID, AdminEmail: approval.AdminEmail, ApprovedAt: approval.CreatedAt}
+		}),
+		CreatedAt: ceremony.CreatedAt,
+	}
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// recoveryKey is the recovery key of vault 1, owned by user 10, with admins 20, 30 and 40.
+func recoveryKey() *models.RecoveryKey {
+	return &models.RecoveryKey{
+		Model:     gorm.Model{ID: 2},
+		VaultID:   1,
+		OwnerID:   10,
+		Threshold: 2,
+		Shares: []models.RecoveryShare{
+			{AdminID: 20, AdminEmail: "jane@test.com", EncryptedShare: []byte("share-20")},
+			{AdminID: 30, AdminEmail: "bob@test.com", EncryptedShare: []byte("share-30")},
+			{AdminID: 40, AdminEmail: "ann@test.com", EncryptedShare: []byte("share-40")},
+		},
+	}
+}
+
+func newRecoveryService() (*recoveryService, *mocks.RecoveryRepositoryMock, *mocks.VaultRepositoryMock, *mocks.UserRepositoryMock) {
+	repoMock := &mocks.RecoveryRepositoryMock{}
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	userRepoMock := &mocks.UserRepositoryMock{}
+	transactorMock := &mocks.TransactorMock{}
+	transactorMock.On("WithinTransaction", mock.Anything)
+
+	return NewRecoveryService(repoMock, vaultRepoMock, userRepoMock, transactorMock), repoMock, vaultRepoMock, userRepoMock
+}
+
+func TestNewRecoveryService(t *testing.T) {
+	repoMock := &mocks.RecoveryRepositoryMock{}
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	userRepoMock := &mocks.UserRepositoryMock{}
+	transactorMock := &mocks.TransactorMock{}
+
+	recoverySvc := NewRecoveryService(repoMock, vaultRepoMock, userRepoMock, transactorMock)
+
+	assert.Equal(t, repoMock, recoverySvc.repository)
+	assert.Equal(t, vaultRepoMock, recoverySvc.vaultRepository)
+	assert.Equal(t, userRepoMock, recoverySvc.userRepository)
+	assert.Equal(t, transactorMock, recoverySvc.transactor)
+}
+
+func TestSetupRecoveryKey(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	jane := models.RecoveryShareInput{Email: "jane@test.com", EncryptedShare: []byte("share-20")}
+	bob := models.RecoveryShareInput{Email: "bob@test.com", EncryptedShare: []byte("share-30")}
+
+	testCases := []struct {
+		name     string
+		vaultID  uint
+		input    models.RecoveryKeyInput
+		expected error
+	}{
+		{"success", 1, models.RecoveryKeyInput{Threshold: 2, Shares: []models.RecoveryShareInput{jane, bob}}, nil},
+		{"vault of another user", 2, models.RecoveryKeyInput{Threshold: 2, Shares: []models.RecoveryShareInput{jane, bob}}, cerrors.NotFoundError("vault not found")},
+		{"one share", 1, models.RecoveryKeyInput{Threshold: 2, Shares: []models.RecoveryShareInput{jane}}, cerrors.BadRequestError("shares must be between 2 and 255")},
+		{"threshold of one", 1, models.RecoveryKeyInput{Threshold: 1, Shares: []models.RecoveryShareInput{jane, bob}}, cerrors.BadRequestError("threshold must be between 2 and the number of shares")},
+		{"threshold above shares", 1, models.RecoveryKeyInput{Threshold: 3, Shares: []models.RecoveryShareInput{jane, bob}}, cerrors.BadRequestError("threshold must be between 2 and the number of shares")},
+		{"missing email", 1, models.RecoveryKeyInput{Threshold: 2, Shares: []models.RecoveryShareInput{jane, {EncryptedShare: []byte("s")}}}, cerrors.BadRequestError("email is required")},
+		{"missing share", 1, models.RecoveryKeyInput{Threshold: 2, Shares: []models.RecoveryShareInput{jane, {Email: "bob@test.com"}}}, cerrors.BadRequestError("encryptedShare is required")},
+		{"unknown admin", 1, models.RecoveryKeyInput{Threshold: 2, Shares: []models.RecoveryShareInput{jane, {Email: "nobody@test.com", EncryptedShare: []byte("s")}}}, cerrors.NotFoundError("user nobody@test.com not found")},
+		{"admin twice", 1, models.RecoveryKeyInput{Threshold: 2, Shares: []models.RecoveryShareInput{jane, jane}}, cerrors.BadRequestError("jane@test.com holds more than one share")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			recoverySvc, repoMock, vaultRepoMock, userRepoMock := newRecoveryService()
+			vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: 10}, nil)
+			vaultRepoMock.On("FindByID", ctx, uint(2)).Return(&models.Vault{Model: gorm.Model{ID: 2}, UserID: 11}, nil)
+			userRepoMock.On("FindByEmail", ctx, "jane@test.com").Return(&models.User{Model: gorm.Model{ID: 20}, Email: "jane@test.com"}, nil)
+			userRepoMock.On("FindByEmail", ctx, "bob@test.com").Return(&models.User{Model: gorm.Model{ID: 30}, Email: "bob@test.com"}, nil)
+			userRepoMock.On("FindByEmail", ctx, "nobody@test.com").Return(&models.User{}, nil)
+			repoMock.On("FindKeyByVaultID", ctx, uint(1)).Return(&models.RecoveryKey{Model: gorm.Model{ID: 1}}, nil)
+			repoMock.On("DeleteKey", ctx, uint(1)).Return(nil)
+			repoMock.On("SaveKey", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				args.Get(1).(*models.RecoveryKey).ID = 2
+			})
+
+			// when
+			actual, error := recoverySvc.Setup(ctx, tc.vaultID, tc.input)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+
+			if tc.expected != nil {
+				assert.Nil(t, actual)
+				repoMock.AssertNotCalled(t, "SaveKey", mock.Anything, mock.Anything)
+				return
+			}
+
+			// then: the key replaces the one the vault had
+			assert.Equal(t, &models.RecoveryKeyDetail{
+				ID:        2,
+				VaultID:   1,
+				OwnerID:   10,
+				Threshold: 2,
+				Admins:    []models.RecoveryAdmin{{ID: 20, Email: "jane@test.com"}, {ID: 30, Email: "bob@test.com"}},
+			}, actual)
+			repoMock.AssertCalled(t, "DeleteKey", ctx, uint(1))
+			repoMock.AssertCalled(t, "SaveKey", ctx, &models.RecoveryKey{
+				Model:     gorm.Model{ID: 2},
+				VaultID:   1,
+				OwnerID:   10,
+				Threshold: 2,
+				Shares: []models.RecoveryShare{
+					{AdminID: 20, AdminEmail: "jane@test.com", EncryptedShare: []byte("share-20")},
+					{AdminID: 30, AdminEmail: "bob@test.com", EncryptedShare: []byte("share-30")},
+				},
+			})
+		})
+	}
+}
+
+func TestGetRecoveryKey(t *testing.T) {
+	testCases := []struct {
+		name     string
+		userID   uint
+		share    []byte
+		expected error
+	}{
+		{"owner", 10, nil, cerrors.NotFoundError("recovery key not found")},
+		{"admin", 30, []byte("share-30"), nil},
+		{"someone else", 50, nil, cerrors.NotFoundError("recovery key not found")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			ctx := context.WithValue(context.TODO(), keys.UserIDKey, tc.userID)
+			recoverySvc, repoMock, _, _ := newRecoveryService()
+			repoMock.On("FindKeyByVaultID", ctx, uint(1)).Return(recoveryKey(), nil)
+
+			// when
+			key, keyErr := recoverySvc.Get(ctx, 1)
+			share, shareErr := recoverySvc.Share(ctx, 1)
+
+			// then: the owner sees the key but holds no share
+			if tc.userID == 50 {
+				assert.Nil(t, key)
+				assert.Equal(t, tc.expected, keyErr)
+			} else {
+				assert.Nil(t, keyErr)
+				assert.Equal(t, 3, len(key.Admins))
+			}
+			assert.Equal(t, tc.share, share)
+			assert.Equal(t, tc.expected, shareErr)
+		})
+	}
+}
+
+func TestStartRecoveryCeremony(t *testing.T) {
+	publicKey := make([]byte, 32)
+
+	testCases := []struct {
+		name       string
+		userID     uint
+		input      models.RecoveryCeremonyInput
+		ceremonies []models.RecoveryCeremony
+		expected   error
+	}{
+		{"success", 20, models.RecoveryCeremonyInput{Reason: " Owner left ", PublicKey: publicKey}, []models.RecoveryCeremony{{Status: models.RecoveryCompleted}}, nil},
+		{"by the owner", 10, models.RecoveryCeremonyInput{Reason: "Owner left", PublicKey: publicKey}, nil, cerrors.NotFoundError("recovery key not found")},
+		{"missing reason", 20, models.RecoveryCeremonyInput{PublicKey: publicKey}, nil, cerrors.BadRequestError("reason is required")},
+		{"invalid public key", 20, models.RecoveryCeremonyInput{Reason: "Owner left", PublicKey: []byte("key")}, nil, cerrors.BadRequestError("publicKey must be an X25519 public key")},
+		{"ceremony in progress", 20, models.RecoveryCeremonyInput{Reason: "Owner left", PublicKey: publicKey}, []models.RecoveryCeremony{{Model: gorm.Model{ID: 4}, Status: models.RecoveryApproved}}, cerrors.ConflictError("recovery ceremony 4 is approved")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			ctx := context.WithValue(context.TODO(), keys.UserIDKey, tc.userID)
+			recoverySvc, repoMock, _, _ := newRecoveryService()
+			repoMock.On("FindKeyByVaultID", ctx, uint(1)).Return(recoveryKey(), nil)
+			repoMock.On("FindCeremoniesByKeyID", ctx, uint(2)).Return(tc.ceremonies, nil)
+			repoMock.On("SaveCeremony", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				args.Get(1).(*models.RecoveryCeremony).ID = 5
+			})
+
+			// when
+			actual, error := recoverySvc.Start(ctx, 1, tc.input)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+
+			if tc.expected != nil {
+				assert.Nil(t, actual)
+				repoMock.AssertNotCalled(t, "SaveCeremony", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.Equal(t, &models.RecoveryCeremonyDetail{
+				ID:             5,
+				VaultID:        1,
+				RequesterID:    20,
+				RequesterEmail: "jane@test.com",
+				Reason:         "Owner left",
+				PublicKey:      publicKey,
+				Status:         models.RecoveryPending,
+				Threshold:      2,
+				Approvals:      []models.RecoveryApprovalDetail{},
+			}, actual)
+		})
+	}
+}
+
+func TestRecoveryCeremonyFlow(t *testing.T) {
+	ceremony := func(status string, approvers ...uint) *models.RecoveryCeremony {
+		c := &models.RecoveryCeremony{
+			Model:          gorm.Model{ID: 5},
+			RecoveryKeyID:  2,
+			VaultID:        1,
+			RequesterID:    20,
+			RequesterEmail: "jane@test.com",
+			Status:         status,
+		}
+
+		for _, approver := range approvers {
+			c.Approvals = append(c.Approvals, models.RecoveryApproval{CeremonyID: 5, AdminID: approver, Share: []byte("wrapped")})
+		}
+
+		return c
+	}
+
+	testCases := []struct {
+		name     string
+		userID   uint
+		ceremony *models.RecoveryCeremony
+		call     func(s *recoveryService, ctx context.Context) (any, error)
+		expected error
+		// saved is the status saved, empty when nothing is.
+		saved string
+	}{
+		{"first approval", 30, ceremony(models.RecoveryPending), approveCeremony, nil, models.RecoveryPending},
+		{"threshold reached", 30, ceremony(models.RecoveryPending, 20), approveCeremony, nil, models.RecoveryApproved},
+		{"approved twice", 30, ceremony(models.RecoveryPending, 30), approveCeremony, cerrors.ConflictError("you already approved this recovery ceremony"), ""},
+		{"approved by the owner", 10, ceremony(models.RecoveryPending), approveCeremony, cerrors.ForbiddenError("only the admins of the recovery key can approve a recovery ceremony"), ""},
+		{"approved by someone else", 50, ceremony(models.RecoveryPending), approveCeremony, cerrors.NotFoundError("recovery ceremony not found"), ""},
+		{"approved once over", 30, ceremony(models.RecoveryCancelled), approveCeremony, cerrors.ConflictError("recovery ceremony is cancelled, not pending"), ""},
+		{"completed", 20, ceremony(models.RecoveryApproved, 20, 30), completeCeremony, nil, models.RecoveryCompleted},
+		{"completed before approval", 20, ceremony(models.RecoveryPending, 20), completeCeremony, cerrors.ConflictError("recovery ceremony is pending, not approved"), ""},
+		{"completed by another admin", 30, ceremony(models.RecoveryApproved, 20, 30), completeCeremony, cerrors.ForbiddenError("only the requester can complete a recovery ceremony"), ""},
+		{"cancelled by the owner", 10, ceremony(models.RecoveryApproved, 20, 30), cancelCeremony, nil, models.RecoveryCancelled},
+		{"cancelled by another admin", 30, ceremony(models.RecoveryPending), cancelCeremony, cerrors.ForbiddenError("only the requester and the owner of the vault can cancel a recovery ceremony"), ""},
+		{"cancelled once over", 20, ceremony(models.RecoveryCompleted), cancelCeremony, cerrors.ConflictError("recovery ceremony is completed"), ""},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			ctx := context.WithValue(context.TODO(), keys.UserIDKey, tc.userID)
+			recoverySvc, repoMock, _, _ := newRecoveryService()
+			repoMock.On("FindCeremonyByID", ctx, uint(5)).Return(tc.ceremony, nil)
+			repoMock.On("FindKeyByVaultID", ctx, uint(1)).Return(recoveryKey(), nil)
+			var saved *models.RecoveryCeremony
+			repoMock.On("SaveCeremony", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(*models.RecoveryCeremony)
+			})
+
+			// when
+			actual, error := tc.call(recoverySvc, ctx)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+
+			if tc.saved == "" {
+				assert.Nil(t, saved)
+				return
+			}
+
+			assert.Equal(t, tc.saved, saved.Status)
+
+			switch tc.saved {
+			case models.RecoveryPending, models.RecoveryApproved:
+				detail := actual.(*models.RecoveryCeremonyDetail)
+				assert.Equal(t, tc.saved, detail.Status)
+				assert.Equal(t, uint(30), saved.Approvals[len(saved.Approvals)-1].AdminID)
+				assert.Equal(t, []byte("share"), saved.Approvals[len(saved.Approvals)-1].Share)
+			case models.RecoveryCompleted:
+				assert.Equal(t, &models.RecoveryShares{CeremonyID: 5, VaultID: 1, Shares: [][]byte{[]byte("wrapped"), []byte("wrapped")}}, actual)
+				fallthrough
+			case models.RecoveryCancelled:
+				// then: the shares can't be handed out again
+				for _, approval := range saved.Approvals {
+					assert.Nil(t, approval.Share)
+				}
+			}
+		})
+	}
+}
+
+func approveCeremony(s *recoveryService, ctx context.Context) (any, error) {
+	return s.Approve(ctx, 5, []byte("share"))
+}
+
+func completeCeremony(s *recoveryService, ctx context.Context) (any, error) {
+	return s.Complete(ctx, 5)
+}
+
+func cancelCeremony(s *recoveryService, ctx context.Context) (any, error) {
+	return nil, s.Cancel(ctx, 5)
+}
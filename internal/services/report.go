@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/edgardjr92/gopass/pkg/strength"
+)
+
+// DefaultMaxPasswordAgeDays is used when a report is requested without a maximum password age.
+const DefaultMaxPasswordAgeDays = 365
+
+// Penalties applied to the score of an item for each issue found.
+// The penalty of a single item is capped at 1.
+const (
+	breachedPenalty = 1
+	reusedPenalty   = 0.5
+	weakPenalty     = 0.5
+	oldPenalty      = 0.25
+	insecurePenalty = 0.25
+)
+
+type IReportService interface {
+	// GetUserReport returns the health report of all vaults from the authenticated user.
+	// Passwords not updated in the last maxAgeDays days are reported as old.
+	GetUserReport(ctx context.Context, maxAgeDays uint) (*models.HealthReport, error)
+	// GetVaultReport returns the health report of a single vault from the authenticated user.
+	// Passwords not updated in the last maxAgeDays days are reported as old.
+	GetVaultReport(ctx context.Context, vaultID, maxAgeDays uint) (*models.HealthReport, error)
+}
+
+type reportService struct {
+	vaultRepository repositories.IVaultRepository
+	itemRepository  repositories.IItemRepository
+	breachChecker   IBreachChecker
+	clock           clock.Clock
+}
+
+// NewReportService creates a report service.
+// The breach checker is optional; breached passwords are not reported when it is nil.
+func NewReportService(
+	vaultRepository repositories.IVaultRepository,
+	itemRepository repositories.IItemRepository,
+	breachChecker IBreachChecker,
+	clock clock.Clock,
+) *reportService {
+	return &reportService{vaultRepository, itemRepository, breachChecker, clock}
+}
+
+func (r *reportService) GetUserReport(ctx context.Context, maxAgeDays uint) (*models.HealthReport, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	vaults, err := r.vaultRepository.FindByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find all vaults by userId: %v", err.Error())
+		return nil, err
+	}
+
+	vaultIDs := make([]uint, 0, len(vaults))
+	for _, vault := range vaults {
+		vaultIDs = append(vaultIDs, vault.ID)
+	}
+
+	report, err := r.build(ctx, vaultIDs, maxAgeDays)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report.UserID = userID
+
+	return report, nil
+}
+
+func (r *reportService) GetVaultReport(ctx context.Context, vaultID, maxAgeDays uint) (*models.HealthReport, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	vaults, err := r.vaultRepository.FindByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find all vaults by userId: %v", err.Error())
+		return nil, err
+	}
+
+	found := false
+	for _, vault := range vaults {
+		if vault.ID == vaultID {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return nil, cerrors.NotFoundError("vault not found")
+	}
+
+	report, err := r.build(ctx, []uint{vaultID}, maxAgeDays)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report.UserID = userID
+	report.VaultID = vaultID
+
+	return report, nil
+}
+
+func (r *reportService) build(ctx context.Context, vaultIDs []uint, maxAgeDays uint) (*models.HealthReport, error) {
+	report := &models.HealthReport{
+		Reused:       []models.ReusedPassword{},
+		Weak:         []models.ItemReference{},
+		Old:          []models.ItemReference{},
+		Breached:     []models.BreachedItem{},
+		InsecureURLs: []models.ItemReference{},
+	}
+
+	if len(vaultIDs) == 0 {
+		report.Score = 100
+		return report, nil
+	}
+
+	items, err := r.itemRepository.FindByVaultIDs(ctx, vaultIDs)
+
+	if err != nil {
+		log.Printf("error while trying to find items by vaultIds: %v", err.Error())
+		return nil, err
+	}
+
+	if maxAgeDays == 0 {
+		maxAgeDays = DefaultMaxPasswordAgeDays
+	}
+	oldBefore := r.clock.Now().AddDate(0, 0, -int(maxAgeDays))
+
+	// Passwords are grouped by a keyed hash so the report never carries
+	// anything that could be used to recover them.
+	fingerprintKey := make([]byte, 32)
+	if _, err := rand.Read(fingerprintKey); err != nil {
+		return nil, err
+	}
+
+	penalties := make([]float64, len(items))
+	groups := map[string][]int{}
+	fingerprints := []string{}
+	breaches := map[string]uint32{}
+
+	for i, item := range items {
+		ref := models.ItemReference{ID: item.ID, Name: item.Name, VaultID: item.VaultID}
+
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(item.Url)), "http://") {
+			report.InsecureURLs = append(report.InsecureURLs, ref)
+			penalties[i] += insecurePenalty
+		}
+
+		if item.Password == "" {
+			continue
+		}
+
+		if item.UpdatedAt.Before(oldBefore) {
+			report.Old = append(report.Old, ref)
+			penalties[i] += oldPenalty
+		}
+
+		if strength.IsWeak(item.Password) {
+			report.Weak = append(report.Weak, ref)
+			penalties[i] += weakPenalty
+		}
+
+		if r.breachChecker != nil {
+			count, checked := breaches[item.Password]
+
+			if !checked {
+				count, err = r.breachChecker.Check(ctx, item.Password)
+
+				if err != nil {
+					log.Printf("error while trying to check password breaches: %v", err.Error())
+					return nil, err
+				}
+
+				breaches[item.Password] = count
+			}
+
+			if count > 0 {
+				report.Breached = append(report.Breached, models.BreachedItem{ItemReference: ref, Occurrences: count})
+				penalties[i] += breachedPenalty
+			}
+		}
+
+		mac := hmac.New(sha256.New, fingerprintKey)
+		mac.Write([]byte(item.Password))
+		fingerprint := hex.EncodeToString(mac.Sum(nil)[:8])
+
+		if _, ok := groups[fingerprint]; !ok {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+		groups[fingerprint] = append(groups[fingerprint], i)
+	}
+
+	for _, fingerprint := range fingerprints {
+		indexes := groups[fingerprint]
+
+		if len(indexes) < 2 {
+			continue
+		}
+
+		reused := models.ReusedPassword{Fingerprint: fingerprint}
+		for _, i := range indexes {
+			reused.Items = append(reused.Items, models.ItemReference{ID: items[i].ID, Name: items[i].Name, VaultID: items[i].VaultID})
+			penalties[i] += reusedPenalty
+		}
+		report.Reused = append(report.Reused, reused)
+	}
+
+	report.TotalItems = len(items)
+	report.Score = score(penalties)
+
+	return report, nil
+}
+
+func score(penalties []float64) int {
+	if len(penalties) == 0 {
+		return 100
+	}
+
+	total := 0.0
+	for _, penalty := range penalties {
+		total += math.Min(penalty, 1)
+	}
+
+	return int(math.Round(100 * (1 - total/float64(len(penalties)))))
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestNewReportService(t *testing.T) {
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	itemRepoMock := &mocks.ItemRepositoryMock{}
+	checkerMock := &mocks.BreachCheckerMock{}
+	clockMock := clock.Clock{}
+
+	reportSvc := NewReportService(vaultRepoMock, itemRepoMock, checkerMock, clockMock)
+
+	assert.Equal(t, vaultRepoMock, reportSvc.vaultRepository)
+	assert.Equal(t, itemRepoMock, reportSvc.itemRepository)
+	assert.Equal(t, checkerMock, reportSvc.breachChecker)
+	assert.Equal(t, clockMock, reportSvc.clock)
+}
+
+func TestGetUserReport(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	now := time.Date(2023, 5, 6, 0, 0, 0, 0, time.UTC)
+	clockMock := clock.Clock{NowFn: func() time.Time { return now }}
+
+	vaults := []models.Vault{
+		{Model: gorm.Model{ID: 1}, Name: "Personal", UserID: userID},
+		{Model: gorm.Model{ID: 2}, Name: "Work", UserID: userID},
+	}
+
+	strong := "correct-Horse-battery-9"
+	items := []models.Item{
+		{Model: gorm.Model{ID: 1, UpdatedAt: now}, Name: "GitHub", Url: "https://github.com", Password: strong, VaultID: 1},
+		{Model: gorm.Model{ID: 2, UpdatedAt: now}, Name: "Mail", Url: "https://mail.com", Password: strong, VaultID: 2},
+		{Model: gorm.Model{ID: 3, UpdatedAt: now.AddDate(-2, 0, 0)}, Name: "Forum", Url: "HTTP://forum.com", Password: "Zx!9pQ#mW2$vLr7&", VaultID: 1},
+		{Model: gorm.Model{ID: 4, UpdatedAt: now}, Name: "Router", Password: "abc123", VaultID: 2},
+		{Model: gorm.Model{ID: 5, UpdatedAt: now.AddDate(-2, 0, 0)}, Name: "Note", VaultID: 2},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		checkerMock := &mocks.BreachCheckerMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1, 2}).Return(items, nil)
+		checkerMock.On("Check", ctx, strong).Return(uint32(0), nil).Once()
+		checkerMock.On("Check", ctx, "Zx!9pQ#mW2$vLr7&").Return(uint32(0), nil).Once()
+		checkerMock.On("Check", ctx, "abc123").Return(uint32(5), nil).Once()
+
+		// when
+		reportSvc := &reportService{vaultRepoMock, itemRepoMock, checkerMock, clockMock}
+		actual, error := reportSvc.GetUserReport(ctx, 0)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, userID, actual.UserID)
+		assert.Equal(t, uint(0), actual.VaultID)
+		assert.Equal(t, 5, actual.TotalItems)
+		assert.Equal(t, 50, actual.Score)
+
+		assert.Len(t, actual.Reused, 1)
+		assert.Len(t, actual.Reused[0].Fingerprint, 16)
+		assert.NotContains(t, actual.Reused[0].Fingerprint, strong)
+		assert.Equal(t, []models.ItemReference{
+			{ID: 1, Name: "GitHub", VaultID: 1},
+			{ID: 2, Name: "Mail", VaultID: 2},
+		}, actual.Reused[0].Items)
+
+		assert.Equal(t, []models.ItemReference{{ID: 4, Name: "Router", VaultID: 2}}, actual.Weak)
+		assert.Equal(t, []models.ItemReference{{ID: 3, Name: "Forum", VaultID: 1}}, actual.Old)
+		assert.Equal(t, []models.ItemReference{{ID: 3, Name: "Forum", VaultID: 1}}, actual.InsecureURLs)
+		assert.Equal(t, []models.BreachedItem{
+			{ItemReference: models.ItemReference{ID: 4, Name: "Router", VaultID: 2}, Occurrences: 5},
+		}, actual.Breached)
+
+		vaultRepoMock.AssertExpectations(t)
+		itemRepoMock.AssertExpectations(t)
+		checkerMock.AssertExpectations(t)
+	})
+
+	t.Run("custom max age", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1, 2}).Return([]models.Item{
+			{Model: gorm.Model{ID: 1, UpdatedAt: now.AddDate(0, 0, -31)}, Name: "GitHub", Password: strong, VaultID: 1},
+			{Model: gorm.Model{ID: 2, UpdatedAt: now.AddDate(0, 0, -29)}, Name: "Mail", Password: "Zx!9pQ#mW2$vLr7&", VaultID: 2},
+		}, nil)
+
+		// when
+		reportSvc := &reportService{vaultRepoMock, itemRepoMock, nil, clockMock}
+		actual, error := reportSvc.GetUserReport(ctx, 30)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []models.ItemReference{{ID: 1, Name: "GitHub", VaultID: 1}}, actual.Old)
+		assert.Equal(t, []models.BreachedItem{}, actual.Breached)
+		assert.Equal(t, 88, actual.Score)
+	})
+
+	t.Run("no vaults", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return([]models.Vault{}, nil)
+
+		// when
+		reportSvc := &reportService{vaultRepoMock, itemRepoMock, nil, clockMock}
+		actual, error := reportSvc.GetUserReport(ctx, 0)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, 100, actual.Score)
+		assert.Equal(t, 0, actual.TotalItems)
+
+		itemRepoMock.AssertNotCalled(t, "FindByVaultIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// when
+		reportSvc := &reportService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, nil, clockMock}
+		actual, error := reportSvc.GetUserReport(context.TODO(), 0)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "user is not authenticated", error.Error())
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		checkerMock := &mocks.BreachCheckerMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1, 2}).Return(items, nil)
+		checkerMock.On("Check", ctx, mock.Anything).Return(uint32(0), errors.New("error when reading index"))
+
+		// when
+		reportSvc := &reportService{vaultRepoMock, itemRepoMock, checkerMock, clockMock}
+		actual, error := reportSvc.GetUserReport(ctx, 0)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "error when reading index", error.Error())
+	})
+}
+
+func TestGetVaultReport(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+	clockMock := clock.Clock{}
+
+	vaults := []models.Vault{{Model: gorm.Model{ID: 1}, Name: "Personal", UserID: userID}}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+		itemRepoMock.On("FindByVaultIDs", ctx, []uint{1}).Return([]models.Item{
+			{Model: gorm.Model{ID: 1, UpdatedAt: time.Now()}, Name: "Router", Url: "http://192.168.0.1", Password: "admin", VaultID: 1},
+		}, nil)
+
+		// when
+		reportSvc := &reportService{vaultRepoMock, itemRepoMock, nil, clockMock}
+		actual, error := reportSvc.GetVaultReport(ctx, 1, 0)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, uint(1), actual.VaultID)
+		assert.Equal(t, 1, actual.TotalItems)
+		assert.Equal(t, 25, actual.Score)
+		assert.Len(t, actual.Weak, 1)
+		assert.Len(t, actual.InsecureURLs, 1)
+
+		vaultRepoMock.AssertExpectations(t)
+		itemRepoMock.AssertExpectations(t)
+	})
+
+	t.Run("vault not found", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return(vaults, nil)
+
+		// when
+		reportSvc := &reportService{vaultRepoMock, itemRepoMock, nil, clockMock}
+		actual, error := reportSvc.GetVaultReport(ctx, 2, 0)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "vault not found", error.Error())
+
+		itemRepoMock.AssertNotCalled(t, "FindByVaultIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// when
+		reportSvc := &reportService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, nil, clockMock}
+		actual, error := reportSvc.GetVaultReport(context.TODO(), 1, 0)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "user is not authenticated", error.Error())
+	})
+}
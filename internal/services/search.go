@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/edgardjr92/gopass/pkg/search"
+)
+
+const (
+	// DefaultSearchLimit is the number of results returned when no limit is given.
+	DefaultSearchLimit = 20
+	// MaxSearchLimit is the maximum number of results returned by a search.
+	MaxSearchLimit = 100
+	// searchIndexMaxAge is how long a search index is used before being rebuilt,
+	// so writes that don't go through the item and vault services, such as
+	// imports and restores, eventually show up in search results.
+	searchIndexMaxAge = 15 * time.Minute
+)
+
+// Weights of the searchable fields. Passwords are never indexed.
+const (
+	nameWeight     = 3
+	usernameWeight = 2
+	hostWeight     = 2
+	tagsWeight     = 2
+	notesWeight    = 1
+)
+
+type ISearchService interface {
+	// Search returns the vaults and items from the authenticated user matching the query, best first.
+	// It returns at most limit results, DefaultSearchLimit when limit is zero.
+	Search(ctx context.Context, query string, limit uint) ([]models.SearchResult, error)
+}
+
+type searchKey struct {
+	kind string
+	id   uint
+}
+
+// userIndex is the search index of the vaults and items from a user.
+type userIndex struct {
+	index *search.Index[searchKey]
+	// results hold the fields returned for each document.
+	results map[searchKey]models.SearchResult
+	builtAt time.Time
+}
+
+type searchService struct {
+	vaultRepository repositories.IVaultRepository
+	itemRepository  repositories.IItemRepository
+	clock           clock.Clock
+
+	mu      sync.Mutex
+	indexes map[uint]*userIndex
+	// generations count the writes of each user, so an index built while
+	// a write happened isn't kept.
+	generations map[uint]uint64
+}
+
+// NewSearchService creates a search service keeping an in-memory index per user.
+// The index is built on the first search of the user and kept up to date by the
+// services returned by NewSearchIndexedItemService, NewSearchIndexedVaultService
+// and NewSearchIndexedTagService.
+func NewSearchService(
+	vaultRepository repositories.IVaultRepository,
+	itemRepository repositories.IItemRepository,
+	clock clock.Clock,
+) *searchService {
+	return &searchService{
+		vaultRepository: vaultRepository,
+		itemRepository:  itemRepository,
+		clock:           clock,
+		indexes:         map[uint]*userIndex{},
+		generations:     map[uint]uint64{},
+	}
+}
+
+func (s *searchService) Search(ctx context.Context, query string, limit uint) ([]models.SearchResult, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return []models.SearchResult{}, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(query) {
+		return []models.SearchResult{}, cerrors.BadRequestError("query is required")
+	}
+
+	if limit == 0 {
+		limit = DefaultSearchLimit
+	}
+
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	ui, err := s.userIndex(ctx, userID)
+
+	if err != nil {
+		return []models.SearchResult{}, err
+	}
+
+	matches := ui.index.Search(query, int(limit))
+	results := make([]models.SearchResult, 0, len(matches))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, match := range matches {
+		result, ok := ui.results[match.Key]
+
+		if !ok {
+			continue
+		}
+
+		result.Score = match.Score
+		result.Matches = match.Fields
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// userIndex returns the index of the user, building it when it's missing or too old.
+func (s *searchService) userIndex(ctx context.Context, userID uint) (*userIndex, error) {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	ui := s.indexes[userID]
+	generation := s.generations[userID]
+	s.mu.Unlock()
+
+	if ui != nil && now.Sub(ui.builtAt) < searchIndexMaxAge {
+		return ui, nil
+	}
+
+	vaults, err := s.vaultRepository.FindByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find all vaults by userId: %v", err.Error())
+		return nil, err
+	}
+
+	vaultNames := map[uint]string{}
+
+	for _, vault := range vaults {
+		vaultNames[vault.ID] = vault.Name
+	}
+
+	items, err := s.itemRepository.FindByVaultIDs(ctx, utils.Map(vaults, func(v models.Vault) uint { return v.ID }))
+
+	if err != nil {
+		log.Printf("error while trying to find items by vaultIds: %v", err.Error())
+		return nil, err
+	}
+
+	ui = &userIndex{index: search.NewIndex[searchKey](), results: map[searchKey]models.SearchResult{}, builtAt: now}
+
+	for _, vault := range vaults {
+		ui.putVault(vault)
+	}
+
+	for _, item := range items {
+		ui.putItem(item, vaultNames[item.VaultID])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.generations[userID] == generation {
+		s.indexes[userID] = ui
+	}
+
+	return ui, nil
+}
+
+// itemChanged updates the item in the index of the user, after it was created, updated or deleted.
+func (s *searchService) itemChanged(ctx context.Context, userID, itemID uint) {
+	s.mu.Lock()
+	s.generations[userID]++
+	ui := s.indexes[userID]
+	s.mu.Unlock()
+
+	if ui == nil {
+		return
+	}
+
+	key := searchKey{models.SearchKindItem, itemID}
+	item, err := s.itemRepository.FindByID(ctx, itemID)
+
+	if err != nil {
+		log.Printf("error while trying to find an item by id: %v", err.Error())
+		s.invalidate(userID)
+		return
+	}
+
+	if item.ID == 0 {
+		ui.index.Delete(key)
+
+		s.mu.Lock()
+		delete(ui.results, key)
+		s.mu.Unlock()
+
+		return
+	}
+
+	s.mu.Lock()
+	vault, ok := ui.results[searchKey{models.SearchKindVault, item.VaultID}]
+	s.mu.Unlock()
+
+	if !ok {
+		s.invalidate(userID)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ui.putItem(*item, vault.Name)
+}
+
+// invalidate drops the index of the user, which is rebuilt on the next search.
+func (s *searchService) invalidate(userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generations[userID]++
+	delete(s.indexes, userID)
+}
+
+func (ui *userIndex) putVault(vault models.Vault) {
+	key := searchKey{models.SearchKindVault, vault.ID}
+
+	ui.index.Put(search.Document[searchKey]{Key: key, Fields: []search.Field{
+		{Name: "name", Text: vault.Name, Weight: nameWeight},
+	}})
+
+	ui.results[key] = models.SearchResult{
+		Kind:      models.SearchKindVault,
+		ID:        vault.ID,
+		VaultID:   vault.ID,
+		VaultName: vault.Name,
+		Name:      vault.Name,
+	}
+}
+
+func (ui *userIndex) putItem(item models.Item, vaultName string) {
+	key := searchKey{models.SearchKindItem, item.ID}
+
+	ui.index.Put(search.Document[searchKey]{Key: key, Fields: []search.Field{
+		{Name: "name", Text: item.Name, Weight: nameWeight},
+		{Name: "username", Text: item.Username, Weight: usernameWeight},
+		{Name: "host", Text: urlHost(item.Url), Weight: hostWeight},
+		{Name: "tags", Text: strings.Join(tagNames(item.Tags), " "), Weight: tagsWeight},
+		{Name: "notes", Text: item.Notes, Weight: notesWeight},
+	}})
+
+	ui.results[key] = models.SearchResult{
+		Kind:      models.SearchKindItem,
+		ID:        item.ID,
+		VaultID:   item.VaultID,
+		VaultName: vaultName,
+		Name:      item.Name,
+		Username:  item.Username,
+		Url:       item.Url,
+	}
+}
+
+// urlHost returns the host of an item URL, which may be a bare host such as "github.com".
+func urlHost(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+
+	if rawURL == "" {
+		return ""
+	}
+
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "//" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
+
+// searchIndexedItemService is an item service updating the search index on writes.
+type searchIndexedItemService struct {
+	IItemService
+	search *searchService
+}
+
+func NewSearchIndexedItemService(itemService IItemService, searchService *searchService) *searchIndexedItemService {
+	return &searchIndexedItemService{itemService, searchService}
+}
+
+func (i *searchIndexedItemService) Create(ctx context.Context, vaultID uint, input models.ItemInput) (uint, error) {
+	id, err := i.IItemService.Create(ctx, vaultID, input)
+
+	if err == nil {
+		i.search.itemChanged(ctx, ctx.Value(keys.UserIDKey).(uint), id)
+	}
+
+	return id, err
+}
+
+func (i *searchIndexedItemService) Update(ctx context.Context, id uint, input models.ItemInput) error {
+	err := i.IItemService.Update(ctx, id, input)
+
+	if err == nil {
+		i.search.itemChanged(ctx, ctx.Value(keys.UserIDKey).(uint), id)
+	}
+
+	return err
+}
+
+func (i *searchIndexedItemService) Delete(ctx context.Context, id uint) error {
+	err := i.IItemService.Delete(ctx, id)
+
+	if err == nil {
+		i.search.itemChanged(ctx, ctx.Value(keys.UserIDKey).(uint), id)
+	}
+
+	return err
+}
+
+// searchIndexedVaultService is a vault service updating the search index on writes.
+// Vault writes are rare, so they drop the index of the user instead of updating it.
+type searchIndexedVaultService struct {
+	IVaultService
+	search *searchService
+}
+
+func NewSearchIndexedVaultService(vaultService IVaultService, searchService *searchService) *searchIndexedVaultService {
+	return &searchIndexedVaultService{vaultService, searchService}
+}
+
+func (v *searchIndexedVaultService) Create(ctx context.Context, name string) (uint, error) {
+	id, err := v.IVaultService.Create(ctx, name)
+
+	if err == nil {
+		v.search.invalidate(ctx.Value(keys.UserIDKey).(uint))
+	}
+
+	return id, err
+}
+
+func (v *searchIndexedVaultService) Rename(ctx context.Context, id uint, name string, revision uint64) error {
+	err := v.IVaultService.Rename(ctx, id, name, revision)
+
+	if err == nil {
+		v.search.invalidate(ctx.Value(keys.UserIDKey).(uint))
+	}
+
+	return err
+}
+
+func (v *searchIndexedVaultService) Delete(ctx context.Context, id uint) error {
+	err := v.IVaultService.Delete(ctx, id)
+
+	if err == nil {
+		v.search.invalidate(ctx.Value(keys.UserIDKey).(uint))
+	}
+
+	return err
+}
+
+// searchIndexedTagService is a tag service updating the search index on writes.
+// A tag write changes many items, so it drops the index of the user like vault writes.
+type searchIndexedTagService struct {
+	ITagService
+	search *searchService
+}
+
+func NewSearchIndexedTagService(tagService ITagService, searchService *searchService) *searchIndexedTagService {
+	return &searchIndexedTagService{tagService, searchService}
+}
+
+func (t *searchIndexedTagService) Rename(ctx context.Context, id uint, name string) error {
+	err := t.ITagService.Rename(ctx, id, name)
+
+	if err == nil {
+		t.search.invalidate(ctx.Value(keys.UserIDKey).(uint))
+	}
+
+	return err
+}
+
+func (t *searchIndexedTagService) Delete(ctx context.Context, id uint) error {
+	err := t.ITagService.Delete(ctx, id)
+
+	if err == nil {
+		t.search.invalidate(ctx.Value(keys.UserIDKey).(uint))
+	}
+
+	return err
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestNewSearchService(t *testing.T) {
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	itemRepoMock := &mocks.ItemRepositoryMock{}
+	clk := clock.Clock{}
+
+	searchSvc := NewSearchService(vaultRepoMock, itemRepoMock, clk)
+
+	assert.Equal(t, vaultRepoMock, searchSvc.vaultRepository)
+	assert.Equal(t, itemRepoMock, searchSvc.itemRepository)
+	assert.Equal(t, clk, searchSvc.clock)
+}
+
+// searchFixture mocks the vaults and items of user 10 for a search service.
+func searchFixture(ctx context.Context) (*mocks.VaultRepositoryMock, *mocks.ItemRepositoryMock) {
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	itemRepoMock := &mocks.ItemRepositoryMock{}
+
+	vaultRepoMock.On("FindByUserID", ctx, uint(10)).Return([]models.Vault{
+		{Model: gorm.Model{ID: 1}, Name: "Work", UserID: 10},
+		{Model: gorm.Model{ID: 2}, Name: "GitHub orgs", UserID: 10},
+	}, nil)
+	itemRepoMock.On("FindByVaultIDs", ctx, []uint{1, 2}).Return([]models.Item{
+		{Model: gorm.Model{ID: 5}, Name: "GitHub", Username: "octocat", Url: "https://github.com/login", Password: "hunter2", VaultID: 1},
+		{Model: gorm.Model{ID: 6}, Name: "Email", Username: "john@acme.com", Notes: "backup codes in github gist", VaultID: 1},
+		{Model: gorm.Model{ID: 7}, Name: "Acme", Username: "deploy", Url: "git.acme.com", VaultID: 2, Tags: []models.Tag{{Name: "infra"}}},
+	}, nil)
+
+	return vaultRepoMock, itemRepoMock
+}
+
+func TestSearch(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		vaultRepoMock, itemRepoMock := searchFixture(ctx)
+
+		// when
+		searchSvc := NewSearchService(vaultRepoMock, itemRepoMock, clock.Clock{})
+		actual, error := searchSvc.Search(ctx, "github", 0)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []models.SearchResult{
+			{Kind: "item", ID: 5, VaultID: 1, VaultName: "Work", Name: "GitHub", Username: "octocat", Url: "https://github.com/login", Score: 3, Matches: []string{"name", "host"}},
+			{Kind: "vault", ID: 2, VaultID: 2, VaultName: "GitHub orgs", Name: "GitHub orgs", Score: 3, Matches: []string{"name"}},
+			{Kind: "item", ID: 6, VaultID: 1, VaultName: "Work", Name: "Email", Username: "john@acme.com", Score: 1, Matches: []string{"notes"}},
+		}, actual)
+	})
+
+	testCases := []struct {
+		name  string
+		query string
+		limit uint
+		ids   []uint
+	}{
+		{"fuzzy", "gihtub", 0, []uint{5, 2, 6}},
+		{"prefix", "oct", 0, []uint{5}},
+		{"url host", "acme com", 0, []uint{7, 6}},
+		{"tags", "infra", 0, []uint{7}},
+		{"limit", "github", 1, []uint{5}},
+		{"passwords are not searched", "hunter2", 0, []uint{}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			vaultRepoMock, itemRepoMock := searchFixture(ctx)
+
+			// when
+			searchSvc := NewSearchService(vaultRepoMock, itemRepoMock, clock.Clock{})
+			actual, error := searchSvc.Search(ctx, tc.query, tc.limit)
+
+			// then
+			assert.Nil(t, error)
+			assert.Equal(t, tc.ids, resultIDs(actual))
+		})
+	}
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// when
+		searchSvc := NewSearchService(&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, clock.Clock{})
+		actual, error := searchSvc.Search(context.TODO(), "github", 0)
+
+		// then
+		assert.Empty(t, actual)
+		assert.Equal(t, "user is not authenticated", error.Error())
+	})
+
+	t.Run("query is required", func(t *testing.T) {
+		// when
+		searchSvc := NewSearchService(&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, clock.Clock{})
+		actual, error := searchSvc.Search(ctx, " ", 0)
+
+		// then
+		assert.Empty(t, actual)
+		assert.Equal(t, "query is required", error.Error())
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		vaultRepoMock.On("FindByUserID", ctx, userID).Return([]models.Vault{}, errors.New("error when finding vaults"))
+
+		// when
+		searchSvc := NewSearchService(vaultRepoMock, &mocks.ItemRepositoryMock{}, clock.Clock{})
+		actual, error := searchSvc.Search(ctx, "github", 0)
+
+		// then
+		assert.Empty(t, actual)
+		assert.Equal(t, "error when finding vaults", error.Error())
+	})
+}
+
+func TestSearchIndexCache(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	now := time.Date(2023, 5, 6, 10, 0, 0, 0, time.UTC)
+	clk := clock.Clock{NowFn: func() time.Time { return now }}
+
+	// given
+	vaultRepoMock, itemRepoMock := searchFixture(ctx)
+	searchSvc := NewSearchService(vaultRepoMock, itemRepoMock, clk)
+
+	// when
+	_, _ = searchSvc.Search(ctx, "github", 0)
+	now = now.Add(searchIndexMaxAge - time.Second)
+	_, _ = searchSvc.Search(ctx, "github", 0)
+
+	// then
+	vaultRepoMock.AssertNumberOfCalls(t, "FindByUserID", 1)
+
+	now = now.Add(time.Second)
+	_, _ = searchSvc.Search(ctx, "github", 0)
+	vaultRepoMock.AssertNumberOfCalls(t, "FindByUserID", 2)
+}
+
+func TestSearchIndexedItemService(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	input := models.ItemInput{Name: "GitLab"}
+
+	// given
+	vaultRepoMock, itemRepoMock := searchFixture(ctx)
+	searchSvc := NewSearchService(vaultRepoMock, itemRepoMock, clock.Clock{})
+	_, _ = searchSvc.Search(ctx, "github", 0)
+
+	itemSvcMock := &mocks.ItemServiceMock{}
+	itemSvcMock.On("Create", ctx, uint(2), input).Return(uint(8), nil)
+	itemSvcMock.On("Update", ctx, uint(5), mock.Anything).Return(nil)
+	itemSvcMock.On("Delete", ctx, uint(6)).Return(nil)
+	itemSvcMock.On("Delete", ctx, uint(7)).Return(errors.New("error when deleting item"))
+	itemRepoMock.On("FindByID", ctx, uint(8)).Return(&models.Item{Model: gorm.Model{ID: 8}, Name: "GitLab", Url: "gitlab.com", VaultID: 2}, nil)
+	itemRepoMock.On("FindByID", ctx, uint(5)).Return(&models.Item{Model: gorm.Model{ID: 5}, Name: "Bitbucket", VaultID: 1}, nil)
+	itemRepoMock.On("FindByID", ctx, uint(6)).Return(&models.Item{}, nil)
+
+	itemSvc := NewSearchIndexedItemService(itemSvcMock, searchSvc)
+
+	// when
+	id, error := itemSvc.Create(ctx, 2, input)
+	assert.Equal(t, uint(8), id)
+	assert.Nil(t, error)
+	assert.Nil(t, itemSvc.Update(ctx, 5, models.ItemInput{Name: "Bitbucket"}))
+	assert.Nil(t, itemSvc.Delete(ctx, 6))
+	assert.NotNil(t, itemSvc.Delete(ctx, 7))
+
+	// then: the index was updated without being rebuilt
+	gitlab, _ := searchSvc.Search(ctx, "gitlab", 0)
+	assert.Equal(t, []uint{8}, resultIDs(gitlab))
+	assert.Equal(t, "GitHub orgs", gitlab[0].VaultName)
+
+	github, _ := searchSvc.Search(ctx, "github", 0)
+	assert.Equal(t, []uint{2}, resultIDs(github))
+
+	acme, _ := searchSvc.Search(ctx, "acme", 0)
+	assert.Equal(t, []uint{7}, resultIDs(acme))
+
+	vaultRepoMock.AssertNumberOfCalls(t, "FindByUserID", 1)
+}
+
+func TestSearchIndexedVaultService(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	// given
+	vaultRepoMock, itemRepoMock := searchFixture(ctx)
+	searchSvc := NewSearchService(vaultRepoMock, itemRepoMock, clock.Clock{})
+	_, _ = searchSvc.Search(ctx, "github", 0)
+
+	vaultSvcMock := &mocks.VaultServiceMock{}
+	vaultSvcMock.On("Rename", ctx, uint(2), "Orgs", uint64(5)).Return(nil)
+
+	// when
+	error := NewSearchIndexedVaultService(vaultSvcMock, searchSvc).Rename(ctx, 2, "Orgs", 5)
+
+	// then: the index is rebuilt on the next search
+	assert.Nil(t, error)
+	_, _ = searchSvc.Search(ctx, "github", 0)
+	vaultRepoMock.AssertNumberOfCalls(t, "FindByUserID", 2)
+}
+
+func TestSearchIndexedTagService(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	// given
+	vaultRepoMock, itemRepoMock := searchFixture(ctx)
+	searchSvc := NewSearchService(vaultRepoMock, itemRepoMock, clock.Clock{})
+	_, _ = searchSvc.Search(ctx, "infra", 0)
+
+	tagSvcMock := &mocks.TagServiceMock{}
+	tagSvcMock.On("Rename", ctx, uint(3), "ops").Return(nil)
+	tagSvcMock.On("Delete", ctx, uint(3)).Return(errors.New("error when deleting tag"))
+
+	tagSvc := NewSearchIndexedTagService(tagSvcMock, searchSvc)
+
+	// when
+	assert.NotNil(t, tagSvc.Delete(ctx, 3))
+	_, _ = searchSvc.Search(ctx, "infra", 0)
+	assert.Nil(t, tagSvc.Rename(ctx, 3, "ops"))
+	_, _ = searchSvc.Search(ctx, "infra", 0)
+
+	// then: only the rename rebuilt the index
+	vaultRepoMock.AssertNumberOfCalls(t, "FindByUserID", 2)
+}
+
+func resultIDs(results []models.SearchResult) []uint {
+	ids := make([]uint, len(results))
+
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+
+	return ids
+}
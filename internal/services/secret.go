@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/secretref"
+)
+
+// secretFinder finds the items referenced by secretref references among the
+// vaults of the authenticated user.
+type secretFinder struct {
+	vaultService IVaultService
+	itemService  IItemService
+}
+
+// NewSecretFinder returns a secretref.ItemFinder over the vault and item services.
+func NewSecretFinder(vaultService IVaultService, itemService IItemService) *secretFinder {
+	return &secretFinder{vaultService, itemService}
+}
+
+func (s *secretFinder) FindItem(ctx context.Context, vaultName, itemName string) (secretref.Item, error) {
+	vaults, err := s.vaultService.GetAll(ctx)
+
+	if err != nil {
+		log.Printf("error while trying to find all vaults: %v", err.Error())
+		return nil, err
+	}
+
+	vault, err := secretref.Match(vaults, vaultName, func(v models.VaultDetail) string { return v.Name })
+
+	if err != nil {
+		return nil, fmt.Errorf("vault %q: %w", vaultName, err)
+	}
+
+	items, err := s.itemService.GetAll(ctx, vault.ID, models.ItemFilter{})
+
+	if err != nil {
+		log.Printf("error while trying to find all items by vaultId: %v", err.Error())
+		return nil, err
+	}
+
+	item, err := secretref.Match(items, itemName, func(i models.ItemDetail) string { return i.Name })
+
+	if err != nil {
+		return nil, fmt.Errorf("item %q in vault %q: %w", itemName, vaultName, err)
+	}
+
+	return secretref.Item{
+		"name":     item.Name,
+		"username": item.Username,
+		"password": item.Password,
+		"url":      item.Url,
+		"notes":    item.Notes,
+	}, nil
+}
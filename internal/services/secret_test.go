@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/secretref"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSecretFinder(t *testing.T) {
+	vaultSvcMock := &mocks.VaultServiceMock{}
+	itemSvcMock := &mocks.ItemServiceMock{}
+
+	finder := NewSecretFinder(vaultSvcMock, itemSvcMock)
+
+	assert.Equal(t, vaultSvcMock, finder.vaultService)
+	assert.Equal(t, itemSvcMock, finder.itemService)
+}
+
+func TestFindSecretItem(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	vaultSvcMock := &mocks.VaultServiceMock{}
+	itemSvcMock := &mocks.ItemServiceMock{}
+
+	vaultSvcMock.On("GetAll", ctx).Return([]models.VaultDetail{{ID: 1, Name: "Work"}}, nil)
+	itemSvcMock.On("GetAll", ctx, uint(1), models.ItemFilter{}).Return([]models.ItemDetail{
+		{ID: 5, VaultID: 1, Name: "Postgres", Username: "app", Password: "pg-secret", Url: "postgres://db"},
+	}, nil)
+
+	testCases := []struct {
+		name     string
+		vault    string
+		item     string
+		expected secretref.Item
+		err      error
+	}{
+		{"success", "Work", "postgres", secretref.Item{
+			"name": "Postgres", "username": "app", "password": "pg-secret", "url": "postgres://db", "notes": "",
+		}, nil},
+		{"vault not found", "Personal", "Postgres", nil, secretref.ErrNotFound},
+		{"item not found", "Work", "Redis", nil, secretref.ErrNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			finder := &secretFinder{vaultSvcMock, itemSvcMock}
+			actual, error := finder.FindItem(ctx, tc.vault, tc.item)
+
+			// then
+			assert.Equal(t, tc.expected, actual)
+			assert.True(t, errors.Is(error, tc.err))
+		})
+	}
+
+	t.Run("resolves references", func(t *testing.T) {
+		// when
+		resolver := secretref.NewResolver(&secretFinder{vaultSvcMock, itemSvcMock})
+		actual, error := resolver.Resolve(ctx, secretref.Ref{Vault: "Work", Item: "Postgres", Field: "password"})
+
+		// then
+		assert.Equal(t, "pg-secret", actual)
+		assert.Nil(t, error)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		vaultSvcMock := &mocks.VaultServiceMock{}
+		vaultSvcMock.On("GetAll", ctx).Return([]models.VaultDetail{}, errors.New("error when finding vaults"))
+
+		// when
+		finder := &secretFinder{vaultSvcMock, &mocks.ItemServiceMock{}}
+		actual, error := finder.FindItem(ctx, "Work", "Postgres")
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "error when finding vaults", error.Error())
+	})
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/clock"
+)
+
+const (
+	// sendMaxSize is the largest ciphertext of a send, in bytes.
+	sendMaxSize = 256 << 10
+	// sendMaxViews is the largest number of views of a send.
+	sendMaxViews = 100
+	// sendDefaultLifetime is the lifetime of sends created without one,
+	// and sendMaxLifetime the longest one.
+	sendDefaultLifetime = 24 * time.Hour
+	sendMaxLifetime     = 30 * 24 * time.Hour
+	// sendMaxFailedAttempts is the number of wrong access keys after which a
+	// send is deleted, so its password can't be guessed.
+	sendMaxFailedAttempts = 10
+	// sendPurgeInterval is the time between two purges of the expired sends.
+	sendPurgeInterval = time.Minute
+	// sendAccessIDSize is the number of random bytes of access IDs.
+	sendAccessIDSize = 16
+)
+
+type ISendService interface {
+	// Create stores a send of the authenticated user, encrypted by the client.
+	Create(ctx context.Context, input models.SendInput) (*models.SendDetail, error)
+	// GetAll returns the sends of the authenticated user that weren't purged yet.
+	GetAll(ctx context.Context) ([]models.SendDetail, error)
+	// Delete deletes a send before its expiry or its last view.
+	Delete(ctx context.Context, id uint) error
+	// Access counts a view of the send with the given access ID and returns its
+	// ciphertext. It doesn't need an authenticated user, only the access key
+	// derived from the access password of sends that have one. The send is
+	// deleted on its last view.
+	Access(ctx context.Context, accessID, accessKey string) (*models.SendContent, error)
+}
+
+type sendService struct {
+	repository    repositories.ISendRepository
+	transactor    repositories.ITransactor
+	clock         clock.Clock
+	purgeInterval time.Duration
+}
+
+// NewSendService creates a send service. The expired sends are purged while Run runs.
+func NewSendService(repository repositories.ISendRepository, transactor repositories.ITransactor, clock clock.Clock) *sendService {
+	return &sendService{repository, transactor, clock, sendPurgeInterval}
+}
+
+func (s *sendService) Create(ctx context.Context, input models.SendInput) (*models.SendDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if len(input.Ciphertext) == 0 {
+		return nil, cerrors.BadRequestError("ciphertext is required")
+	}
+
+	if len(input.Ciphertext) > sendMaxSize {
+		return nil, cerrors.BadRequestError(fmt.Sprintf("ciphertext must be at most %d bytes", sendMaxSize))
+	}
+
+	if input.MaxViews < 0 || input.MaxViews > sendMaxViews {
+		return nil, cerrors.BadRequestError(fmt.Sprintf("maxViews must be between 1 and %d", sendMaxViews))
+	}
+
+	lifetime := time.Duration(input.ExpiresIn) * time.Second
+
+	if input.ExpiresIn < 0 || lifetime > sendMaxLifetime {
+		return nil, cerrors.BadRequestError(fmt.Sprintf("expiresIn must be at most %d seconds", int64(sendMaxLifetime/time.Second)))
+	}
+
+	if input.MaxViews == 0 {
+		input.MaxViews = 1
+	}
+
+	if lifetime == 0 {
+		lifetime = sendDefaultLifetime
+	}
+
+	accessID := make([]byte, sendAccessIDSize)
+
+	if _, err := rand.Read(accessID); err != nil {
+		log.Printf("error while trying to generate send access id: %v", err.Error())
+		return nil, err
+	}
+
+	newSend := models.Send{
+		AccessID:   base64.RawURLEncoding.EncodeToString(accessID),
+		UserID:     userID,
+		Ciphertext: input.Ciphertext,
+		MaxViews:   input.MaxViews,
+		ExpiresAt:  s.clock.Now().Add(lifetime).UTC(),
+	}
+
+	if !utils.IsBlank(input.AccessKey) {
+		newSend.AccessHash = accessHash(input.AccessKey)
+	}
+
+	if err := s.repository.Save(ctx, &newSend); err != nil {
+		log.Printf("error while trying to save send: %v", err.Error())
+		return nil, err
+	}
+
+	detail := toSendDetail(newSend)
+
+	return &detail, nil
+}
+
+func (s *sendService) GetAll(ctx context.Context) ([]models.SendDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return []models.SendDetail{}, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	sends, err := s.repository.FindByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find sends by userId: %v", err.Error())
+		return []models.SendDetail{}, err
+	}
+
+	return utils.Map(sends, toSendDetail), nil
+}
+
+func (s *sendService) Delete(ctx context.Context, id uint) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	send, err := s.repository.FindByID(ctx, id)
+
+	if err != nil {
+		log.Printf("error while trying to find a send by id: %v", err.Error())
+		return err
+	}
+
+	if send.ID == 0 || send.UserID != userID {
+		return cerrors.NotFoundError("send not found")
+	}
+
+	if err := s.repository.Delete(ctx, id); err != nil {
+		log.Printf("error while trying to delete send: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *sendService) Access(ctx context.Context, accessID, accessKey string) (*models.SendContent, error) {
+	var content *models.SendContent
+	// denied is the error of a denied access, which still commits the
+	// transaction so failed attempts are counted.
+	var denied error
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		send, err := s.repository.FindByAccessID(ctx, accessID)
+
+		if err != nil {
+			log.Printf("error while trying to find a send by access id: %v", err.Error())
+			return err
+		}
+
+		if send.ID == 0 {
+			denied = cerrors.NotFoundError("send not found")
+			return nil
+		}
+
+		// Expired sends not purged yet are gone all the same.
+		if !s.clock.Now().Before(send.ExpiresAt) {
+			denied = cerrors.NotFoundError("send not found")
+			return s.delete(ctx, send.ID)
+		}
+
+		if send.AccessHash != "" {
+			if utils.IsBlank(accessKey) {
+				denied = cerrors.UnauthorizedError("access password required")
+				return nil
+			}
+
+			if subtle.ConstantTimeCompare([]byte(send.AccessHash), []byte(accessHash(accessKey))) != 1 {
+				denied = cerrors.UnauthorizedError("invalid access password")
+
+				if send.FailedAttempts++; send.FailedAttempts >= sendMaxFailedAttempts {
+					return s.delete(ctx, send.ID)
+				}
+
+				return s.save(ctx, send)
+			}
+		}
+
+		send.Views++
+		content = &models.SendContent{Ciphertext: send.Ciphertext, ViewsLeft: send.MaxViews - send.Views, ExpiresAt: send.ExpiresAt}
+
+		if send.Views >= send.MaxViews {
+			return s.delete(ctx, send.ID)
+		}
+
+		return s.save(ctx, send)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if denied != nil {
+		return nil, denied
+	}
+
+	return content, nil
+}
+
+// Run purges the expired sends every purgeInterval until ctx is done.
+func (s *sendService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		s.purge(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// purge deletes the sends expired by now.
+func (s *sendService) purge(ctx context.Context) {
+	if _, err := s.repository.DeleteExpired(ctx, s.clock.Now()); err != nil {
+		log.Printf("error while trying to delete expired sends: %v", err.Error())
+	}
+}
+
+func (s *sendService) save(ctx context.Context, send *models.Send) error {
+	if err := s.repository.Save(ctx, send); err != nil {
+		log.Printf("error while trying to save send: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *sendService) delete(ctx context.Context, id uint) error {
+	if err := s.repository.Delete(ctx, id); err != nil {
+		log.Printf("error while trying to delete send: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// accessHash returns the hash of an access key stored with a send. Access
+// keys are derived by the client with Argon2id, so a fast hash is enough.
+func accessHash(accessKey string) string {
+	sum := sha256.Sum256([]byte(accessKey))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func toSendDetail(send models.Send) models.SendDetail {
+	return models.SendDetail{
+		ID:          send.ID,
+		AccessID:    send.AccessID,
+		MaxViews:    send.MaxViews,
+		Views:       send.Views,
+		HasPassword: send.AccessHash != "",
+		ExpiresAt:   send.ExpiresAt,
+		CreatedAt:   send.CreatedAt,
+	}
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestNewSendService(t *testing.T) {
+	repoMock := &mocks.SendRepositoryMock{}
+	transactorMock := &mocks.TransactorMock{}
+
+	sendSvc := NewSendService(repoMock, transactorMock, clock.Clock{})
+
+	assert.Equal(t, repoMock, sendSvc.repository)
+	assert.Equal(t, transactorMock, sendSvc.transactor)
+	assert.Equal(t, sendPurgeInterval, sendSvc.purgeInterval)
+}
+
+func TestCreateSend(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	fixedClock := clock.Clock{NowFn: func() time.Time { return now }}
+
+	testCases := []struct {
+		name      string
+		input     models.SendInput
+		maxViews  int
+		expiresAt time.Time
+		expected  error
+	}{
+		{"defaults", models.SendInput{Ciphertext: []byte("sealed")}, 1, now.Add(24 * time.Hour), nil},
+		{"views, expiry and password", models.SendInput{Ciphertext: []byte("sealed"), AccessKey: "access-key", MaxViews: 5, ExpiresIn: 3600}, 5, now.Add(time.Hour), nil},
+		{"missing ciphertext", models.SendInput{}, 0, time.Time{}, cerrors.BadRequestError("ciphertext is required")},
+		{"ciphertext too large", models.SendInput{Ciphertext: make([]byte, sendMaxSize+1)}, 0, time.Time{}, cerrors.BadRequestError("ciphertext must be at most 262144 bytes")},
+		{"too many views", models.SendInput{Ciphertext: []byte("sealed"), MaxViews: 101}, 0, time.Time{}, cerrors.BadRequestError("maxViews must be between 1 and 100")},
+		{"negative views", models.SendInput{Ciphertext: []byte("sealed"), MaxViews: -1}, 0, time.Time{}, cerrors.BadRequestError("maxViews must be between 1 and 100")},
+		{"expiry too far", models.SendInput{Ciphertext: []byte("sealed"), ExpiresIn: 31 * 24 * 3600}, 0, time.Time{}, cerrors.BadRequestError("expiresIn must be at most 2592000 seconds")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock := &mocks.SendRepositoryMock{}
+			var saved *models.Send
+			repoMock.On("Save", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(*models.Send)
+				saved.ID = 3
+			})
+
+			// when
+			actual, error := NewSendService(repoMock, &mocks.TransactorMock{}, fixedClock).Create(ctx, tc.input)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+
+			if tc.expected != nil {
+				assert.Nil(t, actual)
+				repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.Equal(t, uint(10), saved.UserID)
+			assert.Equal(t, tc.input.Ciphertext, saved.Ciphertext)
+			assert.Len(t, saved.AccessID, 22)
+			assert.Equal(t, &models.SendDetail{
+				ID:          3,
+				AccessID:    saved.AccessID,
+				MaxViews:    tc.maxViews,
+				HasPassword: tc.input.AccessKey != "",
+				ExpiresAt:   tc.expiresAt,
+			}, actual)
+
+			if tc.input.AccessKey != "" {
+				assert.Equal(t, accessHash(tc.input.AccessKey), saved.AccessHash)
+				assert.NotContains(t, saved.AccessHash, tc.input.AccessKey)
+			}
+		})
+	}
+
+	t.Run("not authenticated", func(t *testing.T) {
+		// when
+		actual, error := NewSendService(&mocks.SendRepositoryMock{}, &mocks.TransactorMock{}, fixedClock).Create(context.TODO(), models.SendInput{Ciphertext: []byte("sealed")})
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, cerrors.UnauthorizedError("user is not authenticated"), error)
+	})
+}
+
+func TestGetAllSends(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	expiresAt := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+
+	// given
+	repoMock := &mocks.SendRepositoryMock{}
+	repoMock.On("FindByUserID", ctx, uint(10)).Return([]models.Send{
+		{Model: gorm.Model{ID: 3}, AccessID: "abc", UserID: 10, Ciphertext: []byte("sealed"), AccessHash: "hash", MaxViews: 2, Views: 1, ExpiresAt: expiresAt},
+	}, nil)
+
+	// when
+	actual, error := NewSendService(repoMock, &mocks.TransactorMock{}, clock.Clock{}).GetAll(ctx)
+
+	// then: ciphertexts aren't listed
+	assert.Nil(t, error)
+	assert.Equal(t, []models.SendDetail{{ID: 3, AccessID: "abc", MaxViews: 2, Views: 1, HasPassword: true, ExpiresAt: expiresAt}}, actual)
+}
+
+func TestDeleteSend(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.SendRepositoryMock{}
+		repoMock.On("FindByID", ctx, uint(3)).Return(&models.Send{Model: gorm.Model{ID: 3}, UserID: 10}, nil)
+		repoMock.On("Delete", ctx, uint(3)).Return(nil)
+
+		// when
+		error := NewSendService(repoMock, &mocks.TransactorMock{}, clock.Clock{}).Delete(ctx, 3)
+
+		// then
+		assert.Nil(t, error)
+		repoMock.AssertExpectations(t)
+	})
+
+	t.Run("send of another user", func(t *testing.T) {
+		// given
+		repoMock := &mocks.SendRepositoryMock{}
+		repoMock.On("FindByID", ctx, uint(3)).Return(&models.Send{Model: gorm.Model{ID: 3}, UserID: 20}, nil)
+
+		// when
+		error := NewSendService(repoMock, &mocks.TransactorMock{}, clock.Clock{}).Delete(ctx, 3)
+
+		// then
+		assert.Equal(t, cerrors.NotFoundError("send not found"), error)
+		repoMock.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAccessSend(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	fixedClock := clock.Clock{NowFn: func() time.Time { return now }}
+	expiresAt := now.Add(time.Hour)
+
+	testCases := []struct {
+		name      string
+		send      models.Send
+		accessKey string
+		expected  *models.SendContent
+		error     error
+		saved     *models.Send
+		deleted   bool
+	}{
+		{
+			name:     "view counted",
+			send:     models.Send{Model: gorm.Model{ID: 3}, Ciphertext: []byte("sealed"), MaxViews: 3, Views: 1, ExpiresAt: expiresAt},
+			expected: &models.SendContent{Ciphertext: []byte("sealed"), ViewsLeft: 1, ExpiresAt: expiresAt},
+			saved:    &models.Send{Model: gorm.Model{ID: 3}, Ciphertext: []byte("sealed"), MaxViews: 3, Views: 2, ExpiresAt: expiresAt},
+		},
+		{
+			name:     "last view",
+			send:     models.Send{Model: gorm.Model{ID: 3}, Ciphertext: []byte("sealed"), MaxViews: 1, ExpiresAt: expiresAt},
+			expected: &models.SendContent{Ciphertext: []byte("sealed"), ViewsLeft: 0, ExpiresAt: expiresAt},
+			deleted:  true,
+		},
+		{
+			name:      "access password",
+			send:      models.Send{Model: gorm.Model{ID: 3}, Ciphertext: []byte("sealed"), AccessHash: accessHash("access-key"), MaxViews: 1, ExpiresAt: expiresAt},
+			accessKey: "access-key",
+			expected:  &models.SendContent{Ciphertext: []byte("sealed"), ViewsLeft: 0, ExpiresAt: expiresAt},
+			deleted:   true,
+		},
+		{
+			name:  "access password missing",
+			send:  models.Send{Model: gorm.Model{ID: 3}, AccessHash: accessHash("access-key"), MaxViews: 1, ExpiresAt: expiresAt},
+			error: cerrors.UnauthorizedError("access password required"),
+		},
+		{
+			name:      "wrong access password",
+			send:      models.Send{Model: gorm.Model{ID: 3}, AccessHash: accessHash("access-key"), MaxViews: 1, ExpiresAt: expiresAt},
+			accessKey: "wrong-key",
+			error:     cerrors.UnauthorizedError("invalid access password"),
+			saved:     &models.Send{Model: gorm.Model{ID: 3}, AccessHash: accessHash("access-key"), MaxViews: 1, FailedAttempts: 1, ExpiresAt: expiresAt},
+		},
+		{
+			name:      "too many wrong access passwords",
+			send:      models.Send{Model: gorm.Model{ID: 3}, AccessHash: accessHash("access-key"), MaxViews: 1, FailedAttempts: sendMaxFailedAttempts - 1, ExpiresAt: expiresAt},
+			accessKey: "wrong-key",
+			error:     cerrors.UnauthorizedError("invalid access password"),
+			deleted:   true,
+		},
+		{
+			name:    "expired",
+			send:    models.Send{Model: gorm.Model{ID: 3}, Ciphertext: []byte("sealed"), MaxViews: 1, ExpiresAt: now},
+			error:   cerrors.NotFoundError("send not found"),
+			deleted: true,
+		},
+		{
+			name:  "not found",
+			send:  models.Send{},
+			error: cerrors.NotFoundError("send not found"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock := &mocks.SendRepositoryMock{}
+			transactorMock := &mocks.TransactorMock{}
+			send := tc.send
+
+			transactorMock.On("WithinTransaction", context.TODO())
+			repoMock.On("FindByAccessID", context.TODO(), "abc").Return(&send, nil)
+			repoMock.On("Save", context.TODO(), mock.Anything).Return(nil)
+			repoMock.On("Delete", context.TODO(), uint(3)).Return(nil)
+
+			// when
+			actual, error := NewSendService(repoMock, transactorMock, fixedClock).Access(context.TODO(), "abc", tc.accessKey)
+
+			// then
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.error, error)
+
+			if tc.saved != nil {
+				repoMock.AssertCalled(t, "Save", context.TODO(), tc.saved)
+			} else {
+				repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+			}
+
+			if tc.deleted {
+				repoMock.AssertCalled(t, "Delete", context.TODO(), uint(3))
+			} else {
+				repoMock.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			}
+		})
+	}
+
+	t.Run("view not counted", func(t *testing.T) {
+		// given
+		repoMock := &mocks.SendRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		transactorMock.On("WithinTransaction", context.TODO())
+		repoMock.On("FindByAccessID", context.TODO(), "abc").Return(&models.Send{Model: gorm.Model{ID: 3}, MaxViews: 2, ExpiresAt: expiresAt}, nil)
+		repoMock.On("Save", context.TODO(), mock.Anything).Return(errors.New("database is down"))
+
+		// when
+		actual, error := NewSendService(repoMock, transactorMock, fixedClock).Access(context.TODO(), "abc", "")
+
+		// then: the ciphertext isn't handed out
+		assert.Nil(t, actual)
+		assert.Equal(t, errors.New("database is down"), error)
+	})
+}
+
+func TestPurgeSends(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	// given
+	repoMock := &mocks.SendRepositoryMock{}
+	purged := make(chan struct{}, 1)
+	repoMock.On("DeleteExpired", mock.Anything, now).Return(int64(1), nil).Run(func(mock.Arguments) {
+		select {
+		case purged <- struct{}{}:
+		default:
+		}
+	})
+
+	sendSvc := NewSendService(repoMock, &mocks.TransactorMock{}, clock.Clock{NowFn: func() time.Time { return now }})
+	sendSvc.purgeInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan struct{})
+
+	// when
+	go func() {
+		sendSvc.Run(ctx)
+		close(done)
+	}()
+
+	// then: the sends are purged on start, then periodically, until ctx is done
+	<-purged
+	<-purged
+	cancel()
+	<-done
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+)
+
+type ISyncService interface {
+	// Changes returns the vaults and items from the user created, updated or
+	// deleted since a revision, with the revision to sync from next time.
+	// Since 0 returns all vaults and items.
+	Changes(ctx context.Context, since uint64) (*models.SyncChanges, error)
+}
+
+type syncService struct {
+	userRepository     repositories.IUserRepository
+	vaultRepository    repositories.IVaultRepository
+	itemRepository     repositories.IItemRepository
+	favoriteRepository repositories.IFavoriteRepository
+}
+
+func NewSyncService(
+	userRepository repositories.IUserRepository,
+	vaultRepository repositories.IVaultRepository,
+	itemRepository repositories.IItemRepository,
+	favoriteRepository repositories.IFavoriteRepository,
+) *syncService {
+	return &syncService{userRepository, vaultRepository, itemRepository, favoriteRepository}
+}
+
+func (s *syncService) Changes(ctx context.Context, since uint64) (*models.SyncChanges, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	// The revision is read before the changes, so a write committed while
+	// they are read is returned again on the next call rather than skipped.
+	user, err := s.userRepository.FindByID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find a user by id: %v", err.Error())
+		return nil, err
+	}
+
+	if user.ID == 0 {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if since > user.Revision {
+		return nil, cerrors.ConflictError("revision is ahead of the server, sync from 0")
+	}
+
+	vaults, err := s.vaultRepository.FindChangedByUserID(ctx, userID, since)
+
+	if err != nil {
+		log.Printf("error while trying to find changed vaults by userId: %v", err.Error())
+		return nil, err
+	}
+
+	items, err := s.itemRepository.FindChangedByUserID(ctx, userID, since)
+
+	if err != nil {
+		log.Printf("error while trying to find changed items by userId: %v", err.Error())
+		return nil, err
+	}
+
+	favorites, err := favoriteItemIDs(ctx, s.favoriteRepository, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &models.SyncChanges{
+		Revision: user.Revision,
+		Vaults:   models.ChangeSet[models.VaultDetail]{Created: []models.VaultDetail{}, Updated: []models.VaultDetail{}, Deleted: []uint{}},
+		Items:    models.ChangeSet[models.ItemDetail]{Created: []models.ItemDetail{}, Updated: []models.ItemDetail{}, Deleted: []uint{}},
+	}
+
+	for _, vault := range vaults {
+		addChange(&changes.Vaults, since, vault.ID, vault.CreatedRevision, vault.DeletedAt.Valid, func() models.VaultDetail {
+			return toVaultDetail(vault)
+		})
+	}
+
+	for _, item := range items {
+		addChange(&changes.Items, since, item.ID, item.CreatedRevision, item.DeletedAt.Valid, func() models.ItemDetail {
+			detail := toItemDetail(item)
+			detail.Favorite = favorites[item.ID]
+			return detail
+		})
+	}
+
+	return changes, nil
+}
+
+// addChange adds a record written after since to a change set. Records both
+// created and deleted since then are left out, as the client never saw them.
+func addChange[T any](set *models.ChangeSet[T], since uint64, id uint, createdRevision uint64, deleted bool, detail func() T) {
+	created := createdRevision > since
+
+	switch {
+	case deleted && !created:
+		set.Deleted = append(set.Deleted, id)
+	case deleted:
+	case created:
+		set.Created = append(set.Created, detail())
+	default:
+		set.Updated = append(set.Updated, detail())
+	}
+}
+
+// withRevision runs fn in a transaction with the next revision of the user,
+// which fn stores in the records it writes so clients can sync them.
+func withRevision(
+	ctx context.Context,
+	transactor repositories.ITransactor,
+	userRepository repositories.IUserRepository,
+	userID uint,
+	fn func(ctx context.Context, revision uint64) error,
+) error {
+	return transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		revision, err := userRepository.NextRevision(ctx, userID)
+
+		if err != nil {
+			log.Printf("error while trying to increment the revision of a user: %v", err.Error())
+			return err
+		}
+
+		return fn(ctx, revision)
+	})
+}
+
+// checkRevision fails when a record of the given kind was written after the
+// revision an update is based on.
+func checkRevision(kind string, current, expected uint64) error {
+	if current != expected {
+		return cerrors.PreconditionFailedError(fmt.Sprintf("%s was changed at revision %d, since revision %d", kind, current, expected))
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// revisionMock returns a user repository numbering the writes of any user with revision.
+func revisionMock(revision uint64) *mocks.UserRepositoryMock {
+	userRepoMock := &mocks.UserRepositoryMock{}
+	userRepoMock.On("NextRevision", mock.Anything, mock.Anything).Return(revision, nil).Maybe()
+
+	return userRepoMock
+}
+
+// transactionMock returns a transactor running any transaction.
+func transactionMock() *mocks.TransactorMock {
+	transactorMock := &mocks.TransactorMock{}
+	transactorMock.On("WithinTransaction", mock.Anything).Maybe()
+
+	return transactorMock
+}
+
+func TestNewSyncService(t *testing.T) {
+	userRepoMock := &mocks.UserRepositoryMock{}
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+	itemRepoMock := &mocks.ItemRepositoryMock{}
+	favoriteRepoMock := &mocks.FavoriteRepositoryMock{}
+
+	syncSvc := NewSyncService(userRepoMock, vaultRepoMock, itemRepoMock, favoriteRepoMock)
+
+	assert.Equal(t, userRepoMock, syncSvc.userRepository)
+	assert.Equal(t, vaultRepoMock, syncSvc.vaultRepository)
+	assert.Equal(t, itemRepoMock, syncSvc.itemRepository)
+	assert.Equal(t, favoriteRepoMock, syncSvc.favoriteRepository)
+}
+
+func TestSyncChanges(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+	deleted := gorm.DeletedAt{Time: time.Date(2023, 5, 6, 10, 0, 0, 0, time.UTC), Valid: true}
+
+	newMocks := func() (*mocks.UserRepositoryMock, *mocks.VaultRepositoryMock, *mocks.ItemRepositoryMock, *mocks.FavoriteRepositoryMock) {
+		userRepoMock := &mocks.UserRepositoryMock{}
+		vaultRepoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		favoriteRepoMock := &mocks.FavoriteRepositoryMock{}
+
+		userRepoMock.On("FindByID", ctx, userID).Return(&models.User{Model: gorm.Model{ID: userID}, Revision: 12}, nil)
+		favoriteRepoMock.On("FindItemIDsByUserID", ctx, userID).Return([]uint{5}, nil)
+
+		return userRepoMock, vaultRepoMock, itemRepoMock, favoriteRepoMock
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		userRepoMock, vaultRepoMock, itemRepoMock, favoriteRepoMock := newMocks()
+
+		vaultRepoMock.On("FindChangedByUserID", ctx, userID, uint64(7)).Return([]models.Vault{
+			{Model: gorm.Model{ID: 1}, Name: "Work", UserID: userID, CreatedRevision: 2, UpdatedRevision: 8},
+			{Model: gorm.Model{ID: 2}, Name: "Home", UserID: userID, CreatedRevision: 9, UpdatedRevision: 9},
+			{Model: gorm.Model{ID: 3, DeletedAt: deleted}, Name: "Old", UserID: userID, CreatedRevision: 3, UpdatedRevision: 10},
+			{Model: gorm.Model{ID: 4, DeletedAt: deleted}, Name: "Tmp", UserID: userID, CreatedRevision: 9, UpdatedRevision: 11},
+		}, nil)
+		itemRepoMock.On("FindChangedByUserID", ctx, userID, uint64(7)).Return([]models.Item{
+			{Model: gorm.Model{ID: 5}, Name: "GitHub", Password: "secret", TOTP: "otpauth://totp/GitHub?secret=JBSWY3DPEHPK3PXP", VaultID: 2, CreatedRevision: 9, UpdatedRevision: 9},
+			{Model: gorm.Model{ID: 6}, Name: "Bank", VaultID: 1, CreatedRevision: 1, UpdatedRevision: 12},
+			{Model: gorm.Model{ID: 7, DeletedAt: deleted}, Name: "Mail", VaultID: 3, CreatedRevision: 2, UpdatedRevision: 10},
+		}, nil)
+
+		// when
+		syncSvc := NewSyncService(userRepoMock, vaultRepoMock, itemRepoMock, favoriteRepoMock)
+		actual, error := syncSvc.Changes(ctx, 7)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, &models.SyncChanges{
+			Revision: 12,
+			Vaults: models.ChangeSet[models.VaultDetail]{
+				Created: []models.VaultDetail{{ID: 2, Name: "Home", UserID: userID, Revision: 9}},
+				Updated: []models.VaultDetail{{ID: 1, Name: "Work", UserID: userID, Revision: 8}},
+				Deleted: []uint{3},
+			},
+			Items: models.ChangeSet[models.ItemDetail]{
+				Created: []models.ItemDetail{{
+					ID: 5, VaultID: 2, Name: "GitHub", Password: "secret", TOTP: "otpauth://totp/GitHub?secret=JBSWY3DPEHPK3PXP", HasTOTP: true, Favorite: true, Revision: 9,
+				}},
+				Updated: []models.ItemDetail{{ID: 6, VaultID: 1, Name: "Bank", Revision: 12}},
+				Deleted: []uint{7},
+			},
+		}, actual)
+	})
+
+	t.Run("full sync", func(t *testing.T) {
+		// given
+		userRepoMock, vaultRepoMock, itemRepoMock, favoriteRepoMock := newMocks()
+
+		vaultRepoMock.On("FindChangedByUserID", ctx, userID, uint64(0)).Return([]models.Vault{
+			{Model: gorm.Model{ID: 1}, Name: "Work", UserID: userID, CreatedRevision: 2, UpdatedRevision: 8},
+			{Model: gorm.Model{ID: 3, DeletedAt: deleted}, Name: "Old", UserID: userID, CreatedRevision: 3, UpdatedRevision: 10},
+		}, nil)
+		itemRepoMock.On("FindChangedByUserID", ctx, userID, uint64(0)).Return([]models.Item{}, nil)
+
+		// when
+		syncSvc := NewSyncService(userRepoMock, vaultRepoMock, itemRepoMock, favoriteRepoMock)
+		actual, error := syncSvc.Changes(ctx, 0)
+
+		// then: everything is created and there are no tombstones
+		assert.Nil(t, error)
+		assert.Equal(t, []models.VaultDetail{{ID: 1, Name: "Work", UserID: userID, Revision: 8}}, actual.Vaults.Created)
+		assert.Empty(t, actual.Vaults.Updated)
+		assert.Empty(t, actual.Vaults.Deleted)
+	})
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// when
+		syncSvc := NewSyncService(&mocks.UserRepositoryMock{}, &mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, &mocks.FavoriteRepositoryMock{})
+		actual, error := syncSvc.Changes(context.TODO(), 0)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "user is not authenticated", error.Error())
+	})
+
+	t.Run("revision ahead of the server", func(t *testing.T) {
+		// given
+		userRepoMock, vaultRepoMock, itemRepoMock, favoriteRepoMock := newMocks()
+
+		// when
+		syncSvc := NewSyncService(userRepoMock, vaultRepoMock, itemRepoMock, favoriteRepoMock)
+		actual, error := syncSvc.Changes(ctx, 13)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "revision is ahead of the server, sync from 0", error.Error())
+
+		vaultRepoMock.AssertNotCalled(t, "FindChangedByUserID", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		userRepoMock, vaultRepoMock, itemRepoMock, favoriteRepoMock := newMocks()
+
+		vaultRepoMock.On("FindChangedByUserID", ctx, userID, uint64(7)).Return([]models.Vault{}, nil)
+		itemRepoMock.On("FindChangedByUserID", ctx, userID, uint64(7)).Return([]models.Item{}, errors.New("error when finding items"))
+
+		// when
+		syncSvc := NewSyncService(userRepoMock, vaultRepoMock, itemRepoMock, favoriteRepoMock)
+		actual, error := syncSvc.Changes(ctx, 7)
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, "error when finding items", error.Error())
+	})
+}
+
+func TestWithRevision(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("numbers the write", func(t *testing.T) {
+		// given
+		userRepoMock := &mocks.UserRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		transactorMock.On("WithinTransaction", ctx)
+		userRepoMock.On("NextRevision", ctx, uint(10)).Return(uint64(5), nil)
+
+		// when
+		var actual uint64
+		error := withRevision(ctx, transactorMock, userRepoMock, 10, func(ctx context.Context, revision uint64) error {
+			actual = revision
+			return nil
+		})
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, uint64(5), actual)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		userRepoMock := &mocks.UserRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		transactorMock.On("WithinTransaction", ctx)
+		userRepoMock.On("NextRevision", ctx, uint(10)).Return(uint64(0), errors.New("error when incrementing revision"))
+
+		// when
+		called := false
+		error := withRevision(ctx, transactorMock, userRepoMock, 10, func(ctx context.Context, revision uint64) error {
+			called = true
+			return nil
+		})
+
+		// then
+		assert.Equal(t, "error when incrementing revision", error.Error())
+		assert.False(t, called)
+	})
+}
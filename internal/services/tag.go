@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+)
+
+type ITagService interface {
+	// GetAll returns all tags from the user.
+	GetAll(ctx context.Context) ([]models.TagDetail, error)
+	// Rename changes the name of a tag on every item that has it.
+	Rename(ctx context.Context, id uint, name string) error
+	// Delete deletes a tag and removes it from every item that has it.
+	Delete(ctx context.Context, id uint) error
+}
+
+type tagService struct {
+	repository     repositories.ITagRepository
+	itemRepository repositories.IItemRepository
+	userRepository repositories.IUserRepository
+	transactor     repositories.ITransactor
+}
+
+func NewTagService(
+	repository repositories.ITagRepository,
+	itemRepository repositories.IItemRepository,
+	userRepository repositories.IUserRepository,
+	transactor repositories.ITransactor,
+) *tagService {
+	return &tagService{repository, itemRepository, userRepository, transactor}
+}
+
+func (t *tagService) GetAll(ctx context.Context) ([]models.TagDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return []models.TagDetail{}, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	tags, err := t.repository.FindByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find all tags by userId: %v", err.Error())
+		return []models.TagDetail{}, err
+	}
+
+	details := utils.Map(tags, func(t models.Tag) models.TagDetail {
+		return models.TagDetail{ID: t.ID, Name: t.Name}
+	})
+
+	return details, nil
+}
+
+func (t *tagService) Rename(ctx context.Context, id uint, name string) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(name) {
+		return cerrors.BadRequestError("name is required")
+	}
+
+	tag, err := t.findTag(ctx, userID, id)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.Name == name {
+		return nil
+	}
+
+	existing, err := t.repository.FindByNameAndUserID(ctx, name, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find a tag by name,userId: %v", err.Error())
+		return err
+	}
+
+	if existing.ID != 0 {
+		return cerrors.ConflictError("tag already exists")
+	}
+
+	// Items reference tags by ID, so they all get the new name.
+	tag.Name = name
+
+	return withRevision(ctx, t.transactor, t.userRepository, userID, func(ctx context.Context, revision uint64) error {
+		if err := t.itemRepository.SetRevisionByTagID(ctx, tag.ID, revision); err != nil {
+			log.Printf("error while trying to set the revision of items by tagId: %v", err.Error())
+			return err
+		}
+
+		if err := t.repository.Save(ctx, tag); err != nil {
+			log.Printf("error while trying to save tag: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (t *tagService) Delete(ctx context.Context, id uint) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	tag, err := t.findTag(ctx, userID, id)
+
+	if err != nil {
+		return err
+	}
+
+	return withRevision(ctx, t.transactor, t.userRepository, userID, func(ctx context.Context, revision uint64) error {
+		if err := t.itemRepository.SetRevisionByTagID(ctx, tag.ID, revision); err != nil {
+			log.Printf("error while trying to set the revision of items by tagId: %v", err.Error())
+			return err
+		}
+
+		if err := t.repository.Delete(ctx, tag.ID); err != nil {
+			log.Printf("error while trying to delete tag: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+}
+
+// findTag finds a tag by ID, or returns a not found error if it doesn't belong to the user.
+func (t *tagService) findTag(ctx context.Context, userID, id uint) (*models.Tag, error) {
+	tag, err := t.repository.FindByID(ctx, id)
+
+	if err != nil {
+		log.Printf("error while trying to find a tag by id: %v", err.Error())
+		return nil, err
+	}
+
+	if tag.ID == 0 || tag.UserID != userID {
+		return nil, cerrors.NotFoundError("tag not found")
+	}
+
+	return tag, nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestNewTagService(t *testing.T) {
+	repoMock := &mocks.TagRepositoryMock{}
+	itemRepoMock := &mocks.ItemRepositoryMock{}
+	userRepoMock := &mocks.UserRepositoryMock{}
+	transactorMock := &mocks.TransactorMock{}
+
+	tagSvc := NewTagService(repoMock, itemRepoMock, userRepoMock, transactorMock)
+
+	assert.Equal(t, repoMock, tagSvc.repository)
+	assert.Equal(t, itemRepoMock, tagSvc.itemRepository)
+	assert.Equal(t, userRepoMock, tagSvc.userRepository)
+	assert.Equal(t, transactorMock, tagSvc.transactor)
+}
+
+func TestGetAllTags(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.TagRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		repoMock.On("FindByUserID", ctx, userID).Return([]models.Tag{{Model: gorm.Model{ID: 7}, Name: "work", UserID: userID}}, nil)
+
+		// when
+		tagSvc := &tagService{repoMock, itemRepoMock, revisionMock(3), transactionMock()}
+		actual, error := tagSvc.GetAll(ctx)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, []models.TagDetail{{ID: 7, Name: "work"}}, actual)
+	})
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// when
+		tagSvc := &tagService{&mocks.TagRepositoryMock{}, &mocks.ItemRepositoryMock{}, revisionMock(3), transactionMock()}
+		actual, error := tagSvc.GetAll(context.TODO())
+
+		// then
+		assert.Equal(t, []models.TagDetail{}, actual)
+		assert.Equal(t, "user is not authenticated", error.Error())
+	})
+}
+
+func TestRenameTag(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.TagRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(7)).Return(&models.Tag{Model: gorm.Model{ID: 7}, Name: "work", UserID: userID}, nil)
+		repoMock.On("FindByNameAndUserID", ctx, "job", userID).Return(&models.Tag{}, nil)
+		repoMock.On("Save", ctx, &models.Tag{Model: gorm.Model{ID: 7}, Name: "job", UserID: userID})
+		itemRepoMock.On("SetRevisionByTagID", ctx, uint(7), uint64(3))
+
+		// when
+		tagSvc := &tagService{repoMock, itemRepoMock, revisionMock(3), transactionMock()}
+		error := tagSvc.Rename(ctx, 7, "job")
+
+		// then
+		assert.Nil(t, error)
+
+		repoMock.AssertExpectations(t)
+		itemRepoMock.AssertExpectations(t)
+	})
+
+	testCases := []struct {
+		name     string
+		newName  string
+		tag      *models.Tag
+		existing *models.Tag
+		err      string
+	}{
+		{"name is required", " ", &models.Tag{}, &models.Tag{}, "name is required"},
+		{"tag not found", "job", &models.Tag{}, &models.Tag{}, "tag not found"},
+		{"tag from another user", "job", &models.Tag{Model: gorm.Model{ID: 7}, UserID: 99}, &models.Tag{}, "tag not found"},
+		{"tag already exists", "job", &models.Tag{Model: gorm.Model{ID: 7}, UserID: userID}, &models.Tag{Model: gorm.Model{ID: 8}}, "tag already exists"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock := &mocks.TagRepositoryMock{}
+			itemRepoMock := &mocks.ItemRepositoryMock{}
+
+			repoMock.On("FindByID", ctx, uint(7)).Return(tc.tag, nil)
+			repoMock.On("FindByNameAndUserID", ctx, tc.newName, userID).Return(tc.existing, nil)
+
+			// when
+			tagSvc := &tagService{repoMock, itemRepoMock, revisionMock(3), transactionMock()}
+			error := tagSvc.Rename(ctx, 7, tc.newName)
+
+			// then
+			assert.Equal(t, tc.err, error.Error())
+
+			repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.TagRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(7)).Return(&models.Tag{Model: gorm.Model{ID: 7}, UserID: userID}, nil)
+		repoMock.On("Delete", ctx, uint(7))
+		itemRepoMock.On("SetRevisionByTagID", ctx, uint(7), uint64(3))
+
+		// when
+		tagSvc := &tagService{repoMock, itemRepoMock, revisionMock(3), transactionMock()}
+		error := tagSvc.Delete(ctx, 7)
+
+		// then
+		assert.Nil(t, error)
+
+		repoMock.AssertExpectations(t)
+		itemRepoMock.AssertExpectations(t)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		repoMock := &mocks.TagRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(7)).Return(&models.Tag{}, errors.New("error when finding tag"))
+
+		// when
+		tagSvc := &tagService{repoMock, itemRepoMock, revisionMock(3), transactionMock()}
+		error := tagSvc.Delete(ctx, 7)
+
+		// then
+		assert.Equal(t, "error when finding tag", error.Error())
+	})
+}
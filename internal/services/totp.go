@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/edgardjr92/gopass/pkg/totp"
+)
+
+type ITOTPService interface {
+	// GetCode returns the current one-time password of an item and how long it remains valid.
+	GetCode(ctx context.Context, itemID uint) (*models.TOTPCode, error)
+}
+
+type totpService struct {
+	itemService IItemService
+	clock       clock.Clock
+}
+
+func NewTOTPService(itemService IItemService, clock clock.Clock) *totpService {
+	return &totpService{itemService, clock}
+}
+
+func (t *totpService) GetCode(ctx context.Context, itemID uint) (*models.TOTPCode, error) {
+	item, err := t.itemService.Get(ctx, itemID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if item.TOTP == "" {
+		return nil, cerrors.NotFoundError("item has no totp")
+	}
+
+	key, err := totp.Parse(item.TOTP)
+
+	if err != nil {
+		log.Printf("error while trying to parse the totp uri of an item: %v", err.Error())
+		return nil, err
+	}
+
+	now := t.clock.Now()
+
+	return &models.TOTPCode{
+		Code:      key.Code(now),
+		Period:    uint(key.Period / time.Second),
+		Remaining: uint(key.Remaining(now) / time.Second),
+	}, nil
+}
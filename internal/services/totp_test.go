@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTOTPService(t *testing.T) {
+	itemSvcMock := &mocks.ItemServiceMock{}
+	clk := clock.Clock{}
+
+	totpSvc := NewTOTPService(itemSvcMock, clk)
+
+	assert.Equal(t, itemSvcMock, totpSvc.itemService)
+	assert.Equal(t, clk, totpSvc.clock)
+}
+
+func TestGetCode(t *testing.T) {
+	ctx := context.TODO()
+	// RFC 6238 SHA1 test secret, 12345678901234567890
+	uri := "otpauth://totp/Example:alice?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&digits=8"
+	clk := clock.Clock{NowFn: func() time.Time { return time.Unix(1111111109, 0) }}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		itemSvcMock := &mocks.ItemServiceMock{}
+		itemSvcMock.On("Get", ctx, uint(5)).Return(&models.ItemDetail{ID: 5, TOTP: uri}, nil)
+
+		// when
+		totpSvc := &totpService{itemSvcMock, clk}
+		actual, error := totpSvc.GetCode(ctx, 5)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, &models.TOTPCode{Code: "07081804", Period: 30, Remaining: 1}, actual)
+	})
+
+	testCases := []struct {
+		name string
+		item *models.ItemDetail
+		err  error
+		msg  string
+	}{
+		{"item not found", nil, cerrors.NotFoundError("item not found"), "item not found"},
+		{"item without totp", &models.ItemDetail{ID: 5}, nil, "item has no totp"},
+		{"invalid totp", &models.ItemDetail{ID: 5, TOTP: "otpauth://hotp/alice"}, nil, "totp: invalid uri: only otpauth://totp and steam:// uris are supported"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			itemSvcMock := &mocks.ItemServiceMock{}
+			itemSvcMock.On("Get", ctx, uint(5)).Return(tc.item, tc.err)
+
+			// when
+			totpSvc := &totpService{itemSvcMock, clk}
+			actual, error := totpSvc.GetCode(ctx, 5)
+
+			// then
+			assert.Nil(t, actual)
+			assert.Equal(t, tc.msg, error.Error())
+		})
+	}
+}
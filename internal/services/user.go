@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
 	"github.com/edgardjr92/gopass/internal/models"
 	"github.com/edgardjr92/gopass/internal/repositories"
 	"github.com/edgardjr92/gopass/internal/utils"
@@ -15,6 +16,10 @@ type IUserService interface {
 	// Create creates a new user.
 	// It returns the ID of the newly created user.
 	Create(ctx context.Context, name, email, authKey string) (uint, error)
+	// ChangePassword replaces the auth key of the authenticated user, given the
+	// current one, and their private key sealed under the new master password.
+	// It revokes every token issued to the user.
+	ChangePassword(ctx context.Context, change models.PasswordChange) error
 }
 
 type userService struct {
@@ -53,7 +58,7 @@ func (u *userService) Create(ctx context.Context, name, email, authKey string) (
 	newUser := models.User{
 		Name:    name,
 		Email:   email,
-		AuthKey: authKey,
+		AuthKey: hashAuthKey(authKey),
 	}
 
 	if err := u.repository.Save(ctx, &newUser); err != nil {
@@ -63,3 +68,47 @@ func (u *userService) Create(ctx context.Context, name, email, authKey string) (
 
 	return newUser.ID, nil
 }
+
+func (u *userService) ChangePassword(ctx context.Context, change models.PasswordChange) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(change.CurrentAuthKey) {
+		return cerrors.BadRequestError("currentAuthKey is required")
+	}
+
+	if utils.IsBlank(change.NewAuthKey) {
+		return cerrors.BadRequestError("newAuthKey is required")
+	}
+
+	user, err := u.repository.FindByID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find user by id: %v", err.Error())
+		return err
+	}
+
+	if user.ID == 0 || !checkAuthKey(user.AuthKey, change.CurrentAuthKey) {
+		return cerrors.UnauthorizedError("invalid credentials")
+	}
+
+	if len(user.PublicKey) != 0 && len(change.EncryptedPrivateKey) == 0 {
+		return cerrors.BadRequestError("encryptedPrivateKey is required")
+	}
+
+	if len(user.PublicKey) == 0 && len(change.EncryptedPrivateKey) != 0 {
+		return cerrors.BadRequestError("keys not set")
+	}
+
+	// The auth key, the private key and the session version are updated together,
+	// so no token outlives the password and the private key opens with it.
+	if err := u.repository.UpdatePassword(ctx, user.ID, hashAuthKey(change.NewAuthKey), change.EncryptedPrivateKey); err != nil {
+		log.Printf("error while trying to update the password of a user: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
 	"github.com/edgardjr92/gopass/internal/mocks"
 	"github.com/edgardjr92/gopass/internal/models"
 	"github.com/stretchr/testify/assert"
@@ -34,7 +35,7 @@ func TestCreateUser(t *testing.T) {
 		repoMock := &mocks.UserRepositoryMock{}
 		hasherMock := &mocks.HasherMock{}
 
-		newUser := &models.User{Name: name, Email: email, AuthKey: authKey}
+		newUser := &models.User{Name: name, Email: email, AuthKey: hashAuthKey(authKey)}
 
 		repoMock.On("FindByEmail", ctx, email).Return(&models.User{}, nil)
 		repoMock.On("Save", ctx, newUser).Run(func(args mock.Arguments) {
@@ -107,3 +108,82 @@ func TestCreateUser(t *testing.T) {
 	}
 
 }
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	testCases := []struct {
+		name     string
+		user     *models.User
+		change   models.PasswordChange
+		expected error
+		sealed   []byte
+	}{
+		{
+			name:   "success",
+			user:   &models.User{Model: gorm.Model{ID: 10}, AuthKey: hashAuthKey("old-key"), PublicKey: []byte("public"), EncryptedPrivateKey: []byte("old-sealed"), SessionVersion: 2},
+			change: models.PasswordChange{CurrentAuthKey: "old-key", NewAuthKey: "new-key", EncryptedPrivateKey: []byte("new-sealed")},
+			sealed: []byte("new-sealed"),
+		},
+		{
+			name:   "without keys",
+			user:   &models.User{Model: gorm.Model{ID: 10}, AuthKey: hashAuthKey("old-key")},
+			change: models.PasswordChange{CurrentAuthKey: "old-key", NewAuthKey: "new-key"},
+		},
+		{
+			name:     "wrong current key",
+			user:     &models.User{Model: gorm.Model{ID: 10}, AuthKey: hashAuthKey("old-key")},
+			change:   models.PasswordChange{CurrentAuthKey: "guess", NewAuthKey: "new-key"},
+			expected: cerrors.UnauthorizedError("invalid credentials"),
+		},
+		{
+			name:     "private key not sealed again",
+			user:     &models.User{Model: gorm.Model{ID: 10}, AuthKey: hashAuthKey("old-key"), PublicKey: []byte("public"), EncryptedPrivateKey: []byte("old-sealed")},
+			change:   models.PasswordChange{CurrentAuthKey: "old-key", NewAuthKey: "new-key"},
+			expected: cerrors.BadRequestError("encryptedPrivateKey is required"),
+		},
+		{
+			name:     "private key without keys",
+			user:     &models.User{Model: gorm.Model{ID: 10}, AuthKey: hashAuthKey("old-key")},
+			change:   models.PasswordChange{CurrentAuthKey: "old-key", NewAuthKey: "new-key", EncryptedPrivateKey: []byte("sealed")},
+			expected: cerrors.BadRequestError("keys not set"),
+		},
+		{
+			name:     "missing new key",
+			user:     &models.User{Model: gorm.Model{ID: 10}, AuthKey: hashAuthKey("old-key")},
+			change:   models.PasswordChange{CurrentAuthKey: "old-key", NewAuthKey: " "},
+			expected: cerrors.BadRequestError("newAuthKey is required"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock := &mocks.UserRepositoryMock{}
+			repoMock.On("FindByID", ctx, uint(10)).Return(tc.user, nil)
+			repoMock.On("UpdatePassword", ctx, uint(10), hashAuthKey("new-key"), tc.sealed).Return(nil)
+
+			// when
+			error := NewUserService(repoMock, &mocks.HasherMock{}).ChangePassword(ctx, tc.change)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+
+			if tc.expected != nil {
+				repoMock.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			repoMock.AssertCalled(t, "UpdatePassword", ctx, uint(10), hashAuthKey("new-key"), tc.sealed)
+			repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		// when
+		error := NewUserService(&mocks.UserRepositoryMock{}, &mocks.HasherMock{}).ChangePassword(context.TODO(), models.PasswordChange{})
+
+		// then
+		assert.Equal(t, cerrors.UnauthorizedError("user is not authenticated"), error)
+	})
+}
@@ -16,15 +16,29 @@ type IVaultService interface {
 	// It returns the ID of the newly created vault.
 	Create(ctx context.Context, name string) (uint, error)
 	// GetAll returns all vaults from a user.
-	GetAll(ctx context.Context, userID uint) ([]models.VaultDetail, error)
+	GetAll(ctx context.Context) ([]models.VaultDetail, error)
+	// Rename changes the name of a vault, if it is still at revision.
+	Rename(ctx context.Context, id uint, name string, revision uint64) error
+	// Delete deletes a vault with all its folders and items.
+	Delete(ctx context.Context, id uint) error
 }
 
 type vaultService struct {
-	repository repositories.IVaultRepository
+	repository       repositories.IVaultRepository
+	itemRepository   repositories.IItemRepository
+	folderRepository repositories.IFolderRepository
+	userRepository   repositories.IUserRepository
+	transactor       repositories.ITransactor
 }
 
-func NewVaultService(repository repositories.IVaultRepository) *vaultService {
-	return &vaultService{repository}
+func NewVaultService(
+	repository repositories.IVaultRepository,
+	itemRepository repositories.IItemRepository,
+	folderRepository repositories.IFolderRepository,
+	userRepository repositories.IUserRepository,
+	transactor repositories.ITransactor,
+) *vaultService {
+	return &vaultService{repository, itemRepository, folderRepository, userRepository, transactor}
 }
 
 func (v *vaultService) Create(ctx context.Context, name string) (uint, error) {
@@ -54,8 +68,19 @@ func (v *vaultService) Create(ctx context.Context, name string) (uint, error) {
 		UserID: userID,
 	}
 
-	if err := v.repository.Save(ctx, &newVault); err != nil {
-		log.Printf("error while trying to save vault: %v", err.Error())
+	err = withRevision(ctx, v.transactor, v.userRepository, userID, func(ctx context.Context, revision uint64) error {
+		newVault.CreatedRevision = revision
+		newVault.UpdatedRevision = revision
+
+		if err := v.repository.Save(ctx, &newVault); err != nil {
+			log.Printf("error while trying to save vault: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
 		return 0, err
 	}
 
@@ -76,13 +101,118 @@ func (v *vaultService) GetAll(ctx context.Context) ([]models.VaultDetail, error)
 		return []models.VaultDetail{}, err
 	}
 
-	details := utils.Map(vaults, func(v models.Vault) models.VaultDetail {
-		return models.VaultDetail{
-			ID:     v.ID,
-			Name:   v.Name,
-			UserID: v.UserID,
+	return utils.Map(vaults, toVaultDetail), nil
+}
+
+func (v *vaultService) Rename(ctx context.Context, id uint, name string, revision uint64) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if utils.IsBlank(name) {
+		return cerrors.BadRequestError("name is required")
+	}
+
+	if revision == 0 {
+		return cerrors.PreconditionRequiredError("revision is required")
+	}
+
+	// The vault is read once the revision of the user is taken, which locks
+	// out other writes of the user, so two renames can't both pass the check.
+	return withRevision(ctx, v.transactor, v.userRepository, userID, func(ctx context.Context, next uint64) error {
+		vault, err := findUserVault(ctx, v.repository, userID, id)
+
+		if err != nil {
+			return err
+		}
+
+		if err := checkRevision("vault", vault.UpdatedRevision, revision); err != nil {
+			return err
+		}
+
+		if vault.Name == name {
+			return nil
+		}
+
+		existing, err := v.repository.FindByNameAndUserID(ctx, name, userID)
+
+		if err != nil {
+			log.Printf("error while trying to find a vault by name,userId: %v", err.Error())
+			return err
+		}
+
+		if existing.ID != 0 {
+			return cerrors.ConflictError("vault already exists")
+		}
+
+		vault.Name = name
+		vault.UpdatedRevision = next
+
+		if err := v.repository.Save(ctx, vault); err != nil {
+			log.Printf("error while trying to save vault: %v", err.Error())
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (v *vaultService) Delete(ctx context.Context, id uint) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	vault, err := findUserVault(ctx, v.repository, userID, id)
+
+	if err != nil {
+		return err
+	}
+
+	return withRevision(ctx, v.transactor, v.userRepository, userID, func(ctx context.Context, revision uint64) error {
+		if err := v.itemRepository.DeleteByVaultID(ctx, vault.ID, revision); err != nil {
+			log.Printf("error while trying to delete items by vaultId: %v", err.Error())
+			return err
 		}
+
+		if err := v.folderRepository.DeleteByVaultID(ctx, vault.ID); err != nil {
+			log.Printf("error while trying to delete folders by vaultId: %v", err.Error())
+			return err
+		}
+
+		if err := v.repository.Delete(ctx, vault.ID, revision); err != nil {
+			log.Printf("error while trying to delete vault: %v", err.Error())
+			return err
+		}
+
+		return nil
 	})
+}
 
-	return details, nil
+// findUserVault finds a vault by ID, or returns a not found error if it doesn't belong to the user.
+func findUserVault(ctx context.Context, repository repositories.IVaultRepository, userID, id uint) (*models.Vault, error) {
+	vault, err := repository.FindByID(ctx, id)
+
+	if err != nil {
+		log.Printf("error while trying to find a vault by id: %v", err.Error())
+		return nil, err
+	}
+
+	if vault.ID == 0 || vault.UserID != userID {
+		return nil, cerrors.NotFoundError("vault not found")
+	}
+
+	return vault, nil
+}
+
+func toVaultDetail(vault models.Vault) models.VaultDetail {
+	return models.VaultDetail{
+		ID:       vault.ID,
+		Name:     vault.Name,
+		UserID:   vault.UserID,
+		Revision: vault.UpdatedRevision,
+	}
 }
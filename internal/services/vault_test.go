@@ -15,10 +15,18 @@ import (
 
 func TestNewVaultService(t *testing.T) {
 	repoMock := &mocks.VaultRepositoryMock{}
+	itemRepoMock := &mocks.ItemRepositoryMock{}
+	folderRepoMock := &mocks.FolderRepositoryMock{}
+	userRepoMock := &mocks.UserRepositoryMock{}
+	transactorMock := &mocks.TransactorMock{}
 
-	vaultSvc := NewVaultService(repoMock)
+	vaultSvc := NewVaultService(repoMock, itemRepoMock, folderRepoMock, userRepoMock, transactorMock)
 
 	assert.Equal(t, repoMock, vaultSvc.repository)
+	assert.Equal(t, itemRepoMock, vaultSvc.itemRepository)
+	assert.Equal(t, folderRepoMock, vaultSvc.folderRepository)
+	assert.Equal(t, userRepoMock, vaultSvc.userRepository)
+	assert.Equal(t, transactorMock, vaultSvc.transactor)
 }
 
 func TestCreateVault(t *testing.T) {
@@ -31,13 +39,13 @@ func TestCreateVault(t *testing.T) {
 		repoMock := &mocks.VaultRepositoryMock{}
 
 		repoMock.On("FindByNameAndUserID", ctx, name, userID).Return(&models.Vault{}, nil)
-		repoMock.On("Save", ctx, &models.Vault{Name: name, UserID: userID}).Run(func(args mock.Arguments) {
+		repoMock.On("Save", ctx, &models.Vault{Name: name, UserID: userID, CreatedRevision: 4, UpdatedRevision: 4}).Run(func(args mock.Arguments) {
 			vault := args.Get(1).(*models.Vault)
 			vault.ID = uint(100)
 		})
 
 		// when
-		vaultSvc := &vaultService{repository: repoMock}
+		vaultSvc := &vaultService{repository: repoMock, userRepository: revisionMock(4), transactor: transactionMock()}
 		actual, error := vaultSvc.Create(ctx, name)
 
 		// then
@@ -53,7 +61,7 @@ func TestCreateVault(t *testing.T) {
 		ctx := context.TODO()
 
 		// when
-		vaultSvc := &vaultService{repository: repoMock}
+		vaultSvc := &vaultService{repository: repoMock, userRepository: revisionMock(4), transactor: transactionMock()}
 		actual, error := vaultSvc.Create(ctx, name)
 
 		// then
@@ -70,7 +78,7 @@ func TestCreateVault(t *testing.T) {
 			repoMock := &mocks.VaultRepositoryMock{}
 
 			// when
-			vaultSvc := &vaultService{repository: repoMock}
+			vaultSvc := &vaultService{repository: repoMock, userRepository: revisionMock(4), transactor: transactionMock()}
 			actual, error := vaultSvc.Create(ctx, n)
 
 			// then
@@ -89,7 +97,7 @@ func TestCreateVault(t *testing.T) {
 			Return(&models.Vault{Model: gorm.Model{ID: 1}}, nil)
 
 		// when
-		vaultSvc := &vaultService{repository: repoMock}
+		vaultSvc := &vaultService{repository: repoMock, userRepository: revisionMock(4), transactor: transactionMock()}
 		actual, error := vaultSvc.Create(ctx, name)
 
 		// then
@@ -124,7 +132,7 @@ func TestCreateVault(t *testing.T) {
 			repoMock.On("Save", ctx, mock.Anything).Return(tc.saveError)
 
 			// when
-			vaultSvc := &vaultService{repository: repoMock}
+			vaultSvc := &vaultService{repository: repoMock, userRepository: revisionMock(4), transactor: transactionMock()}
 			actual, error := vaultSvc.Create(ctx, name)
 
 			// then
@@ -210,3 +218,153 @@ func TestGetAllVaults(t *testing.T) {
 		repoMock.AssertExpectations(t)
 	})
 }
+
+func TestRenameVault(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+	vault := models.Vault{Model: gorm.Model{ID: 1}, Name: "My Vault", UserID: userID, UpdatedRevision: 2}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.VaultRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: vault.Model, Name: vault.Name, UserID: userID, UpdatedRevision: 2}, nil)
+		repoMock.On("FindByNameAndUserID", ctx, "Work", userID).Return(&models.Vault{}, nil)
+		repoMock.On("Save", ctx, &models.Vault{Model: vault.Model, Name: "Work", UserID: userID, UpdatedRevision: 4})
+
+		// when
+		vaultSvc := &vaultService{repository: repoMock, userRepository: revisionMock(4), transactor: transactionMock()}
+		error := vaultSvc.Rename(ctx, 1, "Work", 2)
+
+		// then
+		assert.Nil(t, error)
+
+		repoMock.AssertExpectations(t)
+	})
+
+	t.Run("same name", func(t *testing.T) {
+		// given
+		repoMock := &mocks.VaultRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: vault.Model, Name: vault.Name, UserID: userID, UpdatedRevision: 2}, nil)
+
+		// when
+		vaultSvc := &vaultService{repository: repoMock, userRepository: revisionMock(4), transactor: transactionMock()}
+		error := vaultSvc.Rename(ctx, 1, "My Vault", 2)
+
+		// then
+		assert.Nil(t, error)
+
+		repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		newName  string
+		revision uint64
+		found    *models.Vault
+		existing *models.Vault
+		err      string
+	}{
+		{"user not authenticated", context.TODO(), "Work", 2, &vault, &models.Vault{}, "user is not authenticated"},
+		{"name is required", ctx, " ", 2, &vault, &models.Vault{}, "name is required"},
+		{"revision is required", ctx, "Work", 0, &vault, &models.Vault{}, "revision is required"},
+		{"vault not found", ctx, "Work", 2, &models.Vault{}, &models.Vault{}, "vault not found"},
+		{"vault from another user", ctx, "Work", 2, &models.Vault{Model: gorm.Model{ID: 1}, UserID: 99}, &models.Vault{}, "vault not found"},
+		{"vault changed since revision", ctx, "Work", 1, &vault, &models.Vault{}, "vault was changed at revision 2, since revision 1"},
+		{"vault already exists", ctx, "Work", 2, &vault, &models.Vault{Model: gorm.Model{ID: 2}}, "vault already exists"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock := &mocks.VaultRepositoryMock{}
+
+			repoMock.On("FindByID", ctx, uint(1)).Return(tc.found, nil)
+			repoMock.On("FindByNameAndUserID", ctx, "Work", userID).Return(tc.existing, nil)
+
+			// when
+			vaultSvc := &vaultService{repository: repoMock, userRepository: revisionMock(4), transactor: transactionMock()}
+			error := vaultSvc.Rename(tc.ctx, 1, tc.newName, tc.revision)
+
+			// then
+			assert.Equal(t, tc.err, error.Error())
+
+			repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDeleteVault(t *testing.T) {
+	userID := uint(10)
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, userID)
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		folderRepoMock := &mocks.FolderRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		repoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		transactorMock.On("WithinTransaction", ctx)
+		itemRepoMock.On("DeleteByVaultID", ctx, uint(1), uint64(4))
+		folderRepoMock.On("DeleteByVaultID", ctx, uint(1))
+		repoMock.On("Delete", ctx, uint(1), uint64(4))
+
+		// when
+		vaultSvc := &vaultService{repoMock, itemRepoMock, folderRepoMock, revisionMock(4), transactorMock}
+		error := vaultSvc.Delete(ctx, 1)
+
+		// then
+		assert.Nil(t, error)
+
+		repoMock.AssertExpectations(t)
+		itemRepoMock.AssertExpectations(t)
+		folderRepoMock.AssertExpectations(t)
+		transactorMock.AssertExpectations(t)
+	})
+
+	t.Run("user not authenticated", func(t *testing.T) {
+		// when
+		vaultSvc := &vaultService{&mocks.VaultRepositoryMock{}, &mocks.ItemRepositoryMock{}, &mocks.FolderRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TransactorMock{}}
+		error := vaultSvc.Delete(context.TODO(), 1)
+
+		// then
+		assert.Equal(t, "user is not authenticated", error.Error())
+	})
+
+	t.Run("vault not found", func(t *testing.T) {
+		// given
+		repoMock := &mocks.VaultRepositoryMock{}
+
+		repoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{}, nil)
+
+		// when
+		vaultSvc := &vaultService{repoMock, &mocks.ItemRepositoryMock{}, &mocks.FolderRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TransactorMock{}}
+		error := vaultSvc.Delete(ctx, 1)
+
+		// then
+		assert.Equal(t, "vault not found", error.Error())
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		// given
+		repoMock := &mocks.VaultRepositoryMock{}
+		itemRepoMock := &mocks.ItemRepositoryMock{}
+		transactorMock := &mocks.TransactorMock{}
+
+		repoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: userID}, nil)
+		transactorMock.On("WithinTransaction", ctx)
+		itemRepoMock.On("DeleteByVaultID", ctx, uint(1), uint64(4)).Return(errors.New("error when deleting items"))
+
+		// when
+		vaultSvc := &vaultService{repoMock, itemRepoMock, &mocks.FolderRepositoryMock{}, revisionMock(4), transactorMock}
+		error := vaultSvc.Delete(ctx, 1)
+
+		// then
+		assert.Equal(t, "error when deleting items", error.Error())
+
+		repoMock.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
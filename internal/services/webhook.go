@@ -0,0 +1,569 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/internal/repositories"
+	"github.com/edgardjr92/gopass/internal/utils"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/edgardjr92/gopass/pkg/webhook"
+)
+
+const (
+	// webhookQueueSize is the number of deliveries waiting for a worker, past
+	// which events go straight to the dead letters.
+	webhookQueueSize = 1024
+	// webhookWorkers is the number of deliveries made at once.
+	webhookWorkers = 4
+	// webhookMaxAttempts is the number of attempts made before an event goes to the dead letters.
+	webhookMaxAttempts = 5
+	// webhookBackoff is the wait before the second attempt, doubled before
+	// each next one up to webhookMaxBackoff.
+	webhookBackoff    = 2 * time.Second
+	webhookMaxBackoff = time.Minute
+	// webhookTimeout is how long receivers have to respond.
+	webhookTimeout = 10 * time.Second
+	// webhookSecretSize is the number of random bytes of webhook secrets.
+	webhookSecretSize = 32
+)
+
+type IWebhookService interface {
+	// Create subscribes the authenticated user to the events of their vaults.
+	// The webhook returned holds the secret signing its deliveries, which isn't returned again.
+	Create(ctx context.Context, input models.WebhookInput) (*models.WebhookDetail, error)
+	// GetAll returns all webhooks from the authenticated user, without their secrets.
+	GetAll(ctx context.Context) ([]models.WebhookDetail, error)
+	// Delete deletes a webhook and its dead letters.
+	Delete(ctx context.Context, id uint) error
+	// DeadLetters returns the events that couldn't be delivered to the webhooks
+	// of the authenticated user, newest first.
+	DeadLetters(ctx context.Context) ([]models.WebhookDeadLetterDetail, error)
+	// Redeliver queues a dead letter for delivery again, removing it from the dead letters.
+	Redeliver(ctx context.Context, id uint) error
+	// Discard deletes a dead letter.
+	Discard(ctx context.Context, id uint) error
+}
+
+// webhookDelivery is an event to post to a webhook.
+type webhookDelivery struct {
+	webhook   models.Webhook
+	eventID   string
+	eventType string
+	payload   []byte
+}
+
+type webhookService struct {
+	repository      repositories.IWebhookRepository
+	vaultRepository repositories.IVaultRepository
+	clock           clock.Clock
+	client          *http.Client
+	queue           chan webhookDelivery
+	backoff         time.Duration
+	allowLocal      bool
+}
+
+// errLocalWebhookAddress is returned when dialing a webhook resolves to an address
+// of the network of the server.
+var errLocalWebhookAddress = errors.New("webhook address is loopback, private or link-local")
+
+// NewWebhookService creates a webhook service. Events are queued for delivery
+// by Notify, which is meant to be subscribed to the event bus, and delivered
+// while Run runs.
+//
+// Webhooks can't reach loopback, private or link-local addresses, which are
+// checked when connecting so DNS names resolving to them are caught too.
+// allowLocal lifts this, and allows HTTP to the machine of the server. It is
+// only meant for development.
+func NewWebhookService(
+	repository repositories.IWebhookRepository,
+	vaultRepository repositories.IVaultRepository,
+	clock clock.Clock,
+	allowLocal bool,
+) *webhookService {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+
+	if !allowLocal {
+		dialer.Control = checkWebhookAddress
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Going through a proxy, the dialed address would be the one of the proxy.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   webhookTimeout,
+		// Redirects would turn the deliveries into GET requests, or send them
+		// somewhere the user didn't subscribe.
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	return &webhookService{
+		repository:      repository,
+		vaultRepository: vaultRepository,
+		clock:           clock,
+		client:          client,
+		queue:           make(chan webhookDelivery, webhookQueueSize),
+		backoff:         webhookBackoff,
+		allowLocal:      allowLocal,
+	}
+}
+
+func (w *webhookService) Create(ctx context.Context, input models.WebhookInput) (*models.WebhookDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return nil, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if err := validateWebhookURL(input.URL, w.allowLocal); err != nil {
+		return nil, err
+	}
+
+	for _, eventType := range input.Events {
+		if !containsString(models.EventTypes, eventType) {
+			return nil, cerrors.BadRequestError(fmt.Sprintf("unknown event type %q", eventType))
+		}
+	}
+
+	if input.VaultID != nil {
+		if _, err := findUserVault(ctx, w.vaultRepository, userID, *input.VaultID); err != nil {
+			return nil, err
+		}
+	}
+
+	secret := make([]byte, webhookSecretSize)
+
+	if _, err := rand.Read(secret); err != nil {
+		log.Printf("error while trying to generate webhook secret: %v", err.Error())
+		return nil, err
+	}
+
+	newWebhook := models.Webhook{
+		UserID:  userID,
+		URL:     input.URL,
+		Secret:  "whsec_" + hex.EncodeToString(secret),
+		Events:  input.Events,
+		VaultID: input.VaultID,
+	}
+
+	if err := w.repository.Save(ctx, &newWebhook); err != nil {
+		log.Printf("error while trying to save webhook: %v", err.Error())
+		return nil, err
+	}
+
+	detail := toWebhookDetail(newWebhook)
+	detail.Secret = newWebhook.Secret
+
+	return &detail, nil
+}
+
+func (w *webhookService) GetAll(ctx context.Context) ([]models.WebhookDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return []models.WebhookDetail{}, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	webhooks, err := w.repository.FindByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find webhooks by userId: %v", err.Error())
+		return []models.WebhookDetail{}, err
+	}
+
+	return utils.Map(webhooks, toWebhookDetail), nil
+}
+
+func (w *webhookService) Delete(ctx context.Context, id uint) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if _, err := w.findWebhook(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := w.repository.Delete(ctx, id); err != nil {
+		log.Printf("error while trying to delete webhook: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (w *webhookService) DeadLetters(ctx context.Context) ([]models.WebhookDeadLetterDetail, error) {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return []models.WebhookDeadLetterDetail{}, cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	deadLetters, err := w.repository.FindDeadLettersByUserID(ctx, userID)
+
+	if err != nil {
+		log.Printf("error while trying to find webhook dead letters by userId: %v", err.Error())
+		return []models.WebhookDeadLetterDetail{}, err
+	}
+
+	return utils.Map(deadLetters, toWebhookDeadLetterDetail), nil
+}
+
+func (w *webhookService) Redeliver(ctx context.Context, id uint) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	deadLetter, err := w.findDeadLetter(ctx, userID, id)
+
+	if err != nil {
+		return err
+	}
+
+	webhook, err := w.findWebhook(ctx, userID, deadLetter.WebhookID)
+
+	if err != nil {
+		return err
+	}
+
+	delivery := webhookDelivery{*webhook, deadLetter.EventID, deadLetter.EventType, deadLetter.Payload}
+
+	select {
+	case w.queue <- delivery:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// Deleted once queued, so the event isn't lost. Should this fail, the
+	// event may be delivered twice, which receivers tell by its ID.
+	if err := w.repository.DeleteDeadLetter(ctx, id); err != nil {
+		log.Printf("error while trying to delete webhook dead letter: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (w *webhookService) Discard(ctx context.Context, id uint) error {
+	userID, ok := ctx.Value(keys.UserIDKey).(uint)
+
+	if !ok {
+		return cerrors.UnauthorizedError("user is not authenticated")
+	}
+
+	if _, err := w.findDeadLetter(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := w.repository.DeleteDeadLetter(ctx, id); err != nil {
+		log.Printf("error while trying to delete webhook dead letter: %v", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Notify queues the deliveries of event to the webhooks subscribed to it,
+// without waiting for them. Events finding the queue full go straight to the
+// dead letters.
+func (w *webhookService) Notify(ctx context.Context, event models.Event) {
+	webhooks, err := w.repository.FindByUserID(ctx, event.UserID)
+
+	if err != nil {
+		log.Printf("error while trying to find webhooks by userId: %v", err.Error())
+		return
+	}
+
+	payload, err := json.Marshal(event)
+
+	if err != nil {
+		log.Printf("error while trying to encode event: %v", err.Error())
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !subscribed(webhook, event) {
+			continue
+		}
+
+		delivery := webhookDelivery{webhook, event.ID, event.Type, payload}
+
+		select {
+		case w.queue <- delivery:
+		default:
+			w.deadLetter(delivery, 0, "delivery queue is full")
+		}
+	}
+}
+
+// Run delivers the queued events until ctx is done. The attempts in flight
+// are finished then, within webhookTimeout, and the deliveries still queued
+// or waiting for their next attempt go to the dead letters, so they can be
+// redelivered.
+func (w *webhookService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < webhookWorkers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case delivery := <-w.queue:
+					w.deliver(ctx, delivery)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for {
+		select {
+		case delivery := <-w.queue:
+			w.deadLetter(delivery, 0, "server stopped")
+		default:
+			return
+		}
+	}
+}
+
+// deliver posts a delivery until it succeeds, backing off between attempts.
+// Deliveries that fail webhookMaxAttempts times, or in a way retrying can't
+// fix, go to the dead letters.
+func (w *webhookService) deliver(ctx context.Context, delivery webhookDelivery) {
+	backoff := w.backoff
+
+	for attempt := 1; ; attempt++ {
+		retry, err := w.send(delivery)
+
+		if err == nil {
+			return
+		}
+
+		if !retry || attempt == webhookMaxAttempts {
+			w.deadLetter(delivery, attempt, err.Error())
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			w.deadLetter(delivery, attempt, err.Error())
+			return
+		}
+
+		if backoff *= 2; backoff > webhookMaxBackoff {
+			backoff = webhookMaxBackoff
+		}
+	}
+}
+
+// send posts a delivery once. It tells whether a failed delivery is worth
+// retrying: failures of the network, the server or its rate limits are.
+func (w *webhookService) send(delivery webhookDelivery) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, delivery.webhook.URL, bytes.NewReader(delivery.payload))
+
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gopass-webhook")
+	req.Header.Set(webhook.EventHeader, delivery.eventType)
+	req.Header.Set(webhook.DeliveryHeader, delivery.eventID)
+	// Signed on each attempt, so retries aren't rejected as replays.
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(delivery.webhook.Secret, w.clock.Now(), delivery.payload))
+
+	resp, err := w.client.Do(req)
+
+	if err != nil {
+		return !errors.Is(err, errLocalWebhookAddress), err
+	}
+
+	defer resp.Body.Close()
+	// Drained, so the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+
+	return retry, fmt.Errorf("webhook responded with %s", resp.Status)
+}
+
+// deadLetter saves a delivery given up after attempts attempts. It runs once
+// the request publishing the event is over, so it doesn't use its context.
+func (w *webhookService) deadLetter(delivery webhookDelivery, attempts int, lastError string) {
+	deadLetter := models.WebhookDeadLetter{
+		WebhookID: delivery.webhook.ID,
+		UserID:    delivery.webhook.UserID,
+		EventID:   delivery.eventID,
+		EventType: delivery.eventType,
+		Payload:   delivery.payload,
+		Attempts:  attempts,
+		LastError: lastError,
+	}
+
+	if err := w.repository.SaveDeadLetter(context.Background(), &deadLetter); err != nil {
+		log.Printf("error while trying to save webhook dead letter: %v", err.Error())
+	}
+}
+
+func (w *webhookService) findWebhook(ctx context.Context, userID, id uint) (*models.Webhook, error) {
+	webhook, err := w.repository.FindByID(ctx, id)
+
+	if err != nil {
+		log.Printf("error while trying to find a webhook by id: %v", err.Error())
+		return nil, err
+	}
+
+	if webhook.ID == 0 || webhook.UserID != userID {
+		return nil, cerrors.NotFoundError("webhook not found")
+	}
+
+	return webhook, nil
+}
+
+func (w *webhookService) findDeadLetter(ctx context.Context, userID, id uint) (*models.WebhookDeadLetter, error) {
+	deadLetter, err := w.repository.FindDeadLetterByID(ctx, id)
+
+	if err != nil {
+		log.Printf("error while trying to find a webhook dead letter by id: %v", err.Error())
+		return nil, err
+	}
+
+	if deadLetter.ID == 0 || deadLetter.UserID != userID {
+		return nil, cerrors.NotFoundError("dead letter not found")
+	}
+
+	return deadLetter, nil
+}
+
+// validateWebhookURL checks that webhooks are posted over HTTPS to a host
+// outside the network of the server. With allowLocal, any host is allowed, and
+// HTTP to the machine of the server.
+func validateWebhookURL(rawURL string, allowLocal bool) error {
+	if utils.IsBlank(rawURL) {
+		return cerrors.BadRequestError("url is required")
+	}
+
+	u, err := url.Parse(rawURL)
+
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return cerrors.BadRequestError("url must be an absolute http or https URL")
+	}
+
+	if u.Scheme == "http" && !(allowLocal && isLoopback(u.Hostname())) {
+		return cerrors.BadRequestError("url must use https")
+	}
+
+	// Other hosts are checked once resolved, by checkWebhookAddress.
+	if ip := net.ParseIP(u.Hostname()); !allowLocal && (u.Hostname() == "localhost" || (ip != nil && isLocalIP(ip))) {
+		return cerrors.BadRequestError("url must not be a loopback, private or link-local address")
+	}
+
+	return nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkWebhookAddress is the Control of the dialer of deliveries. It runs
+// with the resolved address, so it also stops DNS names pointing, or rebound,
+// to the network of the server.
+func checkWebhookAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || isLocalIP(ip) {
+		return errLocalWebhookAddress
+	}
+
+	return nil
+}
+
+// isLocalIP tells whether ip is an address of the machine or the network of the server.
+func isLocalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
+
+// subscribed tells whether a webhook selects an event.
+func subscribed(webhook models.Webhook, event models.Event) bool {
+	if webhook.VaultID != nil && *webhook.VaultID != event.VaultID {
+		return false
+	}
+
+	return len(webhook.Events) == 0 || containsString(webhook.Events, event.Type)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toWebhookDetail(webhook models.Webhook) models.WebhookDetail {
+	return models.WebhookDetail{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		VaultID:   webhook.VaultID,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func toWebhookDeadLetterDetail(deadLetter models.WebhookDeadLetter) models.WebhookDeadLetterDetail {
+	return models.WebhookDeadLetterDetail{
+		ID:        deadLetter.ID,
+		WebhookID: deadLetter.WebhookID,
+		EventID:   deadLetter.EventID,
+		EventType: deadLetter.EventType,
+		Attempts:  deadLetter.Attempts,
+		LastError: deadLetter.LastError,
+		FailedAt:  deadLetter.CreatedAt,
+	}
+}
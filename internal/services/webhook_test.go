@@ -0,0 +1,484 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/edgardjr92/gopass/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestNewWebhookService(t *testing.T) {
+	repoMock := &mocks.WebhookRepositoryMock{}
+	vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+	webhookSvc := NewWebhookService(repoMock, vaultRepoMock, clock.Clock{}, false)
+
+	assert.Equal(t, repoMock, webhookSvc.repository)
+	assert.Equal(t, vaultRepoMock, webhookSvc.vaultRepository)
+	assert.False(t, webhookSvc.allowLocal)
+	assert.Equal(t, webhookQueueSize, cap(webhookSvc.queue))
+	assert.Equal(t, webhookBackoff, webhookSvc.backoff)
+}
+
+func TestCreateWebhook(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	vaultID, otherVaultID := uint(1), uint(2)
+
+	local := cerrors.BadRequestError("url must not be a loopback, private or link-local address")
+
+	testCases := []struct {
+		name       string
+		input      models.WebhookInput
+		allowLocal bool
+		expected   error
+	}{
+		{"https", models.WebhookInput{URL: "https://ci.acme.com/hooks/gopass", Events: []string{models.EventItemUpdated}, VaultID: &vaultID}, false, nil},
+		{"http to localhost", models.WebhookInput{URL: "http://localhost:8080/hook"}, false, cerrors.BadRequestError("url must use https")},
+		{"https to localhost", models.WebhookInput{URL: "https://localhost:8443/hook"}, false, local},
+		{"private address", models.WebhookInput{URL: "https://10.0.0.5/hook"}, false, local},
+		{"link-local address", models.WebhookInput{URL: "https://169.254.169.254/latest/meta-data"}, false, local},
+		{"loopback ipv6 address", models.WebhookInput{URL: "https://[::1]/hook"}, false, local},
+		{"http to localhost in development", models.WebhookInput{URL: "http://localhost:8080/hook"}, true, nil},
+		{"http to a loopback address in development", models.WebhookInput{URL: "http://127.0.0.1:8080/hook"}, true, nil},
+		{"private address in development", models.WebhookInput{URL: "https://10.0.0.5/hook"}, true, nil},
+		{"http to a private address in development", models.WebhookInput{URL: "http://10.0.0.5/hook"}, true, cerrors.BadRequestError("url must use https")},
+		{"missing url", models.WebhookInput{URL: " "}, false, cerrors.BadRequestError("url is required")},
+		{"relative url", models.WebhookInput{URL: "/hook"}, false, cerrors.BadRequestError("url must be an absolute http or https URL")},
+		{"other scheme", models.WebhookInput{URL: "ftp://acme.com/hook"}, false, cerrors.BadRequestError("url must be an absolute http or https URL")},
+		{"http", models.WebhookInput{URL: "http://ci.acme.com/hook"}, false, cerrors.BadRequestError("url must use https")},
+		{"unknown event", models.WebhookInput{URL: "https://ci.acme.com/hook", Events: []string{"item.read"}}, false, cerrors.BadRequestError(`unknown event type "item.read"`)},
+		{"vault of another user", models.WebhookInput{URL: "https://ci.acme.com/hook", VaultID: &otherVaultID}, false, cerrors.NotFoundError("vault not found")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			repoMock := &mocks.WebhookRepositoryMock{}
+			vaultRepoMock := &mocks.VaultRepositoryMock{}
+
+			vaultRepoMock.On("FindByID", ctx, uint(1)).Return(&models.Vault{Model: gorm.Model{ID: 1}, UserID: 10}, nil)
+			vaultRepoMock.On("FindByID", ctx, uint(2)).Return(&models.Vault{Model: gorm.Model{ID: 2}, UserID: 20}, nil)
+			repoMock.On("Save", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				args.Get(1).(*models.Webhook).ID = 3
+			})
+
+			// when
+			actual, error := NewWebhookService(repoMock, vaultRepoMock, clock.Clock{}, tc.allowLocal).Create(ctx, tc.input)
+
+			// then
+			assert.Equal(t, tc.expected, error)
+
+			if tc.expected != nil {
+				assert.Nil(t, actual)
+				repoMock.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.Equal(t, uint(3), actual.ID)
+			assert.Equal(t, tc.input.URL, actual.URL)
+			assert.Equal(t, tc.input.Events, actual.Events)
+			assert.Equal(t, tc.input.VaultID, actual.VaultID)
+			assert.True(t, strings.HasPrefix(actual.Secret, "whsec_"))
+			assert.Len(t, actual.Secret, len("whsec_")+2*webhookSecretSize)
+
+			saved := repoMock.Calls[0].Arguments.Get(1).(*models.Webhook)
+			assert.Equal(t, uint(10), saved.UserID)
+			assert.Equal(t, actual.Secret, saved.Secret)
+		})
+	}
+}
+
+func TestCheckWebhookAddress(t *testing.T) {
+	testCases := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", true},
+		{"127.0.0.1:443", false},
+		{"[::1]:443", false},
+		{"10.1.2.3:443", false},
+		{"172.16.0.1:443", false},
+		{"192.168.1.10:443", false},
+		{"[fd00::1]:443", false},
+		{"169.254.169.254:80", false},
+		{"[fe80::1]:443", false},
+		{"[::ffff:127.0.0.1]:443", false},
+		{"0.0.0.0:443", false},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.address, func(t *testing.T) {
+			// when
+			err := checkWebhookAddress("tcp", tc.address, nil)
+
+			// then
+			if tc.allowed {
+				assert.Nil(t, err)
+			} else {
+				assert.Equal(t, errLocalWebhookAddress, err)
+			}
+		})
+	}
+}
+
+func TestGetAllWebhooks(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	// given
+	repoMock := &mocks.WebhookRepositoryMock{}
+	repoMock.On("FindByUserID", ctx, uint(10)).Return([]models.Webhook{
+		{Model: gorm.Model{ID: 3}, UserID: 10, URL: "https://ci.acme.com/hook", Secret: "whsec_secret", Events: []string{models.EventItemUpdated}},
+	}, nil)
+
+	// when
+	actual, error := NewWebhookService(repoMock, &mocks.VaultRepositoryMock{}, clock.Clock{}, false).GetAll(ctx)
+
+	// then: secrets aren't returned again
+	assert.Nil(t, error)
+	assert.Equal(t, []models.WebhookDetail{{ID: 3, URL: "https://ci.acme.com/hook", Events: []string{models.EventItemUpdated}}}, actual)
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.WebhookRepositoryMock{}
+		repoMock.On("FindByID", ctx, uint(3)).Return(&models.Webhook{Model: gorm.Model{ID: 3}, UserID: 10}, nil)
+		repoMock.On("Delete", ctx, uint(3)).Return(nil)
+
+		// when
+		error := NewWebhookService(repoMock, &mocks.VaultRepositoryMock{}, clock.Clock{}, false).Delete(ctx, 3)
+
+		// then
+		assert.Nil(t, error)
+		repoMock.AssertExpectations(t)
+	})
+
+	t.Run("webhook of another user", func(t *testing.T) {
+		// given
+		repoMock := &mocks.WebhookRepositoryMock{}
+		repoMock.On("FindByID", ctx, uint(3)).Return(&models.Webhook{Model: gorm.Model{ID: 3}, UserID: 20}, nil)
+
+		// when
+		error := NewWebhookService(repoMock, &mocks.VaultRepositoryMock{}, clock.Clock{}, false).Delete(ctx, 3)
+
+		// then
+		assert.Equal(t, cerrors.NotFoundError("webhook not found"), error)
+		repoMock.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), keys.UserIDKey, uint(10))
+	deadLetter := &models.WebhookDeadLetter{
+		Model:     gorm.Model{ID: 7},
+		WebhookID: 3,
+		UserID:    10,
+		EventID:   "e1",
+		EventType: models.EventItemUpdated,
+		Payload:   []byte(`{"id":"e1"}`),
+		Attempts:  5,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		repoMock := &mocks.WebhookRepositoryMock{}
+		hook := &models.Webhook{Model: gorm.Model{ID: 3}, UserID: 10, URL: "https://ci.acme.com/hook"}
+		repoMock.On("FindDeadLetterByID", ctx, uint(7)).Return(deadLetter, nil)
+		repoMock.On("FindByID", ctx, uint(3)).Return(hook, nil)
+		repoMock.On("DeleteDeadLetter", ctx, uint(7)).Return(nil)
+		webhookSvc := NewWebhookService(repoMock, &mocks.VaultRepositoryMock{}, clock.Clock{}, false)
+
+		// when
+		error := webhookSvc.Redeliver(ctx, 7)
+
+		// then
+		assert.Nil(t, error)
+		assert.Equal(t, webhookDelivery{*hook, "e1", models.EventItemUpdated, []byte(`{"id":"e1"}`)}, <-webhookSvc.queue)
+		repoMock.AssertExpectations(t)
+	})
+
+	t.Run("webhook deleted", func(t *testing.T) {
+		// given
+		repoMock := &mocks.WebhookRepositoryMock{}
+		repoMock.On("FindDeadLetterByID", ctx, uint(7)).Return(deadLetter, nil)
+		repoMock.On("FindByID", ctx, uint(3)).Return(&models.Webhook{}, nil)
+
+		// when
+		error := NewWebhookService(repoMock, &mocks.VaultRepositoryMock{}, clock.Clock{}, false).Redeliver(ctx, 7)
+
+		// then
+		assert.Equal(t, cerrors.NotFoundError("webhook not found"), error)
+		repoMock.AssertNotCalled(t, "DeleteDeadLetter", mock.Anything, mock.Anything)
+	})
+
+	t.Run("dead letter of another user", func(t *testing.T) {
+		// given
+		repoMock := &mocks.WebhookRepositoryMock{}
+		repoMock.On("FindDeadLetterByID", ctx, uint(8)).Return(&models.WebhookDeadLetter{Model: gorm.Model{ID: 8}, UserID: 20}, nil)
+
+		// when
+		error := NewWebhookService(repoMock, &mocks.VaultRepositoryMock{}, clock.Clock{}, false).Redeliver(ctx, 8)
+
+		// then
+		assert.Equal(t, cerrors.NotFoundError("dead letter not found"), error)
+	})
+}
+
+func TestWebhookSubscribed(t *testing.T) {
+	vaultID := uint(1)
+	event := models.Event{Type: models.EventItemUpdated, VaultID: 1, ItemID: 5}
+
+	testCases := []struct {
+		name     string
+		webhook  models.Webhook
+		expected bool
+	}{
+		{"all events", models.Webhook{}, true},
+		{"event type selected", models.Webhook{Events: []string{models.EventItemCreated, models.EventItemUpdated}}, true},
+		{"event type not selected", models.Webhook{Events: []string{models.EventItemDeleted}}, false},
+		{"vault selected", models.Webhook{VaultID: &vaultID}, true},
+		{"vault not selected", models.Webhook{VaultID: new(uint)}, false},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, subscribed(tc.webhook, event))
+		})
+	}
+}
+
+// webhookReceiver is a webhook endpoint responding with the given statuses,
+// then 204, and verifying the signatures of the deliveries it gets.
+type webhookReceiver struct {
+	t        *testing.T
+	mu       sync.Mutex
+	statuses []int
+	payloads []string
+	received chan struct{}
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) (*webhookReceiver, *httptest.Server) {
+	receiver := &webhookReceiver{t: t, statuses: statuses, received: make(chan struct{}, 16)}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	return receiver, server
+}
+
+func (rc *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	payload, _ := io.ReadAll(r.Body)
+	err := webhook.Verify("whsec_test", r.Header.Get(webhook.SignatureHeader), payload, time.Now(), webhook.DefaultTolerance)
+	assert.Nil(rc.t, err)
+	assert.Equal(rc.t, http.MethodPost, r.Method)
+	assert.Equal(rc.t, models.EventItemUpdated, r.Header.Get(webhook.EventHeader))
+	assert.Equal(rc.t, "e1", r.Header.Get(webhook.DeliveryHeader))
+
+	rc.mu.Lock()
+	rc.payloads = append(rc.payloads, string(payload))
+	status := http.StatusNoContent
+
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+
+	rc.mu.Unlock()
+
+	w.WriteHeader(status)
+	rc.received <- struct{}{}
+}
+
+// attempts returns the number of deliveries received.
+func (rc *webhookReceiver) attempts() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return len(rc.payloads)
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	event := models.Event{
+		ID:         "e1",
+		Type:       models.EventItemUpdated,
+		UserID:     10,
+		VaultID:    1,
+		ItemID:     5,
+		OccurredAt: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+	}
+	payload, _ := json.Marshal(event)
+
+	// startWith runs a webhook service delivering to a webhook posting to url.
+	startWith := func(t *testing.T, url string, allowLocal bool) (*webhookService, *mocks.WebhookRepositoryMock) {
+		repoMock := &mocks.WebhookRepositoryMock{}
+		repoMock.On("FindByUserID", mock.Anything, uint(10)).Return([]models.Webhook{
+			{Model: gorm.Model{ID: 3}, UserID: 10, URL: url, Secret: "whsec_test"},
+			{Model: gorm.Model{ID: 4}, UserID: 10, URL: url, Secret: "whsec_test", Events: []string{models.EventItemDeleted}},
+		}, nil)
+
+		webhookSvc := NewWebhookService(repoMock, &mocks.VaultRepositoryMock{}, clock.Clock{}, allowLocal)
+		webhookSvc.backoff = time.Millisecond
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		done := make(chan struct{})
+		go func() {
+			webhookSvc.Run(ctx)
+			close(done)
+		}()
+		t.Cleanup(func() {
+			cancel()
+			<-done
+		})
+
+		return webhookSvc, repoMock
+	}
+
+	// start runs a webhook service allowed to deliver to the test receivers, on loopback addresses.
+	start := func(t *testing.T, url string) (*webhookService, *mocks.WebhookRepositoryMock) {
+		return startWith(t, url, true)
+	}
+
+	// deadLetters returns a channel receiving the dead letters saved.
+	deadLetters := func(repoMock *mocks.WebhookRepositoryMock) chan *models.WebhookDeadLetter {
+		saved := make(chan *models.WebhookDeadLetter, 1)
+		repoMock.On("SaveDeadLetter", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			saved <- args.Get(1).(*models.WebhookDeadLetter)
+		})
+		return saved
+	}
+
+	t.Run("delivered once", func(t *testing.T) {
+		// given
+		receiver, server := newWebhookReceiver(t)
+		webhookSvc, repoMock := start(t, server.URL)
+
+		// when
+		webhookSvc.Notify(context.TODO(), event)
+
+		// then: only the webhook selecting the event gets it
+		<-receiver.received
+		assert.Equal(t, []string{string(payload)}, receiver.payloads)
+		repoMock.AssertNotCalled(t, "SaveDeadLetter", mock.Anything, mock.Anything)
+	})
+
+	t.Run("retried until delivered", func(t *testing.T) {
+		// given
+		receiver, server := newWebhookReceiver(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		webhookSvc, repoMock := start(t, server.URL)
+
+		// when
+		webhookSvc.Notify(context.TODO(), event)
+
+		// then
+		for i := 0; i < 3; i++ {
+			<-receiver.received
+		}
+		assert.Equal(t, 3, receiver.attempts())
+		repoMock.AssertNotCalled(t, "SaveDeadLetter", mock.Anything, mock.Anything)
+	})
+
+	t.Run("dead letter after the last attempt", func(t *testing.T) {
+		// given
+		statuses := make([]int, webhookMaxAttempts)
+		for i := range statuses {
+			statuses[i] = http.StatusInternalServerError
+		}
+		receiver, server := newWebhookReceiver(t, statuses...)
+		webhookSvc, repoMock := start(t, server.URL)
+		saved := deadLetters(repoMock)
+
+		// when
+		webhookSvc.Notify(context.TODO(), event)
+
+		// then
+		assert.Equal(t, &models.WebhookDeadLetter{
+			WebhookID: 3,
+			UserID:    10,
+			EventID:   "e1",
+			EventType: models.EventItemUpdated,
+			Payload:   payload,
+			Attempts:  webhookMaxAttempts,
+			LastError: "webhook responded with 500 Internal Server Error",
+		}, <-saved)
+		assert.Equal(t, webhookMaxAttempts, receiver.attempts())
+	})
+
+	t.Run("client errors aren't retried", func(t *testing.T) {
+		// given
+		receiver, server := newWebhookReceiver(t, http.StatusGone)
+		webhookSvc, repoMock := start(t, server.URL)
+		saved := deadLetters(repoMock)
+
+		// when
+		webhookSvc.Notify(context.TODO(), event)
+
+		// then
+		deadLetter := <-saved
+		assert.Equal(t, 1, deadLetter.Attempts)
+		assert.Equal(t, "webhook responded with 410 Gone", deadLetter.LastError)
+		assert.Equal(t, 1, receiver.attempts())
+	})
+
+	t.Run("local addresses aren't reached", func(t *testing.T) {
+		// given
+		receiver, server := newWebhookReceiver(t)
+		webhookSvc, repoMock := startWith(t, server.URL, false)
+		saved := deadLetters(repoMock)
+
+		// when
+		webhookSvc.Notify(context.TODO(), event)
+
+		// then: the connection is refused when dialing, and not retried
+		deadLetter := <-saved
+		assert.Equal(t, 1, deadLetter.Attempts)
+		assert.Contains(t, deadLetter.LastError, errLocalWebhookAddress.Error())
+		assert.Equal(t, 0, receiver.attempts())
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		// given
+		repoMock := &mocks.WebhookRepositoryMock{}
+		repoMock.On("FindByUserID", mock.Anything, uint(10)).Return([]models.Webhook{{Model: gorm.Model{ID: 3}, UserID: 10}}, nil)
+		saved := deadLetters(repoMock)
+		webhookSvc := NewWebhookService(repoMock, &mocks.VaultRepositoryMock{}, clock.Clock{}, false)
+		webhookSvc.queue = make(chan webhookDelivery)
+
+		// when
+		webhookSvc.Notify(context.TODO(), event)
+
+		// then
+		deadLetter := <-saved
+		assert.Equal(t, 0, deadLetter.Attempts)
+		assert.Equal(t, "delivery queue is full", deadLetter.LastError)
+	})
+
+	t.Run("webhooks not found", func(t *testing.T) {
+		// given
+		repoMock := &mocks.WebhookRepositoryMock{}
+		repoMock.On("FindByUserID", mock.Anything, uint(10)).Return([]models.Webhook{}, errors.New("error when finding"))
+		webhookSvc := NewWebhookService(repoMock, &mocks.VaultRepositoryMock{}, clock.Clock{}, false)
+
+		// when
+		webhookSvc.Notify(context.TODO(), event)
+
+		// then
+		assert.Empty(t, webhookSvc.queue)
+	})
+}
@@ -0,0 +1,276 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	magic       = "GPBI"
+	version     = 1
+	fanoutSize  = 1 << 16
+	headerSize  = 4 + 4 + 8 + fanoutSize*8
+	recordSize  = sha1.Size + 4
+	maxLineSize = 64
+)
+
+// ErrInvalidIndex is returned when a file is not a breach index or is corrupted.
+var ErrInvalidIndex = errors.New("breach: invalid index")
+
+// Index is a read-only, on-disk index of breached SHA-1 password hashes.
+//
+// The file starts with a header holding a fanout table keyed by the first two
+// bytes of each hash, followed by fixed-size records sorted by hash. A lookup
+// narrows the search to a single fanout bucket and then binary searches it with
+// positioned reads, so the corpus never has to be loaded into memory.
+//
+// An Index is safe for concurrent use.
+type Index struct {
+	r      io.ReaderAt
+	closer io.Closer
+	count  uint64
+	fanout [fanoutSize]uint64
+}
+
+// Open opens the breach index stored at path.
+func Open(path string) (*Index, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := New(f)
+
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	idx.closer = f
+
+	return idx, nil
+}
+
+// New reads the header of a breach index from r.
+// The reader must remain open for as long as the index is used.
+func New(r io.ReaderAt) (*Index, error) {
+	header := make([]byte, headerSize)
+
+	if _, err := r.ReadAt(header, 0); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrInvalidIndex
+		}
+		return nil, err
+	}
+
+	if string(header[:4]) != magic || binary.BigEndian.Uint32(header[4:8]) != version {
+		return nil, ErrInvalidIndex
+	}
+
+	idx := &Index{r: r, count: binary.BigEndian.Uint64(header[8:16])}
+
+	prev := uint64(0)
+	for i := range idx.fanout {
+		offset := 16 + i*8
+		idx.fanout[i] = binary.BigEndian.Uint64(header[offset : offset+8])
+
+		if idx.fanout[i] < prev || idx.fanout[i] > idx.count {
+			return nil, ErrInvalidIndex
+		}
+		prev = idx.fanout[i]
+	}
+
+	if prev != idx.count {
+		return nil, ErrInvalidIndex
+	}
+
+	return idx, nil
+}
+
+// Len returns the number of hashes in the index.
+func (i *Index) Len() uint64 {
+	return i.count
+}
+
+// Close releases the file backing the index, if any.
+func (i *Index) Close() error {
+	if i.closer == nil {
+		return nil
+	}
+	return i.closer.Close()
+}
+
+// Count returns how many times password was seen in the breach corpus.
+// A zero count means the password is not in the corpus.
+func (i *Index) Count(password string) (uint32, error) {
+	return i.Lookup(sha1.Sum([]byte(password)))
+}
+
+// Lookup returns the breach count recorded for a SHA-1 hash,
+// or zero if the hash is not in the index.
+func (i *Index) Lookup(hash [sha1.Size]byte) (uint32, error) {
+	bucket := int(hash[0])<<8 | int(hash[1])
+
+	lo := uint64(0)
+	if bucket > 0 {
+		lo = i.fanout[bucket-1]
+	}
+	hi := i.fanout[bucket]
+
+	record := make([]byte, recordSize)
+
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		if _, err := i.r.ReadAt(record, int64(headerSize+mid*recordSize)); err != nil {
+			return 0, err
+		}
+
+		switch bytes.Compare(record[:sha1.Size], hash[:]) {
+		case 0:
+			return binary.BigEndian.Uint32(record[sha1.Size:]), nil
+		case -1:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return 0, nil
+}
+
+// BuildFile builds a breach index at path from a HIBP corpus.
+// See Build for the accepted input format.
+func BuildFile(path string, src io.Reader) error {
+	f, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := Build(f, src); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+
+	return f.Close()
+}
+
+// Build writes a breach index to w from a HIBP "Pwned Passwords" corpus and
+// returns the number of hashes written.
+//
+// The corpus is the downloadable SHA-1 file ordered by hash: one "HASH:COUNT"
+// line per entry, where HASH is the 40 character hexadecimal SHA-1 of the
+// password (its 5 character range prefix followed by the 35 character suffix).
+// Blank lines are ignored and lines must be sorted by hash.
+func Build(w io.WriteSeeker, src io.Reader) (uint64, error) {
+	if _, err := w.Write(make([]byte, headerSize)); err != nil {
+		return 0, err
+	}
+
+	var (
+		fanout [fanoutSize]uint64
+		count  uint64
+		prev   []byte
+		lineNo int
+	)
+
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, maxLineSize), maxLineSize)
+	out := bufio.NewWriter(w)
+	record := make([]byte, recordSize)
+
+	for scanner.Scan() {
+		lineNo++
+		line := bytes.TrimSpace(scanner.Bytes())
+
+		if len(line) == 0 {
+			continue
+		}
+
+		if err := parseLine(line, record); err != nil {
+			return 0, fmt.Errorf("breach: line %d: %w", lineNo, err)
+		}
+
+		if prev != nil && bytes.Compare(prev, record[:sha1.Size]) >= 0 {
+			return 0, fmt.Errorf("breach: line %d: hashes are not sorted or contain duplicates", lineNo)
+		}
+		prev = append(prev[:0], record[:sha1.Size]...)
+
+		if _, err := out.Write(record); err != nil {
+			return 0, err
+		}
+
+		fanout[int(record[0])<<8|int(record[1])]++
+		count++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("breach: line %d: %w", lineNo+1, err)
+	}
+
+	if err := out.Flush(); err != nil {
+		return 0, err
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	binary.BigEndian.PutUint32(header[4:8], version)
+	binary.BigEndian.PutUint64(header[8:16], count)
+
+	total := uint64(0)
+	for i, n := range fanout {
+		total += n
+		binary.BigEndian.PutUint64(header[16+i*8:], total)
+	}
+
+	if _, err := w.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func parseLine(line []byte, record []byte) error {
+	sep := bytes.IndexByte(line, ':')
+
+	if sep != hex.EncodedLen(sha1.Size) {
+		return errors.New("expected a 40 character SHA-1 hash followed by ':'")
+	}
+
+	if _, err := hex.Decode(record[:sha1.Size], line[:sep]); err != nil {
+		return fmt.Errorf("invalid hash: %w", err)
+	}
+
+	var count uint64
+	for _, c := range line[sep+1:] {
+		if c < '0' || c > '9' {
+			return errors.New("invalid count")
+		}
+		count = count*10 + uint64(c-'0')
+		if count > 1<<32-1 {
+			count = 1<<32 - 1
+		}
+	}
+
+	if len(line) == sep+1 {
+		return errors.New("missing count")
+	}
+
+	binary.BigEndian.PutUint32(record[sha1.Size:], uint32(count))
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func corpus(counts map[string]uint32) string {
+	lines := make([]string, 0, len(counts))
+	for password, count := range counts {
+		sum := sha1.Sum([]byte(password))
+		lines = append(lines, strings.ToUpper(hex.EncodeToString(sum[:]))+":"+strconv.FormatUint(uint64(count), 10))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func TestBuildAndLookup(t *testing.T) {
+	// given
+	counts := map[string]uint32{
+		"password": 9545824,
+		"123456":   37359195,
+		"qwerty":   3912816,
+		"letmein":  478253,
+	}
+	path := filepath.Join(t.TempDir(), "pwned.idx")
+
+	// when
+	err := BuildFile(path, strings.NewReader(corpus(counts)))
+
+	// then
+	assert.Nil(t, err)
+
+	idx, err := Open(path)
+	assert.Nil(t, err)
+	defer idx.Close()
+
+	assert.Equal(t, uint64(len(counts)), idx.Len())
+
+	for password, expected := range counts {
+		actual, err := idx.Count(password)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual, password)
+	}
+
+	actual, err := idx.Count("correct horse battery staple")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), actual)
+}
+
+func TestBuildErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		corpus string
+		err    string
+	}{
+		{"invalid hash", "ZZAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n", "breach: line 1: invalid hash"},
+		{"short hash", "1E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n", "breach: line 1: expected a 40 character SHA-1 hash followed by ':'"},
+		{"missing count", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:\n", "breach: line 1: missing count"},
+		{"invalid count", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:x\n", "breach: line 1: invalid count"},
+		{
+			"unsorted",
+			"7C4A8D09CA3762AF61E59520943DC26494F8941B:1\n5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n",
+			"breach: line 2: hashes are not sorted or contain duplicates",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			path := filepath.Join(t.TempDir(), "pwned.idx")
+
+			// when
+			err := BuildFile(path, strings.NewReader(tc.corpus))
+
+			// then
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+			assert.NoFileExists(t, path)
+		})
+	}
+}
+
+func TestOpenInvalidIndex(t *testing.T) {
+	// when
+	idx, err := New(strings.NewReader("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n"))
+
+	// then
+	assert.Nil(t, idx)
+	assert.Equal(t, ErrInvalidIndex, err)
+}
+
+func TestEmptyIndex(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "pwned.idx")
+	assert.Nil(t, BuildFile(path, strings.NewReader("")))
+
+	// when
+	idx, err := Open(path)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), idx.Len())
+
+	actual, err := idx.Count("password")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), actual)
+	assert.Nil(t, idx.Close())
+}
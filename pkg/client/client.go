@@ -0,0 +1,765 @@
+// Package client is a Go client for the gopass server API.
+//
+// A Client is created with New and authenticated with Login, or with a token
+// obtained earlier:
+//
+//	c := client.New("https://gopass.example.com", "")
+//	if _, err := c.Login(ctx, "john@example.com", masterPassword); err != nil {
+//		return err
+//	}
+//	vaults, err := c.Vaults(ctx)
+//
+// The master password never leaves the client: Signup and Login send an
+// authentication key derived from it with DeriveAuthKey.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/seal"
+	"golang.org/x/crypto/hkdf"
+)
+
+// authKDFParams are the Argon2id parameters used by DeriveAuthKey.
+// Changing them changes every derived key, locking users out of their accounts.
+var authKDFParams = seal.DefaultKDFParams
+
+type Client struct {
+	// BaseURL is the URL of the server, such as https://gopass.example.com.
+	BaseURL string
+	// Token is the bearer token sent with each request. It is set by Login.
+	Token string
+	// HTTPClient sends the requests. http.DefaultClient is used when it is nil.
+	HTTPClient *http.Client
+}
+
+// Vault holds items. Revision changes on every write of the vault and is
+// passed to RenameVault.
+type Vault struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Revision uint64 `json:"revision,omitempty"`
+}
+
+// Item is a login stored in a vault. Revision changes on every write of the
+// item and is passed to UpdateItem through Input.
+type Item struct {
+	ID        uint      `json:"id"`
+	VaultID   uint      `json:"vaultId"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	URLMatch  string    `json:"urlMatch,omitempty"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password"`
+	Notes     string    `json:"notes"`
+	SSHKey    string    `json:"sshKey,omitempty"`
+	TOTP      string    `json:"totp,omitempty"`
+	HasTOTP   bool      `json:"hasTotp,omitempty"`
+	FolderID  *uint     `json:"folderId,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Favorite  bool      `json:"favorite,omitempty"`
+	Revision  uint64    `json:"revision,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Folder groups items of a vault. ParentID is nil for folders at the root of the vault.
+type Folder struct {
+	ID       uint   `json:"id"`
+	VaultID  uint   `json:"vaultId"`
+	ParentID *uint  `json:"parentId,omitempty"`
+	Name     string `json:"name"`
+}
+
+type Tag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// AutofillMatch is an item to fill in a page. Match is the quality of the match,
+// one of "exact", "startsWith", "regex", "host", "domain" and "equivalent".
+type AutofillMatch struct {
+	Item
+	VaultName string `json:"vaultName"`
+	Match     string `json:"match"`
+}
+
+// SyncChanges holds the vaults and items created, updated or deleted since a
+// revision. Revision is the revision to pass to Sync next time.
+type SyncChanges struct {
+	Revision uint64           `json:"revision"`
+	Vaults   ChangeSet[Vault] `json:"vaults"`
+	Items    ChangeSet[Item]  `json:"items"`
+}
+
+// ChangeSet lists created and updated records, and the IDs of deleted ones.
+type ChangeSet[T any] struct {
+	Created []T    `json:"created"`
+	Updated []T    `json:"updated"`
+	Deleted []uint `json:"deleted"`
+}
+
+// AuditEvent is an entry of the audit log, chained to the entry before it by
+// PrevHash. Outcome is "success" or "failure".
+type AuditEvent struct {
+	Seq       uint64    `json:"seq"`
+	Action    string    `json:"action"`
+	Outcome   string    `json:"outcome"`
+	UserID    uint      `json:"userId,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	VaultID   uint      `json:"vaultId,omitempty"`
+	ItemID    uint      `json:"itemId,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	PrevHash  string    `json:"prevHash"`
+	Hash      string    `json:"hash"`
+}
+
+// AuditFilter selects the events returned by AuditEvents. Zero values select all events.
+type AuditFilter struct {
+	VaultID uint
+	// From and To bound the time of the events, To excluded.
+	From time.Time
+	To   time.Time
+	// Limit is the maximum number of events, picked by the server when zero.
+	Limit uint
+}
+
+// AuditVerification is the result of the verification of the audit log.
+// When Valid is false, BrokenAt is the first entry not matching the chain.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  uint64 `json:"entries"`
+	Head     string `json:"head"`
+	BrokenAt uint64 `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Webhook posts the events of the vaults of the user to URL, signed with
+// Secret, which is only returned by CreateWebhook. Events selects the event
+// types posted, all of them when empty, and VaultID the vault, all of them when nil.
+type Webhook struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events,omitempty"`
+	VaultID   *uint     `json:"vaultId,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookDeadLetter is an event that couldn't be delivered to a webhook.
+type WebhookDeadLetter struct {
+	ID        uint      `json:"id"`
+	WebhookID uint      `json:"webhookId"`
+	EventID   string    `json:"eventId"`
+	EventType string    `json:"eventType"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError"`
+	FailedAt  time.Time `json:"failedAt"`
+}
+
+// ItemFilter selects the items returned by ListItems. Zero values select all items.
+type ItemFilter struct {
+	// FolderID selects the items in a folder and its subfolders.
+	FolderID uint
+	// Tag selects the items with a tag, by name.
+	Tag string
+	// Favorite selects the favorite items.
+	Favorite bool
+}
+
+// TOTPCode is the current one-time password of an item.
+type TOTPCode struct {
+	Code string `json:"code"`
+	// Period is how long each code is valid, in seconds.
+	Period uint `json:"period"`
+	// Remaining is how long the code remains valid, in seconds.
+	Remaining uint `json:"remaining"`
+}
+
+// SearchResult is a vault or an item matching a search query. Kind is "vault" or "item".
+type SearchResult struct {
+	Kind      string   `json:"kind"`
+	ID        uint     `json:"id"`
+	VaultID   uint     `json:"vaultId"`
+	VaultName string   `json:"vaultName"`
+	Name      string   `json:"name"`
+	Username  string   `json:"username,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Score     float64  `json:"score"`
+	Matches   []string `json:"matches"`
+}
+
+// ItemInput holds the fields of an item that are set on create and update.
+// Revision is the revision of the item the update is based on; the update
+// fails with a 412 Error when the item was changed since.
+type ItemInput struct {
+	Name     string   `json:"name"`
+	URL      string   `json:"url"`
+	URLMatch string   `json:"urlMatch,omitempty"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	Notes    string   `json:"notes"`
+	SSHKey   string   `json:"sshKey,omitempty"`
+	TOTP     string   `json:"totp,omitempty"`
+	FolderID *uint    `json:"folderId,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Revision uint64   `json:"revision,omitempty"`
+}
+
+// Input returns the fields of the item that can be changed by UpdateItem.
+func (i Item) Input() ItemInput {
+	return ItemInput{
+		Name:     i.Name,
+		URL:      i.URL,
+		URLMatch: i.URLMatch,
+		Username: i.Username,
+		Password: i.Password,
+		Notes:    i.Notes,
+		SSHKey:   i.SSHKey,
+		TOTP:     i.TOTP,
+		FolderID: i.FolderID,
+		Tags:     i.Tags,
+		Revision: i.Revision,
+	}
+}
+
+// Field returns the value of the field with the given name, one of secretref.Fields.
+func (i Item) Field(name string) (string, bool) {
+	switch name {
+	case "name":
+		return i.Name, true
+	case "username":
+		return i.Username, true
+	case "password":
+		return i.Password, true
+	case "url":
+		return i.URL, true
+	case "notes":
+		return i.Notes, true
+	default:
+		return "", false
+	}
+}
+
+// Error is returned when the server responds with an error status.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (status %d)", e.Message, e.StatusCode)
+}
+
+// New returns a client for the server at baseURL, authenticated with token when it isn't empty.
+func New(baseURL, token string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), Token: token}
+}
+
+// DeriveAuthKey derives the key that authenticates a user from the master password.
+// The email, which is case insensitive, salts the derivation so users with the
+// same master password get different keys.
+func DeriveAuthKey(email, masterPassword string) (string, error) {
+	authKey, err := deriveKey(email, masterPassword, "gopass auth")
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(authKey), nil
+}
+
+// deriveKey derives a key for a purpose, named by info, from the master
+// password. Keys for different purposes are independent of each other.
+func deriveKey(email, masterPassword, info string) ([]byte, error) {
+	salt := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	masterKey := seal.DeriveKey(masterPassword, salt[:], authKDFParams)
+
+	key := make([]byte, 32)
+
+	if _, err := io.ReadFull(hkdf.New(sha256.New, masterKey, nil, []byte(info)), key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Signup creates a user and returns its ID.
+func (c *Client) Signup(ctx context.Context, name, email, masterPassword string) (uint, error) {
+	authKey, err := DeriveAuthKey(email, masterPassword)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var res createdResponse
+	err = c.do(ctx, http.MethodPost, "/users", map[string]string{"name": name, "email": email, "authKey": authKey}, &res)
+
+	return res.ID, err
+}
+
+// Login authenticates the user and returns a bearer token, which is also set as the client token.
+func (c *Client) Login(ctx context.Context, email, masterPassword string) (string, error) {
+	authKey, err := DeriveAuthKey(email, masterPassword)
+
+	if err != nil {
+		return "", err
+	}
+
+	var res struct {
+		Token string `json:"token"`
+	}
+
+	if err := c.do(ctx, http.MethodPost, "/auth/login", map[string]string{"email": email, "authKey": authKey}, &res); err != nil {
+		return "", err
+	}
+
+	c.Token = res.Token
+
+	return res.Token, nil
+}
+
+// ChangePassword changes the master password of the user, given the current
+// one, and seals their private key, if any, under the new one. The server
+// revokes every token of the user, so it logs in again and returns the new
+// token, which is also set as the client token.
+func (c *Client) ChangePassword(ctx context.Context, email, currentPassword, newPassword string) (string, error) {
+	currentAuthKey, err := DeriveAuthKey(email, currentPassword)
+
+	if err != nil {
+		return "", err
+	}
+
+	newAuthKey, err := DeriveAuthKey(email, newPassword)
+
+	if err != nil {
+		return "", err
+	}
+
+	body := struct {
+		CurrentAuthKey      string `json:"currentAuthKey"`
+		NewAuthKey          string `json:"newAuthKey"`
+		EncryptedPrivateKey []byte `json:"encryptedPrivateKey,omitempty"`
+	}{CurrentAuthKey: currentAuthKey, NewAuthKey: newAuthKey}
+
+	var keys userKeys
+	err = c.do(ctx, http.MethodGet, "/keys", nil, &keys)
+
+	var apiErr *Error
+
+	switch {
+	case err == nil:
+		privateKey, err := openPrivateKey(email, currentPassword, keys)
+
+		if err != nil {
+			return "", err
+		}
+
+		if body.EncryptedPrivateKey, err = sealPrivateKey(email, newPassword, keys.PublicKey, privateKey); err != nil {
+			return "", err
+		}
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		// The user has no keys yet.
+	default:
+		return "", err
+	}
+
+	if err := c.do(ctx, http.MethodPost, "/account/password", body, nil); err != nil {
+		return "", err
+	}
+
+	return c.Login(ctx, email, newPassword)
+}
+
+// Vaults returns all vaults from the user.
+func (c *Client) Vaults(ctx context.Context) ([]Vault, error) {
+	var vaults []Vault
+	err := c.do(ctx, http.MethodGet, "/vaults", nil, &vaults)
+
+	return vaults, err
+}
+
+// CreateVault creates a vault and returns its ID.
+func (c *Client) CreateVault(ctx context.Context, name string) (uint, error) {
+	var res createdResponse
+	err := c.do(ctx, http.MethodPost, "/vaults", map[string]string{"name": name}, &res)
+
+	return res.ID, err
+}
+
+// RenameVault changes the name of a vault, if it is still at revision.
+func (c *Client) RenameVault(ctx context.Context, id uint, name string, revision uint64) error {
+	body := struct {
+		Name     string `json:"name"`
+		Revision uint64 `json:"revision"`
+	}{name, revision}
+
+	return c.do(ctx, http.MethodPatch, "/vaults/"+idString(id), body, nil)
+}
+
+// DeleteVault deletes a vault and all its items.
+func (c *Client) DeleteVault(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, "/vaults/"+idString(id), nil, nil)
+}
+
+// Items returns all items from a vault.
+func (c *Client) Items(ctx context.Context, vaultID uint) ([]Item, error) {
+	return c.ListItems(ctx, vaultID, ItemFilter{})
+}
+
+// ListItems returns the items from a vault selected by the filter.
+func (c *Client) ListItems(ctx context.Context, vaultID uint, filter ItemFilter) ([]Item, error) {
+	params := url.Values{"vaultId": {idString(vaultID)}}
+
+	if filter.FolderID != 0 {
+		params.Set("folderId", idString(filter.FolderID))
+	}
+
+	if filter.Tag != "" {
+		params.Set("tag", filter.Tag)
+	}
+
+	if filter.Favorite {
+		params.Set("favorite", "true")
+	}
+
+	var items []Item
+	err := c.do(ctx, http.MethodGet, "/items?"+params.Encode(), nil, &items)
+
+	return items, err
+}
+
+// Item returns an item.
+func (c *Client) Item(ctx context.Context, id uint) (*Item, error) {
+	var item Item
+
+	if err := c.do(ctx, http.MethodGet, "/items/"+idString(id), nil, &item); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// CreateItem creates an item in a vault and returns its ID.
+func (c *Client) CreateItem(ctx context.Context, vaultID uint, input ItemInput) (uint, error) {
+	body := struct {
+		VaultID uint `json:"vaultId"`
+		ItemInput
+	}{vaultID, input}
+
+	var res createdResponse
+	err := c.do(ctx, http.MethodPost, "/items", body, &res)
+
+	return res.ID, err
+}
+
+// UpdateItem replaces all fields of an item, if it is still at the revision
+// of the input. MergeItemInput helps to retry when it isn't.
+func (c *Client) UpdateItem(ctx context.Context, id uint, input ItemInput) error {
+	return c.do(ctx, http.MethodPut, "/items/"+idString(id), input, nil)
+}
+
+// DeleteItem deletes an item.
+func (c *Client) DeleteItem(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, "/items/"+idString(id), nil, nil)
+}
+
+// SetFavorite adds an item to the favorites of the user, or removes it.
+func (c *Client) SetFavorite(ctx context.Context, itemID uint, favorite bool) error {
+	method := http.MethodPut
+
+	if !favorite {
+		method = http.MethodDelete
+	}
+
+	return c.do(ctx, method, "/favorites/"+idString(itemID), nil, nil)
+}
+
+// Folders returns all folders from a vault.
+func (c *Client) Folders(ctx context.Context, vaultID uint) ([]Folder, error) {
+	var folders []Folder
+	err := c.do(ctx, http.MethodGet, "/folders?vaultId="+idString(vaultID), nil, &folders)
+
+	return folders, err
+}
+
+// CreateFolder creates a folder in a vault, inside the parent folder when parentID isn't nil, and returns its ID.
+func (c *Client) CreateFolder(ctx context.Context, vaultID uint, name string, parentID *uint) (uint, error) {
+	body := struct {
+		VaultID  uint   `json:"vaultId"`
+		Name     string `json:"name"`
+		ParentID *uint  `json:"parentId,omitempty"`
+	}{vaultID, name, parentID}
+
+	var res createdResponse
+	err := c.do(ctx, http.MethodPost, "/folders", body, &res)
+
+	return res.ID, err
+}
+
+// UpdateFolder renames a folder and moves it to the parent folder, the root of the vault when parentID is nil.
+func (c *Client) UpdateFolder(ctx context.Context, id uint, name string, parentID *uint) error {
+	body := struct {
+		Name     string `json:"name"`
+		ParentID *uint  `json:"parentId,omitempty"`
+	}{name, parentID}
+
+	return c.do(ctx, http.MethodPut, "/folders/"+idString(id), body, nil)
+}
+
+// DeleteFolder deletes an empty folder.
+func (c *Client) DeleteFolder(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, "/folders/"+idString(id), nil, nil)
+}
+
+// Tags returns all tags from the user.
+func (c *Client) Tags(ctx context.Context) ([]Tag, error) {
+	var tags []Tag
+	err := c.do(ctx, http.MethodGet, "/tags", nil, &tags)
+
+	return tags, err
+}
+
+// RenameTag changes the name of a tag on every item that has it.
+func (c *Client) RenameTag(ctx context.Context, id uint, name string) error {
+	return c.do(ctx, http.MethodPatch, "/tags/"+idString(id), map[string]string{"name": name}, nil)
+}
+
+// DeleteTag deletes a tag and removes it from every item that has it.
+func (c *Client) DeleteTag(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, "/tags/"+idString(id), nil, nil)
+}
+
+// TOTP returns the current one-time password of an item.
+func (c *Client) TOTP(ctx context.Context, itemID uint) (*TOTPCode, error) {
+	var code TOTPCode
+
+	if err := c.do(ctx, http.MethodGet, "/totp/"+idString(itemID), nil, &code); err != nil {
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+// Search returns the vaults and items matching the query, best first.
+// The server picks the number of results when limit is zero.
+func (c *Client) Search(ctx context.Context, query string, limit uint) ([]SearchResult, error) {
+	params := url.Values{"q": {query}}
+
+	if limit > 0 {
+		params.Set("limit", idString(limit))
+	}
+
+	var results []SearchResult
+	err := c.do(ctx, http.MethodGet, "/search?"+params.Encode(), nil, &results)
+
+	return results, err
+}
+
+// Autofill returns the items to fill in the page at pageURL, best match first.
+func (c *Client) Autofill(ctx context.Context, pageURL string) ([]AutofillMatch, error) {
+	var matches []AutofillMatch
+	err := c.do(ctx, http.MethodGet, "/autofill?"+url.Values{"url": {pageURL}}.Encode(), nil, &matches)
+
+	return matches, err
+}
+
+// Sync returns the vaults and items created, updated or deleted since a
+// revision, 0 for all of them. Items hold their passwords and TOTP URIs.
+// The server answers with a conflict error when since is ahead of it, after
+// which the client should sync from 0.
+func (c *Client) Sync(ctx context.Context, since uint64) (*SyncChanges, error) {
+	var changes SyncChanges
+
+	path := "/sync?" + url.Values{"since": {strconv.FormatUint(since, 10)}}.Encode()
+
+	if err := c.do(ctx, http.MethodGet, path, nil, &changes); err != nil {
+		return nil, err
+	}
+
+	return &changes, nil
+}
+
+// AuditEvents returns the audit events of the user selected by the filter, newest first.
+func (c *Client) AuditEvents(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	params := url.Values{}
+
+	if filter.VaultID != 0 {
+		params.Set("vaultId", idString(filter.VaultID))
+	}
+
+	if !filter.From.IsZero() {
+		params.Set("from", filter.From.Format(time.RFC3339))
+	}
+
+	if !filter.To.IsZero() {
+		params.Set("to", filter.To.Format(time.RFC3339))
+	}
+
+	if filter.Limit != 0 {
+		params.Set("limit", strconv.FormatUint(uint64(filter.Limit), 10))
+	}
+
+	var events []AuditEvent
+	err := c.do(ctx, http.MethodGet, "/audit?"+params.Encode(), nil, &events)
+
+	return events, err
+}
+
+// VerifyAudit checks the hash chain of the audit log of the server.
+func (c *Client) VerifyAudit(ctx context.Context) (*AuditVerification, error) {
+	var verification AuditVerification
+
+	if err := c.do(ctx, http.MethodGet, "/audit/verify", nil, &verification); err != nil {
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+// Webhooks returns all webhooks from the user, without their secrets.
+func (c *Client) Webhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := c.do(ctx, http.MethodGet, "/webhooks", nil, &webhooks)
+
+	return webhooks, err
+}
+
+// CreateWebhook creates a webhook posting the events selected to url, and
+// returns it along with the secret signing its deliveries.
+func (c *Client) CreateWebhook(ctx context.Context, url string, events []string, vaultID *uint) (*Webhook, error) {
+	body := struct {
+		URL     string   `json:"url"`
+		Events  []string `json:"events,omitempty"`
+		VaultID *uint    `json:"vaultId,omitempty"`
+	}{url, events, vaultID}
+
+	var webhook Webhook
+
+	if err := c.do(ctx, http.MethodPost, "/webhooks", body, &webhook); err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// DeleteWebhook deletes a webhook and its dead letters.
+func (c *Client) DeleteWebhook(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, "/webhooks/"+idString(id), nil, nil)
+}
+
+// WebhookDeadLetters returns the events that couldn't be delivered to the webhooks of the user, newest first.
+func (c *Client) WebhookDeadLetters(ctx context.Context) ([]WebhookDeadLetter, error) {
+	var deadLetters []WebhookDeadLetter
+	err := c.do(ctx, http.MethodGet, "/webhooks/dead-letters", nil, &deadLetters)
+
+	return deadLetters, err
+}
+
+// RedeliverWebhook queues a dead letter for delivery again.
+func (c *Client) RedeliverWebhook(ctx context.Context, deadLetterID uint) error {
+	return c.do(ctx, http.MethodPost, "/webhooks/dead-letters/"+idString(deadLetterID), nil, nil)
+}
+
+// DiscardWebhookDeadLetter deletes a dead letter.
+func (c *Client) DiscardWebhookDeadLetter(ctx context.Context, deadLetterID uint) error {
+	return c.do(ctx, http.MethodDelete, "/webhooks/dead-letters/"+idString(deadLetterID), nil, nil)
+}
+
+type createdResponse struct {
+	ID uint `json:"id"`
+}
+
+// do sends a request with body encoded as JSON and decodes the JSON response into out.
+// Either of them may be nil.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return responseError(res)
+	}
+
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response from server: %w", err)
+	}
+
+	return nil
+}
+
+// responseError builds an Error from the message in the body of an error response.
+func responseError(res *http.Response) error {
+	var body struct {
+		Message string `json:"message"`
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+
+	if err := json.Unmarshal(data, &body); err != nil || body.Message == "" {
+		body.Message = http.StatusText(res.StatusCode)
+	}
+
+	return &Error{StatusCode: res.StatusCode, Message: body.Message}
+}
+
+func idString(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,702 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/handlers"
+	"github.com/edgardjr92/gopass/internal/keys"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/keywrap"
+	"github.com/edgardjr92/gopass/pkg/seal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func init() {
+	authKDFParams = seal.KDFParams{Time: 1, Memory: 64, Threads: 1}
+}
+
+// newServer starts a server with the gopass handlers, backed by the given service mocks.
+func newServer(t *testing.T, userSvc *mocks.UserServiceMock, authSvc *mocks.AuthServiceMock, vaultSvc *mocks.VaultServiceMock, itemSvc *mocks.ItemServiceMock) *httptest.Server {
+	validator := &mocks.JWTValidatorMock{}
+	validator.On("Validate", "jwt-token").Return(uint(10), nil)
+	validator.On("Validate", mock.Anything).Return(uint(0), cerrors.UnauthorizedError("invalid token"))
+
+	protected := http.NewServeMux()
+	handlers.NewVaultHandler(vaultSvc).Register(protected)
+	handlers.NewItemHandler(itemSvc).Register(protected)
+
+	mux := http.NewServeMux()
+	handlers.NewUserHandler(userSvc, authSvc).Register(mux)
+	mux.Handle("/", handlers.Authenticate(validator, protected))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestDeriveAuthKey(t *testing.T) {
+	key, err := DeriveAuthKey("John@Test.com ", "master")
+	assert.Nil(t, err)
+
+	same, _ := DeriveAuthKey("john@test.com", "master")
+	otherEmail, _ := DeriveAuthKey("jane@test.com", "master")
+	otherPassword, _ := DeriveAuthKey("john@test.com", "Master")
+
+	assert.Equal(t, key, same)
+	assert.NotEqual(t, key, otherEmail)
+	assert.NotEqual(t, key, otherPassword)
+	assert.NotContains(t, key, "master")
+}
+
+func TestSignupAndLogin(t *testing.T) {
+	authKey, _ := DeriveAuthKey("john@test.com", "master")
+
+	userSvc := &mocks.UserServiceMock{}
+	authSvc := &mocks.AuthServiceMock{}
+	userSvc.On("Create", mock.Anything, "John", "john@test.com", authKey).Return(uint(1), nil)
+	authSvc.On("Login", mock.Anything, "john@test.com", authKey).Return("jwt-token", nil)
+	authSvc.On("Login", mock.Anything, "john@test.com", mock.Anything).Return("", cerrors.UnauthorizedError("invalid credentials"))
+
+	server := newServer(t, userSvc, authSvc, &mocks.VaultServiceMock{}, &mocks.ItemServiceMock{})
+	c := New(server.URL+"/", "")
+
+	id, err := c.Signup(context.TODO(), "John", "john@test.com", "master")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), id)
+
+	_, err = c.Login(context.TODO(), "john@test.com", "wrong")
+	assert.Equal(t, &Error{StatusCode: http.StatusUnauthorized, Message: "invalid credentials"}, err)
+	assert.Empty(t, c.Token)
+
+	token, err := c.Login(context.TODO(), "john@test.com", "master")
+	assert.Nil(t, err)
+	assert.Equal(t, "jwt-token", token)
+	assert.Equal(t, "jwt-token", c.Token)
+}
+
+func TestVaultsAndItems(t *testing.T) {
+	ctx := context.TODO()
+	userCtx := mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Value(keys.UserIDKey) == uint(10)
+	})
+	input := models.ItemInput{Name: "GitHub", Url: "https://github.com", Username: "octocat", Password: "secret"}
+
+	vaultSvc := &mocks.VaultServiceMock{}
+	vaultSvc.On("GetAll", userCtx).Return([]models.VaultDetail{{ID: 1, Name: "Work", UserID: 10, Revision: 3}}, nil)
+	vaultSvc.On("Create", userCtx, "Work").Return(uint(1), nil)
+	vaultSvc.On("Rename", userCtx, uint(1), "Personal", uint64(3)).Return(nil)
+	vaultSvc.On("Delete", userCtx, uint(2)).Return(cerrors.NotFoundError("vault not found"))
+
+	itemSvc := &mocks.ItemServiceMock{}
+	itemSvc.On("Create", userCtx, uint(1), input).Return(uint(5), nil)
+	itemSvc.On("GetAll", userCtx, uint(1), models.ItemFilter{}).Return([]models.ItemDetail{{ID: 5, VaultID: 1, Name: "GitHub"}}, nil)
+	itemSvc.On("Get", userCtx, uint(5)).Return(&models.ItemDetail{ID: 5, VaultID: 1, Name: "GitHub", Password: "secret", Revision: 7}, nil)
+	itemSvc.On("Update", userCtx, uint(5), models.ItemInput{Name: "GitHub", Password: "renewed", Revision: 7}).Return(nil)
+	itemSvc.On("Update", userCtx, uint(5), models.ItemInput{Name: "GitHub", Password: "secret", Revision: 6}).
+		Return(cerrors.PreconditionFailedError("item was changed at revision 7, since revision 6"))
+	itemSvc.On("Delete", userCtx, uint(5)).Return(nil)
+
+	server := newServer(t, &mocks.UserServiceMock{}, &mocks.AuthServiceMock{}, vaultSvc, itemSvc)
+	c := New(server.URL, "jwt-token")
+
+	vaults, err := c.Vaults(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []Vault{{ID: 1, Name: "Work", Revision: 3}}, vaults)
+
+	vaultID, err := c.CreateVault(ctx, "Work")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), vaultID)
+
+	assert.Nil(t, c.RenameVault(ctx, 1, "Personal", vaults[0].Revision))
+	assert.Equal(t, &Error{StatusCode: http.StatusNotFound, Message: "vault not found"}, c.DeleteVault(ctx, 2))
+
+	itemInput := ItemInput{Name: "GitHub", URL: "https://github.com", Username: "octocat", Password: "secret"}
+	itemID, err := c.CreateItem(ctx, 1, itemInput)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(5), itemID)
+
+	items, err := c.Items(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{{ID: 5, VaultID: 1, Name: "GitHub"}}, items)
+
+	item, err := c.Item(ctx, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", item.Password)
+
+	update := item.Input()
+	update.Password = "renewed"
+	assert.Nil(t, c.UpdateItem(ctx, 5, update))
+
+	stale := ItemInput{Name: "GitHub", Password: "secret", Revision: 6}
+	assert.Equal(t, &Error{StatusCode: http.StatusPreconditionFailed, Message: "item was changed at revision 7, since revision 6"}, c.UpdateItem(ctx, 5, stale))
+	assert.Nil(t, c.DeleteItem(ctx, 5))
+
+	vaultSvc.AssertExpectations(t)
+	itemSvc.AssertExpectations(t)
+}
+
+func TestUnauthenticated(t *testing.T) {
+	server := newServer(t, &mocks.UserServiceMock{}, &mocks.AuthServiceMock{}, &mocks.VaultServiceMock{}, &mocks.ItemServiceMock{})
+
+	_, err := New(server.URL, "").Vaults(context.TODO())
+	assert.Equal(t, &Error{StatusCode: http.StatusUnauthorized, Message: "missing bearer token"}, err)
+
+	_, err = New(server.URL, "expired").Vaults(context.TODO())
+	assert.Equal(t, &Error{StatusCode: http.StatusUnauthorized, Message: "invalid token"}, err)
+}
+
+func TestErrorWithoutMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, "").Vaults(context.TODO())
+	assert.Equal(t, &Error{StatusCode: http.StatusBadGateway, Message: "Bad Gateway"}, err)
+	assert.Equal(t, "Bad Gateway (status 502)", err.Error())
+}
+
+func TestTOTP(t *testing.T) {
+	totpSvc := &mocks.TOTPServiceMock{}
+	totpSvc.On("GetCode", mock.Anything, uint(5)).Return(&models.TOTPCode{Code: "123456", Period: 30, Remaining: 12}, nil)
+	totpSvc.On("GetCode", mock.Anything, uint(6)).Return(nil, cerrors.NotFoundError("item has no totp"))
+
+	mux := http.NewServeMux()
+	handlers.NewTOTPHandler(totpSvc).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(server.URL, "jwt-token")
+
+	code, err := c.TOTP(context.TODO(), 5)
+	assert.Nil(t, err)
+	assert.Equal(t, &TOTPCode{Code: "123456", Period: 30, Remaining: 12}, code)
+
+	_, err = c.TOTP(context.TODO(), 6)
+	assert.Equal(t, &Error{StatusCode: http.StatusNotFound, Message: "item has no totp"}, err)
+}
+
+func TestSearch(t *testing.T) {
+	searchSvc := &mocks.SearchServiceMock{}
+	searchSvc.On("Search", mock.Anything, "git hub", uint(5)).Return([]models.SearchResult{
+		{Kind: "item", ID: 5, VaultID: 1, VaultName: "Work", Name: "GitHub", Url: "https://github.com", Score: 3, Matches: []string{"name"}},
+	}, nil)
+
+	mux := http.NewServeMux()
+	handlers.NewSearchHandler(searchSvc).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	results, err := New(server.URL, "jwt-token").Search(context.TODO(), "git hub", 5)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []SearchResult{
+		{Kind: "item", ID: 5, VaultID: 1, VaultName: "Work", Name: "GitHub", URL: "https://github.com", Score: 3, Matches: []string{"name"}},
+	}, results)
+}
+
+func TestFoldersTagsAndFavorites(t *testing.T) {
+	ctx := context.TODO()
+	parentID := uint(2)
+
+	itemSvc := &mocks.ItemServiceMock{}
+	itemSvc.On("GetAll", mock.Anything, uint(1), models.ItemFilter{FolderID: 2, Tag: "dev ops", Favorite: true}).Return([]models.ItemDetail{
+		{ID: 5, VaultID: 1, Name: "GitHub", FolderID: &parentID, Tags: []string{"dev ops"}, Favorite: true},
+	}, nil)
+	itemSvc.On("SetFavorite", mock.Anything, uint(5), true).Return(nil)
+	itemSvc.On("SetFavorite", mock.Anything, uint(5), false).Return(nil)
+
+	folderSvc := &mocks.FolderServiceMock{}
+	folderSvc.On("GetAll", mock.Anything, uint(1)).Return([]models.FolderDetail{{ID: 3, VaultID: 1, ParentID: &parentID, Name: "CI"}}, nil)
+	folderSvc.On("Create", mock.Anything, uint(1), models.FolderInput{Name: "CI", ParentID: &parentID}).Return(uint(3), nil)
+	folderSvc.On("Update", mock.Anything, uint(3), models.FolderInput{Name: "Builds"}).Return(nil)
+	folderSvc.On("Delete", mock.Anything, uint(2)).Return(cerrors.ConflictError("folder is not empty"))
+
+	tagSvc := &mocks.TagServiceMock{}
+	tagSvc.On("GetAll", mock.Anything).Return([]models.TagDetail{{ID: 7, Name: "dev ops"}}, nil)
+	tagSvc.On("Rename", mock.Anything, uint(7), "devops").Return(nil)
+	tagSvc.On("Delete", mock.Anything, uint(7)).Return(nil)
+
+	mux := http.NewServeMux()
+	handlers.NewItemHandler(itemSvc).Register(mux)
+	handlers.NewFolderHandler(folderSvc).Register(mux)
+	handlers.NewTagHandler(tagSvc).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(server.URL, "jwt-token")
+
+	items, err := c.ListItems(ctx, 1, ItemFilter{FolderID: 2, Tag: "dev ops", Favorite: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{{ID: 5, VaultID: 1, Name: "GitHub", FolderID: &parentID, Tags: []string{"dev ops"}, Favorite: true}}, items)
+	assert.Nil(t, c.SetFavorite(ctx, 5, true))
+	assert.Nil(t, c.SetFavorite(ctx, 5, false))
+
+	folders, err := c.Folders(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []Folder{{ID: 3, VaultID: 1, ParentID: &parentID, Name: "CI"}}, folders)
+
+	folderID, err := c.CreateFolder(ctx, 1, "CI", &parentID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(3), folderID)
+	assert.Nil(t, c.UpdateFolder(ctx, 3, "Builds", nil))
+	assert.Equal(t, &Error{StatusCode: http.StatusConflict, Message: "folder is not empty"}, c.DeleteFolder(ctx, 2))
+
+	tags, err := c.Tags(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []Tag{{ID: 7, Name: "dev ops"}}, tags)
+	assert.Nil(t, c.RenameTag(ctx, 7, "devops"))
+	assert.Nil(t, c.DeleteTag(ctx, 7))
+
+	itemSvc.AssertExpectations(t)
+	folderSvc.AssertExpectations(t)
+	tagSvc.AssertExpectations(t)
+}
+
+func TestAutofill(t *testing.T) {
+	autofillSvc := &mocks.AutofillServiceMock{}
+	autofillSvc.On("Match", mock.Anything, "https://github.com/login?next=/").Return([]models.AutofillMatch{
+		{ItemDetail: models.ItemDetail{ID: 5, VaultID: 1, Name: "GitHub", Url: "github.com", UrlMatch: "host", Password: "secret"}, VaultName: "Work", Match: "host"},
+	}, nil)
+
+	mux := http.NewServeMux()
+	handlers.NewAutofillHandler(autofillSvc).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	matches, err := New(server.URL, "jwt-token").Autofill(context.TODO(), "https://github.com/login?next=/")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []AutofillMatch{
+		{Item: Item{ID: 5, VaultID: 1, Name: "GitHub", URL: "github.com", URLMatch: "host", Password: "secret"}, VaultName: "Work", Match: "host"},
+	}, matches)
+}
+
+func TestSync(t *testing.T) {
+	syncSvc := &mocks.SyncServiceMock{}
+	syncSvc.On("Changes", mock.Anything, uint64(7)).Return(&models.SyncChanges{
+		Revision: 9,
+		Vaults:   models.ChangeSet[models.VaultDetail]{Created: []models.VaultDetail{{ID: 2, Name: "Home", UserID: 10}}, Updated: []models.VaultDetail{}, Deleted: []uint{3}},
+		Items:    models.ChangeSet[models.ItemDetail]{Created: []models.ItemDetail{}, Updated: []models.ItemDetail{{ID: 5, VaultID: 2, Name: "GitHub", Password: "secret"}}, Deleted: []uint{}},
+	}, nil)
+
+	mux := http.NewServeMux()
+	handlers.NewSyncHandler(syncSvc).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	changes, err := New(server.URL, "jwt-token").Sync(context.TODO(), 7)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &SyncChanges{
+		Revision: 9,
+		Vaults:   ChangeSet[Vault]{Created: []Vault{{ID: 2, Name: "Home"}}, Updated: []Vault{}, Deleted: []uint{3}},
+		Items:    ChangeSet[Item]{Created: []Item{}, Updated: []Item{{ID: 5, VaultID: 2, Name: "GitHub", Password: "secret"}}, Deleted: []uint{}},
+	}, changes)
+}
+
+func TestAudit(t *testing.T) {
+	from := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	auditSvc := &mocks.AuditServiceMock{}
+	auditSvc.On("Events", mock.Anything, models.AuditFilter{VaultID: 1, From: from, Limit: 10}).Return([]models.AuditEvent{
+		{Seq: 2, Action: models.AuditItemRead, Outcome: models.AuditSuccess, UserID: 10, VaultID: 1, ItemID: 5, CreatedAt: from, PrevHash: "aa", Hash: "bb"},
+	}, nil)
+	auditSvc.On("Verify", mock.Anything).Return(&models.AuditVerification{Valid: true, Entries: 2, Head: "bb"}, nil)
+
+	mux := http.NewServeMux()
+	handlers.NewAuditHandler(auditSvc).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(server.URL, "jwt-token")
+
+	events, err := c.AuditEvents(context.TODO(), AuditFilter{VaultID: 1, From: from, Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, []AuditEvent{
+		{Seq: 2, Action: "item.read", Outcome: "success", UserID: 10, VaultID: 1, ItemID: 5, CreatedAt: from, PrevHash: "aa", Hash: "bb"},
+	}, events)
+
+	verification, err := c.VerifyAudit(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, &AuditVerification{Valid: true, Entries: 2, Head: "bb"}, verification)
+}
+
+func TestWebhooks(t *testing.T) {
+	vaultID := uint(1)
+	createdAt := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	webhookSvc := &mocks.WebhookServiceMock{}
+	webhookSvc.On("Create", mock.Anything, models.WebhookInput{URL: "https://ci.acme.com/hook", Events: []string{models.EventItemUpdated}, VaultID: &vaultID}).
+		Return(&models.WebhookDetail{ID: 3, URL: "https://ci.acme.com/hook", Events: []string{models.EventItemUpdated}, VaultID: &vaultID, Secret: "whsec_secret", CreatedAt: createdAt}, nil)
+	webhookSvc.On("GetAll", mock.Anything).Return([]models.WebhookDetail{{ID: 3, URL: "https://ci.acme.com/hook", CreatedAt: createdAt}}, nil)
+	webhookSvc.On("Delete", mock.Anything, uint(3)).Return(nil)
+	webhookSvc.On("DeadLetters", mock.Anything).Return([]models.WebhookDeadLetterDetail{
+		{ID: 7, WebhookID: 3, EventID: "e1", EventType: models.EventItemUpdated, Attempts: 5, LastError: "webhook responded with 500 Internal Server Error", FailedAt: createdAt},
+	}, nil)
+	webhookSvc.On("Redeliver", mock.Anything, uint(7)).Return(nil)
+	webhookSvc.On("Discard", mock.Anything, uint(8)).Return(nil)
+
+	mux := http.NewServeMux()
+	handlers.NewWebhookHandler(webhookSvc).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(server.URL, "jwt-token")
+
+	webhook, err := c.CreateWebhook(context.TODO(), "https://ci.acme.com/hook", []string{"item.updated"}, &vaultID)
+	assert.Nil(t, err)
+	assert.Equal(t, &Webhook{ID: 3, URL: "https://ci.acme.com/hook", Events: []string{"item.updated"}, VaultID: &vaultID, Secret: "whsec_secret", CreatedAt: createdAt}, webhook)
+
+	webhooks, err := c.Webhooks(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, []Webhook{{ID: 3, URL: "https://ci.acme.com/hook", CreatedAt: createdAt}}, webhooks)
+
+	deadLetters, err := c.WebhookDeadLetters(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, []WebhookDeadLetter{
+		{ID: 7, WebhookID: 3, EventID: "e1", EventType: "item.updated", Attempts: 5, LastError: "webhook responded with 500 Internal Server Error", FailedAt: createdAt},
+	}, deadLetters)
+
+	assert.Nil(t, c.RedeliverWebhook(context.TODO(), 7))
+	assert.Nil(t, c.DiscardWebhookDeadLetter(context.TODO(), 8))
+	assert.Nil(t, c.DeleteWebhook(context.TODO(), 3))
+
+	webhookSvc.AssertExpectations(t)
+}
+
+func TestSends(t *testing.T) {
+	expiresAt := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+	sendSvc := &mocks.SendServiceMock{}
+	var stored models.SendInput
+	sendSvc.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(models.SendInput)
+	}).Return(&models.SendDetail{ID: 3, AccessID: "abc", MaxViews: 2, HasPassword: true, ExpiresAt: expiresAt}, nil)
+	sendSvc.On("GetAll", mock.Anything).Return([]models.SendDetail{{ID: 3, AccessID: "abc", MaxViews: 2, Views: 1, HasPassword: true, ExpiresAt: expiresAt}}, nil)
+	sendSvc.On("Delete", mock.Anything, uint(3)).Return(nil)
+
+	protected := http.NewServeMux()
+	handler := handlers.NewSendHandler(sendSvc)
+	handler.Register(protected)
+	mux := http.NewServeMux()
+	handler.RegisterPublic(mux)
+	mux.Handle("/", protected)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(server.URL, "jwt-token")
+	item := Item{ID: 5, VaultID: 1, Name: "Wi-Fi", Password: "hunter2", Tags: []string{"home"}, Revision: 7}
+
+	created, err := c.CreateSend(context.TODO(), NewItemSend(item), SendOptions{Password: "correct horse", MaxViews: 2, ExpiresIn: 90 * time.Minute})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(3), created.ID)
+	assert.True(t, strings.HasPrefix(created.Link, server.URL+"/send/abc#"))
+
+	// then: the server gets neither the content, nor the key, nor the password
+	assert.NotContains(t, string(stored.Ciphertext), "hunter2")
+	assert.NotEmpty(t, stored.AccessKey)
+	assert.NotContains(t, stored.AccessKey, "horse")
+	assert.Equal(t, 2, stored.MaxViews)
+	assert.Equal(t, int64(5400), stored.ExpiresIn)
+
+	sendSvc.On("Access", mock.Anything, "abc", stored.AccessKey).Return(&models.SendContent{Ciphertext: stored.Ciphertext, ViewsLeft: 1, ExpiresAt: expiresAt}, nil)
+	sendSvc.On("Access", mock.Anything, "abc", mock.Anything).Return(nil, cerrors.UnauthorizedError("invalid access password"))
+
+	received, err := OpenSend(context.TODO(), created.Link, "correct horse")
+	assert.Nil(t, err)
+	assert.Equal(t, &ReceivedSend{
+		SendContent: SendContent{Type: SendTypeItem, Item: &ItemInput{Name: "Wi-Fi", Password: "hunter2"}},
+		ViewsLeft:   1,
+		ExpiresAt:   expiresAt,
+	}, received)
+
+	_, err = OpenSend(context.TODO(), created.Link, "wrong horse")
+	assert.Equal(t, &Error{StatusCode: http.StatusUnauthorized, Message: "invalid access password"}, err)
+
+	_, err = OpenSend(context.TODO(), server.URL+"/send/abc", "")
+	assert.EqualError(t, err, "invalid send link")
+
+	sends, err := c.Sends(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, []Send{{ID: 3, AccessID: "abc", MaxViews: 2, Views: 1, HasPassword: true, ExpiresAt: expiresAt}}, sends)
+
+	assert.Nil(t, c.DeleteSend(context.TODO(), 3))
+
+	sendSvc.AssertExpectations(t)
+}
+
+func TestEmergencyAccess(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	publicKey, privateKey, err := keywrap.GenerateKey()
+	assert.Nil(t, err)
+
+	emergencySvc := &mocks.EmergencyServiceMock{}
+	var wrappedKey []byte
+	emergencySvc.On("Invite", mock.Anything, models.EmergencyContactInput{Email: "jane@test.com", Access: models.EmergencyTakeover, WaitDays: 2}).
+		Return(&models.EmergencyContactDetail{ID: 3, GrantorID: 10, GranteeID: 20, GranteeEmail: "jane@test.com", Access: models.EmergencyTakeover, WaitDays: 2, Status: models.EmergencyInvited}, nil)
+	emergencySvc.On("Accept", mock.Anything, uint(3), publicKey).Return(nil)
+	emergencySvc.On("Confirm", mock.Anything, uint(3), mock.Anything).Run(func(args mock.Arguments) {
+		wrappedKey = args.Get(2).([]byte)
+	}).Return(nil)
+	emergencySvc.On("Request", mock.Anything, uint(3)).Return(nil)
+	emergencySvc.On("Approve", mock.Anything, uint(3)).Return(nil)
+
+	mux := http.NewServeMux()
+	handlers.NewEmergencyHandler(emergencySvc).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(server.URL, "jwt-token")
+
+	contact, err := c.InviteEmergencyContact(context.TODO(), "jane@test.com", EmergencyTakeover, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, &EmergencyContact{ID: 3, GrantorID: 10, GranteeID: 20, GranteeEmail: "jane@test.com", Access: "takeover", WaitDays: 2, Status: "invited"}, contact)
+
+	assert.Nil(t, c.AcceptEmergencyInvite(context.TODO(), 3, publicKey))
+
+	contact.PublicKey = publicKey
+	assert.Nil(t, c.ConfirmEmergencyContact(context.TODO(), *contact, key))
+	assert.NotContains(t, string(wrappedKey), string(key))
+
+	assert.Nil(t, c.RequestEmergencyAccess(context.TODO(), 3))
+	assert.Nil(t, c.ApproveEmergencyAccess(context.TODO(), 3))
+
+	emergencySvc.On("Access", mock.Anything, uint(3)).Return(&models.EmergencyAccess{
+		GrantorID:  10,
+		Access:     models.EmergencyTakeover,
+		WrappedKey: wrappedKey,
+		Vaults:     &models.SyncChanges{Revision: 4, Vaults: models.ChangeSet[models.VaultDetail]{Created: []models.VaultDetail{{ID: 2, Name: "Home", UserID: 10}}}},
+	}, nil)
+
+	access, err := c.EmergencyAccess(context.TODO(), 3, privateKey)
+	assert.Nil(t, err)
+	assert.Equal(t, &EmergencyAccess{GrantorID: 10, Access: "takeover", Key: key, Vaults: &SyncChanges{Revision: 4, Vaults: ChangeSet[Vault]{Created: []Vault{{ID: 2, Name: "Home"}}}}}, access)
+
+	// then: only the private key of the contact unwraps the key
+	otherPrivateKey, _, _ := keywrap.GenerateKey()
+	_, err = c.EmergencyAccess(context.TODO(), 3, otherPrivateKey)
+	assert.Equal(t, seal.ErrDecrypt, err)
+
+	// then: the private key of the grantor is sealed under the new master password
+	var takeover models.EmergencyTakeoverInput
+	emergencySvc.On("Takeover", mock.Anything, uint(3), mock.Anything).Run(func(args mock.Arguments) {
+		takeover = args.Get(2).(models.EmergencyTakeoverInput)
+	}).Return(nil)
+	assert.Nil(t, c.EmergencyTakeover(context.TODO(), 3, "john@test.com", "new master password", access.Key))
+	authKey, _ := DeriveAuthKey("john@test.com", "new master password")
+	assert.Equal(t, authKey, takeover.AuthKey)
+	grantorPublicKey, _ := keywrap.PublicKey(key)
+	sealed, err := openPrivateKey("john@test.com", "new master password", userKeys{grantorPublicKey, takeover.EncryptedPrivateKey})
+	assert.Nil(t, err)
+	assert.Equal(t, key, sealed)
+
+	emergencySvc.AssertExpectations(t)
+}
+
+func TestRecoveryCeremony(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	var admins []RecoveryAdmin
+	var privateKeys [][]byte
+	for i, email := range []string{"jane@test.com", "bob@test.com", "ann@test.com"} {
+		publicKey, privateKey, err := keywrap.GenerateKey()
+		assert.Nil(t, err)
+		admins = append(admins, RecoveryAdmin{ID: uint(20 + i), Email: email, PublicKey: publicKey})
+		privateKeys = append(privateKeys, privateKey)
+	}
+
+	recoverySvc := &mocks.RecoveryServiceMock{}
+	var input models.RecoveryKeyInput
+	var publicKey []byte
+	var approved [][]byte
+	recoverySvc.On("Setup", mock.Anything, uint(1), mock.Anything).Run(func(args mock.Arguments) {
+		input = args.Get(2).(models.RecoveryKeyInput)
+	}).Return(&models.RecoveryKeyDetail{ID: 2, VaultID: 1, OwnerID: 10, Threshold: 2}, nil)
+	recoverySvc.On("Start", mock.Anything, uint(1), mock.Anything).Run(func(args mock.Arguments) {
+		publicKey = args.Get(2).(models.RecoveryCeremonyInput).PublicKey
+	}).Return(&models.RecoveryCeremonyDetail{ID: 5, VaultID: 1, Status: models.RecoveryPending, Threshold: 2}, nil)
+	recoverySvc.On("Approve", mock.Anything, uint(5), mock.Anything).Run(func(args mock.Arguments) {
+		approved = append(approved, args.Get(2).([]byte))
+	}).Return(&models.RecoveryCeremonyDetail{ID: 5, VaultID: 1, Status: models.RecoveryPending, Threshold: 2}, nil)
+
+	mux := http.NewServeMux()
+	handlers.NewRecoveryHandler(recoverySvc).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(server.URL, "jwt-token")
+
+	recoveryKey, err := c.SetupRecoveryKey(context.TODO(), 1, key, 2, admins)
+	assert.Nil(t, err)
+	assert.Equal(t, &RecoveryKey{ID: 2, VaultID: 1, OwnerID: 10, Threshold: 2}, recoveryKey)
+
+	// then: each admin gets a share the server can't read
+	assert.Equal(t, 2, input.Threshold)
+	assert.Len(t, input.Shares, 3)
+	for i, share := range input.Shares {
+		assert.Equal(t, admins[i].Email, share.Email)
+		assert.NotContains(t, string(share.EncryptedShare), string(key))
+	}
+
+	ceremony, requesterKey, err := c.StartRecoveryCeremony(context.TODO(), 1, "Owner left")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(5), ceremony.ID)
+	ceremony.PublicKey = publicKey
+
+	// when: the first and the last admins approve
+	for _, i := range []int{0, 2} {
+		recoverySvc.On("Share", mock.Anything, uint(1)).Return(input.Shares[i].EncryptedShare, nil).Once()
+		_, err := c.ApproveRecoveryCeremony(context.TODO(), *ceremony, privateKeys[i])
+		assert.Nil(t, err)
+	}
+
+	recoverySvc.On("Complete", mock.Anything, uint(5)).Return(&models.RecoveryShares{CeremonyID: 5, VaultID: 1, Shares: approved}, nil)
+
+	// then: their shares rebuild the key
+	actual, err := c.CompleteRecoveryCeremony(context.TODO(), 5, requesterKey)
+	assert.Nil(t, err)
+	assert.Equal(t, key, actual)
+
+	// then: an admin can't approve with the share of another
+	recoverySvc.On("Share", mock.Anything, uint(1)).Return(input.Shares[1].EncryptedShare, nil).Once()
+	_, err = c.ApproveRecoveryCeremony(context.TODO(), *ceremony, privateKeys[0])
+	assert.Equal(t, seal.ErrDecrypt, err)
+
+	_, err = c.SetupRecoveryKey(context.TODO(), 1, key, 4, admins)
+	assert.EqualError(t, err, "shamir: threshold must be between 2 and the number of shares")
+}
+
+func TestKeys(t *testing.T) {
+	keySvc := &mocks.KeyServiceMock{}
+	var stored models.UserKeys
+	keySvc.On("SetKeys", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(models.UserKeys)
+	}).Return(nil)
+
+	mux := http.NewServeMux()
+	handlers.NewKeyHandler(keySvc).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(server.URL, "jwt-token")
+
+	publicKey, err := c.GenerateKeys(context.TODO(), "john@test.com", "master password")
+	assert.Nil(t, err)
+	assert.Equal(t, stored.PublicKey, publicKey.PublicKey)
+	assert.Equal(t, keywrap.Fingerprint(stored.PublicKey), publicKey.Fingerprint)
+
+	keySvc.On("Keys", mock.Anything).Return(&stored, nil)
+
+	// then: only the master password decrypts the private key, which pairs with the public key
+	privateKey, err := c.PrivateKey(context.TODO(), "john@test.com", "master password")
+	assert.Nil(t, err)
+	wrapped, _ := keywrap.Wrap(publicKey.PublicKey, []byte("key"))
+	unwrapped, err := keywrap.Unwrap(privateKey, wrapped)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("key"), unwrapped)
+
+	_, err = c.PrivateKey(context.TODO(), "john@test.com", "wrong password")
+	assert.Equal(t, seal.ErrDecrypt, err)
+
+	janeKey, _, _ := keywrap.GenerateKey()
+	fingerprint := keywrap.Fingerprint(janeKey)
+	keySvc.On("Lookup", mock.Anything, "jane@test.com").Return(&models.PublicKeyDetail{UserID: 20, Email: "jane@test.com", PublicKey: janeKey, Fingerprint: "forged"}, nil)
+	keySvc.On("Verify", mock.Anything, models.KeyVerificationInput{Email: "jane@test.com", Fingerprint: fingerprint}).
+		Return(&models.PublicKeyDetail{UserID: 20, Email: "jane@test.com", PublicKey: janeKey, Fingerprint: fingerprint, Verified: true}, nil)
+	keySvc.On("Verify", mock.Anything, models.KeyVerificationInput{Email: "jane@test.com", Fingerprint: "0000"}).
+		Return(&models.PublicKeyDetail{UserID: 20, Email: "jane@test.com", PublicKey: janeKey, Fingerprint: "0000", Verified: true}, nil)
+
+	// then: the fingerprint is computed from the key
+	actual, err := c.LookupPublicKey(context.TODO(), "jane@test.com")
+	assert.Nil(t, err)
+	assert.Equal(t, &PublicKey{UserID: 20, Email: "jane@test.com", PublicKey: janeKey, Fingerprint: fingerprint}, actual)
+
+	actual, err = c.VerifyPublicKey(context.TODO(), "jane@test.com", fingerprint)
+	assert.Nil(t, err)
+	assert.True(t, actual.Verified)
+
+	// then: a server claiming another key matches is not trusted
+	_, err = c.VerifyPublicKey(context.TODO(), "jane@test.com", "0000")
+	assert.EqualError(t, err, "fingerprint doesn't match the key of jane@test.com")
+
+	keySvc.AssertExpectations(t)
+}
+
+func TestChangePasswordAndRotateKeys(t *testing.T) {
+	userSvc := &mocks.UserServiceMock{}
+	authSvc := &mocks.AuthServiceMock{}
+	keySvc := &mocks.KeyServiceMock{}
+	var stored models.UserKeys
+	keySvc.On("SetKeys", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(models.UserKeys)
+	}).Return(nil)
+	keySvc.On("Keys", mock.Anything).Return(&stored, nil)
+	userSvc.On("ChangePassword", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored.EncryptedPrivateKey = args.Get(1).(models.PasswordChange).EncryptedPrivateKey
+	}).Return(nil)
+	authSvc.On("Login", mock.Anything, "john@test.com", mock.Anything).Return("new-token", nil)
+
+	mux := http.NewServeMux()
+	handlers.NewUserHandler(userSvc, authSvc).Register(mux)
+	handlers.NewUserHandler(userSvc, authSvc).RegisterAccount(mux)
+	handlers.NewKeyHandler(keySvc).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(server.URL, "jwt-token")
+
+	publicKey, err := c.GenerateKeys(context.TODO(), "john@test.com", "old password")
+	assert.Nil(t, err)
+	privateKey, err := c.PrivateKey(context.TODO(), "john@test.com", "old password")
+	assert.Nil(t, err)
+
+	// when
+	token, err := c.ChangePassword(context.TODO(), "john@test.com", "old password", "new password")
+
+	// then: the client logged in again, and the private key opens with the new password only
+	assert.Nil(t, err)
+	assert.Equal(t, "new-token", token)
+	assert.Equal(t, "new-token", c.Token)
+
+	currentAuthKey, _ := DeriveAuthKey("john@test.com", "old password")
+	newAuthKey, _ := DeriveAuthKey("john@test.com", "new password")
+	userSvc.AssertCalled(t, "ChangePassword", mock.Anything, models.PasswordChange{CurrentAuthKey: currentAuthKey, NewAuthKey: newAuthKey, EncryptedPrivateKey: stored.EncryptedPrivateKey})
+
+	actual, err := c.PrivateKey(context.TODO(), "john@test.com", "new password")
+	assert.Nil(t, err)
+	assert.Equal(t, privateKey, actual)
+
+	_, err = c.PrivateKey(context.TODO(), "john@test.com", "old password")
+	assert.Equal(t, seal.ErrDecrypt, err)
+
+	// given: a recovery share wrapped to the public key
+	share, _ := keywrap.Wrap(publicKey.PublicKey, []byte("share"))
+	var rotation models.KeyRotation
+	keySvc.On("WrappedKeys", mock.Anything).Return([]models.WrappedKey{{Kind: models.WrappedRecoveryShare, ID: 7, WrappedKey: share}}, nil)
+	keySvc.On("Rotate", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		rotation = args.Get(1).(models.KeyRotation)
+	}).Return(nil)
+
+	// when
+	rotated, err := c.RotateKeys(context.TODO(), "john@test.com", "new password")
+
+	// then: the share is wrapped to the new key pair
+	assert.Nil(t, err)
+	assert.Equal(t, rotation.PublicKey, rotated.PublicKey)
+	assert.NotEqual(t, publicKey.Fingerprint, rotated.Fingerprint)
+
+	newPrivateKey, err := openPrivateKey("john@test.com", "new password", userKeys{rotation.PublicKey, rotation.EncryptedPrivateKey})
+	assert.Nil(t, err)
+	assert.Len(t, rotation.WrappedKeys, 1)
+	assert.Equal(t, models.WrappedRecoveryShare, rotation.WrappedKeys[0].Kind)
+	assert.Equal(t, uint(7), rotation.WrappedKeys[0].ID)
+	unwrapped, err := keywrap.Unwrap(newPrivateKey, rotation.WrappedKeys[0].WrappedKey)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("share"), unwrapped)
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/keywrap"
+)
+
+const (
+	// EmergencyView lets an emergency contact read the vaults of the grantor.
+	EmergencyView = "view"
+	// EmergencyTakeover also lets an emergency contact set a new master
+	// password for the grantor.
+	EmergencyTakeover = "takeover"
+)
+
+// EmergencyContact is a user, the grantee, whom another user, the grantor,
+// trusts to access their account. Status goes from "invited" through
+// "accepted", "confirmed" and "requested" to "granted", and is "closed" once
+// the account of the grantor is taken over. GrantsAt is when a
+// requested access is granted unless the grantor rejects it first.
+type EmergencyContact struct {
+	ID           uint       `json:"id"`
+	GrantorID    uint       `json:"grantorId"`
+	GrantorEmail string     `json:"grantorEmail"`
+	GranteeID    uint       `json:"granteeId"`
+	GranteeEmail string     `json:"granteeEmail"`
+	Access       string     `json:"access"`
+	WaitDays     int        `json:"waitDays"`
+	Status       string     `json:"status"`
+	PublicKey    []byte     `json:"publicKey,omitempty"`
+	RequestedAt  *time.Time `json:"requestedAt,omitempty"`
+	GrantsAt     *time.Time `json:"grantsAt,omitempty"`
+}
+
+// EmergencyAccess is the account of a grantor as seen by their emergency
+// contact once access is granted: the key of the grantor, unwrapped by
+// EmergencyAccess, and their vaults and items.
+type EmergencyAccess struct {
+	GrantorID uint         `json:"grantorId"`
+	Access    string       `json:"access"`
+	Key       []byte       `json:"-"`
+	Vaults    *SyncChanges `json:"vaults"`
+}
+
+// EmergencyContacts returns the emergency contacts of the user.
+func (c *Client) EmergencyContacts(ctx context.Context) ([]EmergencyContact, error) {
+	var contacts []EmergencyContact
+	err := c.do(ctx, http.MethodGet, "/emergency/contacts", nil, &contacts)
+
+	return contacts, err
+}
+
+// EmergencyGrantors returns the emergency contacts the user is, to other users.
+func (c *Client) EmergencyGrantors(ctx context.Context) ([]EmergencyContact, error) {
+	var grantors []EmergencyContact
+	err := c.do(ctx, http.MethodGet, "/emergency/grantors", nil, &grantors)
+
+	return grantors, err
+}
+
+// InviteEmergencyContact invites the user with email as an emergency contact
+// with access, EmergencyView or EmergencyTakeover, granted waitDays after they
+// request it. A zero waitDays picks the default wait of the server.
+func (c *Client) InviteEmergencyContact(ctx context.Context, email, access string, waitDays int) (*EmergencyContact, error) {
+	body := struct {
+		Email    string `json:"email"`
+		Access   string `json:"access"`
+		WaitDays int    `json:"waitDays,omitempty"`
+	}{email, access, waitDays}
+
+	var contact EmergencyContact
+
+	if err := c.do(ctx, http.MethodPost, "/emergency/contacts", body, &contact); err != nil {
+		return nil, err
+	}
+
+	return &contact, nil
+}
+
+// AcceptEmergencyInvite accepts to be the emergency contact of a grantor.
+// The key of the grantor is wrapped to publicKey, an X25519 public key whose
+// private key the user needs to keep to use EmergencyAccess.
+func (c *Client) AcceptEmergencyInvite(ctx context.Context, id uint, publicKey []byte) error {
+	body := struct {
+		PublicKey []byte `json:"publicKey"`
+	}{publicKey}
+
+	return c.do(ctx, http.MethodPost, "/emergency/grantors/"+idString(id)+"/accept", body, nil)
+}
+
+// ConfirmEmergencyContact confirms an emergency contact who accepted the
+// invitation, handing them key wrapped to their public key. The server only
+// releases it to the contact once access is granted, and can't unwrap it.
+// Contacts with takeover access need the private key of the user as key, to
+// seal it under the new master password of the account.
+func (c *Client) ConfirmEmergencyContact(ctx context.Context, contact EmergencyContact, key []byte) error {
+	wrappedKey, err := keywrap.Wrap(contact.PublicKey, key)
+
+	if err != nil {
+		return err
+	}
+
+	body := struct {
+		WrappedKey []byte `json:"wrappedKey"`
+	}{wrappedKey}
+
+	return c.do(ctx, http.MethodPost, "/emergency/contacts/"+idString(contact.ID)+"/confirm", body, nil)
+}
+
+// RequestEmergencyAccess requests access to the account of a grantor, which
+// is granted after the wait of the emergency contact unless the grantor rejects it.
+func (c *Client) RequestEmergencyAccess(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodPost, "/emergency/grantors/"+idString(id)+"/request", nil, nil)
+}
+
+// ApproveEmergencyAccess grants a requested access without waiting.
+func (c *Client) ApproveEmergencyAccess(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodPost, "/emergency/contacts/"+idString(id)+"/approve", nil, nil)
+}
+
+// RejectEmergencyAccess rejects a requested access, or revokes a granted one.
+func (c *Client) RejectEmergencyAccess(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodPost, "/emergency/contacts/"+idString(id)+"/reject", nil, nil)
+}
+
+// DeleteEmergencyContact deletes an emergency contact. Both the grantor and the contact can delete it.
+func (c *Client) DeleteEmergencyContact(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, "/emergency/contacts/"+idString(id), nil, nil)
+}
+
+// EmergencyAccess returns the account of a grantor once access is granted,
+// unwrapping the key of the grantor with privateKey, the private key of the
+// public key given to AcceptEmergencyInvite.
+func (c *Client) EmergencyAccess(ctx context.Context, id uint, privateKey []byte) (*EmergencyAccess, error) {
+	var res struct {
+		EmergencyAccess
+		WrappedKey []byte `json:"wrappedKey"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/emergency/grantors/"+idString(id)+"/access", nil, &res); err != nil {
+		return nil, err
+	}
+
+	key, err := keywrap.Unwrap(privateKey, res.WrappedKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	access := res.EmergencyAccess
+	access.Key = key
+
+	return &access, nil
+}
+
+// EmergencyTakeover sets masterPassword as the new master password of a
+// grantor, once takeover access is granted. privateKey is the private key of
+// the grantor, the key unwrapped by EmergencyAccess, which is sealed under
+// the new master password; it is nil when the grantor has no keys. The
+// account of the grantor is then logged in to with grantorEmail and
+// masterPassword, and the emergency contact can't be used again.
+func (c *Client) EmergencyTakeover(ctx context.Context, id uint, grantorEmail, masterPassword string, privateKey []byte) error {
+	authKey, err := DeriveAuthKey(grantorEmail, masterPassword)
+
+	if err != nil {
+		return err
+	}
+
+	body := struct {
+		AuthKey             string `json:"authKey"`
+		EncryptedPrivateKey []byte `json:"encryptedPrivateKey,omitempty"`
+	}{AuthKey: authKey}
+
+	if privateKey != nil {
+		publicKey, err := keywrap.PublicKey(privateKey)
+
+		if err != nil {
+			return err
+		}
+
+		if body.EncryptedPrivateKey, err = sealPrivateKey(grantorEmail, masterPassword, publicKey, privateKey); err != nil {
+			return err
+		}
+	}
+
+	return c.do(ctx, http.MethodPost, "/emergency/grantors/"+idString(id)+"/takeover", body, nil)
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edgardjr92/gopass/pkg/secretref"
+)
+
+// FindItem finds an item by vault and item name, making the client a
+// secretref.ItemFinder:
+//
+//	resolver := secretref.NewResolver(c)
+//	password, err := resolver.Resolve(ctx, ref)
+func (c *Client) FindItem(ctx context.Context, vaultName, itemName string) (secretref.Item, error) {
+	vaults, err := c.Vaults(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vault, err := secretref.Match(vaults, vaultName, func(v Vault) string { return v.Name })
+
+	if err != nil {
+		return nil, fmt.Errorf("vault %q: %w", vaultName, err)
+	}
+
+	items, err := c.Items(ctx, vault.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := secretref.Match(items, itemName, func(i Item) string { return i.Name })
+
+	if err != nil {
+		return nil, fmt.Errorf("item %q in vault %q: %w", itemName, vaultName, err)
+	}
+
+	fields := secretref.Item{}
+
+	for _, name := range secretref.Fields {
+		fields[name], _ = item.Field(name)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/handlers"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/edgardjr92/gopass/pkg/secretref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFindItem(t *testing.T) {
+	validator := &mocks.JWTValidatorMock{}
+	validator.On("Validate", "jwt-token").Return(uint(10), nil)
+
+	vaultSvc := &mocks.VaultServiceMock{}
+	vaultSvc.On("GetAll", mock.Anything).Return([]models.VaultDetail{{ID: 1, Name: "Work"}, {ID: 2, Name: "work"}, {ID: 3, Name: "Personal"}}, nil)
+
+	itemSvc := &mocks.ItemServiceMock{}
+	itemSvc.On("GetAll", mock.Anything, uint(1), models.ItemFilter{}).Return([]models.ItemDetail{
+		{ID: 5, VaultID: 1, Name: "GitHub", Username: "octocat", Password: "gh-secret"},
+		{ID: 6, VaultID: 1, Name: "db", Password: "one"},
+		{ID: 7, VaultID: 1, Name: "DB", Password: "two"},
+		{ID: 8, VaultID: 1, Name: "dup", Password: "one"},
+		{ID: 9, VaultID: 1, Name: "dup", Password: "two"},
+	}, nil)
+	itemSvc.On("GetAll", mock.Anything, uint(3), models.ItemFilter{}).Return([]models.ItemDetail{}, nil)
+	itemSvc.On("GetAll", mock.Anything, mock.Anything).Return([]models.ItemDetail{}, cerrors.NotFoundError("vault not found"))
+
+	mux := http.NewServeMux()
+	handlers.NewVaultHandler(vaultSvc).Register(mux)
+	handlers.NewItemHandler(itemSvc).Register(mux)
+	server := httptest.NewServer(handlers.Authenticate(validator, mux))
+	defer server.Close()
+
+	resolver := secretref.NewResolver(New(server.URL, "jwt-token"))
+
+	testCases := []struct {
+		ref      string
+		expected string
+		err      string
+	}{
+		{"gopass://Work/GitHub/password", "gh-secret", ""},
+		{"gopass://Work/github/username", "octocat", ""},
+		{"gopass://Work/db/password", "one", ""},
+		{"gopass://Work/dup/password", "", `item "dup" in vault "Work": secretref: more than one match`},
+		{"gopass://PERSONAL/GitHub/password", "", `item "GitHub" in vault "PERSONAL": secretref: not found`},
+		{"gopass://Missing/GitHub/password", "", `vault "Missing": secretref: not found`},
+		{"gopass://WORK/GitHub/password", "", `vault "WORK": secretref: more than one match`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.ref, func(t *testing.T) {
+			ref, err := secretref.Parse(tc.ref)
+			assert.Nil(t, err)
+
+			// when
+			value, err := resolver.Resolve(context.TODO(), ref)
+
+			// then
+			assert.Equal(t, tc.expected, value)
+			if tc.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/edgardjr92/gopass/pkg/keywrap"
+	"github.com/edgardjr92/gopass/pkg/seal"
+)
+
+// PublicKey is the public key of a user. Verified tells whether the user of
+// the client compared its fingerprint out-of-band with VerifyPublicKey.
+type PublicKey struct {
+	UserID      uint   `json:"userId"`
+	Email       string `json:"email"`
+	PublicKey   []byte `json:"publicKey"`
+	Fingerprint string `json:"fingerprint"`
+	Verified    bool   `json:"verified"`
+}
+
+type userKeys struct {
+	PublicKey           []byte `json:"publicKey"`
+	EncryptedPrivateKey []byte `json:"encryptedPrivateKey"`
+}
+
+// wrappedKey is a key wrapped to the public key of the user.
+type wrappedKey struct {
+	Kind       string `json:"kind"`
+	ID         uint   `json:"id"`
+	WrappedKey []byte `json:"wrappedKey"`
+}
+
+// GenerateKeys generates the key pair of the user and stores it on the server,
+// the private key encrypted under a key derived from the master password.
+// It returns the public key, whose fingerprint the user shares with others.
+func (c *Client) GenerateKeys(ctx context.Context, email, masterPassword string) (*PublicKey, error) {
+	publicKey, privateKey, err := keywrap.GenerateKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedPrivateKey, err := sealPrivateKey(email, masterPassword, publicKey, privateKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body := userKeys{PublicKey: publicKey, EncryptedPrivateKey: encryptedPrivateKey}
+
+	if err := c.do(ctx, http.MethodPut, "/keys", body, nil); err != nil {
+		return nil, err
+	}
+
+	return &PublicKey{Email: email, PublicKey: publicKey, Fingerprint: keywrap.Fingerprint(publicKey)}, nil
+}
+
+// PrivateKey returns the private key of the user, decrypted with the master password.
+func (c *Client) PrivateKey(ctx context.Context, email, masterPassword string) ([]byte, error) {
+	var res userKeys
+
+	if err := c.do(ctx, http.MethodGet, "/keys", nil, &res); err != nil {
+		return nil, err
+	}
+
+	return openPrivateKey(email, masterPassword, res)
+}
+
+// RotateKeys replaces the key pair of the user with a new one. Every key
+// wrapped to the old public key, as emergency access keys and recovery
+// shares, is unwrapped and wrapped again to the new one, and the server swaps
+// them all at once. It returns the new public key, whose fingerprint the
+// contacts of the user need to verify again.
+func (c *Client) RotateKeys(ctx context.Context, email, masterPassword string) (*PublicKey, error) {
+	oldPrivateKey, err := c.PrivateKey(ctx, email, masterPassword)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var wrappedKeys []wrappedKey
+
+	if err := c.do(ctx, http.MethodGet, "/keys/wrapped", nil, &wrappedKeys); err != nil {
+		return nil, err
+	}
+
+	publicKey, privateKey, err := keywrap.GenerateKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range wrappedKeys {
+		key, err := keywrap.Unwrap(oldPrivateKey, wrappedKeys[i].WrappedKey)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if wrappedKeys[i].WrappedKey, err = keywrap.Wrap(publicKey, key); err != nil {
+			return nil, err
+		}
+	}
+
+	encryptedPrivateKey, err := sealPrivateKey(email, masterPassword, publicKey, privateKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body := struct {
+		userKeys
+		WrappedKeys []wrappedKey `json:"wrappedKeys"`
+	}{userKeys{publicKey, encryptedPrivateKey}, wrappedKeys}
+
+	if err := c.do(ctx, http.MethodPost, "/keys/rotate", body, nil); err != nil {
+		return nil, err
+	}
+
+	return &PublicKey{Email: email, PublicKey: publicKey, Fingerprint: keywrap.Fingerprint(publicKey)}, nil
+}
+
+// LookupPublicKey returns the public key of the user with email. The
+// fingerprint is computed from the key, not taken from the server.
+func (c *Client) LookupPublicKey(ctx context.Context, email string) (*PublicKey, error) {
+	var publicKey PublicKey
+
+	if err := c.do(ctx, http.MethodGet, "/keys/lookup?"+url.Values{"email": {email}}.Encode(), nil, &publicKey); err != nil {
+		return nil, err
+	}
+
+	return checkPublicKey(&publicKey)
+}
+
+// VerifyPublicKey marks the public key of the user with email as verified once
+// fingerprint, compared out-of-band with them, matches it. The match is also
+// checked against the key the server returns.
+func (c *Client) VerifyPublicKey(ctx context.Context, email, fingerprint string) (*PublicKey, error) {
+	body := struct {
+		Email       string `json:"email"`
+		Fingerprint string `json:"fingerprint"`
+	}{email, fingerprint}
+
+	var publicKey PublicKey
+
+	if err := c.do(ctx, http.MethodPost, "/keys/verify", body, &publicKey); err != nil {
+		return nil, err
+	}
+
+	if _, err := checkPublicKey(&publicKey); err != nil {
+		return nil, err
+	}
+
+	if normalizeFingerprint(fingerprint) != normalizeFingerprint(publicKey.Fingerprint) {
+		return nil, errors.New("fingerprint doesn't match the key of " + email)
+	}
+
+	return &publicKey, nil
+}
+
+// sealPrivateKey seals privateKey under a key derived from the master password.
+// The public key is authenticated along, so the pair can't be mixed up.
+func sealPrivateKey(email, masterPassword string, publicKey, privateKey []byte) ([]byte, error) {
+	key, err := deriveKey(email, masterPassword, "gopass private key")
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, ciphertext, err := seal.Seal(key, privateKey, publicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(nonce, ciphertext...), nil
+}
+
+// openPrivateKey opens the private key of keys sealed by sealPrivateKey.
+func openPrivateKey(email, masterPassword string, keys userKeys) ([]byte, error) {
+	if len(keys.EncryptedPrivateKey) < seal.NonceSize {
+		return nil, seal.ErrDecrypt
+	}
+
+	key, err := deriveKey(email, masterPassword, "gopass private key")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return seal.Open(key, keys.EncryptedPrivateKey[:seal.NonceSize], keys.EncryptedPrivateKey[seal.NonceSize:], keys.PublicKey)
+}
+
+func checkPublicKey(publicKey *PublicKey) (*PublicKey, error) {
+	if len(publicKey.PublicKey) != keywrap.KeySize {
+		return nil, errors.New("invalid public key")
+	}
+
+	publicKey.Fingerprint = keywrap.Fingerprint(publicKey.PublicKey)
+
+	return publicKey, nil
+}
+
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.Join(strings.Fields(fingerprint), ""))
+}
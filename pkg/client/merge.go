@@ -0,0 +1,104 @@
+package client
+
+import "sort"
+
+// MergeItemInput merges the changes made to an item by two writers since base:
+// ours, the changes that failed to be saved, and theirs, the item as saved in
+// the meantime. A field changed by only one side keeps that change; tags are
+// merged as sets, keeping the tags added and dropping the ones removed by
+// either side. Conflicts lists, by their JSON name, the fields both sides
+// changed to different values, which are left as in ours.
+//
+// The merged input is based on the revision of theirs, so it can be saved
+// with UpdateItem when there are no conflicts.
+func MergeItemInput(base, ours, theirs ItemInput) (merged ItemInput, conflicts []string) {
+	merged = ours
+	merged.Revision = theirs.Revision
+
+	fields := []struct {
+		name               string
+		base, ours, theirs string
+		merged             *string
+	}{
+		{"name", base.Name, ours.Name, theirs.Name, &merged.Name},
+		{"url", base.URL, ours.URL, theirs.URL, &merged.URL},
+		{"urlMatch", base.URLMatch, ours.URLMatch, theirs.URLMatch, &merged.URLMatch},
+		{"username", base.Username, ours.Username, theirs.Username, &merged.Username},
+		{"password", base.Password, ours.Password, theirs.Password, &merged.Password},
+		{"notes", base.Notes, ours.Notes, theirs.Notes, &merged.Notes},
+		{"sshKey", base.SSHKey, ours.SSHKey, theirs.SSHKey, &merged.SSHKey},
+		{"totp", base.TOTP, ours.TOTP, theirs.TOTP, &merged.TOTP},
+	}
+
+	for _, f := range fields {
+		switch {
+		case f.ours == f.base:
+			*f.merged = f.theirs
+		case f.theirs != f.base && f.theirs != f.ours:
+			conflicts = append(conflicts, f.name)
+		}
+	}
+
+	switch {
+	case sameFolder(ours.FolderID, base.FolderID):
+		merged.FolderID = theirs.FolderID
+	case !sameFolder(theirs.FolderID, base.FolderID) && !sameFolder(theirs.FolderID, ours.FolderID):
+		conflicts = append(conflicts, "folderId")
+	}
+
+	merged.Tags = mergeTags(base.Tags, ours.Tags, theirs.Tags)
+
+	return merged, conflicts
+}
+
+func sameFolder(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// mergeTags returns the tags of theirs without the ones ours removed from
+// base, plus the ones ours added, sorted.
+func mergeTags(base, ours, theirs []string) []string {
+	inBase := make(map[string]bool, len(base))
+
+	for _, t := range base {
+		inBase[t] = true
+	}
+
+	inOurs := make(map[string]bool, len(ours))
+
+	for _, t := range ours {
+		inOurs[t] = true
+	}
+
+	tags := make(map[string]bool, len(theirs)+len(ours))
+
+	for _, t := range theirs {
+		if !inBase[t] || inOurs[t] {
+			tags[t] = true
+		}
+	}
+
+	for _, t := range ours {
+		if !inBase[t] {
+			tags[t] = true
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	merged := make([]string, 0, len(tags))
+
+	for t := range tags {
+		merged = append(merged, t)
+	}
+
+	sort.Strings(merged)
+
+	return merged
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeItemInput(t *testing.T) {
+	folder, otherFolder := uint(3), uint(4)
+	base := ItemInput{Name: "GitHub", Username: "octocat", Password: "secret", Tags: []string{"dev", "work"}, Revision: 7}
+
+	testCases := []struct {
+		name      string
+		ours      func(ItemInput) ItemInput
+		theirs    func(ItemInput) ItemInput
+		expected  func(ItemInput) ItemInput
+		conflicts []string
+	}{
+		{
+			name:     "different fields",
+			ours:     func(i ItemInput) ItemInput { i.Username = "hubot"; return i },
+			theirs:   func(i ItemInput) ItemInput { i.Notes = "recovery codes"; return i },
+			expected: func(i ItemInput) ItemInput { i.Username, i.Notes = "hubot", "recovery codes"; return i },
+		},
+		{
+			name:     "same change",
+			ours:     func(i ItemInput) ItemInput { i.Password = "rotated"; return i },
+			theirs:   func(i ItemInput) ItemInput { i.Password = "rotated"; return i },
+			expected: func(i ItemInput) ItemInput { i.Password = "rotated"; return i },
+		},
+		{
+			name:      "conflicting changes keep ours",
+			ours:      func(i ItemInput) ItemInput { i.Password = "mine"; i.FolderID = &folder; return i },
+			theirs:    func(i ItemInput) ItemInput { i.Password = "theirs"; i.FolderID = &otherFolder; return i },
+			expected:  func(i ItemInput) ItemInput { i.Password = "mine"; i.FolderID = &folder; return i },
+			conflicts: []string{"password", "folderId"},
+		},
+		{
+			name:     "folder moved by them",
+			ours:     func(i ItemInput) ItemInput { return i },
+			theirs:   func(i ItemInput) ItemInput { i.FolderID = &folder; return i },
+			expected: func(i ItemInput) ItemInput { i.FolderID = &folder; return i },
+		},
+		{
+			name:     "tags merged as sets",
+			ours:     func(i ItemInput) ItemInput { i.Tags = []string{"work", "2fa"}; return i },
+			theirs:   func(i ItemInput) ItemInput { i.Tags = []string{"dev", "work", "shared"}; return i },
+			expected: func(i ItemInput) ItemInput { i.Tags = []string{"2fa", "shared", "work"}; return i },
+		},
+		{
+			name:     "all tags removed",
+			ours:     func(i ItemInput) ItemInput { i.Tags = nil; return i },
+			theirs:   func(i ItemInput) ItemInput { return i },
+			expected: func(i ItemInput) ItemInput { i.Tags = nil; return i },
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			theirs := tc.theirs(base)
+			theirs.Revision = 8
+
+			// when
+			actual, conflicts := MergeItemInput(base, tc.ours(base), theirs)
+
+			// then
+			expected := tc.expected(base)
+			expected.Revision = 8
+			assert.Equal(t, expected, actual)
+			assert.Equal(t, tc.conflicts, conflicts)
+		})
+	}
+}
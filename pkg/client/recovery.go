@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/keywrap"
+	"github.com/edgardjr92/gopass/pkg/shamir"
+)
+
+// RecoveryAdmin is an admin holding a share of a recovery key.
+type RecoveryAdmin struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+	// PublicKey is the X25519 public key the share of the admin is encrypted
+	// to by SetupRecoveryKey. The server doesn't return it.
+	PublicKey []byte `json:"-"`
+}
+
+// RecoveryKey is the recovery key of a vault, split into one share per admin,
+// any Threshold of which rebuild it.
+type RecoveryKey struct {
+	ID        uint            `json:"id"`
+	VaultID   uint            `json:"vaultId"`
+	OwnerID   uint            `json:"ownerId"`
+	Threshold int             `json:"threshold"`
+	Admins    []RecoveryAdmin `json:"admins"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// RecoveryApproval is the approval of a recovery ceremony by an admin.
+type RecoveryApproval struct {
+	AdminID    uint      `json:"adminId"`
+	AdminEmail string    `json:"adminEmail"`
+	ApprovedAt time.Time `json:"approvedAt"`
+}
+
+// RecoveryCeremony is an admin rebuilding a recovery key with the shares of
+// the other admins. Status is "pending" until Threshold admins approved it,
+// then "approved", and "completed" or "cancelled" once over.
+type RecoveryCeremony struct {
+	ID             uint               `json:"id"`
+	VaultID        uint               `json:"vaultId"`
+	RequesterID    uint               `json:"requesterId"`
+	RequesterEmail string             `json:"requesterEmail"`
+	Reason         string             `json:"reason"`
+	PublicKey      []byte             `json:"publicKey"`
+	Status         string             `json:"status"`
+	Threshold      int                `json:"threshold"`
+	Approvals      []RecoveryApproval `json:"approvals"`
+	CreatedAt      time.Time          `json:"createdAt"`
+}
+
+// SetupRecoveryKey splits key into a share per admin, any threshold of which
+// rebuild it, and sets it as the recovery key of a vault of the user. Each
+// share is encrypted to the public key of its admin, so neither the server
+// nor fewer than threshold admins can rebuild the key.
+func (c *Client) SetupRecoveryKey(ctx context.Context, vaultID uint, key []byte, threshold int, admins []RecoveryAdmin) (*RecoveryKey, error) {
+	shares, err := shamir.Split(key, len(admins), threshold)
+
+	if err != nil {
+		return nil, err
+	}
+
+	type shareInput struct {
+		Email          string `json:"email"`
+		EncryptedShare []byte `json:"encryptedShare"`
+	}
+
+	body := struct {
+		Threshold int          `json:"threshold"`
+		Shares    []shareInput `json:"shares"`
+	}{Threshold: threshold}
+
+	for i, admin := range admins {
+		encryptedShare, err := keywrap.Wrap(admin.PublicKey, shares[i])
+
+		if err != nil {
+			return nil, err
+		}
+
+		body.Shares = append(body.Shares, shareInput{admin.Email, encryptedShare})
+	}
+
+	var recoveryKey RecoveryKey
+
+	if err := c.do(ctx, http.MethodPut, "/recovery/keys/"+idString(vaultID), body, &recoveryKey); err != nil {
+		return nil, err
+	}
+
+	return &recoveryKey, nil
+}
+
+// RecoveryKey returns the recovery key of a vault, without its shares.
+func (c *Client) RecoveryKey(ctx context.Context, vaultID uint) (*RecoveryKey, error) {
+	var recoveryKey RecoveryKey
+
+	if err := c.do(ctx, http.MethodGet, "/recovery/keys/"+idString(vaultID), nil, &recoveryKey); err != nil {
+		return nil, err
+	}
+
+	return &recoveryKey, nil
+}
+
+// DeleteRecoveryKey deletes the recovery key of a vault and its ceremonies.
+func (c *Client) DeleteRecoveryKey(ctx context.Context, vaultID uint) error {
+	return c.do(ctx, http.MethodDelete, "/recovery/keys/"+idString(vaultID), nil, nil)
+}
+
+// RecoveryCeremonies returns the ceremonies of the recovery key of a vault, newest first.
+func (c *Client) RecoveryCeremonies(ctx context.Context, vaultID uint) ([]RecoveryCeremony, error) {
+	var ceremonies []RecoveryCeremony
+	err := c.do(ctx, http.MethodGet, "/recovery/keys/"+idString(vaultID)+"/ceremonies", nil, &ceremonies)
+
+	return ceremonies, err
+}
+
+// StartRecoveryCeremony starts a ceremony rebuilding the recovery key of a
+// vault, which the other admins see along with reason. It returns the private
+// key the approved shares are encrypted to, which CompleteRecoveryCeremony needs.
+func (c *Client) StartRecoveryCeremony(ctx context.Context, vaultID uint, reason string) (*RecoveryCeremony, []byte, error) {
+	publicKey, privateKey, err := keywrap.GenerateKey()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body := struct {
+		Reason    string `json:"reason"`
+		PublicKey []byte `json:"publicKey"`
+	}{reason, publicKey}
+
+	var ceremony RecoveryCeremony
+
+	if err := c.do(ctx, http.MethodPost, "/recovery/keys/"+idString(vaultID)+"/ceremonies", body, &ceremony); err != nil {
+		return nil, nil, err
+	}
+
+	return &ceremony, privateKey, nil
+}
+
+// ApproveRecoveryCeremony approves a ceremony with the share of the user,
+// decrypted with privateKey, the private key of the admin, and encrypted again
+// to the requester of the ceremony. It returns the ceremony with the approval.
+func (c *Client) ApproveRecoveryCeremony(ctx context.Context, ceremony RecoveryCeremony, privateKey []byte) (*RecoveryCeremony, error) {
+	var res struct {
+		EncryptedShare []byte `json:"encryptedShare"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/recovery/keys/"+idString(ceremony.VaultID)+"/share", nil, &res); err != nil {
+		return nil, err
+	}
+
+	share, err := keywrap.Unwrap(privateKey, res.EncryptedShare)
+
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedShare, err := keywrap.Wrap(ceremony.PublicKey, share)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body := struct {
+		Share []byte `json:"share"`
+	}{wrappedShare}
+
+	var approved RecoveryCeremony
+
+	if err := c.do(ctx, http.MethodPost, "/recovery/ceremonies/"+idString(ceremony.ID)+"/approve", body, &approved); err != nil {
+		return nil, err
+	}
+
+	return &approved, nil
+}
+
+// CancelRecoveryCeremony cancels a ceremony the user requested, or one of a vault of the user.
+func (c *Client) CancelRecoveryCeremony(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodPost, "/recovery/ceremonies/"+idString(id)+"/cancel", nil, nil)
+}
+
+// CompleteRecoveryCeremony gets the shares of an approved ceremony the user
+// requested, decrypts them with privateKey, returned by StartRecoveryCeremony,
+// and rebuilds the recovery key with them. The server hands the shares out once.
+func (c *Client) CompleteRecoveryCeremony(ctx context.Context, id uint, privateKey []byte) ([]byte, error) {
+	var res struct {
+		Shares [][]byte `json:"shares"`
+	}
+
+	if err := c.do(ctx, http.MethodPost, "/recovery/ceremonies/"+idString(id)+"/complete", nil, &res); err != nil {
+		return nil, err
+	}
+
+	shares := make([][]byte, len(res.Shares))
+
+	for i, wrappedShare := range res.Shares {
+		share, err := keywrap.Unwrap(privateKey, wrappedShare)
+
+		if err != nil {
+			return nil, err
+		}
+
+		shares[i] = share
+	}
+
+	return shamir.Combine(shares)
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/send"
+)
+
+const (
+	// SendTypeText is the type of the sends holding a text.
+	SendTypeText = "text"
+	// SendTypeItem is the type of the sends holding an item snapshot.
+	SendTypeItem = "item"
+)
+
+// SendContent is what a send shares, encrypted by the client: a text, or a
+// snapshot of an item without its folder, tags and revision.
+type SendContent struct {
+	Type string     `json:"type"`
+	Text string     `json:"text,omitempty"`
+	Item *ItemInput `json:"item,omitempty"`
+}
+
+// NewTextSend returns the content of a send sharing text.
+func NewTextSend(text string) SendContent {
+	return SendContent{Type: SendTypeText, Text: text}
+}
+
+// NewItemSend returns the content of a send sharing a snapshot of item.
+func NewItemSend(item Item) SendContent {
+	input := item.Input()
+	input.FolderID, input.Tags, input.Revision = nil, nil, 0
+
+	return SendContent{Type: SendTypeItem, Item: &input}
+}
+
+// SendOptions limit who can open a send and for how long. Zero values pick
+// the defaults of the server: a single view within a day.
+type SendOptions struct {
+	// Password is asked of the people opening the send, on top of its link.
+	Password string
+	// MaxViews is the number of times the send can be opened.
+	MaxViews int
+	// ExpiresIn is the lifetime of the send, rounded down to the second.
+	ExpiresIn time.Duration
+}
+
+// Send is a send of the user. Link is only known to CreateSend, since the
+// server never sees the key it carries.
+type Send struct {
+	ID          uint      `json:"id"`
+	AccessID    string    `json:"accessId"`
+	MaxViews    int       `json:"maxViews"`
+	Views       int       `json:"views"`
+	HasPassword bool      `json:"hasPassword,omitempty"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Link        string    `json:"link,omitempty"`
+}
+
+// ReceivedSend is a send opened from its link. ViewsLeft is zero when it was
+// the last view, after which the send is gone.
+type ReceivedSend struct {
+	SendContent
+	ViewsLeft int       `json:"viewsLeft"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CreateSend encrypts content with a new key and stores it on the server as
+// a send, which it returns along with its link. The key is carried in the
+// fragment of the link only, so the server can't decrypt the send.
+func (c *Client) CreateSend(ctx context.Context, content SendContent, opts SendOptions) (*Send, error) {
+	plaintext, err := json.Marshal(content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := send.NewKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := send.Seal(key, opts.Password, plaintext)
+
+	if err != nil {
+		return nil, err
+	}
+
+	accessKey, err := send.AccessKey(key, opts.Password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body := struct {
+		Ciphertext []byte `json:"ciphertext"`
+		AccessKey  string `json:"accessKey,omitempty"`
+		MaxViews   int    `json:"maxViews,omitempty"`
+		ExpiresIn  int64  `json:"expiresIn,omitempty"`
+	}{ciphertext, accessKey, opts.MaxViews, int64(opts.ExpiresIn / time.Second)}
+
+	var created Send
+
+	if err := c.do(ctx, http.MethodPost, "/sends", body, &created); err != nil {
+		return nil, err
+	}
+
+	created.Link = send.Link(c.BaseURL, created.AccessID, key)
+
+	return &created, nil
+}
+
+// Sends returns the sends of the user that are still open, without their links.
+func (c *Client) Sends(ctx context.Context) ([]Send, error) {
+	var sends []Send
+	err := c.do(ctx, http.MethodGet, "/sends", nil, &sends)
+
+	return sends, err
+}
+
+// DeleteSend deletes a send before its expiry or its last view.
+func (c *Client) DeleteSend(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, "/sends/"+idString(id), nil, nil)
+}
+
+// OpenSend opens the send at link, using up one of its views. It needs no
+// account; password is the access password of the sends that have one.
+func OpenSend(ctx context.Context, link, password string) (*ReceivedSend, error) {
+	baseURL, accessID, key, err := send.ParseLink(link)
+
+	if err != nil {
+		return nil, errors.New("invalid send link")
+	}
+
+	accessKey, err := send.AccessKey(key, password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Ciphertext []byte    `json:"ciphertext"`
+		ViewsLeft  int       `json:"viewsLeft"`
+		ExpiresAt  time.Time `json:"expiresAt"`
+	}
+
+	body := map[string]string{"accessKey": accessKey}
+
+	if err := New(baseURL, "").do(ctx, http.MethodPost, "/send/"+url.PathEscape(accessID), body, &res); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := send.Open(key, password, res.Ciphertext)
+
+	if err != nil {
+		return nil, errors.New("send can't be decrypted, the link may be incomplete")
+	}
+
+	received := ReceivedSend{ViewsLeft: res.ViewsLeft, ExpiresAt: res.ExpiresAt}
+
+	if err := json.Unmarshal(plaintext, &received.SendContent); err != nil {
+		return nil, fmt.Errorf("invalid send content: %w", err)
+	}
+
+	return &received, nil
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// watchRetry is the wait before reconnecting a dropped stream, doubled
+	// while the server can't be reached up to watchMaxRetry.
+	watchRetry    = time.Second
+	watchMaxRetry = time.Minute
+	// watchIdleTimeout is how long a stream may stay silent, heartbeats
+	// included, before it is taken for dropped.
+	watchIdleTimeout = 45 * time.Second
+)
+
+// Notification tells that a vault or item changed, so the client syncs.
+// It holds the IDs and the revision of the change only, never secrets.
+type Notification struct {
+	Type     string `json:"type"`
+	VaultID  uint   `json:"vaultId,omitempty"`
+	ItemID   uint   `json:"itemId,omitempty"`
+	Revision uint64 `json:"revision,omitempty"`
+}
+
+// stopError is an error returned by the function given to Watch.
+type stopError struct {
+	err error
+}
+
+func (e stopError) Error() string {
+	return e.err.Error()
+}
+
+// Watch calls fn with the notifications of the changes made to the vaults of
+// the user, until ctx is done or fn returns an error, which Watch returns.
+// When since isn't nil, the changes made after that revision come first.
+// Dropped streams are resumed from the last revision received. Errors of the
+// requests, such as an expired token, end the watch.
+func (c *Client) Watch(ctx context.Context, since *uint64, fn func(Notification) error) error {
+	retry := watchRetry
+
+	for {
+		received, err := c.stream(ctx, since, func(n Notification) error {
+			if n.Revision != 0 {
+				revision := n.Revision
+				since = &revision
+			}
+
+			if err := fn(n); err != nil {
+				return stopError{err}
+			}
+
+			return nil
+		})
+
+		var stop stopError
+		var resErr *Error
+
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.As(err, &stop):
+			return stop.err
+		case errors.As(err, &resErr) && resErr.StatusCode < http.StatusInternalServerError:
+			return err
+		}
+
+		if received {
+			retry = watchRetry
+		}
+
+		select {
+		case <-time.After(retry):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if retry *= 2; retry > watchMaxRetry {
+			retry = watchMaxRetry
+		}
+	}
+}
+
+// stream reads a stream of server-sent events until it ends, telling whether
+// any notification was received.
+func (c *Client) stream(ctx context.Context, since *uint64, fn func(Notification) error) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	path := "/events"
+
+	if since != nil {
+		path += "?" + url.Values{"since": {strconv.FormatUint(*since, 10)}}.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return false, responseError(res)
+	}
+
+	idle := time.AfterFunc(watchIdleTimeout, cancel)
+	defer idle.Stop()
+
+	received := false
+	var data strings.Builder
+	scanner := bufio.NewScanner(res.Body)
+
+	for scanner.Scan() {
+		idle.Reset(watchIdleTimeout)
+		line := scanner.Text()
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+
+			var notification Notification
+
+			if err := json.Unmarshal([]byte(data.String()), &notification); err != nil {
+				return received, fmt.Errorf("invalid notification from server: %w", err)
+			}
+
+			data.Reset()
+			received = true
+
+			if err := fn(notification); err != nil {
+				return received, err
+			}
+		case field == "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+
+			data.WriteString(value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return received, err
+	}
+
+	return received, errors.New("stream ended")
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/internal/cerrors"
+	"github.com/edgardjr92/gopass/internal/handlers"
+	"github.com/edgardjr92/gopass/internal/mocks"
+	"github.com/edgardjr92/gopass/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// notifications returns a closed channel holding notifications, a stream the server ends.
+func notifications(sent ...models.Notification) <-chan models.Notification {
+	ch := make(chan models.Notification, len(sent))
+
+	for _, n := range sent {
+		ch <- n
+	}
+
+	close(ch)
+
+	return ch
+}
+
+func TestWatch(t *testing.T) {
+	defaultRetry := watchRetry
+	watchRetry = time.Millisecond
+	t.Cleanup(func() { watchRetry = defaultRetry })
+
+	t.Run("resumed from the last revision", func(t *testing.T) {
+		// given
+		resumed := uint64(42)
+		notificationSvc := &mocks.NotificationServiceMock{}
+		notificationSvc.On("Subscribe", mock.Anything, (*uint64)(nil)).Return(notifications(
+			models.Notification{Type: models.EventVaultCreated, VaultID: 2, Revision: 41},
+			models.Notification{Type: models.EventItemUpdated, VaultID: 1, ItemID: 5, Revision: 42},
+		), nil).Once()
+		notificationSvc.On("Subscribe", mock.Anything, &resumed).Return(notifications(
+			models.Notification{Type: models.EventItemDeleted, VaultID: 1, ItemID: 5, Revision: 43},
+		), nil).Once()
+
+		mux := http.NewServeMux()
+		handlers.NewNotificationHandler(notificationSvc).Register(mux)
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		var received []Notification
+		stop := errors.New("stop")
+
+		// when
+		err := New(server.URL, "jwt-token").Watch(context.TODO(), nil, func(n Notification) error {
+			received = append(received, n)
+
+			if n.Revision == 43 {
+				return stop
+			}
+
+			return nil
+		})
+
+		// then
+		assert.Equal(t, stop, err)
+		assert.Equal(t, []Notification{
+			{Type: "vault.created", VaultID: 2, Revision: 41},
+			{Type: "item.updated", VaultID: 1, ItemID: 5, Revision: 42},
+			{Type: "item.deleted", VaultID: 1, ItemID: 5, Revision: 43},
+		}, received)
+		notificationSvc.AssertExpectations(t)
+	})
+
+	t.Run("request error", func(t *testing.T) {
+		// given
+		since := uint64(50)
+		notificationSvc := &mocks.NotificationServiceMock{}
+		notificationSvc.On("Subscribe", mock.Anything, &since).Return(nil, cerrors.ConflictError("revision is ahead of the server, sync from 0"))
+
+		mux := http.NewServeMux()
+		handlers.NewNotificationHandler(notificationSvc).Register(mux)
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		// when
+		err := New(server.URL, "jwt-token").Watch(context.TODO(), &since, func(n Notification) error { return nil })
+
+		// then
+		assert.Equal(t, &Error{StatusCode: http.StatusConflict, Message: "revision is ahead of the server, sync from 0"}, err)
+	})
+
+	t.Run("server unreachable", func(t *testing.T) {
+		// given
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+		defer cancel()
+
+		// when: the client retries until ctx is done
+		err := New(server.URL, "jwt-token").Watch(ctx, nil, func(n Notification) error { return nil })
+
+		// then
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+}
@@ -0,0 +1,67 @@
+// Package eventbus implements an in-process publish/subscribe bus.
+//
+// Events are handed to the subscribers synchronously, in the goroutine of the
+// publisher and in the order they subscribed, so subscribers doing slow work,
+// such as network calls, must hand the events over to their own goroutines.
+package eventbus
+
+import (
+	"context"
+	"sync"
+)
+
+// Handler handles the events published on a bus.
+type Handler[E any] func(ctx context.Context, event E)
+
+type subscription[E any] struct {
+	id      uint64
+	handler Handler[E]
+}
+
+// Bus is a publish/subscribe bus of events of type E, safe for concurrent use.
+// The zero value is an empty bus ready to use.
+type Bus[E any] struct {
+	mu            sync.RWMutex
+	subscriptions []subscription[E]
+	nextID        uint64
+}
+
+// New returns an empty bus.
+func New[E any]() *Bus[E] {
+	return &Bus[E]{}
+}
+
+// Subscribe adds handler to the subscribers of the bus. The returned function
+// removes it, and can be called more than once.
+func (b *Bus[E]) Subscribe(handler Handler[E]) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.subscriptions = append(b.subscriptions, subscription[E]{id, handler})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		for i, s := range b.subscriptions {
+			if s.id == id {
+				// Copied, so Publish calls iterating the old slice aren't affected.
+				b.subscriptions = append(b.subscriptions[:i:i], b.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish hands event to the subscribers of the bus.
+func (b *Bus[E]) Publish(ctx context.Context, event E) {
+	b.mu.RLock()
+	subscriptions := b.subscriptions
+	b.mu.RUnlock()
+
+	for _, s := range subscriptions {
+		s.handler(ctx, event)
+	}
+}
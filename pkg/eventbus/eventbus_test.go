@@ -0,0 +1,81 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublish(t *testing.T) {
+	bus := New[string]()
+	var received []string
+
+	bus.Subscribe(func(ctx context.Context, event string) { received = append(received, "first "+event) })
+	unsubscribe := bus.Subscribe(func(ctx context.Context, event string) { received = append(received, "second "+event) })
+	bus.Subscribe(func(ctx context.Context, event string) { received = append(received, "third "+event) })
+
+	bus.Publish(context.TODO(), "created")
+	unsubscribe()
+	unsubscribe()
+	bus.Publish(context.TODO(), "deleted")
+
+	assert.Equal(t, []string{"first created", "second created", "third created", "first deleted", "third deleted"}, received)
+}
+
+func TestPublishWithoutSubscribers(t *testing.T) {
+	var bus Bus[int]
+
+	assert.NotPanics(t, func() { bus.Publish(context.TODO(), 1) })
+}
+
+func TestUnsubscribeWhilePublishing(t *testing.T) {
+	bus := New[int]()
+	var mu sync.Mutex
+	count := 0
+	var unsubscribe func()
+
+	unsubscribe = bus.Subscribe(func(ctx context.Context, event int) {
+		// Unsubscribing from a handler must not deadlock.
+		unsubscribe()
+	})
+	bus.Subscribe(func(ctx context.Context, event int) {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+	})
+
+	bus.Publish(context.TODO(), 1)
+	bus.Publish(context.TODO(), 2)
+
+	assert.Equal(t, 2, count)
+}
+
+func TestConcurrentUse(t *testing.T) {
+	bus := New[int]()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	total := 0
+
+	bus.Subscribe(func(ctx context.Context, event int) {
+		mu.Lock()
+		defer mu.Unlock()
+		total += event
+	})
+
+	for i := 1; i <= 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			bus.Publish(context.TODO(), i)
+		}(i)
+		go func() {
+			defer wg.Done()
+			bus.Subscribe(func(ctx context.Context, event int) {})()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 55, total)
+}
@@ -0,0 +1,122 @@
+// Package generator generates random passwords.
+package generator
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+const (
+	lowercase = "abcdefghijklmnopqrstuvwxyz"
+	uppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits    = "0123456789"
+	symbols   = "!@#$%^&*()-_=+[]{};:,.<>?/~"
+	// ambiguous holds the characters that are easily mistaken for one another.
+	ambiguous = "Il1O0o"
+)
+
+var (
+	ErrNoCharacterSets = errors.New("generator: at least one character set must be enabled")
+	ErrTooShort        = errors.New("generator: length is shorter than the number of enabled character sets")
+)
+
+type Options struct {
+	Length    int
+	Lowercase bool
+	Uppercase bool
+	Digits    bool
+	Symbols   bool
+	// ExcludeAmbiguous leaves out characters such as l, 1, O and 0.
+	ExcludeAmbiguous bool
+}
+
+// DefaultOptions returns options for a 20 characters password using all character sets.
+func DefaultOptions() Options {
+	return Options{Length: 20, Lowercase: true, Uppercase: true, Digits: true, Symbols: true}
+}
+
+// Generate returns a random password built from the enabled character sets.
+// The password has at least one character of each enabled set.
+func Generate(opts Options) (string, error) {
+	var sets []string
+
+	for _, set := range []struct {
+		enabled bool
+		chars   string
+	}{
+		{opts.Lowercase, lowercase},
+		{opts.Uppercase, uppercase},
+		{opts.Digits, digits},
+		{opts.Symbols, symbols},
+	} {
+		if !set.enabled {
+			continue
+		}
+
+		chars := set.chars
+
+		if opts.ExcludeAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguous, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+
+		sets = append(sets, chars)
+	}
+
+	if len(sets) == 0 {
+		return "", ErrNoCharacterSets
+	}
+
+	if opts.Length < len(sets) {
+		return "", ErrTooShort
+	}
+
+	all := strings.Join(sets, "")
+	password := make([]byte, opts.Length)
+
+	for i := range password {
+		// The first characters come from each set in turn, so every set is used.
+		chars := all
+		if i < len(sets) {
+			chars = sets[i]
+		}
+
+		n, err := randInt(len(chars))
+
+		if err != nil {
+			return "", err
+		}
+
+		password[i] = chars[n]
+	}
+
+	// Shuffle so the guaranteed characters are not always at the start.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randInt(i + 1)
+
+		if err != nil {
+			return "", err
+		}
+
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
+
+// randInt returns a uniform random number in [0, n).
+func randInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int(v.Int64()), nil
+}
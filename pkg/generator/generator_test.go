@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("default options", func(t *testing.T) {
+		// when
+		password, err := Generate(DefaultOptions())
+
+		// then
+		assert.Nil(t, err)
+		assert.Len(t, password, 20)
+		assert.True(t, strings.ContainsAny(password, lowercase))
+		assert.True(t, strings.ContainsAny(password, uppercase))
+		assert.True(t, strings.ContainsAny(password, digits))
+		assert.True(t, strings.ContainsAny(password, symbols))
+	})
+
+	t.Run("uses every enabled set", func(t *testing.T) {
+		opts := Options{Length: 2, Digits: true, Symbols: true}
+
+		for i := 0; i < 50; i++ {
+			// when
+			password, err := Generate(opts)
+
+			// then
+			assert.Nil(t, err)
+			assert.True(t, strings.ContainsAny(password, digits))
+			assert.True(t, strings.ContainsAny(password, symbols))
+		}
+	})
+
+	t.Run("exclude ambiguous", func(t *testing.T) {
+		opts := Options{Length: 200, Lowercase: true, Uppercase: true, Digits: true, ExcludeAmbiguous: true}
+
+		// when
+		password, err := Generate(opts)
+
+		// then
+		assert.Nil(t, err)
+		assert.False(t, strings.ContainsAny(password, ambiguous))
+	})
+
+	t.Run("passwords differ", func(t *testing.T) {
+		// when
+		first, _ := Generate(DefaultOptions())
+		second, _ := Generate(DefaultOptions())
+
+		// then
+		assert.NotEqual(t, first, second)
+	})
+
+	testCases := []struct {
+		name string
+		opts Options
+		err  error
+	}{
+		{"no character sets", Options{Length: 10}, ErrNoCharacterSets},
+		{"too short", Options{Length: 1, Lowercase: true, Digits: true}, ErrTooShort},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			password, err := Generate(tc.opts)
+
+			// then
+			assert.Empty(t, password)
+			assert.Equal(t, tc.err, err)
+		})
+	}
+}
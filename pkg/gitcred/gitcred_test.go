@@ -0,0 +1,377 @@
+package gitcred
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStore keeps vaults and items in memory.
+type fakeStore struct {
+	vaults []client.Vault
+	items  []client.Item
+	nextID uint
+	err    error
+}
+
+func (s *fakeStore) id() uint {
+	s.nextID++
+	return 100 + s.nextID
+}
+
+func (s *fakeStore) Vaults(ctx context.Context) ([]client.Vault, error) {
+	return s.vaults, s.err
+}
+
+func (s *fakeStore) Items(ctx context.Context, vaultID uint) ([]client.Item, error) {
+	var items []client.Item
+
+	for _, item := range s.items {
+		if item.VaultID == vaultID {
+			// like the server, listings leave out the TOTP
+			item.TOTP = ""
+			items = append(items, item)
+		}
+	}
+
+	return items, s.err
+}
+
+func (s *fakeStore) Item(ctx context.Context, id uint) (*client.Item, error) {
+	for _, item := range s.items {
+		if item.ID == id {
+			return &item, s.err
+		}
+	}
+	return nil, errors.New("item not found")
+}
+
+func (s *fakeStore) CreateVault(ctx context.Context, name string) (uint, error) {
+	id := s.id()
+	s.vaults = append(s.vaults, client.Vault{ID: id, Name: name})
+	return id, nil
+}
+
+func (s *fakeStore) CreateItem(ctx context.Context, vaultID uint, input client.ItemInput) (uint, error) {
+	id := s.id()
+	s.items = append(s.items, client.Item{
+		ID: id, VaultID: vaultID, Name: input.Name, URL: input.URL, Username: input.Username, Password: input.Password,
+	})
+	return id, nil
+}
+
+func (s *fakeStore) UpdateItem(ctx context.Context, id uint, input client.ItemInput) error {
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].Name, s.items[i].URL = input.Name, input.URL
+			s.items[i].Username, s.items[i].Password = input.Username, input.Password
+			s.items[i].TOTP = input.TOTP
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) DeleteItem(ctx context.Context, id uint) error {
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("item not found")
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		vaults: []client.Vault{{ID: 1, Name: "Personal"}, {ID: 2, Name: "Git"}},
+		items: []client.Item{
+			{ID: 10, VaultID: 1, Name: "GitHub", URL: "https://github.com", Username: "octocat", Password: "gh-secret"},
+			{ID: 11, VaultID: 1, Name: "GitHub work", URL: "https://github.com/acme", Username: "octo-work", Password: "work-secret"},
+			{ID: 12, VaultID: 1, Name: "GitLab", URL: "gitlab.com", Username: "tanuki", Password: "gl-secret"},
+			{ID: 13, VaultID: 1, Name: "No password", URL: "https://example.com", Username: "nobody"},
+			{ID: 20, VaultID: 2, Name: "Gitea", URL: "http://git.local:3000", Username: "admin", Password: "gitea-secret"},
+		},
+	}
+}
+
+// runHelper runs an action with the given stdin, as git does.
+func runHelper(t *testing.T, h *Helper, action, stdin string) string {
+	var out bytes.Buffer
+
+	err := h.Run(context.TODO(), action, strings.NewReader(stdin), &out)
+
+	assert.Nil(t, err)
+
+	return out.String()
+}
+
+func TestReadCredential(t *testing.T) {
+	t.Run("attributes", func(t *testing.T) {
+		// when
+		c, err := ReadCredential(strings.NewReader("protocol=https\nhost=github.com\npath=acme/api.git\nusername=octocat\nwwwauth[]=Basic\n\nignored=1\n"))
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, Credential{Protocol: "https", Host: "github.com", Path: "acme/api.git", Username: "octocat"}, c)
+	})
+
+	t.Run("url", func(t *testing.T) {
+		// when
+		c, err := ReadCredential(strings.NewReader("url=https://octocat@github.com:8443/acme/api.git\n"))
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, Credential{Protocol: "https", Host: "github.com:8443", Path: "acme/api.git", Username: "octocat"}, c)
+	})
+
+	t.Run("invalid line", func(t *testing.T) {
+		// when
+		_, err := ReadCredential(strings.NewReader("host\n"))
+
+		// then
+		assert.Equal(t, `gitcred: invalid line "host"`, err.Error())
+	})
+}
+
+func TestWriteCredential(t *testing.T) {
+	var out bytes.Buffer
+
+	err := WriteCredential(&out, Credential{Protocol: "https", Host: "github.com", Username: "octocat", Password: "secret"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "protocol=https\nhost=github.com\nusername=octocat\npassword=secret\n", out.String())
+
+	err = WriteCredential(&out, Credential{Password: "a\nb"})
+
+	assert.Equal(t, "gitcred: password holds a newline or NUL character", err.Error())
+}
+
+func TestMatchScore(t *testing.T) {
+	c := Credential{Protocol: "https", Host: "github.com", Path: "acme/api.git"}
+
+	testCases := []struct {
+		url   string
+		score int
+	}{
+		{"github.com", 1},
+		{"https://GitHub.com/", 2},
+		{"https://github.com/acme", 3},
+		{"https://github.com/acme/api", 4},
+		{"https://github.com/acme/api.git", 4},
+		{"http://github.com", 0},
+		{"https://github.com/other", 0},
+		{"https://github.com/acme/api-v2", 0},
+		{"https://github.com:8443", 0},
+		{"https://gist.github.com", 0},
+		{"", 0},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.score, matchScore(tc.url, c), tc.url)
+	}
+
+	// a URL without scheme is not sent over http
+	assert.Equal(t, 0, matchScore("github.com", Credential{Protocol: "http", Host: "github.com"}))
+	assert.Equal(t, 2, matchScore("http://github.com", Credential{Protocol: "http", Host: "github.com"}))
+
+	// without credential.useHttpPath, git sends no path and any path of the host matches
+	assert.Equal(t, 2, matchScore("https://github.com/acme", Credential{Protocol: "https", Host: "github.com"}))
+}
+
+func TestGet(t *testing.T) {
+	testCases := []struct {
+		name   string
+		helper Helper
+		stdin  string
+		out    string
+	}{
+		{
+			"host match",
+			Helper{},
+			"protocol=https\nhost=github.com\n\n",
+			"protocol=https\nhost=github.com\nusername=octocat\npassword=gh-secret\n",
+		},
+		{
+			"most specific path",
+			Helper{},
+			"protocol=https\nhost=github.com\npath=acme/api.git\n\n",
+			"protocol=https\nhost=github.com\npath=acme/api.git\nusername=octo-work\npassword=work-secret\n",
+		},
+		{
+			"username given",
+			Helper{},
+			"protocol=https\nhost=github.com\npath=acme/api.git\nusername=octocat\n\n",
+			"protocol=https\nhost=github.com\npath=acme/api.git\nusername=octocat\npassword=gh-secret\n",
+		},
+		{
+			"url without scheme",
+			Helper{},
+			"protocol=ssh\nhost=gitlab.com\n\n",
+			"protocol=ssh\nhost=gitlab.com\nusername=tanuki\npassword=gl-secret\n",
+		},
+		{
+			"port",
+			Helper{},
+			"url=http://git.local:3000/team/app.git\n\n",
+			"protocol=http\nhost=git.local:3000\npath=team/app.git\nusername=admin\npassword=gitea-secret\n",
+		},
+		{"item without password", Helper{}, "protocol=https\nhost=example.com\n\n", ""},
+		{"no match", Helper{}, "protocol=https\nhost=bitbucket.org\n\n", ""},
+		{"only vault", Helper{OnlyVault: true}, "protocol=https\nhost=github.com\n\n", ""},
+		{"missing only vault", Helper{Vault: "Work", OnlyVault: true}, "protocol=https\nhost=github.com\n\n", ""},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			store := newFakeStore()
+			tc.helper.Store = store
+
+			// when
+			out := runHelper(t, &tc.helper, "get", tc.stdin)
+
+			// then
+			assert.Equal(t, tc.out, out)
+		})
+	}
+
+	t.Run("store error", func(t *testing.T) {
+		// given
+		store := newFakeStore()
+		store.err = errors.New("unauthorized")
+
+		// when
+		err := (&Helper{Store: store}).Run(context.TODO(), "get", strings.NewReader("protocol=https\nhost=github.com\n"), &bytes.Buffer{})
+
+		// then
+		assert.Equal(t, "unauthorized", err.Error())
+	})
+}
+
+func TestStore(t *testing.T) {
+	t.Run("creates the vault and the item", func(t *testing.T) {
+		// given
+		store := newFakeStore()
+		helper := &Helper{Store: store, Vault: "CI"}
+
+		// when
+		out := runHelper(t, helper, "store", "protocol=https\nhost=github.com\npath=acme/api.git\nusername=bot\npassword=token\n\n")
+
+		// then
+		assert.Equal(t, "", out)
+		assert.Equal(t, client.Vault{ID: 101, Name: "CI"}, store.vaults[2])
+		assert.Equal(t, client.Item{
+			ID: 102, VaultID: 101, Name: "github.com/acme/api", URL: "https://github.com/acme/api.git", Username: "bot", Password: "token",
+		}, store.items[len(store.items)-1])
+
+		// the stored credential is returned by get
+		out = runHelper(t, helper, "get", "protocol=https\nhost=github.com\npath=acme/api.git\nusername=bot\n")
+		assert.Equal(t, "protocol=https\nhost=github.com\npath=acme/api.git\nusername=bot\npassword=token\n", out)
+	})
+
+	t.Run("updates the password", func(t *testing.T) {
+		// given
+		store := newFakeStore()
+
+		// when
+		runHelper(t, &Helper{Store: store}, "store", "protocol=http\nhost=git.local:3000\nusername=admin\npassword=new-secret\n")
+
+		// then
+		assert.Len(t, store.items, 5)
+		assert.Equal(t, "new-secret", store.items[4].Password)
+		assert.Equal(t, "Gitea", store.items[4].Name)
+	})
+
+	t.Run("keeps the totp of the updated item", func(t *testing.T) {
+		// given
+		store := newFakeStore()
+		store.items[4].TOTP = "otpauth://totp/gitea?secret=JBSWY3DPEHPK3PXP"
+
+		// when
+		runHelper(t, &Helper{Store: store}, "store", "protocol=http\nhost=git.local:3000\nusername=admin\npassword=new-secret\n")
+
+		// then
+		assert.Equal(t, "new-secret", store.items[4].Password)
+		assert.Equal(t, "otpauth://totp/gitea?secret=JBSWY3DPEHPK3PXP", store.items[4].TOTP)
+	})
+
+	t.Run("never writes outside its vault", func(t *testing.T) {
+		// given
+		store := newFakeStore()
+
+		// when
+		runHelper(t, &Helper{Store: store}, "store", "protocol=https\nhost=github.com\nusername=octocat\npassword=changed\n")
+
+		// then
+		assert.Equal(t, "gh-secret", store.items[0].Password)
+		assert.Equal(t, client.Item{
+			ID: 101, VaultID: 2, Name: "github.com", URL: "https://github.com", Username: "octocat", Password: "changed",
+		}, store.items[5])
+	})
+
+	t.Run("without password", func(t *testing.T) {
+		// given
+		store := newFakeStore()
+
+		// when
+		runHelper(t, &Helper{Store: store}, "store", "protocol=https\nhost=github.com\nusername=octocat\n")
+
+		// then
+		assert.Len(t, store.items, 5)
+	})
+}
+
+func TestErase(t *testing.T) {
+	t.Run("deletes the matching item", func(t *testing.T) {
+		// given
+		store := newFakeStore()
+
+		// when
+		runHelper(t, &Helper{Store: store}, "erase", "protocol=http\nhost=git.local:3000\nusername=admin\npassword=gitea-secret\n")
+
+		// then
+		assert.Len(t, store.items, 4)
+		assert.Equal(t, uint(13), store.items[3].ID)
+	})
+
+	testCases := []struct {
+		name   string
+		helper Helper
+		stdin  string
+	}{
+		{"password changed since", Helper{}, "protocol=http\nhost=git.local:3000\nusername=admin\npassword=old\n"},
+		{"other protocol", Helper{}, "protocol=https\nhost=git.local:3000\nusername=admin\n"},
+		{"outside its vault", Helper{}, "protocol=https\nhost=github.com\nusername=octocat\npassword=gh-secret\n"},
+		{"missing vault", Helper{Vault: "Work"}, "protocol=http\nhost=git.local:3000\nusername=admin\n"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			store := newFakeStore()
+			tc.helper.Store = store
+
+			// when
+			runHelper(t, &tc.helper, "erase", tc.stdin)
+
+			// then
+			assert.Len(t, store.items, 5)
+			assert.Len(t, store.vaults, 2)
+		})
+	}
+}
+
+func TestRunUnknownAction(t *testing.T) {
+	// given
+	store := newFakeStore()
+
+	// when
+	out := runHelper(t, &Helper{Store: store}, "capability", "protocol=https\nhost=github.com\n")
+
+	// then
+	assert.Equal(t, "", out)
+}
@@ -0,0 +1,274 @@
+package gitcred
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+)
+
+// DefaultVault is the vault credentials are stored in when Helper.Vault is empty.
+const DefaultVault = "Git"
+
+// Store is the part of the gopass API used by the helper, implemented by *client.Client.
+type Store interface {
+	Vaults(ctx context.Context) ([]client.Vault, error)
+	Items(ctx context.Context, vaultID uint) ([]client.Item, error)
+	Item(ctx context.Context, id uint) (*client.Item, error)
+	CreateVault(ctx context.Context, name string) (uint, error)
+	CreateItem(ctx context.Context, vaultID uint, input client.ItemInput) (uint, error)
+	UpdateItem(ctx context.Context, id uint, input client.ItemInput) error
+	DeleteItem(ctx context.Context, id uint) error
+}
+
+// Helper answers the requests of git.
+//
+// Get looks for credentials in every vault, unless OnlyVault is set. Store
+// and erase only ever change the items of Vault, so the helper can't delete
+// or overwrite logins the user keeps elsewhere.
+type Helper struct {
+	Store Store
+	// Vault is where credentials are stored, DefaultVault when empty.
+	// It is created on the first store.
+	Vault string
+	// OnlyVault restricts get to Vault.
+	OnlyVault bool
+}
+
+// Run runs a git credential helper action, reading the credential from in
+// and writing the response to out. Unknown actions are ignored, as the
+// protocol requires.
+func (h *Helper) Run(ctx context.Context, action string, in io.Reader, out io.Writer) error {
+	c, err := ReadCredential(in)
+
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "get":
+		found, ok, err := h.Get(ctx, c)
+
+		if err != nil || !ok {
+			return err
+		}
+
+		return WriteCredential(out, found)
+	case "store":
+		return h.Save(ctx, c)
+	case "erase":
+		return h.Erase(ctx, c)
+	default:
+		return nil
+	}
+}
+
+// Get returns the username and password of the item whose URL best matches c.
+// When c has a username, only the items with that username match.
+func (h *Helper) Get(ctx context.Context, c Credential) (Credential, bool, error) {
+	if c.Host == "" {
+		return Credential{}, false, nil
+	}
+
+	vaults, err := h.searchedVaults(ctx)
+
+	if err != nil {
+		return Credential{}, false, err
+	}
+
+	var best *client.Item
+	bestScore := 0
+
+	for _, vault := range vaults {
+		items, err := h.Store.Items(ctx, vault.ID)
+
+		if err != nil {
+			return Credential{}, false, err
+		}
+
+		for i := range items {
+			item := &items[i]
+
+			if item.Password == "" || (c.Username != "" && item.Username != c.Username) {
+				continue
+			}
+
+			if score := matchScore(item.URL, c); score > bestScore {
+				best, bestScore = item, score
+			}
+		}
+	}
+
+	if best == nil {
+		return Credential{}, false, nil
+	}
+
+	found := c
+	found.Username = best.Username
+	found.Password = best.Password
+
+	return found, true, nil
+}
+
+// Save stores the credential git used successfully. The item holding the same
+// URL and username is updated, or a new item is created.
+func (h *Helper) Save(ctx context.Context, c Credential) error {
+	if c.Host == "" || c.Password == "" {
+		return nil
+	}
+
+	vaultID, err := h.storeVault(ctx, true)
+
+	if err != nil {
+		return err
+	}
+
+	items, err := h.Store.Items(ctx, vaultID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.Username != c.Username || !sameURL(item.URL, c) {
+			continue
+		}
+
+		if item.Password == c.Password {
+			return nil
+		}
+
+		// Listings leave out secrets such as the TOTP, which the update would
+		// clear, so the update is built from the full item.
+		full, err := h.Store.Item(ctx, item.ID)
+
+		if err != nil {
+			return err
+		}
+
+		input := full.Input()
+		input.Password = c.Password
+
+		return h.Store.UpdateItem(ctx, item.ID, input)
+	}
+
+	name := c.Host
+
+	if c.Path != "" {
+		name += "/" + cleanPath(c.Path)
+	}
+
+	_, err = h.Store.CreateItem(ctx, vaultID, client.ItemInput{
+		Name:     name,
+		URL:      c.URL(),
+		Username: c.Username,
+		Password: c.Password,
+	})
+
+	return err
+}
+
+// Erase deletes the items of the store vault holding the credential git rejected.
+// Items whose password has changed since are kept.
+func (h *Helper) Erase(ctx context.Context, c Credential) error {
+	if c.Host == "" {
+		return nil
+	}
+
+	vaultID, err := h.storeVault(ctx, false)
+
+	if err != nil || vaultID == 0 {
+		return err
+	}
+
+	items, err := h.Store.Items(ctx, vaultID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if c.Username != "" && item.Username != c.Username {
+			continue
+		}
+
+		if c.Password != "" && item.Password != c.Password {
+			continue
+		}
+
+		if !sameURL(item.URL, c) {
+			continue
+		}
+
+		if err := h.Store.DeleteItem(ctx, item.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *Helper) vaultName() string {
+	if h.Vault == "" {
+		return DefaultVault
+	}
+
+	return h.Vault
+}
+
+// searchedVaults returns the vaults get looks into.
+func (h *Helper) searchedVaults(ctx context.Context) ([]client.Vault, error) {
+	vaults, err := h.Store.Vaults(ctx)
+
+	if err != nil || !h.OnlyVault {
+		return vaults, err
+	}
+
+	for _, v := range vaults {
+		if v.Name == h.vaultName() {
+			return []client.Vault{v}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// storeVault returns the ID of the vault credentials are stored in, creating it
+// when create is set. It returns zero when the vault doesn't exist and create isn't set.
+func (h *Helper) storeVault(ctx context.Context, create bool) (uint, error) {
+	vaults, err := h.Store.Vaults(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, v := range vaults {
+		if v.Name == h.vaultName() {
+			return v.ID, nil
+		}
+	}
+
+	if !create {
+		return 0, nil
+	}
+
+	id, err := h.Store.CreateVault(ctx, h.vaultName())
+
+	if err != nil {
+		return 0, fmt.Errorf("creating vault %q: %w", h.vaultName(), err)
+	}
+
+	return id, nil
+}
+
+// sameURL tells whether an item URL points to exactly the location of the credential.
+func sameURL(itemURL string, c Credential) bool {
+	u, ok := parseItemURL(itemURL)
+
+	return ok &&
+		strings.EqualFold(u.Scheme, c.Protocol) &&
+		strings.EqualFold(u.Host, c.Host) &&
+		cleanPath(u.Path) == cleanPath(c.Path)
+}
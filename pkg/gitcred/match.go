@@ -0,0 +1,70 @@
+package gitcred
+
+import (
+	"net/url"
+	"strings"
+)
+
+// matchScore tells how well an item URL matches the credential requested by git.
+// It returns zero when it doesn't match and a higher score the more specific the URL is.
+//
+// The host must be the same, port included. When the URL has a scheme it must
+// be the protocol of the credential, while a URL without one, such as
+// "github.com", matches any protocol but http, so passwords are only sent in
+// clear text to items with an explicit http:// URL. When the URL has a path
+// and git sends one, the path must be the same or a parent of it; a trailing
+// ".git" is ignored.
+func matchScore(itemURL string, c Credential) int {
+	u, ok := parseItemURL(itemURL)
+
+	if !ok || u.Host == "" || !strings.EqualFold(u.Host, c.Host) {
+		return 0
+	}
+
+	score := 1
+
+	if u.Scheme != "" {
+		if !strings.EqualFold(u.Scheme, c.Protocol) {
+			return 0
+		}
+
+		score++
+	} else if strings.EqualFold(c.Protocol, "http") {
+		return 0
+	}
+
+	itemPath := cleanPath(u.Path)
+
+	if itemPath == "" || c.Path == "" {
+		return score
+	}
+
+	path := cleanPath(c.Path)
+
+	if path != itemPath && !strings.HasPrefix(path, itemPath+"/") {
+		return 0
+	}
+
+	return score + strings.Count(itemPath, "/") + 1
+}
+
+// parseItemURL parses the URL of an item, which may be a bare host such as "github.com".
+func parseItemURL(s string) (*url.URL, bool) {
+	s = strings.TrimSpace(s)
+
+	if s == "" {
+		return nil, false
+	}
+
+	if !strings.Contains(s, "://") {
+		s = "//" + s
+	}
+
+	u, err := url.Parse(s)
+
+	return u, err == nil
+}
+
+func cleanPath(path string) string {
+	return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
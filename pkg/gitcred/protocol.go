@@ -0,0 +1,118 @@
+// Package gitcred implements a git credential helper backed by gopass items.
+//
+// Git talks to credential helpers by running them with an action, get, store
+// or erase, and writing the credential on their stdin as key=value lines:
+//
+//	protocol=https
+//	host=github.com
+//	path=org/repo.git
+//
+// The response to get is written back in the same format. See
+// https://git-scm.com/docs/git-credential for the details of the protocol.
+package gitcred
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Credential holds the attributes of a git credential request or response.
+type Credential struct {
+	Protocol string
+	Host     string
+	// Path is only sent by git when credential.useHttpPath is set.
+	Path     string
+	Username string
+	Password string
+}
+
+// ReadCredential reads a credential, up to a blank line or the end of r.
+// Unknown attributes are ignored, so newer versions of git keep working.
+func ReadCredential(r io.Reader) (Credential, error) {
+	var c Credential
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			break
+		}
+
+		key, value, found := strings.Cut(line, "=")
+
+		if !found {
+			return Credential{}, fmt.Errorf("gitcred: invalid line %q", line)
+		}
+
+		switch key {
+		case "protocol":
+			c.Protocol = value
+		case "host":
+			c.Host = value
+		case "path":
+			c.Path = value
+		case "username":
+			c.Username = value
+		case "password":
+			c.Password = value
+		case "url":
+			u, err := url.Parse(value)
+
+			if err != nil {
+				return Credential{}, fmt.Errorf("gitcred: invalid url: %w", err)
+			}
+
+			c.Protocol = u.Scheme
+			c.Host = u.Host
+			c.Path = strings.TrimPrefix(u.Path, "/")
+
+			if u.User != nil {
+				c.Username = u.User.Username()
+			}
+		}
+	}
+
+	return c, scanner.Err()
+}
+
+// WriteCredential writes the non-empty attributes of c, as a response to git.
+func WriteCredential(w io.Writer, c Credential) error {
+	for _, attr := range []struct{ key, value string }{
+		{"protocol", c.Protocol},
+		{"host", c.Host},
+		{"path", c.Path},
+		{"username", c.Username},
+		{"password", c.Password},
+	} {
+		if attr.value == "" {
+			continue
+		}
+
+		// Git rejects values holding a newline, which would also start a new attribute.
+		if strings.ContainsAny(attr.value, "\n\x00") {
+			return fmt.Errorf("gitcred: %s holds a newline or NUL character", attr.key)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s=%s\n", attr.key, attr.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// URL returns the URL of the credential, as stored in the items created by the helper.
+func (c Credential) URL() string {
+	u := url.URL{Scheme: c.Protocol, Host: c.Host}
+
+	if c.Path != "" {
+		u.Path = "/" + c.Path
+	}
+
+	return u.String()
+}
@@ -0,0 +1,178 @@
+// Package inject runs commands with secrets from gopass in their environment.
+//
+// Secrets are given as environment variables whose value is a reference
+// (see package secretref), either in the environment itself or in an env
+// file:
+//
+//	# .env.gopass
+//	DATABASE_URL=gopass://Work/Postgres/url
+//	DATABASE_PASSWORD=gopass://Work/Postgres/password
+//	LOG_LEVEL=debug
+//
+// The references are resolved right before the command starts, so the
+// secrets are never written to disk.
+package inject
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/edgardjr92/gopass/pkg/secretref"
+)
+
+type Var struct {
+	Name  string
+	Value string
+}
+
+// ParseEnv parses an env file. Each line holds a NAME=value assignment, which
+// may start with "export". Blank lines and lines starting with # are ignored.
+// Values may be wrapped in single or double quotes; double quoted values
+// support the escapes of Go strings.
+func ParseEnv(r io.Reader) ([]Var, error) {
+	var vars []Var
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimSpace(strings.TrimPrefix(text, "export "))
+		name, value, found := strings.Cut(text, "=")
+		name = strings.TrimSpace(name)
+
+		if !found || !validName(name) {
+			return nil, fmt.Errorf("inject: line %d: expected NAME=value", line)
+		}
+
+		value, err := unquote(strings.TrimSpace(value))
+
+		if err != nil {
+			return nil, fmt.Errorf("inject: line %d: %v", line, err)
+		}
+
+		vars = append(vars, Var{name, value})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// ParseEnviron parses environment entries in the NAME=value form of os.Environ.
+func ParseEnviron(environ []string) []Var {
+	vars := make([]Var, 0, len(environ))
+
+	for _, entry := range environ {
+		if name, value, found := strings.Cut(entry, "="); found {
+			vars = append(vars, Var{name, value})
+		}
+	}
+
+	return vars
+}
+
+// Resolve replaces the references in the values of vars with the secrets they point to.
+// It returns the resolved variables along with the secrets, which is what should be masked.
+func Resolve(ctx context.Context, resolver secretref.Resolver, vars []Var) ([]Var, []string, error) {
+	resolved := make([]Var, len(vars))
+	var secrets []string
+
+	for i, v := range vars {
+		resolved[i] = v
+
+		if !secretref.IsRef(v.Value) {
+			continue
+		}
+
+		ref, err := secretref.Parse(v.Value)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", v.Name, err)
+		}
+
+		secret, err := resolver.Resolve(ctx, ref)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", v.Name, err)
+		}
+
+		resolved[i].Value = secret
+		secrets = append(secrets, secret)
+	}
+
+	return resolved, secrets, nil
+}
+
+// Merge merges variable lists, later ones overriding the variables with the same name.
+// Variables keep the position of their first occurrence.
+func Merge(lists ...[]Var) []Var {
+	var merged []Var
+	index := map[string]int{}
+
+	for _, vars := range lists {
+		for _, v := range vars {
+			if i, ok := index[v.Name]; ok {
+				merged[i] = v
+				continue
+			}
+
+			index[v.Name] = len(merged)
+			merged = append(merged, v)
+		}
+	}
+
+	return merged
+}
+
+// Environ formats vars in the NAME=value form used by exec.Cmd.
+func Environ(vars []Var) []string {
+	environ := make([]string, len(vars))
+
+	for i, v := range vars {
+		environ[i] = v.Name + "=" + v.Value
+	}
+
+	return environ
+}
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		if r != '_' && !(r >= 'A' && r <= 'Z') && !(r >= 'a' && r <= 'z') && !(i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func unquote(value string) (string, error) {
+	if len(value) < 2 {
+		return value, nil
+	}
+
+	switch {
+	case value[0] == '"' && value[len(value)-1] == '"':
+		return strconv.Unquote(value)
+	case value[0] == '\'' && value[len(value)-1] == '\'':
+		return value[1 : len(value)-1], nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,207 @@
+package inject
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/edgardjr92/gopass/pkg/secretref"
+	"github.com/stretchr/testify/assert"
+)
+
+type mapResolver map[string]string
+
+func (m mapResolver) Resolve(ctx context.Context, ref secretref.Ref) (string, error) {
+	value, ok := m[ref.String()]
+
+	if !ok {
+		return "", secretref.ErrNotFound
+	}
+
+	return value, nil
+}
+
+func TestParseEnv(t *testing.T) {
+	// given
+	data := `
+# database
+DATABASE_URL=gopass://Work/Postgres/url
+export DATABASE_PASSWORD = gopass://Work/Postgres/password
+GREETING="hello\tworld"
+RAW='a "quoted" $value'
+EMPTY=
+`
+
+	// when
+	vars, err := ParseEnv(strings.NewReader(data))
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, []Var{
+		{"DATABASE_URL", "gopass://Work/Postgres/url"},
+		{"DATABASE_PASSWORD", "gopass://Work/Postgres/password"},
+		{"GREETING", "hello\tworld"},
+		{"RAW", `a "quoted" $value`},
+		{"EMPTY", ""},
+	}, vars)
+
+	invalid := []string{"NO_VALUE", "1ABC=x", "A-B=x", `BAD="\q"`}
+	for _, line := range invalid {
+		_, err := ParseEnv(strings.NewReader(line))
+		assert.NotNil(t, err, line)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	resolver := mapResolver{"gopass://Work/Postgres/password": "pg-secret"}
+
+	t.Run("success", func(t *testing.T) {
+		// when
+		vars, secrets, err := Resolve(context.TODO(), resolver, []Var{
+			{"DATABASE_PASSWORD", "gopass://Work/Postgres/password"},
+			{"LOG_LEVEL", "debug"},
+		})
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, []Var{{"DATABASE_PASSWORD", "pg-secret"}, {"LOG_LEVEL", "debug"}}, vars)
+		assert.Equal(t, []string{"pg-secret"}, secrets)
+	})
+
+	t.Run("invalid reference", func(t *testing.T) {
+		// when
+		_, _, err := Resolve(context.TODO(), resolver, []Var{{"TOKEN", "gopass://Work/GitHub"}})
+
+		// then
+		assert.True(t, errors.Is(err, secretref.ErrInvalidRef))
+		assert.True(t, strings.HasPrefix(err.Error(), "TOKEN: "))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		// when
+		_, _, err := Resolve(context.TODO(), resolver, []Var{{"TOKEN", "gopass://Work/GitHub/password"}})
+
+		// then
+		assert.EqualError(t, err, "TOKEN: secretref: not found")
+	})
+}
+
+func TestMerge(t *testing.T) {
+	merged := Merge(
+		ParseEnviron([]string{"PATH=/bin", "TOKEN=old", "BROKEN"}),
+		[]Var{{"TOKEN", "new"}, {"EXTRA", "x"}},
+	)
+
+	assert.Equal(t, []Var{{"PATH", "/bin"}, {"TOKEN", "new"}, {"EXTRA", "x"}}, merged)
+	assert.Equal(t, []string{"PATH=/bin", "TOKEN=new", "EXTRA=x"}, Environ(merged))
+}
+
+func TestMasker(t *testing.T) {
+	testCases := []struct {
+		name     string
+		secrets  []string
+		writes   []string
+		expected string
+	}{
+		{"single write", []string{"s3cret"}, []string{"the password is s3cret!\n"}, "the password is <concealed by gopass>!\n"},
+		{"split across writes", []string{"s3cret"}, []string{"pass: s3", "c", "ret\n"}, "pass: <concealed by gopass>\n"},
+		{"prefix is not a secret", []string{"s3cret"}, []string{"s3c", "ond s3"}, "s3cond s3"},
+		{"repeated", []string{"ab"}, []string{"abab", "a", "b"}, strings.Repeat(Concealed, 3)},
+		{"longest first", []string{"key", "keychain"}, []string{"keychain key"}, Concealed + " " + Concealed},
+		{"empty secrets are ignored", []string{""}, []string{"output"}, "output"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			var buf bytes.Buffer
+			m := NewMasker(&buf, tc.secrets)
+
+			// when
+			for _, w := range tc.writes {
+				n, err := m.Write([]byte(w))
+				assert.Nil(t, err)
+				assert.Equal(t, len(w), n)
+			}
+
+			assert.Nil(t, m.Flush())
+
+			// then
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	t.Run("writes through what can't be a secret", func(t *testing.T) {
+		// given
+		var buf bytes.Buffer
+		m := NewMasker(&buf, []string{"s3cret"})
+
+		// when
+		m.Write([]byte("Enter code: s"))
+
+		// then
+		assert.Equal(t, "Enter code: ", buf.String())
+	})
+}
+
+func TestRunner(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	env := []Var{{"DB_PASSWORD", "gopass://Work/Postgres/password"}, {"NAME", "app"}}
+	resolver := mapResolver{"gopass://Work/Postgres/password": "pg-secret"}
+
+	t.Run("masks secrets", func(t *testing.T) {
+		// given
+		var stdout, stderr bytes.Buffer
+		runner := &Runner{Resolver: resolver, Stdout: &stdout, Stderr: &stderr}
+
+		// when
+		err := runner.Run(context.TODO(), env, "sh", "-c", `echo "$NAME $DB_PASSWORD"; echo "$DB_PASSWORD" >&2`)
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, "app <concealed by gopass>\n", stdout.String())
+		assert.Equal(t, "<concealed by gopass>\n", stderr.String())
+	})
+
+	t.Run("no masking", func(t *testing.T) {
+		// given
+		var stdout bytes.Buffer
+		runner := &Runner{Resolver: resolver, Stdout: &stdout, NoMasking: true}
+
+		// when
+		err := runner.Run(context.TODO(), env, "sh", "-c", `echo "$DB_PASSWORD"`)
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, "pg-secret\n", stdout.String())
+	})
+
+	t.Run("exit status", func(t *testing.T) {
+		// given
+		runner := &Runner{Resolver: resolver}
+
+		// when
+		err := runner.Run(context.TODO(), env, "sh", "-c", "exit 3")
+
+		// then
+		var exitErr *exec.ExitError
+		assert.True(t, errors.As(err, &exitErr))
+		assert.Equal(t, 3, exitErr.ExitCode())
+	})
+
+	t.Run("unresolved reference", func(t *testing.T) {
+		// given
+		runner := &Runner{Resolver: mapResolver{}}
+
+		// when
+		err := runner.Run(context.TODO(), env, "sh", "-c", "echo should not run")
+
+		// then
+		assert.EqualError(t, err, "DB_PASSWORD: secretref: not found")
+	})
+}
@@ -0,0 +1,118 @@
+package inject
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Concealed replaces the secrets in masked output.
+const Concealed = "<concealed by gopass>"
+
+// Masker is a writer that replaces secrets with Concealed before writing to
+// the underlying writer.
+//
+// A secret may be split across writes, so output that could be the start of a
+// secret is held back until the next write tells whether it is. Flush must be
+// called once writing is done to write what is left.
+type Masker struct {
+	mu      sync.Mutex
+	w       io.Writer
+	secrets [][]byte
+	pending []byte
+}
+
+// NewMasker returns a masker for the given secrets. Empty secrets are ignored.
+func NewMasker(w io.Writer, secrets []string) *Masker {
+	if w == nil {
+		w = io.Discard
+	}
+
+	m := &Masker{w: w}
+
+	for _, s := range secrets {
+		if s != "" {
+			m.secrets = append(m.secrets, []byte(s))
+		}
+	}
+
+	// Longer secrets first, so a secret containing another one is concealed as a whole.
+	sort.Slice(m.secrets, func(i, j int) bool { return len(m.secrets[i]) > len(m.secrets[j]) })
+
+	return m
+}
+
+func (m *Masker) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pending = append(m.pending, p...)
+	out, rest := m.mask(m.pending, false)
+	m.pending = append(m.pending[:0], rest...)
+
+	if _, err := m.w.Write(out); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the output held back by Write.
+func (m *Masker) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out, _ := m.mask(m.pending, true)
+	m.pending = m.pending[:0]
+
+	_, err := m.w.Write(out)
+
+	return err
+}
+
+// mask returns data with the secrets concealed. Unless final, it stops at the
+// first byte from which the rest of data is the start of a secret, and returns
+// that rest to be masked along with the next write.
+func (m *Masker) mask(data []byte, final bool) ([]byte, []byte) {
+	out := make([]byte, 0, len(data))
+
+	for i := 0; i < len(data); {
+		if n := m.matchAt(data[i:]); n > 0 {
+			out = append(out, Concealed...)
+			i += n
+			continue
+		}
+
+		if !final && m.partialAt(data[i:]) {
+			return out, data[i:]
+		}
+
+		out = append(out, data[i])
+		i++
+	}
+
+	return out, nil
+}
+
+// matchAt returns the length of the secret data starts with, or zero.
+func (m *Masker) matchAt(data []byte) int {
+	for _, s := range m.secrets {
+		if bytes.HasPrefix(data, s) {
+			return len(s)
+		}
+	}
+
+	return 0
+}
+
+// partialAt tells whether data is the start of a secret.
+func (m *Masker) partialAt(data []byte) bool {
+	for _, s := range m.secrets {
+		if len(data) < len(s) && bytes.HasPrefix(s, data) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package inject
+
+import (
+	"context"
+	"io"
+	"os/exec"
+
+	"github.com/edgardjr92/gopass/pkg/secretref"
+)
+
+type Runner struct {
+	Resolver secretref.Resolver
+	Stdin    io.Reader
+	Stdout   io.Writer
+	Stderr   io.Writer
+	// NoMasking leaves the secrets in the output of the command as they are.
+	// Masking makes the command write to a pipe instead of Stdout and Stderr,
+	// so it can't tell when they are a terminal.
+	NoMasking bool
+}
+
+// Run resolves the references in env and runs the command with env as its environment.
+// When the command exits with a non-zero status, the error is an *exec.ExitError.
+//
+// ctx only bounds the resolution of the references. The command isn't killed
+// when ctx is done, so it can handle interrupts from the terminal by itself.
+func (r *Runner) Run(ctx context.Context, env []Var, name string, args ...string) error {
+	resolved, secrets, err := Resolve(ctx, r.Resolver, env)
+
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(name, args...)
+	cmd.Env = Environ(resolved)
+	cmd.Stdin = r.Stdin
+	cmd.Stdout = r.Stdout
+	cmd.Stderr = r.Stderr
+
+	if r.NoMasking || len(secrets) == 0 {
+		return cmd.Run()
+	}
+
+	stdout := NewMasker(r.Stdout, secrets)
+	stderr := NewMasker(r.Stderr, secrets)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+
+	if flushErr := stdout.Flush(); err == nil {
+		err = flushErr
+	}
+
+	if flushErr := stderr.Flush(); err == nil {
+		err = flushErr
+	}
+
+	return err
+}
@@ -1,17 +1,27 @@
 package jwt
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ErrTokenRevoked is returned by Validate for the tokens issued to a user
+// before their session version was bumped.
+var ErrTokenRevoked = errors.New("token has been revoked")
+
 type JWTGenerator interface {
-	Generate(userID uint, exp time.Time) (string, error)
+	Generate(userID, sessionVersion uint, exp time.Time) (string, error)
 }
 
+// SessionVersion returns the current session version of a user. Bumping it
+// revokes every token issued to the user before.
+type SessionVersion func(userID uint) (uint, error)
+
 type jwtGo struct {
-	secret []byte
+	secret         []byte
+	sessionVersion SessionVersion
 }
 
 func NewJWTService(secret []byte) JWTGenerator {
@@ -23,16 +33,18 @@ func NewJWTService(secret []byte) JWTGenerator {
 // token string can be used to authenticate the user in subsequent requests.
 //
 // userID: the user ID to include in the JWT token claims.
+// sessionVersion: the session version of the user, see SessionVersion.
 // exp: the expiration time of the JWT token.
 //
 // Returns the JWT token string or an error if the token could not be generated.
-func (j jwtGo) Generate(userID uint, exp time.Time) (string, error) {
+func (j jwtGo) Generate(userID, sessionVersion uint, exp time.Time) (string, error) {
 	if exp.IsZero() {
 		exp = time.Now().Add(24 * time.Hour)
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": userID,
+		"ver":     sessionVersion,
 		"exp":     exp.Unix(),
 	})
 
@@ -44,3 +56,66 @@ func (j jwtGo) Generate(userID uint, exp time.Time) (string, error) {
 
 	return tokenStr, nil
 }
+
+type JWTValidator interface {
+	Validate(token string) (uint, error)
+}
+
+func NewJWTValidator(secret []byte) JWTValidator {
+	return &jwtGo{secret: secret}
+}
+
+// NewSessionValidator returns a validator that also rejects the tokens whose
+// session version isn't the current one of their user, as returned by sessionVersion.
+func NewSessionValidator(secret []byte, sessionVersion SessionVersion) JWTValidator {
+	return &jwtGo{secret: secret, sessionVersion: sessionVersion}
+}
+
+// Validate validates a JWT token generated by Generate.
+// It checks the HMAC-SHA256 signature and the expiration time of the token,
+// and its session version when the validator was built with one.
+//
+// tokenStr: the JWT token string.
+//
+// Returns the user ID from the token claims or an error if the token is invalid.
+func (j jwtGo) Validate(tokenStr string) (uint, error) {
+	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
+		return j.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+
+	if err != nil {
+		return 0, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+
+	if !ok {
+		return 0, jwt.ErrTokenInvalidClaims
+	}
+
+	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil {
+		return 0, jwt.ErrTokenRequiredClaimMissing
+	}
+
+	userID, ok := claims["user_id"].(float64)
+
+	if !ok || userID <= 0 {
+		return 0, jwt.ErrTokenInvalidClaims
+	}
+
+	if j.sessionVersion != nil {
+		// Tokens issued before session versions carry none, which is version 0.
+		version, _ := claims["ver"].(float64)
+		current, err := j.sessionVersion(uint(userID))
+
+		if err != nil {
+			return 0, err
+		}
+
+		if uint(version) != current {
+			return 0, ErrTokenRevoked
+		}
+	}
+
+	return uint(userID), nil
+}
@@ -0,0 +1,48 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	secret := []byte("secret")
+	exp := time.Now().Add(time.Hour)
+	token, err := NewJWTService(secret).Generate(10, 2, exp)
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		name      string
+		validator JWTValidator
+		token     string
+		expected  uint
+		err       error
+	}{
+		{"valid", NewJWTValidator(secret), token, 10, nil},
+		{"current session", NewSessionValidator(secret, func(userID uint) (uint, error) { return 2, nil }), token, 10, nil},
+		{"revoked session", NewSessionValidator(secret, func(userID uint) (uint, error) { return 3, nil }), token, 0, ErrTokenRevoked},
+		{"session lookup fails", NewSessionValidator(secret, func(userID uint) (uint, error) { return 0, errors.New("db down") }), token, 0, errors.New("db down")},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			actual, error := tc.validator.Validate(tc.token)
+
+			// then
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.err, error)
+		})
+	}
+
+	t.Run("wrong secret", func(t *testing.T) {
+		// when
+		_, error := NewJWTValidator([]byte("other")).Validate(token)
+
+		// then
+		assert.NotNil(t, error)
+	})
+}
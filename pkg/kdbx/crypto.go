@@ -0,0 +1,324 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20"
+)
+
+// Cipher is the algorithm the database payload is encrypted with.
+type Cipher int
+
+const (
+	AES256 Cipher = iota
+	ChaCha20
+)
+
+// KDF is the algorithm the composite key is transformed with.
+type KDF int
+
+const (
+	Argon2d KDF = iota
+	Argon2id
+	AESKDF
+)
+
+var (
+	cipherAES256   = [16]byte{0x31, 0xc1, 0xf2, 0xe6, 0xbf, 0x71, 0x43, 0x50, 0xbe, 0x58, 0x05, 0x21, 0x6a, 0xfc, 0x5a, 0xff}
+	cipherChaCha20 = [16]byte{0xd6, 0x03, 0x8a, 0x2b, 0x8b, 0x6f, 0x4c, 0xb5, 0xa5, 0x24, 0x33, 0x9a, 0x31, 0xdb, 0xb5, 0x9a}
+
+	kdfArgon2d  = [16]byte{0xef, 0x63, 0x6d, 0xdf, 0x8c, 0x29, 0x44, 0x4b, 0x91, 0xf7, 0xa9, 0xa4, 0x03, 0xe3, 0x0a, 0x0c}
+	kdfArgon2id = [16]byte{0x9e, 0x29, 0x8b, 0x19, 0x56, 0xdb, 0x47, 0x73, 0xb2, 0x3d, 0xfc, 0x3e, 0xc6, 0xf0, 0xa1, 0xe6}
+	kdfAES      = [16]byte{0xc9, 0xd9, 0xf3, 0x9a, 0x62, 0x8a, 0x44, 0x60, 0xbf, 0x74, 0x0d, 0x08, 0xc1, 0x8a, 0x4f, 0xea}
+)
+
+// blockSize is the size of the HMAC blocks the encrypted payload is split into.
+const blockSize = 1 << 20
+
+// Limits on the parameters read from a database, which come from an untrusted
+// file: they bound the memory and the time it takes to open one. They are
+// well above the defaults of KeePass and KeePassXC.
+const (
+	// MaxArgon2Memory is the largest Argon2 memory cost, in bytes.
+	MaxArgon2Memory = 1 << 30
+	// MaxArgon2Iterations is the largest number of Argon2 passes.
+	MaxArgon2Iterations = 100
+	// MaxArgon2Parallelism is the largest number of Argon2 lanes.
+	MaxArgon2Parallelism = 16
+	// MaxAESRounds is the largest number of AES-KDF rounds.
+	MaxAESRounds = 100_000_000
+	// maxBlockSize is the largest HMAC block accepted, KeePass writes blocks of blockSize.
+	maxBlockSize = 16 << 20
+	// maxPayloadSize is the largest decompressed payload accepted.
+	maxPayloadSize = 512 << 20
+)
+
+// transformKey derives the transformed key from the composite key with the
+// KDF described by the header parameters.
+func transformKey(composite []byte, params variantDictionary) ([]byte, error) {
+	uuid, _ := params.bytes("$UUID")
+
+	switch {
+	case bytes.Equal(uuid, kdfArgon2d[:]), bytes.Equal(uuid, kdfArgon2id[:]):
+		salt, ok1 := params.bytes("S")
+		parallelism, ok2 := params.uint32("P")
+		memory, ok3 := params.uint64("M")
+		iterations, ok4 := params.uint64("I")
+		version, ok5 := params.uint32("V")
+
+		if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 {
+			return nil, errors.New("kdbx: missing argon2 parameters")
+		}
+
+		if version != argon2Version {
+			return nil, fmt.Errorf("kdbx: unsupported argon2 version 0x%x", version)
+		}
+
+		if parallelism == 0 || iterations == 0 || memory/1024 == 0 {
+			return nil, errors.New("kdbx: invalid argon2 parameters")
+		}
+
+		if parallelism > MaxArgon2Parallelism || iterations > MaxArgon2Iterations || memory > MaxArgon2Memory {
+			return nil, ErrKDFLimits
+		}
+
+		secret, _ := params.bytes("K")
+		data, _ := params.bytes("A")
+		kib := uint32(memory / 1024)
+
+		if bytes.Equal(uuid, kdfArgon2id[:]) && len(secret) == 0 && len(data) == 0 {
+			return argon2.IDKey(composite, salt, uint32(iterations), kib, uint8(parallelism), 32), nil
+		}
+
+		mode := argon2d
+		if bytes.Equal(uuid, kdfArgon2id[:]) {
+			mode = argon2id
+		}
+
+		return argon2Key(mode, composite, salt, secret, data, uint32(iterations), kib, parallelism, 32), nil
+
+	case bytes.Equal(uuid, kdfAES[:]):
+		seed, ok1 := params.bytes("S")
+		rounds, ok2 := params.uint64("R")
+
+		if !ok1 || !ok2 || len(seed) != 32 {
+			return nil, errors.New("kdbx: missing aes-kdf parameters")
+		}
+
+		if rounds > MaxAESRounds {
+			return nil, ErrKDFLimits
+		}
+
+		block, err := aes.NewCipher(seed)
+
+		if err != nil {
+			return nil, err
+		}
+
+		key := append([]byte{}, composite...)
+		for i := uint64(0); i < rounds; i++ {
+			block.Encrypt(key[:16], key[:16])
+			block.Encrypt(key[16:], key[16:])
+		}
+
+		sum := sha256.Sum256(key)
+
+		return sum[:], nil
+	}
+
+	return nil, errors.New("kdbx: unsupported key derivation function")
+}
+
+// blockHMACKey returns the key used to authenticate the block with the given index.
+// The header is authenticated as block math.MaxUint64.
+func blockHMACKey(hmacKey []byte, index uint64) []byte {
+	h := sha512.New()
+	binary.Write(h, binary.LittleEndian, index)
+	h.Write(hmacKey)
+	return h.Sum(nil)
+}
+
+func blockHMAC(hmacKey []byte, index uint64, data []byte) []byte {
+	mac := hmac.New(sha256.New, blockHMACKey(hmacKey, index))
+	binary.Write(mac, binary.LittleEndian, index)
+	binary.Write(mac, binary.LittleEndian, int32(len(data)))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// readBlocks reads and authenticates the HMAC block stream.
+func readBlocks(r io.Reader, hmacKey []byte) ([]byte, error) {
+	payload := &bytes.Buffer{}
+
+	for index := uint64(0); ; index++ {
+		var header [sha256.Size + 4]byte
+
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return nil, ErrCorrupted
+		}
+
+		size := int32(binary.LittleEndian.Uint32(header[sha256.Size:]))
+
+		// The size is only authenticated along with the block, so it's
+		// bounded before the block is read.
+		if size < 0 || size > maxBlockSize {
+			return nil, ErrCorrupted
+		}
+
+		data := make([]byte, size)
+
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, ErrCorrupted
+		}
+
+		if !hmac.Equal(header[:sha256.Size], blockHMAC(hmacKey, index, data)) {
+			return nil, ErrCorrupted
+		}
+
+		if size == 0 {
+			return payload.Bytes(), nil
+		}
+
+		payload.Write(data)
+	}
+}
+
+// writeBlocks writes data as an HMAC block stream.
+func writeBlocks(w io.Writer, hmacKey []byte, data []byte) error {
+	index := uint64(0)
+
+	for {
+		n := len(data)
+		if n > blockSize {
+			n = blockSize
+		}
+
+		if _, err := w.Write(blockHMAC(hmacKey, index, data[:n])); err != nil {
+			return err
+		}
+
+		if err := binary.Write(w, binary.LittleEndian, int32(n)); err != nil {
+			return err
+		}
+
+		if _, err := w.Write(data[:n]); err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return nil
+		}
+
+		data = data[n:]
+		index++
+	}
+}
+
+func decrypt(cipherID []byte, key, iv, data []byte) ([]byte, error) {
+	switch {
+	case bytes.Equal(cipherID, cipherAES256[:]):
+		block, err := aes.NewCipher(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(iv) != aes.BlockSize || len(data) == 0 || len(data)%aes.BlockSize != 0 {
+			return nil, ErrCorrupted
+		}
+
+		out := make([]byte, len(data))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+
+		padding := int(out[len(out)-1])
+		if padding == 0 || padding > aes.BlockSize {
+			return nil, ErrCorrupted
+		}
+
+		return out[:len(out)-padding], nil
+
+	case bytes.Equal(cipherID, cipherChaCha20[:]):
+		stream, err := chacha20.NewUnauthenticatedCipher(key, iv)
+
+		if err != nil {
+			return nil, ErrCorrupted
+		}
+
+		out := make([]byte, len(data))
+		stream.XORKeyStream(out, data)
+
+		return out, nil
+	}
+
+	return nil, errors.New("kdbx: unsupported cipher")
+}
+
+func encrypt(c Cipher, key, iv, data []byte) ([]byte, error) {
+	switch c {
+	case AES256:
+		block, err := aes.NewCipher(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		padding := aes.BlockSize - len(data)%aes.BlockSize
+		out := append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, out)
+
+		return out, nil
+
+	case ChaCha20:
+		stream, err := chacha20.NewUnauthenticatedCipher(key, iv)
+
+		if err != nil {
+			return nil, err
+		}
+
+		out := make([]byte, len(data))
+		stream.XORKeyStream(out, data)
+
+		return out, nil
+	}
+
+	return nil, errors.New("kdbx: unsupported cipher")
+}
+
+// innerStream decrypts and encrypts protected values of the XML payload.
+// KDBX 4 files use ChaCha20 keyed with the SHA-512 of the inner stream key.
+type innerStream struct {
+	stream *chacha20.Cipher
+}
+
+const innerStreamChaCha20 = 3
+
+func newInnerStream(id uint32, key []byte) (*innerStream, error) {
+	if id != innerStreamChaCha20 {
+		return nil, fmt.Errorf("kdbx: unsupported inner random stream %d", id)
+	}
+
+	sum := sha512.Sum512(key)
+	stream, err := chacha20.NewUnauthenticatedCipher(sum[:32], sum[32:44])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &innerStream{stream}, nil
+}
+
+func (s *innerStream) xor(data []byte) []byte {
+	out := make([]byte, len(data))
+	s.stream.XORKeyStream(out, data)
+	return out
+}
@@ -0,0 +1,479 @@
+// Package kdbx reads and writes KeePass databases in the KDBX 4 format.
+//
+// Databases are protected with a composite key made of a master password
+// and/or a key file, transformed with Argon2d, Argon2id or AES-KDF, and
+// encrypted with AES-256-CBC or ChaCha20. Protected values, such as passwords,
+// are additionally encrypted with the ChaCha20 inner random stream.
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	signature1 = 0x9AA2D903
+	signature2 = 0xB54BFB67
+
+	majorVersion = 4
+
+	compressionNone = 0
+	compressionGzip = 1
+)
+
+// Outer header field IDs.
+const (
+	headerEnd         = 0
+	headerCipherID    = 2
+	headerCompression = 3
+	headerMasterSeed  = 4
+	headerIV          = 7
+	headerKDF         = 11
+)
+
+// Inner header field IDs.
+const (
+	innerEnd       = 0
+	innerStreamID  = 1
+	innerStreamKey = 2
+)
+
+var (
+	// ErrInvalidSignature is returned when the data is not a KeePass database.
+	ErrInvalidSignature = errors.New("kdbx: not a keepass database")
+	// ErrUnsupportedVersion is returned for databases older than KDBX 4.
+	ErrUnsupportedVersion = errors.New("kdbx: only kdbx 4 databases are supported")
+	// ErrInvalidCredentials is returned when the key doesn't open the database.
+	ErrInvalidCredentials = errors.New("kdbx: invalid credentials")
+	// ErrCorrupted is returned when the database fails its integrity checks.
+	ErrCorrupted = errors.New("kdbx: database is corrupted")
+	// ErrKDFLimits is returned when the key derivation parameters of the
+	// database exceed MaxArgon2Memory, MaxArgon2Iterations,
+	// MaxArgon2Parallelism or MaxAESRounds.
+	ErrKDFLimits = errors.New("kdbx: key derivation parameters exceed the supported limits")
+)
+
+// UUID identifies groups and entries.
+type UUID [16]byte
+
+// NewUUID returns a random UUID.
+func NewUUID() UUID {
+	var uuid UUID
+	rand.Read(uuid[:])
+	return uuid
+}
+
+type Database struct {
+	Name string
+	Root Group
+	// RecycleBin is the UUID of the group holding deleted entries, or zero if there is none.
+	RecycleBin UUID
+}
+
+type Group struct {
+	UUID    UUID
+	Name    string
+	Entries []Entry
+	Groups  []Group
+}
+
+type Entry struct {
+	UUID     UUID
+	Title    string
+	UserName string
+	Password string
+	URL      string
+	Notes    string
+	// Fields holds the custom string fields of the entry.
+	Fields     []Field
+	Tags       []string
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+type Field struct {
+	Key       string
+	Value     string
+	Protected bool
+}
+
+// Settings control how a database is encrypted by Write.
+type Settings struct {
+	Cipher Cipher
+	KDF    KDF
+	// Iterations is the number of Argon2 passes, or of AES-KDF rounds.
+	Iterations uint64
+	// Memory is the Argon2 memory cost in bytes.
+	Memory uint64
+	// Parallelism is the number of Argon2 lanes.
+	Parallelism uint32
+	Compress    bool
+}
+
+// DefaultSettings returns AES-256 encryption with gzip compression and
+// Argon2id using 10 passes over 64 MiB in 2 lanes.
+func DefaultSettings() Settings {
+	return Settings{
+		Cipher:      AES256,
+		KDF:         Argon2id,
+		Iterations:  10,
+		Memory:      64 << 20,
+		Parallelism: 2,
+		Compress:    true,
+	}
+}
+
+type header struct {
+	raw         []byte
+	cipherID    []byte
+	compression uint32
+	masterSeed  []byte
+	iv          []byte
+	kdfParams   variantDictionary
+}
+
+// Read decrypts a KDBX 4 database.
+func Read(r io.Reader, key *Key) (*Database, error) {
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	h, rest, err := readHeader(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) < 2*sha256.Size {
+		return nil, ErrCorrupted
+	}
+
+	if sum := sha256.Sum256(h.raw); !bytes.Equal(sum[:], rest[:sha256.Size]) {
+		return nil, ErrCorrupted
+	}
+
+	transformed, err := transformKey(key.composite[:], h.kdfParams)
+
+	if err != nil {
+		return nil, err
+	}
+
+	encryptionKey, hmacKey := deriveKeys(h.masterSeed, transformed)
+
+	mac := hmac.New(sha256.New, blockHMACKey(hmacKey, math.MaxUint64))
+	mac.Write(h.raw)
+
+	if !hmac.Equal(mac.Sum(nil), rest[sha256.Size:2*sha256.Size]) {
+		return nil, ErrInvalidCredentials
+	}
+
+	payload, err := readBlocks(bytes.NewReader(rest[2*sha256.Size:]), hmacKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := decrypt(h.cipherID, encryptionKey, h.iv, payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if h.compression == compressionGzip {
+		zr, err := gzip.NewReader(bytes.NewReader(plain))
+
+		if err != nil {
+			return nil, ErrCorrupted
+		}
+
+		if plain, err = io.ReadAll(io.LimitReader(zr, maxPayloadSize+1)); err != nil || len(plain) > maxPayloadSize {
+			return nil, ErrCorrupted
+		}
+	}
+
+	stream, xmlData, err := readInnerHeader(plain)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeXML(xmlData, stream)
+}
+
+// Write encrypts a database in the KDBX 4 format.
+func Write(w io.Writer, db *Database, key *Key, settings Settings) error {
+	h, err := newHeader(settings)
+
+	if err != nil {
+		return err
+	}
+
+	transformed, err := transformKey(key.composite[:], h.kdfParams)
+
+	if err != nil {
+		return err
+	}
+
+	encryptionKey, hmacKey := deriveKeys(h.masterSeed, transformed)
+
+	streamKey := make([]byte, 64)
+	if _, err := rand.Read(streamKey); err != nil {
+		return err
+	}
+
+	stream, err := newInnerStream(innerStreamChaCha20, streamKey)
+
+	if err != nil {
+		return err
+	}
+
+	xmlData, err := encodeXML(db, stream)
+
+	if err != nil {
+		return err
+	}
+
+	plain := &bytes.Buffer{}
+	streamID := make([]byte, 4)
+	binary.LittleEndian.PutUint32(streamID, innerStreamChaCha20)
+	writeField(plain, innerStreamID, streamID)
+	writeField(plain, innerStreamKey, streamKey)
+	writeField(plain, innerEnd, nil)
+	plain.Write(xmlData)
+
+	payload := plain.Bytes()
+
+	if settings.Compress {
+		compressed := &bytes.Buffer{}
+		zw := gzip.NewWriter(compressed)
+		zw.Write(payload)
+
+		if err := zw.Close(); err != nil {
+			return err
+		}
+
+		payload = compressed.Bytes()
+	}
+
+	encrypted, err := encrypt(settings.Cipher, encryptionKey, h.iv, payload)
+
+	if err != nil {
+		return err
+	}
+
+	headerHash := sha256.Sum256(h.raw)
+	mac := hmac.New(sha256.New, blockHMACKey(hmacKey, math.MaxUint64))
+	mac.Write(h.raw)
+
+	out := &bytes.Buffer{}
+	out.Write(h.raw)
+	out.Write(headerHash[:])
+	out.Write(mac.Sum(nil))
+
+	if err := writeBlocks(out, hmacKey, encrypted); err != nil {
+		return err
+	}
+
+	_, err = w.Write(out.Bytes())
+
+	return err
+}
+
+func deriveKeys(masterSeed, transformed []byte) ([]byte, []byte) {
+	encryptionKey := sha256.Sum256(append(append([]byte{}, masterSeed...), transformed...))
+	hmacKey := sha512.Sum512(append(append(append([]byte{}, masterSeed...), transformed...), 0x01))
+
+	return encryptionKey[:], hmacKey[:]
+}
+
+func readHeader(data []byte) (*header, []byte, error) {
+	if len(data) < 12 ||
+		binary.LittleEndian.Uint32(data[0:4]) != signature1 ||
+		binary.LittleEndian.Uint32(data[4:8]) != signature2 {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	if binary.LittleEndian.Uint32(data[8:12])>>16 != majorVersion {
+		return nil, nil, ErrUnsupportedVersion
+	}
+
+	h := &header{}
+	offset := 12
+
+	for {
+		if len(data) < offset+5 {
+			return nil, nil, ErrCorrupted
+		}
+
+		id := data[offset]
+		size := int(binary.LittleEndian.Uint32(data[offset+1 : offset+5]))
+		offset += 5
+
+		if size < 0 || len(data) < offset+size {
+			return nil, nil, ErrCorrupted
+		}
+
+		value := data[offset : offset+size]
+		offset += size
+
+		switch id {
+		case headerEnd:
+			h.raw = data[:offset]
+
+			if h.cipherID == nil || h.masterSeed == nil || h.iv == nil || h.kdfParams == nil {
+				return nil, nil, ErrCorrupted
+			}
+
+			return h, data[offset:], nil
+		case headerCipherID:
+			h.cipherID = value
+		case headerCompression:
+			if size != 4 {
+				return nil, nil, ErrCorrupted
+			}
+			h.compression = binary.LittleEndian.Uint32(value)
+		case headerMasterSeed:
+			if size != 32 {
+				return nil, nil, ErrCorrupted
+			}
+			h.masterSeed = value
+		case headerIV:
+			h.iv = value
+		case headerKDF:
+			params, err := unmarshalVariantDictionary(value)
+
+			if err != nil {
+				return nil, nil, ErrCorrupted
+			}
+
+			h.kdfParams = params
+		}
+	}
+}
+
+func newHeader(settings Settings) (*header, error) {
+	h := &header{
+		masterSeed: make([]byte, 32),
+		kdfParams:  variantDictionary{},
+	}
+
+	switch settings.Cipher {
+	case AES256:
+		h.cipherID = cipherAES256[:]
+		h.iv = make([]byte, 16)
+	case ChaCha20:
+		h.cipherID = cipherChaCha20[:]
+		h.iv = make([]byte, 12)
+	default:
+		return nil, errors.New("kdbx: unsupported cipher")
+	}
+
+	salt := make([]byte, 32)
+
+	for _, b := range [][]byte{h.masterSeed, h.iv, salt} {
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+	}
+
+	switch settings.KDF {
+	case Argon2d, Argon2id:
+		uuid := kdfArgon2d
+		if settings.KDF == Argon2id {
+			uuid = kdfArgon2id
+		}
+
+		h.kdfParams.setBytes("$UUID", uuid[:])
+		h.kdfParams.setBytes("S", salt)
+		h.kdfParams.setUint32("P", settings.Parallelism)
+		h.kdfParams.setUint64("M", settings.Memory)
+		h.kdfParams.setUint64("I", settings.Iterations)
+		h.kdfParams.setUint32("V", argon2Version)
+	case AESKDF:
+		h.kdfParams.setBytes("$UUID", kdfAES[:])
+		h.kdfParams.setBytes("S", salt)
+		h.kdfParams.setUint64("R", settings.Iterations)
+	default:
+		return nil, errors.New("kdbx: unsupported key derivation function")
+	}
+
+	if settings.Compress {
+		h.compression = compressionGzip
+	}
+
+	raw := &bytes.Buffer{}
+	binary.Write(raw, binary.LittleEndian, uint32(signature1))
+	binary.Write(raw, binary.LittleEndian, uint32(signature2))
+	binary.Write(raw, binary.LittleEndian, uint32(majorVersion<<16))
+
+	compression := make([]byte, 4)
+	binary.LittleEndian.PutUint32(compression, h.compression)
+
+	writeField(raw, headerCipherID, h.cipherID)
+	writeField(raw, headerCompression, compression)
+	writeField(raw, headerMasterSeed, h.masterSeed)
+	writeField(raw, headerIV, h.iv)
+	writeField(raw, headerKDF, h.kdfParams.marshal())
+	writeField(raw, headerEnd, []byte("\r\n\r\n"))
+
+	h.raw = raw.Bytes()
+
+	return h, nil
+}
+
+func readInnerHeader(data []byte) (*innerStream, []byte, error) {
+	var streamID uint32
+	var streamKey []byte
+
+	for {
+		if len(data) < 5 {
+			return nil, nil, ErrCorrupted
+		}
+
+		id := data[0]
+		size := int(binary.LittleEndian.Uint32(data[1:5]))
+		data = data[5:]
+
+		if size < 0 || len(data) < size {
+			return nil, nil, ErrCorrupted
+		}
+
+		value := data[:size]
+		data = data[size:]
+
+		switch id {
+		case innerEnd:
+			stream, err := newInnerStream(streamID, streamKey)
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return stream, data, nil
+		case innerStreamID:
+			if size != 4 {
+				return nil, nil, ErrCorrupted
+			}
+			streamID = binary.LittleEndian.Uint32(value)
+		case innerStreamKey:
+			streamKey = value
+		}
+	}
+}
+
+func writeField(buf *bytes.Buffer, id byte, value []byte) {
+	buf.WriteByte(id)
+	binary.Write(buf, binary.LittleEndian, uint32(len(value)))
+	buf.Write(value)
+}
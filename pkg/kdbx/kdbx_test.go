@@ -0,0 +1,310 @@
+package kdbx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/argon2"
+)
+
+func testSettings(cipher Cipher, kdf KDF) Settings {
+	settings := Settings{Cipher: cipher, KDF: kdf, Iterations: 2, Memory: 64 << 10, Parallelism: 2, Compress: true}
+	if kdf == AESKDF {
+		settings.Iterations = 1000
+	}
+	return settings
+}
+
+func testDatabase() *Database {
+	created := time.Date(2023, 5, 6, 10, 0, 0, 0, time.UTC)
+	recycleBin := NewUUID()
+
+	return &Database{
+		Name:       "Team",
+		RecycleBin: recycleBin,
+		Root: Group{
+			UUID: NewUUID(),
+			Name: "Team",
+			Entries: []Entry{
+				{
+					UUID:       NewUUID(),
+					Title:      "GitHub",
+					UserName:   "octocat",
+					Password:   "s3cr3t-ü-ñ",
+					URL:        "https://github.com",
+					Notes:      "line 1\nline 2 <&>",
+					Fields:     []Field{{Key: "Recovery codes", Value: "1234 5678", Protected: true}, {Key: "Team", Value: "platform"}},
+					Tags:       []string{"work", "2fa"},
+					CreatedAt:  created,
+					ModifiedAt: created.Add(time.Hour),
+				},
+			},
+			Groups: []Group{
+				{
+					UUID: NewUUID(),
+					Name: "Servers",
+					Entries: []Entry{
+						{UUID: NewUUID(), Title: "db", UserName: "root", Password: "", CreatedAt: created, ModifiedAt: created},
+						{UUID: NewUUID(), Title: "cache", Password: "redis-pass", CreatedAt: created, ModifiedAt: created},
+					},
+				},
+				{UUID: recycleBin, Name: "Recycle Bin"},
+			},
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cipher Cipher
+		kdf    KDF
+	}{
+		{"aes256 argon2d", AES256, Argon2d},
+		{"aes256 argon2id", AES256, Argon2id},
+		{"chacha20 argon2id", ChaCha20, Argon2id},
+		{"chacha20 aes-kdf", ChaCha20, AESKDF},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			db := testDatabase()
+			key := NewPasswordKey("correct horse battery staple")
+			buf := &bytes.Buffer{}
+
+			// when
+			err := Write(buf, db, key, testSettings(tc.cipher, tc.kdf))
+			assert.Nil(t, err)
+
+			actual, err := Read(bytes.NewReader(buf.Bytes()), key)
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, db, actual)
+			assert.NotContains(t, buf.String(), "GitHub")
+		})
+	}
+
+	t.Run("uncompressed", func(t *testing.T) {
+		// given
+		db := testDatabase()
+		key := NewPasswordKey("password")
+		settings := testSettings(AES256, Argon2id)
+		settings.Compress = false
+		buf := &bytes.Buffer{}
+
+		// when
+		assert.Nil(t, Write(buf, db, key, settings))
+		actual, err := Read(buf, key)
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, db, actual)
+	})
+}
+
+func TestReadErrors(t *testing.T) {
+	db := testDatabase()
+	key := NewPasswordKey("password")
+	buf := &bytes.Buffer{}
+	assert.Nil(t, Write(buf, db, key, testSettings(ChaCha20, Argon2id)))
+	data := buf.Bytes()
+
+	t.Run("wrong password", func(t *testing.T) {
+		actual, err := Read(bytes.NewReader(data), NewPasswordKey("wrong"))
+
+		assert.Nil(t, actual)
+		assert.Equal(t, ErrInvalidCredentials, err)
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		tampered := append([]byte{}, data...)
+		tampered[len(tampered)-50] ^= 0x01
+
+		actual, err := Read(bytes.NewReader(tampered), key)
+
+		assert.Nil(t, actual)
+		assert.Equal(t, ErrCorrupted, err)
+	})
+
+	t.Run("tampered header", func(t *testing.T) {
+		tampered := append([]byte{}, data...)
+		tampered[20] ^= 0x01
+
+		actual, err := Read(bytes.NewReader(tampered), key)
+
+		assert.Nil(t, actual)
+		assert.Equal(t, ErrCorrupted, err)
+	})
+
+	t.Run("not a database", func(t *testing.T) {
+		actual, err := Read(bytes.NewReader([]byte("<KeePassFile/>")), key)
+
+		assert.Nil(t, actual)
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+
+	t.Run("kdbx 3", func(t *testing.T) {
+		old := append([]byte{}, data[:12]...)
+		old[10] = 3
+
+		actual, err := Read(bytes.NewReader(old), key)
+
+		assert.Nil(t, actual)
+		assert.Equal(t, ErrUnsupportedVersion, err)
+	})
+}
+
+func TestKeyFiles(t *testing.T) {
+	raw := bytes.Repeat([]byte{0xAB}, 32)
+
+	testCases := []struct {
+		name    string
+		keyFile []byte
+	}{
+		{"binary", raw},
+		{"hex", []byte(hex.EncodeToString(raw))},
+		{"xml v1", []byte(`<?xml version="1.0" encoding="utf-8"?><KeyFile><Meta><Version>1.00</Version></Meta><Key><Data>q6urq6urq6urq6urq6urq6urq6urq6urq6urq6urq6s=</Data></Key></KeyFile>`)},
+		{"xml v2", []byte(`<?xml version="1.0" encoding="utf-8"?><KeyFile><Meta><Version>2.0</Version></Meta><Key><Data Hash="9A2DB2E2">ABABABAB ABABABAB ABABABAB ABABABAB
+			ABABABAB ABABABAB ABABABAB ABABABAB</Data></Key></KeyFile>`)},
+	}
+
+	expected, err := NewKey("password", raw)
+	assert.Nil(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := NewKey("password", tc.keyFile)
+
+			assert.Nil(t, err)
+			assert.Equal(t, expected, key)
+		})
+	}
+
+	t.Run("hashed", func(t *testing.T) {
+		key, err := NewKey("", []byte("any file works as a key file"))
+
+		assert.Nil(t, err)
+		assert.NotEqual(t, expected, key)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		key, err := NewKey("", []byte(`<KeyFile><Meta><Version>2.0</Version></Meta><Key><Data Hash="00000000">`+hex.EncodeToString(raw)+`</Data></Key></KeyFile>`))
+
+		assert.Nil(t, key)
+		assert.Equal(t, "kdbx: key file checksum mismatch", err.Error())
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		key, err := NewKey("", nil)
+
+		assert.Nil(t, key)
+		assert.Equal(t, "kdbx: a password or a key file is required", err.Error())
+	})
+}
+
+func TestArgon2(t *testing.T) {
+	t.Run("argon2d rfc 9106 test vector", func(t *testing.T) {
+		password := bytes.Repeat([]byte{0x01}, 32)
+		salt := bytes.Repeat([]byte{0x02}, 16)
+		secret := bytes.Repeat([]byte{0x03}, 8)
+		data := bytes.Repeat([]byte{0x04}, 12)
+
+		actual := argon2Key(argon2d, password, salt, secret, data, 3, 32, 4, 32)
+
+		assert.Equal(t, "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb", hex.EncodeToString(actual))
+	})
+
+	t.Run("argon2id matches x/crypto", func(t *testing.T) {
+		params := []struct{ time, memory, threads uint32 }{{1, 64, 1}, {3, 256, 4}, {2, 1024, 2}}
+
+		for _, p := range params {
+			actual := argon2Key(argon2id, []byte("password"), []byte("somesalt"), nil, nil, p.time, p.memory, p.threads, 32)
+			expected := argon2.IDKey([]byte("password"), []byte("somesalt"), p.time, p.memory, uint8(p.threads), 32)
+
+			assert.Equal(t, expected, actual)
+		}
+	})
+}
+
+func TestKDFLimits(t *testing.T) {
+	argon2Params := func(memory, iterations uint64, parallelism uint32) variantDictionary {
+		params := variantDictionary{}
+		params.setBytes("$UUID", kdfArgon2id[:])
+		params.setBytes("S", bytes.Repeat([]byte{0x01}, 32))
+		params.setUint32("P", parallelism)
+		params.setUint64("M", memory)
+		params.setUint64("I", iterations)
+		params.setUint32("V", argon2Version)
+		return params
+	}
+
+	aesParams := func(rounds uint64) variantDictionary {
+		params := variantDictionary{}
+		params.setBytes("$UUID", kdfAES[:])
+		params.setBytes("S", bytes.Repeat([]byte{0x01}, 32))
+		params.setUint64("R", rounds)
+		return params
+	}
+
+	testCases := []struct {
+		name   string
+		params variantDictionary
+	}{
+		{"argon2 memory", argon2Params(MaxArgon2Memory+1024, 2, 2)},
+		{"argon2 memory overflowing uint32 kib", argon2Params(1<<50, 2, 2)},
+		{"argon2 iterations", argon2Params(64<<10, MaxArgon2Iterations+1, 2)},
+		{"argon2 parallelism", argon2Params(64<<10, 2, MaxArgon2Parallelism+1)},
+		{"aes rounds", aesParams(MaxAESRounds + 1)},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := transformKey([]byte("composite"), tc.params)
+
+			assert.Nil(t, actual)
+			assert.Equal(t, ErrKDFLimits, err)
+		})
+	}
+
+	t.Run("within limits", func(t *testing.T) {
+		actual, err := transformKey([]byte("composite"), argon2Params(64<<10, 2, 2))
+
+		assert.Nil(t, err)
+		assert.Len(t, actual, 32)
+	})
+}
+
+func TestReadBlocksLimits(t *testing.T) {
+	t.Run("oversized block", func(t *testing.T) {
+		// given
+		header := make([]byte, 32+4)
+		binary.LittleEndian.PutUint32(header[32:], maxBlockSize+1)
+
+		// when
+		actual, err := readBlocks(bytes.NewReader(header), []byte("key"))
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, ErrCorrupted, err)
+	})
+
+	t.Run("negative block size", func(t *testing.T) {
+		// given
+		header := make([]byte, 32+4)
+		binary.LittleEndian.PutUint32(header[32:], 0xffffffff)
+
+		// when
+		actual, err := readBlocks(bytes.NewReader(header), []byte("key"))
+
+		// then
+		assert.Nil(t, actual)
+		assert.Equal(t, ErrCorrupted, err)
+	})
+}
@@ -0,0 +1,120 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"strings"
+)
+
+// Key holds the credentials a database is protected with:
+// a master password, a key file or both.
+type Key struct {
+	composite [sha256.Size]byte
+}
+
+// NewPasswordKey creates a key from a master password.
+func NewPasswordKey(password string) *Key {
+	key, _ := NewKey(password, nil)
+	return key
+}
+
+// NewKey creates a key from a master password and the contents of a KeePass key file.
+// Either can be empty, but not both. Key files can be in the XML formats
+// (versions 1.0 and 2.0), 32 raw bytes, 64 hexadecimal characters or any other
+// file, which is hashed.
+func NewKey(password string, keyFile []byte) (*Key, error) {
+	if password == "" && len(keyFile) == 0 {
+		return nil, errors.New("kdbx: a password or a key file is required")
+	}
+
+	h := sha256.New()
+
+	if password != "" {
+		sum := sha256.Sum256([]byte(password))
+		h.Write(sum[:])
+	}
+
+	if len(keyFile) > 0 {
+		fileKey, err := keyFileKey(keyFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		h.Write(fileKey)
+	}
+
+	key := &Key{}
+	h.Sum(key.composite[:0])
+
+	return key, nil
+}
+
+func keyFileKey(data []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<KeyFile")) {
+		return xmlKeyFileKey(trimmed)
+	}
+
+	if len(data) == 32 {
+		return data, nil
+	}
+
+	if len(data) == 64 {
+		if key, err := hex.DecodeString(string(data)); err == nil {
+			return key, nil
+		}
+	}
+
+	sum := sha256.Sum256(data)
+
+	return sum[:], nil
+}
+
+func xmlKeyFileKey(data []byte) ([]byte, error) {
+	var file struct {
+		Meta struct {
+			Version string `xml:"Version"`
+		} `xml:"Meta"`
+		Key struct {
+			Data struct {
+				Hash string `xml:"Hash,attr"`
+				Text string `xml:",chardata"`
+			} `xml:"Data"`
+		} `xml:"Key"`
+	}
+
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, errors.New("kdbx: invalid key file")
+	}
+
+	text := strings.Join(strings.Fields(file.Key.Data.Text), "")
+
+	if strings.HasPrefix(file.Meta.Version, "2.") {
+		key, err := hex.DecodeString(text)
+
+		if err != nil || len(key) != 32 {
+			return nil, errors.New("kdbx: invalid key file")
+		}
+
+		sum := sha256.Sum256(key)
+		if file.Key.Data.Hash != "" && !strings.EqualFold(hex.EncodeToString(sum[:4]), file.Key.Data.Hash) {
+			return nil, errors.New("kdbx: key file checksum mismatch")
+		}
+
+		return key, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(text)
+
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("kdbx: invalid key file")
+	}
+
+	return key, nil
+}
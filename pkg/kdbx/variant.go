@@ -0,0 +1,137 @@
+package kdbx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// Value types of a KDBX variant dictionary.
+const (
+	variantEnd       = 0x00
+	variantUInt32    = 0x04
+	variantUInt64    = 0x05
+	variantBool      = 0x08
+	variantInt32     = 0x0C
+	variantInt64     = 0x0D
+	variantString    = 0x18
+	variantByteArray = 0x42
+
+	variantVersion = 0x0100
+)
+
+// variantDictionary is the typed key/value map KDBX 4 uses for KDF parameters.
+type variantDictionary map[string]variantValue
+
+type variantValue struct {
+	kind  byte
+	value []byte
+}
+
+func (d variantDictionary) setUint32(key string, v uint32) {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	d[key] = variantValue{variantUInt32, b}
+}
+
+func (d variantDictionary) setUint64(key string, v uint64) {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, v)
+	d[key] = variantValue{variantUInt64, b}
+}
+
+func (d variantDictionary) setBytes(key string, v []byte) {
+	d[key] = variantValue{variantByteArray, v}
+}
+
+func (d variantDictionary) uint32(key string) (uint32, bool) {
+	v, ok := d[key]
+	if !ok || v.kind != variantUInt32 || len(v.value) != 4 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(v.value), true
+}
+
+func (d variantDictionary) uint64(key string) (uint64, bool) {
+	v, ok := d[key]
+	if !ok || v.kind != variantUInt64 || len(v.value) != 8 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(v.value), true
+}
+
+func (d variantDictionary) bytes(key string) ([]byte, bool) {
+	v, ok := d[key]
+	if !ok || v.kind != variantByteArray {
+		return nil, false
+	}
+	return v.value, true
+}
+
+func (d variantDictionary) marshal() []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, uint16(variantVersion))
+
+	keys := make([]string, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		v := d[key]
+		buf.WriteByte(v.kind)
+		binary.Write(buf, binary.LittleEndian, int32(len(key)))
+		buf.WriteString(key)
+		binary.Write(buf, binary.LittleEndian, int32(len(v.value)))
+		buf.Write(v.value)
+	}
+
+	buf.WriteByte(variantEnd)
+
+	return buf.Bytes()
+}
+
+func unmarshalVariantDictionary(data []byte) (variantDictionary, error) {
+	errInvalid := errors.New("invalid variant dictionary")
+
+	if len(data) < 2 || binary.LittleEndian.Uint16(data)&0xFF00 != variantVersion&0xFF00 {
+		return nil, errInvalid
+	}
+	data = data[2:]
+
+	d := variantDictionary{}
+
+	for {
+		if len(data) < 1 {
+			return nil, errInvalid
+		}
+
+		kind := data[0]
+		data = data[1:]
+
+		if kind == variantEnd {
+			return d, nil
+		}
+
+		var fields [2][]byte
+		for i := range fields {
+			if len(data) < 4 {
+				return nil, errInvalid
+			}
+
+			n := int(int32(binary.LittleEndian.Uint32(data)))
+			data = data[4:]
+
+			if n < 0 || len(data) < n {
+				return nil, errInvalid
+			}
+
+			fields[i] = data[:n]
+			data = data[n:]
+		}
+
+		d[string(fields[0])] = variantValue{kind, fields[1]}
+	}
+}
@@ -0,0 +1,363 @@
+package kdbx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+const generator = "gopass"
+
+// epoch is the number of seconds between 0001-01-01 and the Unix epoch.
+// KDBX 4 stores times as seconds since 0001-01-01.
+const epoch = 62135596800
+
+type xmlFile struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    xmlMeta  `xml:"Meta"`
+	Root    xmlRoot  `xml:"Root"`
+}
+
+type xmlMeta struct {
+	Generator         string `xml:"Generator"`
+	DatabaseName      string `xml:"DatabaseName"`
+	RecycleBinEnabled string `xml:"RecycleBinEnabled"`
+	RecycleBinUUID    string `xml:"RecycleBinUUID"`
+}
+
+type xmlRoot struct {
+	Group          xmlGroup `xml:"Group"`
+	DeletedObjects string   `xml:"DeletedObjects"`
+}
+
+type xmlGroup struct {
+	UUID    string     `xml:"UUID"`
+	Name    string     `xml:"Name"`
+	Times   xmlTimes   `xml:"Times"`
+	Entries []xmlEntry `xml:"Entry"`
+	Groups  []xmlGroup `xml:"Group"`
+}
+
+type xmlEntry struct {
+	UUID    string      `xml:"UUID"`
+	Tags    string      `xml:"Tags,omitempty"`
+	Times   xmlTimes    `xml:"Times"`
+	Strings []xmlString `xml:"String"`
+}
+
+type xmlTimes struct {
+	CreationTime         string `xml:"CreationTime"`
+	LastModificationTime string `xml:"LastModificationTime"`
+	LastAccessTime       string `xml:"LastAccessTime"`
+	ExpiryTime           string `xml:"ExpiryTime"`
+	Expires              string `xml:"Expires"`
+	UsageCount           int    `xml:"UsageCount"`
+	LocationChanged      string `xml:"LocationChanged"`
+}
+
+type xmlString struct {
+	Key   string   `xml:"Key"`
+	Value xmlValue `xml:"Value"`
+}
+
+type xmlValue struct {
+	Protected       string `xml:"Protected,attr,omitempty"`
+	ProtectInMemory string `xml:"ProtectInMemory,attr,omitempty"`
+	Text            string `xml:",chardata"`
+}
+
+func decodeXML(data []byte, stream *innerStream) (*Database, error) {
+	data, err := unprotect(data, stream)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var file xmlFile
+
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, ErrCorrupted
+	}
+
+	db := &Database{
+		Name:       file.Meta.DatabaseName,
+		Root:       file.Root.Group.group(),
+		RecycleBin: decodeUUID(file.Meta.RecycleBinUUID),
+	}
+
+	return db, nil
+}
+
+// unprotect rewrites the XML payload with its protected values decrypted.
+// Protected values are encrypted with a single inner stream in document
+// order, so they have to be processed in a single pass over the tokens.
+// Decrypted values are marked with the ProtectInMemory attribute instead.
+func unprotect(data []byte, stream *innerStream) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	out := &bytes.Buffer{}
+	encoder := xml.NewEncoder(out)
+
+	protected := false
+	value := &strings.Builder{}
+
+	for {
+		token, err := decoder.Token()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, ErrCorrupted
+		}
+
+		switch t := token.(type) {
+		case xml.ProcInst:
+			continue
+		case xml.StartElement:
+			protected = false
+
+			if t.Name.Local == "Value" {
+				attrs := make([]xml.Attr, 0, len(t.Attr))
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "Protected" {
+						protected = strings.EqualFold(attr.Value, "True")
+						continue
+					}
+					attrs = append(attrs, attr)
+				}
+
+				if protected {
+					attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "ProtectInMemory"}, Value: "True"})
+					value.Reset()
+				}
+
+				t.Attr = attrs
+			}
+
+			token = t
+		case xml.CharData:
+			if protected {
+				value.Write(t)
+				continue
+			}
+		case xml.EndElement:
+			if protected {
+				ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value.String()))
+
+				if err != nil {
+					return nil, ErrCorrupted
+				}
+
+				if err := encoder.EncodeToken(xml.CharData(stream.xor(ciphertext))); err != nil {
+					return nil, ErrCorrupted
+				}
+
+				protected = false
+			}
+		}
+
+		if err := encoder.EncodeToken(xml.CopyToken(token)); err != nil {
+			return nil, ErrCorrupted
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return nil, ErrCorrupted
+	}
+
+	return out.Bytes(), nil
+}
+
+func encodeXML(db *Database, stream *innerStream) ([]byte, error) {
+	file := xmlFile{
+		Meta: xmlMeta{
+			Generator:         generator,
+			DatabaseName:      db.Name,
+			RecycleBinEnabled: "False",
+		},
+		Root: xmlRoot{Group: newXMLGroup(db.Root, stream)},
+	}
+
+	if db.RecycleBin != (UUID{}) {
+		file.Meta.RecycleBinEnabled = "True"
+		file.Meta.RecycleBinUUID = encodeUUID(db.RecycleBin)
+	}
+
+	data, err := xml.MarshalIndent(file, "", "\t")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// newXMLGroup converts a group to XML, encrypting protected values with the
+// inner stream in the order they are marshaled: entries before subgroups.
+func newXMLGroup(group Group, stream *innerStream) xmlGroup {
+	now := time.Now()
+
+	g := xmlGroup{
+		UUID:  encodeUUID(orNewUUID(group.UUID)),
+		Name:  group.Name,
+		Times: newXMLTimes(now, now),
+	}
+
+	for _, entry := range group.Entries {
+		e := xmlEntry{
+			UUID:  encodeUUID(orNewUUID(entry.UUID)),
+			Tags:  strings.Join(entry.Tags, ";"),
+			Times: newXMLTimes(entry.CreatedAt, entry.ModifiedAt),
+		}
+
+		fields := []Field{
+			{Key: "Title", Value: entry.Title},
+			{Key: "UserName", Value: entry.UserName},
+			{Key: "Password", Value: entry.Password, Protected: true},
+			{Key: "URL", Value: entry.URL},
+			{Key: "Notes", Value: entry.Notes},
+		}
+
+		for _, field := range append(fields, entry.Fields...) {
+			value := xmlValue{Text: field.Value}
+
+			if field.Protected {
+				value = xmlValue{Protected: "True", Text: base64.StdEncoding.EncodeToString(stream.xor([]byte(field.Value)))}
+			}
+
+			e.Strings = append(e.Strings, xmlString{Key: field.Key, Value: value})
+		}
+
+		g.Entries = append(g.Entries, e)
+	}
+
+	for _, child := range group.Groups {
+		g.Groups = append(g.Groups, newXMLGroup(child, stream))
+	}
+
+	return g
+}
+
+func (g xmlGroup) group() Group {
+	group := Group{UUID: decodeUUID(g.UUID), Name: g.Name}
+
+	for _, e := range g.Entries {
+		entry := Entry{
+			UUID:       decodeUUID(e.UUID),
+			Tags:       decodeTags(e.Tags),
+			CreatedAt:  decodeTime(e.Times.CreationTime),
+			ModifiedAt: decodeTime(e.Times.LastModificationTime),
+		}
+
+		for _, s := range e.Strings {
+			switch s.Key {
+			case "Title":
+				entry.Title = s.Value.Text
+			case "UserName":
+				entry.UserName = s.Value.Text
+			case "Password":
+				entry.Password = s.Value.Text
+			case "URL":
+				entry.URL = s.Value.Text
+			case "Notes":
+				entry.Notes = s.Value.Text
+			default:
+				entry.Fields = append(entry.Fields, Field{
+					Key:       s.Key,
+					Value:     s.Value.Text,
+					Protected: strings.EqualFold(s.Value.ProtectInMemory, "True"),
+				})
+			}
+		}
+
+		group.Entries = append(group.Entries, entry)
+	}
+
+	for _, child := range g.Groups {
+		group.Groups = append(group.Groups, child.group())
+	}
+
+	return group
+}
+
+// decodeTags splits the tags of an entry, which KeePass separates with
+// semicolons and older versions with commas.
+func decodeTags(text string) []string {
+	var tags []string
+
+	for _, tag := range strings.FieldsFunc(text, func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+func newXMLTimes(createdAt, modifiedAt time.Time) xmlTimes {
+	now := time.Now()
+
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+
+	if modifiedAt.IsZero() {
+		modifiedAt = createdAt
+	}
+
+	return xmlTimes{
+		CreationTime:         encodeTime(createdAt),
+		LastModificationTime: encodeTime(modifiedAt),
+		LastAccessTime:       encodeTime(modifiedAt),
+		ExpiryTime:           encodeTime(modifiedAt),
+		Expires:              "False",
+		LocationChanged:      encodeTime(modifiedAt),
+	}
+}
+
+func encodeTime(t time.Time) string {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(t.Unix()+epoch))
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// decodeTime decodes a KDBX 4 time, falling back to the ISO 8601 format of
+// older versions. It returns the zero time for invalid values.
+func decodeTime(s string) time.Time {
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil && len(b) == 8 {
+		return time.Unix(int64(binary.LittleEndian.Uint64(b))-epoch, 0).UTC()
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC()
+	}
+
+	return time.Time{}
+}
+
+func encodeUUID(uuid UUID) string {
+	return base64.StdEncoding.EncodeToString(uuid[:])
+}
+
+func decodeUUID(s string) UUID {
+	var uuid UUID
+
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil && len(b) == len(uuid) {
+		copy(uuid[:], b)
+	}
+
+	return uuid
+}
+
+func orNewUUID(uuid UUID) UUID {
+	if uuid == (UUID{}) {
+		return NewUUID()
+	}
+	return uuid
+}
@@ -0,0 +1,146 @@
+// Package keywrap wraps keys to X25519 public keys, so a key can be handed
+// to another user through the server without the server being able to read it.
+//
+// A wrapped key is an ephemeral X25519 public key followed by the key sealed
+// with XChaCha20-Poly1305 under a key derived with HKDF-SHA256 from the
+// Diffie-Hellman secret of the ephemeral key and the recipient key.
+package keywrap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/edgardjr92/gopass/pkg/seal"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// KeySize is the size of X25519 public and private keys.
+const KeySize = curve25519.ScalarSize
+
+// ErrInvalidKey is returned for public or private keys that aren't X25519 keys.
+var ErrInvalidKey = errors.New("keywrap: invalid X25519 key")
+
+// GenerateKey returns a new X25519 key pair.
+func GenerateKey() ([]byte, []byte, error) {
+	privateKey, err := seal.Random(KeySize)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	publicKey, err := PublicKey(privateKey)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return publicKey, privateKey, nil
+}
+
+// PublicKey returns the public key of an X25519 private key.
+func PublicKey(privateKey []byte) ([]byte, error) {
+	if len(privateKey) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	return curve25519.X25519(privateKey, curve25519.Basepoint)
+}
+
+// Fingerprint returns the fingerprint of a public key, which people compare
+// out-of-band to check that a public key is genuine: the first 20 bytes of its
+// SHA-256, as ten groups of four hex digits.
+func Fingerprint(publicKey []byte) string {
+	sum := sha256.Sum256(publicKey)
+	digits := hex.EncodeToString(sum[:20])
+	groups := make([]string, 0, len(digits)/4)
+
+	for i := 0; i < len(digits); i += 4 {
+		groups = append(groups, digits[i:i+4])
+	}
+
+	return strings.Join(groups, " ")
+}
+
+// Wrap wraps key to publicKey. Only the holder of the matching private key can unwrap it.
+func Wrap(publicKey, key []byte) ([]byte, error) {
+	if len(publicKey) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	ephemeralPublic, ephemeralPrivate, err := GenerateKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	sealKey, err := wrappingKey(ephemeralPrivate, publicKey, ephemeralPublic, publicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, ciphertext, err := seal.Seal(sealKey, key, ephemeralPublic)
+
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped := append(ephemeralPublic, nonce...)
+
+	return append(wrapped, ciphertext...), nil
+}
+
+// Unwrap unwraps a key wrapped by Wrap to the public key of privateKey.
+// It returns seal.ErrDecrypt when the key was wrapped to another public key
+// or was modified.
+func Unwrap(privateKey, wrapped []byte) ([]byte, error) {
+	if len(privateKey) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	if len(wrapped) < KeySize+seal.NonceSize {
+		return nil, seal.ErrDecrypt
+	}
+
+	ephemeralPublic := wrapped[:KeySize]
+	nonce := wrapped[KeySize : KeySize+seal.NonceSize]
+
+	publicKey, err := PublicKey(privateKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sealKey, err := wrappingKey(privateKey, ephemeralPublic, ephemeralPublic, publicKey)
+
+	if err != nil {
+		return nil, seal.ErrDecrypt
+	}
+
+	return seal.Open(sealKey, nonce, wrapped[KeySize+seal.NonceSize:], ephemeralPublic)
+}
+
+// wrappingKey derives the key sealing a wrapped key from the Diffie-Hellman
+// secret of privateKey and peerPublic, bound to the ephemeral and recipient
+// public keys.
+func wrappingKey(privateKey, peerPublic, ephemeralPublic, recipientPublic []byte) ([]byte, error) {
+	// X25519 fails on low-order points, whose secret would be all zeros.
+	secret, err := curve25519.X25519(privateKey, peerPublic)
+
+	if err != nil {
+		return nil, err
+	}
+
+	salt := append(append([]byte{}, ephemeralPublic...), recipientPublic...)
+	key := make([]byte, seal.KeySize)
+
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte("gopass keywrap")), key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
@@ -0,0 +1,68 @@
+package keywrap
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/edgardjr92/gopass/pkg/seal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapAndUnwrap(t *testing.T) {
+	publicKey, privateKey, err := GenerateKey()
+	assert.Nil(t, err)
+	_, otherPrivateKey, _ := GenerateKey()
+	key, _ := seal.NewKey()
+
+	wrapped, err := Wrap(publicKey, key)
+	assert.Nil(t, err)
+	assert.False(t, bytes.Contains(wrapped, key))
+
+	actual, err := Unwrap(privateKey, wrapped)
+	assert.Nil(t, err)
+	assert.Equal(t, key, actual)
+
+	// then: another private key or a modified wrapped key fail
+	_, err = Unwrap(otherPrivateKey, wrapped)
+	assert.Equal(t, seal.ErrDecrypt, err)
+
+	tampered := append([]byte{}, wrapped...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = Unwrap(privateKey, tampered)
+	assert.Equal(t, seal.ErrDecrypt, err)
+
+	_, err = Unwrap(privateKey, wrapped[:KeySize])
+	assert.Equal(t, seal.ErrDecrypt, err)
+
+	// then: each wrap uses a new ephemeral key
+	again, _ := Wrap(publicKey, key)
+	assert.NotEqual(t, wrapped, again)
+}
+
+func TestInvalidKeys(t *testing.T) {
+	_, err := Wrap([]byte("short"), []byte("key"))
+	assert.Equal(t, ErrInvalidKey, err)
+
+	_, err = Unwrap([]byte("short"), make([]byte, 100))
+	assert.Equal(t, ErrInvalidKey, err)
+
+	_, err = PublicKey(nil)
+	assert.Equal(t, ErrInvalidKey, err)
+
+	// then: low-order public keys are rejected
+	_, err = Wrap(make([]byte, KeySize), []byte("key"))
+	assert.NotNil(t, err)
+}
+
+func TestFingerprint(t *testing.T) {
+	publicKey, _, _ := GenerateKey()
+	otherPublicKey, _, _ := GenerateKey()
+
+	actual := Fingerprint(publicKey)
+
+	assert.Len(t, actual, 49)
+	assert.Equal(t, actual, Fingerprint(publicKey))
+	assert.NotEqual(t, actual, Fingerprint(otherPublicKey))
+	// then: the SHA-256 of nothing starts with e3b0c442...
+	assert.Equal(t, "e3b0 c442 98fc 1c14 9afb f4c8 996f b924 27ae 41e4", Fingerprint(nil))
+}
@@ -0,0 +1,371 @@
+// Package nativemsg implements a native messaging host for the gopass browser
+// extensions. Browsers start the host and exchange length-prefixed JSON
+// messages with it over its stdin and stdout.
+//
+// The extension first sends a hello, answered with a session bound to the
+// gopass session the user logged in with locally. Every other request carries
+// the session: lookup lists the logins of a page without their passwords, fill
+// returns the credentials of one of them and save stores a new login.
+package nativemsg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+	"github.com/edgardjr92/gopass/pkg/clock"
+)
+
+// DefaultVault is the vault new logins are saved in when Host.Vault is empty.
+const DefaultVault = "Browser"
+
+// DefaultSessionTTL is how long sessions last when Host.SessionTTL is zero.
+const DefaultSessionTTL = 12 * time.Hour
+
+// ErrLocked is returned by Host.Unlock when gopass isn't logged in.
+var ErrLocked = errors.New(`gopass is locked, run "gopass login" first`)
+
+// Store is the part of the gopass API used by the host, implemented by *client.Client.
+type Store interface {
+	Vaults(ctx context.Context) ([]client.Vault, error)
+	CreateVault(ctx context.Context, name string) (uint, error)
+	CreateItem(ctx context.Context, vaultID uint, input client.ItemInput) (uint, error)
+	TOTP(ctx context.Context, itemID uint) (*client.TOTPCode, error)
+	Autofill(ctx context.Context, pageURL string) ([]client.AutofillMatch, error)
+}
+
+// Host answers the requests of a browser extension.
+type Host struct {
+	// Unlock returns the store of the local gopass session and its token, or
+	// ErrLocked. It is called for each request, so logging out of gopass
+	// locks the extension at once.
+	Unlock func() (Store, string, error)
+	// Vault is where new logins are saved, DefaultVault when empty.
+	// It is created on the first save.
+	Vault string
+	// SessionTTL is how long sessions last, DefaultSessionTTL when zero.
+	SessionTTL time.Duration
+	Clock      clock.Clock
+	// Rand is the source of session nonces, crypto/rand when nil.
+	Rand io.Reader
+}
+
+// Serve answers the messages read from r on w until r is closed.
+func (h *Host) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	for {
+		msg, err := ReadMessage(r)
+
+		if err == io.EOF {
+			return nil
+		}
+
+		var resp Response
+
+		switch {
+		case errors.Is(err, ErrMessageTooLarge):
+			resp = errorResponse("", &Error{Code: CodeBadRequest, Message: "message too large"})
+		case err != nil:
+			return err
+		default:
+			resp = h.handleMessage(ctx, msg)
+		}
+
+		err = WriteMessage(w, resp)
+
+		if errors.Is(err, ErrMessageTooLarge) {
+			err = WriteMessage(w, errorResponse(resp.ID, &Error{Code: CodeServerError, Message: "response too large"}))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (h *Host) handleMessage(ctx context.Context, msg []byte) Response {
+	var req Request
+
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return errorResponse("", &Error{Code: CodeBadRequest, Message: "invalid request"})
+	}
+
+	return h.Handle(ctx, req)
+}
+
+// Handle answers a request.
+func (h *Host) Handle(ctx context.Context, req Request) Response {
+	if req.Version != Version {
+		return errorResponse(req.ID, &Error{
+			Code:     CodeUnsupportedVersion,
+			Message:  fmt.Sprintf("unsupported version %d", req.Version),
+			Versions: []int{Version},
+		})
+	}
+
+	payload, err := h.handle(ctx, req)
+
+	if err != nil {
+		return errorResponse(req.ID, toError(err))
+	}
+
+	return Response{Version: Version, ID: req.ID, OK: true, Payload: payload}
+}
+
+func (h *Host) handle(ctx context.Context, req Request) (any, error) {
+	switch req.Type {
+	case TypeHello, TypeLookup, TypeFill, TypeSave:
+	default:
+		return nil, &Error{Code: CodeBadRequest, Message: fmt.Sprintf("unknown request type %q", req.Type)}
+	}
+
+	store, token, err := h.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Type == TypeHello {
+		return h.hello(token)
+	}
+
+	if !validSession(req.Session, token, h.Clock.Now()) {
+		return nil, &Error{Code: CodeInvalidSession, Message: "invalid session, send a hello"}
+	}
+
+	switch req.Type {
+	case TypeLookup:
+		return h.lookup(ctx, store, req)
+	case TypeFill:
+		return h.fill(ctx, store, req)
+	default:
+		return h.save(ctx, store, req)
+	}
+}
+
+func (h *Host) hello(token string) (any, error) {
+	expiresAt := h.Clock.Now().Add(h.sessionTTL()).Truncate(time.Second)
+	session, err := newSession(token, expiresAt, h.rand())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return HelloResponse{Session: session, ExpiresAt: expiresAt}, nil
+}
+
+func (h *Host) lookup(ctx context.Context, store Store, req Request) (any, error) {
+	var lookup LookupRequest
+
+	if err := decodePayload(req, &lookup); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(lookup.URL) == "" {
+		return nil, &Error{Code: CodeBadRequest, Message: "url is required"}
+	}
+
+	matches, err := store.Autofill(ctx, lookup.URL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	logins := make([]Login, len(matches))
+
+	for i, m := range matches {
+		logins[i] = Login{
+			ID:       m.ID,
+			VaultID:  m.VaultID,
+			Vault:    m.VaultName,
+			Name:     m.Name,
+			Username: m.Username,
+			Match:    m.Match,
+			HasTOTP:  m.HasTOTP,
+		}
+	}
+
+	return LookupResponse{Logins: logins}, nil
+}
+
+// fill only returns the credentials of logins matching the page, so a page
+// can't get the extension to fill in the logins of other sites.
+func (h *Host) fill(ctx context.Context, store Store, req Request) (any, error) {
+	var fill FillRequest
+
+	if err := decodePayload(req, &fill); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(fill.URL) == "" {
+		return nil, &Error{Code: CodeBadRequest, Message: "url is required"}
+	}
+
+	if fill.ItemID == 0 {
+		return nil, &Error{Code: CodeBadRequest, Message: "itemId is required"}
+	}
+
+	matches, err := store.Autofill(ctx, fill.URL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range matches {
+		if m.ID != fill.ItemID {
+			continue
+		}
+
+		resp := FillResponse{Username: m.Username, Password: m.Password}
+
+		if m.HasTOTP {
+			code, err := store.TOTP(ctx, m.ID)
+
+			if err != nil {
+				return nil, err
+			}
+
+			resp.TOTP = code.Code
+		}
+
+		return resp, nil
+	}
+
+	return nil, &Error{Code: CodeNotFound, Message: "login not found for this page"}
+}
+
+// save stores the origin of the page only, as the rest of its URL often
+// holds session state.
+func (h *Host) save(ctx context.Context, store Store, req Request) (any, error) {
+	var save SaveRequest
+
+	if err := decodePayload(req, &save); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(strings.TrimSpace(save.URL))
+
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, &Error{Code: CodeBadRequest, Message: "url must be an http or https url"}
+	}
+
+	if save.Password == "" {
+		return nil, &Error{Code: CodeBadRequest, Message: "password is required"}
+	}
+
+	vaultID, err := h.saveVault(ctx, store, save.Vault)
+
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(save.Name)
+
+	if name == "" {
+		name = u.Hostname()
+	}
+
+	id, err := store.CreateItem(ctx, vaultID, client.ItemInput{
+		Name:     name,
+		URL:      u.Scheme + "://" + u.Host,
+		Username: save.Username,
+		Password: save.Password,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return SaveResponse{ID: id, VaultID: vaultID}, nil
+}
+
+// saveVault returns the vault called name, or the vault of the host, which is
+// created when missing.
+func (h *Host) saveVault(ctx context.Context, store Store, name string) (uint, error) {
+	vaults, err := store.Vaults(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	create := name == ""
+
+	if create {
+		name = h.vaultName()
+	}
+
+	for _, v := range vaults {
+		if v.Name == name {
+			return v.ID, nil
+		}
+	}
+
+	if !create {
+		return 0, &Error{Code: CodeNotFound, Message: fmt.Sprintf("vault %q not found", name)}
+	}
+
+	return store.CreateVault(ctx, name)
+}
+
+func (h *Host) vaultName() string {
+	if h.Vault == "" {
+		return DefaultVault
+	}
+
+	return h.Vault
+}
+
+func (h *Host) sessionTTL() time.Duration {
+	if h.SessionTTL == 0 {
+		return DefaultSessionTTL
+	}
+
+	return h.SessionTTL
+}
+
+func (h *Host) rand() io.Reader {
+	if h.Rand == nil {
+		return rand.Reader
+	}
+
+	return h.Rand
+}
+
+func errorResponse(id string, err *Error) Response {
+	return Response{Version: Version, ID: id, Error: err}
+}
+
+// toError returns the error sent to the extension for err.
+func toError(err error) *Error {
+	var msgErr *Error
+
+	if errors.As(err, &msgErr) {
+		return msgErr
+	}
+
+	if errors.Is(err, ErrLocked) {
+		return &Error{Code: CodeLocked, Message: err.Error()}
+	}
+
+	var apiErr *client.Error
+
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized:
+			return &Error{Code: CodeLocked, Message: `gopass session expired, run "gopass login" again`}
+		case http.StatusBadRequest:
+			return &Error{Code: CodeBadRequest, Message: apiErr.Message}
+		case http.StatusNotFound:
+			return &Error{Code: CodeNotFound, Message: apiErr.Message}
+		}
+	}
+
+	return &Error{Code: CodeServerError, Message: err.Error()}
+}
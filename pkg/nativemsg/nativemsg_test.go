@@ -0,0 +1,326 @@
+package nativemsg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+var testNow = time.Date(2023, 5, 6, 10, 0, 0, 0, time.UTC)
+
+// fakeStore keeps vaults and the matches of pages in memory.
+type fakeStore struct {
+	vaults  []client.Vault
+	matches map[string][]client.AutofillMatch
+	nextID  uint
+}
+
+func (s *fakeStore) Vaults(ctx context.Context) ([]client.Vault, error) {
+	return s.vaults, nil
+}
+
+func (s *fakeStore) CreateVault(ctx context.Context, name string) (uint, error) {
+	s.nextID++
+	s.vaults = append(s.vaults, client.Vault{ID: s.nextID, Name: name})
+	return s.nextID, nil
+}
+
+func (s *fakeStore) CreateItem(ctx context.Context, vaultID uint, input client.ItemInput) (uint, error) {
+	s.nextID++
+	match := client.AutofillMatch{
+		Item:  client.Item{ID: s.nextID, VaultID: vaultID, Name: input.Name, URL: input.URL, Username: input.Username, Password: input.Password},
+		Match: "host",
+	}
+
+	for _, v := range s.vaults {
+		if v.ID == vaultID {
+			match.VaultName = v.Name
+		}
+	}
+
+	s.matches[input.URL] = append(s.matches[input.URL], match)
+
+	return s.nextID, nil
+}
+
+func (s *fakeStore) TOTP(ctx context.Context, itemID uint) (*client.TOTPCode, error) {
+	return &client.TOTPCode{Code: "287082", Period: 30, Remaining: 12}, nil
+}
+
+func (s *fakeStore) Autofill(ctx context.Context, pageURL string) ([]client.AutofillMatch, error) {
+	if !strings.HasPrefix(pageURL, "https://") && !strings.HasPrefix(pageURL, "http://") {
+		return nil, &client.Error{StatusCode: http.StatusBadRequest, Message: "url must be an http or https url"}
+	}
+
+	return s.matches[pageURL], nil
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		vaults: []client.Vault{{ID: 1, Name: "Personal"}, {ID: 2, Name: "Work"}},
+		matches: map[string][]client.AutofillMatch{
+			"https://github.com/login": {
+				{Item: client.Item{ID: 10, VaultID: 1, Name: "GitHub", URL: "github.com", Username: "octocat", Password: "gh-secret", HasTOTP: true}, VaultName: "Personal", Match: "domain"},
+				{Item: client.Item{ID: 11, VaultID: 2, Name: "GitHub work", URL: "github.com", Username: "octo-work", Password: "work-secret"}, VaultName: "Work", Match: "domain"},
+			},
+		},
+		nextID: 100,
+	}
+}
+
+// newTestHost returns a host unlocked with token, handing out predictable sessions.
+func newTestHost(store Store, token *string) *Host {
+	return &Host{
+		Unlock: func() (Store, string, error) {
+			if *token == "" {
+				return nil, "", ErrLocked
+			}
+			return store, *token, nil
+		},
+		Clock: clock.Clock{NowFn: func() time.Time { return testNow }},
+		Rand:  bytes.NewReader(bytes.Repeat([]byte{7}, 1024)),
+	}
+}
+
+// frame returns the messages framed as a browser sends them.
+func frame(t *testing.T, messages ...string) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	for _, msg := range messages {
+		assert.Nil(t, WriteMessage(&buf, json.RawMessage(msg)))
+	}
+
+	return &buf
+}
+
+// TestGolden sends the requests of each testdata/*.in file, one per line, to a
+// host and compares its responses with the .golden file next to it. $SESSION
+// is replaced by the session of the last hello. Run with -update to rewrite
+// the golden files after a change of the wire format.
+func TestGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.in"))
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, files)
+
+	for _, file := range files {
+		file := file
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".in"), func(t *testing.T) {
+			// given
+			input, err := os.ReadFile(file)
+			assert.Nil(t, err)
+
+			token := "token-1"
+			host := newTestHost(newFakeStore(), &token)
+			session := ""
+			var out bytes.Buffer
+
+			// when
+			for _, line := range strings.Split(strings.TrimSpace(string(input)), "\n") {
+				var resp bytes.Buffer
+				err := host.Serve(context.TODO(), frame(t, strings.ReplaceAll(line, "$SESSION", session)), &resp)
+				assert.Nil(t, err)
+
+				msg, err := ReadMessage(&resp)
+				assert.Nil(t, err)
+
+				var hello struct {
+					Payload HelloResponse `json:"payload"`
+				}
+
+				if err := json.Unmarshal(msg, &hello); err == nil && hello.Payload.Session != "" {
+					session = hello.Payload.Session
+				}
+
+				assert.Nil(t, json.Indent(&out, msg, "", "  "))
+				out.WriteString("\n")
+			}
+
+			// then
+			golden := strings.TrimSuffix(file, ".in") + ".golden"
+
+			if *update {
+				assert.Nil(t, os.WriteFile(golden, out.Bytes(), 0o644))
+			}
+
+			expected, err := os.ReadFile(golden)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), out.String())
+		})
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	t.Run("messages", func(t *testing.T) {
+		// given
+		r := bytes.NewReader([]byte("\x02\x00\x00\x00{}\x0a\x00\x00\x00{\"id\":\"1\"}"))
+
+		// when
+		first, err1 := ReadMessage(r)
+		second, err2 := ReadMessage(r)
+		_, err3 := ReadMessage(r)
+
+		// then
+		assert.Nil(t, err1)
+		assert.Equal(t, "{}", string(first))
+		assert.Nil(t, err2)
+		assert.Equal(t, `{"id":"1"}`, string(second))
+		assert.Equal(t, io.EOF, err3)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		// when
+		_, err := ReadMessage(bytes.NewReader([]byte("\x05\x00\x00\x00{}")))
+
+		// then
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		// given
+		r := bytes.NewReader(append([]byte("\x01\x00\x10\x00"), make([]byte, MaxMessageSize+1)...))
+
+		// when
+		_, err := ReadMessage(r)
+
+		// then: the message was skipped
+		assert.Equal(t, ErrMessageTooLarge, err)
+		assert.Equal(t, 0, r.Len())
+	})
+}
+
+func TestWriteMessage(t *testing.T) {
+	var out bytes.Buffer
+
+	err := WriteMessage(&out, Response{Version: Version, ID: "1", OK: true})
+
+	assert.Nil(t, err)
+	assert.Equal(t, " \x00\x00\x00{\"version\":1,\"id\":\"1\",\"ok\":true}", out.String())
+
+	err = WriteMessage(&out, strings.Repeat("a", MaxMessageSize))
+
+	assert.Equal(t, ErrMessageTooLarge, err)
+}
+
+func TestSessions(t *testing.T) {
+	ctx := context.TODO()
+	lookup := Request{Version: Version, ID: "2", Type: TypeLookup, Payload: json.RawMessage(`{"url":"https://github.com/login"}`)}
+
+	hello := func(host *Host) string {
+		resp := host.Handle(ctx, Request{Version: Version, ID: "1", Type: TypeHello})
+		assert.True(t, resp.OK)
+		return resp.Payload.(HelloResponse).Session
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		// given
+		token := "token-1"
+		host := newTestHost(newFakeStore(), &token)
+		lookup.Session = hello(host)
+
+		// when
+		resp := host.Handle(ctx, lookup)
+
+		// then
+		assert.True(t, resp.OK)
+	})
+
+	t.Run("revoked by a new login", func(t *testing.T) {
+		// given
+		token := "token-1"
+		host := newTestHost(newFakeStore(), &token)
+		lookup.Session = hello(host)
+		token = "token-2"
+
+		// when
+		resp := host.Handle(ctx, lookup)
+
+		// then
+		assert.Equal(t, CodeInvalidSession, resp.Error.Code)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		// given
+		token := "token-1"
+		host := newTestHost(newFakeStore(), &token)
+		lookup.Session = hello(host)
+		host.Clock = clock.Clock{NowFn: func() time.Time { return testNow.Add(DefaultSessionTTL) }}
+
+		// when
+		resp := host.Handle(ctx, lookup)
+
+		// then
+		assert.Equal(t, CodeInvalidSession, resp.Error.Code)
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		// given
+		token := "token-1"
+		host := newTestHost(newFakeStore(), &token)
+		lookup.Session = hello(host)
+		token = ""
+
+		// when
+		resp := host.Handle(ctx, lookup)
+
+		// then
+		assert.Equal(t, &Error{Code: CodeLocked, Message: ErrLocked.Error()}, resp.Error)
+	})
+}
+
+func TestServe(t *testing.T) {
+	t.Run("skips messages too large", func(t *testing.T) {
+		// given
+		token := "token-1"
+		host := newTestHost(newFakeStore(), &token)
+		in := frame(t, `{"version":1,"id":"1","type":"hello"}`)
+		large := append([]byte("\x01\x00\x10\x00"), make([]byte, MaxMessageSize+1)...)
+		in = bytes.NewBuffer(append(large, in.Bytes()...))
+		var out bytes.Buffer
+
+		// when
+		err := host.Serve(context.TODO(), in, &out)
+
+		// then
+		assert.Nil(t, err)
+		first, _ := ReadMessage(&out)
+		second, _ := ReadMessage(&out)
+		assert.JSONEq(t, `{"version":1,"id":"","ok":false,"error":{"code":"bad_request","message":"message too large"}}`, string(first))
+		assert.Contains(t, string(second), `"ok":true`)
+	})
+
+	t.Run("truncated stream", func(t *testing.T) {
+		// given
+		token := "token-1"
+		host := newTestHost(newFakeStore(), &token)
+
+		// when
+		err := host.Serve(context.TODO(), strings.NewReader("\x05\x00\x00\x00{}"), io.Discard)
+
+		// then
+		assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	})
+
+	t.Run("server session expired", func(t *testing.T) {
+		// when
+		err := toError(&client.Error{StatusCode: http.StatusUnauthorized, Message: "invalid token"})
+
+		// then
+		assert.Equal(t, CodeLocked, err.Code)
+	})
+}
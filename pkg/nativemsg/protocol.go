@@ -0,0 +1,205 @@
+package nativemsg
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Version is the version of the messages spoken by the host. Requests with
+// another version are answered with an unsupported_version error listing the
+// supported versions, so extensions can tell an outdated host from a broken one.
+const Version = 1
+
+// MaxMessageSize is the largest message the host reads or writes. Browsers
+// reject messages over 1 MB from native hosts, and requests are much smaller.
+const MaxMessageSize = 1 << 20
+
+// ErrMessageTooLarge is returned for messages over MaxMessageSize. The message
+// has been skipped, so the next one can still be read.
+var ErrMessageTooLarge = errors.New("nativemsg: message too large")
+
+// ReadMessage reads a message: its length as a 32-bit unsigned integer in
+// native byte order, followed by that many bytes of JSON. Every platform
+// browsers run native hosts on is little-endian. It returns io.EOF when the
+// browser closed the stream between messages.
+func ReadMessage(r io.Reader) ([]byte, error) {
+	var header [4]byte
+
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.LittleEndian.Uint32(header[:])
+
+	if size > MaxMessageSize {
+		if _, err := io.CopyN(io.Discard, r, int64(size)); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		return nil, ErrMessageTooLarge
+	}
+
+	msg := make([]byte, size)
+
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return msg, nil
+}
+
+// WriteMessage writes v as a JSON message, prefixed with its length.
+func WriteMessage(w io.Writer, v any) error {
+	msg, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	if len(msg) > MaxMessageSize {
+		return ErrMessageTooLarge
+	}
+
+	buf := make([]byte, 4, 4+len(msg))
+	binary.LittleEndian.PutUint32(buf, uint32(len(msg)))
+
+	_, err = w.Write(append(buf, msg...))
+
+	return err
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
+// Request types.
+const (
+	TypeHello  = "hello"
+	TypeLookup = "lookup"
+	TypeFill   = "fill"
+	TypeSave   = "save"
+)
+
+// Error codes.
+const (
+	CodeBadRequest         = "bad_request"
+	CodeUnsupportedVersion = "unsupported_version"
+	// CodeLocked means gopass isn't logged in, or its session expired.
+	CodeLocked = "locked"
+	// CodeInvalidSession means the session of the request is missing, expired
+	// or was handed out for another gopass session. A new hello is needed.
+	CodeInvalidSession = "invalid_session"
+	CodeNotFound       = "not_found"
+	CodeServerError    = "server_error"
+)
+
+// Request is a message from the extension. ID is chosen by the extension and
+// copied in the response. Every request but hello carries the session handed
+// out by hello.
+type Request struct {
+	Version int             `json:"version"`
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Session string          `json:"session,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Response answers a request. Payload is set when OK is true, Error otherwise.
+type Response struct {
+	Version int    `json:"version"`
+	ID      string `json:"id"`
+	OK      bool   `json:"ok"`
+	Payload any    `json:"payload,omitempty"`
+	Error   *Error `json:"error,omitempty"`
+}
+
+// Error tells why a request failed.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Versions lists the supported versions of unsupported_version errors.
+	Versions []int `json:"versions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// HelloResponse hands out a session to the extension.
+type HelloResponse struct {
+	Session   string    `json:"session"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// LookupRequest asks for the logins of a page.
+type LookupRequest struct {
+	URL string `json:"url"`
+}
+
+// LookupResponse lists the logins of a page, best match first. Passwords are
+// only sent by fill.
+type LookupResponse struct {
+	Logins []Login `json:"logins"`
+}
+
+// Login is an item matching a page.
+type Login struct {
+	ID       uint   `json:"id"`
+	VaultID  uint   `json:"vaultId"`
+	Vault    string `json:"vault"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Match    string `json:"match"`
+	HasTOTP  bool   `json:"hasTotp,omitempty"`
+}
+
+// FillRequest asks for the credentials of a login to fill in the page at URL.
+type FillRequest struct {
+	URL    string `json:"url"`
+	ItemID uint   `json:"itemId"`
+}
+
+// FillResponse holds the credentials of a login, with its current one-time
+// password when it has one.
+type FillResponse struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	TOTP     string `json:"totp,omitempty"`
+}
+
+// SaveRequest asks to save a new login for the page at URL. Name defaults to
+// the host of the page and Vault to the vault of the host.
+type SaveRequest struct {
+	URL      string `json:"url"`
+	Name     string `json:"name,omitempty"`
+	Vault    string `json:"vault,omitempty"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// SaveResponse identifies the saved login.
+type SaveResponse struct {
+	ID      uint `json:"id"`
+	VaultID uint `json:"vaultId"`
+}
+
+// decodePayload decodes the payload of req into v.
+func decodePayload(req Request, v any) error {
+	if len(req.Payload) == 0 {
+		return &Error{Code: CodeBadRequest, Message: "payload is required"}
+	}
+
+	if err := json.Unmarshal(req.Payload, v); err != nil {
+		return &Error{Code: CodeBadRequest, Message: fmt.Sprintf("invalid %s payload", req.Type)}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package nativemsg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+const (
+	sessionNonceSize = 16
+	sessionSize      = 8 + sessionNonceSize + sha256.Size
+)
+
+// sessionKey derives the key sessions are signed with from the token of the
+// gopass session. Logging out or in again changes the token, which revokes
+// every session handed out before.
+func sessionKey(token string) []byte {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte("gopass native messaging session"))
+
+	return mac.Sum(nil)
+}
+
+// newSession returns a session expiring at expiresAt: the expiry, a random
+// nonce and their MAC. Sessions are verified without being stored, as
+// browsers may start a host for each message.
+func newSession(token string, expiresAt time.Time, rand io.Reader) (string, error) {
+	buf := make([]byte, 8+sessionNonceSize, sessionSize)
+	binary.BigEndian.PutUint64(buf, uint64(expiresAt.Unix()))
+
+	if _, err := io.ReadFull(rand, buf[8:]); err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, sessionKey(token))
+	mac.Write(buf)
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(buf)), nil
+}
+
+// validSession tells whether session was handed out for token and hasn't expired at now.
+func validSession(session, token string, now time.Time) bool {
+	buf, err := base64.RawURLEncoding.DecodeString(session)
+
+	if err != nil || len(buf) != sessionSize {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, sessionKey(token))
+	mac.Write(buf[:8+sessionNonceSize])
+
+	if !hmac.Equal(mac.Sum(nil), buf[8+sessionNonceSize:]) {
+		return false
+	}
+
+	return now.Unix() < int64(binary.BigEndian.Uint64(buf))
+}
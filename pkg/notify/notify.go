@@ -0,0 +1,50 @@
+// Package notify sends notifications to users. Notifier is the extension
+// point: the server is given the notifier of its deployment, such as one
+// sending emails, and NewLogNotifier serves setups without any.
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// Message is a notification for the user with the email To.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier sends messages to users.
+type Notifier interface {
+	// Notify sends msg to its recipient.
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Func adapts a function to a Notifier.
+type Func func(ctx context.Context, msg Message) error
+
+// Notify calls f.
+func (f Func) Notify(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+type logNotifier struct {
+	logger *log.Logger
+}
+
+// NewLogNotifier returns a notifier writing messages to logger, the standard
+// logger when it is nil.
+func NewLogNotifier(logger *log.Logger) *logNotifier {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &logNotifier{logger}
+}
+
+func (l *logNotifier) Notify(ctx context.Context, msg Message) error {
+	l.logger.Printf("notification to %s: %s: %s", msg.To, msg.Subject, msg.Body)
+
+	return nil
+}
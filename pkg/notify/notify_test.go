@@ -0,0 +1,32 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunc(t *testing.T) {
+	var received Message
+	notifier := Func(func(ctx context.Context, msg Message) error {
+		received = msg
+		return nil
+	})
+
+	err := notifier.Notify(context.TODO(), Message{To: "jane@test.com", Subject: "Hello", Body: "Hi Jane"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, Message{To: "jane@test.com", Subject: "Hello", Body: "Hi Jane"}, received)
+}
+
+func TestLogNotifier(t *testing.T) {
+	var out bytes.Buffer
+
+	err := NewLogNotifier(log.New(&out, "", 0)).Notify(context.TODO(), Message{To: "jane@test.com", Subject: "Hello", Body: "Hi Jane"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "notification to jane@test.com: Hello: Hi Jane\n", out.String())
+}
@@ -0,0 +1,100 @@
+// Package seal provides the authenticated encryption used to protect data at rest:
+// keys derived from passphrases with Argon2id and encryption with XChaCha20-Poly1305.
+package seal
+
+import (
+	"crypto/rand"
+	"errors"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// KeySize is the size of the keys used by Seal and Open.
+	KeySize = chacha20poly1305.KeySize
+	// NonceSize is the size of the nonces generated by Seal.
+	NonceSize = chacha20poly1305.NonceSizeX
+	// SaltSize is the size of the salts generated by NewSalt.
+	SaltSize = 16
+)
+
+// ErrDecrypt is returned when a ciphertext can't be decrypted, either because
+// the key is wrong or because the ciphertext or additional data were modified.
+var ErrDecrypt = errors.New("seal: message authentication failed")
+
+// KDFParams are the Argon2id parameters used to derive a key from a passphrase.
+type KDFParams struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+// DefaultKDFParams follows the second recommended option of RFC 9106:
+// 3 passes over 64 MiB with 4 lanes.
+var DefaultKDFParams = KDFParams{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// NewSalt returns a random salt for DeriveKey.
+func NewSalt() ([]byte, error) {
+	return Random(SaltSize)
+}
+
+// NewKey returns a random key for Seal.
+func NewKey() ([]byte, error) {
+	return Random(KeySize)
+}
+
+// Random returns n random bytes.
+func Random(n int) ([]byte, error) {
+	b := make([]byte, n)
+
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DeriveKey derives a key from a passphrase with Argon2id.
+func DeriveKey(passphrase string, salt []byte, params KDFParams) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, params.Time, params.Memory, params.Threads, KeySize)
+}
+
+// Seal encrypts and authenticates plaintext and authenticates additionalData
+// with XChaCha20-Poly1305. It returns a random nonce and the ciphertext.
+func Seal(key, plaintext, additionalData []byte) ([]byte, []byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce, err := Random(NonceSize)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nonce, aead.Seal(nil, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts a ciphertext produced by Seal.
+func Open(key, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != NonceSize {
+		return nil, ErrDecrypt
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
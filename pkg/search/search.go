@@ -0,0 +1,344 @@
+// Package search implements an in-memory full-text index with prefix and fuzzy matching.
+//
+// Documents are made of weighted text fields, split into lowercase terms on
+// anything that isn't a letter or a digit. A query matches the documents where
+// every query term matches a term of some field, exactly, as a prefix, or with
+// a few typos. Documents are ranked by the sum of the best score of each query
+// term, weighted by the field it matched.
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Scores of the ways a query term can match a term of a document.
+const (
+	exactScore = 1.0
+	// prefixScore is the score of a prefix covering half of the term,
+	// shorter prefixes score less and longer ones more.
+	prefixScore = 0.7
+	fuzzyScore  = 0.5
+	// fuzzyPrefixScore is the score of a query term matching the start of a term with typos.
+	fuzzyPrefixScore = 0.3
+	// minFuzzyLength is the length from which query terms may have a typo,
+	// and twoTyposLength the length from which they may have two.
+	minFuzzyLength = 4
+	twoTyposLength = 8
+)
+
+// Field is a part of a document, whose matches are scored with Weight.
+type Field struct {
+	Name   string
+	Text   string
+	Weight float64
+}
+
+type Document[K comparable] struct {
+	Key    K
+	Fields []Field
+}
+
+type Result[K comparable] struct {
+	Key   K
+	Score float64
+	// Fields are the names of the fields holding a match, in the order of the document.
+	Fields []string
+}
+
+type posting struct {
+	field  int
+	weight float64
+}
+
+type document[K comparable] struct {
+	Document[K]
+	terms []string
+	// sortKey breaks the ties between documents with the same score.
+	sortKey string
+	seq     uint64
+}
+
+// Index is a full-text index of documents identified by keys of type K.
+// It is safe for concurrent use.
+type Index[K comparable] struct {
+	mu    sync.RWMutex
+	docs  map[K]*document[K]
+	terms map[string]map[K][]posting
+	seq   uint64
+}
+
+func NewIndex[K comparable]() *Index[K] {
+	return &Index[K]{docs: map[K]*document[K]{}, terms: map[string]map[K][]posting{}}
+}
+
+// Len returns the number of documents in the index.
+func (i *Index[K]) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.docs)
+}
+
+// Put adds a document, or replaces the document with the same key.
+func (i *Index[K]) Put(doc Document[K]) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.delete(doc.Key)
+
+	i.seq++
+	d := &document[K]{Document: doc, seq: i.seq}
+
+	if len(doc.Fields) > 0 {
+		d.sortKey = strings.ToLower(doc.Fields[0].Text)
+	}
+
+	for f, field := range doc.Fields {
+		for _, term := range Tokenize(field.Text) {
+			postings := i.terms[term]
+
+			if postings == nil {
+				postings = map[K][]posting{}
+				i.terms[term] = postings
+			}
+
+			if len(postings[doc.Key]) == 0 {
+				d.terms = append(d.terms, term)
+			}
+
+			postings[doc.Key] = append(postings[doc.Key], posting{f, field.Weight})
+		}
+	}
+
+	i.docs[doc.Key] = d
+}
+
+// Delete removes the document with the key, if any.
+func (i *Index[K]) Delete(key K) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.delete(key)
+}
+
+func (i *Index[K]) delete(key K) {
+	d, ok := i.docs[key]
+
+	if !ok {
+		return
+	}
+
+	for _, term := range d.terms {
+		delete(i.terms[term], key)
+
+		if len(i.terms[term]) == 0 {
+			delete(i.terms, term)
+		}
+	}
+
+	delete(i.docs, key)
+}
+
+// Search returns the documents matching every term of the query, best first.
+// At most limit results are returned, all of them when limit is zero.
+func (i *Index[K]) Search(query string, limit int) []Result[K] {
+	queryTerms := Tokenize(query)
+
+	if len(queryTerms) == 0 {
+		return []Result[K]{}
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	type match struct {
+		scores []float64
+		fields map[int]bool
+	}
+
+	matches := map[K]*match{}
+
+	for q, queryTerm := range queryTerms {
+		for term, postings := range i.terms {
+			score := termScore(queryTerm, term)
+
+			if score == 0 {
+				continue
+			}
+
+			for key, docPostings := range postings {
+				// Documents missing a previous query term can't match anymore.
+				m := matches[key]
+
+				if m == nil {
+					if q > 0 {
+						continue
+					}
+
+					m = &match{scores: make([]float64, len(queryTerms)), fields: map[int]bool{}}
+					matches[key] = m
+				}
+
+				for _, p := range docPostings {
+					if s := score * p.weight; s > m.scores[q] {
+						m.scores[q] = s
+					}
+
+					m.fields[p.field] = true
+				}
+			}
+		}
+
+		for key, m := range matches {
+			if m.scores[q] == 0 {
+				delete(matches, key)
+			}
+		}
+	}
+
+	results := make([]Result[K], 0, len(matches))
+
+	for key, m := range matches {
+		doc := i.docs[key]
+		result := Result[K]{Key: key}
+
+		for _, s := range m.scores {
+			result.Score += s
+		}
+
+		for f, field := range doc.Fields {
+			if m.fields[f] {
+				result.Fields = append(result.Fields, field.Name)
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+
+		da, db := i.docs[results[a].Key], i.docs[results[b].Key]
+
+		if da.sortKey != db.sortKey {
+			return da.sortKey < db.sortKey
+		}
+
+		return da.seq < db.seq
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// Tokenize splits text into lowercase terms.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// termScore returns how well a query term matches a term, zero when it doesn't.
+func termScore(query, term string) float64 {
+	if query == term {
+		return exactScore
+	}
+
+	q, t := []rune(query), []rune(term)
+
+	if strings.HasPrefix(term, query) {
+		coverage := float64(len(q)) / float64(len(t))
+
+		return prefixScore + (exactScore-prefixScore)*(coverage-0.5)
+	}
+
+	if len(q) < minFuzzyLength {
+		return 0
+	}
+
+	maxTypos := 1
+
+	if len(q) >= twoTyposLength {
+		maxTypos = 2
+	}
+
+	if d := distance(q, t, maxTypos); d <= maxTypos {
+		return fuzzyScore / float64(d)
+	}
+
+	if len(t) > len(q) {
+		if d := distance(q, t[:len(q)], maxTypos); d <= maxTypos {
+			return fuzzyPrefixScore / float64(d)
+		}
+	}
+
+	return 0
+}
+
+// distance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and transpositions of
+// adjacent runes turning a into b. It returns max+1 as soon as the distance
+// is known to be greater than max.
+func distance(a, b []rune, max int) int {
+	if diff := len(a) - len(b); diff > max || -diff > max {
+		return max + 1
+	}
+
+	// Three rows of the dynamic programming matrix are enough for transpositions.
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = minInt(curr[j], prev2[j-2]+1)
+			}
+
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+
+		if rowMin > max {
+			return max + 1
+		}
+
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(first int, rest ...int) int {
+	for _, n := range rest {
+		if n < first {
+			first = n
+		}
+	}
+
+	return first
+}
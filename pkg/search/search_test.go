@@ -0,0 +1,114 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func keys(results []Result[int]) []int {
+	keys := make([]int, len(results))
+
+	for i, r := range results {
+		keys[i] = r.Key
+	}
+
+	return keys
+}
+
+func newTestIndex() *Index[int] {
+	index := NewIndex[int]()
+
+	for _, doc := range []Document[int]{
+		{1, []Field{{"name", "GitHub", 3}, {"username", "octocat", 2}, {"host", "github.com", 2}}},
+		{2, []Field{{"name", "GitLab", 3}, {"username", "tanuki", 2}, {"host", "gitlab.com", 2}}},
+		{3, []Field{{"name", "Work email", 3}, {"notes", "recovery codes for github in the safe", 1}}},
+		{4, []Field{{"name", "Databases", 3}, {"host", "db.internal", 2}}},
+	} {
+		index.Put(doc)
+	}
+
+	return index
+}
+
+func TestSearch(t *testing.T) {
+	index := newTestIndex()
+
+	testCases := []struct {
+		name  string
+		query string
+		keys  []int
+	}{
+		{"exact", "github", []int{1, 3}},
+		{"case insensitive", "GITHUB", []int{1, 3}},
+		{"prefix", "git", []int{1, 2, 3}},
+		{"typo", "githbu", []int{1, 3}},
+		{"typo in a prefix", "databsa", []int{4}},
+		{"every term matches", "git tanuki", []int{2}},
+		{"no fuzzy match for short terms", "gat", []int{}},
+		{"no match", "bitbucket", []int{}},
+		{"empty", " ", []int{}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.keys, keys(index.Search(tc.query, 0)))
+		})
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	// given
+	index := newTestIndex()
+
+	// when
+	results := index.Search("github", 0)
+
+	// then: a name match ranks above a notes match
+	assert.Equal(t, []Result[int]{
+		{Key: 1, Score: 3, Fields: []string{"name", "host"}},
+		{Key: 3, Score: 1, Fields: []string{"notes"}},
+	}, results)
+
+	// exact matches rank above prefix matches, which rank above typos
+	assert.Greater(t, termScore("git", "git"), termScore("git", "github"))
+	assert.Greater(t, termScore("gith", "github"), termScore("git", "github"))
+	assert.Greater(t, termScore("git", "github"), termScore("githbu", "github"))
+
+	assert.Equal(t, []int{1}, keys(index.Search("git", 1)))
+}
+
+func TestPutAndDelete(t *testing.T) {
+	// given
+	index := newTestIndex()
+
+	// when
+	index.Put(Document[int]{1, []Field{{"name", "Bitbucket", 3}}})
+	index.Delete(2)
+	index.Delete(42)
+
+	// then
+	assert.Equal(t, 3, index.Len())
+	assert.Equal(t, []int{1}, keys(index.Search("bitbucket", 0)))
+	assert.Equal(t, []int{3}, keys(index.Search("git", 0)))
+	assert.Empty(t, index.terms["gitlab"])
+	assert.Empty(t, index.terms["tanuki"])
+}
+
+func TestDistance(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		max  int
+		d    int
+	}{
+		{"github", "github", 1, 0},
+		{"github", "githbu", 1, 1},
+		{"github", "gitlab", 2, 2},
+		{"github", "gitlab", 1, 2},
+		{"ab", "abcd", 1, 2},
+		{"crème", "creme", 1, 1},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.d, distance([]rune(tc.a), []rune(tc.b), tc.max), tc.a+" "+tc.b)
+	}
+}
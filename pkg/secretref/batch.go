@@ -0,0 +1,67 @@
+package secretref
+
+import (
+	"context"
+	"sync"
+)
+
+type batchResolver struct {
+	finder ItemFinder
+	mu     sync.Mutex
+	items  map[itemKey]*lookup
+}
+
+type itemKey struct {
+	vault string
+	item  string
+}
+
+// lookup is a FindItem call, shared by all the references to the same item.
+type lookup struct {
+	done chan struct{}
+	item Item
+	err  error
+}
+
+// NewBatchResolver returns a resolver that looks up each item once, however many
+// of its fields are referenced, including by concurrent calls.
+//
+// Items are cached for the life of the resolver, failed lookups included, so it
+// should be used for one batch of references, such as the templates of a render
+// or the environment of a command.
+func NewBatchResolver(finder ItemFinder) Resolver {
+	return &batchResolver{finder: finder, items: map[itemKey]*lookup{}}
+}
+
+func (b *batchResolver) Resolve(ctx context.Context, ref Ref) (string, error) {
+	return resolve(ctx, b, ref)
+}
+
+// FindItem makes the batch resolver an ItemFinder, so resolve can use it.
+func (b *batchResolver) FindItem(ctx context.Context, vault, item string) (Item, error) {
+	// The key keeps the names as given: "Work" and "work" may be different
+	// vaults, since exact matches win over case insensitive ones.
+	key := itemKey{vault, item}
+
+	b.mu.Lock()
+	l, ok := b.items[key]
+
+	if !ok {
+		l = &lookup{done: make(chan struct{})}
+		b.items[key] = l
+	}
+
+	b.mu.Unlock()
+
+	if !ok {
+		l.item, l.err = b.finder.FindItem(ctx, vault, item)
+		close(l.done)
+	}
+
+	select {
+	case <-l.done:
+		return l.item, l.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
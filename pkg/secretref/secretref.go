@@ -0,0 +1,169 @@
+// Package secretref parses and resolves references to secrets stored in gopass.
+//
+// A reference names a field of an item in a vault:
+//
+//	gopass://<vault>/<item>/<field>
+//
+// such as gopass://Work/GitHub/password. The field is one of name, username,
+// password, url or notes. Names holding a slash or a percent sign must escape
+// it as %2F or %25; any other percent-encoding is decoded as well.
+//
+// Vault and item names are matched exactly, or case insensitively when there
+// is no exact match. A name matching more than one vault or item is an error
+// rather than a guess.
+package secretref
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Scheme is the prefix of every reference.
+const Scheme = "gopass://"
+
+// Fields lists the fields a reference can point to.
+var Fields = []string{"name", "username", "password", "url", "notes"}
+
+var (
+	ErrInvalidRef = errors.New("secretref: invalid reference")
+	ErrNotFound   = errors.New("secretref: not found")
+	ErrAmbiguous  = errors.New("secretref: more than one match")
+)
+
+type Ref struct {
+	Vault string
+	Item  string
+	Field string
+}
+
+// IsRef tells whether s looks like a reference, so it should be resolved instead of used as is.
+func IsRef(s string) bool {
+	return strings.HasPrefix(s, Scheme)
+}
+
+// Parse parses and validates a reference.
+func Parse(s string) (Ref, error) {
+	if !IsRef(s) {
+		return Ref{}, fmt.Errorf("%w %q: must start with %s", ErrInvalidRef, s, Scheme)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(s, Scheme), "/")
+
+	if len(parts) != 3 {
+		return Ref{}, fmt.Errorf("%w %q: must have the form %s<vault>/<item>/<field>", ErrInvalidRef, s, Scheme)
+	}
+
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+
+		if err != nil {
+			return Ref{}, fmt.Errorf("%w %q: %v", ErrInvalidRef, s, err)
+		}
+
+		parts[i] = unescaped
+	}
+
+	ref := Ref{Vault: parts[0], Item: parts[1], Field: parts[2]}
+
+	if err := ref.Validate(); err != nil {
+		return Ref{}, fmt.Errorf("%w %q: %v", ErrInvalidRef, s, err)
+	}
+
+	return ref, nil
+}
+
+// Validate checks that the vault and item are given and that the field is one of Fields.
+func (r Ref) Validate() error {
+	if strings.TrimSpace(r.Vault) == "" || strings.TrimSpace(r.Item) == "" {
+		return errors.New("vault and item are required")
+	}
+
+	for _, f := range Fields {
+		if r.Field == f {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown field %q, use one of %s", r.Field, strings.Join(Fields, ", "))
+}
+
+// String returns the reference in its textual form, escaping the names as needed.
+func (r Ref) String() string {
+	return Scheme + escape(r.Vault) + "/" + escape(r.Item) + "/" + escape(r.Field)
+}
+
+func escape(name string) string {
+	return strings.NewReplacer("%", "%25", "/", "%2F").Replace(name)
+}
+
+type Resolver interface {
+	// Resolve returns the value of the referenced field.
+	Resolve(ctx context.Context, ref Ref) (string, error)
+}
+
+// Item holds the fields of an item, keyed by the names in Fields.
+type Item map[string]string
+
+type ItemFinder interface {
+	// FindItem finds an item by vault and item name.
+	// It returns an error wrapping ErrNotFound or ErrAmbiguous when no single item matches.
+	FindItem(ctx context.Context, vault, item string) (Item, error)
+}
+
+type resolver struct {
+	finder ItemFinder
+}
+
+// NewResolver returns a resolver reading the fields of the items found by finder.
+// Each reference is looked up on its own; see NewBatchResolver to resolve many at once.
+func NewResolver(finder ItemFinder) Resolver {
+	return &resolver{finder}
+}
+
+func (r *resolver) Resolve(ctx context.Context, ref Ref) (string, error) {
+	return resolve(ctx, r.finder, ref)
+}
+
+func resolve(ctx context.Context, finder ItemFinder, ref Ref) (string, error) {
+	if err := ref.Validate(); err != nil {
+		return "", fmt.Errorf("%w %s: %v", ErrInvalidRef, ref, err)
+	}
+
+	item, err := finder.FindItem(ctx, ref.Vault, ref.Item)
+
+	if err != nil {
+		return "", err
+	}
+
+	return item[ref.Field], nil
+}
+
+// Match returns the element called name, preferring an exact match over a case insensitive one.
+// It is meant for ItemFinder implementations, so they all match names the same way.
+func Match[T any](elems []T, name string, nameOf func(T) string) (*T, error) {
+	for _, equal := range []func(a, b string) bool{
+		func(a, b string) bool { return a == b },
+		strings.EqualFold,
+	} {
+		var found []T
+
+		for _, e := range elems {
+			if equal(nameOf(e), name) {
+				found = append(found, e)
+			}
+		}
+
+		if len(found) == 1 {
+			return &found[0], nil
+		}
+
+		if len(found) > 1 {
+			return nil, ErrAmbiguous
+		}
+	}
+
+	return nil, ErrNotFound
+}
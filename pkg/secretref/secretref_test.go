@@ -0,0 +1,70 @@
+package secretref
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		ref      string
+		expected Ref
+	}{
+		{"gopass://Work/GitHub/password", Ref{"Work", "GitHub", "password"}},
+		{"gopass://My Vault/CI%2FCD token/notes", Ref{"My Vault", "CI/CD token", "notes"}},
+		{"gopass://100%25/a%20b/url", Ref{"100%", "a b", "url"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.ref, func(t *testing.T) {
+			// when
+			ref, err := Parse(tc.ref)
+
+			// then
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, ref)
+		})
+	}
+
+	invalid := []string{
+		"",
+		"https://Work/GitHub/password",
+		"gopass://Work/GitHub",
+		"gopass://Work/GitHub/password/extra",
+		"gopass://Work//password",
+		"gopass://Work/%zz/password",
+		"gopass://Work/GitHub/totp",
+		"gopass://Work/GitHub/Password",
+		"gopass:// /GitHub/password",
+	}
+	for _, s := range invalid {
+		t.Run(s, func(t *testing.T) {
+			// when
+			_, err := Parse(s)
+
+			// then
+			assert.True(t, errors.Is(err, ErrInvalidRef))
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	ref := Ref{"100% Work", "CI/CD", "password"}
+
+	assert.Equal(t, "gopass://100%25 Work/CI%2FCD/password", ref.String())
+
+	parsed, err := Parse(ref.String())
+	assert.Nil(t, err)
+	assert.Equal(t, ref, parsed)
+}
+
+func TestResolveInvalidRef(t *testing.T) {
+	resolver := NewResolver(&countingFinder{})
+
+	_, err := resolver.Resolve(context.TODO(), Ref{"Work", "GitHub", "totp"})
+
+	assert.True(t, errors.Is(err, ErrInvalidRef))
+	assert.Contains(t, err.Error(), `unknown field "totp"`)
+}
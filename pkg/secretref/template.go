@@ -0,0 +1,190 @@
+package secretref
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+// maxConcurrentLookups bounds the items looked up at the same time before rendering.
+const maxConcurrentLookups = 8
+
+// Renderer renders text/template templates that read secrets with the secret function:
+//
+//	[database]
+//	user = {{ secret "gopass://Work/Postgres/username" }}
+//	password = {{ secret "gopass://Work/Postgres/password" | printf "%q" }}
+type Renderer struct {
+	resolver Resolver
+}
+
+// NewRenderer returns a renderer looking items up with finder.
+// Items are cached for the life of the renderer, as with NewBatchResolver,
+// so a renderer should be used for one batch of templates.
+func NewRenderer(finder ItemFinder) *Renderer {
+	return &Renderer{NewBatchResolver(finder)}
+}
+
+// Render executes the template text, called name in errors, with data and writes the output to w.
+//
+// The references given to secret as string literals are resolved before the
+// template is executed, at most one lookup per item, and all those that can't
+// be resolved are reported together. Other references are resolved as the
+// template runs. Nothing is written to w when rendering fails.
+func (r *Renderer) Render(ctx context.Context, w io.Writer, name, text string, data any) error {
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"secret": func(s string) (string, error) {
+			ref, err := Parse(s)
+
+			if err != nil {
+				return "", err
+			}
+
+			return r.resolver.Resolve(ctx, ref)
+		}}).
+		Parse(text)
+
+	if err != nil {
+		return err
+	}
+
+	if err := r.prefetch(ctx, tmpl); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf.Bytes())
+
+	return err
+}
+
+// literalRef is a string literal given to the secret function.
+type literalRef struct {
+	location string
+	value    string
+}
+
+// prefetch resolves the literal references of tmpl, reporting all that fail.
+func (r *Renderer) prefetch(ctx context.Context, tmpl *template.Template) error {
+	var literals []literalRef
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil {
+			literals = collectRefs(t.Tree, t.Tree.Root, literals)
+		}
+	}
+
+	problems := make([]string, len(literals))
+	sem := make(chan struct{}, maxConcurrentLookups)
+	var wg sync.WaitGroup
+
+	for i, lit := range literals {
+		ref, err := Parse(lit.value)
+
+		if err != nil {
+			problems[i] = fmt.Sprintf("%s: %v", lit.location, err)
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int, lit literalRef, ref Ref) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if _, err := r.resolver.Resolve(ctx, ref); err != nil {
+				problems[i] = fmt.Sprintf("%s: %s: %v", lit.location, ref, err)
+			}
+		}(i, lit, ref)
+	}
+
+	wg.Wait()
+
+	var failed []string
+
+	for _, p := range problems {
+		if p != "" {
+			failed = append(failed, p)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	noun := "references"
+
+	if len(failed) == 1 {
+		noun = "reference"
+	}
+
+	return fmt.Errorf("%s: %d secret %s can't be resolved:\n\t%s", tmpl.Name(), len(failed), noun, strings.Join(failed, "\n\t"))
+}
+
+// collectRefs appends the string literals given to the secret function under node.
+func collectRefs(tree *parse.Tree, node parse.Node, refs []literalRef) []literalRef {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, child := range n.Nodes {
+			refs = collectRefs(tree, child, refs)
+		}
+	case *parse.ActionNode:
+		refs = collectRefs(tree, n.Pipe, refs)
+	case *parse.IfNode:
+		refs = collectBranch(tree, &n.BranchNode, refs)
+	case *parse.RangeNode:
+		refs = collectBranch(tree, &n.BranchNode, refs)
+	case *parse.WithNode:
+		refs = collectBranch(tree, &n.BranchNode, refs)
+	case *parse.TemplateNode:
+		refs = collectRefs(tree, n.Pipe, refs)
+	case *parse.PipeNode:
+		if n == nil {
+			return refs
+		}
+
+		for _, cmd := range n.Cmds {
+			refs = collectRefs(tree, cmd, refs)
+		}
+	case *parse.CommandNode:
+		if len(n.Args) == 2 {
+			ident, isIdent := n.Args[0].(*parse.IdentifierNode)
+			str, isString := n.Args[1].(*parse.StringNode)
+
+			if isIdent && isString && ident.Ident == "secret" {
+				location, _ := tree.ErrorContext(str)
+				refs = append(refs, literalRef{location, str.Text})
+			}
+		}
+
+		for _, arg := range n.Args {
+			refs = collectRefs(tree, arg, refs)
+		}
+	}
+
+	return refs
+}
+
+func collectBranch(tree *parse.Tree, n *parse.BranchNode, refs []literalRef) []literalRef {
+	refs = collectRefs(tree, n.Pipe, refs)
+	refs = collectRefs(tree, n.List, refs)
+
+	if n.ElseList != nil {
+		refs = collectRefs(tree, n.ElseList, refs)
+	}
+
+	return refs
+}
@@ -0,0 +1,157 @@
+package secretref
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingFinder finds items in a fixed set and counts the lookups.
+type countingFinder struct {
+	mu      sync.Mutex
+	items   map[string]Item
+	lookups map[string]int
+}
+
+func newCountingFinder() *countingFinder {
+	return &countingFinder{
+		items: map[string]Item{
+			"Work/Postgres": {"name": "Postgres", "username": "app", "password": "pg-secret", "url": "postgres://db:5432", "notes": ""},
+			"Work/Redis":    {"name": "Redis", "username": "", "password": "redis-secret", "url": "redis://cache", "notes": ""},
+		},
+		lookups: map[string]int{},
+	}
+}
+
+func (f *countingFinder) FindItem(ctx context.Context, vault, item string) (Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := vault + "/" + item
+	f.lookups[key]++
+
+	if found, ok := f.items[key]; ok {
+		return found, nil
+	}
+
+	return nil, fmt.Errorf("item %q in vault %q: %w", item, vault, ErrNotFound)
+}
+
+func TestBatchResolver(t *testing.T) {
+	// given
+	finder := newCountingFinder()
+	resolver := NewBatchResolver(finder)
+
+	// when
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			field := Fields[i%len(Fields)]
+			_, err := resolver.Resolve(context.TODO(), Ref{"Work", "Postgres", field})
+			assert.Nil(t, err)
+
+			_, err = resolver.Resolve(context.TODO(), Ref{"Work", "Missing", field})
+			assert.ErrorIs(t, err, ErrNotFound)
+		}(i)
+	}
+
+	wg.Wait()
+
+	// then
+	assert.Equal(t, map[string]int{"Work/Postgres": 1, "Work/Missing": 1}, finder.lookups)
+}
+
+func TestRender(t *testing.T) {
+	t.Run("one lookup per item", func(t *testing.T) {
+		// given
+		finder := newCountingFinder()
+		renderer := NewRenderer(finder)
+
+		var text strings.Builder
+
+		for i := 0; i < 25; i++ {
+			text.WriteString(`{{ secret "gopass://Work/Postgres/password" }} {{ secret "gopass://Work/Redis/url" }}` + "\n")
+		}
+
+		// when
+		var buf bytes.Buffer
+		err := renderer.Render(context.TODO(), &buf, "config.tmpl", text.String(), nil)
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, strings.Repeat("pg-secret redis://cache\n", 25), buf.String())
+		assert.Equal(t, map[string]int{"Work/Postgres": 1, "Work/Redis": 1}, finder.lookups)
+	})
+
+	t.Run("control structures and data", func(t *testing.T) {
+		// given
+		renderer := NewRenderer(newCountingFinder())
+		text := `{{ define "db" }}user={{ secret "gopass://Work/Postgres/username" }}{{ end -}}
+{{ range .Envs }}[{{ . }}]
+{{ template "db" }}
+{{ if eq . "prod" }}password={{ secret "gopass://Work/Postgres/password" | printf "%q" }}{{ else }}password=dev{{ end }}
+{{ end }}`
+
+		// when
+		var buf bytes.Buffer
+		err := renderer.Render(context.TODO(), &buf, "config.tmpl", text, map[string][]string{"Envs": {"dev", "prod"}})
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, "[dev]\nuser=app\npassword=dev\n[prod]\nuser=app\npassword=\"pg-secret\"\n", buf.String())
+	})
+
+	t.Run("dynamic references", func(t *testing.T) {
+		// given
+		renderer := NewRenderer(newCountingFinder())
+		text := `{{ range .Items }}{{ secret (printf "gopass://Work/%s/password" .) }};{{ end }}`
+
+		// when
+		var buf bytes.Buffer
+		err := renderer.Render(context.TODO(), &buf, "dynamic.tmpl", text, map[string][]string{"Items": {"Postgres", "Redis"}})
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, "pg-secret;redis-secret;", buf.String())
+	})
+
+	t.Run("reports all broken references", func(t *testing.T) {
+		// given
+		renderer := NewRenderer(newCountingFinder())
+		text := "a={{ secret \"gopass://Work/Postgres/password\" }}\n" +
+			"b={{ secret \"gopass://Work/Missing/password\" }}\n" +
+			"c={{ secret \"gopass://Work/Postgres/totp\" }}\n"
+
+		// when
+		var buf bytes.Buffer
+		err := renderer.Render(context.TODO(), &buf, "config.tmpl", text, nil)
+
+		// then
+		assert.Equal(t, "config.tmpl: 2 secret references can't be resolved:\n"+
+			"\tconfig.tmpl:2:12: gopass://Work/Missing/password: item \"Missing\" in vault \"Work\": secretref: not found\n"+
+			"\tconfig.tmpl:3:12: secretref: invalid reference \"gopass://Work/Postgres/totp\": unknown field \"totp\", use one of name, username, password, url, notes",
+			err.Error())
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("template error", func(t *testing.T) {
+		// given
+		renderer := NewRenderer(newCountingFinder())
+
+		// when
+		err := renderer.Render(context.TODO(), &bytes.Buffer{}, "broken.tmpl", "{{ secret }", nil)
+
+		// then
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,130 @@
+// Package send provides the encryption and the links of sends, secrets shared
+// with people who may have no account. Sends are encrypted by the client with
+// a random key carried in the fragment of their link, which browsers and
+// clients never send to the server, so the server only stores ciphertexts.
+package send
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/edgardjr92/gopass/pkg/seal"
+	"golang.org/x/crypto/hkdf"
+)
+
+// kdfParams are the Argon2id parameters used to derive a key from the access password.
+var kdfParams = seal.DefaultKDFParams
+
+// ErrInvalidLink is returned by ParseLink for links that aren't send links.
+var ErrInvalidLink = errors.New("send: invalid link")
+
+// NewKey returns a random key for a send.
+func NewKey() ([]byte, error) {
+	return seal.NewKey()
+}
+
+// Seal encrypts plaintext with the key of a send and, when it isn't empty,
+// the access password, so a leaked link alone doesn't disclose the send.
+// The nonce is prepended to the ciphertext returned.
+func Seal(key []byte, password string, plaintext []byte) ([]byte, error) {
+	contentKey, err := derive(key, passwordKey(key, password), "gopass send content")
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, ciphertext, err := seal.Seal(contentKey, plaintext, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(nonce, ciphertext...), nil
+}
+
+// Open decrypts a ciphertext produced by Seal. It returns seal.ErrDecrypt
+// when the key or the password is wrong.
+func Open(key []byte, password string, sealed []byte) ([]byte, error) {
+	if len(sealed) < seal.NonceSize {
+		return nil, seal.ErrDecrypt
+	}
+
+	contentKey, err := derive(key, passwordKey(key, password), "gopass send content")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return seal.Open(contentKey, sealed[:seal.NonceSize], sealed[seal.NonceSize:], nil)
+}
+
+// AccessKey derives from the access password the key the server checks
+// before handing out a send, so it never sees the password. It is empty
+// when password is.
+func AccessKey(key []byte, password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+
+	accessKey, err := derive(passwordKey(key, password), nil, "gopass send access")
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(accessKey), nil
+}
+
+// Link returns the link of a send served at baseURL, as in
+// https://gopass.example.com/send/{accessID}#{key}.
+func Link(baseURL, accessID string, key []byte) string {
+	return strings.TrimRight(baseURL, "/") + "/send/" + accessID + "#" + base64.RawURLEncoding.EncodeToString(key)
+}
+
+// ParseLink returns the URL of the server, the access ID and the key of a send link.
+func ParseLink(link string) (string, string, []byte, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return "", "", nil, ErrInvalidLink
+	}
+
+	base, accessID, ok := strings.Cut(u.Path, "/send/")
+
+	if !ok || accessID == "" || strings.Contains(accessID, "/") {
+		return "", "", nil, ErrInvalidLink
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(u.Fragment)
+
+	if err != nil || len(key) != seal.KeySize {
+		return "", "", nil, ErrInvalidLink
+	}
+
+	return u.Scheme + "://" + u.Host + base, accessID, key, nil
+}
+
+// passwordKey derives a key from the access password, salted with the key of
+// the send. It is nil when password is empty.
+func passwordKey(key []byte, password string) []byte {
+	if password == "" {
+		return nil
+	}
+
+	return seal.DeriveKey(password, key, kdfParams)
+}
+
+// derive derives a key for the given purpose from secret and salt.
+func derive(secret, salt []byte, info string) ([]byte, error) {
+	key := make([]byte, seal.KeySize)
+
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
@@ -0,0 +1,110 @@
+package send
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/edgardjr92/gopass/pkg/seal"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	kdfParams = seal.KDFParams{Time: 1, Memory: 64, Threads: 1}
+}
+
+func TestSealAndOpen(t *testing.T) {
+	key, _ := NewKey()
+	otherKey, _ := NewKey()
+	plaintext := []byte("wifi password: hunter2")
+
+	testCases := []struct {
+		name     string
+		password string
+		openKey  []byte
+		openWith string
+		expected error
+	}{
+		{"without password", "", key, "", nil},
+		{"with password", "correct horse", key, "correct horse", nil},
+		{"wrong key", "", otherKey, "", seal.ErrDecrypt},
+		{"wrong password", "correct horse", key, "wrong horse", seal.ErrDecrypt},
+		{"missing password", "correct horse", key, "", seal.ErrDecrypt},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			sealed, err := Seal(key, tc.password, plaintext)
+			assert.Nil(t, err)
+			assert.False(t, bytes.Contains(sealed, plaintext))
+
+			actual, err := Open(tc.openKey, tc.openWith, sealed)
+
+			assert.Equal(t, tc.expected, err)
+			if tc.expected == nil {
+				assert.Equal(t, plaintext, actual)
+			}
+		})
+	}
+
+	_, err := Open(key, "", []byte("short"))
+	assert.Equal(t, seal.ErrDecrypt, err)
+}
+
+func TestAccessKey(t *testing.T) {
+	key, _ := NewKey()
+	otherKey, _ := NewKey()
+
+	accessKey, err := AccessKey(key, "correct horse")
+	assert.Nil(t, err)
+
+	same, _ := AccessKey(key, "correct horse")
+	otherPassword, _ := AccessKey(key, "wrong horse")
+	otherSend, _ := AccessKey(otherKey, "correct horse")
+	none, _ := AccessKey(key, "")
+
+	assert.Equal(t, accessKey, same)
+	assert.NotEqual(t, accessKey, otherPassword)
+	assert.NotEqual(t, accessKey, otherSend)
+	assert.NotContains(t, accessKey, "horse")
+	assert.Empty(t, none)
+}
+
+func TestLink(t *testing.T) {
+	key := bytes.Repeat([]byte{0xfb}, seal.KeySize)
+
+	link := Link("https://gopass.example.com/", "abc123", key)
+
+	assert.Equal(t, "https://gopass.example.com/send/abc123#-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_s", link)
+
+	baseURL, accessID, actual, err := ParseLink(link)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://gopass.example.com", baseURL)
+	assert.Equal(t, "abc123", accessID)
+	assert.Equal(t, key, actual)
+
+	baseURL, _, _, err = ParseLink(Link("http://localhost:8080/gopass", "abc123", key))
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:8080/gopass", baseURL)
+}
+
+func TestParseInvalidLink(t *testing.T) {
+	testCases := []struct {
+		name string
+		link string
+	}{
+		{"relative", "/send/abc123#-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_s"},
+		{"not a send", "https://gopass.example.com/vaults/1#-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_s"},
+		{"no access ID", "https://gopass.example.com/send/#-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_s"},
+		{"no key", "https://gopass.example.com/send/abc123"},
+		{"short key", "https://gopass.example.com/send/abc123#-_v7"},
+		{"invalid key", "https://gopass.example.com/send/abc123#not base64!"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, _, err := ParseLink(tc.link)
+
+			assert.Equal(t, ErrInvalidLink, err)
+		})
+	}
+}
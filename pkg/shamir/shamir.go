@@ -0,0 +1,142 @@
+// Package shamir splits secrets into shares with Shamir's secret sharing
+// scheme over GF(256), any threshold of which rebuild the secret while fewer
+// reveal nothing about it.
+//
+// Each byte of the secret is the constant term of a random polynomial of
+// degree threshold-1. A share is the x coordinate, one byte from 1 to 255,
+// followed by the values of the polynomials at x.
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+// MaxShares is the maximum number of shares of a secret.
+const MaxShares = 255
+
+var (
+	// ErrInvalidShares is returned by Combine for shares that aren't shares of the same secret.
+	ErrInvalidShares = errors.New("shamir: invalid shares")
+	// ErrTooFewShares is returned by Combine for less than two shares.
+	ErrTooFewShares = errors.New("shamir: at least two shares are required")
+)
+
+// Split splits secret into n shares, any k of which rebuild it with Combine.
+// k must be at least 2 and at most n, which must be at most MaxShares.
+func Split(secret []byte, n, k int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("shamir: secret is empty")
+	}
+
+	if n < 2 || n > MaxShares {
+		return nil, errors.New("shamir: shares must be between 2 and 255")
+	}
+
+	if k < 2 || k > n {
+		return nil, errors.New("shamir: threshold must be between 2 and the number of shares")
+	}
+
+	shares := make([][]byte, n)
+
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][0] = byte(i + 1)
+	}
+
+	coefficients := make([]byte, k)
+
+	for j, b := range secret {
+		coefficients[0] = b
+
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+
+		for _, share := range shares {
+			share[j+1] = evaluate(coefficients, share[0])
+		}
+	}
+
+	return shares, nil
+}
+
+// Combine rebuilds a secret from shares returned by Split. Given fewer shares
+// than the threshold, it returns a wrong secret: the shares don't record the
+// threshold, nor detect it.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrTooFewShares
+	}
+
+	size := len(shares[0])
+	seen := make(map[byte]bool, len(shares))
+
+	for _, share := range shares {
+		if len(share) != size || size < 2 || share[0] == 0 || seen[share[0]] {
+			return nil, ErrInvalidShares
+		}
+
+		seen[share[0]] = true
+	}
+
+	secret := make([]byte, size-1)
+
+	// Lagrange interpolation at 0: the secret is the sum of y_i * l_i(0), where
+	// l_i(0) is the product of x_j / (x_j - x_i) for j != i. In GF(256)
+	// subtraction is addition, which is XOR.
+	for i, share := range shares {
+		basis := byte(1)
+
+		for j, other := range shares {
+			if i != j {
+				basis = mul(basis, div(other[0], other[0]^share[0]))
+			}
+		}
+
+		for k := range secret {
+			secret[k] ^= mul(share[k+1], basis)
+		}
+	}
+
+	return secret, nil
+}
+
+// evaluate returns the value at x of the polynomial with coefficients, lowest degree first.
+func evaluate(coefficients []byte, x byte) byte {
+	var y byte
+
+	// Horner's method.
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = mul(y, x) ^ coefficients[i]
+	}
+
+	return y
+}
+
+// mul multiplies in GF(256) modulo the AES polynomial x^8 + x^4 + x^3 + x + 1.
+// It doesn't branch on its operands, which may be secret.
+func mul(a, b byte) byte {
+	var product byte
+
+	for i := 0; i < 8; i++ {
+		product ^= -(b & 1) & a
+		carry := -(a >> 7)
+		a = a<<1 ^ carry&0x1b
+		b >>= 1
+	}
+
+	return product
+}
+
+// div divides a by b, which must not be zero, in GF(256).
+func div(a, b byte) byte {
+	// b^254 is the inverse of b, since b^255 = 1.
+	inverse := b
+
+	for i := 0; i < 6; i++ {
+		inverse = mul(mul(inverse, inverse), b)
+	}
+
+	return mul(a, mul(inverse, inverse))
+}
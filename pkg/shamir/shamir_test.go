@@ -0,0 +1,92 @@
+package shamir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitAndCombine(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	shares, err := Split(secret, 5, 3)
+	assert.Nil(t, err)
+	assert.Len(t, shares, 5)
+
+	for i, share := range shares {
+		assert.Len(t, share, len(secret)+1)
+		assert.Equal(t, byte(i+1), share[0])
+	}
+
+	// then: any threshold of shares, in any order, rebuild the secret
+	subsets := [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}}
+	for _, subset := range subsets {
+		var selected [][]byte
+		for _, i := range subset {
+			selected = append(selected, shares[i])
+		}
+
+		actual, err := Combine(selected)
+		assert.Nil(t, err)
+		assert.Equal(t, secret, actual)
+	}
+
+	// then: fewer shares don't
+	actual, err := Combine(shares[:2])
+	assert.Nil(t, err)
+	assert.NotEqual(t, secret, actual)
+
+	// then: each split uses new polynomials
+	again, _ := Split(secret, 5, 3)
+	assert.NotEqual(t, shares, again)
+}
+
+func TestSplitErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		secret   []byte
+		n, k     int
+		expected string
+	}{
+		{"empty secret", nil, 3, 2, "shamir: secret is empty"},
+		{"one share", []byte("s"), 1, 1, "shamir: shares must be between 2 and 255"},
+		{"too many shares", []byte("s"), 256, 2, "shamir: shares must be between 2 and 255"},
+		{"threshold of one", []byte("s"), 3, 1, "shamir: threshold must be between 2 and the number of shares"},
+		{"threshold above shares", []byte("s"), 3, 4, "shamir: threshold must be between 2 and the number of shares"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := Split(tc.secret, tc.n, tc.k)
+
+			assert.Nil(t, actual)
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	shares, _ := Split([]byte("secret"), 3, 2)
+
+	_, err := Combine(shares[:1])
+	assert.Equal(t, ErrTooFewShares, err)
+
+	_, err = Combine([][]byte{shares[0], shares[0]})
+	assert.Equal(t, ErrInvalidShares, err)
+
+	_, err = Combine([][]byte{shares[0], shares[1][:3]})
+	assert.Equal(t, ErrInvalidShares, err)
+
+	_, err = Combine([][]byte{shares[0], append([]byte{0}, shares[1][1:]...)})
+	assert.Equal(t, ErrInvalidShares, err)
+}
+
+func TestField(t *testing.T) {
+	// then: the multiplication of FIPS 197, section 4.2
+	assert.Equal(t, byte(0xc1), mul(0x57, 0x83))
+	assert.Equal(t, byte(0xfe), mul(0x57, 0x13))
+
+	for a := 1; a < 256; a++ {
+		assert.Equal(t, byte(1), div(byte(a), byte(a)))
+		assert.Equal(t, byte(a), mul(div(byte(a), 0x53), 0x53))
+	}
+}
@@ -0,0 +1,363 @@
+// Package sshagent implements an ssh-agent serving the SSH keys stored in gopass items.
+//
+// The agent loads the keys of the items when a client lists them and keeps
+// them in memory only. Each signature needs a confirmation, unless the key has
+// been confirmed within the unlock TTL. Keys can't be added or removed through
+// the agent: they are managed as gopass items.
+package sshagent
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var (
+	// ErrReadOnly is returned when a client tries to add or remove keys.
+	ErrReadOnly = errors.New("sshagent: keys are managed in gopass")
+	// ErrLocked is returned when signing with a locked agent.
+	ErrLocked = errors.New("sshagent: agent is locked")
+	// ErrUnknownKey is returned when signing with a key the agent doesn't have.
+	ErrUnknownKey = errors.New("sshagent: unknown key")
+	// ErrDenied is returned when the use of a key isn't confirmed.
+	ErrDenied = errors.New("sshagent: use of the key denied")
+)
+
+// Key describes a key the agent serves, as shown when asking for a confirmation.
+type Key struct {
+	ItemID      uint
+	Vault       string
+	Name        string
+	Fingerprint string
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s/%s (%s)", k.Vault, k.Name, k.Fingerprint)
+}
+
+// ConfirmFunc asks whether a key may be used to sign. A false answer denies the signature.
+type ConfirmFunc func(ctx context.Context, key Key) (bool, error)
+
+// Options configures how an Agent confirms the use of keys.
+type Options struct {
+	// Confirm is called before every signature, unless the key is unlocked.
+	// Without it, every signature is denied.
+	Confirm ConfirmFunc
+	// TTL is how long a key stays unlocked after its use is confirmed.
+	// A zero TTL asks for a confirmation on every signature.
+	TTL   time.Duration
+	Clock clock.Clock
+}
+
+type loadedKey struct {
+	Key
+	signer ssh.Signer
+	// unlockedUntil is the time the key needs a confirmation again.
+	unlockedUntil time.Time
+}
+
+// Agent implements agent.ExtendedAgent. It is safe for concurrent use.
+type Agent struct {
+	source  Source
+	options Options
+
+	mu         sync.Mutex
+	keys       map[string]*loadedKey
+	passphrase []byte
+	// confirmMu makes confirmations ask one at a time.
+	confirmMu sync.Mutex
+}
+
+var _ agent.ExtendedAgent = (*Agent)(nil)
+
+func New(source Source, options Options) *Agent {
+	return &Agent{source: source, options: options, keys: map[string]*loadedKey{}}
+}
+
+// List loads the keys from the source and returns their public keys.
+// A locked agent lists no keys.
+func (a *Agent) List() ([]*agent.Key, error) {
+	if a.isLocked() {
+		return []*agent.Key{}, nil
+	}
+
+	keys, err := a.load(context.Background())
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*agent.Key, len(keys))
+
+	for i, k := range keys {
+		pub := k.signer.PublicKey()
+		list[i] = &agent.Key{Format: pub.Type(), Blob: pub.Marshal(), Comment: k.Vault + "/" + k.Name}
+	}
+
+	return list, nil
+}
+
+func (a *Agent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+// SignWithFlags signs data with the key, once its use is confirmed.
+// The flags select the SHA-2 signatures of RSA keys.
+func (a *Agent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	ctx := context.Background()
+	k, err := a.find(ctx, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.authorize(ctx, k); err != nil {
+		return nil, err
+	}
+
+	algorithm := ""
+
+	switch {
+	case flags&agent.SignatureFlagRsaSha256 != 0:
+		algorithm = ssh.KeyAlgoRSASHA256
+	case flags&agent.SignatureFlagRsaSha512 != 0:
+		algorithm = ssh.KeyAlgoRSASHA512
+	}
+
+	if algorithm == "" || k.signer.PublicKey().Type() != ssh.KeyAlgoRSA {
+		return k.signer.Sign(nil, data)
+	}
+
+	signer, ok := k.signer.(ssh.AlgorithmSigner)
+
+	if !ok {
+		return nil, fmt.Errorf("sshagent: key %s can't sign with %s", k.Key, algorithm)
+	}
+
+	return signer.SignWithAlgorithm(nil, data, algorithm)
+}
+
+// Signers isn't supported: private keys never leave the agent.
+func (a *Agent) Signers() ([]ssh.Signer, error) {
+	return nil, ErrReadOnly
+}
+
+func (a *Agent) Add(key agent.AddedKey) error {
+	return ErrReadOnly
+}
+
+func (a *Agent) Remove(key ssh.PublicKey) error {
+	return ErrReadOnly
+}
+
+// RemoveAll forgets the loaded keys and their unlocks. The items aren't changed.
+func (a *Agent) RemoveAll() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.keys = map[string]*loadedKey{}
+
+	return nil
+}
+
+// Lock forgets the loaded keys and refuses to sign until Unlock is called with the same passphrase.
+func (a *Agent) Lock(passphrase []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.passphrase != nil {
+		return errors.New("sshagent: agent is already locked")
+	}
+
+	a.passphrase = append([]byte{}, passphrase...)
+	a.keys = map[string]*loadedKey{}
+
+	return nil
+}
+
+func (a *Agent) Unlock(passphrase []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.passphrase == nil {
+		return errors.New("sshagent: agent is not locked")
+	}
+
+	if subtle.ConstantTimeCompare(a.passphrase, passphrase) != 1 {
+		return errors.New("sshagent: incorrect passphrase")
+	}
+
+	a.passphrase = nil
+
+	return nil
+}
+
+func (a *Agent) Extension(extensionType string, contents []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
+
+func (a *Agent) isLocked() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.passphrase != nil
+}
+
+// load replaces the loaded keys with the ones of the source, keeping the unlocks of the remaining keys.
+// Items whose key can't be parsed are skipped.
+func (a *Agent) load(ctx context.Context) ([]*loadedKey, error) {
+	items, err := a.source.KeyItems(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*loadedKey, 0, len(items))
+
+	for _, item := range items {
+		signer, err := parseSigner(item)
+
+		if err != nil {
+			log.Printf("skipping ssh key of item %d: %v", item.ItemID, err)
+			continue
+		}
+
+		keys = append(keys, &loadedKey{
+			Key: Key{
+				ItemID:      item.ItemID,
+				Vault:       item.Vault,
+				Name:        item.Name,
+				Fingerprint: ssh.FingerprintSHA256(signer.PublicKey()),
+			},
+			signer: signer,
+		})
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// The agent may have been locked while the keys were loading.
+	if a.passphrase != nil {
+		return nil, ErrLocked
+	}
+
+	loaded := make(map[string]*loadedKey, len(keys))
+
+	for _, k := range keys {
+		blob := string(k.signer.PublicKey().Marshal())
+
+		if old, ok := a.keys[blob]; ok {
+			k.unlockedUntil = old.unlockedUntil
+		}
+
+		loaded[blob] = k
+	}
+
+	a.keys = loaded
+
+	return keys, nil
+}
+
+// find returns the loaded key, reloading the keys from the source when it isn't loaded yet.
+func (a *Agent) find(ctx context.Context, key ssh.PublicKey) (*loadedKey, error) {
+	blob := key.Marshal()
+
+	a.mu.Lock()
+	locked := a.passphrase != nil
+	k, ok := a.keys[string(blob)]
+	a.mu.Unlock()
+
+	if locked {
+		return nil, ErrLocked
+	}
+
+	if ok {
+		return k, nil
+	}
+
+	keys, err := a.load(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		if bytes.Equal(k.signer.PublicKey().Marshal(), blob) {
+			return k, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// authorize returns nil when the key is unlocked or its use is confirmed.
+func (a *Agent) authorize(ctx context.Context, k *loadedKey) error {
+	a.confirmMu.Lock()
+	defer a.confirmMu.Unlock()
+
+	a.mu.Lock()
+	unlocked := a.options.Clock.Now().Before(k.unlockedUntil)
+	a.mu.Unlock()
+
+	if unlocked {
+		return nil
+	}
+
+	if a.options.Confirm == nil {
+		return ErrDenied
+	}
+
+	ok, err := a.options.Confirm(ctx, k.Key)
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrDenied
+	}
+
+	if a.options.TTL > 0 {
+		a.mu.Lock()
+		k.unlockedUntil = a.options.Clock.Now().Add(a.options.TTL)
+		a.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Fingerprint returns the SHA256 fingerprint of the public key of the item.
+func (item KeyItem) Fingerprint() (string, error) {
+	signer, err := parseSigner(item)
+
+	if err != nil {
+		return "", err
+	}
+
+	return ssh.FingerprintSHA256(signer.PublicKey()), nil
+}
+
+func parseSigner(item KeyItem) (ssh.Signer, error) {
+	key, err := ssh.ParseRawPrivateKey(item.PrivateKey)
+
+	var missing *ssh.PassphraseMissingError
+
+	if errors.As(err, &missing) {
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(item.PrivateKey, item.Passphrase)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ssh.NewSignerFromKey(key)
+}
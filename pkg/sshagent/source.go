@@ -0,0 +1,88 @@
+package sshagent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+	"github.com/edgardjr92/gopass/pkg/secretref"
+)
+
+// KeyItem is an item holding an SSH key.
+type KeyItem struct {
+	ItemID uint
+	Vault  string
+	Name   string
+	// PrivateKey is the OpenSSH or PEM private key of the item.
+	PrivateKey []byte
+	// Passphrase decrypts PrivateKey when it is encrypted.
+	Passphrase []byte
+}
+
+// Source loads the SSH key items served by the agent.
+type Source interface {
+	KeyItems(ctx context.Context) ([]KeyItem, error)
+}
+
+// Store is the part of the gopass API used by VaultSource, implemented by *client.Client.
+type Store interface {
+	Vaults(ctx context.Context) ([]client.Vault, error)
+	Items(ctx context.Context, vaultID uint) ([]client.Item, error)
+}
+
+// VaultSource loads the SSH key items of the vaults of a user.
+type VaultSource struct {
+	Store Store
+	// Vaults are the names of the vaults to load the keys from, all of them when empty.
+	Vaults []string
+}
+
+func (s *VaultSource) KeyItems(ctx context.Context) ([]KeyItem, error) {
+	vaults, err := s.Store.Vaults(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.Vaults) > 0 {
+		selected := make([]client.Vault, 0, len(s.Vaults))
+
+		for _, name := range s.Vaults {
+			vault, err := secretref.Match(vaults, name, func(v client.Vault) string { return v.Name })
+
+			if err != nil {
+				return nil, fmt.Errorf("vault %q: %w", name, err)
+			}
+
+			selected = append(selected, *vault)
+		}
+
+		vaults = selected
+	}
+
+	var keys []KeyItem
+
+	for _, vault := range vaults {
+		items, err := s.Store.Items(ctx, vault.ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			if item.SSHKey == "" {
+				continue
+			}
+
+			keys = append(keys, KeyItem{
+				ItemID:     item.ID,
+				Vault:      vault.Name,
+				Name:       item.Name,
+				PrivateKey: []byte(item.SSHKey),
+				Passphrase: []byte(item.Password),
+			})
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,357 @@
+package sshagent
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgardjr92/gopass/pkg/client"
+	"github.com/edgardjr92/gopass/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+type sourceFunc func(ctx context.Context) ([]KeyItem, error)
+
+func (f sourceFunc) KeyItems(ctx context.Context) ([]KeyItem, error) {
+	return f(ctx)
+}
+
+func staticSource(items ...KeyItem) Source {
+	return sourceFunc(func(ctx context.Context) ([]KeyItem, error) { return items, nil })
+}
+
+func ed25519Item(t *testing.T, id uint, name string) (KeyItem, ssh.PublicKey) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	assert.Nil(t, err)
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	assert.Nil(t, err)
+
+	return KeyItem{
+		ItemID:     id,
+		Vault:      "Work",
+		Name:       name,
+		PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+	}, sshPub
+}
+
+// newClient serves the agent on an in-memory connection, as ssh would talk to it.
+func newClient(t *testing.T, a agent.Agent) agent.ExtendedAgent {
+	c1, c2 := net.Pipe()
+	t.Cleanup(func() { c1.Close(); c2.Close() })
+
+	go agent.ServeAgent(a, c2)
+
+	return agent.NewClient(c1)
+}
+
+func alwaysConfirm(confirmed *[]Key) ConfirmFunc {
+	return func(ctx context.Context, key Key) (bool, error) {
+		*confirmed = append(*confirmed, key)
+		return true, nil
+	}
+}
+
+func TestList(t *testing.T) {
+	// given
+	item, pub := ed25519Item(t, 10, "deploy")
+	broken := KeyItem{ItemID: 11, Vault: "Work", Name: "broken", PrivateKey: []byte("not a key")}
+	c := newClient(t, New(staticSource(item, broken), Options{}))
+
+	// when
+	keys, err := c.List()
+
+	// then
+	assert.Nil(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, pub.Marshal(), keys[0].Blob)
+	assert.Equal(t, "Work/deploy", keys[0].Comment)
+}
+
+func TestSign(t *testing.T) {
+	item, pub := ed25519Item(t, 10, "deploy")
+	data := []byte("challenge")
+
+	t.Run("confirmed", func(t *testing.T) {
+		// given
+		var confirmed []Key
+		c := newClient(t, New(staticSource(item), Options{Confirm: alwaysConfirm(&confirmed)}))
+
+		// when
+		sig, err := c.Sign(pub, data)
+
+		// then
+		assert.Nil(t, err)
+		assert.Nil(t, pub.Verify(data, sig))
+		assert.Equal(t, []Key{{ItemID: 10, Vault: "Work", Name: "deploy", Fingerprint: ssh.FingerprintSHA256(pub)}}, confirmed)
+	})
+
+	t.Run("confirmation on every use", func(t *testing.T) {
+		// given
+		var confirmed []Key
+		c := newClient(t, New(staticSource(item), Options{Confirm: alwaysConfirm(&confirmed)}))
+
+		// when
+		_, err1 := c.Sign(pub, data)
+		_, err2 := c.Sign(pub, data)
+
+		// then
+		assert.Nil(t, err1)
+		assert.Nil(t, err2)
+		assert.Len(t, confirmed, 2)
+	})
+
+	t.Run("unlocked for the ttl", func(t *testing.T) {
+		// given
+		now := time.Date(2023, 5, 6, 10, 0, 0, 0, time.UTC)
+		var confirmed []Key
+		a := New(staticSource(item), Options{
+			Confirm: alwaysConfirm(&confirmed),
+			TTL:     time.Minute,
+			Clock:   clock.Clock{NowFn: func() time.Time { return now }},
+		})
+		c := newClient(t, a)
+
+		// when
+		_, err := c.Sign(pub, data)
+		assert.Nil(t, err)
+
+		now = now.Add(59 * time.Second)
+		_, err = c.Sign(pub, data)
+		assert.Nil(t, err)
+
+		// then
+		assert.Len(t, confirmed, 1)
+
+		now = now.Add(time.Second)
+		_, err = c.Sign(pub, data)
+		assert.Nil(t, err)
+		assert.Len(t, confirmed, 2)
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		// given
+		a := New(staticSource(item), Options{Confirm: func(ctx context.Context, key Key) (bool, error) { return false, nil }})
+
+		// when
+		_, err := a.Sign(pub, data)
+
+		// then
+		assert.Equal(t, ErrDenied, err)
+	})
+
+	t.Run("without confirmation", func(t *testing.T) {
+		// when
+		_, err := New(staticSource(item), Options{TTL: time.Hour}).Sign(pub, data)
+
+		// then
+		assert.Equal(t, ErrDenied, err)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		// given
+		_, other := ed25519Item(t, 12, "other")
+
+		// when
+		_, err := New(staticSource(item), Options{}).Sign(other, data)
+
+		// then
+		assert.Equal(t, ErrUnknownKey, err)
+	})
+
+	t.Run("source error", func(t *testing.T) {
+		// given
+		source := sourceFunc(func(ctx context.Context) ([]KeyItem, error) { return nil, errors.New("unauthorized") })
+
+		// when
+		_, err := New(source, Options{}).Sign(pub, data)
+
+		// then
+		assert.Equal(t, "unauthorized", err.Error())
+	})
+}
+
+func TestSignEncryptedRSAKey(t *testing.T) {
+	// given
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	// Legacy encrypted PEM keys are deprecated but still found in the wild.
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key), []byte("passphrase"), x509.PEMCipherAES256)
+	assert.Nil(t, err)
+
+	item := KeyItem{ItemID: 10, Vault: "Work", Name: "legacy", PrivateKey: pem.EncodeToMemory(block), Passphrase: []byte("passphrase")}
+	pub, err := ssh.NewPublicKey(&key.PublicKey)
+	assert.Nil(t, err)
+
+	var confirmed []Key
+	c := newClient(t, New(staticSource(item), Options{Confirm: alwaysConfirm(&confirmed)}))
+
+	// when
+	sig, err := c.SignWithFlags(pub, []byte("challenge"), agent.SignatureFlagRsaSha512)
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, ssh.KeyAlgoRSASHA512, sig.Format)
+	assert.Nil(t, pub.Verify([]byte("challenge"), sig))
+}
+
+func TestLock(t *testing.T) {
+	// given
+	item, pub := ed25519Item(t, 10, "deploy")
+	var confirmed []Key
+	c := newClient(t, New(staticSource(item), Options{Confirm: alwaysConfirm(&confirmed)}))
+
+	// when
+	assert.Nil(t, c.Lock([]byte("secret")))
+
+	// then
+	keys, err := c.List()
+	assert.Nil(t, err)
+	assert.Empty(t, keys)
+
+	_, err = c.Sign(pub, []byte("challenge"))
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, c.Unlock([]byte("wrong")))
+	assert.Nil(t, c.Unlock([]byte("secret")))
+
+	_, err = c.Sign(pub, []byte("challenge"))
+	assert.Nil(t, err)
+}
+
+func TestReadOnly(t *testing.T) {
+	// given
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	item, pub := ed25519Item(t, 10, "deploy")
+	c := newClient(t, New(staticSource(item), Options{}))
+
+	// then
+	assert.NotNil(t, c.Add(agent.AddedKey{PrivateKey: priv}))
+	assert.NotNil(t, c.Remove(pub))
+	assert.Nil(t, c.RemoveAll())
+
+	keys, err := c.List()
+	assert.Nil(t, err)
+	assert.Len(t, keys, 1)
+}
+
+type fakeStore struct {
+	vaults []client.Vault
+	items  map[uint][]client.Item
+}
+
+func (s *fakeStore) Vaults(ctx context.Context) ([]client.Vault, error) {
+	return s.vaults, nil
+}
+
+func (s *fakeStore) Items(ctx context.Context, vaultID uint) ([]client.Item, error) {
+	return s.items[vaultID], nil
+}
+
+func TestVaultSource(t *testing.T) {
+	store := &fakeStore{
+		vaults: []client.Vault{{ID: 1, Name: "Work"}, {ID: 2, Name: "Personal"}},
+		items: map[uint][]client.Item{
+			1: {{ID: 10, Name: "deploy", SSHKey: "key", Password: "passphrase"}, {ID: 11, Name: "GitHub", Password: "secret"}},
+			2: {{ID: 20, Name: "home", SSHKey: "home-key"}},
+		},
+	}
+
+	t.Run("all vaults", func(t *testing.T) {
+		// when
+		keys, err := (&VaultSource{Store: store}).KeyItems(context.TODO())
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, []KeyItem{
+			{ItemID: 10, Vault: "Work", Name: "deploy", PrivateKey: []byte("key"), Passphrase: []byte("passphrase")},
+			{ItemID: 20, Vault: "Personal", Name: "home", PrivateKey: []byte("home-key"), Passphrase: []byte{}},
+		}, keys)
+	})
+
+	t.Run("selected vaults", func(t *testing.T) {
+		// when
+		keys, err := (&VaultSource{Store: store, Vaults: []string{"personal"}}).KeyItems(context.TODO())
+
+		// then
+		assert.Nil(t, err)
+		assert.Len(t, keys, 1)
+		assert.Equal(t, uint(20), keys[0].ItemID)
+	})
+
+	t.Run("missing vault", func(t *testing.T) {
+		// when
+		_, err := (&VaultSource{Store: store, Vaults: []string{"Ops"}}).KeyItems(context.TODO())
+
+		// then
+		assert.Equal(t, `vault "Ops": secretref: not found`, err.Error())
+	})
+}
+
+func TestServe(t *testing.T) {
+	// given
+	item, pub := ed25519Item(t, 10, "deploy")
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := Listen(path)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+
+	go func() {
+		served <- Serve(ctx, l, New(staticSource(item), Options{}))
+	}()
+
+	// when
+	conn, err := net.Dial("unix", path)
+	assert.Nil(t, err)
+
+	keys, err := agent.NewClient(conn).List()
+	conn.Close()
+
+	// then
+	assert.Nil(t, err)
+	assert.Equal(t, pub.Marshal(), keys[0].Blob)
+
+	_, err = Listen(path)
+	assert.NotNil(t, err)
+
+	cancel()
+	assert.Nil(t, <-served)
+
+	// the stale socket is replaced
+	l, err = Listen(path)
+	assert.Nil(t, err)
+	l.Close()
+}
+
+func TestParseEncryptedECDSAKeyWithWrongPassphrase(t *testing.T) {
+	// given
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalECPrivateKey(key)
+
+	// Legacy encrypted PEM keys are deprecated but still found in the wild.
+	block, _ := x509.EncryptPEMBlock(rand.Reader, "EC PRIVATE KEY", der, []byte("passphrase"), x509.PEMCipherAES256)
+
+	// when
+	_, err := parseSigner(KeyItem{PrivateKey: pem.EncodeToMemory(block), Passphrase: []byte("wrong")})
+
+	// then
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,76 @@
+package strength
+
+import (
+	"math"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MinLength is the length under which a password is always considered weak.
+	MinLength = 8
+	// MinEntropy is the estimated entropy, in bits, under which a password is considered weak.
+	MinEntropy = 50
+)
+
+// Entropy estimates the entropy of a password in bits.
+//
+// The estimate is based on the size of the character pool the password draws
+// from (lowercase, uppercase, digits, symbols and other runes). Characters that
+// repeat or continue a sequence of the previous one ("aaa", "abc", "321") only
+// count for half, since they add little to the effort of guessing the password.
+func Entropy(password string) float64 {
+	if password == "" {
+		return 0
+	}
+
+	var lower, upper, digit, symbol, other bool
+	length := 0.0
+	prev := rune(-1)
+
+	for _, r := range password {
+		switch {
+		case r < utf8.RuneSelf && unicode.IsLower(r):
+			lower = true
+		case r < utf8.RuneSelf && unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case r < utf8.RuneSelf && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+
+		if prev >= 0 && (r == prev || r == prev+1 || r == prev-1) {
+			length += 0.5
+		} else {
+			length++
+		}
+		prev = r
+	}
+
+	pool := 0
+	if lower {
+		pool += 26
+	}
+	if upper {
+		pool += 26
+	}
+	if digit {
+		pool += 10
+	}
+	if symbol {
+		pool += 33
+	}
+	if other {
+		pool += 100
+	}
+
+	return length * math.Log2(float64(pool))
+}
+
+// IsWeak reports whether a password is too short or too predictable.
+func IsWeak(password string) bool {
+	return utf8.RuneCountInString(password) < MinLength || Entropy(password) < MinEntropy
+}
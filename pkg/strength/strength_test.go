@@ -0,0 +1,61 @@
+package strength
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntropy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		password string
+		expected float64
+	}{
+		{"empty", "", 0},
+		{"dictionary word", "password", 7.5 * math.Log2(26)},
+		{"repeats", "aaaaaaaa", 4.5 * math.Log2(26)},
+		{"ascending sequence", "abcdefgh", 4.5 * math.Log2(26)},
+		{"descending sequence", "87654321", 4.5 * math.Log2(10)},
+		{"mixed classes", "aB3$", 4 * math.Log2(95)},
+		{"other runes", "пароль", 6 * math.Log2(100)},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			actual := Entropy(tc.password)
+
+			// then
+			assert.InDelta(t, tc.expected, actual, 1e-9)
+		})
+	}
+}
+
+func TestIsWeak(t *testing.T) {
+	testCases := []struct {
+		name     string
+		password string
+		expected bool
+	}{
+		{"empty", "", true},
+		{"short", "aB3$xY7", true},
+		{"dictionary word", "password", true},
+		{"dictionary word with a digit", "sunshine1", true},
+		{"repeats", "zzzzzzzzzzzzzzzz", true},
+		{"sequences", "abcdefgh12345678", true},
+		{"long random", "q7#Lm2!vR9@xT4", false},
+		{"long random lowercase", "xqzvbnwtrkplmjhd", false},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			actual := IsWeak(tc.password)
+
+			// then
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Package totp generates time-based one-time passwords (RFC 6238) from otpauth URIs.
+//
+// Keys are read from the URIs of authenticator apps:
+//
+//	otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example&algorithm=SHA256&digits=8&period=60
+//
+// The algorithm defaults to SHA1, digits to 6 and period to 30 seconds. Steam
+// Guard keys are given with the steam:// scheme, as in steam://JBSWY3DPEHPK3PXP,
+// or with encoder=steam on an otpauth URI.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDigits = 6
+	DefaultPeriod = 30 * time.Second
+	// steamAlphabet holds the characters of Steam Guard codes.
+	steamAlphabet = "23456789BCDFGHJKMNPQRTVWXY"
+	steamDigits   = 5
+)
+
+// ErrInvalidURI is returned for URIs that aren't TOTP keys.
+var ErrInvalidURI = errors.New("totp: invalid uri")
+
+type Algorithm string
+
+const (
+	SHA1   Algorithm = "SHA1"
+	SHA256 Algorithm = "SHA256"
+	SHA512 Algorithm = "SHA512"
+)
+
+// Key is a TOTP key, as given by an otpauth URI.
+type Key struct {
+	Issuer    string
+	Account   string
+	Secret    []byte
+	Algorithm Algorithm
+	Digits    int
+	Period    time.Duration
+	// Steam makes codes of 5 characters from the Steam Guard alphabet.
+	Steam bool
+}
+
+// Parse parses an otpauth://totp or steam:// URI.
+func Parse(uri string) (*Key, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+
+	if err != nil {
+		return nil, ErrInvalidURI
+	}
+
+	if strings.EqualFold(u.Scheme, "steam") {
+		// The secret is the host, or the opaque part of steam:SECRET.
+		secret, err := decodeSecret(u.Host + u.Opaque)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &Key{Issuer: "Steam", Secret: secret, Algorithm: SHA1, Digits: steamDigits, Period: DefaultPeriod, Steam: true}, nil
+	}
+
+	if !strings.EqualFold(u.Scheme, "otpauth") || !strings.EqualFold(u.Host, "totp") {
+		return nil, fmt.Errorf("%w: only otpauth://totp and steam:// uris are supported", ErrInvalidURI)
+	}
+
+	query := u.Query()
+	secret, err := decodeSecret(query.Get("secret"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{Secret: secret, Algorithm: SHA1, Digits: DefaultDigits, Period: DefaultPeriod}
+
+	// The label is "issuer:account" or "account".
+	label := strings.TrimPrefix(u.Path, "/")
+
+	if issuer, account, found := strings.Cut(label, ":"); found {
+		key.Issuer, key.Account = strings.TrimSpace(issuer), strings.TrimSpace(account)
+	} else {
+		key.Account = label
+	}
+
+	if issuer := query.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		key.Algorithm = Algorithm(strings.ToUpper(algorithm))
+
+		if key.Algorithm != SHA1 && key.Algorithm != SHA256 && key.Algorithm != SHA512 {
+			return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidURI, algorithm)
+		}
+	}
+
+	if digits := query.Get("digits"); digits != "" {
+		key.Digits, err = strconv.Atoi(digits)
+
+		if err != nil || (key.Digits != 6 && key.Digits != 8) {
+			return nil, fmt.Errorf("%w: digits must be 6 or 8", ErrInvalidURI)
+		}
+	}
+
+	if period := query.Get("period"); period != "" {
+		seconds, err := strconv.Atoi(period)
+
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("%w: period must be a positive number of seconds", ErrInvalidURI)
+		}
+
+		key.Period = time.Duration(seconds) * time.Second
+	}
+
+	if strings.EqualFold(query.Get("encoder"), "steam") {
+		key.Steam = true
+		key.Digits = steamDigits
+	}
+
+	return key, nil
+}
+
+// decodeSecret decodes a base32 secret, which authenticator apps give without padding
+// and sometimes in lowercase or with spaces.
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+
+	data, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("%w: secret must be base32 encoded", ErrInvalidURI)
+	}
+
+	return data, nil
+}
+
+// Code returns the code valid at t.
+func (k *Key) Code(t time.Time) string {
+	counter := uint64(t.Unix()) / uint64(k.Period/time.Second)
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(k.hash(), k.Secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, RFC 4226 section 5.3.
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	if k.Steam {
+		code := make([]byte, steamDigits)
+
+		for i := range code {
+			code[i] = steamAlphabet[value%uint32(len(steamAlphabet))]
+			value /= uint32(len(steamAlphabet))
+		}
+
+		return string(code)
+	}
+
+	mod := uint32(1)
+
+	for i := 0; i < k.Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", k.Digits, value%mod)
+}
+
+// Remaining returns how long the code valid at t remains valid.
+func (k *Key) Remaining(t time.Time) time.Duration {
+	period := int64(k.Period / time.Second)
+	elapsed := t.Unix() % period
+
+	return time.Duration(period-elapsed) * time.Second
+}
+
+func (k *Key) hash() func() hash.Hash {
+	switch k.Algorithm {
+	case SHA256:
+		return sha256.New
+	case SHA512:
+		return sha512.New
+	default:
+		return sha1.New
+	}
+}
@@ -0,0 +1,127 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func uri(secret, params string) string {
+	return "otpauth://totp/Example:alice@example.com?secret=" +
+		base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte(secret)) + params
+}
+
+// TestCodeRFC6238 checks the test vectors from RFC 6238 appendix B.
+func TestCodeRFC6238(t *testing.T) {
+	sha1Key := uri("12345678901234567890", "&digits=8")
+	sha256Key := uri("12345678901234567890123456789012", "&digits=8&algorithm=SHA256")
+	sha512Key := uri("1234567890123456789012345678901234567890123456789012345678901234", "&digits=8&algorithm=sha512")
+
+	testCases := []struct {
+		unix int64
+		uri  string
+		code string
+	}{
+		{59, sha1Key, "94287082"},
+		{59, sha256Key, "46119246"},
+		{59, sha512Key, "90693936"},
+		{1111111109, sha1Key, "07081804"},
+		{1111111109, sha256Key, "68084774"},
+		{1111111109, sha512Key, "25091201"},
+		{1234567890, sha1Key, "89005924"},
+		{1234567890, sha256Key, "91819424"},
+		{1234567890, sha512Key, "93441116"},
+		{2000000000, sha1Key, "69279037"},
+		{2000000000, sha256Key, "90698825"},
+		{2000000000, sha512Key, "38618901"},
+	}
+	for _, tc := range testCases {
+		key, err := Parse(tc.uri)
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.code, key.Code(time.Unix(tc.unix, 0)), tc.uri)
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		// when
+		key, err := Parse("otpauth://totp/Example:alice@example.com?secret=jbsw y3dp ehpk 3pxp")
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, &Key{
+			Issuer: "Example", Account: "alice@example.com", Secret: []byte("Hello!\xde\xad\xbe\xef"),
+			Algorithm: SHA1, Digits: 6, Period: 30 * time.Second,
+		}, key)
+	})
+
+	t.Run("parameters", func(t *testing.T) {
+		// when
+		key, err := Parse("otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&issuer=ACME%20Co&algorithm=SHA512&digits=8&period=60")
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, "ACME Co", key.Issuer)
+		assert.Equal(t, "alice", key.Account)
+		assert.Equal(t, SHA512, key.Algorithm)
+		assert.Equal(t, 8, key.Digits)
+		assert.Equal(t, time.Minute, key.Period)
+	})
+
+	t.Run("steam", func(t *testing.T) {
+		for _, uri := range []string{"steam://JBSWY3DPEHPK3PXP", "otpauth://totp/Steam:alice?secret=JBSWY3DPEHPK3PXP&encoder=steam"} {
+			key, err := Parse(uri)
+
+			assert.Nil(t, err)
+			assert.True(t, key.Steam, uri)
+			assert.Equal(t, 5, key.Digits, uri)
+		}
+	})
+
+	invalid := []string{
+		"",
+		"https://example.com",
+		"otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP&counter=1",
+		"otpauth://totp/alice",
+		"otpauth://totp/alice?secret=not-base32!",
+		"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&algorithm=MD5",
+		"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&digits=7",
+		"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&period=0",
+		"steam://",
+	}
+	for _, uri := range invalid {
+		_, err := Parse(uri)
+		assert.ErrorIs(t, err, ErrInvalidURI, uri)
+	}
+}
+
+func TestSteamCode(t *testing.T) {
+	// given
+	key, _ := Parse("steam://JBSWY3DPEHPK3PXP")
+	now := time.Unix(1683367200, 0)
+
+	// when
+	code := key.Code(now)
+
+	// then
+	assert.Equal(t, "TB95G", code)
+	assert.Len(t, code, 5)
+
+	for _, c := range code {
+		assert.True(t, strings.ContainsRune(steamAlphabet, c), code)
+	}
+
+	assert.Equal(t, code, key.Code(now.Add(29*time.Second)))
+}
+
+func TestRemaining(t *testing.T) {
+	key, _ := Parse(uri("12345678901234567890", "&period=60"))
+
+	assert.Equal(t, 60*time.Second, key.Remaining(time.Unix(120, 0)))
+	assert.Equal(t, 15*time.Second, key.Remaining(time.Unix(165, 0)))
+	assert.Equal(t, time.Second, key.Remaining(time.Unix(179, 999)))
+}
@@ -0,0 +1,286 @@
+// Package urlmatch matches the URL of a web page against the URLs of saved
+// logins, to find the logins to fill in the page.
+//
+// How loosely a login URL matches is set by its Strategy. With the default,
+// StrategyDomain, a login for https://accounts.example.co.uk matches any page
+// of example.co.uk, its registrable domain, found with an embedded copy of
+// the Public Suffix List, and any page of a domain declared equivalent.
+package urlmatch
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Strategy is how the URL of a login is matched against page URLs.
+type Strategy string
+
+const (
+	// StrategyDomain matches pages with the same registrable domain, or an equivalent one.
+	// It is the default strategy, used when the strategy is empty.
+	StrategyDomain Strategy = "domain"
+	// StrategyHost matches pages with the same host and port.
+	StrategyHost Strategy = "host"
+	// StrategyStartsWith matches pages whose URL starts with the login URL.
+	StrategyStartsWith Strategy = "startsWith"
+	// StrategyExact matches pages with the same URL, ignoring the fragment.
+	StrategyExact Strategy = "exact"
+	// StrategyRegex matches pages whose URL matches the login URL as a regular expression.
+	StrategyRegex Strategy = "regex"
+	// StrategyNever matches no page.
+	StrategyNever Strategy = "never"
+)
+
+// Strategies are the valid strategies.
+var Strategies = []Strategy{StrategyDomain, StrategyHost, StrategyStartsWith, StrategyExact, StrategyRegex, StrategyNever}
+
+// Quality is how closely a login URL matches a page URL. Better matches are greater.
+type Quality int
+
+const (
+	NoMatch Quality = iota
+	// MatchEquivalent is a page in a domain equivalent to the domain of the login.
+	MatchEquivalent
+	// MatchDomain is a page with the registrable domain of the login.
+	MatchDomain
+	// MatchHost is a page with the host and port of the login.
+	MatchHost
+	// MatchRegex is a page matching the regular expression of the login.
+	MatchRegex
+	// MatchStartsWith is a page whose URL starts with the login URL.
+	MatchStartsWith
+	// MatchExact is a page with the URL of the login.
+	MatchExact
+)
+
+var qualityNames = map[Quality]string{
+	NoMatch:         "none",
+	MatchEquivalent: "equivalent",
+	MatchDomain:     "domain",
+	MatchHost:       "host",
+	MatchRegex:      "regex",
+	MatchStartsWith: "startsWith",
+	MatchExact:      "exact",
+}
+
+func (q Quality) String() string {
+	return qualityNames[q]
+}
+
+var (
+	// ErrInvalidURL is returned for page URLs that aren't absolute http or https URLs.
+	ErrInvalidURL = errors.New("urlmatch: invalid url")
+	// ErrInvalidStrategy is returned for unknown strategies.
+	ErrInvalidStrategy = errors.New("urlmatch: invalid strategy")
+	// ErrInvalidRegex is returned for logins with StrategyRegex whose URL isn't a valid regular expression.
+	ErrInvalidRegex = errors.New("urlmatch: invalid regex")
+)
+
+// DefaultEquivalentDomains are groups of registrable domains of the same sites.
+var DefaultEquivalentDomains = [][]string{
+	{"google.com", "youtube.com", "gmail.com"},
+	{"apple.com", "icloud.com"},
+	{"microsoft.com", "live.com", "office.com", "microsoftonline.com", "outlook.com"},
+	{"amazon.com", "amazon.co.uk", "amazon.de", "amazon.fr", "amazon.co.jp"},
+	{"atlassian.com", "atlassian.net", "bitbucket.org"},
+}
+
+// Page is a parsed page URL.
+type Page struct {
+	url    *url.URL
+	raw    string
+	host   string
+	domain string
+}
+
+// ParsePage parses the URL of a page, which must be an absolute http or https URL.
+func ParsePage(rawURL string) (*Page, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, ErrInvalidURL
+	}
+
+	u.Fragment = ""
+	u.RawFragment = ""
+	host := strings.ToLower(u.Hostname())
+
+	return &Page{url: u, raw: u.String(), host: host, domain: RegistrableDomain(host)}, nil
+}
+
+// URL returns the page URL, without its fragment.
+func (p *Page) URL() string {
+	return p.raw
+}
+
+// Matcher matches login URLs against pages.
+type Matcher struct {
+	// groups maps registrable domains to the index of their equivalence group.
+	groups map[string]int
+}
+
+// NewMatcher returns a matcher treating the domains of each group as the same site.
+func NewMatcher(equivalentDomains [][]string) *Matcher {
+	m := &Matcher{groups: map[string]int{}}
+
+	for i, group := range equivalentDomains {
+		for _, domain := range group {
+			m.groups[strings.ToLower(domain)] = i
+		}
+	}
+
+	return m
+}
+
+// Validate checks that a strategy is known and, for StrategyRegex, that the login URL compiles.
+func Validate(loginURL string, strategy Strategy) error {
+	switch strategy {
+	case "", StrategyDomain, StrategyHost, StrategyStartsWith, StrategyExact, StrategyNever:
+		return nil
+	case StrategyRegex:
+		if _, err := regexp.Compile(loginURL); err != nil {
+			return ErrInvalidRegex
+		}
+
+		return nil
+	default:
+		return ErrInvalidStrategy
+	}
+}
+
+// Match returns how well a login URL matches the page with the given strategy.
+// Login URLs may leave out the scheme, as in "github.com/login"; https is assumed.
+// A login URL with a scheme only matches pages with the same scheme, except that
+// http logins match https pages, so logins don't leak to unencrypted pages.
+func (m *Matcher) Match(page *Page, loginURL string, strategy Strategy) Quality {
+	loginURL = strings.TrimSpace(loginURL)
+
+	if loginURL == "" {
+		return NoMatch
+	}
+
+	switch strategy {
+	case StrategyNever:
+		return NoMatch
+	case StrategyRegex:
+		re, err := regexp.Compile(loginURL)
+
+		if err != nil || !re.MatchString(page.raw) {
+			return NoMatch
+		}
+
+		return MatchRegex
+	}
+
+	login, hasScheme := parseLoginURL(loginURL)
+
+	if login == nil {
+		return NoMatch
+	}
+
+	if hasScheme && login.Scheme != page.url.Scheme && !(login.Scheme == "http" && page.url.Scheme == "https") {
+		return NoMatch
+	}
+
+	loginPort := effectivePort(login)
+
+	// An http login upgraded to https moves to the https port.
+	if login.Scheme != page.url.Scheme && login.Port() == "" {
+		loginPort = defaultPort(page.url.Scheme)
+	}
+
+	loginHost := strings.ToLower(login.Hostname())
+	sameHost := loginHost == page.host && loginPort == effectivePort(page.url)
+
+	if sameHost && sameResource(login, page.url) {
+		return MatchExact
+	}
+
+	switch strategy {
+	case StrategyExact:
+		return NoMatch
+	case StrategyStartsWith:
+		if sameHost && strings.HasPrefix(requestURI(page.url), requestURI(login)) {
+			return MatchStartsWith
+		}
+
+		return NoMatch
+	}
+
+	if sameHost {
+		return MatchHost
+	}
+
+	if strategy == StrategyHost {
+		return NoMatch
+	}
+
+	loginDomain := RegistrableDomain(loginHost)
+
+	// Hosts without a registrable domain, such as IP addresses, only match themselves.
+	if loginDomain == "" || page.domain == "" {
+		return NoMatch
+	}
+
+	if loginDomain == page.domain {
+		return MatchDomain
+	}
+
+	loginGroup, ok := m.groups[loginDomain]
+
+	if pageGroup, pageOK := m.groups[page.domain]; ok && pageOK && loginGroup == pageGroup {
+		return MatchEquivalent
+	}
+
+	return NoMatch
+}
+
+// parseLoginURL parses a login URL, adding the https scheme when it has none.
+func parseLoginURL(rawURL string) (*url.URL, bool) {
+	hasScheme := strings.Contains(rawURL, "://")
+
+	if !hasScheme {
+		rawURL = "https://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+
+	if err != nil || u.Hostname() == "" {
+		return nil, hasScheme
+	}
+
+	return u, hasScheme
+}
+
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+
+	return defaultPort(u.Scheme)
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "http" {
+		return "80"
+	}
+
+	return "443"
+}
+
+// sameResource compares the path and query of two URLs, an empty path being "/".
+func sameResource(a, b *url.URL) bool {
+	return requestURI(a) == requestURI(b)
+}
+
+func requestURI(u *url.URL) string {
+	uri := u.RequestURI()
+
+	if uri == "" {
+		return "/"
+	}
+
+	return uri
+}
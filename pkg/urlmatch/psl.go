@@ -0,0 +1,110 @@
+package urlmatch
+
+import (
+	"bufio"
+	_ "embed"
+	"net"
+	"strings"
+	"sync"
+)
+
+// publicSuffixData is the Public Suffix List from https://publicsuffix.org/list/,
+// ICANN and private domains. Refresh it with "go generate ./pkg/urlmatch" and
+// commit the result along with any test changes it requires.
+//
+//go:generate curl -fsSL -o public_suffix_list.dat https://publicsuffix.org/list/public_suffix_list.dat
+//go:embed public_suffix_list.dat
+var publicSuffixData string
+
+type suffixRules struct {
+	exact     map[string]bool
+	wildcard  map[string]bool
+	exception map[string]bool
+}
+
+var (
+	rulesOnce sync.Once
+	rules     suffixRules
+)
+
+func loadRules() {
+	rules = parseRules(publicSuffixData)
+}
+
+func parseRules(data string) suffixRules {
+	r := suffixRules{exact: map[string]bool{}, wildcard: map[string]bool{}, exception: map[string]bool{}}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+
+	for scanner.Scan() {
+		// Rules end at the first whitespace.
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "//") {
+			continue
+		}
+
+		rule := strings.ToLower(fields[0])
+
+		switch {
+		case strings.HasPrefix(rule, "!"):
+			r.exception[rule[1:]] = true
+		case strings.HasPrefix(rule, "*."):
+			r.wildcard[rule[2:]] = true
+		default:
+			r.exact[rule] = true
+		}
+	}
+
+	return r
+}
+
+// PublicSuffix returns the public suffix of a host, such as "co.uk" for
+// "www.example.co.uk". Hosts must be lowercase, without a trailing dot.
+func PublicSuffix(host string) string {
+	rulesOnce.Do(loadRules)
+
+	labels := strings.Split(host, ".")
+
+	// The longest matching rule wins, an exception rule over any other; the
+	// public suffix of an exception is the rule without its first label.
+	for i := range labels {
+		suffix := strings.Join(labels[i:], ".")
+
+		if rules.exception[suffix] {
+			return strings.Join(labels[i+1:], ".")
+		}
+
+		if rules.exact[suffix] {
+			return suffix
+		}
+
+		if i+1 < len(labels) && rules.wildcard[strings.Join(labels[i+1:], ".")] {
+			return suffix
+		}
+	}
+
+	// The implicit "*" rule.
+	return labels[len(labels)-1]
+}
+
+// RegistrableDomain returns the public suffix of a host plus one label, such
+// as "example.co.uk" for "www.example.co.uk". It returns the empty string for
+// hosts without one: public suffixes themselves, IP addresses and single labels
+// such as "localhost".
+func RegistrableDomain(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if host == "" || net.ParseIP(host) != nil {
+		return ""
+	}
+
+	suffix := PublicSuffix(host)
+
+	if suffix == host {
+		return ""
+	}
+
+	rest := strings.TrimSuffix(host, "."+suffix)
+
+	return rest[strings.LastIndex(rest, ".")+1:] + "." + suffix
+}
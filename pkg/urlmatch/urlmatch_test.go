@@ -0,0 +1,121 @@
+package urlmatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrableDomain(t *testing.T) {
+	testCases := []struct {
+		host     string
+		expected string
+	}{
+		{"example.com", "example.com"},
+		{"www.Example.COM.", "example.com"},
+		{"a.b.example.co.uk", "example.co.uk"},
+		{"co.uk", ""},
+		{"com", ""},
+		{"octocat.github.io", "octocat.github.io"},
+		{"github.io", ""},
+		{"example.unknowntld", "example.unknowntld"},
+		{"localhost", ""},
+		{"192.168.1.10", ""},
+		{"::1", ""},
+		// Wildcard and exception rules.
+		{"shop.example.ck", "shop.example.ck"},
+		{"example.ck", ""},
+		{"www.ck", "www.ck"},
+		{"a.www.ck", "www.ck"},
+		{"foo.kawasaki.jp", ""},
+		{"city.kawasaki.jp", "city.kawasaki.jp"},
+		{"www.city.kawasaki.jp", "city.kawasaki.jp"},
+		// Private domains of hosting providers.
+		{"contoso.azurewebsites.net", "contoso.azurewebsites.net"},
+		{"www.acme.onrender.com", "acme.onrender.com"},
+		{"acme.fly.dev", "acme.fly.dev"},
+		{"acme.myshopify.com", "acme.myshopify.com"},
+		{"1a2b.ngrok.io", "1a2b.ngrok.io"},
+		{"raw.githubusercontent.com", "raw.githubusercontent.com"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.host, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RegistrableDomain(tc.host))
+		})
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	page, err := ParsePage(" https://Accounts.Example.com/login?next=%2F#top ")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://Accounts.Example.com/login?next=%2F", page.URL())
+	assert.Equal(t, "example.com", page.domain)
+
+	for _, invalid := range []string{"", "example.com", "ftp://example.com", "javascript:alert(1)", "https://"} {
+		_, err := ParsePage(invalid)
+		assert.Equal(t, ErrInvalidURL, err, invalid)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	m := NewMatcher(DefaultEquivalentDomains)
+
+	testCases := []struct {
+		name     string
+		page     string
+		login    string
+		strategy Strategy
+		expected Quality
+	}{
+		{"exact", "https://github.com/login", "https://github.com/login", "", MatchExact},
+		{"exact without scheme", "https://github.com/", "github.com", "", MatchExact},
+		{"same host", "https://github.com/settings", "github.com/login", "", MatchHost},
+		{"same domain", "https://gist.github.com/", "https://github.com/login", "", MatchDomain},
+		{"multi-label suffix", "https://www.bbc.co.uk/", "account.bbc.co.uk", StrategyDomain, MatchDomain},
+		{"different site under a public suffix", "https://evil.co.uk/", "bbc.co.uk", "", NoMatch},
+		{"private suffix", "https://mallory.github.io/", "https://octocat.github.io/", "", NoMatch},
+		{"hosting provider suffix", "https://mallory.azurewebsites.net/", "https://contoso.azurewebsites.net/", "", NoMatch},
+		{"user content of a site", "https://raw.githubusercontent.com/", "https://github.com/login", "", NoMatch},
+		{"equivalent domains", "https://www.youtube.com/", "https://accounts.google.com/", "", MatchEquivalent},
+		{"unrelated", "https://gitlab.com/", "github.com", "", NoMatch},
+		{"http login on https page", "https://github.com/login", "http://github.com/login", "", MatchExact},
+		{"https login on http page", "http://github.com/login", "https://github.com/login", "", NoMatch},
+		{"port", "https://example.com:8443/", "https://example.com/", "", MatchDomain},
+		{"http login on https page with a port", "https://example.com:8443/", "http://example.com/", "", MatchDomain},
+		{"ip address", "http://192.168.1.1/", "http://192.168.1.1/admin", "", MatchHost},
+		{"other ip address", "http://192.168.1.2/", "http://192.168.1.1/", "", NoMatch},
+		{"host strategy", "https://gist.github.com/", "github.com", StrategyHost, NoMatch},
+		{"host strategy same host", "https://github.com/x", "github.com", StrategyHost, MatchHost},
+		{"starts with", "https://github.com/orgs/acme/settings", "github.com/orgs/acme", StrategyStartsWith, MatchStartsWith},
+		{"starts with other path", "https://github.com/orgs/other", "github.com/orgs/acme", StrategyStartsWith, NoMatch},
+		{"exact strategy", "https://github.com/login?x=1", "https://github.com/login", StrategyExact, NoMatch},
+		{"regex", "https://ci.acme.internal:8080/login", `^https://ci\.acme\.internal(:\d+)?/`, StrategyRegex, MatchRegex},
+		{"regex no match", "https://acme.com/", `^https://ci\.acme\.internal/`, StrategyRegex, NoMatch},
+		{"never", "https://github.com/login", "https://github.com/login", StrategyNever, NoMatch},
+		{"empty login url", "https://github.com/", "", "", NoMatch},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			page, err := ParsePage(tc.page)
+			assert.Nil(t, err)
+
+			// when
+			actual := m.Match(page, tc.login, tc.strategy)
+
+			// then
+			assert.Equal(t, tc.expected.String(), actual.String())
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, Validate("github.com", ""))
+	assert.Nil(t, Validate("github.com", StrategyStartsWith))
+	assert.Nil(t, Validate(`^https://(www\.)?github\.com/`, StrategyRegex))
+	assert.Equal(t, ErrInvalidRegex, Validate(`^https://(github.com`, StrategyRegex))
+	assert.Equal(t, ErrInvalidStrategy, Validate("github.com", "fuzzy"))
+}
@@ -0,0 +1,103 @@
+// Package webhook signs webhook payloads and verifies their signatures.
+//
+// The signature of a payload is sent in the SignatureHeader header as
+//
+//	t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<payload>">
+//
+// keyed with the secret of the webhook. Signing the time along with the
+// payload lets receivers reject deliveries replayed after a while.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of webhook deliveries.
+const (
+	// SignatureHeader holds the signature of the payload.
+	SignatureHeader = "X-Gopass-Signature"
+	// EventHeader holds the type of the event delivered.
+	EventHeader = "X-Gopass-Event"
+	// DeliveryHeader holds the ID of the event delivered, the same on every
+	// attempt, so receivers can ignore the events they already handled.
+	DeliveryHeader = "X-Gopass-Delivery"
+)
+
+// DefaultTolerance is how old signatures are accepted by receivers by default.
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	// ErrInvalidSignature is returned when a signature is missing, malformed
+	// or doesn't match the payload.
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	// ErrExpiredSignature is returned when a signature is too old, or too far in the future.
+	ErrExpiredSignature = errors.New("webhook: expired signature")
+)
+
+// Sign returns the signature of payload at time t.
+func Sign(secret string, t time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac(secret, timestamp, payload))
+}
+
+// Verify checks that signature is a signature of payload with secret, made
+// within tolerance of now.
+func Verify(secret, signature string, payload []byte, now time.Time, tolerance time.Duration) error {
+	var timestamp string
+	var sums [][]byte
+
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			// Several signatures are accepted, so secrets can be rotated.
+			if sum, err := hex.DecodeString(value); err == nil {
+				sums = append(sums, sum)
+			}
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+
+	if err != nil || len(sums) == 0 {
+		return ErrInvalidSignature
+	}
+
+	expected := mac(secret, timestamp, payload)
+	valid := false
+
+	for _, sum := range sums {
+		if hmac.Equal(sum, expected) {
+			valid = true
+		}
+	}
+
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return ErrExpiredSignature
+	}
+
+	return nil
+}
+
+func mac(secret, timestamp string, payload []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(payload)
+
+	return h.Sum(nil)
+}
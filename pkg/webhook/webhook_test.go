@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	signature := Sign("whsec_test", time.Unix(1700000000, 0), []byte(`{"type":"item.updated"}`))
+
+	assert.Equal(t, "t=1700000000,v1=9bc42d19a6fcbee380e44293cbf00c855d1b4311b0bb15520fc8d8849d536881", signature)
+}
+
+func TestVerify(t *testing.T) {
+	signedAt := time.Unix(1700000000, 0)
+	payload := []byte(`{"type":"item.updated"}`)
+	signature := Sign("whsec_test", signedAt, payload)
+
+	testCases := []struct {
+		name      string
+		secret    string
+		signature string
+		payload   string
+		now       time.Time
+		expected  error
+	}{
+		{"valid", "whsec_test", signature, string(payload), signedAt.Add(time.Minute), nil},
+		{"clock skew", "whsec_test", signature, string(payload), signedAt.Add(-time.Minute), nil},
+		{"rotated secret", "whsec_test", signature + ",v1=00ff", string(payload), signedAt, nil},
+		{"wrong secret", "whsec_other", signature, string(payload), signedAt, ErrInvalidSignature},
+		{"tampered payload", "whsec_test", signature, `{"type":"item.deleted"}`, signedAt, ErrInvalidSignature},
+		{"replayed", "whsec_test", signature, string(payload), signedAt.Add(DefaultTolerance + time.Second), ErrExpiredSignature},
+		{"no timestamp", "whsec_test", signature[len("t=1700000000,"):], string(payload), signedAt, ErrInvalidSignature},
+		{"no signature", "whsec_test", "t=1700000000", string(payload), signedAt, ErrInvalidSignature},
+		{"empty", "whsec_test", "", string(payload), signedAt, ErrInvalidSignature},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(tc.secret, tc.signature, []byte(tc.payload), tc.now, DefaultTolerance)
+
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+}